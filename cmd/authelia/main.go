@@ -107,6 +107,8 @@ func startServer() {
 		if err != nil {
 			logger.Fatalf("Failed to Check LDAP Authentication Backend: %v", err)
 		}
+	case config.AuthenticationBackend.SQL != nil:
+		userProvider = authentication.NewSQLUserProvider(config.AuthenticationBackend.SQL, storageProvider)
 	default:
 		logger.Fatalf("Unrecognized authentication backend")
 	}
@@ -131,14 +133,24 @@ func startServer() {
 
 	clock := utils.RealClock{}
 	authorizer := authorization.NewAuthorizer(config)
+	startAccessControlReloader(authorizer)
 	sessionProvider := session.NewProvider(config.Session, autheliaCertPool)
 	regulator := regulation.NewRegulator(config.Regulation, storageProvider, clock)
 
-	oidcProvider, err := oidc.NewOpenIDConnectProvider(config.IdentityProviders.OIDC)
+	oidcProvider, err := oidc.NewOpenIDConnectProvider(config.IdentityProviders.OIDC, storageProvider, autheliaCertPool)
 	if err != nil {
 		logger.Fatalf("Error initializing OpenID Connect Provider: %+v", err)
 	}
 
+	if oidcProvider.Store != nil {
+		if err = oidcProvider.Store.LoadClients(); err != nil {
+			logger.Fatalf("Error loading OpenID Connect clients: %+v", err)
+		}
+
+		go oidcProvider.Store.StartPurgeRoutine()
+		go oidcProvider.Store.StartClientReloadRoutine()
+	}
+
 	providers := middlewares.Providers{
 		Authorizer:      authorizer,
 		UserProvider:    userProvider,
@@ -180,7 +192,7 @@ func main() {
 
 	rootCmd.AddCommand(buildCmd, commands.HashPasswordCmd,
 		commands.ValidateConfigCmd, commands.CertificatesCmd,
-		commands.RSACmd)
+		commands.RSACmd, commands.AccessControlCmd, commands.RegulationCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		logger.Fatal(err)
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/authelia/authelia/internal/authorization"
+	"github.com/authelia/authelia/internal/configuration"
+	"github.com/authelia/authelia/internal/logging"
+)
+
+// startAccessControlReloader reloads the access control rules of the authorizer when the configuration file changes or
+// when Authelia receives a SIGHUP signal.
+func startAccessControlReloader(authorizer *authorization.Authorizer) {
+	logger := logging.Logger()
+
+	if _, err := configuration.WatchConfig(configPathFlag, func() { reloadAccessControl(authorizer, "the configuration file changed") }); err != nil {
+		logger.Errorf("Unable to watch the configuration file %s, the access control rules will only be reloaded on SIGHUP: %+v", configPathFlag, err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		for range signals {
+			reloadAccessControl(authorizer, "SIGHUP received")
+		}
+	}()
+}
+
+func reloadAccessControl(authorizer *authorization.Authorizer, reason string) {
+	logger := logging.Logger()
+
+	config, errs := configuration.ReadAccessControl(configPathFlag)
+	if len(errs) != 0 {
+		logger.Errorf("Reloading the access control rules as %s failed, the current rules are kept:", reason)
+
+		for _, err := range errs {
+			logger.Error(err)
+		}
+
+		return
+	}
+
+	diff := authorizer.Reload(*config)
+
+	if diff.IsEmpty() {
+		logger.Debugf("Reloaded the access control rules as %s without any change", reason)
+
+		return
+	}
+
+	logger.Infof("Reloaded the access control rules as %s", reason)
+
+	if diff.PreviousDefaultPolicy != diff.DefaultPolicy {
+		logger.Infof("Access control default policy changed from %s to %s", diff.PreviousDefaultPolicy, diff.DefaultPolicy)
+	}
+
+	for _, rule := range diff.Removed {
+		logger.Infof("Access control rule removed: %s", rule)
+	}
+
+	for _, rule := range diff.Added {
+		logger.Infof("Access control rule added: %s", rule)
+	}
+
+	if diff.NetworksChanged {
+		logger.Info("Access control networks changed")
+	}
+}
@@ -19,8 +19,8 @@ const (
 const (
 	// TOTP Method using Time-Based One-Time Password applications like Google Authenticator.
 	TOTP = "totp"
-	// U2F Method using U2F devices like Yubikeys.
-	U2F = "u2f"
+	// Webauthn Method using Webauthn devices like YubiKeys.
+	Webauthn = "webauthn"
 	// Push Method using Duo application to receive push notifications.
 	Push = "mobile_push"
 )
@@ -28,10 +28,40 @@ const (
 const (
 	ldapSupportedExtensionAttribute = "supportedExtension"
 	ldapOIDPasswdModifyExtension    = "1.3.6.1.4.1.4203.1.11.1" // http://oidref.com/1.3.6.1.4.1.4203.1.11.1
+
+	// ldapInChainGroupsFilter matches the groups the user is a member of directly or through nested groups with the
+	// LDAP_MATCHING_RULE_IN_CHAIN of Active Directory.
+	ldapInChainGroupsFilter = "(member:1.2.840.113556.1.4.1941:={dn})"
+)
+
+// Error codes of the password policy control, see https://tools.ietf.org/html/draft-behera-ldap-password-policy-10.
+const (
+	ldapPasswordPolicyPasswordExpired  = 0
+	ldapPasswordPolicyAccountLocked    = 1
+	ldapPasswordPolicyChangeAfterReset = 2
+)
+
+// Codes of the diagnostic message of the bind errors of Active Directory, see
+// https://ldapwiki.com/wiki/Common%20Active%20Directory%20Bind%20Errors.
+const (
+	ldapActiveDirectoryPasswordExpired    = "532"
+	ldapActiveDirectoryAccountDisabled    = "533"
+	ldapActiveDirectoryAccountExpired     = "701"
+	ldapActiveDirectoryPasswordMustChange = "773"
+)
+
+const (
+	ldapMetricPoolOpen     = "pool_connections_open"
+	ldapMetricPoolIdle     = "pool_connections_idle"
+	ldapMetricPoolInUse    = "pool_connections_in_use"
+	ldapMetricPoolWaits    = "pool_waits"
+	ldapMetricPoolTimeouts = "pool_timeouts"
+	ldapMetricDials        = "dials"
+	ldapMetricDialErrors   = "dial_errors"
 )
 
 // PossibleMethods is the set of all possible 2FA methods.
-var PossibleMethods = []string{TOTP, U2F, Push}
+var PossibleMethods = []string{TOTP, Webauthn, Push}
 
 // CryptAlgo the crypt representation of an algorithm used in the prefix of the hash.
 type CryptAlgo string
@@ -59,6 +89,25 @@ var HashingPossibleSaltCharacters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJ
 // ErrUserNotFound indicates the user wasn't found in the authentication backend.
 var ErrUserNotFound = errors.New("user not found")
 
+// ErrPasswordExpired indicates the password of the user is correct but has expired, it must be changed.
+var ErrPasswordExpired = errors.New("password expired")
+
+// ErrPasswordMustChange indicates the password of the user is correct but must be changed, usually because it has been
+// reset by an administrator.
+var ErrPasswordMustChange = errors.New("password must be changed")
+
+// ErrAccountLocked indicates the account of the user is locked.
+var ErrAccountLocked = errors.New("account locked")
+
+// ErrAccountDisabled indicates the account of the user is disabled or expired.
+var ErrAccountDisabled = errors.New("account disabled")
+
+// ErrPasswordPolicy indicates the new password of the user doesn't meet the password policy of the directory.
+var ErrPasswordPolicy = errors.New("password does not meet the password policy requirements")
+
+// ErrLDAPPoolTimeout indicates no connection of the LDAP pool was released before the timeout of the pool.
+var ErrLDAPPoolTimeout = errors.New("timeout waiting for a connection of the ldap pool")
+
 const argon2id = "argon2id"
 const sha512 = "sha512"
 
@@ -28,6 +28,8 @@ type UserDetailsModel struct {
 	DisplayName    string   `yaml:"displayname" valid:"required"`
 	Email          string   `yaml:"email"`
 	Groups         []string `yaml:"groups"`
+
+	Attributes map[string]interface{} `yaml:"attributes,omitempty"`
 }
 
 // DatabaseModel is the model of users file database.
@@ -168,6 +170,31 @@ func (p *FileUserProvider) GetDetails(username string) (*UserDetails, error) {
 	return nil, fmt.Errorf("User '%s' does not exist in database", username)
 }
 
+// GetAttributes retrieves the values of the given attributes of a user, attributes without a value are omitted.
+func (p *FileUserProvider) GetAttributes(username string, attributes []string) (map[string][]string, error) {
+	details, ok := p.database.Users[username]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	values := make(map[string][]string, len(attributes))
+
+	for _, attribute := range attributes {
+		switch value := details.Attributes[attribute].(type) {
+		case nil:
+			continue
+		case []interface{}:
+			for _, item := range value {
+				values[attribute] = append(values[attribute], fmt.Sprint(item))
+			}
+		default:
+			values[attribute] = []string{fmt.Sprint(value)}
+		}
+	}
+
+	return values, nil
+}
+
 // UpdatePassword update the password of the given user.
 func (p *FileUserProvider) UpdatePassword(username string, newPassword string) error {
 	details, ok := p.database.Users[username]
@@ -133,6 +133,24 @@ func TestShouldRetrieveUserDetails(t *testing.T) {
 	})
 }
 
+func TestShouldRetrieveUserAttributes(t *testing.T) {
+	WithDatabase(UserDatabaseContent, func(path string) {
+		config := DefaultFileAuthenticationBackendConfiguration
+		config.Path = path
+		provider := NewFileUserProvider(&config)
+		attributes, err := provider.GetAttributes("john", []string{"department", "employee_number", "locales", "missing"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]string{
+			"department":      {"Engineering"},
+			"employee_number": {"1234"},
+			"locales":         {"en", "fr"},
+		}, attributes)
+
+		_, err = provider.GetAttributes("unknown", []string{"department"})
+		assert.Equal(t, ErrUserNotFound, err)
+	})
+}
+
 func TestShouldUpdatePassword(t *testing.T) {
 	WithDatabase(UserDatabaseContent, func(path string) {
 		config := DefaultFileAuthenticationBackendConfiguration
@@ -284,6 +302,12 @@ users:
     groups:
       - admins
       - dev
+    attributes:
+      department: Engineering
+      employee_number: 1234
+      locales:
+        - en
+        - fr
 
   harry:
     displayname: "Harry Potter"
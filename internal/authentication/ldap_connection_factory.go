@@ -11,7 +11,9 @@ import (
 // LDAPConnection interface representing a connection to the ldap.
 type LDAPConnection interface {
 	Bind(username, password string) error
+	SimpleBind(simpleBindRequest *ldap.SimpleBindRequest) (*ldap.SimpleBindResult, error)
 	Close()
+	IsClosing() bool
 
 	Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error)
 	Modify(modifyRequest *ldap.ModifyRequest) error
@@ -34,11 +36,21 @@ func (lc *LDAPConnectionImpl) Bind(username, password string) error {
 	return lc.conn.Bind(username, password)
 }
 
+// SimpleBind binds ldap connection with a simple bind request and returns the controls of the response.
+func (lc *LDAPConnectionImpl) SimpleBind(simpleBindRequest *ldap.SimpleBindRequest) (*ldap.SimpleBindResult, error) {
+	return lc.conn.SimpleBind(simpleBindRequest)
+}
+
 // Close closes a ldap connection.
 func (lc *LDAPConnectionImpl) Close() {
 	lc.conn.Close()
 }
 
+// IsClosing returns true if the ldap connection is closing or closed.
+func (lc *LDAPConnectionImpl) IsClosing() bool {
+	return lc.conn.IsClosing()
+}
+
 // Search searches a ldap server.
 func (lc *LDAPConnectionImpl) Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
 	return lc.conn.Search(searchRequest)
@@ -0,0 +1,225 @@
+package authentication
+
+import (
+	"expvar"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+var (
+	// ldapMetrics are the metrics of the LDAP connections published by the expvars endpoint.
+	ldapMetrics = expvar.NewMap("ldap")
+
+	// ldapServersMetrics are the health of the LDAP servers, 1 if the server is healthy and 0 otherwise.
+	ldapServersMetrics = new(expvar.Map).Init()
+)
+
+func init() {
+	ldapMetrics.Set("servers_healthy", ldapServersMetrics)
+}
+
+// ldapConnectionPool is a bounded pool of the connections of the LDAP bind user. The connections are opened on demand
+// and kept idle once released until they are closed by the server. The idle connections may be closed by the server
+// without the pool noticing it, the operations failing on them with a network error are retried on a new connection.
+type ldapConnectionPool struct {
+	connect func() (LDAPConnection, error)
+	slots   chan struct{}
+	idle    chan LDAPConnection
+	timeout time.Duration
+}
+
+// newLDAPConnectionPool creates a pool of at most size connections opened with the connect function.
+func newLDAPConnectionPool(size int, timeout time.Duration, connect func() (LDAPConnection, error)) *ldapConnectionPool {
+	return &ldapConnectionPool{
+		connect: connect,
+		slots:   make(chan struct{}, size),
+		idle:    make(chan LDAPConnection, size),
+		timeout: timeout,
+	}
+}
+
+// Get returns an idle connection of the pool or opens a new one. It waits for a connection to be released when all
+// the connections of the pool are in use and fails once the timeout of the pool is reached.
+func (p *ldapConnectionPool) Get() (LDAPConnection, error) {
+	if err := p.acquire(); err != nil {
+		return nil, err
+	}
+
+	if conn := p.idleConnection(); conn != nil {
+		ldapMetrics.Add(ldapMetricPoolInUse, 1)
+
+		return &ldapPooledConnection{LDAPConnection: conn, pool: p, reused: true}, nil
+	}
+
+	conn, err := p.connect()
+	if err != nil {
+		<-p.slots
+		return nil, err
+	}
+
+	ldapMetrics.Add(ldapMetricPoolOpen, 1)
+	ldapMetrics.Add(ldapMetricPoolInUse, 1)
+
+	return &ldapPooledConnection{LDAPConnection: conn, pool: p}, nil
+}
+
+// idleConnection returns an idle connection which is still open or nil if there is none.
+func (p *ldapConnectionPool) idleConnection() LDAPConnection {
+	for {
+		select {
+		case conn := <-p.idle:
+			ldapMetrics.Add(ldapMetricPoolIdle, -1)
+
+			if !conn.IsClosing() {
+				return conn
+			}
+
+			p.discard(conn)
+		default:
+			return nil
+		}
+	}
+}
+
+// acquire reserves a connection of the pool, waiting for the timeout of the pool when all of them are in use.
+func (p *ldapConnectionPool) acquire() error {
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	ldapMetrics.Add(ldapMetricPoolWaits, 1)
+
+	timer := time.NewTimer(p.timeout)
+	defer timer.Stop()
+
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		ldapMetrics.Add(ldapMetricPoolTimeouts, 1)
+		return ErrLDAPPoolTimeout
+	}
+}
+
+// release puts a connection back in the pool, the broken connections are closed.
+func (p *ldapConnectionPool) release(conn LDAPConnection, broken bool) {
+	ldapMetrics.Add(ldapMetricPoolInUse, -1)
+
+	if broken || conn.IsClosing() {
+		p.discard(conn)
+	} else {
+		select {
+		case p.idle <- conn:
+			ldapMetrics.Add(ldapMetricPoolIdle, 1)
+		default:
+			p.discard(conn)
+		}
+	}
+
+	<-p.slots
+}
+
+func (p *ldapConnectionPool) discard(conn LDAPConnection) {
+	conn.Close()
+	ldapMetrics.Add(ldapMetricPoolOpen, -1)
+}
+
+// ldapPooledConnection is a connection of the pool, closing it releases it to the pool. The connection is closed
+// instead when it's broken by a network error or when it's bound to another user.
+type ldapPooledConnection struct {
+	LDAPConnection
+
+	pool     *ldapConnectionPool
+	broken   bool
+	released bool
+	reused   bool
+}
+
+// Bind binds the connection to another user, it's not reused by the pool since it's no longer bound to the bind user.
+func (c *ldapPooledConnection) Bind(username, password string) error {
+	c.broken = true
+
+	return c.LDAPConnection.Bind(username, password)
+}
+
+// SimpleBind binds the connection to another user, it's not reused by the pool since it's no longer bound to the bind
+// user.
+func (c *ldapPooledConnection) SimpleBind(simpleBindRequest *ldap.SimpleBindRequest) (*ldap.SimpleBindResult, error) {
+	c.broken = true
+
+	return c.LDAPConnection.SimpleBind(simpleBindRequest)
+}
+
+// Close releases the connection to the pool.
+func (c *ldapPooledConnection) Close() {
+	if c.released {
+		return
+	}
+
+	c.released = true
+
+	c.pool.release(c.LDAPConnection, c.broken)
+}
+
+// Search searches a ldap server.
+func (c *ldapPooledConnection) Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	result, err := c.LDAPConnection.Search(searchRequest)
+	if c.retry(err) {
+		result, err = c.LDAPConnection.Search(searchRequest)
+	}
+
+	return result, c.check(err)
+}
+
+// Modify modifies an ldap object.
+func (c *ldapPooledConnection) Modify(modifyRequest *ldap.ModifyRequest) error {
+	err := c.LDAPConnection.Modify(modifyRequest)
+	if c.retry(err) {
+		err = c.LDAPConnection.Modify(modifyRequest)
+	}
+
+	return c.check(err)
+}
+
+// PasswordModify modifies an ldap objects password.
+func (c *ldapPooledConnection) PasswordModify(pwdModifyRequest *ldap.PasswordModifyRequest) error {
+	err := c.LDAPConnection.PasswordModify(pwdModifyRequest)
+	if c.retry(err) {
+		err = c.LDAPConnection.PasswordModify(pwdModifyRequest)
+	}
+
+	return c.check(err)
+}
+
+// retry replaces the connection with a new one when it was idle in the pool and the operation failed with a network
+// error, the server has likely closed it in the meantime. It returns true when the operation can be attempted again.
+func (c *ldapPooledConnection) retry(err error) bool {
+	if !c.reused || c.broken || !ldap.IsErrorWithCode(err, ldap.ErrorNetwork) {
+		return false
+	}
+
+	c.reused = false
+
+	conn, err := c.pool.connect()
+	if err != nil {
+		return false
+	}
+
+	c.pool.discard(c.LDAPConnection)
+	ldapMetrics.Add(ldapMetricPoolOpen, 1)
+
+	c.LDAPConnection = conn
+
+	return true
+}
+
+func (c *ldapPooledConnection) check(err error) error {
+	if ldap.IsErrorWithCode(err, ldap.ErrorNetwork) {
+		c.broken = true
+	}
+
+	return err
+}
@@ -0,0 +1,274 @@
+package authentication
+
+import (
+	"crypto/tls"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldFailoverToNextLDAPServer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPConnectionFactory(ctrl)
+	mockConn := NewMockLDAPConnection(ctrl)
+
+	ldapClient := newLDAPUserProvider(
+		schema.LDAPAuthenticationBackendConfiguration{
+			URLs: []string{"ldap://dc1.example.com", "ldap://dc2.example.com"},
+		},
+		nil,
+		mockFactory)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldap://dc1.example.com"), gomock.Any()).
+			Return(nil, errors.New("could not connect")),
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldap://dc2.example.com"), gomock.Any()).
+			Return(mockConn, nil),
+		mockConn.EXPECT().
+			Bind(gomock.Eq("cn=admin,dc=example,dc=com"), gomock.Eq("password")).
+			Return(nil),
+	)
+
+	_, err := ldapClient.connect("cn=admin,dc=example,dc=com", "password")
+	require.NoError(t, err)
+
+	assert.False(t, ldapClient.servers.servers[0].isHealthy())
+	assert.True(t, ldapClient.servers.servers[1].isHealthy())
+
+	// The unhealthy server is attempted last.
+	candidates := ldapClient.servers.candidates()
+	assert.Equal(t, "ldap://dc2.example.com", candidates[0].url)
+	assert.Equal(t, "ldap://dc1.example.com", candidates[1].url)
+}
+
+func TestShouldReturnLastErrorWhenAllLDAPServersFail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPConnectionFactory(ctrl)
+
+	ldapClient := newLDAPUserProvider(
+		schema.LDAPAuthenticationBackendConfiguration{
+			URLs: []string{"ldap://dc1.example.com", "ldap://dc2.example.com"},
+		},
+		nil,
+		mockFactory)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldap://dc1.example.com"), gomock.Any()).
+			Return(nil, errors.New("could not connect to dc1")),
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldap://dc2.example.com"), gomock.Any()).
+			Return(nil, errors.New("could not connect to dc2")),
+	)
+
+	_, err := ldapClient.connect("cn=admin,dc=example,dc=com", "password")
+	assert.EqualError(t, err, "could not connect to dc2")
+}
+
+func TestShouldUseLDAPServersInTurnWithRoundRobin(t *testing.T) {
+	servers := newLDAPServers([]string{"ldap://dc1.example.com", "ldap://dc2.example.com", "ldap://dc3.example.com"},
+		schema.LDAPURLStrategyRoundRobin, nil)
+
+	var first []string
+
+	for i := 0; i < 4; i++ {
+		first = append(first, servers.candidates()[0].url)
+	}
+
+	assert.Equal(t, []string{"ldap://dc1.example.com", "ldap://dc2.example.com", "ldap://dc3.example.com", "ldap://dc1.example.com"}, first)
+
+	servers.servers[1].setHealthy(false)
+
+	// The turn of the unhealthy server is skipped, it's attempted last.
+	candidates := servers.candidates()
+	assert.Equal(t, "ldap://dc3.example.com", candidates[0].url)
+	assert.Equal(t, "ldap://dc1.example.com", candidates[1].url)
+	assert.Equal(t, "ldap://dc2.example.com", candidates[2].url)
+}
+
+func TestShouldSetServerNameOfEachLDAPServer(t *testing.T) {
+	servers := newLDAPServers([]string{"ldaps://dc1.example.com", "ldaps://dc2.example.com:636"},
+		schema.LDAPURLStrategyFailover, &tls.Config{}) //nolint:gosec
+
+	assert.Equal(t, "dc1.example.com", servers.servers[0].tlsConfig.ServerName)
+	assert.Equal(t, "dc2.example.com", servers.servers[1].tlsConfig.ServerName)
+}
+
+func TestShouldReuseConnectionsOfThePool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPConnectionFactory(ctrl)
+	mockConn := NewMockLDAPConnection(ctrl)
+
+	ldapClient := newLDAPUserProvider(
+		schema.LDAPAuthenticationBackendConfiguration{
+			URL:      "ldap://127.0.0.1:389",
+			User:     "cn=admin,dc=example,dc=com",
+			Password: "password",
+			Pool:     schema.LDAPPoolConfiguration{MaxConnections: 1},
+		},
+		nil,
+		mockFactory)
+
+	mockFactory.EXPECT().
+		DialURL(gomock.Eq("ldap://127.0.0.1:389"), gomock.Any()).
+		Return(mockConn, nil)
+
+	mockConn.EXPECT().
+		Bind(gomock.Eq("cn=admin,dc=example,dc=com"), gomock.Eq("password")).
+		Return(nil)
+
+	mockConn.EXPECT().IsClosing().Return(false).Times(3)
+
+	for i := 0; i < 2; i++ {
+		conn, err := ldapClient.connectBindUser()
+		require.NoError(t, err)
+
+		conn.Close()
+	}
+}
+
+func TestShouldTimeoutWhenAllConnectionsOfThePoolAreInUse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockConn := NewMockLDAPConnection(ctrl)
+
+	pool := newLDAPConnectionPool(1, time.Millisecond, func() (LDAPConnection, error) {
+		return mockConn, nil
+	})
+
+	conn, err := pool.Get()
+	require.NoError(t, err)
+
+	_, err = pool.Get()
+	assert.Equal(t, ErrLDAPPoolTimeout, err)
+
+	mockConn.EXPECT().IsClosing().Return(false)
+
+	conn.Close()
+	conn.Close()
+
+	mockConn.EXPECT().IsClosing().Return(false)
+
+	_, err = pool.Get()
+	assert.NoError(t, err)
+}
+
+func TestShouldCloseBrokenConnectionsOfThePool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockConn := NewMockLDAPConnection(ctrl)
+
+	pool := newLDAPConnectionPool(1, time.Millisecond, func() (LDAPConnection, error) {
+		return mockConn, nil
+	})
+
+	conn, err := pool.Get()
+	require.NoError(t, err)
+
+	gomock.InOrder(
+		mockConn.EXPECT().
+			Search(gomock.Any()).
+			Return(nil, ldap.NewError(ldap.ErrorNetwork, errors.New("connection reset"))),
+		mockConn.EXPECT().Close(),
+	)
+
+	_, err = conn.Search(&ldap.SearchRequest{})
+	assert.Error(t, err)
+
+	conn.Close()
+
+	assert.Len(t, pool.idle, 0)
+	assert.Len(t, pool.slots, 0)
+}
+
+func TestShouldRetryOnNewConnectionWhenIdleConnectionOfThePoolIsStale(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	staleConn := NewMockLDAPConnection(ctrl)
+	freshConn := NewMockLDAPConnection(ctrl)
+
+	connections := []LDAPConnection{staleConn, freshConn}
+
+	pool := newLDAPConnectionPool(1, time.Millisecond, func() (LDAPConnection, error) {
+		conn := connections[0]
+		connections = connections[1:]
+
+		return conn, nil
+	})
+
+	conn, err := pool.Get()
+	require.NoError(t, err)
+
+	staleConn.EXPECT().IsClosing().Return(false).Times(2)
+
+	conn.Close()
+
+	conn, err = pool.Get()
+	require.NoError(t, err)
+
+	gomock.InOrder(
+		staleConn.EXPECT().
+			Search(gomock.Any()).
+			Return(nil, ldap.NewError(ldap.ErrorNetwork, errors.New("connection reset"))),
+		staleConn.EXPECT().Close(),
+		freshConn.EXPECT().
+			Search(gomock.Any()).
+			Return(&ldap.SearchResult{}, nil),
+		freshConn.EXPECT().IsClosing().Return(false),
+	)
+
+	_, err = conn.Search(&ldap.SearchRequest{})
+	assert.NoError(t, err)
+
+	conn.Close()
+
+	assert.Len(t, pool.idle, 1)
+	assert.Len(t, pool.slots, 0)
+}
+
+func TestShouldNotRetryOnNewConnectionWhenConnectionOfThePoolIsNew(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockConn := NewMockLDAPConnection(ctrl)
+
+	pool := newLDAPConnectionPool(1, time.Millisecond, func() (LDAPConnection, error) {
+		return mockConn, nil
+	})
+
+	conn, err := pool.Get()
+	require.NoError(t, err)
+
+	// The connection isn't retried since it has just been opened.
+	gomock.InOrder(
+		mockConn.EXPECT().
+			Search(gomock.Any()).
+			Return(nil, ldap.NewError(ldap.ErrorNetwork, errors.New("connection reset"))),
+		mockConn.EXPECT().Close(),
+	)
+
+	_, err = conn.Search(&ldap.SearchRequest{})
+	assert.Error(t, err)
+
+	conn.Close()
+
+	assert.Len(t, pool.idle, 0)
+}
@@ -0,0 +1,99 @@
+package authentication
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// ldapActiveDirectoryDataRegexp matches the code of the diagnostic message of the bind errors of Active Directory like
+// `80090308: LdapErr: DSID-0C09042A, comment: AcceptSecurityContext error, data 532, v3839`.
+var ldapActiveDirectoryDataRegexp = regexp.MustCompile(`data ([0-9a-fA-F]+)`)
+
+// newLDAPPasswordPolicyBindRequest creates the bind request of a user asking the directory for the password policy
+// control. The control isn't critical so the directories which don't support it ignore it.
+func newLDAPPasswordPolicyBindRequest(userDN, password string) *ldap.SimpleBindRequest {
+	return ldap.NewSimpleBindRequest(userDN, password, []ldap.Control{ldap.NewControlBeheraPasswordPolicy()})
+}
+
+// checkPasswordPolicy returns the error of the bind of the user given the password policy of the directory. The error
+// wraps ErrPasswordExpired or ErrPasswordMustChange when the password of the user is correct but must be changed, and
+// ErrAccountLocked or ErrAccountDisabled when the directory gives the reason the account can't be used.
+func (p *LDAPUserProvider) checkPasswordPolicy(result *ldap.SimpleBindResult, err error) error {
+	if reason := ldapPasswordPolicyControlReason(result); reason != nil {
+		return wrapLDAPPasswordPolicyError(reason, err)
+	}
+
+	if err == nil || p.configuration.Implementation != schema.LDAPImplementationActiveDirectory ||
+		!ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return err
+	}
+
+	if reason := ldapActiveDirectoryBindErrorReason(err); reason != nil {
+		return wrapLDAPPasswordPolicyError(reason, err)
+	}
+
+	return err
+}
+
+// ldapPasswordPolicyControlReason returns the reason given by the password policy controls of the response of a bind
+// or nil if there is none.
+func ldapPasswordPolicyControlReason(result *ldap.SimpleBindResult) error {
+	if result == nil {
+		return nil
+	}
+
+	if control, ok := ldap.FindControl(result.Controls, ldap.ControlTypeBeheraPasswordPolicy).(*ldap.ControlBeheraPasswordPolicy); ok {
+		switch control.Error {
+		case ldapPasswordPolicyPasswordExpired:
+			return ErrPasswordExpired
+		case ldapPasswordPolicyAccountLocked:
+			return ErrAccountLocked
+		case ldapPasswordPolicyChangeAfterReset:
+			return ErrPasswordMustChange
+		}
+
+		// The user has been authenticated with one of the grace authentications of an expired password.
+		if control.Grace >= 0 {
+			return ErrPasswordExpired
+		}
+	}
+
+	if control, ok := ldap.FindControl(result.Controls, ldap.ControlTypeVChuPasswordMustChange).(*ldap.ControlVChuPasswordMustChange); ok && control.MustChange {
+		return ErrPasswordMustChange
+	}
+
+	return nil
+}
+
+// ldapActiveDirectoryBindErrorReason returns the reason given by the diagnostic message of a bind error of Active
+// Directory or nil if there is none. Only the codes Active Directory returns when the password is correct are mapped
+// so the reason isn't disclosed to anyone not knowing the password.
+func ldapActiveDirectoryBindErrorReason(err error) error {
+	matches := ldapActiveDirectoryDataRegexp.FindStringSubmatch(err.Error())
+	if matches == nil {
+		return nil
+	}
+
+	switch matches[1] {
+	case ldapActiveDirectoryPasswordExpired:
+		return ErrPasswordExpired
+	case ldapActiveDirectoryPasswordMustChange:
+		return ErrPasswordMustChange
+	case ldapActiveDirectoryAccountDisabled, ldapActiveDirectoryAccountExpired:
+		return ErrAccountDisabled
+	}
+
+	return nil
+}
+
+func wrapLDAPPasswordPolicyError(reason, err error) error {
+	if err == nil {
+		return reason
+	}
+
+	return fmt.Errorf("%w: %v", reason, err)
+}
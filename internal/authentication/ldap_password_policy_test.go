@@ -0,0 +1,167 @@
+package authentication
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func newPasswordPolicyTestProvider(ctrl *gomock.Controller, implementation string,
+	bindResult *ldap.SimpleBindResult, bindErr error) *LDAPUserProvider {
+	mockFactory := NewMockLDAPConnectionFactory(ctrl)
+	mockConn := NewMockLDAPConnection(ctrl)
+
+	ldapClient := newLDAPUserProvider(
+		schema.LDAPAuthenticationBackendConfiguration{
+			Implementation:       implementation,
+			URL:                  "ldap://127.0.0.1:389",
+			User:                 "cn=admin,dc=example,dc=com",
+			Password:             "password",
+			UsernameAttribute:    "uid",
+			MailAttribute:        "mail",
+			DisplayNameAttribute: "displayname",
+			UsersFilter:          "uid={input}",
+			BaseDN:               "dc=example,dc=com",
+		},
+		nil,
+		mockFactory)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldap://127.0.0.1:389"), gomock.Any()).
+			Return(mockConn, nil),
+		mockConn.EXPECT().
+			Bind(gomock.Eq("cn=admin,dc=example,dc=com"), gomock.Eq("password")).
+			Return(nil),
+		mockConn.EXPECT().
+			Search(gomock.Any()).
+			Return(&ldap.SearchResult{
+				Entries: []*ldap.Entry{
+					{
+						DN:         "uid=john,dc=example,dc=com",
+						Attributes: []*ldap.EntryAttribute{{Name: "uid", Values: []string{"john"}}},
+					},
+				},
+			}, nil),
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldap://127.0.0.1:389"), gomock.Any()).
+			Return(mockConn, nil),
+		mockConn.EXPECT().
+			SimpleBind(gomock.Eq(newLDAPPasswordPolicyBindRequest("uid=john,dc=example,dc=com", "password"))).
+			Return(bindResult, bindErr),
+	)
+
+	mockConn.EXPECT().Close().AnyTimes()
+
+	return ldapClient
+}
+
+func TestShouldReturnReasonOfPasswordPolicyControl(t *testing.T) {
+	testCases := []struct {
+		name     string
+		control  *ldap.ControlBeheraPasswordPolicy
+		bindErr  error
+		expected error
+	}{
+		{"PasswordExpired", &ldap.ControlBeheraPasswordPolicy{Expire: -1, Grace: -1, Error: ldapPasswordPolicyPasswordExpired},
+			ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("")), ErrPasswordExpired},
+		{"AccountLocked", &ldap.ControlBeheraPasswordPolicy{Expire: -1, Grace: -1, Error: ldapPasswordPolicyAccountLocked},
+			ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("")), ErrAccountLocked},
+		{"ChangeAfterReset", &ldap.ControlBeheraPasswordPolicy{Expire: -1, Grace: -1, Error: ldapPasswordPolicyChangeAfterReset},
+			nil, ErrPasswordMustChange},
+		{"GraceAuthentication", &ldap.ControlBeheraPasswordPolicy{Expire: -1, Grace: 2, Error: -1},
+			nil, ErrPasswordExpired},
+		{"ExpirationWarning", &ldap.ControlBeheraPasswordPolicy{Expire: 3600, Grace: -1, Error: -1},
+			nil, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ldapClient := newPasswordPolicyTestProvider(ctrl, schema.LDAPImplementationCustom,
+				&ldap.SimpleBindResult{Controls: []ldap.Control{tc.control}}, tc.bindErr)
+
+			valid, err := ldapClient.CheckUserPassword("john", "password")
+
+			if tc.expected == nil {
+				assert.True(t, valid)
+				assert.NoError(t, err)
+			} else {
+				assert.False(t, valid)
+				assert.True(t, errors.Is(err, tc.expected))
+			}
+		})
+	}
+}
+
+func TestShouldReturnPasswordMustChangeOfNetscapeControl(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ldapClient := newPasswordPolicyTestProvider(ctrl, schema.LDAPImplementationCustom,
+		&ldap.SimpleBindResult{Controls: []ldap.Control{&ldap.ControlVChuPasswordMustChange{MustChange: true}}}, nil)
+
+	valid, err := ldapClient.CheckUserPassword("john", "password")
+
+	assert.False(t, valid)
+	assert.True(t, errors.Is(err, ErrPasswordMustChange))
+}
+
+func TestShouldReturnReasonOfActiveDirectoryBindError(t *testing.T) {
+	testCases := []struct {
+		code     string
+		expected error
+	}{
+		{ldapActiveDirectoryPasswordExpired, ErrPasswordExpired},
+		{ldapActiveDirectoryPasswordMustChange, ErrPasswordMustChange},
+		{ldapActiveDirectoryAccountDisabled, ErrAccountDisabled},
+		{ldapActiveDirectoryAccountExpired, ErrAccountDisabled},
+		{"52e", nil},
+		{"775", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.code, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bindErr := ldap.NewError(ldap.LDAPResultInvalidCredentials,
+				errors.New("80090308: LdapErr: DSID-0C09042A, comment: AcceptSecurityContext error, data "+tc.code+", v3839"))
+
+			ldapClient := newPasswordPolicyTestProvider(ctrl, schema.LDAPImplementationActiveDirectory,
+				&ldap.SimpleBindResult{}, bindErr)
+
+			valid, err := ldapClient.CheckUserPassword("john", "password")
+
+			assert.False(t, valid)
+			assert.Error(t, err)
+
+			if tc.expected == nil {
+				for _, reason := range []error{ErrPasswordExpired, ErrPasswordMustChange, ErrAccountLocked, ErrAccountDisabled} {
+					assert.False(t, errors.Is(err, reason))
+				}
+			} else {
+				assert.True(t, errors.Is(err, tc.expected))
+			}
+		})
+	}
+}
+
+func TestShouldIgnoreActiveDirectoryBindErrorWithCustomImplementation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ldapClient := newPasswordPolicyTestProvider(ctrl, schema.LDAPImplementationCustom, &ldap.SimpleBindResult{},
+		ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("AcceptSecurityContext error, data 775, v3839")))
+
+	_, err := ldapClient.CheckUserPassword("john", "password")
+
+	assert.False(t, errors.Is(err, ErrAccountLocked))
+}
@@ -0,0 +1,106 @@
+package authentication
+
+import (
+	"crypto/tls"
+	"expvar"
+	"net/url"
+	"sync/atomic"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// ldapServer is an LDAP server of the configuration along with its health.
+type ldapServer struct {
+	url       string
+	tlsConfig *tls.Config
+	dialOpts  ldap.DialOpt
+	healthy   int32
+}
+
+// isHealthy returns true if the last connection to the server succeeded.
+func (s *ldapServer) isHealthy() bool {
+	return atomic.LoadInt32(&s.healthy) == 1
+}
+
+// setHealthy sets the health of the server and returns true if it changed.
+func (s *ldapServer) setHealthy(healthy bool) (changed bool) {
+	var value int32
+
+	if healthy {
+		value = 1
+	}
+
+	metric := new(expvar.Int)
+	metric.Set(int64(value))
+	ldapServersMetrics.Set(s.url, metric)
+
+	return atomic.SwapInt32(&s.healthy, value) != value
+}
+
+// ldapServers selects the LDAP servers the connections are made to.
+type ldapServers struct {
+	servers  []*ldapServer
+	strategy string
+	next     uint32
+}
+
+// newLDAPServers creates the LDAP servers of the given URLs, they are healthy until a connection fails. The server name
+// of the TLS configuration is the hostname of each server when it's not configured and there are multiple servers.
+func newLDAPServers(urls []string, strategy string, tlsConfig *tls.Config) *ldapServers {
+	servers := &ldapServers{
+		servers:  make([]*ldapServer, len(urls)),
+		strategy: strategy,
+	}
+
+	for i, rawURL := range urls {
+		server := &ldapServer{
+			url:       rawURL,
+			tlsConfig: tlsConfig,
+		}
+
+		if tlsConfig != nil && tlsConfig.ServerName == "" && len(urls) > 1 {
+			if parsedURL, err := url.Parse(rawURL); err == nil {
+				server.tlsConfig = tlsConfig.Clone()
+				server.tlsConfig.ServerName = parsedURL.Hostname()
+			}
+		}
+
+		if server.tlsConfig != nil {
+			server.dialOpts = ldap.DialWithTLSConfig(server.tlsConfig)
+		}
+
+		server.setHealthy(true)
+
+		servers.servers[i] = server
+	}
+
+	return servers
+}
+
+// candidates returns the servers in the order the connections are attempted. The healthy servers come first in the
+// configured order or in turn depending on the strategy, the unhealthy servers are still attempted as a last resort.
+func (s *ldapServers) candidates() []*ldapServer {
+	n := len(s.servers)
+	start := 0
+
+	if s.strategy == schema.LDAPURLStrategyRoundRobin && n > 1 {
+		start = int((atomic.AddUint32(&s.next, 1) - 1) % uint32(n))
+	}
+
+	healthy := make([]*ldapServer, 0, n)
+	unhealthy := make([]*ldapServer, 0, n)
+
+	for i := 0; i < n; i++ {
+		server := s.servers[(start+i)%n]
+
+		if server.isHealthy() {
+			healthy = append(healthy, server)
+		} else {
+			unhealthy = append(unhealthy, server)
+		}
+	}
+
+	return append(healthy, unhealthy...)
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/x509"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/go-ldap/ldap/v3"
 	"github.com/sirupsen/logrus"
@@ -19,7 +20,8 @@ import (
 type LDAPUserProvider struct {
 	configuration     schema.LDAPAuthenticationBackendConfiguration
 	tlsConfig         *tls.Config
-	dialOpts          ldap.DialOpt
+	servers           *ldapServers
+	pool              *ldapConnectionPool
 	logger            *logrus.Logger
 	connectionFactory LDAPConnectionFactory
 	usersBaseDN       string
@@ -37,11 +39,24 @@ func NewLDAPUserProvider(configuration schema.AuthenticationBackendConfiguration
 		return provider, err
 	}
 
-	if !provider.supportExtensionPasswdModify && !configuration.DisableResetPassword &&
-		provider.configuration.Implementation != schema.LDAPImplementationActiveDirectory {
-		provider.logger.Warnf("Your LDAP server implementation may not support a method for password hashing " +
-			"known to Authelia, it's strongly recommended you ensure your directory server hashes the password " +
-			"attribute when users reset their password via Authelia.")
+	if !configuration.DisableResetPassword {
+		switch provider.passwordChangeStrategy() {
+		case schema.LDAPPasswordChangeStrategyReplace:
+			provider.logger.Warnf("Your LDAP server implementation may not support a method for password hashing " +
+				"known to Authelia, it's strongly recommended you ensure your directory server hashes the password " +
+				"attribute when users reset their password via Authelia.")
+		case schema.LDAPPasswordChangeStrategyPasswordModify:
+			if !provider.supportExtensionPasswdModify {
+				provider.logger.Warnf("Your LDAP server doesn't advertise the support of the password modify " +
+					"extended operation, the password changes may fail.")
+			}
+		}
+	}
+
+	if len(provider.servers.servers) > 1 {
+		if interval, _ := utils.ParseDurationString(provider.configuration.HealthCheckInterval); interval > 0 {
+			go provider.checkServersHealth(interval)
+		}
 	}
 
 	return provider, nil
@@ -54,24 +69,34 @@ func newLDAPUserProvider(configuration schema.LDAPAuthenticationBackendConfigura
 
 	tlsConfig := utils.NewTLSConfig(configuration.TLS, tls.VersionTLS12, certPool)
 
-	var dialOpts ldap.DialOpt
-
-	if tlsConfig != nil {
-		dialOpts = ldap.DialWithTLSConfig(tlsConfig)
-	}
-
 	if factory == nil {
 		factory = NewLDAPConnectionFactoryImpl()
 	}
 
+	urls := configuration.URLs
+	if len(urls) == 0 {
+		urls = []string{configuration.URL}
+	}
+
 	provider = &LDAPUserProvider{
 		configuration:     configuration,
 		tlsConfig:         tlsConfig,
-		dialOpts:          dialOpts,
+		servers:           newLDAPServers(urls, configuration.URLStrategy, tlsConfig),
 		logger:            logging.Logger(),
 		connectionFactory: factory,
 	}
 
+	if configuration.Pool.MaxConnections > 0 {
+		timeout, err := utils.ParseDurationString(configuration.Pool.Timeout)
+		if err != nil || timeout <= 0 {
+			timeout, _ = utils.ParseDurationString(schema.DefaultLDAPAuthenticationBackendConfiguration.Pool.Timeout)
+		}
+
+		provider.pool = newLDAPConnectionPool(configuration.Pool.MaxConnections, timeout, func() (LDAPConnection, error) {
+			return provider.connect(provider.configuration.User, provider.configuration.Password)
+		})
+	}
+
 	provider.parseDynamicConfiguration()
 
 	return provider
@@ -102,7 +127,7 @@ func (p *LDAPUserProvider) parseDynamicConfiguration() {
 }
 
 func (p *LDAPUserProvider) checkServer() (err error) {
-	conn, err := p.connect(p.configuration.User, p.configuration.Password)
+	conn, err := p.connectBindUser()
 	if err != nil {
 		return err
 	}
@@ -140,28 +165,103 @@ func (p *LDAPUserProvider) checkServer() (err error) {
 	return nil
 }
 
+// connect opens a connection bound to the given user. The connection is made to the first server of the candidates
+// which accepts it, the servers which don't are marked as unhealthy.
 func (p *LDAPUserProvider) connect(userDN string, password string) (LDAPConnection, error) {
-	conn, err := p.connectionFactory.DialURL(p.configuration.URL, p.dialOpts)
+	return p.connectWithBind(func(conn LDAPConnection) error {
+		return conn.Bind(userDN, password)
+	})
+}
+
+// connectWithBind opens a connection bound with the given bind function like connect.
+func (p *LDAPUserProvider) connectWithBind(bind func(conn LDAPConnection) error) (LDAPConnection, error) {
+	var err error
+
+	for _, server := range p.servers.candidates() {
+		var conn LDAPConnection
+
+		if conn, err = p.dial(server); err != nil {
+			p.setServerHealth(server, err)
+			continue
+		}
+
+		p.setServerHealth(server, nil)
+
+		if err = bind(conn); err != nil {
+			conn.Close()
+
+			return nil, err
+		}
+
+		return conn, nil
+	}
+
+	return nil, err
+}
+
+// connectBindUser returns a connection bound to the bind user of the configuration, it comes from the pool when it's
+// enabled.
+func (p *LDAPUserProvider) connectBindUser() (LDAPConnection, error) {
+	if p.pool != nil {
+		return p.pool.Get()
+	}
+
+	return p.connect(p.configuration.User, p.configuration.Password)
+}
+
+// dial opens a connection to the server, upgraded to TLS when StartTLS is enabled.
+func (p *LDAPUserProvider) dial(server *ldapServer) (LDAPConnection, error) {
+	ldapMetrics.Add(ldapMetricDials, 1)
+
+	conn, err := p.connectionFactory.DialURL(server.url, server.dialOpts)
 	if err != nil {
+		ldapMetrics.Add(ldapMetricDialErrors, 1)
 		return nil, err
 	}
 
 	if p.configuration.StartTLS {
-		if err := conn.StartTLS(p.tlsConfig); err != nil {
+		if err := conn.StartTLS(server.tlsConfig); err != nil {
+			ldapMetrics.Add(ldapMetricDialErrors, 1)
 			return nil, err
 		}
 	}
 
-	if err := conn.Bind(userDN, password); err != nil {
-		return nil, err
+	return conn, nil
+}
+
+// setServerHealth marks the server as unhealthy when the error is not nil and as healthy otherwise.
+func (p *LDAPUserProvider) setServerHealth(server *ldapServer, err error) {
+	if !server.setHealthy(err == nil) {
+		return
 	}
 
-	return conn, nil
+	if err != nil {
+		p.logger.Errorf("LDAP server %s is unhealthy: %s", server.url, err)
+	} else {
+		p.logger.Infof("LDAP server %s is healthy", server.url)
+	}
+}
+
+// checkServersHealth connects to every server at the given interval to update their health.
+func (p *LDAPUserProvider) checkServersHealth(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, server := range p.servers.servers {
+			conn, err := p.dial(server)
+			if err == nil {
+				conn.Close()
+			}
+
+			p.setServerHealth(server, err)
+		}
+	}
 }
 
 // CheckUserPassword checks if provided password matches for the given user.
 func (p *LDAPUserProvider) CheckUserPassword(inputUsername string, password string) (bool, error) {
-	conn, err := p.connect(p.configuration.User, p.configuration.Password)
+	conn, err := p.connectBindUser()
 	if err != nil {
 		return false, err
 	}
@@ -172,9 +272,13 @@ func (p *LDAPUserProvider) CheckUserPassword(inputUsername string, password stri
 		return false, err
 	}
 
-	userConn, err := p.connect(profile.DN, password)
+	userConn, err := p.connectWithBind(func(conn LDAPConnection) error {
+		result, err := conn.SimpleBind(newLDAPPasswordPolicyBindRequest(profile.DN, password))
+
+		return p.checkPasswordPolicy(result, err)
+	})
 	if err != nil {
-		return false, fmt.Errorf("Authentication of user %s failed. Cause: %s", inputUsername, err)
+		return false, fmt.Errorf("Authentication of user %s failed. Cause: %w", inputUsername, err)
 	}
 	defer userConn.Close()
 
@@ -195,6 +299,7 @@ type ldapUserProfile struct {
 	Emails      []string
 	DisplayName string
 	Username    string
+	MemberOf    []string
 }
 
 func (p *LDAPUserProvider) resolveUsersFilter(userFilter string, inputUsername string) string {
@@ -216,6 +321,10 @@ func (p *LDAPUserProvider) getUserProfile(conn LDAPConnection, inputUsername str
 		p.configuration.MailAttribute,
 		p.configuration.UsernameAttribute}
 
+	if p.configuration.GroupSearchMode == schema.LDAPGroupSearchModeMemberOf {
+		attributes = append(attributes, p.configuration.MemberOfAttribute)
+	}
+
 	// Search for the given username.
 	searchRequest := ldap.NewSearchRequest(
 		p.usersBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
@@ -256,6 +365,10 @@ func (p *LDAPUserProvider) getUserProfile(conn LDAPConnection, inputUsername str
 
 			userProfile.Username = attr.Values[0]
 		}
+
+		if p.configuration.GroupSearchMode == schema.LDAPGroupSearchModeMemberOf && strings.EqualFold(attr.Name, p.configuration.MemberOfAttribute) {
+			userProfile.MemberOf = attr.Values
+		}
 	}
 
 	if userProfile.DN == "" {
@@ -283,7 +396,7 @@ func (p *LDAPUserProvider) resolveGroupsFilter(inputUsername string, profile *ld
 
 // GetDetails retrieve the groups a user belongs to.
 func (p *LDAPUserProvider) GetDetails(inputUsername string) (*UserDetails, error) {
-	conn, err := p.connect(p.configuration.User, p.configuration.Password)
+	conn, err := p.connectBindUser()
 	if err != nil {
 		return nil, err
 	}
@@ -294,45 +407,204 @@ func (p *LDAPUserProvider) GetDetails(inputUsername string) (*UserDetails, error
 		return nil, err
 	}
 
-	groupsFilter, err := p.resolveGroupsFilter(inputUsername, profile)
+	groups, err := p.getGroups(conn, inputUsername, profile)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to create group filter for user %s. Cause: %s", inputUsername, err)
+		return nil, err
 	}
 
-	// Search for the given username.
+	return &UserDetails{
+		Username:    profile.Username,
+		DisplayName: profile.DisplayName,
+		Emails:      profile.Emails,
+		Groups:      groups,
+	}, nil
+}
+
+// getGroups retrieves the names of the groups of the user with the group search mode of the configuration.
+func (p *LDAPUserProvider) getGroups(conn LDAPConnection, inputUsername string, profile *ldapUserProfile) ([]string, error) {
+	switch p.configuration.GroupSearchMode {
+	case schema.LDAPGroupSearchModeRecursive:
+		return p.getGroupsRecursive(conn, inputUsername, profile)
+	case schema.LDAPGroupSearchModeMemberOf:
+		return p.getGroupsMemberOf(conn, inputUsername, profile)
+	case schema.LDAPGroupSearchModeInChain:
+		groupsFilter := strings.ReplaceAll(ldapInChainGroupsFilter, "{dn}", ldap.EscapeFilter(profile.DN))
+
+		sr, err := p.searchGroups(conn, inputUsername, groupsFilter)
+		if err != nil {
+			return nil, err
+		}
+
+		groups := make([]string, 0, len(sr.Entries))
+
+		for _, entry := range sr.Entries {
+			groups = append(groups, entry.GetEqualFoldAttributeValues(p.configuration.GroupNameAttribute)...)
+		}
+
+		return groups, nil
+	default:
+		groupsFilter, err := p.resolveGroupsFilter(inputUsername, profile)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to create group filter for user %s. Cause: %s", inputUsername, err)
+		}
+
+		sr, err := p.searchGroups(conn, inputUsername, groupsFilter)
+		if err != nil {
+			return nil, err
+		}
+
+		groups := make([]string, 0)
+
+		for _, res := range sr.Entries {
+			if len(res.Attributes) == 0 {
+				p.logger.Warningf("No groups retrieved from LDAP for user %s", inputUsername)
+				break
+			}
+			// Append all values of the document. Normally there should be only one per document.
+			groups = append(groups, res.Attributes[0].Values...)
+		}
+
+		return groups, nil
+	}
+}
+
+// getGroupsRecursive retrieves the groups of the user along with the groups they are nested in. The groups filter is
+// used to search the groups of the user and then the groups of each group found, a group found twice is skipped so
+// the cycles of the nested groups end the search.
+func (p *LDAPUserProvider) getGroupsRecursive(conn LDAPConnection, inputUsername string, profile *ldapUserProfile) ([]string, error) {
+	groups := make([]string, 0)
+	visited := map[string]bool{strings.ToLower(profile.DN): true}
+	members := []string{profile.DN}
+
+	for len(members) != 0 {
+		member := members[0]
+		members = members[1:]
+
+		groupsFilter := strings.ReplaceAll(p.configuration.GroupsFilter, "{dn}", ldap.EscapeFilter(member))
+
+		sr, err := p.searchGroups(conn, inputUsername, groupsFilter)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range sr.Entries {
+			dn := strings.ToLower(entry.DN)
+
+			if visited[dn] {
+				p.logger.Tracef("Group %s of %s was already found while resolving the nested groups of user %s", entry.DN, member, inputUsername)
+				continue
+			}
+
+			visited[dn] = true
+
+			groups = append(groups, entry.GetEqualFoldAttributeValues(p.configuration.GroupNameAttribute)...)
+			members = append(members, entry.DN)
+		}
+	}
+
+	return groups, nil
+}
+
+// getGroupsMemberOf retrieves the groups listed in the member of attribute of the user. The name of a group is read
+// from its DN when the first RDN is the group name attribute, the group is searched otherwise.
+func (p *LDAPUserProvider) getGroupsMemberOf(conn LDAPConnection, inputUsername string, profile *ldapUserProfile) ([]string, error) {
+	groups := make([]string, 0, len(profile.MemberOf))
+
+	for _, dn := range profile.MemberOf {
+		if name, ok := p.groupNameFromDN(dn); ok {
+			groups = append(groups, name)
+			continue
+		}
+
+		searchRequest := ldap.NewSearchRequest(
+			dn, ldap.ScopeBaseObject, ldap.NeverDerefAliases,
+			1, 0, false, "(objectClass=*)", []string{p.configuration.GroupNameAttribute}, nil,
+		)
+
+		sr, err := conn.Search(searchRequest)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to retrieve group %s of user %s. Cause: %s", dn, inputUsername, err)
+		}
+
+		for _, entry := range sr.Entries {
+			groups = append(groups, entry.GetEqualFoldAttributeValues(p.configuration.GroupNameAttribute)...)
+		}
+	}
+
+	return groups, nil
+}
+
+func (p *LDAPUserProvider) groupNameFromDN(dn string) (name string, ok bool) {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil || len(parsed.RDNs) == 0 {
+		return "", false
+	}
+
+	for _, attribute := range parsed.RDNs[0].Attributes {
+		if strings.EqualFold(attribute.Type, p.configuration.GroupNameAttribute) {
+			return attribute.Value, true
+		}
+	}
+
+	return "", false
+}
+
+func (p *LDAPUserProvider) searchGroups(conn LDAPConnection, inputUsername, groupsFilter string) (*ldap.SearchResult, error) {
 	searchGroupRequest := ldap.NewSearchRequest(
 		p.groupsBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
 		0, 0, false, groupsFilter, []string{p.configuration.GroupNameAttribute}, nil,
 	)
 
 	sr, err := conn.Search(searchGroupRequest)
-
 	if err != nil {
 		return nil, fmt.Errorf("Unable to retrieve groups of user %s. Cause: %s", inputUsername, err)
 	}
 
-	groups := make([]string, 0)
+	return sr, nil
+}
 
-	for _, res := range sr.Entries {
-		if len(res.Attributes) == 0 {
-			p.logger.Warningf("No groups retrieved from LDAP for user %s", inputUsername)
-			break
+// GetAttributes retrieves the values of the given attributes of a user, attributes without a value are omitted.
+func (p *LDAPUserProvider) GetAttributes(inputUsername string, attributes []string) (map[string][]string, error) {
+	conn, err := p.connectBindUser()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	userFilter := p.resolveUsersFilter(p.configuration.UsersFilter, inputUsername)
+
+	searchRequest := ldap.NewSearchRequest(
+		p.usersBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		1, 0, false, userFilter, attributes, nil,
+	)
+
+	sr, err := conn.Search(searchRequest)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to retrieve attributes of user %s. Cause: %s", inputUsername, err)
+	}
+
+	if len(sr.Entries) == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	if len(sr.Entries) > 1 {
+		return nil, fmt.Errorf("Multiple users %s found", inputUsername)
+	}
+
+	values := make(map[string][]string, len(attributes))
+
+	for _, attribute := range attributes {
+		if value := sr.Entries[0].GetEqualFoldAttributeValues(attribute); len(value) != 0 {
+			values[attribute] = value
 		}
-		// Append all values of the document. Normally there should be only one per document.
-		groups = append(groups, res.Attributes[0].Values...)
 	}
 
-	return &UserDetails{
-		Username:    profile.Username,
-		DisplayName: profile.DisplayName,
-		Emails:      profile.Emails,
-		Groups:      groups,
-	}, nil
+	return values, nil
 }
 
 // UpdatePassword update the password of the given user.
 func (p *LDAPUserProvider) UpdatePassword(inputUsername string, newPassword string) error {
-	conn, err := p.connect(p.configuration.User, p.configuration.Password)
+	conn, err := p.connectBindUser()
 	if err != nil {
 		return fmt.Errorf("Unable to update password. Cause: %s", err)
 	}
@@ -344,8 +616,8 @@ func (p *LDAPUserProvider) UpdatePassword(inputUsername string, newPassword stri
 		return fmt.Errorf("Unable to update password. Cause: %s", err)
 	}
 
-	switch {
-	case p.supportExtensionPasswdModify:
+	switch p.passwordChangeStrategy() {
+	case schema.LDAPPasswordChangeStrategyPasswordModify:
 		modifyRequest := ldap.NewPasswordModifyRequest(
 			profile.DN,
 			"",
@@ -353,7 +625,7 @@ func (p *LDAPUserProvider) UpdatePassword(inputUsername string, newPassword stri
 		)
 
 		err = conn.PasswordModify(modifyRequest)
-	case p.configuration.Implementation == schema.LDAPImplementationActiveDirectory:
+	case schema.LDAPPasswordChangeStrategyUnicodePwd:
 		modifyRequest := ldap.NewModifyRequest(profile.DN, nil)
 		utf16 := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
 		// The password needs to be enclosed in quotes
@@ -369,9 +641,30 @@ func (p *LDAPUserProvider) UpdatePassword(inputUsername string, newPassword stri
 		err = conn.Modify(modifyRequest)
 	}
 
+	// The directory rejects the passwords which don't meet its password policy with a constraint violation.
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultConstraintViolation) {
+		return fmt.Errorf("Unable to update password. Cause: %w: %s", ErrPasswordPolicy, err)
+	}
+
 	if err != nil {
 		return fmt.Errorf("Unable to update password. Cause: %s", err)
 	}
 
 	return nil
 }
+
+// passwordChangeStrategy returns the password change strategy of the configuration, the auto strategy is resolved
+// given the extensions supported by the LDAP server and the implementation.
+func (p *LDAPUserProvider) passwordChangeStrategy() string {
+	switch {
+	case p.configuration.PasswordChangeStrategy != "" &&
+		p.configuration.PasswordChangeStrategy != schema.LDAPPasswordChangeStrategyAuto:
+		return p.configuration.PasswordChangeStrategy
+	case p.supportExtensionPasswdModify:
+		return schema.LDAPPasswordChangeStrategyPasswordModify
+	case p.configuration.Implementation == schema.LDAPImplementationActiveDirectory:
+		return schema.LDAPPasswordChangeStrategyUnicodePwd
+	default:
+		return schema.LDAPPasswordChangeStrategyReplace
+	}
+}
@@ -3,6 +3,7 @@ package authentication
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/go-ldap/ldap/v3"
@@ -644,6 +645,68 @@ func TestShouldReturnUsernameFromLDAP(t *testing.T) {
 	assert.Equal(t, details.Username, "John")
 }
 
+func TestShouldReturnUserAttributesFromLDAP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPConnectionFactory(ctrl)
+	mockConn := NewMockLDAPConnection(ctrl)
+
+	ldapClient := newLDAPUserProvider(
+		schema.LDAPAuthenticationBackendConfiguration{
+			URL:                  "ldap://127.0.0.1:389",
+			User:                 "cn=admin,dc=example,dc=com",
+			Password:             "password",
+			UsernameAttribute:    "uid",
+			MailAttribute:        "mail",
+			DisplayNameAttribute: "displayname",
+			UsersFilter:          "uid={input}",
+			AdditionalUsersDN:    "ou=users",
+			BaseDN:               "dc=example,dc=com",
+		},
+		nil,
+		mockFactory)
+
+	dialURL := mockFactory.EXPECT().
+		DialURL(gomock.Eq("ldap://127.0.0.1:389"), gomock.Any()).
+		Return(mockConn, nil)
+
+	connBind := mockConn.EXPECT().
+		Bind(gomock.Eq("cn=admin,dc=example,dc=com"), gomock.Eq("password")).
+		Return(nil)
+
+	connClose := mockConn.EXPECT().Close()
+
+	searchAttributes := mockConn.EXPECT().
+		Search(gomock.Any()).
+		DoAndReturn(func(request *ldap.SearchRequest) (*ldap.SearchResult, error) {
+			assert.Equal(t, "ou=users,dc=example,dc=com", request.BaseDN)
+			assert.Equal(t, "uid=john", request.Filter)
+			assert.Equal(t, []string{"employeeNumber", "preferredLanguage"}, request.Attributes)
+
+			return &ldap.SearchResult{
+				Entries: []*ldap.Entry{
+					{
+						DN: "uid=john,ou=users,dc=example,dc=com",
+						Attributes: []*ldap.EntryAttribute{
+							{
+								Name:   "employeenumber",
+								Values: []string{"1234"},
+							},
+						},
+					},
+				},
+			}, nil
+		})
+
+	gomock.InOrder(dialURL, connBind, searchAttributes, connClose)
+
+	attributes, err := ldapClient.GetAttributes("john", []string{"employeeNumber", "preferredLanguage"})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string][]string{"employeeNumber": {"1234"}}, attributes)
+}
+
 func TestShouldUpdateUserPasswordPasswdModifyExtension(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1010,8 +1073,8 @@ func TestShouldCheckValidUserPassword(t *testing.T) {
 			DialURL(gomock.Eq("ldap://127.0.0.1:389"), gomock.Any()).
 			Return(mockConn, nil),
 		mockConn.EXPECT().
-			Bind(gomock.Eq("uid=test,dc=example,dc=com"), gomock.Eq("password")).
-			Return(nil),
+			SimpleBind(gomock.Eq(newLDAPPasswordPolicyBindRequest("uid=test,dc=example,dc=com", "password"))).
+			Return(&ldap.SimpleBindResult{}, nil),
 		mockConn.EXPECT().Close().Times(2),
 	)
 
@@ -1077,8 +1140,10 @@ func TestShouldCheckInvalidUserPassword(t *testing.T) {
 			DialURL(gomock.Eq("ldap://127.0.0.1:389"), gomock.Any()).
 			Return(mockConn, nil),
 		mockConn.EXPECT().
-			Bind(gomock.Eq("uid=test,dc=example,dc=com"), gomock.Eq("password")).
-			Return(errors.New("Invalid username or password")),
+			SimpleBind(gomock.Eq(newLDAPPasswordPolicyBindRequest("uid=test,dc=example,dc=com", "password"))).
+			Return(nil, errors.New("Invalid username or password")),
+		// The connection of the user whose bind failed is closed before the connection of the bind user.
+		mockConn.EXPECT().Close(),
 		mockConn.EXPECT().Close(),
 	)
 
@@ -1310,3 +1375,285 @@ func TestShouldReturnLDAPSAlreadySecuredWhenStartTLSAttempted(t *testing.T) {
 	_, err := ldapClient.GetDetails("john")
 	assert.EqualError(t, err, "LDAP Result Code 200 \"Network Error\": ldap: already encrypted")
 }
+
+func newNestedGroupsTestProvider(mode string, factory LDAPConnectionFactory) *LDAPUserProvider {
+	return newLDAPUserProvider(
+		schema.LDAPAuthenticationBackendConfiguration{
+			URL:                  "ldap://127.0.0.1:389",
+			User:                 "cn=admin,dc=example,dc=com",
+			Password:             "password",
+			UsernameAttribute:    "uid",
+			MailAttribute:        "mail",
+			DisplayNameAttribute: "displayname",
+			UsersFilter:          "uid={input}",
+			GroupsFilter:         "(member={dn})",
+			GroupSearchMode:      mode,
+			GroupNameAttribute:   "cn",
+			MemberOfAttribute:    "memberOf",
+			AdditionalUsersDN:    "ou=users",
+			BaseDN:               "dc=example,dc=com",
+		},
+		nil,
+		factory)
+}
+
+func newNestedGroupsTestUserResult(attributes ...*ldap.EntryAttribute) *ldap.SearchResult {
+	return &ldap.SearchResult{
+		Entries: []*ldap.Entry{
+			{
+				DN: "uid=john,ou=users,dc=example,dc=com",
+				Attributes: append([]*ldap.EntryAttribute{
+					{
+						Name:   "uid",
+						Values: []string{"john"},
+					},
+				}, attributes...),
+			},
+		},
+	}
+}
+
+func newNestedGroupsTestGroupsResult(dns ...string) *ldap.SearchResult {
+	result := &ldap.SearchResult{}
+
+	for _, dn := range dns {
+		result.Entries = append(result.Entries, ldap.NewEntry(dn, map[string][]string{"cn": {strings.SplitN(strings.TrimPrefix(dn, "cn="), ",", 2)[0]}}))
+	}
+
+	return result
+}
+
+func expectGroupsSearch(t *testing.T, mockConn *MockLDAPConnection, filter string, result *ldap.SearchResult) *gomock.Call {
+	return mockConn.EXPECT().
+		Search(gomock.Any()).
+		DoAndReturn(func(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+			assert.Equal(t, filter, searchRequest.Filter)
+
+			return result, nil
+		})
+}
+
+func TestShouldResolveNestedGroupsRecursively(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPConnectionFactory(ctrl)
+	mockConn := NewMockLDAPConnection(ctrl)
+
+	ldapClient := newNestedGroupsTestProvider(schema.LDAPGroupSearchModeRecursive, mockFactory)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldap://127.0.0.1:389"), gomock.Any()).
+			Return(mockConn, nil),
+		mockConn.EXPECT().
+			Bind(gomock.Eq("cn=admin,dc=example,dc=com"), gomock.Eq("password")).
+			Return(nil),
+		mockConn.EXPECT().
+			Search(gomock.Any()).
+			Return(newNestedGroupsTestUserResult(), nil),
+		expectGroupsSearch(t, mockConn, "(member=uid=john,ou=users,dc=example,dc=com)",
+			newNestedGroupsTestGroupsResult("cn=dev,ou=groups,dc=example,dc=com")),
+		expectGroupsSearch(t, mockConn, "(member=cn=dev,ou=groups,dc=example,dc=com)",
+			newNestedGroupsTestGroupsResult("cn=engineering,ou=groups,dc=example,dc=com")),
+		// The engineering group is a member of the dev group which is a cycle.
+		expectGroupsSearch(t, mockConn, "(member=cn=engineering,ou=groups,dc=example,dc=com)",
+			newNestedGroupsTestGroupsResult("CN=dev,ou=groups,dc=example,dc=com", "cn=staff,ou=groups,dc=example,dc=com")),
+		expectGroupsSearch(t, mockConn, "(member=cn=staff,ou=groups,dc=example,dc=com)",
+			newNestedGroupsTestGroupsResult()),
+		mockConn.EXPECT().Close(),
+	)
+
+	details, err := ldapClient.GetDetails("john")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"dev", "engineering", "staff"}, details.Groups)
+}
+
+func TestShouldResolveGroupsFromMemberOfAttribute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPConnectionFactory(ctrl)
+	mockConn := NewMockLDAPConnection(ctrl)
+
+	ldapClient := newNestedGroupsTestProvider(schema.LDAPGroupSearchModeMemberOf, mockFactory)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldap://127.0.0.1:389"), gomock.Any()).
+			Return(mockConn, nil),
+		mockConn.EXPECT().
+			Bind(gomock.Eq("cn=admin,dc=example,dc=com"), gomock.Eq("password")).
+			Return(nil),
+		mockConn.EXPECT().
+			Search(gomock.Any()).
+			DoAndReturn(func(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+				assert.Contains(t, searchRequest.Attributes, "memberOf")
+
+				return newNestedGroupsTestUserResult(&ldap.EntryAttribute{
+					Name:   "memberof",
+					Values: []string{"CN=Admins,OU=Groups,DC=example,DC=com", "ipaUniqueID=1234,cn=groups,dc=example,dc=com"},
+				}), nil
+			}),
+		mockConn.EXPECT().
+			Search(gomock.Any()).
+			DoAndReturn(func(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+				assert.Equal(t, "ipaUniqueID=1234,cn=groups,dc=example,dc=com", searchRequest.BaseDN)
+				assert.Equal(t, ldap.ScopeBaseObject, searchRequest.Scope)
+
+				return newNestedGroupsTestGroupsResult("cn=dev,cn=groups,dc=example,dc=com"), nil
+			}),
+		mockConn.EXPECT().Close(),
+	)
+
+	details, err := ldapClient.GetDetails("john")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Admins", "dev"}, details.Groups)
+}
+
+func TestShouldResolveGroupsWithMatchingRuleInChain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPConnectionFactory(ctrl)
+	mockConn := NewMockLDAPConnection(ctrl)
+
+	ldapClient := newNestedGroupsTestProvider(schema.LDAPGroupSearchModeInChain, mockFactory)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldap://127.0.0.1:389"), gomock.Any()).
+			Return(mockConn, nil),
+		mockConn.EXPECT().
+			Bind(gomock.Eq("cn=admin,dc=example,dc=com"), gomock.Eq("password")).
+			Return(nil),
+		mockConn.EXPECT().
+			Search(gomock.Any()).
+			Return(newNestedGroupsTestUserResult(), nil),
+		expectGroupsSearch(t, mockConn, "(member:1.2.840.113556.1.4.1941:=uid=john,ou=users,dc=example,dc=com)",
+			newNestedGroupsTestGroupsResult("cn=dev,ou=groups,dc=example,dc=com", "cn=engineering,ou=groups,dc=example,dc=com")),
+		mockConn.EXPECT().Close(),
+	)
+
+	details, err := ldapClient.GetDetails("john")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"dev", "engineering"}, details.Groups)
+}
+
+func newPasswordChangeTestProvider(ctrl *gomock.Controller, implementation, strategy string,
+	update func(conn *MockLDAPConnection) *gomock.Call) *LDAPUserProvider {
+	mockFactory := NewMockLDAPConnectionFactory(ctrl)
+	mockConn := NewMockLDAPConnection(ctrl)
+
+	ldapClient := newLDAPUserProvider(
+		schema.LDAPAuthenticationBackendConfiguration{
+			Implementation:         implementation,
+			PasswordChangeStrategy: strategy,
+			URL:                    "ldap://127.0.0.1:389",
+			User:                   "uid=admin,dc=example,dc=com",
+			Password:               "password",
+			UsernameAttribute:      "uid",
+			MailAttribute:          "mail",
+			DisplayNameAttribute:   "displayName",
+			UsersFilter:            "uid={input}",
+			BaseDN:                 "dc=example,dc=com",
+		},
+		nil,
+		mockFactory)
+
+	ldapClient.supportExtensionPasswdModify = true
+
+	gomock.InOrder(
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldap://127.0.0.1:389"), gomock.Any()).
+			Return(mockConn, nil),
+		mockConn.EXPECT().
+			Bind(gomock.Eq("uid=admin,dc=example,dc=com"), gomock.Eq("password")).
+			Return(nil),
+		mockConn.EXPECT().
+			Search(gomock.Any()).
+			Return(&ldap.SearchResult{
+				Entries: []*ldap.Entry{
+					{
+						DN:         "uid=test,dc=example,dc=com",
+						Attributes: []*ldap.EntryAttribute{{Name: "uid", Values: []string{"john"}}},
+					},
+				},
+			}, nil),
+		update(mockConn),
+		mockConn.EXPECT().Close(),
+	)
+
+	return ldapClient
+}
+
+func TestShouldUpdatePasswordWithReplaceStrategy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	modifyRequest := ldap.NewModifyRequest("uid=test,dc=example,dc=com", nil)
+	modifyRequest.Replace("userPassword", []string{"password"})
+
+	ldapClient := newPasswordChangeTestProvider(ctrl, schema.LDAPImplementationCustom, schema.LDAPPasswordChangeStrategyReplace,
+		func(conn *MockLDAPConnection) *gomock.Call {
+			return conn.EXPECT().Modify(modifyRequest).Return(nil)
+		})
+
+	err := ldapClient.UpdatePassword("john", "password")
+	require.NoError(t, err)
+}
+
+func TestShouldUpdatePasswordWithUnicodePwdStrategy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	utf16 := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	pwdEncoded, _ := utf16.NewEncoder().String("\"password\"")
+
+	modifyRequest := ldap.NewModifyRequest("uid=test,dc=example,dc=com", nil)
+	modifyRequest.Replace("unicodePwd", []string{pwdEncoded})
+
+	ldapClient := newPasswordChangeTestProvider(ctrl, schema.LDAPImplementationCustom, schema.LDAPPasswordChangeStrategyUnicodePwd,
+		func(conn *MockLDAPConnection) *gomock.Call {
+			return conn.EXPECT().Modify(modifyRequest).Return(nil)
+		})
+
+	err := ldapClient.UpdatePassword("john", "password")
+	require.NoError(t, err)
+}
+
+func TestShouldUpdatePasswordWithPasswordModifyStrategyWhenAuto(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ldapClient := newPasswordChangeTestProvider(ctrl, schema.LDAPImplementationActiveDirectory, schema.LDAPPasswordChangeStrategyAuto,
+		func(conn *MockLDAPConnection) *gomock.Call {
+			return conn.EXPECT().
+				PasswordModify(ldap.NewPasswordModifyRequest("uid=test,dc=example,dc=com", "", "password")).
+				Return(nil)
+		})
+
+	err := ldapClient.UpdatePassword("john", "password")
+	require.NoError(t, err)
+}
+
+func TestShouldReturnPasswordPolicyErrorWhenPasswordIsRejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ldapClient := newPasswordChangeTestProvider(ctrl, schema.LDAPImplementationCustom, schema.LDAPPasswordChangeStrategyPasswordModify,
+		func(conn *MockLDAPConnection) *gomock.Call {
+			return conn.EXPECT().
+				PasswordModify(gomock.Any()).
+				Return(ldap.NewError(ldap.LDAPResultConstraintViolation, errors.New("Password fails quality checking policy")))
+		})
+
+	err := ldapClient.UpdatePassword("john", "password")
+
+	assert.True(t, errors.Is(err, ErrPasswordPolicy))
+	assert.EqualError(t, err, "Unable to update password. Cause: password does not meet the password policy requirements: "+
+		"LDAP Result Code 19 \"Constraint Violation\": Password fails quality checking policy")
+}
@@ -0,0 +1,129 @@
+package authentication
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+// SQLUserStorage is the storage of the users read by the SQL user provider, it's implemented by the storage providers.
+type SQLUserStorage interface {
+	LoadUser(username string) (user *models.User, err error)
+	UpdateUserPassword(username, password string, updatedAt time.Time) (err error)
+}
+
+// SQLUserProvider is a provider reading details from the SQL database of the storage.
+type SQLUserProvider struct {
+	configuration *schema.SQLAuthenticationBackendConfiguration
+	storage       SQLUserStorage
+}
+
+// NewSQLUserProvider creates a new instance of SQLUserProvider.
+func NewSQLUserProvider(configuration *schema.SQLAuthenticationBackendConfiguration, storage SQLUserStorage) *SQLUserProvider {
+	return &SQLUserProvider{
+		configuration: configuration,
+		storage:       storage,
+	}
+}
+
+func (p *SQLUserProvider) loadUser(username string) (*models.User, error) {
+	user, err := p.storage.LoadUser(username)
+
+	switch {
+	case errors.Is(err, storage.ErrNoUser):
+		return nil, ErrUserNotFound
+	case err != nil:
+		return nil, fmt.Errorf("Unable to load user %s from database: %w", username, err)
+	}
+
+	return user, nil
+}
+
+// CheckUserPassword checks if provided password matches for the given user. The password of a disabled user is checked
+// before returning ErrAccountDisabled so the state of the account isn't disclosed to anyone not knowing the password.
+func (p *SQLUserProvider) CheckUserPassword(username string, password string) (bool, error) {
+	user, err := p.loadUser(username)
+	if err != nil {
+		return false, err
+	}
+
+	ok, err := CheckPassword(password, strings.TrimPrefix(user.Password, "{CRYPT}"))
+	if err != nil {
+		return false, fmt.Errorf("Unable to check the password of user %s: %w", username, err)
+	}
+
+	if ok && user.Disabled {
+		return false, ErrAccountDisabled
+	}
+
+	return ok, nil
+}
+
+// GetDetails retrieve the details of a user, the details of the disabled users can't be retrieved.
+func (p *SQLUserProvider) GetDetails(username string) (*UserDetails, error) {
+	user, err := p.loadUser(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Disabled {
+		return nil, ErrAccountDisabled
+	}
+
+	return &UserDetails{
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Groups:      user.Groups,
+		Emails:      user.Emails,
+	}, nil
+}
+
+// GetAttributes retrieves the values of the given attributes of a user, attributes without a value are omitted.
+func (p *SQLUserProvider) GetAttributes(username string, attributes []string) (map[string][]string, error) {
+	user, err := p.loadUser(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Disabled {
+		return nil, ErrAccountDisabled
+	}
+
+	values := make(map[string][]string, len(attributes))
+
+	for _, attribute := range attributes {
+		if value, ok := user.Attributes[attribute]; ok && len(value) != 0 {
+			values[attribute] = value
+		}
+	}
+
+	return values, nil
+}
+
+// UpdatePassword update the password of the given user.
+func (p *SQLUserProvider) UpdatePassword(username string, newPassword string) error {
+	algorithm, err := ConfigAlgoToCryptoAlgo(p.configuration.Password.Algorithm)
+	if err != nil {
+		return err
+	}
+
+	hash, err := HashPassword(
+		newPassword, "", algorithm, p.configuration.Password.Iterations,
+		p.configuration.Password.Memory*1024, p.configuration.Password.Parallelism,
+		p.configuration.Password.KeyLength, p.configuration.Password.SaltLength)
+	if err != nil {
+		return err
+	}
+
+	err = p.storage.UpdateUserPassword(username, hash, time.Now())
+	if err != nil {
+		return fmt.Errorf("Unable to update the password of user %s in database: %w", username, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package authentication
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+func newSQLUserProviderTest(t *testing.T) (*SQLUserProvider, *storage.MockProvider, *gomock.Controller) {
+	ctrl := gomock.NewController(t)
+	mockStorage := storage.NewMockProvider(ctrl)
+
+	provider := NewSQLUserProvider(&schema.SQLAuthenticationBackendConfiguration{
+		Password: &schema.PasswordConfiguration{
+			Iterations:  schema.DefaultCIPasswordConfiguration.Iterations,
+			KeyLength:   schema.DefaultCIPasswordConfiguration.KeyLength,
+			SaltLength:  schema.DefaultCIPasswordConfiguration.SaltLength,
+			Algorithm:   schema.DefaultCIPasswordConfiguration.Algorithm,
+			Memory:      schema.DefaultCIPasswordConfiguration.Memory,
+			Parallelism: schema.DefaultCIPasswordConfiguration.Parallelism,
+		},
+	}, mockStorage)
+
+	return provider, mockStorage, ctrl
+}
+
+func newSQLUserProviderTestUser() *models.User {
+	return &models.User{
+		ID:          1,
+		Username:    "john",
+		DisplayName: "John Doe",
+		Password:    "{CRYPT}$argon2id$v=19$m=65536,t=3,p=2$BpLnfgDsc2WD8F2q$o/vzA4myCqZZ36bUGsDY//8mKUYNZZaR0t4MFFSs+iM",
+		Emails:      []string{"john.doe@authelia.com", "jdoe@authelia.com"},
+		Groups:      []string{"admins", "dev"},
+		Attributes:  map[string][]string{"phone_number": {"+1 555 0100"}},
+	}
+}
+
+func TestSQLUserProviderShouldCheckUserPassword(t *testing.T) {
+	provider, mockStorage, ctrl := newSQLUserProviderTest(t)
+	defer ctrl.Finish()
+
+	user := newSQLUserProviderTestUser()
+	user.Password = "$6$rounds=500000$jgiCMRyGXzoqpxS3$w2pJeZnnH8bwW3zzvoMWtTRfQYsHbWbD/hquuQ5vUeIyl9gdwBIt6RWk2S6afBA0DPakbeWgD/4SZPiS0hYtU/"
+
+	mockStorage.EXPECT().LoadUser("john").Return(newSQLUserProviderTestUser(), nil)
+	mockStorage.EXPECT().LoadUser("harry").Return(user, nil)
+	mockStorage.EXPECT().LoadUser("john").Return(newSQLUserProviderTestUser(), nil)
+
+	ok, err := provider.CheckUserPassword("john", "password")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = provider.CheckUserPassword("harry", "password")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = provider.CheckUserPassword("john", "wrong_password")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSQLUserProviderShouldReturnErrorOfUnknownUser(t *testing.T) {
+	provider, mockStorage, ctrl := newSQLUserProviderTest(t)
+	defer ctrl.Finish()
+
+	mockStorage.EXPECT().LoadUser("fake").Return(nil, storage.ErrNoUser).Times(3)
+
+	ok, err := provider.CheckUserPassword("fake", "password")
+	assert.False(t, ok)
+	assert.Equal(t, ErrUserNotFound, err)
+
+	details, err := provider.GetDetails("fake")
+	assert.Nil(t, details)
+	assert.Equal(t, ErrUserNotFound, err)
+
+	values, err := provider.GetAttributes("fake", []string{"phone_number"})
+	assert.Nil(t, values)
+	assert.Equal(t, ErrUserNotFound, err)
+}
+
+func TestSQLUserProviderShouldWrapErrorOfDatabase(t *testing.T) {
+	provider, mockStorage, ctrl := newSQLUserProviderTest(t)
+	defer ctrl.Finish()
+
+	mockStorage.EXPECT().LoadUser("john").Return(nil, errors.New("connection refused"))
+
+	details, err := provider.GetDetails("john")
+	assert.Nil(t, details)
+	assert.EqualError(t, err, "Unable to load user john from database: connection refused")
+}
+
+func TestSQLUserProviderShouldReturnAccountDisabledOnlyWithCorrectPassword(t *testing.T) {
+	provider, mockStorage, ctrl := newSQLUserProviderTest(t)
+	defer ctrl.Finish()
+
+	user := newSQLUserProviderTestUser()
+	user.Disabled = true
+
+	mockStorage.EXPECT().LoadUser("john").Return(user, nil).Times(4)
+
+	ok, err := provider.CheckUserPassword("john", "wrong_password")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = provider.CheckUserPassword("john", "password")
+	assert.False(t, ok)
+	assert.Equal(t, ErrAccountDisabled, err)
+
+	details, err := provider.GetDetails("john")
+	assert.Nil(t, details)
+	assert.Equal(t, ErrAccountDisabled, err)
+
+	values, err := provider.GetAttributes("john", []string{"phone_number"})
+	assert.Nil(t, values)
+	assert.Equal(t, ErrAccountDisabled, err)
+}
+
+func TestSQLUserProviderShouldGetDetailsAndAttributes(t *testing.T) {
+	provider, mockStorage, ctrl := newSQLUserProviderTest(t)
+	defer ctrl.Finish()
+
+	mockStorage.EXPECT().LoadUser("john").Return(newSQLUserProviderTestUser(), nil).Times(2)
+
+	details, err := provider.GetDetails("john")
+	require.NoError(t, err)
+	assert.Equal(t, "john", details.Username)
+	assert.Equal(t, "John Doe", details.DisplayName)
+	assert.Equal(t, []string{"john.doe@authelia.com", "jdoe@authelia.com"}, details.Emails)
+	assert.Equal(t, []string{"admins", "dev"}, details.Groups)
+
+	values, err := provider.GetAttributes("john", []string{"phone_number", "locale"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"phone_number": {"+1 555 0100"}}, values)
+}
+
+func TestSQLUserProviderShouldUpdatePassword(t *testing.T) {
+	provider, mockStorage, ctrl := newSQLUserProviderTest(t)
+	defer ctrl.Finish()
+
+	var hash string
+
+	mockStorage.EXPECT().
+		UpdateUserPassword("john", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_, password string, _ interface{}) error {
+			hash = password
+			return nil
+		})
+
+	err := provider.UpdatePassword("john", "newpassword")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$"))
+
+	ok, err := CheckPassword("newpassword", hash)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	mockStorage.EXPECT().
+		UpdateUserPassword("harry", gomock.Any(), gomock.Any()).
+		Return(storage.ErrNoUser)
+
+	err = provider.UpdatePassword("harry", "newpassword")
+	assert.True(t, errors.Is(err, storage.ErrNoUser))
+}
@@ -5,5 +5,6 @@ package authentication
 type UserProvider interface {
 	CheckUserPassword(username string, password string) (bool, error)
 	GetDetails(username string) (*UserDetails, error)
+	GetAttributes(username string, attributes []string) (map[string][]string, error)
 	UpdatePassword(username string, newPassword string) error
 }
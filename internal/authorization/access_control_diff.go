@@ -0,0 +1,93 @@
+package authorization
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// AccessControlDiff describes the changes between two access control configurations. The rules are compared by their
+// criteria and policy, so a rule which only moved is neither added nor removed.
+type AccessControlDiff struct {
+	PreviousDefaultPolicy string
+	DefaultPolicy         string
+
+	Added   []string
+	Removed []string
+
+	// NetworksChanged is true if the networks or the explain networks changed.
+	NetworksChanged bool
+}
+
+// NewAccessControlDiff returns the changes between the previous and current access control configurations.
+func NewAccessControlDiff(previous, current schema.AccessControlConfiguration) (diff AccessControlDiff) {
+	diff = AccessControlDiff{
+		PreviousDefaultPolicy: previous.DefaultPolicy,
+		DefaultPolicy:         current.DefaultPolicy,
+		NetworksChanged: !reflect.DeepEqual(previous.Networks, current.Networks) ||
+			!reflect.DeepEqual(previous.Explain, current.Explain),
+	}
+
+	counts := map[string]int{}
+
+	for _, rule := range previous.Rules {
+		counts[ruleToString(rule)]++
+	}
+
+	for _, rule := range current.Rules {
+		description := ruleToString(rule)
+
+		if counts[description] > 0 {
+			counts[description]--
+
+			continue
+		}
+
+		diff.Added = append(diff.Added, description)
+	}
+
+	for _, rule := range previous.Rules {
+		description := ruleToString(rule)
+
+		if counts[description] > 0 {
+			counts[description]--
+
+			diff.Removed = append(diff.Removed, description)
+		}
+	}
+
+	return diff
+}
+
+// IsEmpty returns true if the configurations are equivalent, apart from the order of the rules.
+func (d AccessControlDiff) IsEmpty() bool {
+	return d.PreviousDefaultPolicy == d.DefaultPolicy && len(d.Added) == 0 && len(d.Removed) == 0 && !d.NetworksChanged
+}
+
+// ruleToString returns a description of the criteria and policy of a rule, the criteria which aren't configured are
+// omitted.
+func ruleToString(rule schema.ACLRule) string {
+	var parts []string
+
+	add := func(name string, value interface{}, length int) {
+		if length != 0 {
+			parts = append(parts, fmt.Sprintf("%s=%v", name, value))
+		}
+	}
+
+	add("domain", rule.Domains, len(rule.Domains))
+	add("domain_regex", rule.DomainsRegex, len(rule.DomainsRegex))
+	add("policy", rule.Policy, len(rule.Policy))
+	add("max_age", rule.MaxAge, len(rule.MaxAge))
+	add("subject", rule.Subjects, len(rule.Subjects))
+	add("networks", rule.Networks, len(rule.Networks))
+	add("resources", rule.Resources, len(rule.Resources))
+	add("methods", rule.Methods, len(rule.Methods))
+	add("query", rule.Query, len(rule.Query))
+	add("headers", rule.Headers, len(rule.Headers))
+	add("time_windows", rule.TimeWindows, len(rule.TimeWindows))
+
+	return strings.Join(parts, " ")
+}
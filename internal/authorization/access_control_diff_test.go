@@ -0,0 +1,71 @@
+package authorization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldDiffAccessControlConfigurations(t *testing.T) {
+	previous := schema.AccessControlConfiguration{
+		DefaultPolicy: deny,
+		Rules: []schema.ACLRule{
+			{Domains: []string{"public.example.com"}, Policy: bypass},
+			{Domains: []string{"secure.example.com"}, Policy: twoFactor, Subjects: [][]string{{"group:admins"}}},
+			{Domains: []string{"single.example.com"}, Policy: oneFactor},
+		},
+	}
+
+	current := schema.AccessControlConfiguration{
+		DefaultPolicy: oneFactor,
+		Networks:      []schema.ACLNetwork{{Name: "internal", Networks: []string{"10.0.0.0/8"}}},
+		Rules: []schema.ACLRule{
+			{Domains: []string{"single.example.com"}, Policy: oneFactor},
+			{Domains: []string{"public.example.com"}, Policy: bypass, Methods: []string{"GET"}},
+			{Domains: []string{"secure.example.com"}, Policy: twoFactor, Subjects: [][]string{{"group:admins"}}},
+		},
+	}
+
+	diff := NewAccessControlDiff(previous, current)
+
+	assert.False(t, diff.IsEmpty())
+	assert.Equal(t, deny, diff.PreviousDefaultPolicy)
+	assert.Equal(t, oneFactor, diff.DefaultPolicy)
+	assert.True(t, diff.NetworksChanged)
+	assert.Equal(t, []string{"domain=[public.example.com] policy=bypass methods=[GET]"}, diff.Added)
+	assert.Equal(t, []string{"domain=[public.example.com] policy=bypass"}, diff.Removed)
+
+	assert.True(t, NewAccessControlDiff(current, current).IsEmpty())
+}
+
+func TestShouldReloadAuthorizer(t *testing.T) {
+	authorizer := NewAuthorizer(&schema.Configuration{
+		AccessControl: schema.AccessControlConfiguration{
+			DefaultPolicy: deny,
+			Rules: []schema.ACLRule{
+				{Domains: []string{"public.example.com"}, Policy: bypass},
+			},
+		},
+	})
+
+	object := Object{Scheme: "https", Domain: "public.example.com", Path: "/", Method: "GET"}
+
+	assert.Equal(t, Bypass, authorizer.GetRequiredLevel(John, object))
+	assert.False(t, authorizer.IsSecondFactorEnabled())
+
+	diff := authorizer.Reload(schema.AccessControlConfiguration{
+		DefaultPolicy: twoFactor,
+		Rules: []schema.ACLRule{
+			{Domains: []string{"public.example.com"}, Policy: oneFactor},
+		},
+	})
+
+	assert.Equal(t, []string{"domain=[public.example.com] policy=one_factor"}, diff.Added)
+	assert.Equal(t, []string{"domain=[public.example.com] policy=bypass"}, diff.Removed)
+
+	assert.Equal(t, OneFactor, authorizer.GetRequiredLevel(John, object))
+	assert.Equal(t, TwoFactor, authorizer.DefaultPolicy())
+	assert.True(t, authorizer.IsSecondFactorEnabled())
+}
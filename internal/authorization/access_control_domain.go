@@ -2,6 +2,7 @@ package authorization
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/authelia/authelia/internal/utils"
@@ -13,11 +14,17 @@ type AccessControlDomain struct {
 	Wildcard      bool
 	UserWildcard  bool
 	GroupWildcard bool
+
+	// Pattern is the regular expression of the domain when it's configured with domain_regex. The values of its named
+	// capture groups can be referenced by the subjects of the rule.
+	Pattern *regexp.Regexp
 }
 
 // IsMatch returns true if the ACL domain matches the object domain.
 func (acd AccessControlDomain) IsMatch(subject Subject, object Object) (match bool) {
 	switch {
+	case acd.Pattern != nil:
+		return acd.Pattern.MatchString(object.Domain)
 	case acd.Wildcard:
 		return strings.HasSuffix(object.Domain, acd.Name)
 	case acd.UserWildcard:
@@ -30,3 +37,10 @@ func (acd AccessControlDomain) IsMatch(subject Subject, object Object) (match bo
 		return object.Domain == acd.Name
 	}
 }
+
+// Captures adds the values of the named capture groups of the ACL domain pattern for the object domain to captures.
+func (acd AccessControlDomain) Captures(object Object, captures map[string]string) {
+	if acd.Pattern != nil {
+		addPatternCaptures(acd.Pattern, object.Domain, captures)
+	}
+}
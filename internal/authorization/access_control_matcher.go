@@ -0,0 +1,43 @@
+package authorization
+
+import (
+	"regexp"
+)
+
+// AccessControlMatcher represents an ACL criteria on the values of a query argument or a header of the object.
+type AccessControlMatcher struct {
+	Key      string
+	Operator string
+	Value    string
+	Pattern  *regexp.Regexp
+}
+
+// IsMatch returns true if the values of the query argument or header of the object satisfy the ACL matcher.
+func (acm AccessControlMatcher) IsMatch(values []string) (match bool) {
+	switch acm.Operator {
+	case operatorPresent:
+		return len(values) != 0
+	case operatorAbsent:
+		return len(values) == 0
+	case operatorEqual:
+		return acm.isAnyValueMatch(values)
+	case operatorNotEqual:
+		return !acm.isAnyValueMatch(values)
+	case operatorPattern:
+		return acm.isAnyValueMatch(values)
+	case operatorNotPattern:
+		return !acm.isAnyValueMatch(values)
+	default:
+		return false
+	}
+}
+
+func (acm AccessControlMatcher) isAnyValueMatch(values []string) (match bool) {
+	for _, value := range values {
+		if acm.Pattern != nil && acm.Pattern.MatchString(value) || acm.Pattern == nil && value == acm.Value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -13,3 +13,8 @@ type AccessControlResource struct {
 func (acr AccessControlResource) IsMatch(object Object) (match bool) {
 	return acr.Pattern.MatchString(object.Path)
 }
+
+// Captures adds the values of the named capture groups of the ACL resource pattern for the object path to captures.
+func (acr AccessControlResource) Captures(object Object, captures map[string]string) {
+	addPatternCaptures(acr.Pattern, object.Path, captures)
+}
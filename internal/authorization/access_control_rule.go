@@ -2,6 +2,7 @@ package authorization
 
 import (
 	"net"
+	"time"
 
 	"github.com/authelia/authelia/internal/configuration/schema"
 	"github.com/authelia/authelia/internal/utils"
@@ -22,12 +23,18 @@ func NewAccessControlRules(config schema.AccessControlConfiguration) (rules []*A
 func NewAccessControlRule(pos int, rule schema.ACLRule, networksMap map[string][]*net.IPNet, networksCacheMap map[string]*net.IPNet) *AccessControlRule {
 	return &AccessControlRule{
 		Position:  pos,
-		Domains:   schemaDomainsToACL(rule.Domains),
+		Domains:   schemaDomainsToACL(rule.Domains, rule.DomainsRegex),
 		Resources: schemaResourcesToACL(rule.Resources),
 		Methods:   schemaMethodsToACL(rule.Methods),
 		Networks:  schemaNetworksToACL(rule.Networks, networksMap, networksCacheMap),
 		Subjects:  schemaSubjectsToACL(rule.Subjects),
 		Policy:    PolicyToLevel(rule.Policy),
+
+		Query:       schemaMatchersToACL(rule.Query, false),
+		Headers:     schemaMatchersToACL(rule.Headers, true),
+		TimeWindows: schemaTimeWindowsToACL(rule.TimeWindows),
+
+		MaxAge: schemaMaxAgeToACL(rule.MaxAge),
 	}
 }
 
@@ -40,15 +47,24 @@ type AccessControlRule struct {
 	Networks  []*net.IPNet
 	Subjects  []AccessControlSubjects
 	Policy    Level
+
+	Query       []AccessControlMatcher
+	Headers     []AccessControlMatcher
+	TimeWindows []AccessControlTimeWindow
+
+	// MaxAge is the maximum age of the second factor authentication of the subject, 0 means no maximum.
+	MaxAge time.Duration
 }
 
 // IsMatch returns true if all elements of an AccessControlRule match the object and subject.
 func (acr *AccessControlRule) IsMatch(subject Subject, object Object) (match bool) {
-	if !isMatchForDomains(subject, object, acr) {
+	captures := map[string]string{}
+
+	if !isMatchForDomains(subject, object, acr, captures) {
 		return false
 	}
 
-	if !isMatchForResources(object, acr) {
+	if !isMatchForResources(object, acr, captures) {
 		return false
 	}
 
@@ -56,18 +72,30 @@ func (acr *AccessControlRule) IsMatch(subject Subject, object Object) (match boo
 		return false
 	}
 
+	if !isMatchForQuery(object, acr) {
+		return false
+	}
+
+	if !isMatchForHeaders(object, acr) {
+		return false
+	}
+
+	if !isMatchForTimeWindows(object, acr) {
+		return false
+	}
+
 	if !isMatchForNetworks(subject, acr) {
 		return false
 	}
 
-	if !isMatchForSubjects(subject, acr) {
+	if !isMatchForSubjects(subject, acr, captures) {
 		return false
 	}
 
 	return true
 }
 
-func isMatchForDomains(subject Subject, object Object, acl *AccessControlRule) (match bool) {
+func isMatchForDomains(subject Subject, object Object, acl *AccessControlRule, captures map[string]string) (match bool) {
 	// If there are no domains in this rule then the domain condition is a match.
 	if len(acl.Domains) == 0 {
 		return true
@@ -76,6 +104,8 @@ func isMatchForDomains(subject Subject, object Object, acl *AccessControlRule) (
 	// Iterate over the domains until we find a match (return true) or until we exit the loop (return false).
 	for _, domain := range acl.Domains {
 		if domain.IsMatch(subject, object) {
+			domain.Captures(object, captures)
+
 			return true
 		}
 	}
@@ -83,7 +113,7 @@ func isMatchForDomains(subject Subject, object Object, acl *AccessControlRule) (
 	return false
 }
 
-func isMatchForResources(object Object, acl *AccessControlRule) (match bool) {
+func isMatchForResources(object Object, acl *AccessControlRule, captures map[string]string) (match bool) {
 	// If there are no resources in this rule then the resource condition is a match.
 	if len(acl.Resources) == 0 {
 		return true
@@ -92,6 +122,8 @@ func isMatchForResources(object Object, acl *AccessControlRule) (match bool) {
 	// Iterate over the resources until we find a match (return true) or until we exit the loop (return false).
 	for _, resource := range acl.Resources {
 		if resource.IsMatch(object) {
+			resource.Captures(object, captures)
+
 			return true
 		}
 	}
@@ -108,6 +140,44 @@ func isMatchForMethods(object Object, acl *AccessControlRule) (match bool) {
 	return utils.IsStringInSlice(object.Method, acl.Methods)
 }
 
+func isMatchForQuery(object Object, acl *AccessControlRule) (match bool) {
+	// Every query matcher of this rule must match, if there are none then the query condition is a match.
+	for _, matcher := range acl.Query {
+		if !matcher.IsMatch(object.Query[matcher.Key]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isMatchForHeaders(object Object, acl *AccessControlRule) (match bool) {
+	// Every header matcher of this rule must match, if there are none then the header condition is a match.
+	for _, matcher := range acl.Headers {
+		if !matcher.IsMatch(object.Header.Values(matcher.Key)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isMatchForTimeWindows(object Object, acl *AccessControlRule) (match bool) {
+	// If there are no time windows in this rule then the time condition is a match.
+	if len(acl.TimeWindows) == 0 {
+		return true
+	}
+
+	// Iterate over the time windows until we find a match (return true) or until we exit the loop (return false).
+	for _, timeWindow := range acl.TimeWindows {
+		if timeWindow.IsMatch(object) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func isMatchForNetworks(subject Subject, acl *AccessControlRule) (match bool) {
 	// If there are no networks in this rule then the network condition is a match.
 	if len(acl.Networks) == 0 {
@@ -124,7 +194,7 @@ func isMatchForNetworks(subject Subject, acl *AccessControlRule) (match bool) {
 	return false
 }
 
-func isMatchForSubjects(subject Subject, acl *AccessControlRule) (match bool) {
+func isMatchForSubjects(subject Subject, acl *AccessControlRule, captures map[string]string) (match bool) {
 	// If there are no subjects in this rule then the subject condition is a match.
 	if len(acl.Subjects) == 0 || subject.IsAnonymous() {
 		return true
@@ -132,10 +202,27 @@ func isMatchForSubjects(subject Subject, acl *AccessControlRule) (match bool) {
 
 	// Iterate over the subjects until we find a match (return true) or until we exit the loop (return false).
 	for _, subjectRule := range acl.Subjects {
-		if subjectRule.IsMatch(subject) {
+		if subjectRule.IsMatch(subject, captures) {
 			return true
 		}
 	}
 
 	return false
 }
+
+// HasClientSubjects returns true if one of the subjects of the rule is an OpenID Connect client, negated or not.
+func (acr *AccessControlRule) HasClientSubjects() bool {
+	for _, subjects := range acr.Subjects {
+		for _, subject := range subjects.Subjects {
+			if negation, ok := subject.(AccessControlNegation); ok {
+				subject = negation.Subject
+			}
+
+			if _, ok := subject.(AccessControlClient); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -1,12 +1,15 @@
 package authorization
 
 import (
+	"strings"
+
 	"github.com/authelia/authelia/internal/utils"
 )
 
-// AccessControlSubject abstracts an ACL subject of type `group:` or `user:`.
+// AccessControlSubject abstracts an ACL subject of type `group:`, `user:`, `email:` or `oidc:`, optionally negated. The captures are the values of the named
+// capture groups of the domain and resource patterns of the rule, see expandCaptures.
 type AccessControlSubject interface {
-	IsMatch(subject Subject) (match bool)
+	IsMatch(subject Subject, captures map[string]string) (match bool)
 }
 
 // AccessControlSubjects represents an ACL subject.
@@ -24,9 +27,9 @@ func (acs *AccessControlSubjects) AddSubject(subjectRule string) {
 }
 
 // IsMatch returns true if the ACL subjects match the subject properties.
-func (acs AccessControlSubjects) IsMatch(subject Subject) (match bool) {
+func (acs AccessControlSubjects) IsMatch(subject Subject, captures map[string]string) (match bool) {
 	for _, rule := range acs.Subjects {
-		if !rule.IsMatch(subject) {
+		if !rule.IsMatch(subject, captures) {
 			return false
 		}
 	}
@@ -39,9 +42,16 @@ type AccessControlUser struct {
 	Name string
 }
 
-// IsMatch returns true if the AccessControlUser name matches the Subject username.
-func (acu AccessControlUser) IsMatch(subject Subject) (match bool) {
-	return subject.Username == acu.Name
+// IsMatch returns true if the AccessControlUser name matches the Subject username. Names referencing capture groups
+// are matched case-insensitively as they're usually captured from the domain.
+func (acu AccessControlUser) IsMatch(subject Subject, captures map[string]string) (match bool) {
+	if !hasCapturePlaceholders(acu.Name) {
+		return subject.Username == acu.Name
+	}
+
+	name, ok := expandCaptures(acu.Name, captures)
+
+	return ok && strings.EqualFold(subject.Username, name)
 }
 
 // AccessControlGroup represents an ACL subject of type `group:`.
@@ -49,7 +59,56 @@ type AccessControlGroup struct {
 	Name string
 }
 
-// IsMatch returns true if the AccessControlGroup name matches one of the groups of the Subject.
-func (acg AccessControlGroup) IsMatch(subject Subject) (match bool) {
-	return utils.IsStringInSlice(acg.Name, subject.Groups)
+// IsMatch returns true if the AccessControlGroup name matches one of the groups of the Subject. Names referencing
+// capture groups are matched case-insensitively as they're usually captured from the domain.
+func (acg AccessControlGroup) IsMatch(subject Subject, captures map[string]string) (match bool) {
+	if !hasCapturePlaceholders(acg.Name) {
+		return utils.IsStringInSlice(acg.Name, subject.Groups)
+	}
+
+	name, ok := expandCaptures(acg.Name, captures)
+
+	return ok && utils.IsStringInSliceFold(name, subject.Groups)
+}
+
+// AccessControlEmail represents an ACL subject of type `email:`.
+type AccessControlEmail struct {
+	Pattern string
+}
+
+// IsMatch returns true if the AccessControlEmail pattern matches one of the emails of the Subject. The pattern may
+// contain `*` wildcards, and the emails are matched case-insensitively.
+func (ace AccessControlEmail) IsMatch(subject Subject, captures map[string]string) (match bool) {
+	pattern, ok := expandCaptures(ace.Pattern, captures)
+	if !ok {
+		return false
+	}
+
+	for _, email := range subject.Emails {
+		if isWildcardMatchFold(pattern, email) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AccessControlClient represents an ACL subject of type `oidc:`.
+type AccessControlClient struct {
+	ID string
+}
+
+// IsMatch returns true if the AccessControlClient id matches the OpenID Connect client id of the Subject.
+func (acc AccessControlClient) IsMatch(subject Subject, _ map[string]string) (match bool) {
+	return subject.ClientID != "" && subject.ClientID == acc.ID
+}
+
+// AccessControlNegation represents a negated ACL subject, its subject is prefixed with `!`.
+type AccessControlNegation struct {
+	Subject AccessControlSubject
+}
+
+// IsMatch returns true if the negated subject does not match the Subject.
+func (acn AccessControlNegation) IsMatch(subject Subject, captures map[string]string) (match bool) {
+	return !acn.Subject.IsMatch(subject, captures)
 }
@@ -0,0 +1,45 @@
+package authorization
+
+import (
+	"time"
+)
+
+// AccessControlTimeWindow represents an ACL time window, a window which ends before it starts spans midnight and
+// continues the next day.
+type AccessControlTimeWindow struct {
+	Days     []time.Weekday
+	Start    time.Duration
+	End      time.Duration
+	Location *time.Location
+}
+
+// IsMatch returns true if the time the object was evaluated at is within the ACL time window.
+func (actw AccessControlTimeWindow) IsMatch(object Object) (match bool) {
+	t := object.Time.In(actw.Location)
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+
+	if actw.Start <= actw.End {
+		return actw.isDayMatch(t.Weekday()) && offset >= actw.Start && offset < actw.End
+	}
+
+	if offset >= actw.Start {
+		return actw.isDayMatch(t.Weekday())
+	}
+
+	return offset < actw.End && actw.isDayMatch((t.Weekday()+6)%7)
+}
+
+func (actw AccessControlTimeWindow) isDayMatch(weekday time.Weekday) (match bool) {
+	// If there are no days in this time window then every day is a match.
+	if len(actw.Days) == 0 {
+		return true
+	}
+
+	for _, day := range actw.Days {
+		if day == weekday {
+			return true
+		}
+	}
+
+	return false
+}
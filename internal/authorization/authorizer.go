@@ -1,33 +1,80 @@
 package authorization
 
 import (
+	"net"
+	"sync"
+	"time"
+
 	"github.com/authelia/authelia/internal/configuration/schema"
 	"github.com/authelia/authelia/internal/logging"
 )
 
 // Authorizer the component in charge of checking whether a user can access a given resource.
 type Authorizer struct {
-	defaultPolicy Level
-	rules         []*AccessControlRule
 	configuration *schema.Configuration
+
+	mutex         sync.RWMutex
+	accessControl *accessControl
+}
+
+// accessControl is the parsed access control configuration of the Authorizer, it's never modified once created so it
+// can be swapped as a whole when the configuration is reloaded.
+type accessControl struct {
+	defaultPolicy   Level
+	rules           []*AccessControlRule
+	explainNetworks []*net.IPNet
+
+	config schema.AccessControlConfiguration
 }
 
 // NewAuthorizer create an instance of authorizer with a given access control configuration.
 func NewAuthorizer(configuration *schema.Configuration) *Authorizer {
 	return &Authorizer{
-		defaultPolicy: PolicyToLevel(configuration.AccessControl.DefaultPolicy),
-		rules:         NewAccessControlRules(configuration.AccessControl),
 		configuration: configuration,
+		accessControl: newAccessControl(configuration.AccessControl),
+	}
+}
+
+func newAccessControl(config schema.AccessControlConfiguration) *accessControl {
+	networksMap, networksCacheMap := parseSchemaNetworks(config.Networks)
+
+	return &accessControl{
+		defaultPolicy:   PolicyToLevel(config.DefaultPolicy),
+		rules:           NewAccessControlRules(config),
+		explainNetworks: schemaNetworksToACL(config.Explain.Networks, networksMap, networksCacheMap),
+		config:          config,
 	}
 }
 
+// Reload atomically replaces the access control configuration of the authorizer, the configuration must have been
+// validated beforehand. The requests being evaluated during the reload use either the previous or the new rules.
+func (p *Authorizer) Reload(config schema.AccessControlConfiguration) (diff AccessControlDiff) {
+	current := newAccessControl(config)
+
+	p.mutex.Lock()
+	previous := p.accessControl
+	p.accessControl = current
+	p.mutex.Unlock()
+
+	return NewAccessControlDiff(previous.config, current.config)
+}
+
+func (p *Authorizer) get() *accessControl {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.accessControl
+}
+
 // IsSecondFactorEnabled return true if at least one policy is set to second factor.
-func (p Authorizer) IsSecondFactorEnabled() bool {
-	if p.defaultPolicy == TwoFactor {
+func (p *Authorizer) IsSecondFactorEnabled() bool {
+	acl := p.get()
+
+	if acl.defaultPolicy == TwoFactor {
 		return true
 	}
 
-	for _, rule := range p.rules {
+	for _, rule := range acl.rules {
 		if rule.Policy == TwoFactor {
 			return true
 		}
@@ -45,17 +92,41 @@ func (p Authorizer) IsSecondFactorEnabled() bool {
 }
 
 // GetRequiredLevel retrieve the required level of authorization to access the object.
-func (p Authorizer) GetRequiredLevel(subject Subject, object Object) Level {
+func (p *Authorizer) GetRequiredLevel(subject Subject, object Object) Level {
+	level, _ := p.GetRequiredLevelAndMaxAge(subject, object)
+
+	return level
+}
+
+// GetRequiredLevelAndMaxAge retrieve the required level of authorization to access the object and the maximum age of
+// the authentication at this level, a maximum age of 0 means the authentication never expires.
+func (p *Authorizer) GetRequiredLevelAndMaxAge(subject Subject, object Object) (level Level, maxAge time.Duration) {
+	acl := p.get()
+
+	if rule := acl.getMatchingRule(subject, object); rule != nil {
+		return rule.Policy, rule.MaxAge
+	}
+
+	return acl.defaultPolicy, 0
+}
+
+// GetMatchingRule retrieve the first rule matching the subject and object, nil is returned when the default policy
+// applies.
+func (p *Authorizer) GetMatchingRule(subject Subject, object Object) *AccessControlRule {
+	return p.get().getMatchingRule(subject, object)
+}
+
+func (acl *accessControl) getMatchingRule(subject Subject, object Object) *AccessControlRule {
 	logger := logging.Logger()
 
 	logger.Debugf("Check authorization of subject %s and object %s (method %s).",
 		subject.String(), object.String(), object.Method)
 
-	for _, rule := range p.rules {
+	for _, rule := range acl.rules {
 		if rule.IsMatch(subject, object) {
 			logger.Tracef(traceFmtACLHitMiss, "HIT", rule.Position, subject.String(), object.String(), object.Method)
 
-			return rule.Policy
+			return rule
 		}
 
 		logger.Tracef(traceFmtACLHitMiss, "MISS", rule.Position, subject.String(), object.String(), object.Method)
@@ -64,5 +135,54 @@ func (p Authorizer) GetRequiredLevel(subject Subject, object Object) Level {
 	logger.Debugf("No matching rule for subject %s and url %s... Applying default policy.",
 		subject.String(), object.String())
 
-	return p.defaultPolicy
+	return nil
+}
+
+// IsExplainEnabled returns true if the access control decisions should be explained to the client with the ip.
+func (p *Authorizer) IsExplainEnabled(ip net.IP) bool {
+	for _, network := range p.get().explainNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetRuleMatchResults evaluates every criteria of every rule for the subject and object. It's not used to make
+// authorization decisions but to explain them, the first result which IsMatch is the rule GetRequiredLevel applies.
+func (p *Authorizer) GetRuleMatchResults(subject Subject, object Object) (results []RuleMatchResult) {
+	matched := false
+
+	for _, rule := range p.get().rules {
+		captures := map[string]string{}
+
+		result := RuleMatchResult{
+			Rule:    rule,
+			Skipped: matched,
+
+			MatchDomain:      isMatchForDomains(subject, object, rule, captures),
+			MatchResources:   isMatchForResources(object, rule, captures),
+			MatchMethods:     isMatchForMethods(object, rule),
+			MatchQuery:       isMatchForQuery(object, rule),
+			MatchHeaders:     isMatchForHeaders(object, rule),
+			MatchTimeWindows: isMatchForTimeWindows(object, rule),
+			MatchNetworks:    isMatchForNetworks(subject, rule),
+		}
+
+		result.MatchSubjects = isMatchForSubjects(subject, rule, captures)
+
+		if result.IsMatch() {
+			matched = true
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// DefaultPolicy returns the level of the policy applied when no rule matches.
+func (p *Authorizer) DefaultPolicy() Level {
+	return p.get().defaultPolicy
 }
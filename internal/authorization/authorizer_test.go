@@ -2,8 +2,10 @@ package authorization
 
 import (
 	"net"
+	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -45,6 +47,12 @@ func (s *AuthorizerTester) CheckAuthorizations(t *testing.T, subject Subject, re
 	assert.Equal(t, expectedLevel, level)
 }
 
+func (s *AuthorizerTester) CheckObjectAuthorizations(t *testing.T, subject Subject, object Object, expectedLevel Level) {
+	level := s.GetRequiredLevel(subject, object)
+
+	assert.Equal(t, expectedLevel, level)
+}
+
 type AuthorizerTesterBuilder struct {
 	config schema.AccessControlConfiguration
 }
@@ -161,6 +169,198 @@ func (s *AuthorizerSuite) TestShouldCheckDynamicDomainRules() {
 	tester.CheckAuthorizations(s.T(), UserWithGroups, "https://othergroup.example.com/", "GET", Denied)
 }
 
+func (s *AuthorizerSuite) TestShouldCheckDomainRegexRules() {
+	tester := NewAuthorizerBuilder().
+		WithDefaultPolicy(deny).
+		WithRule(schema.ACLRule{
+			DomainsRegex: []string{`^(?P<User>\w+)\.home\.example\.com$`},
+			Policy:       oneFactor,
+			Subjects:     [][]string{{"user:{User}"}},
+		}).
+		WithRule(schema.ACLRule{
+			DomainsRegex: []string{`^(?P<Group>\w+)\.team\.example\.com$`},
+			Policy:       twoFactor,
+			Subjects:     [][]string{{"group:{Group}"}},
+		}).
+		WithRule(schema.ACLRule{
+			DomainsRegex: []string{`^(public|static)\.example\.com$`},
+			Policy:       bypass,
+		}).
+		Build()
+
+	tester.CheckAuthorizations(s.T(), John, "https://john.home.example.com/", "GET", OneFactor)
+	tester.CheckAuthorizations(s.T(), Bob, "https://john.home.example.com/", "GET", Denied)
+	tester.CheckAuthorizations(s.T(), AnonymousUser, "https://john.home.example.com/", "GET", OneFactor)
+	tester.CheckAuthorizations(s.T(), John, "https://dev.team.example.com/", "GET", TwoFactor)
+	tester.CheckAuthorizations(s.T(), John, "https://ops.team.example.com/", "GET", Denied)
+	tester.CheckAuthorizations(s.T(), John, "https://static.example.com/", "GET", Bypass)
+	tester.CheckAuthorizations(s.T(), John, "https://static.example.com.evil.com/", "GET", Denied)
+}
+
+func (s *AuthorizerSuite) TestShouldCheckResourceCaptureSubjects() {
+	tester := NewAuthorizerBuilder().
+		WithDefaultPolicy(deny).
+		WithRule(schema.ACLRule{
+			Domains:   []string{"files.example.com"},
+			Policy:    oneFactor,
+			Resources: []string{`^/users/(?P<User>\w+)/`, `^/shared/`},
+			Subjects:  [][]string{{"user:{User}"}},
+		}).
+		Build()
+
+	tester.CheckAuthorizations(s.T(), John, "https://files.example.com/users/john/notes.txt", "GET", OneFactor)
+	tester.CheckAuthorizations(s.T(), John, "https://files.example.com/users/JOHN/notes.txt", "GET", OneFactor)
+	tester.CheckAuthorizations(s.T(), Bob, "https://files.example.com/users/john/notes.txt", "GET", Denied)
+	tester.CheckAuthorizations(s.T(), John, "https://files.example.com/shared/notes.txt", "GET", Denied)
+}
+
+func (s *AuthorizerSuite) TestShouldCheckNegatedEmailAndClientSubjects() {
+	tester := NewAuthorizerBuilder().
+		WithDefaultPolicy(deny).
+		WithRule(schema.ACLRule{
+			Domains:  []string{"app.example.com"},
+			Policy:   deny,
+			Subjects: [][]string{{"group:dev", "!group:admins"}, {"email:*@contractors.example.com"}},
+		}).
+		WithRule(schema.ACLRule{
+			Domains:  []string{"app.example.com"},
+			Policy:   twoFactor,
+			Subjects: [][]string{{"oidc:app"}},
+		}).
+		WithRule(schema.ACLRule{
+			Domains:  []string{"app.example.com"},
+			Policy:   oneFactor,
+			Subjects: [][]string{{"!oidc:app", "email:*@Example.com"}},
+		}).
+		Build()
+
+	john := Subject{Username: "john", Groups: []string{"dev", "admins"}, Emails: []string{"john@example.com"}}
+	harry := Subject{Username: "harry", Groups: []string{"dev"}, Emails: []string{"harry@example.com"}}
+	fred := Subject{Username: "fred", Emails: []string{"fred@example.org", "fred@CONTRACTORS.example.com"}}
+	bob := Subject{Username: "bob", Emails: []string{"bob@example.org"}}
+	client := Subject{Username: "john", Groups: []string{"admins"}, Emails: []string{"john@example.com"}, ClientID: "app"}
+
+	tester.CheckAuthorizations(s.T(), john, "https://app.example.com/", "GET", OneFactor)
+	tester.CheckAuthorizations(s.T(), harry, "https://app.example.com/", "GET", Denied)
+	tester.CheckAuthorizations(s.T(), fred, "https://app.example.com/", "GET", Denied)
+	tester.CheckAuthorizations(s.T(), bob, "https://app.example.com/", "GET", Denied)
+	tester.CheckAuthorizations(s.T(), client, "https://app.example.com/", "GET", TwoFactor)
+}
+
+func (s *AuthorizerSuite) TestShouldCheckQueryRules() {
+	tester := NewAuthorizerBuilder().
+		WithDefaultPolicy(deny).
+		WithRule(schema.ACLRule{
+			Domains: []string{"api.example.com"},
+			Policy:  bypass,
+			Query: []schema.ACLMatcher{
+				{Key: "format", Value: "json"},
+				{Key: "debug", Operator: "absent"},
+			},
+		}).
+		WithRule(schema.ACLRule{
+			Domains: []string{"api.example.com"},
+			Policy:  twoFactor,
+			Query: []schema.ACLMatcher{
+				{Key: "token", Operator: "pattern", Value: "^[a-f0-9]{8}$"},
+			},
+		}).
+		WithRule(schema.ACLRule{
+			Domains: []string{"api.example.com"},
+			Policy:  oneFactor,
+			Query: []schema.ACLMatcher{
+				{Key: "format"},
+				{Key: "format", Operator: "not equal", Value: "xml"},
+			},
+		}).
+		Build()
+
+	object := func(rawURL string) Object {
+		targetURL, err := url.ParseRequestURI(rawURL)
+		s.Require().NoError(err)
+
+		return NewObject(targetURL, "GET", time.Now())
+	}
+
+	tester.CheckObjectAuthorizations(s.T(), John, object("https://api.example.com/?format=json"), Bypass)
+	tester.CheckObjectAuthorizations(s.T(), John, object("https://api.example.com/?format=json&debug=1"), OneFactor)
+	tester.CheckObjectAuthorizations(s.T(), John, object("https://api.example.com/?format=xml&format=json"), Bypass)
+	tester.CheckObjectAuthorizations(s.T(), John, object("https://api.example.com/?token=0123abcd"), TwoFactor)
+	tester.CheckObjectAuthorizations(s.T(), John, object("https://api.example.com/?token=0123abcz"), Denied)
+	tester.CheckObjectAuthorizations(s.T(), John, object("https://api.example.com/?format=yaml"), OneFactor)
+	tester.CheckObjectAuthorizations(s.T(), John, object("https://api.example.com/?format=xml"), Denied)
+	tester.CheckObjectAuthorizations(s.T(), John, object("https://api.example.com/"), Denied)
+}
+
+func (s *AuthorizerSuite) TestShouldCheckHeaderRules() {
+	tester := NewAuthorizerBuilder().
+		WithDefaultPolicy(deny).
+		WithRule(schema.ACLRule{
+			Domains:   []string{"app.example.com"},
+			Resources: []string{"^/api([/?].*)?$"},
+			Policy:    oneFactor,
+			Headers: []schema.ACLMatcher{
+				{Key: "x-internal"},
+			},
+		}).
+		WithRule(schema.ACLRule{
+			Domains: []string{"app.example.com"},
+			Policy:  twoFactor,
+			Headers: []schema.ACLMatcher{
+				{Key: "User-Agent", Operator: "not pattern", Value: "(?i)bot"},
+			},
+		}).
+		Build()
+
+	object := func(path string, header http.Header) Object {
+		return Object{Scheme: "https", Domain: "app.example.com", Path: path, Method: "GET", Header: header}
+	}
+
+	tester.CheckObjectAuthorizations(s.T(), John, object("/api", http.Header{"X-Internal": []string{"true"}}), OneFactor)
+	tester.CheckObjectAuthorizations(s.T(), John, object("/api", http.Header{}), TwoFactor)
+	tester.CheckObjectAuthorizations(s.T(), John, object("/", http.Header{"X-Internal": []string{"true"}}), TwoFactor)
+	tester.CheckObjectAuthorizations(s.T(), John, object("/api", http.Header{"User-Agent": []string{"GoogleBot/2.1"}}), Denied)
+	tester.CheckObjectAuthorizations(s.T(), John, object("/", nil), TwoFactor)
+}
+
+func (s *AuthorizerSuite) TestShouldCheckTimeWindowRules() {
+	tester := NewAuthorizerBuilder().
+		WithDefaultPolicy(deny).
+		WithRule(schema.ACLRule{
+			Domains: []string{"office.example.com"},
+			Policy:  oneFactor,
+			TimeWindows: []schema.ACLTimeWindow{
+				{Days: []string{"mon", "tue", "wed", "thu", "friday"}, Start: "09:00", End: "17:00", Timezone: "UTC"},
+			},
+		}).
+		WithRule(schema.ACLRule{
+			Domains: []string{"backup.example.com"},
+			Policy:  bypass,
+			TimeWindows: []schema.ACLTimeWindow{
+				{Days: []string{"sat"}, Start: "22:00", End: "02:00", Timezone: "Europe/Paris"},
+			},
+		}).
+		Build()
+
+	object := func(domain string, t time.Time) Object {
+		return Object{Scheme: "https", Domain: domain, Path: "/", Method: "GET", Time: t}
+	}
+
+	// 2021-06-07 is a Monday.
+	tester.CheckObjectAuthorizations(s.T(), John, object("office.example.com", time.Date(2021, 6, 7, 9, 0, 0, 0, time.UTC)), OneFactor)
+	tester.CheckObjectAuthorizations(s.T(), John, object("office.example.com", time.Date(2021, 6, 11, 16, 59, 59, 0, time.UTC)), OneFactor)
+	tester.CheckObjectAuthorizations(s.T(), John, object("office.example.com", time.Date(2021, 6, 7, 17, 0, 0, 0, time.UTC)), Denied)
+	tester.CheckObjectAuthorizations(s.T(), John, object("office.example.com", time.Date(2021, 6, 7, 8, 59, 0, 0, time.UTC)), Denied)
+	tester.CheckObjectAuthorizations(s.T(), John, object("office.example.com", time.Date(2021, 6, 12, 12, 0, 0, 0, time.UTC)), Denied)
+
+	// Paris is UTC+2 in June, the window spans from Saturday 20:00 UTC to Sunday 00:00 UTC.
+	tester.CheckObjectAuthorizations(s.T(), John, object("backup.example.com", time.Date(2021, 6, 12, 20, 0, 0, 0, time.UTC)), Bypass)
+	tester.CheckObjectAuthorizations(s.T(), John, object("backup.example.com", time.Date(2021, 6, 12, 23, 30, 0, 0, time.UTC)), Bypass)
+	tester.CheckObjectAuthorizations(s.T(), John, object("backup.example.com", time.Date(2021, 6, 13, 0, 0, 0, 0, time.UTC)), Denied)
+	tester.CheckObjectAuthorizations(s.T(), John, object("backup.example.com", time.Date(2021, 6, 12, 19, 59, 0, 0, time.UTC)), Denied)
+	tester.CheckObjectAuthorizations(s.T(), John, object("backup.example.com", time.Date(2021, 6, 6, 23, 0, 0, 0, time.UTC)), Denied)
+}
+
 func (s *AuthorizerSuite) TestShouldCheckMultipleDomainRule() {
 	tester := NewAuthorizerBuilder().
 		WithDefaultPolicy(deny).
@@ -492,6 +692,90 @@ func (s *AuthorizerSuite) TestPolicyToLevel() {
 	s.Assert().Equal(Denied, PolicyToLevel("whatever"))
 }
 
+func (s *AuthorizerSuite) TestLevelToPolicy() {
+	s.Assert().Equal(bypass, LevelToPolicy(Bypass))
+	s.Assert().Equal(oneFactor, LevelToPolicy(OneFactor))
+	s.Assert().Equal(twoFactor, LevelToPolicy(TwoFactor))
+	s.Assert().Equal(deny, LevelToPolicy(Denied))
+
+	s.Assert().Equal(deny, LevelToPolicy(Level(42)))
+}
+
+func (s *AuthorizerSuite) TestShouldGetMatchingRuleAndExplainNetworks() {
+	authorizer := NewAuthorizer(&schema.Configuration{
+		AccessControl: schema.AccessControlConfiguration{
+			DefaultPolicy: deny,
+			Networks:      []schema.ACLNetwork{{Name: "internal", Networks: []string{"10.0.0.0/8"}}},
+			Rules: []schema.ACLRule{
+				{Domains: []string{"public.example.com"}, Policy: bypass},
+				{Domains: []string{"*.example.com"}, Policy: twoFactor},
+			},
+			Explain: schema.ACLExplain{Networks: []string{"internal", "192.168.1.1"}},
+		},
+	})
+
+	rule := authorizer.GetMatchingRule(John, Object{Domain: "secure.example.com", Path: "/"})
+	s.Require().NotNil(rule)
+	s.Assert().Equal(2, rule.Position)
+	s.Assert().Equal(TwoFactor, rule.Policy)
+
+	s.Assert().Nil(authorizer.GetMatchingRule(John, Object{Domain: "example.org", Path: "/"}))
+
+	s.Assert().True(authorizer.IsExplainEnabled(net.ParseIP("10.0.0.8")))
+	s.Assert().True(authorizer.IsExplainEnabled(net.ParseIP("192.168.1.1")))
+	s.Assert().False(authorizer.IsExplainEnabled(net.ParseIP("192.168.1.2")))
+	s.Assert().False(authorizer.IsExplainEnabled(nil))
+}
+
+func (s *AuthorizerSuite) TestShouldGetRuleMatchResults() {
+	tester := NewAuthorizerBuilder().
+		WithDefaultPolicy(deny).
+		WithRule(schema.ACLRule{
+			Domains:   []string{"public.example.com"},
+			Resources: []string{"^/admin.*$"},
+			Methods:   []string{"POST"},
+			Policy:    bypass,
+		}).
+		WithRule(schema.ACLRule{
+			Domains:  []string{"public.example.com"},
+			Networks: []string{"192.168.0.0/16"},
+			Subjects: [][]string{{"user:bob"}},
+			Policy:   oneFactor,
+		}).
+		WithRule(schema.ACLRule{
+			Domains: []string{"*.example.com"},
+			Policy:  twoFactor,
+		}).
+		WithRule(schema.ACLRule{
+			Domains: []string{"public.example.com"},
+			Policy:  oneFactor,
+		}).
+		Build()
+
+	s.Assert().Equal(Denied, tester.DefaultPolicy())
+
+	results := tester.GetRuleMatchResults(John, Object{Scheme: "https", Domain: "public.example.com", Path: "/", Method: "GET"})
+
+	s.Require().Len(results, 4)
+
+	s.Assert().False(results[0].IsMatch())
+	s.Assert().False(results[0].Skipped)
+	s.Assert().Equal([]string{"resources", "methods"}, results[0].Mismatches())
+
+	s.Assert().False(results[1].IsMatch())
+	s.Assert().False(results[1].Skipped)
+	s.Assert().Equal([]string{"networks", "subject"}, results[1].Mismatches())
+
+	s.Assert().True(results[2].IsMatch())
+	s.Assert().False(results[2].Skipped)
+	s.Assert().Empty(results[2].Mismatches())
+	s.Assert().Equal(TwoFactor, results[2].Rule.Policy)
+	s.Assert().Equal(3, results[2].Rule.Position)
+
+	s.Assert().True(results[3].IsMatch())
+	s.Assert().True(results[3].Skipped)
+}
+
 func TestRunSuite(t *testing.T) {
 	s := AuthorizerSuite{}
 	suite.Run(t, &s)
@@ -520,14 +804,14 @@ func TestNewAuthorizer(t *testing.T) {
 
 	authorizer := NewAuthorizer(config)
 
-	assert.Equal(t, Denied, authorizer.defaultPolicy)
-	assert.Equal(t, TwoFactor, authorizer.rules[0].Policy)
+	assert.Equal(t, Denied, authorizer.accessControl.defaultPolicy)
+	assert.Equal(t, TwoFactor, authorizer.accessControl.rules[0].Policy)
 
-	user, ok := authorizer.rules[0].Subjects[0].Subjects[0].(AccessControlUser)
+	user, ok := authorizer.accessControl.rules[0].Subjects[0].Subjects[0].(AccessControlUser)
 	require.True(t, ok)
 	assert.Equal(t, "admin", user.Name)
 
-	group, ok := authorizer.rules[0].Subjects[1].Subjects[0].(AccessControlGroup)
+	group, ok := authorizer.accessControl.rules[0].Subjects[1].Subjects[0].(AccessControlGroup)
 	require.True(t, ok)
 	assert.Equal(t, "admins", group.Name)
 }
@@ -548,7 +832,7 @@ func TestAuthorizerIsSecondFactorEnabledRuleWithNoOIDC(t *testing.T) {
 	authorizer := NewAuthorizer(config)
 	assert.False(t, authorizer.IsSecondFactorEnabled())
 
-	authorizer.rules[0].Policy = TwoFactor
+	authorizer.accessControl.rules[0].Policy = TwoFactor
 	assert.True(t, authorizer.IsSecondFactorEnabled())
 }
 
@@ -577,22 +861,22 @@ func TestAuthorizerIsSecondFactorEnabledRuleWithOIDC(t *testing.T) {
 	authorizer := NewAuthorizer(config)
 	assert.False(t, authorizer.IsSecondFactorEnabled())
 
-	authorizer.rules[0].Policy = TwoFactor
+	authorizer.accessControl.rules[0].Policy = TwoFactor
 	assert.True(t, authorizer.IsSecondFactorEnabled())
 
-	authorizer.rules[0].Policy = OneFactor
+	authorizer.accessControl.rules[0].Policy = OneFactor
 	assert.False(t, authorizer.IsSecondFactorEnabled())
 
 	config.IdentityProviders.OIDC.Clients[0].Policy = twoFactor
 
 	assert.True(t, authorizer.IsSecondFactorEnabled())
 
-	authorizer.rules[0].Policy = OneFactor
+	authorizer.accessControl.rules[0].Policy = OneFactor
 	config.IdentityProviders.OIDC.Clients[0].Policy = oneFactor
 
 	assert.False(t, authorizer.IsSecondFactorEnabled())
 
-	authorizer.defaultPolicy = TwoFactor
+	authorizer.accessControl.defaultPolicy = TwoFactor
 
 	assert.True(t, authorizer.IsSecondFactorEnabled())
 }
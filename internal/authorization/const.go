@@ -1,5 +1,9 @@
 package authorization
 
+import (
+	"regexp"
+)
+
 // Level is the type representing an authorization level.
 type Level int
 
@@ -16,10 +20,26 @@ const (
 
 const userPrefix = "user:"
 const groupPrefix = "group:"
+const emailPrefix = "email:"
+const clientPrefix = "oidc:"
+const negationPrefix = "!"
 
 const bypass = "bypass"
 const oneFactor = "one_factor"
 const twoFactor = "two_factor"
 const deny = "deny"
 
+const (
+	operatorEqual      = "equal"
+	operatorNotEqual   = "not equal"
+	operatorPresent    = "present"
+	operatorAbsent     = "absent"
+	operatorPattern    = "pattern"
+	operatorNotPattern = "not pattern"
+)
+
+// reCapturePlaceholder matches the placeholders of the subjects referencing the named capture groups of the domain and
+// resource patterns of a rule.
+var reCapturePlaceholder = regexp.MustCompile(`{(\w+)}`)
+
 const traceFmtACLHitMiss = "ACL %s Position %d for subject %s and object %s (Method %s)"
@@ -3,19 +3,29 @@ package authorization
 import (
 	"fmt"
 	"net"
+	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 // Subject represents the identity of a user for the purposes of ACL matching.
 type Subject struct {
 	Username string
 	Groups   []string
+	Emails   []string
 	IP       net.IP
+
+	// ClientID is the id of the OpenID Connect client the decision is made on behalf of.
+	ClientID string
 }
 
 // String returns a string representation of the Subject.
 func (s Subject) String() string {
+	if s.ClientID != "" {
+		return fmt.Sprintf("username=%s groups=%s ip=%s client=%s", s.Username, strings.Join(s.Groups, ","), s.IP.String(), s.ClientID)
+	}
+
 	return fmt.Sprintf("username=%s groups=%s ip=%s", s.Username, strings.Join(s.Groups, ","), s.IP.String())
 }
 
@@ -30,6 +40,13 @@ type Object struct {
 	Domain string
 	Path   string
 	Method string
+
+	// Query is the parsed query of the URL and Header the headers of the request the object was requested with.
+	Query  url.Values
+	Header http.Header
+
+	// Time is the time the object is evaluated at.
+	Time time.Time
 }
 
 // String is a string representation of the Object.
@@ -37,17 +54,19 @@ func (o Object) String() string {
 	return fmt.Sprintf("%s://%s%s", o.Scheme, o.Domain, o.Path)
 }
 
-// NewObjectRaw creates a new Object type from a URL and a method header.
-func NewObjectRaw(targetURL *url.URL, method []byte) (object Object) {
-	return NewObject(targetURL, string(method))
+// NewObjectRaw creates a new Object type from a URL and a method header evaluated at the given time.
+func NewObjectRaw(targetURL *url.URL, method []byte, now time.Time) (object Object) {
+	return NewObject(targetURL, string(method), now)
 }
 
-// NewObject creates a new Object type from a URL and a method header.
-func NewObject(targetURL *url.URL, method string) (object Object) {
+// NewObject creates a new Object type from a URL and a method header evaluated at the given time.
+func NewObject(targetURL *url.URL, method string, now time.Time) (object Object) {
 	object = Object{
 		Scheme: targetURL.Scheme,
 		Domain: targetURL.Hostname(),
 		Method: method,
+		Query:  targetURL.Query(),
+		Time:   now,
 	}
 
 	if targetURL.RawQuery == "" {
@@ -58,3 +77,49 @@ func NewObject(targetURL *url.URL, method string) (object Object) {
 
 	return object
 }
+
+// RuleMatchResult describes how each criteria of a rule matched a subject and object.
+type RuleMatchResult struct {
+	Rule *AccessControlRule
+
+	// Skipped is true when an earlier rule matched, so this rule would not have been evaluated.
+	Skipped bool
+
+	MatchDomain      bool
+	MatchResources   bool
+	MatchMethods     bool
+	MatchQuery       bool
+	MatchHeaders     bool
+	MatchTimeWindows bool
+	MatchNetworks    bool
+	MatchSubjects    bool
+}
+
+// IsMatch returns true if all the criteria of the rule matched.
+func (r RuleMatchResult) IsMatch() (match bool) {
+	return r.MatchDomain && r.MatchResources && r.MatchMethods && r.MatchQuery && r.MatchHeaders &&
+		r.MatchTimeWindows && r.MatchNetworks && r.MatchSubjects
+}
+
+// Mismatches returns the names of the criteria of the rule which did not match.
+func (r RuleMatchResult) Mismatches() (criteria []string) {
+	for _, c := range []struct {
+		name  string
+		match bool
+	}{
+		{"domain", r.MatchDomain},
+		{"resources", r.MatchResources},
+		{"methods", r.MatchMethods},
+		{"query", r.MatchQuery},
+		{"headers", r.MatchHeaders},
+		{"time_windows", r.MatchTimeWindows},
+		{"networks", r.MatchNetworks},
+		{"subject", r.MatchSubjects},
+	} {
+		if !c.match {
+			criteria = append(criteria, c.name)
+		}
+	}
+
+	return criteria
+}
@@ -3,6 +3,7 @@ package authorization
 import (
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,10 +14,14 @@ func TestShouldAppendQueryParamToURL(t *testing.T) {
 
 	require.NoError(t, err)
 
-	object := NewObject(targetURL, "GET")
+	now := time.Now()
+
+	object := NewObject(targetURL, "GET", now)
 
 	assert.Equal(t, "domain.example.com", object.Domain)
 	assert.Equal(t, "GET", object.Method)
 	assert.Equal(t, "/api?type=none", object.Path)
 	assert.Equal(t, "https", object.Scheme)
+	assert.Equal(t, url.Values{"type": []string{"none"}}, object.Query)
+	assert.Equal(t, now, object.Time)
 }
@@ -2,11 +2,14 @@ package authorization
 
 import (
 	"net"
+	"net/http"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/authelia/authelia/internal/authentication"
 	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
 )
 
 // PolicyToLevel converts a string policy to int authorization level.
@@ -25,23 +28,46 @@ func PolicyToLevel(policy string) Level {
 	return Denied
 }
 
+// LevelToPolicy converts an int authorization level to string policy.
+func LevelToPolicy(level Level) (policy string) {
+	switch level {
+	case Bypass:
+		return bypass
+	case OneFactor:
+		return oneFactor
+	case TwoFactor:
+		return twoFactor
+	case Denied:
+		return deny
+	}
+
+	return deny
+}
+
 func schemaSubjectToACLSubject(subjectRule string) (subject AccessControlSubject) {
-	if strings.HasPrefix(subjectRule, userPrefix) {
-		user := strings.Trim(subjectRule[len(userPrefix):], " ")
+	if strings.HasPrefix(subjectRule, negationPrefix) {
+		if subject = schemaSubjectToACLSubject(subjectRule[len(negationPrefix):]); subject == nil {
+			return nil
+		}
 
-		return AccessControlUser{Name: user}
+		return AccessControlNegation{Subject: subject}
 	}
 
-	if strings.HasPrefix(subjectRule, groupPrefix) {
-		group := strings.Trim(subjectRule[len(groupPrefix):], " ")
-
-		return AccessControlGroup{Name: group}
+	switch {
+	case strings.HasPrefix(subjectRule, userPrefix):
+		return AccessControlUser{Name: strings.Trim(subjectRule[len(userPrefix):], " ")}
+	case strings.HasPrefix(subjectRule, groupPrefix):
+		return AccessControlGroup{Name: strings.Trim(subjectRule[len(groupPrefix):], " ")}
+	case strings.HasPrefix(subjectRule, emailPrefix):
+		return AccessControlEmail{Pattern: strings.Trim(subjectRule[len(emailPrefix):], " ")}
+	case strings.HasPrefix(subjectRule, clientPrefix):
+		return AccessControlClient{ID: strings.Trim(subjectRule[len(clientPrefix):], " ")}
 	}
 
 	return nil
 }
 
-func schemaDomainsToACL(domainRules []string) (domains []AccessControlDomain) {
+func schemaDomainsToACL(domainRules, domainRegexRules []string) (domains []AccessControlDomain) {
 	for _, domainRule := range domainRules {
 		domain := AccessControlDomain{}
 
@@ -64,9 +90,85 @@ func schemaDomainsToACL(domainRules []string) (domains []AccessControlDomain) {
 		domains = append(domains, domain)
 	}
 
+	for _, domainRegexRule := range domainRegexRules {
+		domains = append(domains, AccessControlDomain{Pattern: regexp.MustCompile(domainRegexRule)})
+	}
+
 	return domains
 }
 
+func schemaMatchersToACL(matcherRules []schema.ACLMatcher, header bool) (matchers []AccessControlMatcher) {
+	for _, matcherRule := range matcherRules {
+		matcher := AccessControlMatcher{
+			Key:      matcherRule.Key,
+			Operator: strings.ToLower(matcherRule.Operator),
+			Value:    matcherRule.Value,
+		}
+
+		if header {
+			matcher.Key = http.CanonicalHeaderKey(matcher.Key)
+		}
+
+		switch matcher.Operator {
+		case "":
+			if matcher.Value == "" {
+				matcher.Operator = operatorPresent
+			} else {
+				matcher.Operator = operatorEqual
+			}
+		case operatorPattern, operatorNotPattern:
+			matcher.Pattern = regexp.MustCompile(matcher.Value)
+		}
+
+		matchers = append(matchers, matcher)
+	}
+
+	return matchers
+}
+
+func schemaTimeWindowsToACL(timeWindowRules []schema.ACLTimeWindow) (timeWindows []AccessControlTimeWindow) {
+	for _, timeWindowRule := range timeWindowRules {
+		timeWindow := AccessControlTimeWindow{
+			End:      utils.Day,
+			Location: time.Local,
+		}
+
+		for _, dayRule := range timeWindowRule.Days {
+			if day, err := utils.ParseWeekday(dayRule); err == nil {
+				timeWindow.Days = append(timeWindow.Days, day)
+			}
+		}
+
+		if timeWindowRule.Start != "" {
+			timeWindow.Start, _ = utils.ParseTimeOfDay(timeWindowRule.Start)
+		}
+
+		if timeWindowRule.End != "" {
+			timeWindow.End, _ = utils.ParseTimeOfDay(timeWindowRule.End)
+		}
+
+		if timeWindowRule.Timezone != "" {
+			if location, err := time.LoadLocation(timeWindowRule.Timezone); err == nil {
+				timeWindow.Location = location
+			}
+		}
+
+		timeWindows = append(timeWindows, timeWindow)
+	}
+
+	return timeWindows
+}
+
+func schemaMaxAgeToACL(maxAgeRule string) (maxAge time.Duration) {
+	if maxAgeRule == "" {
+		return 0
+	}
+
+	maxAge, _ = utils.ParseDurationString(maxAgeRule)
+
+	return maxAge
+}
+
 func schemaResourcesToACL(resourceRules []string) (resources []AccessControlResource) {
 	for _, resourceRule := range resourceRules {
 		resources = append(resources, AccessControlResource{regexp.MustCompile(resourceRule)})
@@ -167,6 +269,73 @@ func schemaSubjectsToACL(subjectRules [][]string) (subjects []AccessControlSubje
 	return subjects
 }
 
+// addPatternCaptures adds the values of the named capture groups of the pattern for the value to captures. The
+// values of the groups which didn't participate in the match are not added.
+func addPatternCaptures(pattern *regexp.Regexp, value string, captures map[string]string) {
+	matches := pattern.FindStringSubmatchIndex(value)
+	if matches == nil {
+		return
+	}
+
+	for i, name := range pattern.SubexpNames() {
+		if name == "" || matches[2*i] < 0 {
+			continue
+		}
+
+		captures[name] = value[matches[2*i]:matches[2*i+1]]
+	}
+}
+
+// hasCapturePlaceholders returns true if the value references named capture groups with the `{Name}` syntax.
+func hasCapturePlaceholders(value string) bool {
+	return reCapturePlaceholder.MatchString(value)
+}
+
+// expandCaptures replaces the `{Name}` placeholders of the value with the values of the named capture groups. It
+// returns false if one of the placeholders references a capture group which didn't match.
+func expandCaptures(value string, captures map[string]string) (expanded string, ok bool) {
+	ok = true
+
+	expanded = reCapturePlaceholder.ReplaceAllStringFunc(value, func(placeholder string) string {
+		captured, found := captures[placeholder[1:len(placeholder)-1]]
+		if !found || captured == "" {
+			ok = false
+		}
+
+		return captured
+	})
+
+	return expanded, ok
+}
+
+// isWildcardMatchFold returns true if the value matches the pattern case-insensitively, where `*` in the pattern
+// matches any sequence of characters.
+func isWildcardMatchFold(pattern, value string) bool {
+	parts := strings.Split(strings.ToLower(pattern), "*")
+	value = strings.ToLower(value)
+
+	if len(parts) == 1 {
+		return value == parts[0]
+	}
+
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+
+	value = value[len(parts[0]):]
+
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(value, part)
+		if i == -1 {
+			return false
+		}
+
+		value = value[i+len(part):]
+	}
+
+	return strings.HasSuffix(value, parts[len(parts)-1])
+}
+
 func domainToPrefixSuffix(domain string) (prefix, suffix string) {
 	parts := strings.Split(domain, ".")
 
@@ -19,7 +19,24 @@ func TestShouldNotParseInvalidSubjects(t *testing.T) {
 
 	require.Len(t, subjectsACL[0].Subjects, 1)
 
-	assert.True(t, subjectsACL[0].IsMatch(Subject{Username: "a", Groups: []string{"z"}}))
+	assert.True(t, subjectsACL[0].IsMatch(Subject{Username: "a", Groups: []string{"z"}}, nil))
+}
+
+func TestShouldExpandCaptures(t *testing.T) {
+	captures := map[string]string{"User": "john", "Team": ""}
+
+	assert.True(t, hasCapturePlaceholders("{User}"))
+	assert.False(t, hasCapturePlaceholders("john"))
+
+	expanded, ok := expandCaptures("{User}-admins", captures)
+	assert.True(t, ok)
+	assert.Equal(t, "john-admins", expanded)
+
+	_, ok = expandCaptures("{Team}", captures)
+	assert.False(t, ok)
+
+	_, ok = expandCaptures("{Group}", captures)
+	assert.False(t, ok)
 }
 
 func TestShouldSplitDomainCorrectly(t *testing.T) {
@@ -195,3 +212,27 @@ func TestShouldReturnCorrectValidationLevel(t *testing.T) {
 	assert.False(t, IsAuthLevelSufficient(authentication.OneFactor, TwoFactor))
 	assert.True(t, IsAuthLevelSufficient(authentication.TwoFactor, TwoFactor))
 }
+
+func TestShouldMatchWildcardsCaseInsensitively(t *testing.T) {
+	assert.True(t, isWildcardMatchFold("*@example.com", "john@Example.com"))
+	assert.True(t, isWildcardMatchFold("john@example.com", "JOHN@example.com"))
+	assert.True(t, isWildcardMatchFold("j*@*.example.com", "john@mail.example.com"))
+	assert.True(t, isWildcardMatchFold("*", "anything"))
+	assert.True(t, isWildcardMatchFold("a*a", "aa"))
+
+	assert.False(t, isWildcardMatchFold("*@example.com", "john@example.com.evil.com"))
+	assert.False(t, isWildcardMatchFold("john@example.com", "john@example.org"))
+	assert.False(t, isWildcardMatchFold("a*a", "a"))
+	assert.False(t, isWildcardMatchFold("j*@*.example.com", "john@example.com"))
+}
+
+func TestShouldParseNegatedEmailAndClientSubjects(t *testing.T) {
+	subjectsACL := schemaSubjectsToACL([][]string{{"!group:contractors", "email:*@example.com", "oidc:app"}, {"!invalid:x"}})
+
+	require.Len(t, subjectsACL, 1)
+	require.Len(t, subjectsACL[0].Subjects, 3)
+
+	assert.Equal(t, AccessControlNegation{Subject: AccessControlGroup{Name: "contractors"}}, subjectsACL[0].Subjects[0])
+	assert.Equal(t, AccessControlEmail{Pattern: "*@example.com"}, subjectsACL[0].Subjects[1])
+	assert.Equal(t, AccessControlClient{ID: "app"}, subjectsACL[0].Subjects[2])
+}
@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authelia/authelia/internal/authorization"
+	"github.com/authelia/authelia/internal/configuration"
+)
+
+func init() {
+	AccessControlCheckPolicyCmd.Flags().String("config", "", "the configuration file to load the access control rules from")
+	AccessControlCheckPolicyCmd.Flags().String("url", "", "the url of the object")
+	AccessControlCheckPolicyCmd.Flags().String("method", "GET", "the HTTP method of the object")
+	AccessControlCheckPolicyCmd.Flags().String("username", "", "the username of the subject")
+	AccessControlCheckPolicyCmd.Flags().StringSlice("groups", nil, "the groups of the subject")
+	AccessControlCheckPolicyCmd.Flags().StringSlice("emails", nil, "the emails of the subject")
+	AccessControlCheckPolicyCmd.Flags().String("client-id", "", "the id of the OpenID Connect client the subject uses")
+	AccessControlCheckPolicyCmd.Flags().String("ip", "", "the ip of the subject")
+	AccessControlCheckPolicyCmd.Flags().StringArray("header", nil, "a header of the object in the 'Name: value' format, can be repeated")
+	AccessControlCheckPolicyCmd.Flags().String("time", "", "the time the object is evaluated at in the RFC3339 format, defaults to now")
+
+	_ = AccessControlCheckPolicyCmd.MarkFlagRequired("config")
+	_ = AccessControlCheckPolicyCmd.MarkFlagRequired("url")
+
+	AccessControlCmd.AddCommand(AccessControlCheckPolicyCmd)
+}
+
+// AccessControlCmd access control helper command.
+var AccessControlCmd = &cobra.Command{
+	Use:   "access-control",
+	Short: "Commands related to the access control rules",
+}
+
+// AccessControlCheckPolicyCmd checks the policy the access control rules apply to a request.
+var AccessControlCheckPolicyCmd = &cobra.Command{
+	Use:   "check-policy",
+	Short: "Check the policy the access control rules of a configuration apply to a subject and object.",
+	Run:   accessControlCheckPolicy,
+}
+
+func accessControlCheckPolicy(cobraCmd *cobra.Command, args []string) {
+	configPath, _ := cobraCmd.Flags().GetString("config")
+
+	config, errs := configuration.Read(configPath)
+	if len(errs) != 0 {
+		errors := ""
+		for _, err := range errs {
+			errors += fmt.Sprintf("\t%s\n", err.Error())
+		}
+
+		log.Fatalf("Error(s) occurred parsing configuration:\n%s", errors)
+	}
+
+	subject, object, err := accessControlCheckPolicyRequest(cobraCmd)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	authorizer := authorization.NewAuthorizer(config)
+
+	fmt.Printf("Performing policy check for subject %s and object %s (method %s).\n\n", subject.String(), object.String(), object.Method)
+
+	for _, result := range authorizer.GetRuleMatchResults(subject, object) {
+		if result.Skipped {
+			break
+		}
+
+		if !result.IsMatch() {
+			fmt.Printf("Rule #%d did not match on: %s.\n", result.Rule.Position, strings.Join(result.Mismatches(), ", "))
+
+			continue
+		}
+
+		fmt.Printf("Rule #%d matched.\n\nThe required policy is '%s'.\n", result.Rule.Position, authorization.LevelToPolicy(result.Rule.Policy))
+
+		if result.Rule.MaxAge != 0 {
+			fmt.Printf("The second factor authentication must have been completed within the last %s.\n", result.Rule.MaxAge)
+		}
+
+		if subject.IsAnonymous() && len(result.Rule.Subjects) != 0 {
+			fmt.Println("The rule has subjects which are only evaluated once the subject is authenticated, the rules may match differently then.")
+		}
+
+		return
+	}
+
+	fmt.Printf("No rule matched.\n\nThe required policy is the default policy '%s'.\n", authorization.LevelToPolicy(authorizer.DefaultPolicy()))
+}
+
+func accessControlCheckPolicyRequest(cobraCmd *cobra.Command) (subject authorization.Subject, object authorization.Object, err error) {
+	rawURL, _ := cobraCmd.Flags().GetString("url")
+	method, _ := cobraCmd.Flags().GetString("method")
+	username, _ := cobraCmd.Flags().GetString("username")
+	groups, _ := cobraCmd.Flags().GetStringSlice("groups")
+	emails, _ := cobraCmd.Flags().GetStringSlice("emails")
+	clientID, _ := cobraCmd.Flags().GetString("client-id")
+	ip, _ := cobraCmd.Flags().GetString("ip")
+	headers, _ := cobraCmd.Flags().GetStringArray("header")
+	rawTime, _ := cobraCmd.Flags().GetString("time")
+
+	targetURL, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		return subject, object, fmt.Errorf("Unable to parse the url %s: %w", rawURL, err)
+	}
+
+	subject = authorization.Subject{
+		Username: username,
+		Groups:   groups,
+		Emails:   emails,
+		ClientID: clientID,
+	}
+
+	if ip != "" {
+		if subject.IP = net.ParseIP(ip); subject.IP == nil {
+			return subject, object, fmt.Errorf("Unable to parse the ip %s", ip)
+		}
+	}
+
+	now := time.Now()
+
+	if rawTime != "" {
+		if now, err = time.Parse(time.RFC3339, rawTime); err != nil {
+			return subject, object, fmt.Errorf("Unable to parse the time %s: %w", rawTime, err)
+		}
+	}
+
+	object = authorization.NewObject(targetURL, strings.ToUpper(method), now)
+	object.Header = http.Header{}
+
+	for _, header := range headers {
+		parts := strings.SplitN(header, ":", 2)
+		if len(parts) != 2 {
+			return subject, object, fmt.Errorf("Unable to parse the header %s, it must be in the 'Name: value' format", header)
+		}
+
+		object.Header.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+
+	return subject, object, nil
+}
@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authelia/authelia/internal/configuration"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/regulation"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{RegulationListCmd, RegulationBanCmd, RegulationUnbanCmd} {
+		cmd.Flags().String("config", "", "the configuration file of the regulation and storage backend")
+		_ = cmd.MarkFlagRequired("config")
+
+		RegulationCmd.AddCommand(cmd)
+	}
+
+	for _, cmd := range []*cobra.Command{RegulationBanCmd, RegulationUnbanCmd} {
+		cmd.Flags().String("admin", "", "the administrator recorded in the authentication log, defaults to the current user")
+	}
+
+	RegulationBanCmd.Flags().String("duration", "", "the duration of the ban in the duration notation format, defaults to the ban time of the type")
+	RegulationBanCmd.Flags().String("reason", "", "the reason of the ban")
+}
+
+// RegulationCmd regulation helper command.
+var RegulationCmd = &cobra.Command{
+	Use:   "regulation",
+	Short: "Commands related to the bans of the regulation",
+}
+
+// RegulationListCmd lists the active bans of the regulation.
+var RegulationListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the banned users, IP addresses and networks along with their number of failed authentication attempts.",
+	Args:  cobra.NoArgs,
+	Run:   regulationList,
+}
+
+// RegulationBanCmd bans a user, an IP address or a network.
+var RegulationBanCmd = &cobra.Command{
+	Use:   "ban [user|ip|network] [value]",
+	Short: "Ban a user, an IP address or a network.",
+	Args:  cobra.ExactArgs(2),
+	Run:   regulationBan,
+}
+
+// RegulationUnbanCmd unbans a user, an IP address or a network.
+var RegulationUnbanCmd = &cobra.Command{
+	Use:   "unban [user|ip|network] [value]",
+	Short: "Unban a user, an IP address or a network.",
+	Args:  cobra.ExactArgs(2),
+	Run:   regulationUnban,
+}
+
+func regulationList(cobraCmd *cobra.Command, _ []string) {
+	regulator := newRegulationRegulator(cobraCmd)
+
+	bans, err := regulator.Bans()
+	if err != nil {
+		log.Fatalf("Unable to load the bans: %v", err)
+	}
+
+	if len(bans) == 0 {
+		fmt.Println("No user, IP address or network is banned.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "TYPE\tVALUE\tBANNED UNTIL\tFAILURES\tREASON")
+
+	for _, ban := range bans {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", ban.Type, ban.Value, ban.BannedUntil.Format(time.RFC3339), ban.Failures, ban.Reason)
+	}
+
+	_ = w.Flush()
+}
+
+func regulationBan(cobraCmd *cobra.Command, args []string) {
+	regulator := newRegulationRegulator(cobraCmd)
+
+	rawDuration, _ := cobraCmd.Flags().GetString("duration")
+	reason, _ := cobraCmd.Flags().GetString("reason")
+
+	var (
+		duration time.Duration
+		err      error
+	)
+
+	if rawDuration != "" {
+		if duration, err = utils.ParseDurationString(rawDuration); err != nil || duration <= 0 {
+			log.Fatalf("Unable to parse the duration %s of the ban", rawDuration)
+		}
+	}
+
+	ban, err := regulator.Ban(args[0], args[1], duration, reason, regulationAdmin(cobraCmd))
+	if err != nil {
+		log.Fatalf("Unable to ban the %s %s: %v", args[0], args[1], err)
+	}
+
+	fmt.Printf("The %s %s is banned until %s.\n", ban.Type, ban.Value, ban.ExpiresAt.Format(time.RFC3339))
+}
+
+func regulationUnban(cobraCmd *cobra.Command, args []string) {
+	regulator := newRegulationRegulator(cobraCmd)
+
+	if err := regulator.Unban(args[0], args[1], regulationAdmin(cobraCmd)); err != nil {
+		log.Fatalf("Unable to unban the %s %s: %v", args[0], args[1], err)
+	}
+
+	fmt.Printf("The %s %s is unbanned.\n", args[0], args[1])
+}
+
+func newRegulationRegulator(cobraCmd *cobra.Command) *regulation.Regulator {
+	configPath, _ := cobraCmd.Flags().GetString("config")
+
+	config, errs := configuration.Read(configPath)
+	if len(errs) != 0 {
+		errors := ""
+		for _, err := range errs {
+			errors += fmt.Sprintf("\t%s\n", err.Error())
+		}
+
+		log.Fatalf("Error(s) occurred parsing configuration:\n%s", errors)
+	}
+
+	return regulation.NewRegulator(config.Regulation, newStorageProvider(config.Storage), utils.RealClock{})
+}
+
+func newStorageProvider(config schema.StorageConfiguration) storage.Provider {
+	switch {
+	case config.PostgreSQL != nil:
+		return storage.NewPostgreSQLProvider(*config.PostgreSQL)
+	case config.MySQL != nil:
+		return storage.NewMySQLProvider(*config.MySQL)
+	case config.Local != nil:
+		return storage.NewSQLiteProvider(config.Local.Path)
+	default:
+		log.Fatal("Unrecognized storage backend")
+	}
+
+	return nil
+}
+
+// regulationAdmin returns the administrator recorded in the authentication log.
+func regulationAdmin(cobraCmd *cobra.Command) string {
+	if admin, _ := cobraCmd.Flags().GetString("admin"); admin != "" {
+		return admin
+	}
+
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+
+	log.Fatal("Unable to determine the current user, the administrator must be provided with the --admin flag")
+
+	return ""
+}
@@ -7,7 +7,9 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v2"
 
@@ -66,7 +68,12 @@ func Read(configPath string) (*schema.Configuration, []error) {
 
 	var configuration schema.Configuration
 
-	viper.Unmarshal(&configuration) //nolint:errcheck // TODO: Legacy code, consider refactoring time permitting.
+	viper.Unmarshal(&configuration, viper.DecodeHook(decodeHook)) //nolint:errcheck // TODO: Legacy code, consider refactoring time permitting.
+
+	// The empty maps are omitted when unmarshalling, the sql backend has no required option so it's usually one.
+	if configuration.AuthenticationBackend.SQL == nil && viper.IsSet("authentication_backend.sql") {
+		configuration.AuthenticationBackend.SQL = &schema.SQLAuthenticationBackendConfiguration{}
+	}
 
 	val := schema.NewStructValidator()
 	validator.ValidateSecrets(&configuration, val, viper.GetViper())
@@ -86,6 +93,12 @@ func Read(configPath string) (*schema.Configuration, []error) {
 	return &configuration, nil
 }
 
+var decodeHook = mapstructure.ComposeDecodeHookFunc(
+	mapstructure.StringToTimeDurationHookFunc(),
+	mapstructure.StringToSliceHookFunc(","),
+	mapstructure.StringToTimeHookFunc(time.RFC3339),
+)
+
 //go:embed config.template.yml
 var cfg []byte
 
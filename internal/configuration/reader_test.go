@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
 	"github.com/authelia/authelia/internal/utils"
 )
 
@@ -148,7 +149,7 @@ func TestShouldErrorPermissionsConfigFile(t *testing.T) {
 	if runtime.GOOS == windows {
 		require.Len(t, errors, 5)
 		assert.EqualError(t, errors[0], "Provide a JWT secret using \"jwt_secret\" key")
-		assert.EqualError(t, errors[1], "Please provide `ldap` or `file` object in `authentication_backend`")
+		assert.EqualError(t, errors[1], "Please provide `ldap`, `file` or `sql` object in `authentication_backend`")
 		assert.EqualError(t, errors[2], "Set domain of the session object")
 		assert.EqualError(t, errors[3], "A storage configuration must be provided. It could be 'local', 'mysql' or 'postgres'")
 		assert.EqualError(t, errors[4], "A notifier configuration must be provided")
@@ -234,6 +235,22 @@ func TestShouldParseAltConfigFile(t *testing.T) {
 	assert.Len(t, config.AccessControl.Rules, 12)
 }
 
+func TestShouldParseSQLAuthenticationBackendWithoutOptions(t *testing.T) {
+	dir := setupEnv(t)
+
+	require.NoError(t, os.Setenv("AUTHELIA_STORAGE_POSTGRES_PASSWORD_FILE", dir+"postgres"))
+	require.NoError(t, os.Setenv("AUTHELIA_JWT_SECRET_FILE", dir+"jwt"))
+	require.NoError(t, os.Setenv("AUTHELIA_SESSION_SECRET_FILE", dir+"session"))
+
+	config, errors := Read("./test_resources/config_sql.yml")
+	require.Len(t, errors, 0)
+
+	require.NotNil(t, config.AuthenticationBackend.SQL)
+	assert.Nil(t, config.AuthenticationBackend.File)
+	assert.Nil(t, config.AuthenticationBackend.LDAP)
+	assert.Equal(t, &schema.DefaultPasswordConfiguration, config.AuthenticationBackend.SQL.Password)
+}
+
 func TestShouldNotParseConfigFileWithOldOrUnexpectedKeys(t *testing.T) {
 	dir := setupEnv(t)
 
@@ -5,6 +5,12 @@ type AccessControlConfiguration struct {
 	DefaultPolicy string       `mapstructure:"default_policy"`
 	Networks      []ACLNetwork `mapstructure:"networks"`
 	Rules         []ACLRule    `mapstructure:"rules"`
+	Explain       ACLExplain   `mapstructure:"explain"`
+}
+
+// ACLExplain represents the configuration related to explaining the access control decisions of the verify endpoint.
+type ACLExplain struct {
+	Networks []string `mapstructure:"networks"`
 }
 
 // ACLNetwork represents one ACL network group entry; "weak" coerces a single value into slice.
@@ -15,12 +21,34 @@ type ACLNetwork struct {
 
 // ACLRule represents one ACL rule entry; "weak" coerces a single value into slice.
 type ACLRule struct {
-	Domains   []string   `mapstructure:"domain,weak"`
-	Policy    string     `mapstructure:"policy"`
-	Subjects  [][]string `mapstructure:"subject,weak"`
-	Networks  []string   `mapstructure:"networks"`
-	Resources []string   `mapstructure:"resources"`
-	Methods   []string   `mapstructure:"methods"`
+	Domains      []string   `mapstructure:"domain,weak"`
+	DomainsRegex []string   `mapstructure:"domain_regex,weak"`
+	Policy       string     `mapstructure:"policy"`
+	Subjects     [][]string `mapstructure:"subject,weak"`
+	Networks     []string   `mapstructure:"networks"`
+	Resources    []string   `mapstructure:"resources"`
+	Methods      []string   `mapstructure:"methods"`
+
+	Query       []ACLMatcher    `mapstructure:"query"`
+	Headers     []ACLMatcher    `mapstructure:"headers"`
+	TimeWindows []ACLTimeWindow `mapstructure:"time_windows"`
+
+	MaxAge string `mapstructure:"max_age"`
+}
+
+// ACLMatcher represents one ACL criteria on a query argument or a header of the request.
+type ACLMatcher struct {
+	Key      string `mapstructure:"key"`
+	Operator string `mapstructure:"operator"`
+	Value    string `mapstructure:"value"`
+}
+
+// ACLTimeWindow represents one ACL criteria on the time of the request; "weak" coerces a single value into slice.
+type ACLTimeWindow struct {
+	Days     []string `mapstructure:"days,weak"`
+	Start    string   `mapstructure:"start"`
+	End      string   `mapstructure:"end"`
+	Timezone string   `mapstructure:"timezone"`
 }
 
 // DefaultACLNetwork represents the default configuration related to access control network group configuration.
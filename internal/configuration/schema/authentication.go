@@ -2,21 +2,35 @@ package schema
 
 // LDAPAuthenticationBackendConfiguration represents the configuration related to LDAP server.
 type LDAPAuthenticationBackendConfiguration struct {
-	Implementation       string     `mapstructure:"implementation"`
-	URL                  string     `mapstructure:"url"`
-	BaseDN               string     `mapstructure:"base_dn"`
-	AdditionalUsersDN    string     `mapstructure:"additional_users_dn"`
-	UsersFilter          string     `mapstructure:"users_filter"`
-	AdditionalGroupsDN   string     `mapstructure:"additional_groups_dn"`
-	GroupsFilter         string     `mapstructure:"groups_filter"`
-	GroupNameAttribute   string     `mapstructure:"group_name_attribute"`
-	UsernameAttribute    string     `mapstructure:"username_attribute"`
-	MailAttribute        string     `mapstructure:"mail_attribute"`
-	DisplayNameAttribute string     `mapstructure:"display_name_attribute"`
-	User                 string     `mapstructure:"user"`
-	Password             string     `mapstructure:"password"`
-	StartTLS             bool       `mapstructure:"start_tls"`
-	TLS                  *TLSConfig `mapstructure:"tls"`
+	Implementation         string     `mapstructure:"implementation"`
+	URL                    string     `mapstructure:"url"`
+	URLs                   []string   `mapstructure:"urls"`
+	URLStrategy            string     `mapstructure:"url_strategy"`
+	HealthCheckInterval    string     `mapstructure:"health_check_interval"`
+	BaseDN                 string     `mapstructure:"base_dn"`
+	AdditionalUsersDN      string     `mapstructure:"additional_users_dn"`
+	UsersFilter            string     `mapstructure:"users_filter"`
+	AdditionalGroupsDN     string     `mapstructure:"additional_groups_dn"`
+	GroupsFilter           string     `mapstructure:"groups_filter"`
+	GroupSearchMode        string     `mapstructure:"group_search_mode"`
+	GroupNameAttribute     string     `mapstructure:"group_name_attribute"`
+	MemberOfAttribute      string     `mapstructure:"member_of_attribute"`
+	UsernameAttribute      string     `mapstructure:"username_attribute"`
+	MailAttribute          string     `mapstructure:"mail_attribute"`
+	DisplayNameAttribute   string     `mapstructure:"display_name_attribute"`
+	PasswordChangeStrategy string     `mapstructure:"password_change_strategy"`
+	User                   string     `mapstructure:"user"`
+	Password               string     `mapstructure:"password"`
+	StartTLS               bool       `mapstructure:"start_tls"`
+	TLS                    *TLSConfig `mapstructure:"tls"`
+
+	Pool LDAPPoolConfiguration `mapstructure:"pool"`
+}
+
+// LDAPPoolConfiguration represents the configuration of the pool of connections of the LDAP bind user.
+type LDAPPoolConfiguration struct {
+	MaxConnections int    `mapstructure:"max_connections"`
+	Timeout        string `mapstructure:"timeout"`
 }
 
 // FileAuthenticationBackendConfiguration represents the configuration related to file-based backend.
@@ -25,6 +39,12 @@ type FileAuthenticationBackendConfiguration struct {
 	Password *PasswordConfiguration `mapstructure:"password"`
 }
 
+// SQLAuthenticationBackendConfiguration represents the configuration related to the backend reading the users from the
+// SQL database of the storage.
+type SQLAuthenticationBackendConfiguration struct {
+	Password *PasswordConfiguration `mapstructure:"password"`
+}
+
 // PasswordConfiguration represents the configuration related to password hashing.
 type PasswordConfiguration struct {
 	Iterations  int    `mapstructure:"iterations"`
@@ -41,6 +61,7 @@ type AuthenticationBackendConfiguration struct {
 	RefreshInterval      string                                  `mapstructure:"refresh_interval"`
 	LDAP                 *LDAPAuthenticationBackendConfiguration `mapstructure:"ldap"`
 	File                 *FileAuthenticationBackendConfiguration `mapstructure:"file"`
+	SQL                  *SQLAuthenticationBackendConfiguration  `mapstructure:"sql"`
 }
 
 // DefaultPasswordConfiguration represents the default configuration related to Argon2id hashing.
@@ -72,14 +93,22 @@ var DefaultPasswordSHA512Configuration = PasswordConfiguration{
 
 // DefaultLDAPAuthenticationBackendConfiguration represents the default LDAP config.
 var DefaultLDAPAuthenticationBackendConfiguration = LDAPAuthenticationBackendConfiguration{
-	Implementation:       LDAPImplementationCustom,
-	UsernameAttribute:    "uid",
-	MailAttribute:        "mail",
-	DisplayNameAttribute: "displayname",
-	GroupNameAttribute:   "cn",
+	Implementation:         LDAPImplementationCustom,
+	URLStrategy:            LDAPURLStrategyFailover,
+	HealthCheckInterval:    "1m",
+	GroupSearchMode:        LDAPGroupSearchModeFilter,
+	MemberOfAttribute:      "memberOf",
+	UsernameAttribute:      "uid",
+	MailAttribute:          "mail",
+	DisplayNameAttribute:   "displayname",
+	GroupNameAttribute:     "cn",
+	PasswordChangeStrategy: LDAPPasswordChangeStrategyAuto,
 	TLS: &TLSConfig{
 		MinimumVersion: "TLS1.2",
 	},
+	Pool: LDAPPoolConfiguration{
+		Timeout: "5s",
+	},
 }
 
 // DefaultLDAPAuthenticationBackendImplementationActiveDirectoryConfiguration represents the default LDAP config for the MSAD Implementation.
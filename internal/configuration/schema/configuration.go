@@ -22,6 +22,7 @@ type Configuration struct {
 	AuthenticationBackend AuthenticationBackendConfiguration `mapstructure:"authentication_backend"`
 	Session               SessionConfiguration               `mapstructure:"session"`
 	TOTP                  *TOTPConfiguration                 `mapstructure:"totp"`
+	Webauthn              *WebauthnConfiguration             `mapstructure:"webauthn"`
 	DuoAPI                *DuoAPIConfiguration               `mapstructure:"duo_api"`
 	AccessControl         AccessControlConfiguration         `mapstructure:"access_control"`
 	Regulation            *RegulationConfiguration           `mapstructure:"regulation"`
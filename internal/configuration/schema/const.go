@@ -23,3 +23,40 @@ const LDAPImplementationCustom = "custom"
 
 // LDAPImplementationActiveDirectory is the string for the Active Directory LDAP implementation.
 const LDAPImplementationActiveDirectory = "activedirectory"
+
+// LDAPURLStrategyFailover is the string for the strategy using the LDAP servers in the configured order.
+const LDAPURLStrategyFailover = "failover"
+
+// LDAPURLStrategyRoundRobin is the string for the strategy using the LDAP servers in turn.
+const LDAPURLStrategyRoundRobin = "round_robin"
+
+// LDAPPasswordChangeStrategyAuto is the string for the password change strategy using the password modify extended
+// operation when the LDAP server supports it, the unicodePwd attribute with Active Directory and the userPassword
+// attribute otherwise.
+const LDAPPasswordChangeStrategyAuto = "auto"
+
+// LDAPPasswordChangeStrategyPasswordModify is the string for the password change strategy using the password modify
+// extended operation of RFC 3062.
+const LDAPPasswordChangeStrategyPasswordModify = "password_modify"
+
+// LDAPPasswordChangeStrategyUnicodePwd is the string for the password change strategy replacing the unicodePwd
+// attribute of Active Directory.
+const LDAPPasswordChangeStrategyUnicodePwd = "unicode_pwd"
+
+// LDAPPasswordChangeStrategyReplace is the string for the password change strategy replacing the userPassword
+// attribute.
+const LDAPPasswordChangeStrategyReplace = "replace"
+
+// LDAPGroupSearchModeFilter is the string for the group search mode using a single search with the groups filter.
+const LDAPGroupSearchModeFilter = "filter"
+
+// LDAPGroupSearchModeRecursive is the string for the group search mode searching the groups of the groups with the
+// groups filter.
+const LDAPGroupSearchModeRecursive = "recursive"
+
+// LDAPGroupSearchModeMemberOf is the string for the group search mode reading the memberOf attribute of the user.
+const LDAPGroupSearchModeMemberOf = "memberof"
+
+// LDAPGroupSearchModeInChain is the string for the group search mode using the Active Directory
+// LDAP_MATCHING_RULE_IN_CHAIN.
+const LDAPGroupSearchModeInChain = "in_chain"
@@ -13,16 +13,78 @@ type OpenIDConnectConfiguration struct {
 	HMACSecret       string `mapstructure:"hmac_secret"`
 	IssuerPrivateKey string `mapstructure:"issuer_private_key"`
 
+	IssuerPrivateKeys []OpenIDConnectIssuerPrivateKeyConfiguration `mapstructure:"issuer_private_keys"`
+
 	AccessTokenLifespan       time.Duration `mapstructure:"access_token_lifespan"`
 	AuthorizeCodeLifespan     time.Duration `mapstructure:"authorize_code_lifespan"`
 	IDTokenLifespan           time.Duration `mapstructure:"id_token_lifespan"`
 	RefreshTokenLifespan      time.Duration `mapstructure:"refresh_token_lifespan"`
+	DeviceCodeLifespan        time.Duration `mapstructure:"device_code_lifespan"`
+	DeviceCodePollingInterval time.Duration `mapstructure:"device_code_polling_interval"`
 	EnableClientDebugMessages bool          `mapstructure:"enable_client_debug_messages"`
 	MinimumParameterEntropy   int           `mapstructure:"minimum_parameter_entropy"`
 
+	PushedAuthorizationRequestLifespan time.Duration `mapstructure:"pushed_authorization_request_lifespan"`
+
+	ClientRegistration OpenIDConnectClientRegistrationConfiguration `mapstructure:"client_registration"`
+
+	ClaimsPolicies []OpenIDConnectClaimsPolicyConfiguration `mapstructure:"claims_policies"`
+
 	Clients []OpenIDConnectClientConfiguration `mapstructure:"clients"`
 }
 
+// OpenIDConnectClaimsPolicyConfiguration configuration for the claims issued to the OpenID Connect clients using the
+// policy in addition to the standard claims.
+type OpenIDConnectClaimsPolicyConfiguration struct {
+	Name         string                                  `mapstructure:"name"`
+	CustomScopes []OpenIDConnectCustomScopeConfiguration `mapstructure:"custom_scopes"`
+	CustomClaims []OpenIDConnectCustomClaimConfiguration `mapstructure:"custom_claims"`
+	RenameClaims []OpenIDConnectRenameClaimConfiguration `mapstructure:"rename_claims"`
+}
+
+// OpenIDConnectCustomScopeConfiguration configuration for a scope which is only used by custom claims.
+type OpenIDConnectCustomScopeConfiguration struct {
+	Name        string `mapstructure:"name"`
+	Description string `mapstructure:"description"`
+}
+
+// OpenIDConnectCustomClaimConfiguration configuration for a custom claim. The value is either taken from an attribute
+// of the user or mapped from the groups of the user.
+type OpenIDConnectCustomClaimConfiguration struct {
+	Name         string                                  `mapstructure:"name"`
+	Scope        string                                  `mapstructure:"scope"`
+	Attribute    string                                  `mapstructure:"attribute"`
+	Roles        []OpenIDConnectRoleMappingConfiguration `mapstructure:"roles"`
+	DefaultValue string                                  `mapstructure:"default_value"`
+	MultiValued  bool                                    `mapstructure:"multi_valued"`
+}
+
+// OpenIDConnectRoleMappingConfiguration configuration for the role given to the members of a group.
+type OpenIDConnectRoleMappingConfiguration struct {
+	Group string `mapstructure:"group"`
+	Role  string `mapstructure:"role"`
+}
+
+// OpenIDConnectRenameClaimConfiguration configuration for renaming a standard claim.
+type OpenIDConnectRenameClaimConfiguration struct {
+	Claim string `mapstructure:"claim"`
+	Name  string `mapstructure:"name"`
+}
+
+// OpenIDConnectClientRegistrationConfiguration configuration for the OpenID Connect clients registered at runtime.
+type OpenIDConnectClientRegistrationConfiguration struct {
+	AdminGroup         string `mapstructure:"admin_group"`
+	InitialAccessToken string `mapstructure:"initial_access_token"`
+}
+
+// OpenIDConnectIssuerPrivateKeyConfiguration configuration for an additional OpenID Connect issuer private key.
+type OpenIDConnectIssuerPrivateKeyConfiguration struct {
+	KeyID      string    `mapstructure:"key_id"`
+	Algorithm  string    `mapstructure:"algorithm"`
+	Key        string    `mapstructure:"key"`
+	ActivateAt time.Time `mapstructure:"activate_at"`
+}
+
 // OpenIDConnectClientConfiguration configuration for an OpenID Connect client.
 type OpenIDConnectClientConfiguration struct {
 	ID            string   `mapstructure:"id"`
@@ -31,11 +93,31 @@ type OpenIDConnectClientConfiguration struct {
 	RedirectURIs  []string `mapstructure:"redirect_uris"`
 	Policy        string   `mapstructure:"authorization_policy"`
 	Scopes        []string `mapstructure:"scopes"`
+	Audience      []string `mapstructure:"audience"`
 	GrantTypes    []string `mapstructure:"grant_types"`
 	ResponseTypes []string `mapstructure:"response_types"`
 	ResponseModes []string `mapstructure:"response_modes"`
 
+	IDTokenSigningAlgorithm  string `mapstructure:"id_token_signing_algorithm"`
 	UserinfoSigningAlgorithm string `mapstructure:"userinfo_signing_algorithm"`
+
+	ConsentMode                  string        `mapstructure:"consent_mode"`
+	PreConfiguredConsentDuration time.Duration `mapstructure:"pre_configured_consent_duration"`
+
+	ClaimsPolicy string `mapstructure:"claims_policy"`
+
+	TokenEndpointAuthMethod string `mapstructure:"token_endpoint_auth_method"`
+
+	RequirePushedAuthorizationRequests bool `mapstructure:"require_pushed_authorization_requests"`
+	RequirePKCE                        bool `mapstructure:"require_pkce"`
+
+	RequestObjectSigningAlgorithm string   `mapstructure:"request_object_signing_algorithm"`
+	RequestURIs                   []string `mapstructure:"request_uris"`
+	JWKSURI                       string   `mapstructure:"jwks_uri"`
+
+	PostLogoutRedirectURIs []string `mapstructure:"post_logout_redirect_uris"`
+	FrontChannelLogoutURI  string   `mapstructure:"frontchannel_logout_uri"`
+	BackChannelLogoutURI   string   `mapstructure:"backchannel_logout_uri"`
 }
 
 // DefaultOpenIDConnectConfiguration contains defaults for OIDC.
@@ -44,6 +126,11 @@ var DefaultOpenIDConnectConfiguration = OpenIDConnectConfiguration{
 	AuthorizeCodeLifespan: time.Minute,
 	IDTokenLifespan:       time.Hour,
 	RefreshTokenLifespan:  time.Minute * 90,
+
+	DeviceCodeLifespan:        time.Minute * 10,
+	DeviceCodePollingInterval: time.Second * 5,
+
+	PushedAuthorizationRequestLifespan: time.Minute * 5,
 }
 
 // DefaultOpenIDConnectClientConfiguration contains defaults for OIDC Clients.
@@ -54,5 +141,10 @@ var DefaultOpenIDConnectClientConfiguration = OpenIDConnectClientConfiguration{
 	ResponseTypes: []string{"code"},
 	ResponseModes: []string{"form_post", "query", "fragment"},
 
+	IDTokenSigningAlgorithm:  "RS256",
 	UserinfoSigningAlgorithm: "none",
+
+	RequestObjectSigningAlgorithm: "RS256",
+
+	PreConfiguredConsentDuration: time.Hour * 24 * 7,
 }
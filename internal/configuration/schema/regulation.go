@@ -5,6 +5,30 @@ type RegulationConfiguration struct {
 	MaxRetries int    `mapstructure:"max_retries"`
 	FindTime   string `mapstructure:"find_time"`
 	BanTime    string `mapstructure:"ban_time"`
+
+	IP             RegulationIPConfiguration      `mapstructure:"ip"`
+	Network        RegulationNetworkConfiguration `mapstructure:"network"`
+	ExemptNetworks []string                       `mapstructure:"exempt_networks"`
+
+	AdminGroup string `mapstructure:"admin_group"`
+}
+
+// RegulationIPConfiguration represents the configuration of the regulation of the failed authentication attempts made
+// from a single IP address regardless of the username.
+type RegulationIPConfiguration struct {
+	MaxRetries int    `mapstructure:"max_retries"`
+	FindTime   string `mapstructure:"find_time"`
+	BanTime    string `mapstructure:"ban_time"`
+}
+
+// RegulationNetworkConfiguration represents the configuration of the regulation of the failed authentication attempts
+// made from the IP addresses of a subnet regardless of the username.
+type RegulationNetworkConfiguration struct {
+	MaxRetries       int    `mapstructure:"max_retries"`
+	FindTime         string `mapstructure:"find_time"`
+	BanTime          string `mapstructure:"ban_time"`
+	IPv4PrefixLength int    `mapstructure:"ipv4_prefix_length"`
+	IPv6PrefixLength int    `mapstructure:"ipv6_prefix_length"`
 }
 
 // DefaultRegulationConfiguration represents default configuration parameters for the regulator.
@@ -12,4 +36,15 @@ var DefaultRegulationConfiguration = RegulationConfiguration{
 	MaxRetries: 3,
 	FindTime:   "2m",
 	BanTime:    "5m",
+
+	IP: RegulationIPConfiguration{
+		FindTime: "5m",
+		BanTime:  "30m",
+	},
+	Network: RegulationNetworkConfiguration{
+		FindTime:         "5m",
+		BanTime:          "30m",
+		IPv4PrefixLength: 24,
+		IPv6PrefixLength: 64,
+	},
 }
@@ -7,6 +7,14 @@ type ServerConfiguration struct {
 	WriteBufferSize int    `mapstructure:"write_buffer_size"`
 	EnablePprof     bool   `mapstructure:"enable_endpoint_pprof"`
 	EnableExpvars   bool   `mapstructure:"enable_endpoint_expvars"`
+
+	ExtAuthz ExtAuthzConfiguration `mapstructure:"ext_authz"`
+}
+
+// ExtAuthzConfiguration represents the configuration of the Envoy external authorization server.
+type ExtAuthzConfiguration struct {
+	GRPCPort    int    `mapstructure:"grpc_port"`
+	AutheliaURL string `mapstructure:"authelia_url"`
 }
 
 // DefaultServerConfiguration represents the default values of the ServerConfiguration.
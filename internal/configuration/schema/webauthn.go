@@ -0,0 +1,23 @@
+package schema
+
+// WebauthnConfiguration represents the configuration related to Webauthn options.
+type WebauthnConfiguration struct {
+	Disable     bool   `mapstructure:"disable"`
+	DisplayName string `mapstructure:"display_name"`
+
+	ConveyancePreference string `mapstructure:"attestation_conveyance_preference"`
+	UserVerification     string `mapstructure:"user_verification"`
+
+	Timeout string `mapstructure:"timeout"`
+
+	AllowClonedAuthenticators bool `mapstructure:"allow_cloned_authenticators"`
+}
+
+// DefaultWebauthnConfiguration describes the default values for the WebauthnConfiguration.
+var DefaultWebauthnConfiguration = WebauthnConfiguration{
+	DisplayName: "Authelia",
+	Timeout:     "60s",
+
+	ConveyancePreference: "indirect",
+	UserVerification:     "preferred",
+}
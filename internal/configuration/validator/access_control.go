@@ -5,6 +5,7 @@ import (
 	"net"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/authelia/authelia/internal/configuration/schema"
 	"github.com/authelia/authelia/internal/utils"
@@ -21,9 +22,21 @@ func IsResourceValid(resource string) (err error) {
 	return err
 }
 
-// IsSubjectValid check if a subject is valid.
+// IsSubjectValid check if a subject is valid, subjects other than the empty subject can be negated with `!`.
 func IsSubjectValid(subject string) (isValid bool) {
-	return subject == "" || strings.HasPrefix(subject, "user:") || strings.HasPrefix(subject, "group:")
+	if subject == "" {
+		return true
+	}
+
+	subject = strings.TrimPrefix(subject, "!")
+
+	for _, prefix := range validACLSubjectPrefixes {
+		if strings.HasPrefix(subject, prefix) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // IsNetworkGroupValid check if a network group is valid.
@@ -68,6 +81,12 @@ func ValidateAccessControl(configuration *schema.AccessControlConfiguration, val
 			}
 		}
 	}
+
+	for _, network := range configuration.Explain.Networks {
+		if !IsNetworkValid(network) && !IsNetworkGroupValid(*configuration, network) {
+			validator.Push(fmt.Errorf(errFmtAccessControlExplainInvalidNetwork, network))
+		}
+	}
 }
 
 // ValidateRules validates an ACL Rule configuration.
@@ -87,7 +106,7 @@ func ValidateRules(configuration schema.AccessControlConfiguration, validator *s
 	for i, rule := range configuration.Rules {
 		rulePosition := i + 1
 
-		if len(rule.Domains) == 0 {
+		if len(rule.Domains) == 0 && len(rule.DomainsRegex) == 0 {
 			validator.Push(fmt.Errorf("Rule #%d is invalid, a policy must have one or more domains", rulePosition))
 		}
 
@@ -97,12 +116,22 @@ func ValidateRules(configuration schema.AccessControlConfiguration, validator *s
 
 		validateNetworks(rulePosition, rule, configuration, validator)
 
+		validateDomainsRegex(rulePosition, rule, validator)
+
 		validateResources(rulePosition, rule, validator)
 
 		validateSubjects(rulePosition, rule, validator)
 
 		validateMethods(rulePosition, rule, validator)
 
+		validateMatchers(rulePosition, rule, "Query", rule.Query, validator)
+
+		validateMatchers(rulePosition, rule, "Header", rule.Headers, validator)
+
+		validateTimeWindows(rulePosition, rule, validator)
+
+		validateMaxAge(rulePosition, rule, validator)
+
 		if rule.Policy == bypassPolicy && len(rule.Subjects) != 0 {
 			validator.Push(fmt.Errorf(errAccessControlInvalidPolicyWithSubjects, rulePosition, rule.Domains, rule.Subjects))
 		}
@@ -127,14 +156,51 @@ func validateResources(rulePosition int, rule schema.ACLRule, validator *schema.
 	}
 }
 
+func validateDomainsRegex(rulePosition int, rule schema.ACLRule, validator *schema.StructValidator) {
+	for _, domainRegex := range rule.DomainsRegex {
+		if _, err := regexp.Compile(domainRegex); err != nil {
+			validator.Push(fmt.Errorf("Domain regex %s for rule #%d is invalid, %s", domainRegex, rulePosition, err))
+		}
+	}
+}
+
 func validateSubjects(rulePosition int, rule schema.ACLRule, validator *schema.StructValidator) {
+	captureGroups := ruleCaptureGroupNames(rule)
+
 	for _, subjectRule := range rule.Subjects {
 		for _, subject := range subjectRule {
 			if !IsSubjectValid(subject) {
-				validator.Push(fmt.Errorf("Subject %s for rule #%d domain: %s is invalid, must start with 'user:' or 'group:'", subjectRule, rulePosition, rule.Domains))
+				validator.Push(fmt.Errorf(errFmtAccessControlInvalidSubject, subjectRule, rulePosition, rule.Domains))
+
+				continue
+			}
+
+			for _, placeholder := range reACLSubjectCapturePlaceholder.FindAllStringSubmatch(subject, -1) {
+				if !utils.IsStringInSlice(placeholder[1], captureGroups) {
+					validator.Push(fmt.Errorf(errFmtAccessControlSubjectUnknownCaptureGroup, subject, rulePosition, rule.Domains, placeholder[1]))
+				}
+			}
+		}
+	}
+}
+
+// ruleCaptureGroupNames returns the names of the named capture groups of the domain regexes and resources of a rule
+// which the subjects can reference.
+func ruleCaptureGroupNames(rule schema.ACLRule) (names []string) {
+	for _, expression := range append(append([]string{}, rule.DomainsRegex...), rule.Resources...) {
+		pattern, err := regexp.Compile(expression)
+		if err != nil {
+			continue
+		}
+
+		for _, name := range pattern.SubexpNames() {
+			if name != "" && !utils.IsStringInSlice(name, names) {
+				names = append(names, name)
 			}
 		}
 	}
+
+	return names
 }
 
 func validateMethods(rulePosition int, rule schema.ACLRule, validator *schema.StructValidator) {
@@ -144,3 +210,83 @@ func validateMethods(rulePosition int, rule schema.ACLRule, validator *schema.St
 		}
 	}
 }
+
+func validateMatchers(rulePosition int, rule schema.ACLRule, kind string, matchers []schema.ACLMatcher, validator *schema.StructValidator) {
+	for i, matcher := range matchers {
+		if matcher.Key == "" {
+			validator.Push(fmt.Errorf(errFmtAccessControlMatcherNoKey, kind, i+1, rulePosition, rule.Domains))
+
+			continue
+		}
+
+		operator := strings.ToLower(matcher.Operator)
+
+		switch {
+		case operator == "":
+			continue
+		case !utils.IsStringInSlice(operator, validACLMatcherOperators):
+			validator.Push(fmt.Errorf(errFmtAccessControlMatcherInvalidOperator, kind, matcher.Key, rulePosition, rule.Domains, matcher.Operator, strings.Join(validACLMatcherOperators, ", ")))
+		case (operator == "present" || operator == "absent") && matcher.Value != "":
+			validator.Push(fmt.Errorf(errFmtAccessControlMatcherUnexpectedValue, kind, matcher.Key, rulePosition, rule.Domains, operator))
+		case operator == "pattern" || operator == "not pattern":
+			if _, err := regexp.Compile(matcher.Value); err != nil {
+				validator.Push(fmt.Errorf(errFmtAccessControlMatcherInvalidPattern, kind, matcher.Key, rulePosition, rule.Domains, err))
+			}
+		}
+	}
+}
+
+func validateTimeWindows(rulePosition int, rule schema.ACLRule, validator *schema.StructValidator) {
+	for i, window := range rule.TimeWindows {
+		for _, day := range window.Days {
+			if _, err := utils.ParseWeekday(day); err != nil {
+				validator.Push(fmt.Errorf(errFmtAccessControlTimeWindowInvalid, i+1, rulePosition, rule.Domains, err))
+			}
+		}
+
+		start, errStart := parseTimeWindowBoundary(window.Start, 0)
+		if errStart != nil {
+			validator.Push(fmt.Errorf(errFmtAccessControlTimeWindowInvalid, i+1, rulePosition, rule.Domains, errStart))
+		}
+
+		end, errEnd := parseTimeWindowBoundary(window.End, utils.Day)
+		if errEnd != nil {
+			validator.Push(fmt.Errorf(errFmtAccessControlTimeWindowInvalid, i+1, rulePosition, rule.Domains, errEnd))
+		}
+
+		if errStart == nil && errEnd == nil && start == end {
+			validator.Push(fmt.Errorf(errFmtAccessControlTimeWindowEmpty, i+1, rulePosition, rule.Domains))
+		}
+
+		if window.Timezone != "" {
+			if _, err := time.LoadLocation(window.Timezone); err != nil {
+				validator.Push(fmt.Errorf(errFmtAccessControlTimeWindowInvalid, i+1, rulePosition, rule.Domains, err))
+			}
+		}
+	}
+}
+
+// parseTimeWindowBoundary parses the start or end of a time window, fallback is used when it's not configured.
+func validateMaxAge(rulePosition int, rule schema.ACLRule, validator *schema.StructValidator) {
+	if rule.MaxAge == "" {
+		return
+	}
+
+	if rule.Policy != twoFactorPolicy {
+		validator.Push(fmt.Errorf(errFmtAccessControlMaxAgeInvalidPolicy, rule.MaxAge, rulePosition, rule.Domains))
+
+		return
+	}
+
+	if _, err := utils.ParseDurationString(rule.MaxAge); err != nil {
+		validator.Push(fmt.Errorf(errFmtAccessControlMaxAgeInvalid, rule.MaxAge, rulePosition, rule.Domains, err))
+	}
+}
+
+func parseTimeWindowBoundary(value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	return utils.ParseTimeOfDay(value)
+}
@@ -21,6 +21,7 @@ func (suite *AccessControl) SetupTest() {
 	suite.configuration.DefaultPolicy = denyPolicy
 	suite.configuration.Networks = schema.DefaultACLNetwork
 	suite.configuration.Rules = schema.DefaultACLRule
+	suite.configuration.Explain = schema.ACLExplain{}
 }
 
 func (suite *AccessControl) TestShouldValidateCompleteConfiguration() {
@@ -57,6 +58,17 @@ func (suite *AccessControl) TestShouldRaiseErrorInvalidNetworkGroupNetwork() {
 	suite.Assert().EqualError(suite.validator.Errors()[0], "Network [abc.def.ghi.jkl] from network group: internal must be a valid IP or CIDR")
 }
 
+func (suite *AccessControl) TestShouldRaiseErrorInvalidExplainNetwork() {
+	suite.configuration.Explain.Networks = []string{"internal", "192.168.0.0/16", "external"}
+
+	ValidateAccessControl(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 1)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Network external of the explain configuration is not a valid network or network group")
+}
+
 func (suite *AccessControl) TestShouldRaiseErrorWithNoRulesDefined() {
 	suite.configuration.Rules = []schema.ACLRule{}
 
@@ -178,10 +190,132 @@ func (suite *AccessControl) TestShouldRaiseErrorInvalidSubject() {
 	suite.Require().Len(suite.validator.Warnings(), 0)
 	suite.Require().Len(suite.validator.Errors(), 2)
 
-	suite.Assert().EqualError(suite.validator.Errors()[0], "Subject [invalid] for rule #1 domain: [public.example.com] is invalid, must start with 'user:' or 'group:'")
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Subject [invalid] for rule #1 domain: [public.example.com] is invalid, must start with 'user:', 'group:', 'email:' or 'oidc:', optionally negated with '!'")
 	suite.Assert().EqualError(suite.validator.Errors()[1], fmt.Sprintf(errAccessControlInvalidPolicyWithSubjects, 1, domains, subjects))
 }
 
+func (suite *AccessControl) TestShouldValidateNegatedEmailAndClientSubjects() {
+	suite.configuration.Rules = []schema.ACLRule{
+		{
+			Domains:  []string{"app.example.com"},
+			Policy:   "deny",
+			Subjects: [][]string{{"!group:admins", "email:*@example.com"}, {"oidc:app", "!user:john"}, {"!"}, {"!!user:john"}},
+		},
+	}
+
+	ValidateRules(suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 2)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Subject [!] for rule #1 domain: [app.example.com] is invalid, must start with 'user:', 'group:', 'email:' or 'oidc:', optionally negated with '!'")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "Subject [!!user:john] for rule #1 domain: [app.example.com] is invalid, must start with 'user:', 'group:', 'email:' or 'oidc:', optionally negated with '!'")
+}
+
+func (suite *AccessControl) TestShouldRaiseErrorInvalidDomainRegex() {
+	suite.configuration.Rules = []schema.ACLRule{
+		{
+			DomainsRegex: []string{"^(?P<User>\\w+.example.com$"},
+			Policy:       "one_factor",
+		},
+	}
+
+	ValidateRules(suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 1)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Domain regex ^(?P<User>\\w+.example.com$ for rule #1 is invalid, error parsing regexp: missing closing ): `^(?P<User>\\w+.example.com$`")
+}
+
+func (suite *AccessControl) TestShouldRaiseErrorSubjectUnknownCaptureGroup() {
+	suite.configuration.Rules = []schema.ACLRule{
+		{
+			DomainsRegex: []string{"^(?P<User>\\w+)\\.home\\.example\\.com$"},
+			Policy:       "one_factor",
+			Resources:    []string{"^/teams/(?P<Team>\\w+)/"},
+			Subjects:     [][]string{{"user:{User}"}, {"group:{Team}"}, {"group:{Group}"}},
+		},
+	}
+
+	ValidateRules(suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 1)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Subject group:{Group} for rule #1 domain: [] references the capture group 'Group' which isn't a named capture group of the domain_regex or resources of the rule")
+}
+
+func (suite *AccessControl) TestShouldRaiseErrorInvalidQueryAndHeaders() {
+	suite.configuration.Rules = []schema.ACLRule{
+		{
+			Domains: []string{"api.example.com"},
+			Policy:  "one_factor",
+			Query: []schema.ACLMatcher{
+				{Key: "format", Value: "json"},
+				{Key: "debug", Operator: "absent"},
+				{Value: "json"},
+				{Key: "token", Operator: "matches", Value: "abc"},
+			},
+			Headers: []schema.ACLMatcher{
+				{Key: "X-Internal", Operator: "present", Value: "true"},
+				{Key: "User-Agent", Operator: "Not Pattern", Value: "(bot"},
+			},
+		},
+	}
+
+	ValidateRules(suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 4)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Query criteria #3 for rule #1 domain: [api.example.com] is invalid, a key must be provided")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "Query criteria token for rule #1 domain: [api.example.com] has the invalid operator 'matches', must be one of the following operators: equal, not equal, present, absent, pattern, not pattern")
+	suite.Assert().EqualError(suite.validator.Errors()[2], "Header criteria X-Internal for rule #1 domain: [api.example.com] is invalid, a value can't be provided with the 'present' operator")
+	suite.Assert().EqualError(suite.validator.Errors()[3], "Header criteria User-Agent for rule #1 domain: [api.example.com] has an invalid pattern, error parsing regexp: missing closing ): `(bot`")
+}
+
+func (suite *AccessControl) TestShouldRaiseErrorInvalidTimeWindows() {
+	suite.configuration.Rules = []schema.ACLRule{
+		{
+			Domains: []string{"office.example.com"},
+			Policy:  "one_factor",
+			TimeWindows: []schema.ACLTimeWindow{
+				{Days: []string{"mon", "Friday"}, Start: "09:00", End: "17:00", Timezone: "Europe/Paris"},
+				{Days: []string{"sat"}, Start: "22:00", End: "02:00"},
+				{Days: []string{"weekend"}, Start: "9am"},
+				{Start: "10:00", End: "10:00", Timezone: "Mars/Olympus_Mons"},
+			},
+		},
+	}
+
+	ValidateRules(suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 4)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Time window #3 for rule #1 domain: [office.example.com] is invalid, could not convert the input string of weekend into a day of the week")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "Time window #3 for rule #1 domain: [office.example.com] is invalid, could not convert the input string of 9am into a time of day, it must be in the HH:MM format")
+	suite.Assert().EqualError(suite.validator.Errors()[2], "Time window #4 for rule #1 domain: [office.example.com] is invalid, the start and the end must not be the same time")
+	suite.Assert().EqualError(suite.validator.Errors()[3], "Time window #4 for rule #1 domain: [office.example.com] is invalid, unknown time zone Mars/Olympus_Mons")
+}
+
+func (suite *AccessControl) TestShouldRaiseErrorInvalidMaxAge() {
+	suite.configuration.Rules = []schema.ACLRule{
+		{Domains: []string{"admin.example.com"}, Policy: "two_factor", MaxAge: "5m"},
+		{Domains: []string{"admin.example.com"}, Policy: "one_factor", MaxAge: "5m"},
+		{Domains: []string{"admin.example.com"}, Policy: "two_factor", MaxAge: "5 minutes"},
+	}
+
+	ValidateRules(suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 2)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Max age 5m for rule #2 domain: [admin.example.com] is invalid, it's only supported with the policy 'two_factor'")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "Max age 5 minutes for rule #3 domain: [admin.example.com] is invalid, could not convert the input string of 5 minutes into a duration")
+}
+
 func TestAccessControl(t *testing.T) {
 	suite.Run(t, new(AccessControl))
 }
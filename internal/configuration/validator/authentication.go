@@ -12,18 +12,28 @@ import (
 
 // ValidateAuthenticationBackend validates and update authentication backend configuration.
 func ValidateAuthenticationBackend(configuration *schema.AuthenticationBackendConfiguration, validator *schema.StructValidator) {
-	if configuration.LDAP == nil && configuration.File == nil {
-		validator.Push(errors.New("Please provide `ldap` or `file` object in `authentication_backend`"))
+	backends := 0
+
+	for _, configured := range []bool{configuration.LDAP != nil, configuration.File != nil, configuration.SQL != nil} {
+		if configured {
+			backends++
+		}
 	}
 
-	if configuration.LDAP != nil && configuration.File != nil {
-		validator.Push(errors.New("You cannot provide both `ldap` and `file` objects in `authentication_backend`"))
+	switch {
+	case backends == 0:
+		validator.Push(errors.New("Please provide `ldap`, `file` or `sql` object in `authentication_backend`"))
+	case backends > 1:
+		validator.Push(errors.New("You cannot provide more than one of the `ldap`, `file` and `sql` objects in `authentication_backend`"))
 	}
 
-	if configuration.File != nil {
+	switch {
+	case configuration.File != nil:
 		validateFileAuthenticationBackend(configuration.File, validator)
-	} else if configuration.LDAP != nil {
+	case configuration.LDAP != nil:
 		validateLDAPAuthenticationBackend(configuration.LDAP, validator)
+	case configuration.SQL != nil:
+		validateSQLAuthenticationBackend(configuration.SQL, validator)
 	}
 
 	if configuration.RefreshInterval == "" {
@@ -36,7 +46,6 @@ func ValidateAuthenticationBackend(configuration *schema.AuthenticationBackendCo
 	}
 }
 
-//nolint:gocyclo // TODO: Consider refactoring/simplifying, time permitting.
 func validateFileAuthenticationBackend(configuration *schema.FileAuthenticationBackendConfiguration, validator *schema.StructValidator) {
 	if configuration.Path == "" {
 		validator.Push(errors.New("Please provide a `path` for the users database in `authentication_backend`"))
@@ -45,55 +54,68 @@ func validateFileAuthenticationBackend(configuration *schema.FileAuthenticationB
 	if configuration.Password == nil {
 		configuration.Password = &schema.DefaultPasswordConfiguration
 	} else {
-		if configuration.Password.Algorithm == "" {
-			configuration.Password.Algorithm = schema.DefaultPasswordConfiguration.Algorithm
-		} else {
-			configuration.Password.Algorithm = strings.ToLower(configuration.Password.Algorithm)
-			if configuration.Password.Algorithm != argon2id && configuration.Password.Algorithm != sha512 {
-				validator.Push(fmt.Errorf("Unknown hashing algorithm supplied, valid values are argon2id and sha512, you configured '%s'", configuration.Password.Algorithm))
-			}
-		}
+		validatePasswordConfiguration(configuration.Password, validator)
+	}
+}
 
-		// Iterations (time)
-		if configuration.Password.Iterations == 0 {
-			if configuration.Password.Algorithm == argon2id {
-				configuration.Password.Iterations = schema.DefaultPasswordConfiguration.Iterations
-			} else {
-				configuration.Password.Iterations = schema.DefaultPasswordSHA512Configuration.Iterations
-			}
-		} else if configuration.Password.Iterations < 1 {
-			validator.Push(fmt.Errorf("The number of iterations specified is invalid, must be 1 or more, you configured %d", configuration.Password.Iterations))
+func validateSQLAuthenticationBackend(configuration *schema.SQLAuthenticationBackendConfiguration, validator *schema.StructValidator) {
+	if configuration.Password == nil {
+		configuration.Password = &schema.DefaultPasswordConfiguration
+	} else {
+		validatePasswordConfiguration(configuration.Password, validator)
+	}
+}
+
+//nolint:gocyclo // TODO: Consider refactoring/simplifying, time permitting.
+func validatePasswordConfiguration(configuration *schema.PasswordConfiguration, validator *schema.StructValidator) {
+	if configuration.Algorithm == "" {
+		configuration.Algorithm = schema.DefaultPasswordConfiguration.Algorithm
+	} else {
+		configuration.Algorithm = strings.ToLower(configuration.Algorithm)
+		if configuration.Algorithm != argon2id && configuration.Algorithm != sha512 {
+			validator.Push(fmt.Errorf("Unknown hashing algorithm supplied, valid values are argon2id and sha512, you configured '%s'", configuration.Algorithm))
 		}
+	}
 
-		// Salt Length
-		switch {
-		case configuration.Password.SaltLength == 0:
-			configuration.Password.SaltLength = schema.DefaultPasswordConfiguration.SaltLength
-		case configuration.Password.SaltLength < 8:
-			validator.Push(fmt.Errorf("The salt length must be 2 or more, you configured %d", configuration.Password.SaltLength))
+	// Iterations (time)
+	if configuration.Iterations == 0 {
+		if configuration.Algorithm == argon2id {
+			configuration.Iterations = schema.DefaultPasswordConfiguration.Iterations
+		} else {
+			configuration.Iterations = schema.DefaultPasswordSHA512Configuration.Iterations
 		}
+	} else if configuration.Iterations < 1 {
+		validator.Push(fmt.Errorf("The number of iterations specified is invalid, must be 1 or more, you configured %d", configuration.Iterations))
+	}
 
-		if configuration.Password.Algorithm == argon2id {
-			// Parallelism
-			if configuration.Password.Parallelism == 0 {
-				configuration.Password.Parallelism = schema.DefaultPasswordConfiguration.Parallelism
-			} else if configuration.Password.Parallelism < 1 {
-				validator.Push(fmt.Errorf("Parallelism for argon2id must be 1 or more, you configured %d", configuration.Password.Parallelism))
-			}
+	// Salt Length
+	switch {
+	case configuration.SaltLength == 0:
+		configuration.SaltLength = schema.DefaultPasswordConfiguration.SaltLength
+	case configuration.SaltLength < 8:
+		validator.Push(fmt.Errorf("The salt length must be 2 or more, you configured %d", configuration.SaltLength))
+	}
 
-			// Memory
-			if configuration.Password.Memory == 0 {
-				configuration.Password.Memory = schema.DefaultPasswordConfiguration.Memory
-			} else if configuration.Password.Memory < configuration.Password.Parallelism*8 {
-				validator.Push(fmt.Errorf("Memory for argon2id must be %d or more (parallelism * 8), you configured memory as %d and parallelism as %d", configuration.Password.Parallelism*8, configuration.Password.Memory, configuration.Password.Parallelism))
-			}
+	if configuration.Algorithm == argon2id {
+		// Parallelism
+		if configuration.Parallelism == 0 {
+			configuration.Parallelism = schema.DefaultPasswordConfiguration.Parallelism
+		} else if configuration.Parallelism < 1 {
+			validator.Push(fmt.Errorf("Parallelism for argon2id must be 1 or more, you configured %d", configuration.Parallelism))
+		}
 
-			// Key Length
-			if configuration.Password.KeyLength == 0 {
-				configuration.Password.KeyLength = schema.DefaultPasswordConfiguration.KeyLength
-			} else if configuration.Password.KeyLength < 16 {
-				validator.Push(fmt.Errorf("Key length for argon2id must be 16, you configured %d", configuration.Password.KeyLength))
-			}
+		// Memory
+		if configuration.Memory == 0 {
+			configuration.Memory = schema.DefaultPasswordConfiguration.Memory
+		} else if configuration.Memory < configuration.Parallelism*8 {
+			validator.Push(fmt.Errorf("Memory for argon2id must be %d or more (parallelism * 8), you configured memory as %d and parallelism as %d", configuration.Parallelism*8, configuration.Memory, configuration.Parallelism))
+		}
+
+		// Key Length
+		if configuration.KeyLength == 0 {
+			configuration.KeyLength = schema.DefaultPasswordConfiguration.KeyLength
+		} else if configuration.KeyLength < 16 {
+			validator.Push(fmt.Errorf("Key length for argon2id must be 16, you configured %d", configuration.KeyLength))
 		}
 	}
 }
@@ -104,7 +126,8 @@ func validateLDAPAuthenticationBackend(configuration *schema.LDAPAuthenticationB
 	}
 
 	if configuration.TLS == nil {
-		configuration.TLS = schema.DefaultLDAPAuthenticationBackendConfiguration.TLS
+		tlsConfig := *schema.DefaultLDAPAuthenticationBackendConfiguration.TLS
+		configuration.TLS = &tlsConfig
 	}
 
 	if configuration.TLS.MinimumVersion == "" {
@@ -135,19 +158,108 @@ func validateLDAPAuthenticationBackend(configuration *schema.LDAPAuthenticationB
 			"placeholders, {0} has been replaced with {input} and {1} has been replaced with {username}"))
 	}
 
-	if configuration.URL == "" {
+	validateLDAPURLs(configuration, validator)
+	validateLDAPPool(configuration, validator)
+	validateLDAPGroupSearch(configuration, validator)
+	validateLDAPPasswordChangeStrategy(configuration, validator)
+	validateLDAPRequiredParameters(configuration, validator)
+}
+
+func validateLDAPGroupSearch(configuration *schema.LDAPAuthenticationBackendConfiguration, validator *schema.StructValidator) {
+	switch configuration.GroupSearchMode {
+	case "":
+		configuration.GroupSearchMode = schema.DefaultLDAPAuthenticationBackendConfiguration.GroupSearchMode
+	case schema.LDAPGroupSearchModeRecursive:
+		if !strings.Contains(configuration.GroupsFilter, "{dn}") ||
+			strings.Contains(configuration.GroupsFilter, "{input}") || strings.Contains(configuration.GroupsFilter, "{username}") {
+			validator.Push(fmt.Errorf("authentication backend ldap groups filter must only use the {dn} placeholder with the `%s` group search mode", schema.LDAPGroupSearchModeRecursive))
+		}
+	case schema.LDAPGroupSearchModeFilter, schema.LDAPGroupSearchModeMemberOf, schema.LDAPGroupSearchModeInChain:
+	default:
+		validator.Push(fmt.Errorf("authentication backend ldap group search mode must be blank or one of the following values `%s`, `%s`, `%s`, `%s`",
+			schema.LDAPGroupSearchModeFilter, schema.LDAPGroupSearchModeRecursive, schema.LDAPGroupSearchModeMemberOf, schema.LDAPGroupSearchModeInChain))
+	}
+
+	if configuration.MemberOfAttribute == "" {
+		configuration.MemberOfAttribute = schema.DefaultLDAPAuthenticationBackendConfiguration.MemberOfAttribute
+	}
+}
+
+// validateLDAPPasswordChangeStrategy validates the password change strategy, Active Directory only accepts changes of
+// the unicodePwd attribute over an encrypted connection.
+func validateLDAPPasswordChangeStrategy(configuration *schema.LDAPAuthenticationBackendConfiguration, validator *schema.StructValidator) {
+	switch configuration.PasswordChangeStrategy {
+	case "":
+		configuration.PasswordChangeStrategy = schema.DefaultLDAPAuthenticationBackendConfiguration.PasswordChangeStrategy
+	case schema.LDAPPasswordChangeStrategyUnicodePwd:
+		if configuration.StartTLS {
+			return
+		}
+
+		for _, rawURL := range configuration.URLs {
+			if strings.HasPrefix(rawURL, schemeLDAP+"://") {
+				validator.Push(fmt.Errorf("authentication backend ldap password change strategy `%s` requires the connections to be encrypted with the `%s` scheme or start_tls", schema.LDAPPasswordChangeStrategyUnicodePwd, schemeLDAPS))
+				return
+			}
+		}
+	case schema.LDAPPasswordChangeStrategyAuto, schema.LDAPPasswordChangeStrategyPasswordModify, schema.LDAPPasswordChangeStrategyReplace:
+	default:
+		validator.Push(fmt.Errorf("authentication backend ldap password change strategy must be blank or one of the following values `%s`, `%s`, `%s`, `%s`",
+			schema.LDAPPasswordChangeStrategyAuto, schema.LDAPPasswordChangeStrategyPasswordModify, schema.LDAPPasswordChangeStrategyUnicodePwd, schema.LDAPPasswordChangeStrategyReplace))
+	}
+}
+
+// validateLDAPURLs validates the URLs of the LDAP servers, the url option is the only URL of the urls option when it's
+// configured. The server name of the TLS configuration is only inferred from the URL when there is a single server.
+func validateLDAPURLs(configuration *schema.LDAPAuthenticationBackendConfiguration, validator *schema.StructValidator) {
+	switch {
+	case configuration.URL != "" && len(configuration.URLs) != 0:
+		validator.Push(errors.New("authentication backend ldap url and urls must not be configured together"))
+		return
+	case configuration.URL == "" && len(configuration.URLs) == 0:
 		validator.Push(errors.New("Please provide a URL to the LDAP server"))
-	} else {
-		ldapURL, serverName := validateLDAPURL(configuration.URL, validator)
+		return
+	case configuration.URL != "":
+		configuration.URLs = []string{configuration.URL}
+	}
 
-		configuration.URL = ldapURL
+	for i, rawURL := range configuration.URLs {
+		ldapURL, serverName := validateLDAPURL(rawURL, validator)
 
-		if configuration.TLS.ServerName == "" {
+		configuration.URLs[i] = ldapURL
+
+		if len(configuration.URLs) == 1 && configuration.TLS.ServerName == "" {
 			configuration.TLS.ServerName = serverName
 		}
 	}
 
-	validateLDAPRequiredParameters(configuration, validator)
+	configuration.URL = configuration.URLs[0]
+
+	switch configuration.URLStrategy {
+	case "":
+		configuration.URLStrategy = schema.DefaultLDAPAuthenticationBackendConfiguration.URLStrategy
+	case schema.LDAPURLStrategyFailover, schema.LDAPURLStrategyRoundRobin:
+	default:
+		validator.Push(fmt.Errorf("authentication backend ldap url strategy must be blank or one of the following values `%s`, `%s`", schema.LDAPURLStrategyFailover, schema.LDAPURLStrategyRoundRobin))
+	}
+
+	if configuration.HealthCheckInterval == "" {
+		configuration.HealthCheckInterval = schema.DefaultLDAPAuthenticationBackendConfiguration.HealthCheckInterval
+	} else if _, err := utils.ParseDurationString(configuration.HealthCheckInterval); err != nil {
+		validator.Push(fmt.Errorf(errFmtLDAPDurationInvalid, "health_check_interval", err))
+	}
+}
+
+func validateLDAPPool(configuration *schema.LDAPAuthenticationBackendConfiguration, validator *schema.StructValidator) {
+	if configuration.Pool.MaxConnections < 0 {
+		validator.Push(errors.New("authentication backend ldap pool max connections must be above 0 or 0 to disable the pool"))
+	}
+
+	if configuration.Pool.Timeout == "" {
+		configuration.Pool.Timeout = schema.DefaultLDAPAuthenticationBackendConfiguration.Pool.Timeout
+	} else if _, err := utils.ParseDurationString(configuration.Pool.Timeout); err != nil {
+		validator.Push(fmt.Errorf(errFmtLDAPDurationInvalid, "pool timeout", err))
+	}
 }
 
 // Wrapper for test purposes to exclude the hostname from the return.
@@ -207,9 +319,12 @@ func validateLDAPRequiredParameters(configuration *schema.LDAPAuthenticationBack
 		}
 	}
 
-	if configuration.GroupsFilter == "" {
+	switch {
+	case configuration.GroupSearchMode == schema.LDAPGroupSearchModeMemberOf, configuration.GroupSearchMode == schema.LDAPGroupSearchModeInChain:
+		// The groups filter is not used by these group search modes.
+	case configuration.GroupsFilter == "":
 		validator.Push(errors.New("Please provide a groups filter with `groups_filter` attribute"))
-	} else if !strings.HasPrefix(configuration.GroupsFilter, "(") || !strings.HasSuffix(configuration.GroupsFilter, ")") {
+	case !strings.HasPrefix(configuration.GroupsFilter, "(") || !strings.HasSuffix(configuration.GroupsFilter, ")"):
 		validator.Push(errors.New("The groups filter should contain enclosing parenthesis. For instance cn={input} should be (cn={input})"))
 	}
 }
@@ -22,7 +22,7 @@ func TestShouldRaiseErrorWhenBothBackendsProvided(t *testing.T) {
 	ValidateAuthenticationBackend(&backendConfig, validator)
 
 	require.Len(t, validator.Errors(), 1)
-	assert.EqualError(t, validator.Errors()[0], "You cannot provide both `ldap` and `file` objects in `authentication_backend`")
+	assert.EqualError(t, validator.Errors()[0], "You cannot provide more than one of the `ldap`, `file` and `sql` objects in `authentication_backend`")
 }
 
 func TestShouldRaiseErrorWhenNoBackendProvided(t *testing.T) {
@@ -32,7 +32,53 @@ func TestShouldRaiseErrorWhenNoBackendProvided(t *testing.T) {
 	ValidateAuthenticationBackend(&backendConfig, validator)
 
 	require.Len(t, validator.Errors(), 1)
-	assert.EqualError(t, validator.Errors()[0], "Please provide `ldap` or `file` object in `authentication_backend`")
+	assert.EqualError(t, validator.Errors()[0], "Please provide `ldap`, `file` or `sql` object in `authentication_backend`")
+}
+
+func TestShouldRaiseErrorWhenFileAndSQLBackendsProvided(t *testing.T) {
+	validator := schema.NewStructValidator()
+	backendConfig := schema.AuthenticationBackendConfiguration{}
+
+	backendConfig.File = &schema.FileAuthenticationBackendConfiguration{
+		Path: "/tmp",
+	}
+	backendConfig.SQL = &schema.SQLAuthenticationBackendConfiguration{}
+
+	ValidateAuthenticationBackend(&backendConfig, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "You cannot provide more than one of the `ldap`, `file` and `sql` objects in `authentication_backend`")
+}
+
+func TestShouldSetDefaultPasswordConfigurationOfSQLBackend(t *testing.T) {
+	validator := schema.NewStructValidator()
+	backendConfig := schema.AuthenticationBackendConfiguration{SQL: &schema.SQLAuthenticationBackendConfiguration{}}
+
+	ValidateAuthenticationBackend(&backendConfig, validator)
+
+	assert.False(t, validator.HasWarnings())
+	assert.False(t, validator.HasErrors())
+	assert.Equal(t, &schema.DefaultPasswordConfiguration, backendConfig.SQL.Password)
+}
+
+func TestShouldValidatePasswordConfigurationOfSQLBackend(t *testing.T) {
+	validator := schema.NewStructValidator()
+	backendConfig := schema.AuthenticationBackendConfiguration{SQL: &schema.SQLAuthenticationBackendConfiguration{
+		Password: &schema.PasswordConfiguration{Algorithm: "SHA512"},
+	}}
+
+	ValidateAuthenticationBackend(&backendConfig, validator)
+
+	assert.False(t, validator.HasErrors())
+	assert.Equal(t, schema.DefaultPasswordSHA512Configuration.Algorithm, backendConfig.SQL.Password.Algorithm)
+	assert.Equal(t, schema.DefaultPasswordSHA512Configuration.Iterations, backendConfig.SQL.Password.Iterations)
+
+	backendConfig.SQL.Password = &schema.PasswordConfiguration{Algorithm: "bogus"}
+
+	ValidateAuthenticationBackend(&backendConfig, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "Unknown hashing algorithm supplied, valid values are argon2id and sha512, you configured 'bogus'")
 }
 
 type FileBasedAuthenticationBackend struct {
@@ -442,6 +488,123 @@ func (suite *LDAPAuthenticationBackendSuite) TestShouldHelpDetectNoInputPlacehol
 	suite.Assert().EqualError(suite.validator.Errors()[0], "Unable to detect {input} placeholder in users_filter, your configuration might be broken. Please review configuration options listed at https://www.authelia.com/docs/configuration/authentication/ldap.html")
 }
 
+func (suite *LDAPAuthenticationBackendSuite) TestShouldSetURLsFromURL() {
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasErrors())
+	suite.Assert().Equal([]string{testLDAPURL}, suite.configuration.LDAP.URLs)
+	suite.Assert().Equal("ldap", suite.configuration.LDAP.TLS.ServerName)
+	suite.Assert().Equal(schema.LDAPURLStrategyFailover, suite.configuration.LDAP.URLStrategy)
+	suite.Assert().Equal("1m", suite.configuration.LDAP.HealthCheckInterval)
+	suite.Assert().Equal("5s", suite.configuration.LDAP.Pool.Timeout)
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldValidateMultipleURLs() {
+	suite.configuration.LDAP.URL = ""
+	suite.configuration.LDAP.URLs = []string{"ldaps://dc1.example.com", "ldaps://dc2.example.com"}
+	suite.configuration.LDAP.URLStrategy = schema.LDAPURLStrategyRoundRobin
+
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasErrors())
+	suite.Assert().Equal("ldaps://dc1.example.com", suite.configuration.LDAP.URL)
+	suite.Assert().Equal("", suite.configuration.LDAP.TLS.ServerName)
+	suite.Assert().Equal(schema.LDAPURLStrategyRoundRobin, suite.configuration.LDAP.URLStrategy)
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldRaiseErrorWhenURLAndURLsProvided() {
+	suite.configuration.LDAP.URLs = []string{"ldaps://dc1.example.com"}
+
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "authentication backend ldap url and urls must not be configured together")
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldRaiseErrorOnInvalidFailoverAndPool() {
+	suite.configuration.LDAP.URLStrategy = "random"
+	suite.configuration.LDAP.HealthCheckInterval = "abc"
+	suite.configuration.LDAP.Pool.MaxConnections = -1
+	suite.configuration.LDAP.Pool.Timeout = "abc"
+
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Require().Len(suite.validator.Errors(), 4)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "authentication backend ldap url strategy must be blank or one of the following values `failover`, `round_robin`")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "Error occurred parsing the ldap health_check_interval string: could not convert the input string of abc into a duration")
+	suite.Assert().EqualError(suite.validator.Errors()[2], "authentication backend ldap pool max connections must be above 0 or 0 to disable the pool")
+	suite.Assert().EqualError(suite.validator.Errors()[3], "Error occurred parsing the ldap pool timeout string: could not convert the input string of abc into a duration")
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldSetDefaultGroupSearchMode() {
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasErrors())
+	suite.Assert().Equal(schema.LDAPGroupSearchModeFilter, suite.configuration.LDAP.GroupSearchMode)
+	suite.Assert().Equal("memberOf", suite.configuration.LDAP.MemberOfAttribute)
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldNotRequireGroupsFilterWithMemberOfGroupSearchMode() {
+	suite.configuration.LDAP.GroupSearchMode = schema.LDAPGroupSearchModeMemberOf
+	suite.configuration.LDAP.GroupsFilter = ""
+
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasErrors())
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldRaiseErrorWhenRecursiveGroupsFilterUsesInput() {
+	suite.configuration.LDAP.GroupSearchMode = schema.LDAPGroupSearchModeRecursive
+
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "authentication backend ldap groups filter must only use the {dn} placeholder with the `recursive` group search mode")
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldRaiseErrorWhenGroupSearchModeIsInvalid() {
+	suite.configuration.LDAP.GroupSearchMode = "nested"
+
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "authentication backend ldap group search mode must be blank or one of the following values `filter`, `recursive`, `memberof`, `in_chain`")
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldSetDefaultPasswordChangeStrategy() {
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasErrors())
+	suite.Assert().Equal(schema.LDAPPasswordChangeStrategyAuto, suite.configuration.LDAP.PasswordChangeStrategy)
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldRaiseErrorWhenPasswordChangeStrategyIsInvalid() {
+	suite.configuration.LDAP.PasswordChangeStrategy = "exop"
+
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "authentication backend ldap password change strategy must be blank or one of the following values `auto`, `password_modify`, `unicode_pwd`, `replace`")
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldRaiseErrorWhenUnicodePwdPasswordChangeStrategyIsNotEncrypted() {
+	suite.configuration.LDAP.PasswordChangeStrategy = schema.LDAPPasswordChangeStrategyUnicodePwd
+
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "authentication backend ldap password change strategy `unicode_pwd` requires the connections to be encrypted with the `ldaps` scheme or start_tls")
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldAllowUnicodePwdPasswordChangeStrategyWithStartTLS() {
+	suite.configuration.LDAP.PasswordChangeStrategy = schema.LDAPPasswordChangeStrategyUnicodePwd
+	suite.configuration.LDAP.StartTLS = true
+
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasErrors())
+}
+
 func (suite *LDAPAuthenticationBackendSuite) TestShouldAdaptLDAPURL() {
 	suite.Assert().Equal("", validateLDAPURLSimple("127.0.0.1", suite.validator))
 
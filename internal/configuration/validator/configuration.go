@@ -57,6 +57,12 @@ func ValidateConfiguration(configuration *schema.Configuration, validator *schem
 
 	ValidateTOTP(configuration.TOTP, validator)
 
+	if configuration.Webauthn == nil {
+		configuration.Webauthn = &schema.DefaultWebauthnConfiguration
+	}
+
+	ValidateWebauthn(configuration.Webauthn, validator)
+
 	ValidateAuthenticationBackend(&configuration.AuthenticationBackend, validator)
 
 	ValidateAccessControl(&configuration.AccessControl, validator)
@@ -1,5 +1,9 @@
 package validator
 
+import (
+	"regexp"
+)
+
 const (
 	errFmtDeprecatedConfigurationKey = "[DEPRECATED] The %s configuration option is deprecated and will be " +
 		"removed in %s, please use %s instead"
@@ -16,6 +20,12 @@ const (
 		"should be http or https"
 	errFmtOIDCServerClientRedirectURICantBeParsed = "OIDC client with ID '%s' has an invalid redirect URI '%s' " +
 		"could not be parsed: %v"
+	errFmtOIDCServerClientPostLogoutRedirectURI = "OIDC client with ID '%s' post logout redirect URI %s has an " +
+		"invalid scheme '%s', should be http or https"
+	errFmtOIDCServerClientPostLogoutRedirectURICantBeParsed = "OIDC client with ID '%s' has an invalid post logout " +
+		"redirect URI '%s' could not be parsed: %v"
+	errFmtOIDCServerClientLogoutURI = "OIDC client with ID '%s' has an invalid %s logout URI '%s', must be an " +
+		"absolute http or https URI without a fragment"
 	errFmtOIDCServerClientInvalidPolicy = "OIDC client with ID '%s' has an invalid policy '%s', " +
 		"should be either 'one_factor' or 'two_factor'"
 	errFmtOIDCServerClientInvalidSecret = "OIDC client with ID '%s' has an empty secret" //nolint:gosec
@@ -27,8 +37,55 @@ const (
 		"must be one of: '%s'"
 	errFmtOIDCServerClientInvalidUserinfoAlgorithm = "OIDC client with ID '%s' has an invalid userinfo signing " +
 		"algorithm '%s', must be one of: '%s'"
+	errFmtOIDCServerClientInvalidConsentMode = "OIDC client with ID '%s' has an invalid consent mode '%s', " +
+		"must be one of: '%s'"
+	errFmtOIDCServerClientInvalidPreConfiguredConsentDuration = "OIDC client with ID '%s' has an invalid pre-configured " +
+		"consent duration '%s', must not be negative"
+	errFmtOIDCServerClientInvalidIDTokenAlgorithm = "OIDC client with ID '%s' has an invalid ID token signing " +
+		"algorithm '%s', must be one of: '%s'"
+	errFmtOIDCServerClientInvalidClaimsPolicy = "OIDC client with ID '%s' has an invalid claims policy '%s', " +
+		"it's not defined in the claims policies"
+	errFmtOIDCServerClientInvalidTokenEndpointAuthMethod = "OIDC client with ID '%s' has an invalid token endpoint " +
+		"authentication method '%s', must be one of: '%s'"
+	errFmtOIDCServerClientInvalidRequestObjectAlgorithm = "OIDC client with ID '%s' has an invalid request object " +
+		"signing algorithm '%s', must be one of: '%s'"
+	errFmtOIDCServerClientInvalidRequestURI = "OIDC client with ID '%s' has an invalid request URI '%s', must be an " +
+		"absolute https URI"
+	errFmtOIDCServerClientInvalidJWKSURI = "OIDC client with ID '%s' has an invalid JSON Web Key Set URI '%s', must be " +
+		"an absolute https URI"
+	errFmtOIDCServerClientRequestURIsWithoutJWKSURI = "OIDC client with ID '%s' has request URIs but no JSON Web Key " +
+		"Set URI, the request objects can't be verified without it"
+	errFmtOIDCServerClaimsPolicyEmptyName     = "OIDC Server has a claims policy with index %d and an empty name"
+	errFmtOIDCServerClaimsPolicyDuplicateName = "OIDC Server has more than one claims policy with the name '%s'"
+	errFmtOIDCServerClaimsPolicyInvalidScope  = "OIDC claims policy '%s' has an invalid custom scope '%s', " +
+		"must not be empty, a standard scope or defined more than once"
+	errFmtOIDCServerClaimsPolicyInvalidClaimName = "OIDC claims policy '%s' has an invalid claim name '%s', " +
+		"must not be empty, a reserved claim or defined more than once"
+	errFmtOIDCServerClaimsPolicyClaimInvalidScope = "OIDC claims policy '%s' claim '%s' has an invalid scope '%s', " +
+		"must be one of: '%s'"
+	errFmtOIDCServerClaimsPolicyClaimInvalidSource = "OIDC claims policy '%s' claim '%s' must have either an " +
+		"attribute or roles but not both"
+	errFmtOIDCServerClaimsPolicyClaimInvalidRole = "OIDC claims policy '%s' claim '%s' has a role mapping with " +
+		"index %d which must have both a group and a role"
+	errFmtOIDCServerClaimsPolicyInvalidRenameClaim = "OIDC claims policy '%s' has an invalid claim to rename '%s', " +
+		"must be one of: '%s'"
+	errFmtOIDCServerIssuerPrivateKeyEmpty = "OIDC Server issuer private key with index %d and key ID '%s' " +
+		"must have a key"
+	errFmtOIDCServerIssuerPrivateKeyInvalidAlgorithm = "OIDC Server issuer private key with index %d and key ID '%s' " +
+		"has an invalid algorithm '%s', must be one of: '%s'"
+	errFmtOIDCServerIssuerPrivateKeyDuplicateKeyID = "OIDC Server has more than one issuer private key with the " +
+		"key ID '%s'"
+	errFmtOIDCServerInvalidDeviceCodePollingInterval = "OIDC Server device code polling interval '%s' must be " +
+		"less than the device code lifespan '%s'"
 	errFmtOIDCServerInsecureParameterEntropy = "SECURITY ISSUE: OIDC minimum parameter entropy is configured to an " +
 		"unsafe value, it should be above 8 but it's configured to %d."
+	errFmtOIDCServerInsecureInitialAccessToken = "SECURITY ISSUE: OIDC client registration initial access token " +
+		"is configured to an unsafe value, it should be at least %d characters but it's %d characters."
+
+	errFmtWebauthnTimeout              = "webauthn: timeout '%s' could not be parsed: %v"
+	errFmtWebauthnConveyancePreference = "webauthn: attestation_conveyance_preference '%s' is invalid, " +
+		"must be one of: '%s'"
+	errFmtWebauthnUserVerification = "webauthn: user_verification '%s' is invalid, must be one of: '%s'"
 
 	errFileHashing = "config key incorrect: authentication_backend.file.hashing should be " +
 		"authentication_backend.file.password"
@@ -42,6 +99,12 @@ const (
 	twoFactorPolicy = "two_factor"
 	denyPolicy      = "deny"
 
+	consentModeExplicit      = "explicit"
+	consentModeImplicit      = "implicit"
+	consentModePreConfigured = "pre-configured"
+
+	minimumInitialAccessTokenLength = 32
+
 	argon2id = "argon2id"
 	sha512   = "sha512"
 
@@ -61,18 +124,61 @@ const (
 	testTLSCert       = "/tmp/cert.pem"
 	testTLSKey        = "/tmp/key.pem"
 
-	errAccessControlInvalidPolicyWithSubjects = "Policy [bypass] for rule #%d domain %s with subjects %s is invalid. " +
+	errFmtAccessControlInvalidSubject = "Subject %s for rule #%d domain: %s is invalid, must start with 'user:', " +
+		"'group:', 'email:' or 'oidc:', optionally negated with '!'"
+	errFmtAccessControlSubjectUnknownCaptureGroup = "Subject %s for rule #%d domain: %s references the capture " +
+		"group '%s' which isn't a named capture group of the domain_regex or resources of the rule"
+	errFmtAccessControlMatcherNoKey           = "%s criteria #%d for rule #%d domain: %s is invalid, a key must be provided"
+	errFmtAccessControlMatcherInvalidOperator = "%s criteria %s for rule #%d domain: %s has the invalid operator " +
+		"'%s', must be one of the following operators: %s"
+	errFmtAccessControlMatcherUnexpectedValue = "%s criteria %s for rule #%d domain: %s is invalid, a value can't " +
+		"be provided with the '%s' operator"
+	errFmtAccessControlMatcherInvalidPattern = "%s criteria %s for rule #%d domain: %s has an invalid pattern, %s"
+	errFmtAccessControlTimeWindowInvalid     = "Time window #%d for rule #%d domain: %s is invalid, %s"
+	errFmtAccessControlTimeWindowEmpty       = "Time window #%d for rule #%d domain: %s is invalid, the start and " +
+		"the end must not be the same time"
+	errFmtAccessControlMaxAgeInvalid       = "Max age %s for rule #%d domain: %s is invalid, %s"
+	errFmtAccessControlMaxAgeInvalidPolicy = "Max age %s for rule #%d domain: %s is invalid, it's only supported " +
+		"with the policy 'two_factor'"
+	errFmtAccessControlExplainInvalidNetwork   = "Network %s of the explain configuration is not a valid network or network group"
+	errFmtRegulationDurationInvalid            = "Error occurred parsing regulation %s %s string: %s"
+	errFmtRegulationFindTimeGreaterThanBanTime = "Regulation %s find_time cannot be greater than ban_time"
+	errFmtRegulationNetworkPrefixLengthInvalid = "Regulation network %s_prefix_length %d is invalid, it must be between 1 and %d"
+	errFmtRegulationExemptNetworkInvalid       = "Regulation exempt network %s is not a valid IP address or network"
+	errFmtLDAPDurationInvalid                  = "Error occurred parsing the ldap %s string: %s"
+	errAccessControlInvalidPolicyWithSubjects  = "Policy [bypass] for rule #%d domain %s with subjects %s is invalid. " +
 		"It is not supported to configure both policy bypass and subjects. For more information see: " +
 		"https://www.authelia.com/docs/configuration/access-control.html#combining-subjects-and-the-bypass-policy"
 )
 
+// reACLSubjectCapturePlaceholder matches the placeholders of the ACL subjects referencing the named capture groups of
+// the domain_regex and resources of the rule.
+var reACLSubjectCapturePlaceholder = regexp.MustCompile(`{(\w+)}`)
+
 var validLoggingLevels = []string{"trace", "debug", "info", "warn", "error"}
+var validACLSubjectPrefixes = []string{"user:", "group:", "email:", "oidc:"}
+var validACLMatcherOperators = []string{"equal", "not equal", "present", "absent", "pattern", "not pattern"}
 var validHTTPRequestMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "TRACE", "CONNECT", "OPTIONS"}
 
+var validWebauthnConveyancePreferences = []string{"none", "indirect", "direct"}
+var validWebauthnUserVerificationRequirements = []string{"discouraged", "preferred", "required"}
+
 var validOIDCScopes = []string{"openid", "email", "profile", "groups", "offline_access"}
-var validOIDCGrantTypes = []string{"implicit", "refresh_token", "authorization_code", "password", "client_credentials"}
+var validOIDCRenameClaims = []string{"groups", "name", "email", "alt_emails", "email_verified"}
+
+// reservedOIDCClaims are the claims which custom claims can't use, they are either set by the OpenID Connect provider
+// or are standard claims which can only be renamed.
+var reservedOIDCClaims = []string{"iss", "sub", "aud", "exp", "iat", "auth_time", "nonce", "acr", "amr", "azp",
+	"at_hash", "c_hash", "jti", "sid", "rat", "groups", "name", "email", "alt_emails", "email_verified"}
+var validOIDCGrantTypes = []string{"implicit", "refresh_token", "authorization_code", "password", "client_credentials",
+	"urn:ietf:params:oauth:grant-type:device_code"}
 var validOIDCResponseModes = []string{"form_post", "query", "fragment"}
-var validOIDCUserinfoAlgorithms = []string{"none", "RS256"}
+var validOIDCSigningAlgorithms = []string{"RS256", "PS256", "ES256", "ES384"}
+var validOIDCUserinfoAlgorithms = []string{"none", "RS256", "PS256", "ES256", "ES384"}
+var validOIDCRequestObjectAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256",
+	"ES384", "ES512"}
+var validOIDCTokenEndpointAuthMethods = []string{"client_secret_basic", "client_secret_post"}
+var validOIDCConsentModes = []string{consentModeExplicit, consentModeImplicit, consentModePreConfigured}
 
 // SecretNames contains a map of secret names.
 var SecretNames = map[string]string{
@@ -87,6 +193,8 @@ var SecretNames = map[string]string{
 	"PostgreSQLPassword":            "storage.postgres.password",
 	"OpenIDConnectHMACSecret":       "identity_providers.oidc.hmac_secret",
 	"OpenIDConnectIssuerPrivateKey": "identity_providers.oidc.issuer_private_key",
+
+	"OpenIDConnectInitialAccessToken": "identity_providers.oidc.client_registration.initial_access_token",
 }
 
 // validKeys is a list of valid keys that are not secret names. For the sake of consistency please place any secret in
@@ -119,16 +227,27 @@ var validKeys = []string{
 	"server.path",
 	"server.enable_pprof",
 	"server.enable_expvars",
+	"server.ext_authz.grpc_port",
+	"server.ext_authz.authelia_url",
 
 	// TOTP Keys.
 	"totp.issuer",
 	"totp.period",
 	"totp.skew",
 
+	// Webauthn Keys.
+	"webauthn.disable",
+	"webauthn.display_name",
+	"webauthn.attestation_conveyance_preference",
+	"webauthn.user_verification",
+	"webauthn.timeout",
+	"webauthn.allow_cloned_authenticators",
+
 	// Access Control Keys.
 	"access_control.rules",
 	"access_control.default_policy",
 	"access_control.networks",
+	"access_control.explain.networks",
 
 	// Session Keys.
 	"session.name",
@@ -196,6 +315,16 @@ var validKeys = []string{
 	"regulation.max_retries",
 	"regulation.find_time",
 	"regulation.ban_time",
+	"regulation.ip.max_retries",
+	"regulation.ip.find_time",
+	"regulation.ip.ban_time",
+	"regulation.network.max_retries",
+	"regulation.network.find_time",
+	"regulation.network.ban_time",
+	"regulation.network.ipv4_prefix_length",
+	"regulation.network.ipv6_prefix_length",
+	"regulation.exempt_networks",
+	"regulation.admin_group",
 
 	// DUO API Keys.
 	"duo_api.hostname",
@@ -208,15 +337,23 @@ var validKeys = []string{
 	// LDAP Authentication Backend Keys.
 	"authentication_backend.ldap.implementation",
 	"authentication_backend.ldap.url",
+	"authentication_backend.ldap.urls",
+	"authentication_backend.ldap.url_strategy",
+	"authentication_backend.ldap.health_check_interval",
+	"authentication_backend.ldap.pool.max_connections",
+	"authentication_backend.ldap.pool.timeout",
 	"authentication_backend.ldap.base_dn",
 	"authentication_backend.ldap.username_attribute",
 	"authentication_backend.ldap.additional_users_dn",
 	"authentication_backend.ldap.users_filter",
 	"authentication_backend.ldap.additional_groups_dn",
 	"authentication_backend.ldap.groups_filter",
+	"authentication_backend.ldap.group_search_mode",
+	"authentication_backend.ldap.member_of_attribute",
 	"authentication_backend.ldap.group_name_attribute",
 	"authentication_backend.ldap.mail_attribute",
 	"authentication_backend.ldap.display_name_attribute",
+	"authentication_backend.ldap.password_change_strategy",
 	"authentication_backend.ldap.user",
 	"authentication_backend.ldap.start_tls",
 	"authentication_backend.ldap.tls.minimum_version",
@@ -232,13 +369,27 @@ var validKeys = []string{
 	"authentication_backend.file.password.memory",
 	"authentication_backend.file.password.parallelism",
 
+	// SQL Authentication Backend Keys.
+	"authentication_backend.sql.password.algorithm",
+	"authentication_backend.sql.password.iterations",
+	"authentication_backend.sql.password.key_length",
+	"authentication_backend.sql.password.salt_length",
+	"authentication_backend.sql.password.memory",
+	"authentication_backend.sql.password.parallelism",
+
 	// Identity Provider Keys.
 	"identity_providers.oidc.clients",
+	"identity_providers.oidc.claims_policies",
+	"identity_providers.oidc.issuer_private_keys",
 	"identity_providers.oidc.id_token_lifespan",
 	"identity_providers.oidc.access_token_lifespan",
 	"identity_providers.oidc.refresh_token_lifespan",
 	"identity_providers.oidc.authorize_code_lifespan",
+	"identity_providers.oidc.device_code_lifespan",
+	"identity_providers.oidc.device_code_polling_interval",
 	"identity_providers.oidc.enable_client_debug_messages",
+	"identity_providers.oidc.pushed_authorization_request_lifespan",
+	"identity_providers.oidc.client_registration.admin_group",
 }
 
 var replacedKeys = map[string]string{
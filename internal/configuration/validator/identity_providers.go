@@ -17,10 +17,12 @@ func ValidateIdentityProviders(configuration *schema.IdentityProvidersConfigurat
 
 func validateOIDC(configuration *schema.OpenIDConnectConfiguration, validator *schema.StructValidator) {
 	if configuration != nil {
-		if configuration.IssuerPrivateKey == "" {
+		if configuration.IssuerPrivateKey == "" && len(configuration.IssuerPrivateKeys) == 0 {
 			validator.Push(fmt.Errorf("OIDC Server issuer private key must be provided"))
 		}
 
+		validateOIDCIssuerPrivateKeys(configuration, validator)
+
 		if configuration.AccessTokenLifespan == time.Duration(0) {
 			configuration.AccessTokenLifespan = schema.DefaultOpenIDConnectConfiguration.AccessTokenLifespan
 		}
@@ -37,18 +39,147 @@ func validateOIDC(configuration *schema.OpenIDConnectConfiguration, validator *s
 			configuration.RefreshTokenLifespan = schema.DefaultOpenIDConnectConfiguration.RefreshTokenLifespan
 		}
 
+		if configuration.DeviceCodeLifespan == time.Duration(0) {
+			configuration.DeviceCodeLifespan = schema.DefaultOpenIDConnectConfiguration.DeviceCodeLifespan
+		}
+
+		if configuration.DeviceCodePollingInterval == time.Duration(0) {
+			configuration.DeviceCodePollingInterval = schema.DefaultOpenIDConnectConfiguration.DeviceCodePollingInterval
+		}
+
+		if configuration.PushedAuthorizationRequestLifespan == time.Duration(0) {
+			configuration.PushedAuthorizationRequestLifespan = schema.DefaultOpenIDConnectConfiguration.PushedAuthorizationRequestLifespan
+		}
+
+		if configuration.DeviceCodePollingInterval >= configuration.DeviceCodeLifespan {
+			validator.Push(fmt.Errorf(errFmtOIDCServerInvalidDeviceCodePollingInterval,
+				configuration.DeviceCodePollingInterval, configuration.DeviceCodeLifespan))
+		}
+
 		if configuration.MinimumParameterEntropy != 0 && configuration.MinimumParameterEntropy < 8 {
 			validator.PushWarning(fmt.Errorf(errFmtOIDCServerInsecureParameterEntropy, configuration.MinimumParameterEntropy))
 		}
 
+		if length := len(configuration.ClientRegistration.InitialAccessToken); length != 0 && length < minimumInitialAccessTokenLength {
+			validator.PushWarning(fmt.Errorf(errFmtOIDCServerInsecureInitialAccessToken, minimumInitialAccessTokenLength, length))
+		}
+
+		validateOIDCClaimsPolicies(configuration, validator)
 		validateOIDCClients(configuration, validator)
 
-		if len(configuration.Clients) == 0 {
+		// Clients are not required in the configuration when they can be registered at runtime.
+		if len(configuration.Clients) == 0 && configuration.ClientRegistration.AdminGroup == "" &&
+			configuration.ClientRegistration.InitialAccessToken == "" {
 			validator.Push(fmt.Errorf("OIDC Server has no clients defined"))
 		}
 	}
 }
 
+// ValidateOpenIDConnectClient validates and updates a single OpenID Connect client which is registered at runtime
+// rather than in the configuration.
+func ValidateOpenIDConnectClient(client *schema.OpenIDConnectClientConfiguration, validator *schema.StructValidator) {
+	configuration := &schema.OpenIDConnectConfiguration{
+		Clients: []schema.OpenIDConnectClientConfiguration{*client},
+	}
+
+	validateOIDCClients(configuration, validator)
+
+	*client = configuration.Clients[0]
+}
+
+func validateOIDCIssuerPrivateKeys(configuration *schema.OpenIDConnectConfiguration, validator *schema.StructValidator) {
+	var keyIDs []string
+
+	for i, key := range configuration.IssuerPrivateKeys {
+		if key.Key == "" {
+			validator.Push(fmt.Errorf(errFmtOIDCServerIssuerPrivateKeyEmpty, i, key.KeyID))
+		}
+
+		if key.Algorithm != "" && !utils.IsStringInSlice(key.Algorithm, validOIDCSigningAlgorithms) {
+			validator.Push(fmt.Errorf(errFmtOIDCServerIssuerPrivateKeyInvalidAlgorithm,
+				i, key.KeyID, key.Algorithm, strings.Join(validOIDCSigningAlgorithms, "', '")))
+		}
+
+		if key.KeyID == "" {
+			continue
+		}
+
+		if utils.IsStringInSlice(key.KeyID, keyIDs) {
+			validator.Push(fmt.Errorf(errFmtOIDCServerIssuerPrivateKeyDuplicateKeyID, key.KeyID))
+		}
+
+		keyIDs = append(keyIDs, key.KeyID)
+	}
+}
+
+func validateOIDCClaimsPolicies(configuration *schema.OpenIDConnectConfiguration, validator *schema.StructValidator) {
+	var names []string
+
+	for i, policy := range configuration.ClaimsPolicies {
+		switch {
+		case policy.Name == "":
+			validator.Push(fmt.Errorf(errFmtOIDCServerClaimsPolicyEmptyName, i))
+		case utils.IsStringInSlice(policy.Name, names):
+			validator.Push(fmt.Errorf(errFmtOIDCServerClaimsPolicyDuplicateName, policy.Name))
+		default:
+			names = append(names, policy.Name)
+		}
+
+		scopes := append([]string{}, validOIDCScopes...)
+
+		for _, scope := range policy.CustomScopes {
+			if scope.Name == "" || utils.IsStringInSlice(scope.Name, scopes) {
+				validator.Push(fmt.Errorf(errFmtOIDCServerClaimsPolicyInvalidScope, policy.Name, scope.Name))
+				continue
+			}
+
+			scopes = append(scopes, scope.Name)
+		}
+
+		claims := append([]string{}, reservedOIDCClaims...)
+
+		for _, claim := range policy.CustomClaims {
+			validateOIDCClaimsPolicyClaimName(policy.Name, claim.Name, &claims, validator)
+			validateOIDCClaimsPolicyCustomClaim(policy.Name, claim, scopes, validator)
+		}
+
+		for _, rename := range policy.RenameClaims {
+			if !utils.IsStringInSlice(rename.Claim, validOIDCRenameClaims) {
+				validator.Push(fmt.Errorf(errFmtOIDCServerClaimsPolicyInvalidRenameClaim,
+					policy.Name, rename.Claim, strings.Join(validOIDCRenameClaims, "', '")))
+			}
+
+			validateOIDCClaimsPolicyClaimName(policy.Name, rename.Name, &claims, validator)
+		}
+	}
+}
+
+func validateOIDCClaimsPolicyClaimName(policy, name string, claims *[]string, validator *schema.StructValidator) {
+	if name == "" || utils.IsStringInSlice(name, *claims) {
+		validator.Push(fmt.Errorf(errFmtOIDCServerClaimsPolicyInvalidClaimName, policy, name))
+		return
+	}
+
+	*claims = append(*claims, name)
+}
+
+func validateOIDCClaimsPolicyCustomClaim(policy string, claim schema.OpenIDConnectCustomClaimConfiguration, scopes []string, validator *schema.StructValidator) {
+	if !utils.IsStringInSlice(claim.Scope, scopes) {
+		validator.Push(fmt.Errorf(errFmtOIDCServerClaimsPolicyClaimInvalidScope,
+			policy, claim.Name, claim.Scope, strings.Join(scopes, "', '")))
+	}
+
+	if (claim.Attribute == "") == (len(claim.Roles) == 0) {
+		validator.Push(fmt.Errorf(errFmtOIDCServerClaimsPolicyClaimInvalidSource, policy, claim.Name))
+	}
+
+	for i, role := range claim.Roles {
+		if role.Group == "" || role.Role == "" {
+			validator.Push(fmt.Errorf(errFmtOIDCServerClaimsPolicyClaimInvalidRole, policy, claim.Name, i))
+		}
+	}
+}
+
 func validateOIDCClients(configuration *schema.OpenIDConnectConfiguration, validator *schema.StructValidator) {
 	invalidID, duplicateIDs := false, false
 
@@ -78,13 +209,19 @@ func validateOIDCClients(configuration *schema.OpenIDConnectConfiguration, valid
 			validator.Push(fmt.Errorf(errFmtOIDCServerClientInvalidPolicy, client.ID, client.Policy))
 		}
 
+		validateOIDCClientClaimsPolicy(c, configuration, validator)
 		validateOIDCClientScopes(c, configuration, validator)
 		validateOIDCClientGrantTypes(c, configuration, validator)
 		validateOIDCClientResponseTypes(c, configuration, validator)
 		validateOIDCClientResponseModes(c, configuration, validator)
+		validateOIDCClientIDTokenAlgorithm(c, configuration, validator)
 		validateOIDDClientUserinfoAlgorithm(c, configuration, validator)
+		validateOIDCClientConsentMode(c, configuration, validator)
+		validateOIDCClientTokenEndpointAuthMethod(client, validator)
+		validateOIDCClientRequestObjects(c, configuration, validator)
 
 		validateOIDCClientRedirectURIs(client, validator)
+		validateOIDCClientLogoutURIs(client, validator)
 	}
 
 	if invalidID {
@@ -106,15 +243,44 @@ func validateOIDCClientScopes(c int, configuration *schema.OpenIDConnectConfigur
 		configuration.Clients[c].Scopes = append(configuration.Clients[c].Scopes, "openid")
 	}
 
+	// The custom scopes of the claims policy of the client are valid in addition to the standard scopes.
+	scopes := append([]string{}, validOIDCScopes...)
+
+	for _, policy := range configuration.ClaimsPolicies {
+		if policy.Name != configuration.Clients[c].ClaimsPolicy {
+			continue
+		}
+
+		for _, scope := range policy.CustomScopes {
+			scopes = append(scopes, scope.Name)
+		}
+	}
+
 	for _, scope := range configuration.Clients[c].Scopes {
-		if !utils.IsStringInSlice(scope, validOIDCScopes) {
+		if !utils.IsStringInSlice(scope, scopes) {
 			validator.Push(fmt.Errorf(
 				errFmtOIDCServerClientInvalidScope,
-				configuration.Clients[c].ID, scope, strings.Join(validOIDCScopes, "', '")))
+				configuration.Clients[c].ID, scope, strings.Join(scopes, "', '")))
 		}
 	}
 }
 
+func validateOIDCClientClaimsPolicy(c int, configuration *schema.OpenIDConnectConfiguration, validator *schema.StructValidator) {
+	name := configuration.Clients[c].ClaimsPolicy
+
+	if name == "" {
+		return
+	}
+
+	for _, policy := range configuration.ClaimsPolicies {
+		if policy.Name == name {
+			return
+		}
+	}
+
+	validator.Push(fmt.Errorf(errFmtOIDCServerClientInvalidClaimsPolicy, configuration.Clients[c].ID, name))
+}
+
 func validateOIDCClientGrantTypes(c int, configuration *schema.OpenIDConnectConfiguration, validator *schema.StructValidator) {
 	if len(configuration.Clients[c].GrantTypes) == 0 {
 		configuration.Clients[c].GrantTypes = schema.DefaultOpenIDConnectClientConfiguration.GrantTypes
@@ -152,6 +318,15 @@ func validateOIDCClientResponseModes(c int, configuration *schema.OpenIDConnectC
 	}
 }
 
+func validateOIDCClientIDTokenAlgorithm(c int, configuration *schema.OpenIDConnectConfiguration, validator *schema.StructValidator) {
+	if configuration.Clients[c].IDTokenSigningAlgorithm == "" {
+		configuration.Clients[c].IDTokenSigningAlgorithm = schema.DefaultOpenIDConnectClientConfiguration.IDTokenSigningAlgorithm
+	} else if !utils.IsStringInSlice(configuration.Clients[c].IDTokenSigningAlgorithm, validOIDCSigningAlgorithms) {
+		validator.Push(fmt.Errorf(errFmtOIDCServerClientInvalidIDTokenAlgorithm,
+			configuration.Clients[c].ID, configuration.Clients[c].IDTokenSigningAlgorithm, strings.Join(validOIDCSigningAlgorithms, "', '")))
+	}
+}
+
 func validateOIDDClientUserinfoAlgorithm(c int, configuration *schema.OpenIDConnectConfiguration, validator *schema.StructValidator) {
 	if configuration.Clients[c].UserinfoSigningAlgorithm == "" {
 		configuration.Clients[c].UserinfoSigningAlgorithm = schema.DefaultOpenIDConnectClientConfiguration.UserinfoSigningAlgorithm
@@ -161,6 +336,64 @@ func validateOIDDClientUserinfoAlgorithm(c int, configuration *schema.OpenIDConn
 	}
 }
 
+func validateOIDCClientConsentMode(c int, configuration *schema.OpenIDConnectConfiguration, validator *schema.StructValidator) {
+	client := &configuration.Clients[c]
+
+	switch {
+	case client.ConsentMode == "" && client.PreConfiguredConsentDuration > 0:
+		// The consent is pre-configured when only the duration is set, otherwise it must be given explicitly.
+		client.ConsentMode = consentModePreConfigured
+	case client.ConsentMode == "":
+		client.ConsentMode = consentModeExplicit
+	case !utils.IsStringInSlice(client.ConsentMode, validOIDCConsentModes):
+		validator.Push(fmt.Errorf(errFmtOIDCServerClientInvalidConsentMode,
+			client.ID, client.ConsentMode, strings.Join(validOIDCConsentModes, "', '")))
+	}
+
+	if client.PreConfiguredConsentDuration < 0 {
+		validator.Push(fmt.Errorf(errFmtOIDCServerClientInvalidPreConfiguredConsentDuration, client.ID, client.PreConfiguredConsentDuration))
+	} else if client.PreConfiguredConsentDuration == 0 && client.ConsentMode == consentModePreConfigured {
+		client.PreConfiguredConsentDuration = schema.DefaultOpenIDConnectClientConfiguration.PreConfiguredConsentDuration
+	}
+}
+
+func validateOIDCClientTokenEndpointAuthMethod(client schema.OpenIDConnectClientConfiguration, validator *schema.StructValidator) {
+	if client.TokenEndpointAuthMethod != "" && !utils.IsStringInSlice(client.TokenEndpointAuthMethod, validOIDCTokenEndpointAuthMethods) {
+		validator.Push(fmt.Errorf(errFmtOIDCServerClientInvalidTokenEndpointAuthMethod,
+			client.ID, client.TokenEndpointAuthMethod, strings.Join(validOIDCTokenEndpointAuthMethods, "', '")))
+	}
+}
+
+func validateOIDCClientRequestObjects(c int, configuration *schema.OpenIDConnectConfiguration, validator *schema.StructValidator) {
+	client := &configuration.Clients[c]
+
+	if client.RequestObjectSigningAlgorithm == "" {
+		client.RequestObjectSigningAlgorithm = schema.DefaultOpenIDConnectClientConfiguration.RequestObjectSigningAlgorithm
+	} else if !utils.IsStringInSlice(client.RequestObjectSigningAlgorithm, validOIDCRequestObjectAlgorithms) {
+		validator.Push(fmt.Errorf(errFmtOIDCServerClientInvalidRequestObjectAlgorithm,
+			client.ID, client.RequestObjectSigningAlgorithm, strings.Join(validOIDCRequestObjectAlgorithms, "', '")))
+	}
+
+	for _, requestURI := range client.RequestURIs {
+		if !isAbsoluteHTTPSURI(requestURI) {
+			validator.Push(fmt.Errorf(errFmtOIDCServerClientInvalidRequestURI, client.ID, requestURI))
+		}
+	}
+
+	switch {
+	case client.JWKSURI == "" && len(client.RequestURIs) != 0:
+		validator.Push(fmt.Errorf(errFmtOIDCServerClientRequestURIsWithoutJWKSURI, client.ID))
+	case client.JWKSURI != "" && !isAbsoluteHTTPSURI(client.JWKSURI):
+		validator.Push(fmt.Errorf(errFmtOIDCServerClientInvalidJWKSURI, client.ID, client.JWKSURI))
+	}
+}
+
+func isAbsoluteHTTPSURI(uri string) bool {
+	parsedURI, err := url.Parse(uri)
+
+	return err == nil && parsedURI.Scheme == schemeHTTPS && parsedURI.Host != ""
+}
+
 func validateOIDCClientRedirectURIs(client schema.OpenIDConnectClientConfiguration, validator *schema.StructValidator) {
 	for _, redirectURI := range client.RedirectURIs {
 		parsedURI, err := url.Parse(redirectURI)
@@ -175,3 +408,33 @@ func validateOIDCClientRedirectURIs(client schema.OpenIDConnectClientConfigurati
 		}
 	}
 }
+
+func validateOIDCClientLogoutURIs(client schema.OpenIDConnectClientConfiguration, validator *schema.StructValidator) {
+	for _, redirectURI := range client.PostLogoutRedirectURIs {
+		parsedURI, err := url.Parse(redirectURI)
+
+		if err != nil {
+			validator.Push(fmt.Errorf(errFmtOIDCServerClientPostLogoutRedirectURICantBeParsed, client.ID, redirectURI, err))
+			break
+		}
+
+		if parsedURI.Scheme != schemeHTTPS && parsedURI.Scheme != schemeHTTP {
+			validator.Push(fmt.Errorf(errFmtOIDCServerClientPostLogoutRedirectURI, client.ID, redirectURI, parsedURI.Scheme))
+		}
+	}
+
+	validateOIDCClientLogoutURI(client.ID, "front-channel", client.FrontChannelLogoutURI, validator)
+	validateOIDCClientLogoutURI(client.ID, "back-channel", client.BackChannelLogoutURI, validator)
+}
+
+func validateOIDCClientLogoutURI(id, channel, logoutURI string, validator *schema.StructValidator) {
+	if logoutURI == "" {
+		return
+	}
+
+	parsedURI, err := url.Parse(logoutURI)
+
+	if err != nil || (parsedURI.Scheme != schemeHTTPS && parsedURI.Scheme != schemeHTTP) || parsedURI.Host == "" || parsedURI.Fragment != "" {
+		validator.Push(fmt.Errorf(errFmtOIDCServerClientLogoutURI, id, channel, logoutURI))
+	}
+}
@@ -154,7 +154,7 @@ func TestShouldRaiseErrorWhenOIDCClientConfiguredWithBadGrantTypes(t *testing.T)
 	require.Len(t, validator.Errors(), 1)
 	assert.EqualError(t, validator.Errors()[0], "OIDC client with ID 'good_id' has an invalid grant type "+
 		"'bad_grant_type', must be one of: 'implicit', 'refresh_token', 'authorization_code', "+
-		"'password', 'client_credentials'")
+		"'password', 'client_credentials', 'urn:ietf:params:oauth:grant-type:device_code'")
 }
 
 func TestShouldRaiseErrorWhenOIDCClientConfiguredWithBadResponseModes(t *testing.T) {
@@ -208,7 +208,385 @@ func TestShouldRaiseErrorWhenOIDCClientConfiguredWithBadUserinfoAlg(t *testing.T
 
 	require.Len(t, validator.Errors(), 1)
 	assert.EqualError(t, validator.Errors()[0], "OIDC client with ID 'good_id' has an invalid userinfo "+
-		"signing algorithm 'rs256', must be one of: 'none, RS256'")
+		"signing algorithm 'rs256', must be one of: 'none, RS256, PS256, ES256, ES384'")
+}
+
+func TestShouldRaiseErrorWhenOIDCClientConfiguredWithBadIDTokenAlg(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.IdentityProvidersConfiguration{
+		OIDC: &schema.OpenIDConnectConfiguration{
+			HMACSecret:       "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+			IssuerPrivateKey: "key-material",
+			Clients: []schema.OpenIDConnectClientConfiguration{
+				{
+					ID:                      "good_id",
+					Secret:                  "good_secret",
+					Policy:                  "two_factor",
+					IDTokenSigningAlgorithm: "HS256",
+					RedirectURIs: []string{
+						"https://google.com/callback",
+					},
+				},
+			},
+		},
+	}
+
+	ValidateIdentityProviders(config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "OIDC client with ID 'good_id' has an invalid ID token "+
+		"signing algorithm 'HS256', must be one of: 'RS256', 'PS256', 'ES256', 'ES384'")
+}
+
+func TestShouldRaiseErrorWhenOIDCServerIssuerPrivateKeysBadValues(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.IdentityProvidersConfiguration{
+		OIDC: &schema.OpenIDConnectConfiguration{
+			HMACSecret: "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+			IssuerPrivateKeys: []schema.OpenIDConnectIssuerPrivateKeyConfiguration{
+				{
+					KeyID:     "main",
+					Algorithm: "RS256",
+					Key:       "key-material",
+				},
+				{
+					KeyID:     "main",
+					Algorithm: "ES512",
+					Key:       "key-material",
+				},
+				{
+					KeyID: "empty",
+				},
+			},
+			Clients: []schema.OpenIDConnectClientConfiguration{
+				{
+					ID:     "good_id",
+					Secret: "good_secret",
+					Policy: "two_factor",
+					RedirectURIs: []string{
+						"https://google.com/callback",
+					},
+				},
+			},
+		},
+	}
+
+	ValidateIdentityProviders(config, validator)
+
+	require.Len(t, validator.Errors(), 3)
+	assert.EqualError(t, validator.Errors()[0], "OIDC Server issuer private key with index 1 and key ID 'main' "+
+		"has an invalid algorithm 'ES512', must be one of: 'RS256', 'PS256', 'ES256', 'ES384'")
+	assert.EqualError(t, validator.Errors()[1], "OIDC Server has more than one issuer private key with the key ID 'main'")
+	assert.EqualError(t, validator.Errors()[2], "OIDC Server issuer private key with index 2 and key ID 'empty' "+
+		"must have a key")
+}
+
+func TestShouldRaiseErrorWhenOIDCClientConfiguredWithBadConsentMode(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.IdentityProvidersConfiguration{
+		OIDC: &schema.OpenIDConnectConfiguration{
+			HMACSecret:       "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+			IssuerPrivateKey: "key-material",
+			Clients: []schema.OpenIDConnectClientConfiguration{
+				{
+					ID:          "good_id",
+					Secret:      "good_secret",
+					Policy:      "two_factor",
+					ConsentMode: "always",
+					RedirectURIs: []string{
+						"https://google.com/callback",
+					},
+				},
+				{
+					ID:                           "other_id",
+					Secret:                       "good_secret",
+					Policy:                       "two_factor",
+					PreConfiguredConsentDuration: -time.Hour,
+					RedirectURIs: []string{
+						"https://google.com/callback",
+					},
+				},
+			},
+		},
+	}
+
+	ValidateIdentityProviders(config, validator)
+
+	require.Len(t, validator.Errors(), 2)
+	assert.EqualError(t, validator.Errors()[0], "OIDC client with ID 'good_id' has an invalid consent mode "+
+		"'always', must be one of: 'explicit', 'implicit', 'pre-configured'")
+	assert.EqualError(t, validator.Errors()[1], "OIDC client with ID 'other_id' has an invalid pre-configured "+
+		"consent duration '-1h0m0s', must not be negative")
+}
+
+func TestShouldRaiseErrorWhenOIDCClientConfiguredWithBadLogoutURIs(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.IdentityProvidersConfiguration{
+		OIDC: &schema.OpenIDConnectConfiguration{
+			HMACSecret:       "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+			IssuerPrivateKey: "key-material",
+			Clients: []schema.OpenIDConnectClientConfiguration{
+				{
+					ID:     "good_id",
+					Secret: "good_secret",
+					Policy: "two_factor",
+					RedirectURIs: []string{
+						"https://google.com/callback",
+					},
+					PostLogoutRedirectURIs: []string{
+						"https://google.com/logged-out",
+					},
+					FrontChannelLogoutURI: "https://google.com/frontchannel",
+					BackChannelLogoutURI:  "http://internal:8080/backchannel",
+				},
+				{
+					ID:     "bad_id",
+					Secret: "good_secret",
+					Policy: "two_factor",
+					RedirectURIs: []string{
+						"https://google.com/callback",
+					},
+					PostLogoutRedirectURIs: []string{
+						"tcp://google.com",
+						"http://abc@%two",
+					},
+					FrontChannelLogoutURI: "https://google.com/frontchannel#fragment",
+					BackChannelLogoutURI:  "/backchannel",
+				},
+			},
+		},
+	}
+
+	ValidateIdentityProviders(config, validator)
+
+	require.Len(t, validator.Errors(), 4)
+	assert.EqualError(t, validator.Errors()[0], "OIDC client with ID 'bad_id' post logout redirect URI tcp://google.com "+
+		"has an invalid scheme 'tcp', should be http or https")
+	assert.EqualError(t, validator.Errors()[1], "OIDC client with ID 'bad_id' has an invalid post logout redirect URI "+
+		"'http://abc@%two' could not be parsed: parse \"http://abc@%two\": invalid URL escape \"%tw\"")
+	assert.EqualError(t, validator.Errors()[2], "OIDC client with ID 'bad_id' has an invalid front-channel logout URI "+
+		"'https://google.com/frontchannel#fragment', must be an absolute http or https URI without a fragment")
+	assert.EqualError(t, validator.Errors()[3], "OIDC client with ID 'bad_id' has an invalid back-channel logout URI "+
+		"'/backchannel', must be an absolute http or https URI without a fragment")
+}
+
+func TestShouldRaiseErrorWhenOIDCClientConfiguredWithBadRequestObjectOptions(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.IdentityProvidersConfiguration{
+		OIDC: &schema.OpenIDConnectConfiguration{
+			HMACSecret:       "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+			IssuerPrivateKey: "key-material",
+			Clients: []schema.OpenIDConnectClientConfiguration{
+				{
+					ID:     "good_id",
+					Secret: "good_secret",
+					Policy: "two_factor",
+					RedirectURIs: []string{
+						"https://google.com/callback",
+					},
+					RequestObjectSigningAlgorithm: "ES256",
+					RequestURIs: []string{
+						"https://google.com/request.jwt",
+					},
+					JWKSURI: "https://google.com/jwks.json",
+				},
+				{
+					ID:     "bad_id",
+					Secret: "good_secret",
+					Policy: "two_factor",
+					RedirectURIs: []string{
+						"https://google.com/callback",
+					},
+					RequestObjectSigningAlgorithm: "HS256",
+					RequestURIs: []string{
+						"http://google.com/request.jwt",
+					},
+				},
+				{
+					ID:     "bad_jwks",
+					Secret: "good_secret",
+					Policy: "two_factor",
+					RedirectURIs: []string{
+						"https://google.com/callback",
+					},
+					JWKSURI: "/jwks.json",
+				},
+			},
+		},
+	}
+
+	ValidateIdentityProviders(config, validator)
+
+	require.Len(t, validator.Errors(), 4)
+	assert.EqualError(t, validator.Errors()[0], "OIDC client with ID 'bad_id' has an invalid request object signing "+
+		"algorithm 'HS256', must be one of: 'RS256', 'RS384', 'RS512', 'PS256', 'PS384', 'PS512', 'ES256', 'ES384', 'ES512'")
+	assert.EqualError(t, validator.Errors()[1], "OIDC client with ID 'bad_id' has an invalid request URI "+
+		"'http://google.com/request.jwt', must be an absolute https URI")
+	assert.EqualError(t, validator.Errors()[2], "OIDC client with ID 'bad_id' has request URIs but no JSON Web Key Set "+
+		"URI, the request objects can't be verified without it")
+	assert.EqualError(t, validator.Errors()[3], "OIDC client with ID 'bad_jwks' has an invalid JSON Web Key Set URI "+
+		"'/jwks.json', must be an absolute https URI")
+}
+
+func TestShouldRaiseErrorWhenOIDCClientConfiguredWithBadTokenEndpointAuthMethod(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.IdentityProvidersConfiguration{
+		OIDC: &schema.OpenIDConnectConfiguration{
+			HMACSecret:       "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+			IssuerPrivateKey: "key-material",
+			Clients: []schema.OpenIDConnectClientConfiguration{
+				{
+					ID:     "good_id",
+					Secret: "good_secret",
+					Policy: "two_factor",
+					RedirectURIs: []string{
+						"https://google.com/callback",
+					},
+					TokenEndpointAuthMethod: "client_secret_post",
+				},
+				{
+					ID:     "bad_id",
+					Secret: "good_secret",
+					Policy: "two_factor",
+					RedirectURIs: []string{
+						"https://google.com/callback",
+					},
+					TokenEndpointAuthMethod: "private_key_jwt",
+				},
+			},
+		},
+	}
+
+	ValidateIdentityProviders(config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "OIDC client with ID 'bad_id' has an invalid token endpoint "+
+		"authentication method 'private_key_jwt', must be one of: 'client_secret_basic', 'client_secret_post'")
+}
+
+func TestShouldAllowOIDCClientCustomScopesOfClaimsPolicy(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.IdentityProvidersConfiguration{
+		OIDC: &schema.OpenIDConnectConfiguration{
+			HMACSecret:       "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+			IssuerPrivateKey: "key-material",
+			ClaimsPolicies: []schema.OpenIDConnectClaimsPolicyConfiguration{
+				{
+					Name: "grafana",
+					CustomScopes: []schema.OpenIDConnectCustomScopeConfiguration{
+						{Name: "roles", Description: "Access your roles"},
+					},
+					CustomClaims: []schema.OpenIDConnectCustomClaimConfiguration{
+						{
+							Name:  "role",
+							Scope: "roles",
+							Roles: []schema.OpenIDConnectRoleMappingConfiguration{
+								{Group: "admins", Role: "Admin"},
+							},
+						},
+						{Name: "employee_number", Scope: "profile", Attribute: "employeeNumber"},
+					},
+					RenameClaims: []schema.OpenIDConnectRenameClaimConfiguration{
+						{Claim: "groups", Name: "teams"},
+					},
+				},
+			},
+			Clients: []schema.OpenIDConnectClientConfiguration{
+				{
+					ID:           "grafana",
+					Secret:       "good_secret",
+					Policy:       "two_factor",
+					ClaimsPolicy: "grafana",
+					Scopes:       []string{"openid", "profile", "roles"},
+					RedirectURIs: []string{
+						"https://google.com/callback",
+					},
+				},
+				{
+					ID:     "other",
+					Secret: "good_secret",
+					Policy: "two_factor",
+					Scopes: []string{"openid", "roles"},
+					RedirectURIs: []string{
+						"https://google.com/callback",
+					},
+				},
+			},
+		},
+	}
+
+	ValidateIdentityProviders(config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "OIDC client with ID 'other' has an invalid scope 'roles', "+
+		"must be one of: 'openid', 'email', 'profile', 'groups', 'offline_access'")
+}
+
+func TestShouldRaiseErrorWhenOIDCClaimsPoliciesBadValues(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.IdentityProvidersConfiguration{
+		OIDC: &schema.OpenIDConnectConfiguration{
+			HMACSecret:       "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+			IssuerPrivateKey: "key-material",
+			ClaimsPolicies: []schema.OpenIDConnectClaimsPolicyConfiguration{
+				{
+					Name: "bad",
+					CustomScopes: []schema.OpenIDConnectCustomScopeConfiguration{
+						{Name: "groups"},
+					},
+					CustomClaims: []schema.OpenIDConnectCustomClaimConfiguration{
+						{Name: "sub", Scope: "profile", Attribute: "uid"},
+						{
+							Name:      "role",
+							Scope:     "roles",
+							Attribute: "role",
+							Roles: []schema.OpenIDConnectRoleMappingConfiguration{
+								{Group: "admins"},
+							},
+						},
+					},
+					RenameClaims: []schema.OpenIDConnectRenameClaimConfiguration{
+						{Claim: "sub", Name: "role"},
+					},
+				},
+				{Name: "bad"},
+				{},
+			},
+			Clients: []schema.OpenIDConnectClientConfiguration{
+				{
+					ID:           "good_id",
+					Secret:       "good_secret",
+					Policy:       "two_factor",
+					ClaimsPolicy: "missing",
+					RedirectURIs: []string{
+						"https://google.com/callback",
+					},
+				},
+			},
+		},
+	}
+
+	ValidateIdentityProviders(config, validator)
+
+	require.Len(t, validator.Errors(), 10)
+	assert.EqualError(t, validator.Errors()[0], "OIDC claims policy 'bad' has an invalid custom scope 'groups', "+
+		"must not be empty, a standard scope or defined more than once")
+	assert.EqualError(t, validator.Errors()[1], "OIDC claims policy 'bad' has an invalid claim name 'sub', "+
+		"must not be empty, a reserved claim or defined more than once")
+	assert.EqualError(t, validator.Errors()[2], "OIDC claims policy 'bad' claim 'role' has an invalid scope 'roles', "+
+		"must be one of: 'openid', 'email', 'profile', 'groups', 'offline_access'")
+	assert.EqualError(t, validator.Errors()[3], "OIDC claims policy 'bad' claim 'role' must have either an "+
+		"attribute or roles but not both")
+	assert.EqualError(t, validator.Errors()[4], "OIDC claims policy 'bad' claim 'role' has a role mapping with "+
+		"index 0 which must have both a group and a role")
+	assert.EqualError(t, validator.Errors()[5], "OIDC claims policy 'bad' has an invalid claim to rename 'sub', "+
+		"must be one of: 'groups', 'name', 'email', 'alt_emails', 'email_verified'")
+	assert.EqualError(t, validator.Errors()[6], "OIDC claims policy 'bad' has an invalid claim name 'role', "+
+		"must not be empty, a reserved claim or defined more than once")
+	assert.EqualError(t, validator.Errors()[7], "OIDC Server has more than one claims policy with the name 'bad'")
+	assert.EqualError(t, validator.Errors()[8], "OIDC Server has a claims policy with index 2 and an empty name")
+	assert.EqualError(t, validator.Errors()[9], "OIDC client with ID 'good_id' has an invalid claims policy "+
+		"'missing', it's not defined in the claims policies")
 }
 
 func TestValidateIdentityProvidersShouldRaiseWarningOnSecurityIssue(t *testing.T) {
@@ -276,6 +654,15 @@ func TestValidateIdentityProvidersShouldSetDefaultValues(t *testing.T) {
 						"form_post",
 						"fragment",
 					},
+					PreConfiguredConsentDuration: time.Hour,
+				},
+				{
+					ID:          "c-client",
+					Secret:      "c-client-secret",
+					ConsentMode: consentModePreConfigured,
+					RedirectURIs: []string{
+						"https://google.com",
+					},
 				},
 			},
 		},
@@ -292,6 +679,7 @@ func TestValidateIdentityProvidersShouldSetDefaultValues(t *testing.T) {
 
 	assert.Equal(t, config.OIDC.Clients[0].UserinfoSigningAlgorithm, "none")
 	assert.Equal(t, config.OIDC.Clients[1].UserinfoSigningAlgorithm, "RS256")
+	assert.Equal(t, config.OIDC.Clients[0].IDTokenSigningAlgorithm, "RS256")
 
 	// Assert Clients[0] Description is set to the Clients[0] ID, and Clients[1]'s Description is not overridden.
 	assert.Equal(t, config.OIDC.Clients[0].ID, config.OIDC.Clients[0].Description)
@@ -338,9 +726,69 @@ func TestValidateIdentityProvidersShouldSetDefaultValues(t *testing.T) {
 	assert.Equal(t, "form_post", config.OIDC.Clients[1].ResponseModes[0])
 	assert.Equal(t, "fragment", config.OIDC.Clients[1].ResponseModes[1])
 
+	// Assert Clients[0] requires explicit consent, Clients[1] pre-configures consent because only the duration is
+	// configured, and Clients[2] ends up configured with the default duration.
+	assert.Equal(t, consentModeExplicit, config.OIDC.Clients[0].ConsentMode)
+	assert.Equal(t, consentModePreConfigured, config.OIDC.Clients[1].ConsentMode)
+	assert.Equal(t, time.Hour, config.OIDC.Clients[1].PreConfiguredConsentDuration)
+	assert.Equal(t, consentModePreConfigured, config.OIDC.Clients[2].ConsentMode)
+	assert.Equal(t, time.Hour*24*7, config.OIDC.Clients[2].PreConfiguredConsentDuration)
+
 	assert.Equal(t, false, config.OIDC.EnableClientDebugMessages)
 	assert.Equal(t, time.Hour, config.OIDC.AccessTokenLifespan)
 	assert.Equal(t, time.Minute, config.OIDC.AuthorizeCodeLifespan)
 	assert.Equal(t, time.Hour, config.OIDC.IDTokenLifespan)
 	assert.Equal(t, time.Minute*90, config.OIDC.RefreshTokenLifespan)
+	assert.Equal(t, time.Minute*10, config.OIDC.DeviceCodeLifespan)
+	assert.Equal(t, time.Second*5, config.OIDC.DeviceCodePollingInterval)
+	assert.Equal(t, time.Minute*5, config.OIDC.PushedAuthorizationRequestLifespan)
+
+	assert.Equal(t, "RS256", config.OIDC.Clients[0].RequestObjectSigningAlgorithm)
+}
+
+func TestShouldRaiseErrorWhenOIDCDeviceCodePollingIntervalExceedsLifespan(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.IdentityProvidersConfiguration{
+		OIDC: &schema.OpenIDConnectConfiguration{
+			HMACSecret:                "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+			IssuerPrivateKey:          "key-material",
+			DeviceCodeLifespan:        time.Minute,
+			DeviceCodePollingInterval: time.Minute * 2,
+			Clients: []schema.OpenIDConnectClientConfiguration{
+				{
+					ID:         "tv",
+					Secret:     "good_secret",
+					GrantTypes: []string{"urn:ietf:params:oauth:grant-type:device_code", "refresh_token"},
+				},
+			},
+		},
+	}
+
+	ValidateIdentityProviders(config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "OIDC Server device code polling interval '2m0s' must be less than "+
+		"the device code lifespan '1m0s'")
+}
+
+func TestShouldAllowOIDCServerWithoutClientsWhenClientRegistrationIsEnabled(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.IdentityProvidersConfiguration{
+		OIDC: &schema.OpenIDConnectConfiguration{
+			HMACSecret:       "abc",
+			IssuerPrivateKey: "abc",
+			ClientRegistration: schema.OpenIDConnectClientRegistrationConfiguration{
+				AdminGroup:         "admins",
+				InitialAccessToken: "abc",
+			},
+		},
+	}
+
+	ValidateIdentityProviders(config, validator)
+
+	assert.Len(t, validator.Errors(), 0)
+	require.Len(t, validator.Warnings(), 1)
+
+	assert.EqualError(t, validator.Warnings()[0], "SECURITY ISSUE: OIDC client registration initial access token "+
+		"is configured to an unsafe value, it should be at least 32 characters but it's 3 characters.")
 }
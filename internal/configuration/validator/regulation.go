@@ -30,4 +30,68 @@ func ValidateRegulation(configuration *schema.RegulationConfiguration, validator
 	if findTime > banTime {
 		validator.Push(fmt.Errorf("find_time cannot be greater than ban_time"))
 	}
+
+	validateRegulationIP(configuration, validator)
+
+	validateRegulationNetwork(configuration, validator)
+
+	for _, network := range configuration.ExemptNetworks {
+		if !IsNetworkValid(network) {
+			validator.Push(fmt.Errorf(errFmtRegulationExemptNetworkInvalid, network))
+		}
+	}
+}
+
+func validateRegulationIP(configuration *schema.RegulationConfiguration, validator *schema.StructValidator) {
+	if configuration.IP.FindTime == "" {
+		configuration.IP.FindTime = schema.DefaultRegulationConfiguration.IP.FindTime
+	}
+
+	if configuration.IP.BanTime == "" {
+		configuration.IP.BanTime = schema.DefaultRegulationConfiguration.IP.BanTime
+	}
+
+	validateRegulationTimes("ip", configuration.IP.FindTime, configuration.IP.BanTime, validator)
+}
+
+func validateRegulationNetwork(configuration *schema.RegulationConfiguration, validator *schema.StructValidator) {
+	if configuration.Network.FindTime == "" {
+		configuration.Network.FindTime = schema.DefaultRegulationConfiguration.Network.FindTime
+	}
+
+	if configuration.Network.BanTime == "" {
+		configuration.Network.BanTime = schema.DefaultRegulationConfiguration.Network.BanTime
+	}
+
+	validateRegulationTimes("network", configuration.Network.FindTime, configuration.Network.BanTime, validator)
+
+	switch {
+	case configuration.Network.IPv4PrefixLength == 0:
+		configuration.Network.IPv4PrefixLength = schema.DefaultRegulationConfiguration.Network.IPv4PrefixLength
+	case configuration.Network.IPv4PrefixLength < 1 || configuration.Network.IPv4PrefixLength > 32:
+		validator.Push(fmt.Errorf(errFmtRegulationNetworkPrefixLengthInvalid, "ipv4", configuration.Network.IPv4PrefixLength, 32))
+	}
+
+	switch {
+	case configuration.Network.IPv6PrefixLength == 0:
+		configuration.Network.IPv6PrefixLength = schema.DefaultRegulationConfiguration.Network.IPv6PrefixLength
+	case configuration.Network.IPv6PrefixLength < 1 || configuration.Network.IPv6PrefixLength > 128:
+		validator.Push(fmt.Errorf(errFmtRegulationNetworkPrefixLengthInvalid, "ipv6", configuration.Network.IPv6PrefixLength, 128))
+	}
+}
+
+func validateRegulationTimes(section, rawFindTime, rawBanTime string, validator *schema.StructValidator) {
+	findTime, err := utils.ParseDurationString(rawFindTime)
+	if err != nil {
+		validator.Push(fmt.Errorf(errFmtRegulationDurationInvalid, section, "find_time", err))
+	}
+
+	banTime, err := utils.ParseDurationString(rawBanTime)
+	if err != nil {
+		validator.Push(fmt.Errorf(errFmtRegulationDurationInvalid, section, "ban_time", err))
+	}
+
+	if findTime > banTime {
+		validator.Push(fmt.Errorf(errFmtRegulationFindTimeGreaterThanBanTime, section))
+	}
 }
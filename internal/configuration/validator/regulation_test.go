@@ -57,3 +57,31 @@ func TestShouldRaiseErrorOnBadDurationStrings(t *testing.T) {
 	assert.EqualError(t, validator.Errors()[0], "Error occurred parsing regulation find_time string: could not convert the input string of a year into a duration")
 	assert.EqualError(t, validator.Errors()[1], "Error occurred parsing regulation ban_time string: could not convert the input string of forever into a duration")
 }
+
+func TestShouldSetDefaultRegulationIPAndNetwork(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := newDefaultRegulationConfig()
+
+	ValidateRegulation(&config, validator)
+
+	assert.Len(t, validator.Errors(), 0)
+	assert.Equal(t, schema.DefaultRegulationConfiguration.IP, config.IP)
+	assert.Equal(t, schema.DefaultRegulationConfiguration.Network, config.Network)
+}
+
+func TestShouldRaiseErrorOnInvalidRegulationIPAndNetwork(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := newDefaultRegulationConfig()
+	config.IP = schema.RegulationIPConfiguration{MaxRetries: 10, FindTime: "a year", BanTime: "1h"}
+	config.Network = schema.RegulationNetworkConfiguration{MaxRetries: 10, FindTime: "1h", BanTime: "10m", IPv4PrefixLength: 33, IPv6PrefixLength: -1}
+	config.ExemptNetworks = []string{"10.0.0.0/8", "192.168.1.1", "internal"}
+
+	ValidateRegulation(&config, validator)
+
+	assert.Len(t, validator.Errors(), 5)
+	assert.EqualError(t, validator.Errors()[0], "Error occurred parsing regulation ip find_time string: could not convert the input string of a year into a duration")
+	assert.EqualError(t, validator.Errors()[1], "Regulation network find_time cannot be greater than ban_time")
+	assert.EqualError(t, validator.Errors()[2], "Regulation network ipv4_prefix_length 33 is invalid, it must be between 1 and 32")
+	assert.EqualError(t, validator.Errors()[3], "Regulation network ipv6_prefix_length -1 is invalid, it must be between 1 and 128")
+	assert.EqualError(t, validator.Errors()[4], "Regulation exempt network internal is not a valid IP address or network")
+}
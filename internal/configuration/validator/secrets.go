@@ -62,6 +62,7 @@ func ValidateSecrets(configuration *schema.Configuration, validator *schema.Stru
 	if configuration.IdentityProviders.OIDC != nil {
 		configuration.IdentityProviders.OIDC.HMACSecret = getSecretValue(SecretNames["OpenIDConnectHMACSecret"], validator, viper)
 		configuration.IdentityProviders.OIDC.IssuerPrivateKey = getSecretValue(SecretNames["OpenIDConnectIssuerPrivateKey"], validator, viper)
+		configuration.IdentityProviders.OIDC.ClientRegistration.InitialAccessToken = getSecretValue(SecretNames["OpenIDConnectInitialAccessToken"], validator, viper)
 	}
 }
 
@@ -2,6 +2,7 @@ package validator
 
 import (
 	"fmt"
+	"net/url"
 	"path"
 	"strings"
 
@@ -35,4 +36,20 @@ func ValidateServer(configuration *schema.ServerConfiguration, validator *schema
 	} else if configuration.WriteBufferSize < 0 {
 		validator.Push(fmt.Errorf("server write buffer size must be above 0"))
 	}
+
+	validateExtAuthz(&configuration.ExtAuthz, validator)
+}
+
+func validateExtAuthz(configuration *schema.ExtAuthzConfiguration, validator *schema.StructValidator) {
+	if configuration.GRPCPort < 0 || configuration.GRPCPort > 65535 {
+		validator.Push(fmt.Errorf("server ext_authz grpc port must be between 0 and 65535"))
+	}
+
+	if configuration.AutheliaURL != "" {
+		autheliaURL, err := url.Parse(configuration.AutheliaURL)
+
+		if err != nil || autheliaURL.Scheme != "https" || autheliaURL.Host == "" {
+			validator.Push(fmt.Errorf("server ext_authz authelia url must be an absolute https URL but it is %s", configuration.AutheliaURL))
+		}
+	}
 }
@@ -61,3 +61,29 @@ func TestShouldRaiseOnForwardSlashInPath(t *testing.T) {
 	assert.Len(t, validator.Errors(), 1)
 	assert.Error(t, validator.Errors()[0], "server path must not contain any forward slashes")
 }
+
+func TestShouldValidateExtAuthz(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.ServerConfiguration{
+		ExtAuthz: schema.ExtAuthzConfiguration{
+			GRPCPort:    9092,
+			AutheliaURL: "https://login.example.com",
+		},
+	}
+	ValidateServer(&config, validator)
+	require.Len(t, validator.Errors(), 0)
+}
+
+func TestShouldRaiseOnInvalidExtAuthz(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.ServerConfiguration{
+		ExtAuthz: schema.ExtAuthzConfiguration{
+			GRPCPort:    70000,
+			AutheliaURL: "http://login.example.com",
+		},
+	}
+	ValidateServer(&config, validator)
+	require.Len(t, validator.Errors(), 2)
+	assert.EqualError(t, validator.Errors()[0], "server ext_authz grpc port must be between 0 and 65535")
+	assert.EqualError(t, validator.Errors()[1], "server ext_authz authelia url must be an absolute https URL but it is http://login.example.com")
+}
@@ -0,0 +1,38 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// ValidateWebauthn validates and update Webauthn configuration.
+func ValidateWebauthn(configuration *schema.WebauthnConfiguration, validator *schema.StructValidator) {
+	if configuration.DisplayName == "" {
+		configuration.DisplayName = schema.DefaultWebauthnConfiguration.DisplayName
+	}
+
+	if configuration.Timeout == "" {
+		configuration.Timeout = schema.DefaultWebauthnConfiguration.Timeout
+	} else if _, err := utils.ParseDurationString(configuration.Timeout); err != nil {
+		validator.Push(fmt.Errorf(errFmtWebauthnTimeout, configuration.Timeout, err))
+	}
+
+	switch {
+	case configuration.ConveyancePreference == "":
+		configuration.ConveyancePreference = schema.DefaultWebauthnConfiguration.ConveyancePreference
+	case !utils.IsStringInSlice(configuration.ConveyancePreference, validWebauthnConveyancePreferences):
+		validator.Push(fmt.Errorf(errFmtWebauthnConveyancePreference, configuration.ConveyancePreference,
+			strings.Join(validWebauthnConveyancePreferences, "', '")))
+	}
+
+	switch {
+	case configuration.UserVerification == "":
+		configuration.UserVerification = schema.DefaultWebauthnConfiguration.UserVerification
+	case !utils.IsStringInSlice(configuration.UserVerification, validWebauthnUserVerificationRequirements):
+		validator.Push(fmt.Errorf(errFmtWebauthnUserVerification, configuration.UserVerification,
+			strings.Join(validWebauthnUserVerificationRequirements, "', '")))
+	}
+}
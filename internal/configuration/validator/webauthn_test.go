@@ -0,0 +1,58 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldSetDefaultWebauthnValues(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.WebauthnConfiguration{}
+
+	ValidateWebauthn(&config, validator)
+
+	require.Len(t, validator.Errors(), 0)
+	assert.False(t, config.Disable)
+	assert.Equal(t, schema.DefaultWebauthnConfiguration.DisplayName, config.DisplayName)
+	assert.Equal(t, schema.DefaultWebauthnConfiguration.Timeout, config.Timeout)
+	assert.Equal(t, schema.DefaultWebauthnConfiguration.ConveyancePreference, config.ConveyancePreference)
+	assert.Equal(t, schema.DefaultWebauthnConfiguration.UserVerification, config.UserVerification)
+}
+
+func TestShouldNotOverrideCustomWebauthnValues(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.WebauthnConfiguration{
+		DisplayName:          "Example",
+		Timeout:              "2m",
+		ConveyancePreference: "direct",
+		UserVerification:     "required",
+	}
+
+	ValidateWebauthn(&config, validator)
+
+	require.Len(t, validator.Errors(), 0)
+	assert.Equal(t, "Example", config.DisplayName)
+	assert.Equal(t, "2m", config.Timeout)
+	assert.Equal(t, "direct", config.ConveyancePreference)
+	assert.Equal(t, "required", config.UserVerification)
+}
+
+func TestShouldRaiseErrorsOnInvalidWebauthnValues(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.WebauthnConfiguration{
+		Timeout:              "abc",
+		ConveyancePreference: "enterprise",
+		UserVerification:     "always",
+	}
+
+	ValidateWebauthn(&config, validator)
+
+	require.Len(t, validator.Errors(), 3)
+	assert.EqualError(t, validator.Errors()[0], "webauthn: timeout 'abc' could not be parsed: could not convert the input string of abc into a duration")
+	assert.EqualError(t, validator.Errors()[1], "webauthn: attestation_conveyance_preference 'enterprise' is invalid, must be one of: 'none', 'indirect', 'direct'")
+	assert.EqualError(t, validator.Errors()[2], "webauthn: user_verification 'always' is invalid, must be one of: 'discouraged', 'preferred', 'required'")
+}
@@ -0,0 +1,94 @@
+package configuration
+
+import (
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/configuration/validator"
+	"github.com/authelia/authelia/internal/logging"
+)
+
+// ReadAccessControl reads the access control section of a YAML configuration and validates it. Unlike Read it doesn't
+// affect the global configuration so it can be used to reload the access control rules while Authelia is running.
+func ReadAccessControl(configPath string) (*schema.AccessControlConfiguration, []error) {
+	v := viper.New()
+
+	v.SetConfigFile(configPath)
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, []error{err}
+	}
+
+	var configuration schema.AccessControlConfiguration
+
+	if err := v.UnmarshalKey("access_control", &configuration, viper.DecodeHook(decodeHook)); err != nil {
+		return nil, []error{err}
+	}
+
+	val := schema.NewStructValidator()
+	validator.ValidateAccessControl(&configuration, val)
+	validator.ValidateRules(configuration, val)
+
+	if val.HasErrors() {
+		return nil, val.Errors()
+	}
+
+	for _, warn := range val.Warnings() {
+		logging.Logger().Warnf(warn.Error())
+	}
+
+	return &configuration, nil
+}
+
+// WatchConfig calls onChange each time the YAML configuration is written, created or replaced. The directory of the
+// configuration is watched rather than the file so that the replacements made by editors and the symlink swaps of
+// Kubernetes ConfigMaps are noticed.
+func WatchConfig(configPath string, onChange func()) (watcher *fsnotify.Watcher, err error) {
+	if watcher, err = fsnotify.NewWatcher(); err != nil {
+		return nil, err
+	}
+
+	configFile := filepath.Clean(configPath)
+	realConfigFile, _ := filepath.EvalSymlinks(configFile)
+
+	go func() {
+		logger := logging.Logger()
+
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				currentConfigFile, _ := filepath.EvalSymlinks(configFile)
+
+				written := filepath.Clean(event.Name) == configFile && event.Op&(fsnotify.Write|fsnotify.Create) != 0
+				replaced := currentConfigFile != "" && currentConfigFile != realConfigFile
+
+				if written || replaced {
+					realConfigFile = currentConfigFile
+
+					onChange()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				logger.Errorf("Error occurred watching the configuration file %s: %+v", configPath, err)
+			}
+		}
+	}()
+
+	if err = watcher.Add(filepath.Dir(configFile)); err != nil {
+		_ = watcher.Close()
+
+		return nil, err
+	}
+
+	return watcher, nil
+}
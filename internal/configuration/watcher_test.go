@@ -0,0 +1,65 @@
+package configuration
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldReadAccessControl(t *testing.T) {
+	config, errs := ReadAccessControl("./test_resources/config.yml")
+	require.Len(t, errs, 0)
+
+	assert.Equal(t, "deny", config.DefaultPolicy)
+	require.Len(t, config.Rules, 12)
+	assert.Equal(t, []string{"public.example.com"}, config.Rules[0].Domains)
+	assert.Equal(t, "bypass", config.Rules[0].Policy)
+}
+
+func TestShouldNotReadInvalidAccessControl(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yml")
+
+	require.NoError(t, ioutil.WriteFile(configPath, []byte(`
+access_control:
+  default_policy: deny
+  rules:
+    - domain: public.example.com
+      policy: allow
+`), 0600))
+
+	config, errs := ReadAccessControl(configPath)
+	assert.Nil(t, config)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "Policy [allow] for rule #1 domain: [public.example.com] is invalid, a policy must either be 'deny', 'two_factor', 'one_factor' or 'bypass'")
+
+	_, errs = ReadAccessControl(filepath.Join(dir, "missing.yml"))
+	require.Len(t, errs, 1)
+}
+
+func TestShouldWatchConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yml")
+
+	require.NoError(t, ioutil.WriteFile(configPath, []byte("access_control: {}\n"), 0600))
+
+	changes := make(chan struct{}, 10)
+
+	watcher, err := WatchConfig(configPath, func() { changes <- struct{}{} })
+	require.NoError(t, err)
+
+	defer watcher.Close()
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "other.yml"), []byte("other: true\n"), 0600))
+	require.NoError(t, ioutil.WriteFile(configPath, []byte("access_control:\n  default_policy: deny\n"), 0600))
+
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The change of the configuration was not noticed")
+	}
+}
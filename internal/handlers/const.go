@@ -3,8 +3,8 @@ package handlers
 // TOTPRegistrationAction is the string representation of the action for which the token has been produced.
 const TOTPRegistrationAction = "RegisterTOTPDevice"
 
-// U2FRegistrationAction is the string representation of the action for which the token has been produced.
-const U2FRegistrationAction = "RegisterU2FDevice"
+// WebauthnRegistrationAction is the string representation of the action for which the token has been produced.
+const WebauthnRegistrationAction = "RegisterWebauthnDevice"
 
 // ResetPasswordAction is the string representation of the action for which the token has been produced.
 const ResetPasswordAction = "ResetPassword"
@@ -25,6 +25,13 @@ const remoteNameHeader = "Remote-Name"
 const remoteEmailHeader = "Remote-Email"
 const remoteGroupsHeader = "Remote-Groups"
 
+const xOriginalURLHeader = "X-Original-URL"
+const xForwardedMethodHeader = "X-Forwarded-Method"
+
+// autheliaExplainHeader is the header explaining the access control decisions of the verify endpoint to the clients of
+// the explain networks.
+const autheliaExplainHeader = "X-Authelia-Explain"
+
 const (
 	// Forbidden means the user is forbidden the access to a resource.
 	Forbidden authorizationMatching = iota
@@ -32,15 +39,26 @@ const (
 	NotAuthorized authorizationMatching = iota
 	// Authorized means the user is authorized given her current permissions.
 	Authorized authorizationMatching = iota
+	// ReauthenticationRequired means the user must authenticate with the second factor again as their second factor
+	// authentication is older than the maximum age of the matching rule.
+	ReauthenticationRequired authorizationMatching = iota
 )
 
 const operationFailedMessage = "Operation failed."
 const authenticationFailedMessage = "Authentication failed. Check your credentials."
 const userBannedMessage = "Please retry in a few minutes."
+const accountLockedMessage = "Your account is locked."
+const accountDisabledMessage = "Your account is disabled."
+const passwordExpiredMessage = "Your password has expired and must be changed."
+const passwordMustChangeMessage = "Your password must be changed."
 const unableToRegisterOneTimePasswordMessage = "Unable to set up one-time passwords." //nolint:gosec
 const unableToRegisterSecurityKeyMessage = "Unable to register your security key."
 const unableToResetPasswordMessage = "Unable to reset your password."
 const mfaValidationFailedMessage = "Authentication failed, please retry later."
+const invalidUserCodeMessage = "The code is invalid or has expired."
+const clientNotFoundMessage = "The client doesn't exist or is defined in the configuration."
+
+const secondFactorDeviceDescriptionMaxLength = 30
 
 const ldapPasswordComplexityCode = "0000052D."
 
@@ -61,6 +79,8 @@ const movingAverageWindow = 10
 const msMinimumDelay1FA = float64(250)
 const msMaximumRandomDelay = int64(85)
 
+const regulationBansPath = "/api/regulation/bans"
+
 // OIDC constants.
 const (
 	oidcJWKsPath       = "/api/oidc/jwks"
@@ -70,8 +90,24 @@ const (
 	oidcRevokePath     = "/api/oidc/revoke"
 	oidcUserinfoPath   = "/api/oidc/userinfo"
 
+	oidcDeviceAuthorizationPath        = "/api/oidc/device_authorization"
+	oidcPushedAuthorizationRequestPath = "/api/oidc/pushed_authorization_request"
+	oidcRegistrationPath               = "/api/oidc/register"
+	oidcEndSessionPath                 = "/api/oidc/logout"
+
+	oidcClientsPath = "/api/oidc/clients"
+
 	// Note: If you change this const you must also do so in the frontend at web/src/services/Api.ts.
 	oidcConsentPath = "/api/oidc/consent"
+
+	// Note: If you change this const you must also do so in the frontend at web/src/services/Api.ts.
+	oidcConsentSessionsPath = "/api/oidc/consents"
+
+	// Note: If you change this const you must also do so in the frontend at web/src/services/Api.ts.
+	oidcDevicePath = "/api/oidc/device"
+
+	// Note: If you change this const you must also do so in the frontend at web/src/constants/Routes.ts.
+	oidcDeviceVerificationPath = "/device"
 )
 
 const (
@@ -15,9 +15,13 @@ type ConfigurationBody struct {
 // ConfigurationGet get the configuration accessible to authenticated users.
 func ConfigurationGet(ctx *middlewares.AutheliaCtx) {
 	body := ConfigurationBody{}
-	body.AvailableMethods = MethodList{authentication.TOTP, authentication.U2F}
+	body.AvailableMethods = MethodList{authentication.TOTP}
 	body.TOTPPeriod = ctx.Configuration.TOTP.Period
 
+	if !ctx.Configuration.Webauthn.Disable {
+		body.AvailableMethods = append(body.AvailableMethods, authentication.Webauthn)
+	}
+
 	if ctx.Configuration.DuoAPI != nil {
 		body.AvailableMethods = append(body.AvailableMethods, authentication.Push)
 	}
@@ -33,9 +33,10 @@ func (s *SecondFactorAvailableMethodsFixture) TestShouldServeDefaultMethods() {
 		TOTP: &schema.TOTPConfiguration{
 			Period: schema.DefaultTOTPConfiguration.Period,
 		},
+		Webauthn: &schema.WebauthnConfiguration{},
 	}
 	expectedBody := ConfigurationBody{
-		AvailableMethods:    []string{"totp", "u2f"},
+		AvailableMethods:    []string{"totp", "webauthn"},
 		SecondFactorEnabled: false,
 		TOTPPeriod:          schema.DefaultTOTPConfiguration.Period,
 	}
@@ -50,9 +51,29 @@ func (s *SecondFactorAvailableMethodsFixture) TestShouldServeDefaultMethodsAndMo
 		TOTP: &schema.TOTPConfiguration{
 			Period: schema.DefaultTOTPConfiguration.Period,
 		},
+		Webauthn: &schema.WebauthnConfiguration{},
 	}
 	expectedBody := ConfigurationBody{
-		AvailableMethods:    []string{"totp", "u2f", "mobile_push"},
+		AvailableMethods:    []string{"totp", "webauthn", "mobile_push"},
+		SecondFactorEnabled: false,
+		TOTPPeriod:          schema.DefaultTOTPConfiguration.Period,
+	}
+
+	ConfigurationGet(s.mock.Ctx)
+	s.mock.Assert200OK(s.T(), expectedBody)
+}
+
+func (s *SecondFactorAvailableMethodsFixture) TestShouldNotServeWebauthnWhenDisabled() {
+	s.mock.Ctx.Configuration = schema.Configuration{
+		TOTP: &schema.TOTPConfiguration{
+			Period: schema.DefaultTOTPConfiguration.Period,
+		},
+		Webauthn: &schema.WebauthnConfiguration{
+			Disable: true,
+		},
+	}
+	expectedBody := ConfigurationBody{
+		AvailableMethods:    []string{"totp"},
 		SecondFactorEnabled: false,
 		TOTPPeriod:          schema.DefaultTOTPConfiguration.Period,
 	}
@@ -66,6 +87,7 @@ func (s *SecondFactorAvailableMethodsFixture) TestShouldCheckSecondFactorIsDisab
 		TOTP: &schema.TOTPConfiguration{
 			Period: schema.DefaultTOTPConfiguration.Period,
 		},
+		Webauthn: &schema.WebauthnConfiguration{},
 	}
 	s.mock.Ctx.Providers.Authorizer = authorization.NewAuthorizer(
 		&schema.Configuration{
@@ -88,7 +110,7 @@ func (s *SecondFactorAvailableMethodsFixture) TestShouldCheckSecondFactorIsDisab
 			}})
 	ConfigurationGet(s.mock.Ctx)
 	s.mock.Assert200OK(s.T(), ConfigurationBody{
-		AvailableMethods:    []string{"totp", "u2f"},
+		AvailableMethods:    []string{"totp", "webauthn"},
 		SecondFactorEnabled: false,
 		TOTPPeriod:          schema.DefaultTOTPConfiguration.Period,
 	})
@@ -99,6 +121,7 @@ func (s *SecondFactorAvailableMethodsFixture) TestShouldCheckSecondFactorIsEnabl
 		TOTP: &schema.TOTPConfiguration{
 			Period: schema.DefaultTOTPConfiguration.Period,
 		},
+		Webauthn: &schema.WebauthnConfiguration{},
 	}
 	s.mock.Ctx.Providers.Authorizer = authorization.NewAuthorizer(&schema.Configuration{
 		AccessControl: schema.AccessControlConfiguration{
@@ -120,7 +143,7 @@ func (s *SecondFactorAvailableMethodsFixture) TestShouldCheckSecondFactorIsEnabl
 		}})
 	ConfigurationGet(s.mock.Ctx)
 	s.mock.Assert200OK(s.T(), ConfigurationBody{
-		AvailableMethods:    []string{"totp", "u2f"},
+		AvailableMethods:    []string{"totp", "webauthn"},
 		SecondFactorEnabled: true,
 		TOTPPeriod:          schema.DefaultTOTPConfiguration.Period,
 	})
@@ -131,6 +154,7 @@ func (s *SecondFactorAvailableMethodsFixture) TestShouldCheckSecondFactorIsEnabl
 		TOTP: &schema.TOTPConfiguration{
 			Period: schema.DefaultTOTPConfiguration.Period,
 		},
+		Webauthn: &schema.WebauthnConfiguration{},
 	}
 	s.mock.Ctx.Providers.Authorizer = authorization.NewAuthorizer(
 		&schema.Configuration{
@@ -153,7 +177,7 @@ func (s *SecondFactorAvailableMethodsFixture) TestShouldCheckSecondFactorIsEnabl
 			}})
 	ConfigurationGet(s.mock.Ctx)
 	s.mock.Assert200OK(s.T(), ConfigurationBody{
-		AvailableMethods:    []string{"totp", "u2f"},
+		AvailableMethods:    []string{"totp", "webauthn"},
 		SecondFactorEnabled: true,
 		TOTPPeriod:          schema.DefaultTOTPConfiguration.Period,
 	})
@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
 	"sync"
 	"time"
 
+	"github.com/authelia/authelia/internal/authentication"
 	"github.com/authelia/authelia/internal/middlewares"
 	"github.com/authelia/authelia/internal/regulation"
 	"github.com/authelia/authelia/internal/session"
@@ -77,12 +79,16 @@ func FirstFactorPost(msInitialDelay time.Duration, delayEnabled bool) middleware
 			return
 		}
 
-		bannedUntil, err := ctx.Providers.Regulator.Regulate(bodyJSON.Username)
+		bannedUntil, err := ctx.Providers.Regulator.Regulate(bodyJSON.Username, ctx.RemoteIP())
 
 		if err != nil {
-			if err == regulation.ErrUserIsBanned {
+			switch err {
+			case regulation.ErrUserIsBanned:
 				handleAuthenticationUnauthorized(ctx, fmt.Errorf("User %s is banned until %s", bodyJSON.Username, bannedUntil), userBannedMessage)
 				return
+			case regulation.ErrIPIsBanned:
+				handleAuthenticationUnauthorized(ctx, fmt.Errorf("IP %s is banned until %s", ctx.RemoteIP(), bannedUntil), userBannedMessage)
+				return
 			}
 
 			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to regulate authentication: %s", err.Error()), authenticationFailedMessage)
@@ -92,14 +98,19 @@ func FirstFactorPost(msInitialDelay time.Duration, delayEnabled bool) middleware
 
 		userPasswordOk, err := ctx.Providers.UserProvider.CheckUserPassword(bodyJSON.Username, bodyJSON.Password)
 
+		if errors.Is(err, authentication.ErrPasswordExpired) || errors.Is(err, authentication.ErrPasswordMustChange) {
+			handlePasswordChangeRequired(ctx, bodyJSON.Username, err)
+			return
+		}
+
 		if err != nil {
 			ctx.Logger.Debugf("Mark authentication attempt made by user %s", bodyJSON.Username)
 
-			if err := ctx.Providers.Regulator.Mark(bodyJSON.Username, false); err != nil {
+			if err := ctx.Providers.Regulator.Mark(bodyJSON.Username, ctx.RemoteIP(), false); err != nil {
 				ctx.Logger.Errorf("Unable to mark authentication: %s", err.Error())
 			}
 
-			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Error while checking password for user %s: %s", bodyJSON.Username, err.Error()), authenticationFailedMessage)
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Error while checking password for user %s: %s", bodyJSON.Username, err.Error()), authenticationFailedReasonMessage(err))
 
 			return
 		}
@@ -107,7 +118,7 @@ func FirstFactorPost(msInitialDelay time.Duration, delayEnabled bool) middleware
 		if !userPasswordOk {
 			ctx.Logger.Debugf("Mark authentication attempt made by user %s", bodyJSON.Username)
 
-			if err := ctx.Providers.Regulator.Mark(bodyJSON.Username, false); err != nil {
+			if err := ctx.Providers.Regulator.Mark(bodyJSON.Username, ctx.RemoteIP(), false); err != nil {
 				ctx.Logger.Errorf("Unable to mark authentication: %s", err.Error())
 			}
 
@@ -117,7 +128,7 @@ func FirstFactorPost(msInitialDelay time.Duration, delayEnabled bool) middleware
 		}
 
 		ctx.Logger.Debugf("Mark authentication attempt made by user %s", bodyJSON.Username)
-		err = ctx.Providers.Regulator.Mark(bodyJSON.Username, true)
+		err = ctx.Providers.Regulator.Mark(bodyJSON.Username, ctx.RemoteIP(), true)
 
 		if err != nil {
 			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to mark authentication: %s", err.Error()), authenticationFailedMessage)
@@ -184,7 +195,54 @@ func FirstFactorPost(msInitialDelay time.Duration, delayEnabled bool) middleware
 		if userSession.OIDCWorkflowSession != nil {
 			handleOIDCWorkflowResponse(ctx)
 		} else {
-			Handle1FAResponse(ctx, bodyJSON.TargetURL, bodyJSON.RequestMethod, userSession.Username, userSession.Groups)
+			Handle1FAResponse(ctx, bodyJSON.TargetURL, bodyJSON.RequestMethod, userSession.Username, userSession.Groups, userSession.Emails)
 		}
 	}
 }
+
+// handlePasswordChangeRequired starts the password change of a user whose password is correct but must be changed
+// before the user can be authenticated. The password is then changed the same way as at the end of the reset password
+// process. The session is reset and regenerated like when the user is authenticated so the password change can't be
+// started in a session known to someone else.
+func handlePasswordChangeRequired(ctx *middlewares.AutheliaCtx, username string, err error) {
+	userDetails, detailsErr := ctx.Providers.UserProvider.GetDetails(username)
+	if detailsErr != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Error while retrieving details from user %s: %s", username, detailsErr), authenticationFailedMessage)
+		return
+	}
+
+	userSession := ctx.GetSession()
+	newSession := session.NewDefaultUserSession()
+	newSession.OIDCWorkflowSession = userSession.OIDCWorkflowSession
+	newSession.PasswordResetUsername = &userDetails.Username
+
+	if err := ctx.SaveSession(newSession); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to save password change state of user %s: %s", username, err), authenticationFailedMessage)
+		return
+	}
+
+	if err := ctx.Providers.SessionProvider.RegenerateSession(ctx.RequestCtx); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to regenerate session for user %s: %s", username, err), authenticationFailedMessage)
+		return
+	}
+
+	message := passwordMustChangeMessage
+	if errors.Is(err, authentication.ErrPasswordExpired) {
+		message = passwordExpiredMessage
+	}
+
+	handleAuthenticationUnauthorized(ctx, fmt.Errorf("Password of user %s must be changed: %s", username, err), message)
+}
+
+// authenticationFailedReasonMessage returns the message of the reason the authentication failed given by the user
+// provider.
+func authenticationFailedReasonMessage(err error) string {
+	switch {
+	case errors.Is(err, authentication.ErrAccountLocked):
+		return accountLockedMessage
+	case errors.Is(err, authentication.ErrAccountDisabled):
+		return accountDisabledMessage
+	default:
+		return authenticationFailedMessage
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"fmt"
+	"net"
 	"sync"
 	"testing"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"github.com/authelia/authelia/internal/configuration/schema"
 	"github.com/authelia/authelia/internal/mocks"
 	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/regulation"
 )
 
 type FirstFactorSuite struct {
@@ -62,6 +64,7 @@ func (s *FirstFactorSuite) TestShouldFailIfUserProviderCheckPasswordFail() {
 			Username:   "test",
 			Successful: false,
 			Time:       s.mock.Clock.Now(),
+			RemoteIP:   net.ParseIP("0.0.0.0"),
 		}))
 
 	s.mock.Ctx.Request.SetBodyString(`{
@@ -87,6 +90,7 @@ func (s *FirstFactorSuite) TestShouldCheckAuthenticationIsMarkedWhenInvalidCrede
 			Username:   "test",
 			Successful: false,
 			Time:       s.mock.Clock.Now(),
+			RemoteIP:   net.ParseIP("0.0.0.0"),
 		}))
 
 	s.mock.Ctx.Request.SetBodyString(`{
@@ -98,6 +102,119 @@ func (s *FirstFactorSuite) TestShouldCheckAuthenticationIsMarkedWhenInvalidCrede
 	FirstFactorPost(0, false)(s.mock.Ctx)
 }
 
+func (s *FirstFactorSuite) TestShouldFailWithReasonIfAccountIsLocked() {
+	s.mock.UserProviderMock.
+		EXPECT().
+		CheckUserPassword(gomock.Eq("test"), gomock.Eq("hello")).
+		Return(false, fmt.Errorf("Authentication of user test failed. Cause: %w", authentication.ErrAccountLocked))
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		AppendAuthenticationLog(gomock.Eq(models.AuthenticationAttempt{
+			Username:   "test",
+			Successful: false,
+			Time:       s.mock.Clock.Now(),
+			RemoteIP:   net.ParseIP("0.0.0.0"),
+		}))
+
+	s.mock.Ctx.Request.SetBodyString(`{
+		"username": "test",
+		"password": "hello"
+	}`)
+
+	FirstFactorPost(0, false)(s.mock.Ctx)
+
+	s.mock.Assert401KO(s.T(), "Your account is locked.")
+}
+
+func (s *FirstFactorSuite) TestShouldStartPasswordChangeIfPasswordHasExpired() {
+	s.mock.UserProviderMock.
+		EXPECT().
+		CheckUserPassword(gomock.Eq("Test"), gomock.Eq("hello")).
+		Return(false, fmt.Errorf("Authentication of user Test failed. Cause: %w", authentication.ErrPasswordExpired))
+
+	s.mock.UserProviderMock.
+		EXPECT().
+		GetDetails(gomock.Eq("Test")).
+		Return(&authentication.UserDetails{
+			Username: "test",
+			Emails:   []string{"test@example.com"},
+			Groups:   []string{"dev", "admins"},
+		}, nil)
+
+	userSession := s.mock.Ctx.GetSession()
+	userSession.Username = "john"
+	s.Require().NoError(s.mock.Ctx.SaveSession(userSession))
+
+	s.mock.Ctx.Request.SetBodyString(`{
+		"username": "Test",
+		"password": "hello"
+	}`)
+
+	FirstFactorPost(0, false)(s.mock.Ctx)
+
+	s.mock.Assert401KO(s.T(), "Your password has expired and must be changed.")
+
+	userSession = s.mock.Ctx.GetSession()
+	assert.Equal(s.T(), authentication.NotAuthenticated, userSession.AuthenticationLevel)
+	assert.Equal(s.T(), "", userSession.Username)
+	s.Require().NotNil(userSession.PasswordResetUsername)
+	assert.Equal(s.T(), "test", *userSession.PasswordResetUsername)
+}
+
+func (s *FirstFactorSuite) TestShouldStartPasswordChangeIfPasswordMustChange() {
+	s.mock.UserProviderMock.
+		EXPECT().
+		CheckUserPassword(gomock.Eq("test"), gomock.Eq("hello")).
+		Return(false, authentication.ErrPasswordMustChange)
+
+	s.mock.UserProviderMock.
+		EXPECT().
+		GetDetails(gomock.Eq("test")).
+		Return(&authentication.UserDetails{
+			Username: "test",
+			Emails:   []string{"test@example.com"},
+			Groups:   []string{"dev", "admins"},
+		}, nil)
+
+	s.mock.Ctx.Request.SetBodyString(`{
+		"username": "test",
+		"password": "hello"
+	}`)
+
+	FirstFactorPost(0, false)(s.mock.Ctx)
+
+	s.mock.Assert401KO(s.T(), "Your password must be changed.")
+
+	userSession := s.mock.Ctx.GetSession()
+	s.Require().NotNil(userSession.PasswordResetUsername)
+	assert.Equal(s.T(), "test", *userSession.PasswordResetUsername)
+}
+
+func (s *FirstFactorSuite) TestShouldFailIfIPIsBanned() {
+	s.mock.Ctx.Providers.Regulator = regulation.NewRegulator(&schema.RegulationConfiguration{
+		IP: schema.RegulationIPConfiguration{MaxRetries: 3, FindTime: "5m", BanTime: "30m"},
+	}, s.mock.StorageProviderMock, &s.mock.Clock)
+
+	s.mock.Ctx.Request.Header.Set("X-Forwarded-For", "192.168.1.10")
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		LoadRegulationBan(gomock.Eq(regulation.BanTypeIP), gomock.Eq("192.168.1.10"), gomock.Eq(s.mock.Clock.Now())).
+		Return(&models.RegulationBan{ExpiresAt: s.mock.Clock.Now().Add(10 * time.Minute)}, nil)
+
+	s.mock.Ctx.Request.SetBodyString(`{
+		"username": "test",
+		"password": "hello",
+		"keepMeLoggedIn": true
+	}`)
+
+	FirstFactorPost(0, false)(s.mock.Ctx)
+
+	assert.Equal(s.T(), "IP 192.168.1.10 is banned until 2013-02-03 00:10:00 +0000 UTC", s.mock.Hook.LastEntry().Message)
+	s.mock.Assert401KO(s.T(), "Please retry in a few minutes.")
+}
+
 func (s *FirstFactorSuite) TestShouldFailIfUserProviderGetDetailsFail() {
 	s.mock.UserProviderMock.
 		EXPECT().
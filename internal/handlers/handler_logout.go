@@ -28,6 +28,10 @@ func LogoutPost(ctx *middlewares.AutheliaCtx) {
 		ctx.Error(fmt.Errorf("Unable to parse body during logout: %s", err), operationFailedMessage)
 	}
 
+	// The clients of the OpenID Connect sessions are notified with the back-channel logout, the front-channel logout is
+	// only supported by the end session endpoint.
+	oidcLogout(ctx, ctx.GetSession())
+
 	ctx.Logger.Tracef("Attempting to destroy session")
 
 	err = ctx.Providers.SessionProvider.DestroySession(ctx.RequestCtx)
@@ -2,14 +2,18 @@ package handlers
 
 import (
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/ory/fosite"
 	"github.com/ory/fosite/handler/openid"
 	"github.com/ory/fosite/token/jwt"
+	"github.com/valyala/fasthttp"
 
+	"github.com/authelia/authelia/internal/authorization"
 	"github.com/authelia/authelia/internal/logging"
 	"github.com/authelia/authelia/internal/middlewares"
 	"github.com/authelia/authelia/internal/oidc"
@@ -18,6 +22,25 @@ import (
 )
 
 func oidcAuthorize(ctx *middlewares.AutheliaCtx, rw http.ResponseWriter, r *http.Request) {
+	issuer, err := ctx.ForwardedProtoHost()
+	if err != nil {
+		ctx.Logger.Errorf("Error occurred obtaining issuer: %+v", err)
+		ctx.Providers.OpenIDConnect.Fosite.WriteAuthorizeError(rw, fosite.NewAuthorizeRequest(), err)
+
+		return
+	}
+
+	userSession := ctx.GetSession()
+
+	// The pushed authorization requests and the request objects are resolved before the request is handled by fosite.
+	requestURI, err := ctx.Providers.OpenIDConnect.ResolveAuthorizeRequest(ctx, r, issuer, userSession.OIDCWorkflowSession)
+	if err != nil {
+		ctx.Logger.Errorf("Error occurred in ResolveAuthorizeRequest: %+v", err)
+		ctx.Providers.OpenIDConnect.Fosite.WriteAuthorizeError(rw, fosite.NewAuthorizeRequest(), err)
+
+		return
+	}
+
 	ar, err := ctx.Providers.OpenIDConnect.Fosite.NewAuthorizeRequest(ctx, r)
 	if err != nil {
 		logging.Logger().Errorf("Error occurred in NewAuthorizeRequest: %+v", err)
@@ -37,35 +60,47 @@ func oidcAuthorize(ctx *middlewares.AutheliaCtx, rw http.ResponseWriter, r *http
 		return
 	}
 
-	userSession := ctx.GetSession()
-
 	requestedScopes := ar.GetRequestedScopes()
 	requestedAudience := ar.GetRequestedAudience()
 
 	isAuthInsufficient := !client.IsAuthenticationLevelSufficient(userSession.AuthenticationLevel)
 
-	if isAuthInsufficient || (isConsentMissing(userSession.OIDCWorkflowSession, requestedScopes, requestedAudience)) {
-		oidcAuthorizeHandleAuthorizationOrConsentInsufficient(ctx, userSession, client, isAuthInsufficient, rw, r, ar)
+	if !isAuthInsufficient && isOIDCClientDeniedByAccessControl(ctx.Providers.Authorizer, clientID, ar.GetRedirectURI(), &userSession, ctx.RemoteIP(), ctx.Clock.Now()) {
+		ctx.Logger.Infof("Access to client %s is denied to user %s by the access control rules", clientID, userSession.Username)
+		ctx.Providers.OpenIDConnect.Fosite.WriteAuthorizeError(rw, ar, fosite.ErrAccessDenied.WithHint("The user is not allowed to use this client."))
 
 		return
 	}
 
-	extraClaims := oidcGrantRequests(ar, requestedScopes, requestedAudience, &userSession)
+	if isAuthInsufficient || isOIDCConsentRequired(ctx, client, userSession.OIDCWorkflowSession, userSession.Username, requestedScopes, requestedAudience) {
+		oidcAuthorizeHandleAuthorizationOrConsentInsufficient(ctx, userSession, client, isAuthInsufficient, requestURI, rw, r, ar)
 
-	workflowCreated := time.Unix(userSession.OIDCWorkflowSession.CreatedTimestamp, 0)
+		return
+	}
 
-	userSession.OIDCWorkflowSession = nil
-	if err := ctx.SaveSession(userSession); err != nil {
-		ctx.Logger.Errorf("%v", err)
-		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	oidcGrantRequests(ar, requestedScopes, requestedAudience)
+
+	extraClaims, err := oidcExtraClaims(ctx, client, requestedScopes, &userSession)
+	if err != nil {
+		ctx.Logger.Errorf("Error occurred obtaining the claims of user %s: %+v", userSession.Username, err)
+		ctx.Providers.OpenIDConnect.Fosite.WriteAuthorizeError(rw, ar, fosite.ErrServerError.WithHint("Unable to obtain the claims of the user."))
 
 		return
 	}
 
-	issuer, err := ctx.ForwardedProtoHost()
-	if err != nil {
-		ctx.Logger.Errorf("Error occurred obtaining issuer: %+v", err)
-		ctx.Providers.OpenIDConnect.Fosite.WriteAuthorizeError(rw, ar, err)
+	// The workflow is not initiated when the consent is implicit or was pre-configured by the user.
+	workflowCreated := ctx.Clock.Now()
+	if userSession.OIDCWorkflowSession != nil {
+		workflowCreated = time.Unix(userSession.OIDCWorkflowSession.CreatedTimestamp, 0)
+	}
+
+	// The client is notified when the session is destroyed, the sid claim identifies the session to the client.
+	extraClaims["sid"] = oidcAddLogoutClient(&userSession, issuer, clientID)
+
+	userSession.OIDCWorkflowSession = nil
+	if err := ctx.SaveSession(userSession); err != nil {
+		ctx.Logger.Errorf("%v", err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
 
 		return
 	}
@@ -91,7 +126,7 @@ func oidcAuthorize(ctx *middlewares.AutheliaCtx, rw http.ResponseWriter, r *http
 				Extra:       extraClaims,
 			},
 			Headers: &jwt.Headers{Extra: map[string]interface{}{
-				"kid": ctx.Providers.OpenIDConnect.KeyManager.GetActiveKeyID(),
+				"kid": ctx.Providers.OpenIDConnect.KeyManager.GetActiveKeyID(client.IDTokenSigningAlgorithm),
 			}},
 			Subject: userSession.Username,
 		},
@@ -107,12 +142,76 @@ func oidcAuthorize(ctx *middlewares.AutheliaCtx, rw http.ResponseWriter, r *http
 	ctx.Providers.OpenIDConnect.Fosite.WriteAuthorizeResponse(rw, ar, response)
 }
 
-func oidcGrantRequests(ar fosite.AuthorizeRequester, scopes, audiences []string, userSession *session.UserSession) (extraClaims map[string]interface{}) {
-	extraClaims = map[string]interface{}{}
+// isOIDCClientDeniedByAccessControl returns true if an access control rule with an OpenID Connect client subject denies
+// the user the use of the client. The rules are evaluated for the redirect URI of the authorization request, and only
+// the explicit deny rules referencing a client apply so the default policy doesn't affect the clients.
+func isOIDCClientDeniedByAccessControl(authorizer *authorization.Authorizer, clientID string, redirectURI *url.URL, userSession *session.UserSession, ip net.IP, now time.Time) bool {
+	if redirectURI == nil {
+		return false
+	}
 
+	subject := authorization.Subject{
+		Username: userSession.Username,
+		Groups:   userSession.Groups,
+		Emails:   userSession.Emails,
+		IP:       ip,
+		ClientID: clientID,
+	}
+
+	rule := authorizer.GetMatchingRule(subject, authorization.NewObject(redirectURI, fasthttp.MethodGet, now))
+
+	return rule != nil && rule.Policy == authorization.Denied && rule.HasClientSubjects()
+}
+
+func oidcGrantRequests(ar fosite.AuthorizeRequester, scopes, audiences []string) {
 	for _, scope := range scopes {
 		ar.GrantScope(scope)
+	}
+
+	for _, audience := range oidcGrantedAudience(ar.GetClient().GetID(), audiences) {
+		ar.GrantAudience(audience)
+	}
+}
+
+// oidcGrantedAudience returns the audiences granted to a client, the client itself is always part of the audience.
+func oidcGrantedAudience(clientID string, audiences []string) (granted []string) {
+	granted = append(granted, audiences...)
+
+	if !utils.IsStringInSlice(clientID, granted) {
+		granted = append(granted, clientID)
+	}
+
+	return granted
+}
+
+// oidcExtraClaims returns the claims of the user which the granted scopes give access to, including the custom claims
+// of the claims policy of the client. The user attributes the custom claims are taken from are retrieved from the user
+// provider so they are up to date.
+func oidcExtraClaims(ctx *middlewares.AutheliaCtx, client *oidc.InternalClient, scopes []string, userSession *session.UserSession) (extraClaims map[string]interface{}, err error) {
+	extraClaims = oidcStandardClaims(scopes, userSession)
+
+	if client.ClaimsPolicy == nil {
+		return extraClaims, nil
+	}
+
+	var values map[string][]string
+
+	if attributes := client.ClaimsPolicy.Attributes(scopes); len(attributes) != 0 {
+		if values, err = ctx.Providers.UserProvider.GetAttributes(userSession.Username, attributes); err != nil {
+			return nil, err
+		}
+	}
+
+	client.ClaimsPolicy.Apply(extraClaims, scopes, userSession.Groups, values)
+
+	return extraClaims, nil
+}
+
+// oidcStandardClaims returns the standard claims of the user which the granted scopes give access to.
+func oidcStandardClaims(scopes []string, userSession *session.UserSession) (extraClaims map[string]interface{}) {
+	extraClaims = map[string]interface{}{}
 
+	for _, scope := range scopes {
 		switch scope {
 		case "groups":
 			extraClaims["groups"] = userSession.Groups
@@ -130,20 +229,12 @@ func oidcGrantRequests(ar fosite.AuthorizeRequester, scopes, audiences []string,
 		}
 	}
 
-	for _, audience := range audiences {
-		ar.GrantAudience(audience)
-	}
-
-	if !utils.IsStringInSlice(ar.GetClient().GetID(), ar.GetGrantedAudience()) {
-		ar.GrantAudience(ar.GetClient().GetID())
-	}
-
 	return extraClaims
 }
 
 func oidcAuthorizeHandleAuthorizationOrConsentInsufficient(
 	ctx *middlewares.AutheliaCtx, userSession session.UserSession, client *oidc.InternalClient, isAuthInsufficient bool,
-	rw http.ResponseWriter, r *http.Request,
+	requestURI string, rw http.ResponseWriter, r *http.Request,
 	ar fosite.AuthorizeRequester) {
 	forwardedProtoHost, err := ctx.ForwardedProtoHost()
 	if err != nil {
@@ -168,6 +259,12 @@ func oidcAuthorizeHandleAuthorizationOrConsentInsufficient(
 		CreatedTimestamp:           time.Now().Unix(),
 	}
 
+	// The pushed authorization request has been consumed, its parameters are kept for the next authorization request.
+	if requestURI != "" {
+		userSession.OIDCWorkflowSession.PushedAuthorizeRequestURI = requestURI
+		userSession.OIDCWorkflowSession.PushedAuthorizeRequestForm = r.URL.RawQuery
+	}
+
 	if err := ctx.SaveSession(userSession); err != nil {
 		ctx.Logger.Errorf("%v", err)
 		http.Error(rw, err.Error(), http.StatusInternalServerError)
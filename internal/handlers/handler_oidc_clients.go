@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ory/fosite"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/configuration/validator"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/oidc"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// OIDCClientsGet lists the OpenID Connect clients registered at runtime. The clients defined in the configuration
+// are not listed as they can't be managed.
+func OIDCClientsGet(ctx *middlewares.AutheliaCtx) {
+	clients, err := ctx.Providers.OpenIDConnect.Store.GetRegisteredClients()
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to load OpenID Connect clients: %w", err), operationFailedMessage)
+		return
+	}
+
+	response := make([]oidcClientResponse, len(clients))
+
+	for i, client := range clients {
+		response[i] = newOIDCClientResponse(client, "")
+	}
+
+	if err = ctx.SetJSONBody(response); err != nil {
+		ctx.Logger.Errorf("Unable to set OpenID Connect clients response in body: %s", err)
+	}
+}
+
+// OIDCClientsPost registers a new OpenID Connect client. The generated secret is only sent in the response.
+func OIDCClientsPost(ctx *middlewares.AutheliaCtx) {
+	var body oidcClientRequestBody
+
+	if err := ctx.ParseBody(&body); err != nil {
+		ctx.Error(err, operationFailedMessage)
+		return
+	}
+
+	client, secret, err := oidcCreateClient(ctx, body.toConfiguration())
+
+	switch {
+	case errors.Is(err, errInvalidClientMetadata):
+		ctx.Error(err, err.Error())
+		return
+	case errors.Is(err, oidc.ErrClientAlreadyExists):
+		ctx.Error(fmt.Errorf("Unable to register OpenID Connect client %s: %w", body.ID, err), "A client with this ID already exists.")
+		return
+	case err != nil:
+		ctx.Error(fmt.Errorf("Unable to register OpenID Connect client %s: %w", body.ID, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Infof("OpenID Connect client %s was registered by %s", client.ClientID, ctx.GetSession().Username)
+
+	if err = ctx.SetJSONBody(newOIDCClientResponse(client, secret)); err != nil {
+		ctx.Logger.Errorf("Unable to set OpenID Connect client response in body: %s", err)
+	}
+}
+
+// OIDCClientPut updates the metadata of an OpenID Connect client registered at runtime. The secret is left untouched.
+func OIDCClientPut(ctx *middlewares.AutheliaCtx) {
+	id, existing, ok := oidcLoadRegisteredClient(ctx)
+	if !ok {
+		return
+	}
+
+	var body oidcClientRequestBody
+
+	if err := ctx.ParseBody(&body); err != nil {
+		ctx.Error(err, operationFailedMessage)
+		return
+	}
+
+	config := body.toConfiguration()
+	config.ID = id
+	config.Secret = existing.Secret
+
+	if err := oidcValidateClient(ctx, &config); err != nil {
+		ctx.Error(err, err.Error())
+		return
+	}
+
+	client := oidc.NewClientModel(config)
+	client.ID = existing.ID
+	client.CreatedAt = existing.CreatedAt
+	client.UpdatedAt = ctx.Clock.Now()
+
+	if err := ctx.Providers.OpenIDConnect.Store.UpdateClient(client); err != nil {
+		ctx.Error(fmt.Errorf("Unable to update OpenID Connect client %s: %w", id, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Infof("OpenID Connect client %s was updated by %s", id, ctx.GetSession().Username)
+
+	if err := ctx.SetJSONBody(newOIDCClientResponse(client, "")); err != nil {
+		ctx.Logger.Errorf("Unable to set OpenID Connect client response in body: %s", err)
+	}
+}
+
+// OIDCClientSecretPost replaces the secret of an OpenID Connect client registered at runtime with a newly generated
+// one. The previous secret stops working immediately.
+func OIDCClientSecretPost(ctx *middlewares.AutheliaCtx) {
+	id, client, ok := oidcLoadRegisteredClient(ctx)
+	if !ok {
+		return
+	}
+
+	secret, hash, err := oidc.NewClientSecret(ctx)
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to generate a secret for OpenID Connect client %s: %w", id, err), operationFailedMessage)
+		return
+	}
+
+	client.Secret = hash
+	client.UpdatedAt = ctx.Clock.Now()
+
+	if err = ctx.Providers.OpenIDConnect.Store.RotateClientSecret(id, hash, client.UpdatedAt); err != nil {
+		ctx.Error(fmt.Errorf("Unable to rotate the secret of OpenID Connect client %s: %w", id, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Infof("The secret of OpenID Connect client %s was rotated by %s", id, ctx.GetSession().Username)
+
+	if err = ctx.SetJSONBody(newOIDCClientResponse(*client, secret)); err != nil {
+		ctx.Logger.Errorf("Unable to set OpenID Connect client response in body: %s", err)
+	}
+}
+
+// OIDCClientDelete removes an OpenID Connect client registered at runtime.
+func OIDCClientDelete(ctx *middlewares.AutheliaCtx) {
+	id, _, ok := oidcLoadRegisteredClient(ctx)
+	if !ok {
+		return
+	}
+
+	if err := ctx.Providers.OpenIDConnect.Store.DeleteClient(id); err != nil {
+		ctx.Error(fmt.Errorf("Unable to delete OpenID Connect client %s: %w", id, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Infof("OpenID Connect client %s was deleted by %s", id, ctx.GetSession().Username)
+
+	ctx.ReplyOK()
+}
+
+// oidcRequireClientAdministrator ensures the user is a member of the group allowed to manage the OpenID Connect
+// clients.
+func oidcRequireClientAdministrator(next middlewares.RequestHandler) middlewares.RequestHandler {
+	return func(ctx *middlewares.AutheliaCtx) {
+		var group string
+
+		if ctx.Configuration.IdentityProviders.OIDC != nil {
+			group = ctx.Configuration.IdentityProviders.OIDC.ClientRegistration.AdminGroup
+		}
+
+		if group == "" || !utils.IsStringInSlice(group, ctx.GetSession().Groups) {
+			ctx.ReplyForbidden()
+			return
+		}
+
+		next(ctx)
+	}
+}
+
+func oidcLoadRegisteredClient(ctx *middlewares.AutheliaCtx) (id string, client *models.OAuth2Client, ok bool) {
+	id, ok = ctx.UserValue("id").(string)
+	if !ok {
+		ctx.Error(errors.New("no client id was provided"), operationFailedMessage)
+		return "", nil, false
+	}
+
+	client, err := ctx.Providers.OpenIDConnect.Store.GetRegisteredClient(id)
+
+	switch {
+	case errors.Is(err, fosite.ErrNotFound), errors.Is(err, oidc.ErrClientConfigured):
+		ctx.Error(fmt.Errorf("Unable to load OpenID Connect client %s: %w", id, err), clientNotFoundMessage)
+		return "", nil, false
+	case err != nil:
+		ctx.Error(fmt.Errorf("Unable to load OpenID Connect client %s: %w", id, err), operationFailedMessage)
+		return "", nil, false
+	}
+
+	return id, client, true
+}
+
+// oidcCreateClient generates the secret of the client, validates and persists it. The secret is returned as only its
+// hash is persisted.
+func oidcCreateClient(ctx *middlewares.AutheliaCtx, config schema.OpenIDConnectClientConfiguration) (client models.OAuth2Client, secret string, err error) {
+	if secret, config.Secret, err = oidc.NewClientSecret(ctx); err != nil {
+		return client, "", err
+	}
+
+	if err = oidcValidateClient(ctx, &config); err != nil {
+		return client, "", err
+	}
+
+	client = oidc.NewClientModel(config)
+	client.CreatedAt = ctx.Clock.Now()
+	client.UpdatedAt = client.CreatedAt
+
+	if err = ctx.Providers.OpenIDConnect.Store.CreateClient(client); err != nil {
+		return client, "", err
+	}
+
+	return client, secret, nil
+}
+
+// oidcValidateClient validates the client with the same rules as the clients defined in the configuration and
+// applies the defaults.
+func oidcValidateClient(ctx *middlewares.AutheliaCtx, config *schema.OpenIDConnectClientConfiguration) (err error) {
+	structValidator := schema.NewStructValidator()
+
+	validator.ValidateOpenIDConnectClient(config, structValidator)
+
+	if structValidator.HasErrors() {
+		messages := make([]string, len(structValidator.Errors()))
+
+		for i, err := range structValidator.Errors() {
+			messages[i] = err.Error()
+		}
+
+		return fmt.Errorf("%w: %s", errInvalidClientMetadata, strings.Join(messages, ", "))
+	}
+
+	if err = ctx.Providers.OpenIDConnect.ValidateClientSigningAlgorithms(*config); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidClientMetadata, err)
+	}
+
+	return nil
+}
+
+func (b oidcClientRequestBody) toConfiguration() schema.OpenIDConnectClientConfiguration {
+	return schema.OpenIDConnectClientConfiguration{
+		ID:            b.ID,
+		Description:   b.Description,
+		RedirectURIs:  b.RedirectURIs,
+		Policy:        b.Policy,
+		Scopes:        b.Scopes,
+		Audience:      b.Audience,
+		GrantTypes:    b.GrantTypes,
+		ResponseTypes: b.ResponseTypes,
+		ResponseModes: b.ResponseModes,
+
+		IDTokenSigningAlgorithm:  b.IDTokenSigningAlgorithm,
+		UserinfoSigningAlgorithm: b.UserinfoSigningAlgorithm,
+
+		ConsentMode:                  b.ConsentMode,
+		PreConfiguredConsentDuration: time.Duration(b.PreConfiguredConsentDuration) * time.Second,
+
+		TokenEndpointAuthMethod: b.TokenEndpointAuthMethod,
+
+		RequirePushedAuthorizationRequests: b.RequirePushedAuthorization,
+		RequirePKCE:                        b.RequirePKCE,
+
+		RequestObjectSigningAlgorithm: b.RequestObjectSigningAlg,
+		RequestURIs:                   b.RequestURIs,
+		JWKSURI:                       b.JWKSURI,
+
+		PostLogoutRedirectURIs: b.PostLogoutRedirectURIs,
+		FrontChannelLogoutURI:  b.FrontChannelLogoutURI,
+		BackChannelLogoutURI:   b.BackChannelLogoutURI,
+	}
+}
+
+func newOIDCClientResponse(client models.OAuth2Client, secret string) oidcClientResponse {
+	return oidcClientResponse{
+		ID:                           client.ClientID,
+		Secret:                       secret,
+		Description:                  client.Description,
+		RedirectURIs:                 client.RedirectURIs,
+		Policy:                       client.Policy,
+		Scopes:                       client.Scopes,
+		Audience:                     client.Audience,
+		GrantTypes:                   client.GrantTypes,
+		ResponseTypes:                client.ResponseTypes,
+		ResponseModes:                client.ResponseModes,
+		IDTokenSigningAlgorithm:      client.IDTokenSigningAlgorithm,
+		UserinfoSigningAlgorithm:     client.UserinfoSigningAlgorithm,
+		ConsentMode:                  client.ConsentMode,
+		PreConfiguredConsentDuration: int64(client.PreConfiguredConsentDuration / time.Second),
+		TokenEndpointAuthMethod:      client.TokenEndpointAuthMethod,
+		RequirePushedAuthorization:   client.RequirePushedAuthorizationRequests,
+		RequirePKCE:                  client.RequirePKCE,
+		RequestObjectSigningAlg:      client.RequestObjectSigningAlgorithm,
+		RequestURIs:                  client.RequestURIs,
+		JWKSURI:                      client.JWKSURI,
+		PostLogoutRedirectURIs:       client.PostLogoutRedirectURIs,
+		FrontChannelLogoutURI:        client.FrontChannelLogoutURI,
+		BackChannelLogoutURI:         client.BackChannelLogoutURI,
+		CreatedAt:                    client.CreatedAt,
+		UpdatedAt:                    client.UpdatedAt,
+	}
+}
@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/authorization"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/oidc"
+)
+
+const testInitialAccessToken = "Gr7bN3eCuNpGQd8Yq1sfHVZjt6ZxLgkW"
+
+type HandlerOIDCClientsSuite struct {
+	suite.Suite
+
+	mock *mocks.MockAutheliaCtx
+}
+
+func (s *HandlerOIDCClientsSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Ctx.Clock = &s.mock.Clock
+	userSession := s.mock.Ctx.GetSession()
+	userSession.Username = testUsername
+	userSession.Groups = []string{"admins"}
+	userSession.AuthenticationLevel = authentication.TwoFactor
+	err := s.mock.Ctx.SaveSession(userSession)
+	require.NoError(s.T(), err)
+
+	s.mock.Ctx.Configuration.IdentityProviders.OIDC = &schema.OpenIDConnectConfiguration{
+		ClientRegistration: schema.OpenIDConnectClientRegistrationConfiguration{
+			AdminGroup:         "admins",
+			InitialAccessToken: testInitialAccessToken,
+		},
+	}
+
+	store, err := oidc.NewOpenIDConnectStore(&schema.OpenIDConnectConfiguration{
+		Clients: []schema.OpenIDConnectClientConfiguration{
+			{ID: "configured", Description: "Configured App", Policy: "two_factor"},
+		},
+	}, s.mock.StorageProviderMock)
+	require.NoError(s.T(), err)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(s.T(), err)
+
+	keyManager := oidc.NewKeyManager()
+	_, err = keyManager.AddPrivateKey("", oidc.SigningAlgorithmRSAWithSHA256, key, time.Time{})
+	require.NoError(s.T(), err)
+
+	s.mock.Ctx.Providers.OpenIDConnect.Store = store
+	s.mock.Ctx.Providers.OpenIDConnect.KeyManager = keyManager
+}
+
+func (s *HandlerOIDCClientsSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *HandlerOIDCClientsSuite) registeredClient() models.OAuth2Client {
+	return models.OAuth2Client{
+		ID:                       1,
+		ClientID:                 "grafana",
+		Description:              "Grafana",
+		Secret:                   "$argon2id$v=19$m=65536,t=1,p=8$c2FsdA$hash",
+		RedirectURIs:             []string{"https://grafana.example.com/login/generic_oauth"},
+		Policy:                   "two_factor",
+		Scopes:                   []string{"openid", "groups"},
+		GrantTypes:               []string{"authorization_code"},
+		ResponseTypes:            []string{"code"},
+		ResponseModes:            []string{"form_post"},
+		IDTokenSigningAlgorithm:  "RS256",
+		UserinfoSigningAlgorithm: "none",
+		ConsentMode:              "explicit",
+		CreatedAt:                time.Unix(1577880001, 0).UTC(),
+		UpdatedAt:                time.Unix(1577880001, 0).UTC(),
+	}
+}
+
+func (s *HandlerOIDCClientsSuite) assertSecret(hash, secret string) {
+	s.Assert().True(oidc.IsHashedSecret(hash))
+	s.Assert().NoError(oidc.AutheliaHasher{}.Compare(context.Background(), []byte(hash), []byte(secret)))
+}
+
+func (s *HandlerOIDCClientsSuite) TestShouldForbidUsersNotInTheAdminGroup() {
+	userSession := s.mock.Ctx.GetSession()
+	userSession.Groups = []string{"dev"}
+	s.Require().NoError(s.mock.Ctx.SaveSession(userSession))
+
+	oidcRequireClientAdministrator(OIDCClientsGet)(s.mock.Ctx)
+
+	s.Assert().Equal(fasthttp.StatusForbidden, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *HandlerOIDCClientsSuite) TestShouldForbidAllUsersWithoutAdminGroup() {
+	s.mock.Ctx.Configuration.IdentityProviders.OIDC.ClientRegistration.AdminGroup = ""
+
+	oidcRequireClientAdministrator(OIDCClientsGet)(s.mock.Ctx)
+
+	s.Assert().Equal(fasthttp.StatusForbidden, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *HandlerOIDCClientsSuite) TestShouldListClients() {
+	client := s.registeredClient()
+
+	s.mock.StorageProviderMock.EXPECT().
+		LoadOAuth2Clients().
+		Return([]models.OAuth2Client{client}, nil)
+
+	oidcRequireClientAdministrator(OIDCClientsGet)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), []oidcClientResponse{newOIDCClientResponse(client, "")})
+}
+
+func (s *HandlerOIDCClientsSuite) TestShouldCreateClient() {
+	var saved models.OAuth2Client
+
+	s.mock.StorageProviderMock.EXPECT().
+		SaveOAuth2Client(gomock.Any()).
+		DoAndReturn(func(client models.OAuth2Client) error {
+			saved = client
+			return nil
+		})
+
+	s.mock.Ctx.Request.SetBodyString(`{"id":"grafana","redirect_uris":["https://grafana.example.com/login/generic_oauth"],"authorization_policy":"one_factor","pre_configured_consent_duration":3600}`)
+
+	OIDCClientsPost(s.mock.Ctx)
+
+	response := oidcClientResponse{}
+	s.mock.GetResponseData(s.T(), &response)
+
+	s.Assert().Equal("grafana", response.ID)
+	s.Assert().Equal("grafana", response.Description)
+	s.Assert().Equal("one_factor", response.Policy)
+	s.Assert().Equal([]string{"openid", "groups", "profile", "email"}, response.Scopes)
+	s.Assert().Equal("pre-configured", response.ConsentMode)
+	s.Assert().Equal(int64(3600), response.PreConfiguredConsentDuration)
+	s.Assert().Len(response.Secret, 43)
+
+	s.Assert().Equal("grafana", saved.ClientID)
+	s.Assert().Equal(time.Hour, saved.PreConfiguredConsentDuration)
+	s.Assert().Equal(s.mock.Clock.Now(), saved.CreatedAt)
+	s.assertSecret(saved.Secret, response.Secret)
+
+	client, err := s.mock.Ctx.Providers.OpenIDConnect.Store.GetInternalClient("grafana")
+	s.Require().NoError(err)
+	s.Assert().Equal([]byte(saved.Secret), client.GetHashedSecret())
+}
+
+func (s *HandlerOIDCClientsSuite) TestShouldFailToCreateInvalidClient() {
+	s.mock.Ctx.Request.SetBodyString(`{"id":"grafana","authorization_policy":"deny"}`)
+
+	OIDCClientsPost(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "invalid client metadata: OIDC client with ID 'grafana' has an invalid policy 'deny', should be either 'one_factor' or 'two_factor'")
+}
+
+func (s *HandlerOIDCClientsSuite) TestShouldFailToCreateClientWithoutIssuerPrivateKey() {
+	s.mock.Ctx.Request.SetBodyString(`{"id":"grafana","id_token_signing_algorithm":"ES256"}`)
+
+	OIDCClientsPost(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "invalid client metadata: OIDC client with ID 'grafana' requires an issuer private key: failed to retrieve an active key for the ES256 algorithm")
+}
+
+func (s *HandlerOIDCClientsSuite) TestShouldFailToCreateClientWithExistingID() {
+	s.mock.Ctx.Request.SetBodyString(`{"id":"configured"}`)
+
+	OIDCClientsPost(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "A client with this ID already exists.")
+}
+
+func (s *HandlerOIDCClientsSuite) TestShouldUpdateClient() {
+	existing := s.registeredClient()
+
+	var updated models.OAuth2Client
+
+	gomock.InOrder(
+		s.mock.StorageProviderMock.EXPECT().
+			LoadOAuth2Client(gomock.Eq("grafana")).
+			Return(&existing, nil),
+		s.mock.StorageProviderMock.EXPECT().
+			UpdateOAuth2Client(gomock.Any()).
+			DoAndReturn(func(client models.OAuth2Client) error {
+				updated = client
+				return nil
+			}),
+		s.mock.StorageProviderMock.EXPECT().
+			LoadOAuth2Client(gomock.Eq("grafana")).
+			DoAndReturn(func(_ string) (*models.OAuth2Client, error) {
+				return &updated, nil
+			}),
+	)
+
+	s.mock.Ctx.SetUserValue("id", "grafana")
+	s.mock.Ctx.Request.SetBodyString(`{"id":"ignored","description":"Grafana Dashboards","redirect_uris":["https://grafana.example.com/login/generic_oauth"],"authorization_policy":"one_factor"}`)
+
+	OIDCClientPut(s.mock.Ctx)
+
+	s.Assert().Equal("grafana", updated.ClientID)
+	s.Assert().Equal("Grafana Dashboards", updated.Description)
+	s.Assert().Equal(existing.Secret, updated.Secret)
+	s.Assert().Equal(existing.CreatedAt, updated.CreatedAt)
+	s.Assert().Equal(s.mock.Clock.Now(), updated.UpdatedAt)
+
+	s.mock.Assert200OK(s.T(), newOIDCClientResponse(updated, ""))
+	s.Assert().Equal(authorization.OneFactor, s.mock.Ctx.Providers.OpenIDConnect.Store.GetClientPolicy("grafana"))
+}
+
+func (s *HandlerOIDCClientsSuite) TestShouldRotateClientSecret() {
+	existing := s.registeredClient()
+	rotated := existing
+
+	var hash string
+
+	gomock.InOrder(
+		s.mock.StorageProviderMock.EXPECT().
+			LoadOAuth2Client(gomock.Eq("grafana")).
+			Return(&existing, nil),
+		s.mock.StorageProviderMock.EXPECT().
+			UpdateOAuth2ClientSecret(gomock.Eq("grafana"), gomock.Any(), gomock.Eq(s.mock.Clock.Now())).
+			DoAndReturn(func(_, secret string, _ time.Time) error {
+				hash = secret
+				rotated.Secret = secret
+				return nil
+			}),
+		s.mock.StorageProviderMock.EXPECT().
+			LoadOAuth2Client(gomock.Eq("grafana")).
+			Return(&rotated, nil),
+	)
+
+	s.mock.Ctx.SetUserValue("id", "grafana")
+
+	OIDCClientSecretPost(s.mock.Ctx)
+
+	response := oidcClientResponse{}
+	s.mock.GetResponseData(s.T(), &response)
+
+	s.Assert().Equal("grafana", response.ID)
+	s.assertSecret(hash, response.Secret)
+
+	client, err := s.mock.Ctx.Providers.OpenIDConnect.Store.GetInternalClient("grafana")
+	s.Require().NoError(err)
+	s.Assert().Equal([]byte(hash), client.GetHashedSecret())
+}
+
+func (s *HandlerOIDCClientsSuite) TestShouldDeleteClient() {
+	existing := s.registeredClient()
+
+	gomock.InOrder(
+		s.mock.StorageProviderMock.EXPECT().
+			LoadOAuth2Client(gomock.Eq("grafana")).
+			Return(&existing, nil),
+		s.mock.StorageProviderMock.EXPECT().
+			DeleteOAuth2Client(gomock.Eq("grafana")).
+			Return(nil),
+	)
+
+	s.mock.Ctx.SetUserValue("id", "grafana")
+
+	OIDCClientDelete(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+}
+
+func (s *HandlerOIDCClientsSuite) TestShouldNotManageConfiguredClient() {
+	s.mock.Ctx.SetUserValue("id", "configured")
+
+	OIDCClientDelete(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), clientNotFoundMessage)
+	s.Assert().True(s.mock.Ctx.Providers.OpenIDConnect.Store.IsValidClientID("configured"))
+}
+
+func (s *HandlerOIDCClientsSuite) TestShouldRegisterClientDynamically() {
+	var saved models.OAuth2Client
+
+	s.mock.StorageProviderMock.EXPECT().
+		SaveOAuth2Client(gomock.Any()).
+		DoAndReturn(func(client models.OAuth2Client) error {
+			saved = client
+			return nil
+		})
+
+	s.mock.Ctx.Request.Header.Set(AuthorizationHeader, "Bearer "+testInitialAccessToken)
+	s.mock.Ctx.Request.SetBodyString(`{"client_name":"Wiki","redirect_uris":["https://wiki.example.com/callback"],"scope":"openid email","token_endpoint_auth_method":"client_secret_post"}`)
+
+	OIDCRegistrationPost(s.mock.Ctx)
+
+	s.Require().Equal(fasthttp.StatusCreated, s.mock.Ctx.Response.StatusCode())
+
+	response := oidcRegistrationResponseBody{}
+	s.Require().NoError(json.Unmarshal(s.mock.Ctx.Response.Body(), &response))
+
+	s.Assert().Equal(saved.ClientID, response.ClientID)
+	s.Assert().Len(response.ClientID, 36)
+	s.Assert().Equal("Wiki", response.ClientName)
+	s.Assert().Equal("openid email", response.Scope)
+	s.Assert().Equal("client_secret_post", response.TokenEndpointAuthMethod)
+	s.Assert().Equal(s.mock.Clock.Now().Unix(), response.ClientIDIssuedAt)
+	s.Assert().Equal(int64(0), response.ClientSecretExpiresAt)
+
+	s.Assert().Equal("two_factor", saved.Policy)
+	s.Assert().Equal("explicit", saved.ConsentMode)
+	s.Assert().Equal("client_secret_post", saved.TokenEndpointAuthMethod)
+	s.assertSecret(saved.Secret, response.ClientSecret)
+}
+
+func (s *HandlerOIDCClientsSuite) TestShouldRegisterClientDynamicallyWithDefaultTokenEndpointAuthMethod() {
+	var saved models.OAuth2Client
+
+	s.mock.StorageProviderMock.EXPECT().
+		SaveOAuth2Client(gomock.Any()).
+		DoAndReturn(func(client models.OAuth2Client) error {
+			saved = client
+			return nil
+		})
+
+	s.mock.Ctx.Request.Header.Set(AuthorizationHeader, "Bearer "+testInitialAccessToken)
+	s.mock.Ctx.Request.SetBodyString(`{"client_name":"Wiki","redirect_uris":["https://wiki.example.com/callback"]}`)
+
+	OIDCRegistrationPost(s.mock.Ctx)
+
+	s.Require().Equal(fasthttp.StatusCreated, s.mock.Ctx.Response.StatusCode())
+
+	response := oidcRegistrationResponseBody{}
+	s.Require().NoError(json.Unmarshal(s.mock.Ctx.Response.Body(), &response))
+
+	s.Assert().Equal("client_secret_basic", response.TokenEndpointAuthMethod)
+	s.Assert().Equal("client_secret_basic", saved.TokenEndpointAuthMethod)
+}
+
+func (s *HandlerOIDCClientsSuite) TestShouldRejectDynamicRegistrationWithInvalidToken() {
+	s.mock.Ctx.Request.Header.Set(AuthorizationHeader, "Bearer invalid")
+	s.mock.Ctx.Request.SetBodyString(`{"client_name":"Wiki"}`)
+
+	OIDCRegistrationPost(s.mock.Ctx)
+
+	s.Assert().Equal(fasthttp.StatusUnauthorized, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal(`Bearer error="invalid_token"`, string(s.mock.Ctx.Response.Header.Peek("WWW-Authenticate")))
+	s.Assert().JSONEq(`{"error":"invalid_token","error_description":"The initial access token is invalid."}`, string(s.mock.Ctx.Response.Body()))
+}
+
+func (s *HandlerOIDCClientsSuite) TestShouldRejectDynamicRegistrationWithInvalidMetadata() {
+	s.mock.Ctx.Request.Header.Set(AuthorizationHeader, "Bearer "+testInitialAccessToken)
+	s.mock.Ctx.Request.SetBodyString(`{"client_name":"Wiki","token_endpoint_auth_method":"private_key_jwt"}`)
+
+	OIDCRegistrationPost(s.mock.Ctx)
+
+	s.Assert().Equal(fasthttp.StatusBadRequest, s.mock.Ctx.Response.StatusCode())
+	s.Assert().JSONEq(`{"error":"invalid_client_metadata","error_description":"The token endpoint authentication method 'private_key_jwt' is not supported."}`, string(s.mock.Ctx.Response.Body()))
+}
+
+func (s *HandlerOIDCClientsSuite) TestShouldNotServeDynamicRegistrationWithoutInitialAccessToken() {
+	s.mock.Ctx.Configuration.IdentityProviders.OIDC.ClientRegistration.InitialAccessToken = ""
+	s.mock.Ctx.Request.Header.Set(AuthorizationHeader, "Bearer ")
+
+	OIDCRegistrationPost(s.mock.Ctx)
+
+	s.Assert().Equal(fasthttp.StatusNotFound, s.mock.Ctx.Response.StatusCode())
+}
+
+func TestRunHandlerOIDCClientsSuite(t *testing.T) {
+	s := new(HandlerOIDCClientsSuite)
+	suite.Run(t, s)
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 
 	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/oidc"
+	"github.com/authelia/authelia/internal/session"
 )
 
 func oidcConsent(ctx *middlewares.AutheliaCtx) {
@@ -95,6 +98,13 @@ func oidcConsentPOST(ctx *middlewares.AutheliaCtx) {
 		userSession.OIDCWorkflowSession.GrantedScopes = userSession.OIDCWorkflowSession.RequestedScopes
 		userSession.OIDCWorkflowSession.GrantedAudience = userSession.OIDCWorkflowSession.RequestedAudience
 
+		if client.ConsentMode == oidc.ClientConsentModePreConfigured {
+			if err := oidcConsentPreConfigure(ctx, client, userSession.Username, userSession.OIDCWorkflowSession); err != nil {
+				ctx.Error(fmt.Errorf("Unable to save the pre-configured consent of user %s for client %s: %v", userSession.Username, client.ID, err), "Operation failed")
+				return
+			}
+		}
+
 		if err := ctx.SaveSession(userSession); err != nil {
 			ctx.Error(fmt.Errorf("Unable to write session: %v", err), "Operation failed")
 			return
@@ -116,3 +126,18 @@ func oidcConsentPOST(ctx *middlewares.AutheliaCtx) {
 		ctx.Error(fmt.Errorf("Unable to set JSON body in response"), "Operation failed")
 	}
 }
+
+// oidcConsentPreConfigure remembers the consent the user has given to the client in the workflow so they are not
+// prompted again until the pre-configured consent duration of the client has elapsed.
+func oidcConsentPreConfigure(ctx *middlewares.AutheliaCtx, client *oidc.InternalClient, username string, workflow *session.OIDCWorkflowSession) error {
+	now := ctx.Clock.Now()
+
+	return ctx.Providers.StorageProvider.SaveOAuth2ConsentSession(models.OAuth2ConsentSession{
+		ClientID:        client.ID,
+		Subject:         username,
+		CreatedAt:       now,
+		ExpiresAt:       now.Add(client.PreConfiguredConsentDuration),
+		GrantedScopes:   workflow.GrantedScopes,
+		GrantedAudience: workflow.GrantedAudience,
+	})
+}
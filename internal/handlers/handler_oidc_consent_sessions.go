@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+// OIDCConsentSessionsGet lists the consents the current user has pre-configured for OpenID Connect clients.
+func OIDCConsentSessionsGet(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	consents, err := ctx.Providers.StorageProvider.LoadOAuth2ConsentSessionsBySubject(userSession.Username, ctx.Clock.Now())
+	if err != nil && err != storage.ErrNoOAuth2ConsentSession {
+		ctx.Error(fmt.Errorf("Unable to load OpenID Connect consents for user %s: %w", userSession.Username, err), operationFailedMessage)
+		return
+	}
+
+	response := make([]oidcConsentSessionResponse, len(consents))
+
+	for i, consent := range consents {
+		response[i] = oidcConsentSessionResponse{
+			ID:                consent.ID,
+			ClientID:          consent.ClientID,
+			ClientDescription: consent.ClientID,
+			Scopes:            consent.GrantedScopes,
+			Audience:          consent.GrantedAudience,
+			CreatedAt:         consent.CreatedAt,
+			ExpiresAt:         consent.ExpiresAt,
+		}
+
+		if client, err := ctx.Providers.OpenIDConnect.Store.GetInternalClient(consent.ClientID); err == nil {
+			response[i].ClientDescription = client.Description
+		}
+	}
+
+	if err = ctx.SetJSONBody(response); err != nil {
+		ctx.Logger.Errorf("Unable to set OpenID Connect consents response in body: %s", err)
+	}
+}
+
+// OIDCConsentSessionDelete revokes one of the consents the current user has pre-configured. The user is prompted for
+// consent again the next time the client requests authorization.
+func OIDCConsentSessionDelete(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	value, ok := ctx.UserValue("id").(string)
+	if !ok {
+		ctx.Error(errors.New("no consent id was provided"), operationFailedMessage)
+		return
+	}
+
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		ctx.Error(fmt.Errorf("the consent id '%s' is not a valid integer: %w", value, err), operationFailedMessage)
+		return
+	}
+
+	if err = ctx.Providers.StorageProvider.DeleteOAuth2ConsentSession(userSession.Username, id); err != nil {
+		ctx.Error(fmt.Errorf("Unable to revoke OpenID Connect consent %d of user %s: %w", id, userSession.Username, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Debugf("Revoked OpenID Connect consent %d of user %s", id, userSession.Username)
+
+	ctx.ReplyOK()
+}
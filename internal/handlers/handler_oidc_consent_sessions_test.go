@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/authorization"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/oidc"
+	"github.com/authelia/authelia/internal/session"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+type HandlerOIDCConsentSessionsSuite struct {
+	suite.Suite
+
+	mock *mocks.MockAutheliaCtx
+}
+
+func (s *HandlerOIDCConsentSessionsSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Ctx.Clock = &s.mock.Clock
+	userSession := s.mock.Ctx.GetSession()
+	userSession.Username = testUsername
+	userSession.AuthenticationLevel = authentication.TwoFactor
+	err := s.mock.Ctx.SaveSession(userSession)
+	require.NoError(s.T(), err)
+
+	store, err := oidc.NewOpenIDConnectStore(&schema.OpenIDConnectConfiguration{
+		Clients: []schema.OpenIDConnectClientConfiguration{
+			{ID: "explicit", Description: "Explicit App", Policy: "two_factor", ConsentMode: "explicit"},
+			{ID: "implicit", Description: "Implicit App", Policy: "two_factor", ConsentMode: "implicit"},
+			{ID: "remembered", Description: "Remembered App", Policy: "two_factor", ConsentMode: "pre-configured", PreConfiguredConsentDuration: time.Hour},
+		},
+	}, s.mock.StorageProviderMock)
+	require.NoError(s.T(), err)
+
+	s.mock.Ctx.Providers.OpenIDConnect.Store = store
+}
+
+func (s *HandlerOIDCConsentSessionsSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *HandlerOIDCConsentSessionsSuite) client(id string) *oidc.InternalClient {
+	client, err := s.mock.Ctx.Providers.OpenIDConnect.Store.GetInternalClient(id)
+	s.Require().NoError(err)
+
+	return client
+}
+
+func (s *HandlerOIDCConsentSessionsSuite) TestShouldListConsentSessions() {
+	s.mock.StorageProviderMock.EXPECT().
+		LoadOAuth2ConsentSessionsBySubject(gomock.Eq(testUsername), gomock.Eq(s.mock.Clock.Now())).
+		Return([]models.OAuth2ConsentSession{
+			{ID: 1, ClientID: "remembered", Subject: testUsername, CreatedAt: time.Unix(1577880001, 0).UTC(), ExpiresAt: time.Unix(1577883601, 0).UTC(), GrantedScopes: []string{"openid", "profile"}, GrantedAudience: []string{"remembered"}},
+			{ID: 2, ClientID: "removed", Subject: testUsername, CreatedAt: time.Unix(1577880001, 0).UTC(), ExpiresAt: time.Unix(1577883601, 0).UTC(), GrantedScopes: []string{"openid"}},
+		}, nil)
+
+	OIDCConsentSessionsGet(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), []oidcConsentSessionResponse{
+		{ID: 1, ClientID: "remembered", ClientDescription: "Remembered App", Scopes: []string{"openid", "profile"}, Audience: []string{"remembered"}, CreatedAt: time.Unix(1577880001, 0).UTC(), ExpiresAt: time.Unix(1577883601, 0).UTC()},
+		{ID: 2, ClientID: "removed", ClientDescription: "removed", Scopes: []string{"openid"}, CreatedAt: time.Unix(1577880001, 0).UTC(), ExpiresAt: time.Unix(1577883601, 0).UTC()},
+	})
+}
+
+func (s *HandlerOIDCConsentSessionsSuite) TestShouldListNoConsentSessions() {
+	s.mock.StorageProviderMock.EXPECT().
+		LoadOAuth2ConsentSessionsBySubject(gomock.Eq(testUsername), gomock.Any()).
+		Return(nil, storage.ErrNoOAuth2ConsentSession)
+
+	OIDCConsentSessionsGet(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), []oidcConsentSessionResponse{})
+}
+
+func (s *HandlerOIDCConsentSessionsSuite) TestShouldRevokeConsentSession() {
+	s.mock.Ctx.SetUserValue("id", "1")
+
+	s.mock.StorageProviderMock.EXPECT().
+		DeleteOAuth2ConsentSession(gomock.Eq(testUsername), gomock.Eq(1)).
+		Return(nil)
+
+	OIDCConsentSessionDelete(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+}
+
+func (s *HandlerOIDCConsentSessionsSuite) TestShouldFailToRevokeConsentSessionOfAnotherUser() {
+	s.mock.Ctx.SetUserValue("id", "2")
+
+	s.mock.StorageProviderMock.EXPECT().
+		DeleteOAuth2ConsentSession(gomock.Eq(testUsername), gomock.Eq(2)).
+		Return(storage.ErrNoOAuth2ConsentSession)
+
+	OIDCConsentSessionDelete(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+	s.Assert().Equal("Unable to revoke OpenID Connect consent 2 of user john: No OAuth 2.0 consent session found", s.mock.Hook.LastEntry().Message)
+}
+
+func (s *HandlerOIDCConsentSessionsSuite) TestShouldFailToRevokeConsentSessionWithInvalidID() {
+	s.mock.Ctx.SetUserValue("id", "abc")
+
+	OIDCConsentSessionDelete(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+}
+
+func (s *HandlerOIDCConsentSessionsSuite) TestShouldNotRequireConsentForImplicitClient() {
+	s.Assert().False(isOIDCConsentRequired(s.mock.Ctx, s.client("implicit"), nil, testUsername, []string{"openid"}, nil))
+}
+
+func (s *HandlerOIDCConsentSessionsSuite) TestShouldRequireConsentForExplicitClient() {
+	s.Assert().True(isOIDCConsentRequired(s.mock.Ctx, s.client("explicit"), nil, testUsername, []string{"openid"}, nil))
+}
+
+func (s *HandlerOIDCConsentSessionsSuite) TestShouldUsePreConfiguredConsent() {
+	s.mock.StorageProviderMock.EXPECT().
+		LoadOAuth2ConsentSessionsBySubject(gomock.Eq(testUsername), gomock.Eq(s.mock.Clock.Now())).
+		Return([]models.OAuth2ConsentSession{
+			{ID: 1, ClientID: "explicit", GrantedScopes: []string{"openid", "profile"}},
+			{ID: 2, ClientID: "remembered", GrantedScopes: []string{"openid"}},
+		}, nil).
+		Times(2)
+
+	s.Assert().False(isOIDCConsentRequired(s.mock.Ctx, s.client("remembered"), nil, testUsername, []string{"openid"}, nil))
+	s.Assert().True(isOIDCConsentRequired(s.mock.Ctx, s.client("remembered"), nil, testUsername, []string{"openid", "profile"}, nil))
+}
+
+func (s *HandlerOIDCConsentSessionsSuite) TestShouldSavePreConfiguredConsentOnAccept() {
+	userSession := s.mock.Ctx.GetSession()
+	userSession.OIDCWorkflowSession = &session.OIDCWorkflowSession{
+		ClientID:                   "remembered",
+		RequestedScopes:            []string{"openid", "profile"},
+		RequestedAudience:          []string{"remembered"},
+		AuthURI:                    "https://auth.example.com/api/oidc/authorize?client_id=remembered",
+		TargetURI:                  "https://app.example.com/callback",
+		RequiredAuthorizationLevel: authorization.TwoFactor,
+	}
+	s.Require().NoError(s.mock.Ctx.SaveSession(userSession))
+
+	s.mock.StorageProviderMock.EXPECT().
+		SaveOAuth2ConsentSession(gomock.Eq(models.OAuth2ConsentSession{
+			ClientID:        "remembered",
+			Subject:         testUsername,
+			CreatedAt:       s.mock.Clock.Now(),
+			ExpiresAt:       s.mock.Clock.Now().Add(time.Hour),
+			GrantedScopes:   []string{"openid", "profile"},
+			GrantedAudience: []string{"remembered"},
+		})).
+		Return(nil)
+
+	s.mock.Ctx.Request.SetBodyString(`{"client_id":"remembered","accept_or_reject":"accept"}`)
+
+	oidcConsentPOST(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), ConsentPostResponseBody{RedirectURI: "https://auth.example.com/api/oidc/authorize?client_id=remembered"})
+}
+
+func TestRunHandlerOIDCConsentSessionsSuite(t *testing.T) {
+	suite.Run(t, new(HandlerOIDCConsentSessionsSuite))
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ory/fosite/handler/openid"
+	"github.com/ory/fosite/token/jwt"
+
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/oidc"
+	"github.com/authelia/authelia/internal/session"
+)
+
+// OIDCDeviceGet returns the client and the permissions requested by the device authorization request of a user code.
+func OIDCDeviceGet(ctx *middlewares.AutheliaCtx) {
+	userCode := string(ctx.QueryArgs().Peek("user_code"))
+
+	deviceSession, client, err := oidcLoadPendingDeviceCodeSession(ctx, userCode)
+	if err != nil {
+		ctx.Error(err, invalidUserCodeMessage)
+		return
+	}
+
+	body := client.GetDeviceResponseBody(*deviceSession, ctx.GetSession().AuthenticationLevel)
+	body.UserCode = userCode
+
+	if err = ctx.SetJSONBody(body); err != nil {
+		ctx.Error(fmt.Errorf("Unable to set JSON body: %v", err), operationFailedMessage)
+	}
+}
+
+// OIDCDevicePost approves or denies the device authorization request of a user code on behalf of the user.
+func OIDCDevicePost(ctx *middlewares.AutheliaCtx) {
+	var body DevicePostRequestBody
+
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil {
+		ctx.Error(fmt.Errorf("Unable to unmarshal body: %v", err), operationFailedMessage)
+		return
+	}
+
+	userSession := ctx.GetSession()
+
+	if body.AcceptOrReject != accept && body.AcceptOrReject != reject {
+		ctx.Logger.Infof("User %s tried to reply to a device authorization request with an unexpected verb", userSession.Username)
+		ctx.ReplyBadRequest()
+
+		return
+	}
+
+	deviceSession, client, err := oidcLoadPendingDeviceCodeSession(ctx, body.UserCode)
+	if err != nil {
+		ctx.Error(err, invalidUserCodeMessage)
+		return
+	}
+
+	if !client.IsAuthenticationLevelSufficient(userSession.AuthenticationLevel) {
+		ctx.Logger.Debugf("Insufficient permissions to approve the device authorization request of client %s: %d -> %d",
+			client.ID, userSession.AuthenticationLevel, client.Policy)
+		ctx.ReplyForbidden()
+
+		return
+	}
+
+	deviceSession.Subject = userSession.Username
+
+	if body.AcceptOrReject == accept {
+		if err = oidcApproveDeviceCodeSession(ctx, client, deviceSession, &userSession); err != nil {
+			ctx.Error(fmt.Errorf("Unable to approve the device authorization request of client %s for user %s: %v", client.ID, userSession.Username, err), operationFailedMessage)
+			return
+		}
+	} else {
+		deviceSession.Status = models.OAuth2DeviceCodeStatusDenied
+	}
+
+	if err = ctx.Providers.OpenIDConnect.Store.UpdateDeviceCodeSession(*deviceSession); err != nil {
+		ctx.Error(fmt.Errorf("Unable to save the device authorization request of client %s for user %s: %v", client.ID, userSession.Username, err), operationFailedMessage)
+		return
+	}
+
+	ctx.ReplyOK()
+}
+
+// oidcLoadPendingDeviceCodeSession loads the device authorization request of the user code and its client, and ensures
+// the user can still approve or deny it.
+func oidcLoadPendingDeviceCodeSession(ctx *middlewares.AutheliaCtx, userCode string) (deviceSession *models.OAuth2DeviceCodeSession, client *oidc.InternalClient, err error) {
+	if userCode == "" {
+		return nil, nil, fmt.Errorf("the user code is missing")
+	}
+
+	if deviceSession, err = ctx.Providers.OpenIDConnect.Store.GetDeviceCodeSessionByUserCode(userCode); err != nil {
+		return nil, nil, fmt.Errorf("unable to load the device authorization request: %w", err)
+	}
+
+	switch {
+	case deviceSession.Status != models.OAuth2DeviceCodeStatusPending:
+		return nil, nil, fmt.Errorf("the device authorization request of client %s has already been answered", deviceSession.ClientID)
+	case ctx.Clock.Now().After(deviceSession.ExpiresAt):
+		return nil, nil, fmt.Errorf("the device authorization request of client %s has expired", deviceSession.ClientID)
+	}
+
+	if client, err = ctx.Providers.OpenIDConnect.Store.GetInternalClient(deviceSession.ClientID); err != nil {
+		return nil, nil, fmt.Errorf("unable to find related client configuration with name '%s': %w", deviceSession.ClientID, err)
+	}
+
+	return deviceSession, client, nil
+}
+
+// oidcApproveDeviceCodeSession grants the requested scopes and audience to the device authorization request and
+// stores the session of the user which the tokens are issued for when the device exchanges its device code.
+func oidcApproveDeviceCodeSession(ctx *middlewares.AutheliaCtx, client *oidc.InternalClient, deviceSession *models.OAuth2DeviceCodeSession, userSession *session.UserSession) (err error) {
+	issuer, err := ctx.ForwardedProtoHost()
+	if err != nil {
+		return fmt.Errorf("unable to obtain the issuer: %w", err)
+	}
+
+	authTime, err := userSession.AuthenticatedTime(client.Policy)
+	if err != nil {
+		return fmt.Errorf("unable to obtain the authentication timestamp: %w", err)
+	}
+
+	deviceSession.GrantedScopes = deviceSession.RequestedScopes
+	deviceSession.GrantedAudience = oidcGrantedAudience(client.ID, deviceSession.RequestedAudience)
+
+	extraClaims, err := oidcExtraClaims(ctx, client, deviceSession.GrantedScopes, userSession)
+	if err != nil {
+		return fmt.Errorf("unable to obtain the claims: %w", err)
+	}
+
+	oidcSession := &oidc.OpenIDSession{
+		DefaultSession: &openid.DefaultSession{
+			Claims: &jwt.IDTokenClaims{
+				Subject:     userSession.Username,
+				Issuer:      issuer,
+				AuthTime:    authTime,
+				RequestedAt: deviceSession.RequestedAt,
+				IssuedAt:    time.Now(),
+				Audience:    deviceSession.GrantedAudience,
+				Extra:       extraClaims,
+			},
+			Headers: &jwt.Headers{Extra: map[string]interface{}{
+				"kid": ctx.Providers.OpenIDConnect.KeyManager.GetActiveKeyID(client.IDTokenSigningAlgorithm),
+			}},
+			Subject: userSession.Username,
+		},
+		Extra:    map[string]interface{}{},
+		ClientID: client.ID,
+	}
+
+	if deviceSession.Session, err = json.Marshal(oidcSession); err != nil {
+		return fmt.Errorf("unable to marshal the session: %w", err)
+	}
+
+	deviceSession.Status = models.OAuth2DeviceCodeStatusApproved
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/authelia/authelia/internal/middlewares"
+)
+
+func oidcDeviceAuthorization(ctx *middlewares.AutheliaCtx, rw http.ResponseWriter, req *http.Request) {
+	issuer, err := ctx.ForwardedProtoHost()
+	if err != nil {
+		ctx.Logger.Errorf("Error occurred obtaining issuer: %+v", err)
+		ctx.Providers.OpenIDConnect.WriteDeviceAuthorizeError(rw, err)
+
+		return
+	}
+
+	verificationURI := fmt.Sprintf("%s%s", issuer, oidcDeviceVerificationPath)
+
+	response, err := ctx.Providers.OpenIDConnect.NewDeviceAuthorizeResponse(ctx, req, verificationURI)
+	if err != nil {
+		ctx.Logger.Errorf("Error occurred in NewDeviceAuthorizeResponse: %+v", err)
+		ctx.Providers.OpenIDConnect.WriteDeviceAuthorizeError(rw, err)
+
+		return
+	}
+
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.Header().Set("Pragma", "no-cache")
+
+	ctx.Providers.OpenIDConnect.Write(rw, req, response)
+}
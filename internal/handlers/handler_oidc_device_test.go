@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/oidc"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+type HandlerOIDCDeviceSuite struct {
+	suite.Suite
+
+	mock *mocks.MockAutheliaCtx
+}
+
+func (s *HandlerOIDCDeviceSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Ctx.Clock = &s.mock.Clock
+	userSession := s.mock.Ctx.GetSession()
+	userSession.Username = testUsername
+	userSession.DisplayName = "John Doe"
+	userSession.AuthenticationLevel = authentication.OneFactor
+	userSession.FirstFactorAuthnTimestamp = s.mock.Clock.Now().Unix()
+	err := s.mock.Ctx.SaveSession(userSession)
+	require.NoError(s.T(), err)
+
+	s.mock.Ctx.Request.Header.Set("X-Forwarded-Proto", "https")
+	s.mock.Ctx.Request.Header.Set("X-Forwarded-Host", "auth.example.com")
+
+	store, err := oidc.NewOpenIDConnectStore(&schema.OpenIDConnectConfiguration{
+		Clients: []schema.OpenIDConnectClientConfiguration{
+			{ID: "tv", Description: "Smart TV", Policy: "one_factor", GrantTypes: []string{oidc.GrantTypeDeviceCode}},
+			{ID: "bot", Description: "CI Bot", Policy: "two_factor", GrantTypes: []string{oidc.GrantTypeDeviceCode}},
+		},
+	}, s.mock.StorageProviderMock)
+	require.NoError(s.T(), err)
+
+	s.mock.Ctx.Providers.OpenIDConnect.Store = store
+	s.mock.Ctx.Providers.OpenIDConnect.KeyManager = oidc.NewKeyManager()
+}
+
+func (s *HandlerOIDCDeviceSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *HandlerOIDCDeviceSuite) expectDeviceCodeSession(clientID string, status models.OAuth2DeviceCodeStatus, expiresAt time.Time) {
+	s.mock.StorageProviderMock.EXPECT().
+		LoadOAuth2DeviceCodeSessionByUserCode(gomock.Eq(utils.HashSHA256FromString("BCDFGHJK"))).
+		Return(&models.OAuth2DeviceCodeSession{
+			ID:                1,
+			ClientID:          clientID,
+			Status:            status,
+			RequestedAt:       s.mock.Clock.Now(),
+			ExpiresAt:         expiresAt,
+			RequestedScopes:   []string{"openid", "profile"},
+			RequestedAudience: []string{},
+		}, nil)
+}
+
+func (s *HandlerOIDCDeviceSuite) TestShouldReturnDeviceAuthorizationRequest() {
+	s.expectDeviceCodeSession("bot", models.OAuth2DeviceCodeStatusPending, s.mock.Clock.Now().Add(time.Minute))
+
+	s.mock.Ctx.QueryArgs().Set("user_code", "bcdf-ghjk")
+
+	OIDCDeviceGet(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), oidc.DeviceGetResponseBody{
+		ConsentGetResponseBody: oidc.ConsentGetResponseBody{
+			ClientID:          "bot",
+			ClientDescription: "CI Bot",
+			Scopes: []oidc.Scope{
+				{Name: "openid", Description: "Use OpenID to verify your identity"},
+				{Name: "profile", Description: "Access your display name"},
+			},
+		},
+		UserCode:             "bcdf-ghjk",
+		SecondFactorRequired: true,
+	})
+}
+
+func (s *HandlerOIDCDeviceSuite) TestShouldFailToReturnExpiredDeviceAuthorizationRequest() {
+	s.expectDeviceCodeSession("tv", models.OAuth2DeviceCodeStatusPending, s.mock.Clock.Now().Add(-time.Minute))
+
+	s.mock.Ctx.QueryArgs().Set("user_code", "BCDF-GHJK")
+
+	OIDCDeviceGet(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), invalidUserCodeMessage)
+	s.Assert().Equal("the device authorization request of client tv has expired", s.mock.Hook.LastEntry().Message)
+}
+
+func (s *HandlerOIDCDeviceSuite) TestShouldFailToReturnAnsweredDeviceAuthorizationRequest() {
+	s.expectDeviceCodeSession("tv", models.OAuth2DeviceCodeStatusApproved, s.mock.Clock.Now().Add(time.Minute))
+
+	s.mock.Ctx.QueryArgs().Set("user_code", "BCDF-GHJK")
+
+	OIDCDeviceGet(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), invalidUserCodeMessage)
+}
+
+func (s *HandlerOIDCDeviceSuite) TestShouldApproveDeviceAuthorizationRequest() {
+	s.expectDeviceCodeSession("tv", models.OAuth2DeviceCodeStatusPending, s.mock.Clock.Now().Add(time.Minute))
+
+	s.mock.StorageProviderMock.EXPECT().
+		UpdateOAuth2DeviceCodeSession(gomock.Any()).
+		DoAndReturn(func(session models.OAuth2DeviceCodeSession) error {
+			s.Assert().Equal(models.OAuth2DeviceCodeStatusApproved, session.Status)
+			s.Assert().Equal(testUsername, session.Subject)
+			s.Assert().Equal([]string{"openid", "profile"}, session.GrantedScopes)
+			s.Assert().Equal([]string{"tv"}, session.GrantedAudience)
+
+			oidcSession := oidc.NewSession()
+			s.Require().NoError(json.Unmarshal(session.Session, oidcSession))
+			s.Assert().Equal(testUsername, oidcSession.Subject)
+			s.Assert().Equal("https://auth.example.com", oidcSession.Claims.Issuer)
+			s.Assert().Equal("John Doe", oidcSession.Claims.Extra["name"])
+			s.Assert().Equal("tv", oidcSession.ClientID)
+
+			return nil
+		})
+
+	s.mock.Ctx.Request.SetBodyString(`{"user_code":"BCDF-GHJK","accept_or_reject":"accept"}`)
+
+	OIDCDevicePost(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+}
+
+func (s *HandlerOIDCDeviceSuite) TestShouldDenyDeviceAuthorizationRequest() {
+	s.expectDeviceCodeSession("tv", models.OAuth2DeviceCodeStatusPending, s.mock.Clock.Now().Add(time.Minute))
+
+	s.mock.StorageProviderMock.EXPECT().
+		UpdateOAuth2DeviceCodeSession(gomock.Any()).
+		DoAndReturn(func(session models.OAuth2DeviceCodeSession) error {
+			s.Assert().Equal(models.OAuth2DeviceCodeStatusDenied, session.Status)
+			s.Assert().Equal(testUsername, session.Subject)
+			s.Assert().Nil(session.Session)
+
+			return nil
+		})
+
+	s.mock.Ctx.Request.SetBodyString(`{"user_code":"BCDF-GHJK","accept_or_reject":"reject"}`)
+
+	OIDCDevicePost(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+}
+
+func (s *HandlerOIDCDeviceSuite) TestShouldForbidApprovalWithInsufficientAuthenticationLevel() {
+	s.expectDeviceCodeSession("bot", models.OAuth2DeviceCodeStatusPending, s.mock.Clock.Now().Add(time.Minute))
+
+	s.mock.Ctx.Request.SetBodyString(`{"user_code":"BCDF-GHJK","accept_or_reject":"accept"}`)
+
+	OIDCDevicePost(s.mock.Ctx)
+
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *HandlerOIDCDeviceSuite) TestShouldRejectUnexpectedVerb() {
+	s.mock.Ctx.Request.SetBodyString(`{"user_code":"BCDF-GHJK","accept_or_reject":"maybe"}`)
+
+	OIDCDevicePost(s.mock.Ctx)
+
+	s.Assert().Equal(400, s.mock.Ctx.Response.StatusCode())
+}
+
+func TestRunHandlerOIDCDeviceSuite(t *testing.T) {
+	suite.Run(t, new(HandlerOIDCDeviceSuite))
+}
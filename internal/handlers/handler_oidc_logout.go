@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/oidc"
+	"github.com/authelia/authelia/internal/session"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+var oidcFrontChannelLogoutTemplate = template.Must(template.New("logout").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Logout</title>
+</head>
+<body>
+{{- range .LogoutURIs }}
+<iframe src="{{ . }}" hidden></iframe>
+{{- end }}
+<noscript><a href="{{ .RedirectURI }}">Continue</a></noscript>
+<script nonce="{{ .Nonce }}">window.addEventListener("load", function () { window.location.replace({{ .RedirectURI }}); });</script>
+</body>
+</html>
+`))
+
+type oidcFrontChannelLogoutData struct {
+	LogoutURIs  []string
+	RedirectURI string
+	Nonce       string
+}
+
+// oidcEndSession implements the RP-Initiated Logout, see https://openid.net/specs/openid-connect-rpinitiated-1_0.html.
+// The session of the user is destroyed, the clients the user signed in to during the session are notified and the
+// user is redirected to the post logout redirect URI of the client if it's registered.
+func oidcEndSession(ctx *middlewares.AutheliaCtx) {
+	args := ctx.QueryArgs()
+
+	if ctx.IsPost() {
+		args = ctx.PostArgs()
+	}
+
+	userSession := ctx.GetSession()
+
+	client, err := oidcEndSessionClient(ctx, userSession, string(args.Peek("id_token_hint")), string(args.Peek("client_id")))
+	if err != nil {
+		ctx.Logger.Errorf("Unable to process the logout request of user %s: %v", userSession.Username, err)
+		oidcWriteError(ctx, fasthttp.StatusBadRequest, "invalid_request", err.Error())
+
+		return
+	}
+
+	issuer, err := ctx.ForwardedProtoHost()
+	if err != nil {
+		ctx.Logger.Errorf("Error occurred obtaining issuer: %+v", err)
+		ctx.Response.SetStatusCode(fasthttp.StatusBadRequest)
+
+		return
+	}
+
+	redirectURI := fmt.Sprintf("%s/", issuer)
+
+	if postLogoutRedirectURI := string(args.Peek("post_logout_redirect_uri")); postLogoutRedirectURI != "" {
+		if redirectURI, err = oidcPostLogoutRedirectURI(client, postLogoutRedirectURI, string(args.Peek("state"))); err != nil {
+			ctx.Logger.Errorf("Unable to process the logout request of user %s: %v", userSession.Username, err)
+			oidcWriteError(ctx, fasthttp.StatusBadRequest, "invalid_request", err.Error())
+
+			return
+		}
+	}
+
+	frontChannelLogoutURIs := oidcLogout(ctx, userSession)
+
+	if userSession.Username != "" {
+		if err = ctx.Providers.SessionProvider.DestroySession(ctx.RequestCtx); err != nil {
+			ctx.Logger.Errorf("Unable to destroy session during logout: %s", err)
+			ctx.Response.SetStatusCode(fasthttp.StatusInternalServerError)
+
+			return
+		}
+
+		ctx.Logger.Debugf("User %s was logged out by OpenID Connect client %s", userSession.Username, oidcClientID(client))
+	}
+
+	if len(frontChannelLogoutURIs) == 0 {
+		ctx.Redirect(redirectURI, fasthttp.StatusFound)
+		return
+	}
+
+	oidcWriteFrontChannelLogout(ctx, frontChannelLogoutURIs, redirectURI)
+}
+
+// oidcEndSessionClient returns the client which requested the logout, it's identified by the audience of the ID token
+// hint or by the client_id parameter. The client is nil if neither is provided.
+func oidcEndSessionClient(ctx *middlewares.AutheliaCtx, userSession session.UserSession, idTokenHint, clientID string) (client *oidc.InternalClient, err error) {
+	if idTokenHint != "" {
+		claims, err := ctx.Providers.OpenIDConnect.DecodeIDTokenHint(ctx, idTokenHint)
+		if err != nil {
+			return nil, fmt.Errorf("the ID token hint is invalid: %w", err)
+		}
+
+		if subject, _ := claims["sub"].(string); userSession.Username != "" && subject != userSession.Username {
+			return nil, errors.New("the ID token hint was not issued to the current user")
+		}
+
+		audience := oidcAudienceClaim(claims["aud"])
+
+		switch {
+		case clientID == "" && len(audience) == 1:
+			clientID = audience[0]
+		case clientID != "" && !utils.IsStringInSlice(clientID, audience):
+			return nil, fmt.Errorf("the ID token hint was not issued to the client %s", clientID)
+		}
+	}
+
+	if clientID == "" {
+		return nil, nil
+	}
+
+	if client, err = ctx.Providers.OpenIDConnect.Store.GetInternalClient(clientID); err != nil {
+		return nil, fmt.Errorf("the client %s doesn't exist", clientID)
+	}
+
+	return client, nil
+}
+
+// oidcPostLogoutRedirectURI returns the post logout redirect URI along with the state if it's registered for the client.
+func oidcPostLogoutRedirectURI(client *oidc.InternalClient, postLogoutRedirectURI, state string) (redirectURI string, err error) {
+	if client == nil {
+		return "", errors.New("the post logout redirect URI requires either the ID token hint or the client ID")
+	}
+
+	if !utils.IsStringInSlice(postLogoutRedirectURI, client.PostLogoutRedirectURIs) {
+		return "", fmt.Errorf("the post logout redirect URI %s is not registered for the client %s", postLogoutRedirectURI, client.ID)
+	}
+
+	if state == "" {
+		return postLogoutRedirectURI, nil
+	}
+
+	parsedURI, err := url.Parse(postLogoutRedirectURI)
+	if err != nil {
+		return "", err
+	}
+
+	query := parsedURI.Query()
+	query.Set("state", state)
+	parsedURI.RawQuery = query.Encode()
+
+	return parsedURI.String(), nil
+}
+
+// oidcLogout notifies the clients the user signed in to during the session that the session is being destroyed. The
+// clients with a back-channel logout URI are notified in the background, and the front-channel logout URIs of the
+// clients are returned so they can be loaded by the browser of the user.
+func oidcLogout(ctx *middlewares.AutheliaCtx, userSession session.UserSession) (frontChannelLogoutURIs []string) {
+	logoutSession := userSession.OIDCLogoutSession
+
+	if logoutSession == nil || ctx.Providers.OpenIDConnect.Store == nil {
+		return nil
+	}
+
+	go ctx.Providers.OpenIDConnect.BackChannelLogout(context.Background(), userSession.Username, logoutSession, ctx.Clock.Now())
+
+	for _, id := range logoutSession.ClientIDs {
+		client, err := ctx.Providers.OpenIDConnect.Store.GetInternalClient(id)
+		if err != nil || client.FrontChannelLogoutURI == "" {
+			continue
+		}
+
+		logoutURI, err := url.Parse(client.FrontChannelLogoutURI)
+		if err != nil {
+			continue
+		}
+
+		query := logoutURI.Query()
+		query.Set("iss", logoutSession.Issuer)
+		query.Set("sid", logoutSession.ID)
+		logoutURI.RawQuery = query.Encode()
+
+		frontChannelLogoutURIs = append(frontChannelLogoutURIs, logoutURI.String())
+	}
+
+	return frontChannelLogoutURIs
+}
+
+// oidcAddLogoutClient records that the client was issued an ID token during the session of the user so it's notified
+// when the session is destroyed, and returns the sid claim of the session.
+func oidcAddLogoutClient(userSession *session.UserSession, issuer, clientID string) (sid string) {
+	if userSession.OIDCLogoutSession == nil {
+		userSession.OIDCLogoutSession = &session.OIDCLogoutSession{
+			ID:     uuid.New().String(),
+			Issuer: issuer,
+		}
+	}
+
+	if !utils.IsStringInSlice(clientID, userSession.OIDCLogoutSession.ClientIDs) {
+		userSession.OIDCLogoutSession.ClientIDs = append(userSession.OIDCLogoutSession.ClientIDs, clientID)
+	}
+
+	return userSession.OIDCLogoutSession.ID
+}
+
+// oidcWriteFrontChannelLogout renders a page which loads the front-channel logout URIs of the clients in hidden
+// iframes and then redirects the user to the redirect URI.
+func oidcWriteFrontChannelLogout(ctx *middlewares.AutheliaCtx, frontChannelLogoutURIs []string, redirectURI string) {
+	nonce := utils.RandomString(32, utils.AlphaNumericCharacters)
+
+	var origins []string
+
+	for _, logoutURI := range frontChannelLogoutURIs {
+		if parsedURI, err := url.Parse(logoutURI); err == nil {
+			origins = append(origins, fmt.Sprintf("%s://%s", parsedURI.Scheme, parsedURI.Host))
+		}
+	}
+
+	ctx.SetContentType("text/html; charset=utf-8")
+	ctx.Response.Header.Set("Cache-Control", "no-store")
+	ctx.Response.Header.Set("Content-Security-Policy",
+		fmt.Sprintf("default-src 'none'; frame-src %s; script-src 'nonce-%s'", strings.Join(origins, " "), nonce))
+
+	err := oidcFrontChannelLogoutTemplate.Execute(ctx, oidcFrontChannelLogoutData{
+		LogoutURIs:  frontChannelLogoutURIs,
+		RedirectURI: redirectURI,
+		Nonce:       nonce,
+	})
+	if err != nil {
+		ctx.Logger.Errorf("Unable to execute front-channel logout template: %v", err)
+		ctx.Response.SetStatusCode(fasthttp.StatusInternalServerError)
+	}
+}
+
+// oidcAudienceClaim returns the aud claim of a decoded token which is either a string or an array of strings.
+func oidcAudienceClaim(claim interface{}) (audience []string) {
+	switch value := claim.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				audience = append(audience, s)
+			}
+		}
+	}
+
+	return audience
+}
+
+func oidcClientID(client *oidc.InternalClient) string {
+	if client == nil {
+		return "<unknown>"
+	}
+
+	return client.ID
+}
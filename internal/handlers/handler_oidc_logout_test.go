@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite/token/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/oidc"
+	"github.com/authelia/authelia/internal/session"
+)
+
+type HandlerOIDCLogoutSuite struct {
+	suite.Suite
+
+	mock *mocks.MockAutheliaCtx
+}
+
+func (s *HandlerOIDCLogoutSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Ctx.Clock = &s.mock.Clock
+	s.mock.Ctx.Request.Header.Set("X-Forwarded-Proto", "https")
+	s.mock.Ctx.Request.Header.Set("X-Forwarded-Host", "auth.example.com")
+
+	userSession := s.mock.Ctx.GetSession()
+	userSession.Username = testUsername
+	userSession.AuthenticationLevel = authentication.TwoFactor
+	err := s.mock.Ctx.SaveSession(userSession)
+	require.NoError(s.T(), err)
+
+	store, err := oidc.NewOpenIDConnectStore(&schema.OpenIDConnectConfiguration{
+		Clients: []schema.OpenIDConnectClientConfiguration{
+			{
+				ID:                     "app",
+				Policy:                 "two_factor",
+				PostLogoutRedirectURIs: []string{"https://app.example.com/logged-out"},
+				FrontChannelLogoutURI:  "https://app.example.com/frontchannel",
+			},
+			{
+				ID:     "other",
+				Policy: "two_factor",
+			},
+		},
+	}, s.mock.StorageProviderMock)
+	require.NoError(s.T(), err)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(s.T(), err)
+
+	keyManager := oidc.NewKeyManager()
+	_, err = keyManager.AddPrivateKey("", oidc.SigningAlgorithmRSAWithSHA256, key, time.Time{})
+	require.NoError(s.T(), err)
+
+	s.mock.Ctx.Providers.OpenIDConnect.Store = store
+	s.mock.Ctx.Providers.OpenIDConnect.KeyManager = keyManager
+}
+
+func (s *HandlerOIDCLogoutSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *HandlerOIDCLogoutSuite) idToken(subject string, audience ...string) string {
+	token, _, err := s.mock.Ctx.Providers.OpenIDConnect.KeyManager.Strategy().Generate(context.Background(), jwt.MapClaims{
+		"iss": "https://auth.example.com",
+		"sub": subject,
+		"aud": audience,
+		"exp": time.Now().Add(-time.Minute).Unix(),
+	}, &jwt.Headers{})
+	s.Require().NoError(err)
+
+	return token
+}
+
+func (s *HandlerOIDCLogoutSuite) assertInvalidRequest(description string) {
+	s.Assert().Equal(fasthttp.StatusBadRequest, s.mock.Ctx.Response.StatusCode())
+
+	var body oidcErrorResponseBody
+
+	s.Require().NoError(json.Unmarshal(s.mock.Ctx.Response.Body(), &body))
+	s.Assert().Equal("invalid_request", body.Error)
+	s.Assert().Equal(description, body.ErrorDescription)
+	s.Assert().Equal(testUsername, s.mock.Ctx.GetSession().Username)
+}
+
+func (s *HandlerOIDCLogoutSuite) TestShouldLogoutAndRedirectToPortal() {
+	oidcEndSession(s.mock.Ctx)
+
+	s.Assert().Equal(fasthttp.StatusFound, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("https://auth.example.com/", string(s.mock.Ctx.Response.Header.Peek("Location")))
+	s.Assert().Equal("", s.mock.Ctx.GetSession().Username)
+}
+
+func (s *HandlerOIDCLogoutSuite) TestShouldRedirectToRegisteredPostLogoutRedirectURIWithState() {
+	s.mock.Ctx.Request.SetRequestURI("/api/oidc/logout")
+	s.mock.Ctx.QueryArgs().Set("id_token_hint", s.idToken(testUsername, "app"))
+	s.mock.Ctx.QueryArgs().Set("post_logout_redirect_uri", "https://app.example.com/logged-out")
+	s.mock.Ctx.QueryArgs().Set("state", "abc123")
+
+	oidcEndSession(s.mock.Ctx)
+
+	s.Assert().Equal(fasthttp.StatusFound, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("https://app.example.com/logged-out?state=abc123", string(s.mock.Ctx.Response.Header.Peek("Location")))
+	s.Assert().Equal("", s.mock.Ctx.GetSession().Username)
+}
+
+func (s *HandlerOIDCLogoutSuite) TestShouldAcceptFormPost() {
+	s.mock.Ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+	s.mock.Ctx.Request.Header.SetContentType("application/x-www-form-urlencoded")
+	s.mock.Ctx.Request.SetBodyString("client_id=app&post_logout_redirect_uri=https%3A%2F%2Fapp.example.com%2Flogged-out")
+
+	oidcEndSession(s.mock.Ctx)
+
+	s.Assert().Equal(fasthttp.StatusFound, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("https://app.example.com/logged-out", string(s.mock.Ctx.Response.Header.Peek("Location")))
+}
+
+func (s *HandlerOIDCLogoutSuite) TestShouldRejectUnregisteredPostLogoutRedirectURI() {
+	s.mock.Ctx.QueryArgs().Set("client_id", "app")
+	s.mock.Ctx.QueryArgs().Set("post_logout_redirect_uri", "https://evil.example.com/")
+
+	oidcEndSession(s.mock.Ctx)
+
+	s.assertInvalidRequest("the post logout redirect URI https://evil.example.com/ is not registered for the client app")
+}
+
+func (s *HandlerOIDCLogoutSuite) TestShouldRejectPostLogoutRedirectURIWithoutClient() {
+	s.mock.Ctx.QueryArgs().Set("post_logout_redirect_uri", "https://app.example.com/logged-out")
+
+	oidcEndSession(s.mock.Ctx)
+
+	s.assertInvalidRequest("the post logout redirect URI requires either the ID token hint or the client ID")
+}
+
+func (s *HandlerOIDCLogoutSuite) TestShouldRejectIDTokenHintOfAnotherUser() {
+	s.mock.Ctx.QueryArgs().Set("id_token_hint", s.idToken("harry", "app"))
+
+	oidcEndSession(s.mock.Ctx)
+
+	s.assertInvalidRequest("the ID token hint was not issued to the current user")
+}
+
+func (s *HandlerOIDCLogoutSuite) TestShouldRejectIDTokenHintOfAnotherClient() {
+	s.mock.Ctx.QueryArgs().Set("id_token_hint", s.idToken(testUsername, "app"))
+	s.mock.Ctx.QueryArgs().Set("client_id", "other")
+
+	oidcEndSession(s.mock.Ctx)
+
+	s.assertInvalidRequest("the ID token hint was not issued to the client other")
+}
+
+func (s *HandlerOIDCLogoutSuite) TestShouldRenderFrontChannelLogout() {
+	userSession := s.mock.Ctx.GetSession()
+	userSession.OIDCLogoutSession = &session.OIDCLogoutSession{
+		ID:        "sid-1",
+		Issuer:    "https://auth.example.com",
+		ClientIDs: []string{"app", "other"},
+	}
+	s.Require().NoError(s.mock.Ctx.SaveSession(userSession))
+
+	oidcEndSession(s.mock.Ctx)
+
+	s.Assert().Equal(fasthttp.StatusOK, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("no-store", string(s.mock.Ctx.Response.Header.Peek("Cache-Control")))
+	s.Assert().Regexp(`^default-src 'none'; frame-src https://app.example.com; script-src 'nonce-[a-zA-Z0-9]{32}'$`,
+		string(s.mock.Ctx.Response.Header.Peek("Content-Security-Policy")))
+
+	body := string(s.mock.Ctx.Response.Body())
+
+	s.Assert().Contains(body, `<iframe src="https://app.example.com/frontchannel?iss=https%3A%2F%2Fauth.example.com&amp;sid=sid-1" hidden></iframe>`)
+	s.Assert().Contains(body, `window.location.replace("https://auth.example.com/")`)
+	s.Assert().Equal("", s.mock.Ctx.GetSession().Username)
+}
+
+func TestRunHandlerOIDCLogoutSuite(t *testing.T) {
+	suite.Run(t, new(HandlerOIDCLogoutSuite))
+}
+
+func TestShouldAddLogoutClientOnce(t *testing.T) {
+	userSession := session.UserSession{}
+
+	sid := oidcAddLogoutClient(&userSession, "https://auth.example.com", "app")
+	require.NotEmpty(t, sid)
+
+	assert.Equal(t, sid, oidcAddLogoutClient(&userSession, "https://auth.example.com", "app"))
+	assert.Equal(t, sid, oidcAddLogoutClient(&userSession, "https://auth.example.com", "other"))
+
+	assert.Equal(t, &session.OIDCLogoutSession{
+		ID:        sid,
+		Issuer:    "https://auth.example.com",
+		ClientIDs: []string{"app", "other"},
+	}, userSession.OIDCLogoutSession)
+}
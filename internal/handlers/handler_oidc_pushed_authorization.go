@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/authelia/authelia/internal/middlewares"
+)
+
+func oidcPushedAuthorization(ctx *middlewares.AutheliaCtx, rw http.ResponseWriter, req *http.Request) {
+	issuer, err := ctx.ForwardedProtoHost()
+	if err != nil {
+		ctx.Logger.Errorf("Error occurred obtaining issuer: %+v", err)
+		ctx.Providers.OpenIDConnect.WritePushedAuthorizeError(rw, err)
+
+		return
+	}
+
+	response, err := ctx.Providers.OpenIDConnect.NewPushedAuthorizeResponse(ctx, req, issuer)
+	if err != nil {
+		ctx.Logger.Errorf("Error occurred in NewPushedAuthorizeResponse: %+v", err)
+		ctx.Providers.OpenIDConnect.WritePushedAuthorizeError(rw, err)
+
+		return
+	}
+
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.Header().Set("Pragma", "no-cache")
+
+	ctx.Providers.OpenIDConnect.WriteCode(rw, req, http.StatusCreated, response)
+}
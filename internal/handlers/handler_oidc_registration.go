@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/oidc"
+)
+
+// OIDCRegistrationPost registers a new OpenID Connect client with the dynamic client registration protocol, see
+// RFC 7591. The request must be authorized with the configured initial access token and the clients always require
+// two factor authentication and the explicit consent of the user.
+func OIDCRegistrationPost(ctx *middlewares.AutheliaCtx) {
+	var token string
+
+	if ctx.Configuration.IdentityProviders.OIDC != nil {
+		token = ctx.Configuration.IdentityProviders.OIDC.ClientRegistration.InitialAccessToken
+	}
+
+	if token == "" {
+		ctx.Response.SetStatusCode(fasthttp.StatusNotFound)
+		return
+	}
+
+	header := []byte(fmt.Sprintf("Bearer %s", token))
+
+	if subtle.ConstantTimeCompare(ctx.Request.Header.Peek(AuthorizationHeader), header) != 1 {
+		ctx.Logger.Errorf("Unable to register OpenID Connect client from %s: the initial access token is invalid", ctx.RemoteIP())
+		ctx.Response.Header.Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		oidcWriteError(ctx, fasthttp.StatusUnauthorized, "invalid_token", "The initial access token is invalid.")
+
+		return
+	}
+
+	var body oidcRegistrationRequestBody
+
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil {
+		oidcWriteError(ctx, fasthttp.StatusBadRequest, "invalid_client_metadata", "The client metadata could not be parsed.")
+		return
+	}
+
+	switch body.TokenEndpointAuthMethod {
+	case "":
+		body.TokenEndpointAuthMethod = oidc.TokenEndpointAuthMethodClientSecretBasic
+	case oidc.TokenEndpointAuthMethodClientSecretBasic, oidc.TokenEndpointAuthMethodClientSecretPost:
+		break
+	default:
+		oidcWriteError(ctx, fasthttp.StatusBadRequest, "invalid_client_metadata",
+			fmt.Sprintf("The token endpoint authentication method '%s' is not supported.", body.TokenEndpointAuthMethod))
+
+		return
+	}
+
+	client, secret, err := oidcCreateClient(ctx, schema.OpenIDConnectClientConfiguration{
+		ID:            uuid.New().String(),
+		Description:   body.ClientName,
+		RedirectURIs:  body.RedirectURIs,
+		Scopes:        strings.Fields(body.Scope),
+		GrantTypes:    body.GrantTypes,
+		ResponseTypes: body.ResponseTypes,
+
+		IDTokenSigningAlgorithm:  body.IDTokenSignedResponseAlg,
+		UserinfoSigningAlgorithm: body.UserinfoSignedResponseAlg,
+
+		TokenEndpointAuthMethod: body.TokenEndpointAuthMethod,
+
+		RequirePushedAuthorizationRequests: body.RequirePushedAuthorization,
+
+		RequestObjectSigningAlgorithm: body.RequestObjectSigningAlg,
+		RequestURIs:                   body.RequestURIs,
+		JWKSURI:                       body.JWKSURI,
+
+		PostLogoutRedirectURIs: body.PostLogoutRedirectURIs,
+		FrontChannelLogoutURI:  body.FrontChannelLogoutURI,
+		BackChannelLogoutURI:   body.BackChannelLogoutURI,
+	})
+
+	switch {
+	case errors.Is(err, errInvalidClientMetadata):
+		ctx.Logger.Errorf("Unable to register OpenID Connect client from %s: %v", ctx.RemoteIP(), err)
+		oidcWriteError(ctx, fasthttp.StatusBadRequest, "invalid_client_metadata", err.Error())
+
+		return
+	case err != nil:
+		ctx.Logger.Errorf("Unable to register OpenID Connect client from %s: %v", ctx.RemoteIP(), err)
+		oidcWriteError(ctx, fasthttp.StatusInternalServerError, "server_error", "The client could not be registered.")
+
+		return
+	}
+
+	ctx.Logger.Infof("OpenID Connect client %s (%s) was registered dynamically from %s", client.ClientID, client.Description, ctx.RemoteIP())
+
+	oidcWriteJSON(ctx, fasthttp.StatusCreated, oidcRegistrationResponseBody{
+		ClientID:         client.ClientID,
+		ClientSecret:     secret,
+		ClientIDIssuedAt: client.CreatedAt.Unix(),
+
+		RedirectURIs:               client.RedirectURIs,
+		ClientName:                 client.Description,
+		GrantTypes:                 client.GrantTypes,
+		ResponseTypes:              client.ResponseTypes,
+		Scope:                      strings.Join(client.Scopes, " "),
+		TokenEndpointAuthMethod:    client.TokenEndpointAuthMethod,
+		IDTokenSignedResponseAlg:   client.IDTokenSigningAlgorithm,
+		UserinfoSignedResponseAlg:  client.UserinfoSigningAlgorithm,
+		RequestObjectSigningAlg:    client.RequestObjectSigningAlgorithm,
+		RequestURIs:                client.RequestURIs,
+		JWKSURI:                    client.JWKSURI,
+		RequirePushedAuthorization: client.RequirePushedAuthorizationRequests,
+		PostLogoutRedirectURIs:     client.PostLogoutRedirectURIs,
+		FrontChannelLogoutURI:      client.FrontChannelLogoutURI,
+		BackChannelLogoutURI:       client.BackChannelLogoutURI,
+	})
+}
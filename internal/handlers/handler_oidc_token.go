@@ -19,13 +19,22 @@ func oidcToken(ctx *middlewares.AutheliaCtx, rw http.ResponseWriter, req *http.R
 		return
 	}
 
-	// If this is a client_credentials grant, grant all scopes the client is allowed to perform.
+	// If this is a client_credentials grant, grant all scopes and audiences the client is allowed to perform. There is
+	// no user involved so the client is the subject of the tokens.
 	if accessRequest.GetGrantTypes().ExactOne("client_credentials") {
 		for _, scope := range accessRequest.GetRequestedScopes() {
 			if fosite.HierarchicScopeStrategy(accessRequest.GetClient().GetScopes(), scope) {
 				accessRequest.GrantScope(scope)
 			}
 		}
+
+		for _, audience := range accessRequest.GetRequestedAudience() {
+			accessRequest.GrantAudience(audience)
+		}
+
+		oidcSession.Subject = accessRequest.GetClient().GetID()
+		oidcSession.Claims.Subject = accessRequest.GetClient().GetID()
+		oidcSession.ClientID = accessRequest.GetClient().GetID()
 	}
 
 	response, err := ctx.Providers.OpenIDConnect.Fosite.NewAccessResponse(ctx, accessRequest)
@@ -57,13 +57,14 @@ func oidcUserinfo(ctx *middlewares.AutheliaCtx, rw http.ResponseWriter, req *htt
 	}
 
 	switch client.UserinfoSigningAlgorithm {
-	case "RS256":
+	case oidc.SigningAlgorithmRSAWithSHA256, oidc.SigningAlgorithmRSAPSSWithSHA256,
+		oidc.SigningAlgorithmECDSAWithP256AndSHA256, oidc.SigningAlgorithmECDSAWithP384AndSHA384:
 		claims["jti"] = uuid.New()
 		claims["iat"] = time.Now().Unix()
 
-		keyID, err := ctx.Providers.OpenIDConnect.KeyManager.Strategy().GetPublicKeyID(req.Context())
-		if err != nil {
-			ctx.Providers.OpenIDConnect.WriteError(rw, req, err)
+		keyID := ctx.Providers.OpenIDConnect.KeyManager.GetActiveKeyID(client.UserinfoSigningAlgorithm)
+		if keyID == "" {
+			ctx.Providers.OpenIDConnect.WriteError(rw, req, errors.WithStack(fosite.ErrServerError.WithHintf("No active key for the userinfo signing algorithm '%s'.", client.UserinfoSigningAlgorithm)))
 
 			return
 		}
@@ -80,7 +81,7 @@ func oidcUserinfo(ctx *middlewares.AutheliaCtx, rw http.ResponseWriter, req *htt
 
 		rw.Header().Set("Content-Type", "application/jwt")
 		_, _ = rw.Write([]byte(token))
-	case "none", "":
+	case oidc.SigningAlgorithmNone, "":
 		ctx.Providers.OpenIDConnect.Write(rw, req, claims)
 	default:
 		ctx.Providers.OpenIDConnect.WriteError(rw, req, errors.WithStack(fosite.ErrServerError.WithHintf("Unsupported userinfo signing algorithm '%s'.", client.UserinfoSigningAlgorithm)))
@@ -6,8 +6,10 @@ import (
 
 	"github.com/valyala/fasthttp"
 
+	"github.com/authelia/authelia/internal/configuration/schema"
 	"github.com/authelia/authelia/internal/middlewares"
 	"github.com/authelia/authelia/internal/oidc"
+	"github.com/authelia/authelia/internal/utils"
 )
 
 func oidcWellKnown(ctx *middlewares.AutheliaCtx) {
@@ -24,13 +26,17 @@ func oidcWellKnown(ctx *middlewares.AutheliaCtx) {
 		Issuer:  issuer,
 		JWKSURI: fmt.Sprintf("%s%s", issuer, oidcJWKsPath),
 
-		AuthorizationEndpoint: fmt.Sprintf("%s%s", issuer, oidcAuthorizePath),
-		TokenEndpoint:         fmt.Sprintf("%s%s", issuer, oidcTokenPath),
-		RevocationEndpoint:    fmt.Sprintf("%s%s", issuer, oidcRevokePath),
-		UserinfoEndpoint:      fmt.Sprintf("%s%s", issuer, oidcUserinfoPath),
+		AuthorizationEndpoint:       fmt.Sprintf("%s%s", issuer, oidcAuthorizePath),
+		TokenEndpoint:               fmt.Sprintf("%s%s", issuer, oidcTokenPath),
+		DeviceAuthorizationEndpoint: fmt.Sprintf("%s%s", issuer, oidcDeviceAuthorizationPath),
+		RevocationEndpoint:          fmt.Sprintf("%s%s", issuer, oidcRevokePath),
+		UserinfoEndpoint:            fmt.Sprintf("%s%s", issuer, oidcUserinfoPath),
+		EndSessionEndpoint:          fmt.Sprintf("%s%s", issuer, oidcEndSessionPath),
 
-		Algorithms:         []string{"RS256"},
-		UserinfoAlgorithms: []string{"none", "RS256"},
+		PushedAuthorizationRequestEndpoint: fmt.Sprintf("%s%s", issuer, oidcPushedAuthorizationRequestPath),
+
+		Algorithms:         ctx.Providers.OpenIDConnect.KeyManager.Algorithms(),
+		UserinfoAlgorithms: append([]string{oidc.SigningAlgorithmNone}, ctx.Providers.OpenIDConnect.KeyManager.Algorithms()...),
 
 		SubjectTypesSupported: []string{
 			"public",
@@ -57,6 +63,13 @@ func oidcWellKnown(ctx *middlewares.AutheliaCtx) {
 			"groups",
 			"email",
 		},
+		GrantTypesSupported: []string{
+			"authorization_code",
+			"implicit",
+			"refresh_token",
+			"client_credentials",
+			oidc.GrantTypeDeviceCode,
+		},
 		ClaimsSupported: []string{
 			"aud",
 			"exp",
@@ -72,13 +85,29 @@ func oidcWellKnown(ctx *middlewares.AutheliaCtx) {
 			"alt_emails",
 			"groups",
 			"name",
+			"sid",
+		},
+
+		RequestObjectSigningAlgorithms: oidc.RequestObjectSigningAlgorithms,
+		CodeChallengeMethodsSupported: []string{
+			"S256",
 		},
 
-		RequestURIParameterSupported:       false,
-		BackChannelLogoutSupported:         false,
-		FrontChannelLogoutSupported:        false,
-		BackChannelLogoutSessionSupported:  false,
-		FrontChannelLogoutSessionSupported: false,
+		RequestParameterSupported:          true,
+		RequestURIParameterSupported:       true,
+		BackChannelLogoutSupported:         true,
+		FrontChannelLogoutSupported:        true,
+		BackChannelLogoutSessionSupported:  true,
+		FrontChannelLogoutSessionSupported: true,
+	}
+
+	// Dynamic client registration is only advertised when it's enabled with an initial access token.
+	if ctx.Configuration.IdentityProviders.OIDC != nil && ctx.Configuration.IdentityProviders.OIDC.ClientRegistration.InitialAccessToken != "" {
+		wellKnown.RegistrationEndpoint = fmt.Sprintf("%s%s", issuer, oidcRegistrationPath)
+	}
+
+	if ctx.Configuration.IdentityProviders.OIDC != nil {
+		oidcWellKnownClaimsPolicies(&wellKnown, ctx.Configuration.IdentityProviders.OIDC.ClaimsPolicies)
 	}
 
 	ctx.SetContentType("application/json")
@@ -91,3 +120,31 @@ func oidcWellKnown(ctx *middlewares.AutheliaCtx) {
 		return
 	}
 }
+
+// oidcWellKnownClaimsPolicies advertises the custom scopes and claims of the claims policies along with the standard
+// ones.
+func oidcWellKnownClaimsPolicies(wellKnown *oidc.WellKnownConfiguration, policies []schema.OpenIDConnectClaimsPolicyConfiguration) {
+	for _, policy := range policies {
+		for _, scope := range policy.CustomScopes {
+			if !utils.IsStringInSlice(scope.Name, wellKnown.ScopesSupported) {
+				wellKnown.ScopesSupported = append(wellKnown.ScopesSupported, scope.Name)
+			}
+		}
+
+		var claims []string
+
+		for _, claim := range policy.CustomClaims {
+			claims = append(claims, claim.Name)
+		}
+
+		for _, rename := range policy.RenameClaims {
+			claims = append(claims, rename.Name)
+		}
+
+		for _, claim := range claims {
+			if !utils.IsStringInSlice(claim, wellKnown.ClaimsSupported) {
+				wellKnown.ClaimsSupported = append(wellKnown.ClaimsSupported, claim)
+			}
+		}
+	}
+}
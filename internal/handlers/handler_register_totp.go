@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/pquerna/otp/totp"
 
 	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
 	"github.com/authelia/authelia/internal/session"
+	"github.com/authelia/authelia/internal/storage"
 )
 
 // identityRetrieverFromSession retriever computing the identity from the cookie session.
@@ -37,6 +40,25 @@ var SecondFactorTOTPIdentityStart = middlewares.IdentityVerificationStart(middle
 })
 
 func secondFactorTOTPIdentityFinish(ctx *middlewares.AutheliaCtx, username string) {
+	var requestBody registerTOTPRequestBody
+
+	if err := json.Unmarshal(ctx.PostBody(), &requestBody); err != nil {
+		ctx.Error(fmt.Errorf("Unable to parse TOTP registration request body: %w", err), unableToRegisterOneTimePasswordMessage)
+		return
+	}
+
+	configs, err := ctx.Providers.StorageProvider.LoadTOTPConfigurationsByUsername(username)
+	if err != nil && err != storage.ErrNoTOTPConfiguration {
+		ctx.Error(fmt.Errorf("Unable to load TOTP configurations for user %s: %w", username, err), unableToRegisterOneTimePasswordMessage)
+		return
+	}
+
+	description, err := secondFactorDeviceDescription(requestBody.Description, models.TOTPConfigurationDefaultDescription, totpConfigurationDescriptions(configs))
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to register TOTP configuration for user %s: %w", username, err), unableToRegisterOneTimePasswordMessage)
+		return
+	}
+
 	key, err := totp.Generate(totp.GenerateOpts{
 		Issuer:      ctx.Configuration.TOTP.Issuer,
 		AccountName: username,
@@ -49,9 +71,16 @@ func secondFactorTOTPIdentityFinish(ctx *middlewares.AutheliaCtx, username strin
 		return
 	}
 
-	err = ctx.Providers.StorageProvider.SaveTOTPSecret(username, key.Secret())
+	config := models.TOTPConfiguration{
+		CreatedAt:   ctx.Clock.Now(),
+		Username:    username,
+		Description: description,
+		Secret:      key.Secret(),
+	}
+
+	err = ctx.Providers.StorageProvider.SaveTOTPConfiguration(config)
 	if err != nil {
-		ctx.Error(fmt.Errorf("Unable to save TOTP secret in DB: %s", err), unableToRegisterOneTimePasswordMessage)
+		ctx.Error(fmt.Errorf("Unable to save TOTP configuration in DB: %s", err), unableToRegisterOneTimePasswordMessage)
 		return
 	}
 
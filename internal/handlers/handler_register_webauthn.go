@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/duo-labs/webauthn/protocol"
+	"github.com/duo-labs/webauthn/webauthn"
+
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+)
+
+// SecondFactorWebauthnIdentityStart the handler for initiating the identity validation.
+var SecondFactorWebauthnIdentityStart = middlewares.IdentityVerificationStart(middlewares.IdentityVerificationStartArgs{
+	MailTitle:             "Register your key",
+	MailButtonContent:     "Register",
+	TargetEndpoint:        "/security-key/register",
+	ActionClaim:           WebauthnRegistrationAction,
+	IdentityRetrieverFunc: identityRetrieverFromSession,
+})
+
+// SecondFactorWebauthnIdentityFinish the handler for finishing the identity validation.
+var SecondFactorWebauthnIdentityFinish = middlewares.IdentityVerificationFinish(
+	middlewares.IdentityVerificationFinishArgs{
+		ActionClaim:          WebauthnRegistrationAction,
+		IsTokenUserValidFunc: isTokenUserValidFor2FARegistration,
+	}, secondFactorWebauthnIdentityFinish)
+
+func secondFactorWebauthnIdentityFinish(ctx *middlewares.AutheliaCtx, _ string) {
+	var (
+		w           *webauthn.WebAuthn
+		user        *models.WebauthnUser
+		err         error
+		userSession = ctx.GetSession()
+	)
+
+	if w, err = newWebauthn(ctx); err != nil {
+		ctx.Error(err, unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	if user, err = getWebAuthnUser(ctx, userSession); err != nil {
+		ctx.Error(fmt.Errorf("Unable to load Webauthn user %s: %w", userSession.Username, err), unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	var credentialCreation *protocol.CredentialCreation
+
+	// Prevent an authenticator which is already registered from being registered a second time.
+	if credentialCreation, userSession.Webauthn, err = w.BeginRegistration(user, webauthn.WithExclusions(user.WebAuthnCredentialDescriptors())); err != nil {
+		ctx.Error(fmt.Errorf("Unable to create Webauthn registration challenge for user %s: %w", userSession.Username, err), unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	if err = ctx.SaveSession(userSession); err != nil {
+		ctx.Error(fmt.Errorf("Unable to save Webauthn registration challenge in session for user %s: %w", userSession.Username, err), unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	if err = ctx.SetJSONBody(credentialCreation); err != nil {
+		ctx.Logger.Errorf("Unable to set Webauthn registration challenge in body: %s", err)
+	}
+}
+
+// SecondFactorWebauthnAttestationPost handler validating the client has successfully completed the attestation
+// challenge to complete the Webauthn registration.
+func SecondFactorWebauthnAttestationPost(ctx *middlewares.AutheliaCtx) {
+	var (
+		err  error
+		w    *webauthn.WebAuthn
+		user *models.WebauthnUser
+
+		requestBody         registerWebauthnRequestBody
+		description         string
+		attestationResponse *protocol.ParsedCredentialCreationData
+		credential          *webauthn.Credential
+
+		userSession = ctx.GetSession()
+	)
+
+	if userSession.Webauthn == nil {
+		ctx.Error(fmt.Errorf("Webauthn registration has not been initiated yet"), unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	// Ensure the challenge is cleared if anything goes wrong.
+	defer func() {
+		userSession.Webauthn = nil
+
+		if err := ctx.SaveSession(userSession); err != nil {
+			ctx.Logger.Errorf("Unable to clear Webauthn registration challenge in session for user %s: %s", userSession.Username, err)
+		}
+	}()
+
+	if w, err = newWebauthn(ctx); err != nil {
+		ctx.Error(err, unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	if err = json.Unmarshal(ctx.PostBody(), &requestBody); err != nil {
+		ctx.Error(fmt.Errorf("Unable to parse Webauthn attestation request body: %w", err), unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	if attestationResponse, err = protocol.ParseCredentialCreationResponseBody(bytes.NewReader(ctx.PostBody())); err != nil {
+		ctx.Error(fmt.Errorf("Unable to parse Webauthn attestation response for user %s: %w", userSession.Username, err), unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	if user, err = getWebAuthnUser(ctx, userSession); err != nil {
+		ctx.Error(fmt.Errorf("Unable to load Webauthn user %s: %w", userSession.Username, err), unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	if credential, err = w.CreateCredential(user, *userSession.Webauthn, attestationResponse); err != nil {
+		ctx.Error(fmt.Errorf("Unable to verify Webauthn attestation for user %s: %w", userSession.Username, err), unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	if description, err = secondFactorDeviceDescription(requestBody.Description, models.WebauthnDeviceDefaultDescription, webauthnDeviceDescriptions(user.Devices)); err != nil {
+		ctx.Error(fmt.Errorf("Unable to register Webauthn device for user %s: %w", userSession.Username, err), unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	ctx.Logger.Debugf("Register Webauthn device '%s' for user %s", description, userSession.Username)
+
+	device := models.NewWebauthnDeviceFromCredential(ctx.Clock.Now(), userSession.Username, description, credential)
+
+	if err = ctx.Providers.StorageProvider.SaveWebauthnDevice(device); err != nil {
+		ctx.Error(fmt.Errorf("Unable to register Webauthn device for user %s: %w", userSession.Username, err), unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	ctx.ReplyOK()
+}
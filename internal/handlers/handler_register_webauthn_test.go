@@ -13,15 +13,16 @@ import (
 
 	"github.com/authelia/authelia/internal/middlewares"
 	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/storage"
 )
 
-type HandlerRegisterU2FStep1Suite struct {
+type HandlerRegisterWebauthnSuite struct {
 	suite.Suite
 
 	mock *mocks.MockAutheliaCtx
 }
 
-func (s *HandlerRegisterU2FStep1Suite) SetupTest() {
+func (s *HandlerRegisterWebauthnSuite) SetupTest() {
 	s.mock = mocks.NewMockAutheliaCtx(s.T())
 
 	userSession := s.mock.Ctx.GetSession()
@@ -30,7 +31,7 @@ func (s *HandlerRegisterU2FStep1Suite) SetupTest() {
 	require.NoError(s.T(), err)
 }
 
-func (s *HandlerRegisterU2FStep1Suite) TearDownTest() {
+func (s *HandlerRegisterWebauthnSuite) TearDownTest() {
 	s.mock.Close()
 }
 
@@ -49,8 +50,8 @@ func createToken(secret string, username string, action string, expiresAt time.T
 	return ss
 }
 
-func (s *HandlerRegisterU2FStep1Suite) TestShouldRaiseWhenXForwardedProtoIsMissing() {
-	token := createToken(s.mock.Ctx.Configuration.JWTSecret, "john", U2FRegistrationAction,
+func (s *HandlerRegisterWebauthnSuite) expectToken() {
+	token := createToken(s.mock.Ctx.Configuration.JWTSecret, "john", WebauthnRegistrationAction,
 		time.Now().Add(1*time.Minute))
 	s.mock.Ctx.Request.SetBodyString(fmt.Sprintf("{\"token\":\"%s\"}", token))
 
@@ -61,33 +62,53 @@ func (s *HandlerRegisterU2FStep1Suite) TestShouldRaiseWhenXForwardedProtoIsMissi
 	s.mock.StorageProviderMock.EXPECT().
 		RemoveIdentityVerificationToken(gomock.Eq(token)).
 		Return(nil)
+}
+
+func (s *HandlerRegisterWebauthnSuite) TestShouldRaiseWhenXForwardedProtoIsMissing() {
+	s.expectToken()
 
-	SecondFactorU2FIdentityFinish(s.mock.Ctx)
+	SecondFactorWebauthnIdentityFinish(s.mock.Ctx)
 
 	assert.Equal(s.T(), 200, s.mock.Ctx.Response.StatusCode())
 	assert.Equal(s.T(), "Missing header X-Forwarded-Proto", s.mock.Hook.LastEntry().Message)
 }
 
-func (s *HandlerRegisterU2FStep1Suite) TestShouldRaiseWhenXForwardedHostIsMissing() {
+func (s *HandlerRegisterWebauthnSuite) TestShouldRaiseWhenXForwardedHostIsMissing() {
 	s.mock.Ctx.Request.Header.Add("X-Forwarded-Proto", "http")
-	token := createToken(s.mock.Ctx.Configuration.JWTSecret, "john", U2FRegistrationAction,
-		time.Now().Add(1*time.Minute))
-	s.mock.Ctx.Request.SetBodyString(fmt.Sprintf("{\"token\":\"%s\"}", token))
+	s.expectToken()
 
-	s.mock.StorageProviderMock.EXPECT().
-		FindIdentityVerificationToken(gomock.Eq(token)).
-		Return(true, nil)
+	SecondFactorWebauthnIdentityFinish(s.mock.Ctx)
+
+	assert.Equal(s.T(), 200, s.mock.Ctx.Response.StatusCode())
+	assert.Equal(s.T(), "Missing header X-Forwarded-Host", s.mock.Hook.LastEntry().Message)
+}
+
+func (s *HandlerRegisterWebauthnSuite) TestShouldReturnAttestationChallenge() {
+	s.mock.Ctx.Request.Header.Add("X-Forwarded-Proto", "https")
+	s.mock.Ctx.Request.Header.Add("X-Forwarded-Host", "login.example.com")
+	s.expectToken()
 
 	s.mock.StorageProviderMock.EXPECT().
-		RemoveIdentityVerificationToken(gomock.Eq(token)).
-		Return(nil)
+		LoadWebauthnDevicesByUsername(gomock.Eq("john")).
+		Return(nil, storage.ErrNoWebauthnDevice)
 
-	SecondFactorU2FIdentityFinish(s.mock.Ctx)
+	SecondFactorWebauthnIdentityFinish(s.mock.Ctx)
 
 	assert.Equal(s.T(), 200, s.mock.Ctx.Response.StatusCode())
-	assert.Equal(s.T(), "Missing header X-Forwarded-Host", s.mock.Hook.LastEntry().Message)
+	assert.Contains(s.T(), string(s.mock.Ctx.Response.Body()), "\"rp\":{\"name\":\"Authelia\",\"id\":\"login.example.com\"}")
+
+	userSession := s.mock.Ctx.GetSession()
+	s.Require().NotNil(userSession.Webauthn)
+	assert.Equal(s.T(), []byte("john"), userSession.Webauthn.UserID)
+}
+
+func (s *HandlerRegisterWebauthnSuite) TestShouldFailAttestationWhenNotInitiated() {
+	SecondFactorWebauthnAttestationPost(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Unable to register your security key.")
+	assert.Equal(s.T(), "Webauthn registration has not been initiated yet", s.mock.Hook.LastEntry().Message)
 }
 
-func TestShouldRunHandlerRegisterU2FStep1Suite(t *testing.T) {
-	suite.Run(t, new(HandlerRegisterU2FStep1Suite))
+func TestShouldRunHandlerRegisterWebauthnSuite(t *testing.T) {
+	suite.Run(t, new(HandlerRegisterWebauthnSuite))
 }
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fasthttp/router"
+
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/regulation"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// RegisterRegulation registers the handlers allowing administrators to manage the bans of the regulation.
+func RegisterRegulation(router *router.Router, middleware middlewares.RequestHandlerBridge) {
+	router.GET(regulationBansPath, middleware(middlewares.RequireSecondFactor(regulationRequireAdministrator(RegulationBansGet))))
+	router.POST(regulationBansPath, middleware(middlewares.RequireSecondFactor(regulationRequireAdministrator(RegulationBansPost))))
+	router.DELETE(regulationBansPath+"/{type}/{value:*}", middleware(middlewares.RequireSecondFactor(regulationRequireAdministrator(RegulationBanDelete))))
+}
+
+// RegulationBansGet lists the active bans of the users, IP addresses and networks.
+func RegulationBansGet(ctx *middlewares.AutheliaCtx) {
+	bans, err := ctx.Providers.Regulator.Bans()
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to load the regulation bans: %w", err), operationFailedMessage)
+		return
+	}
+
+	response := make([]regulationBanResponse, len(bans))
+
+	for i, ban := range bans {
+		response[i] = regulationBanResponse{
+			Type:        ban.Type,
+			Value:       ban.Value,
+			Reason:      ban.Reason,
+			BannedUntil: ban.BannedUntil,
+			Failures:    ban.Failures,
+		}
+	}
+
+	if err = ctx.SetJSONBody(response); err != nil {
+		ctx.Logger.Errorf("Unable to set regulation bans response in body: %s", err)
+	}
+}
+
+// RegulationBansPost bans a user, an IP address or a network. The ban time of the type of the value is used when no
+// duration is provided.
+func RegulationBansPost(ctx *middlewares.AutheliaCtx) {
+	var body regulationBanRequestBody
+
+	if err := ctx.ParseBody(&body); err != nil {
+		ctx.Error(err, operationFailedMessage)
+		return
+	}
+
+	var duration time.Duration
+
+	if body.Duration != "" {
+		var err error
+
+		if duration, err = utils.ParseDurationString(body.Duration); err != nil || duration <= 0 {
+			ctx.Error(fmt.Errorf("Unable to parse the duration %s of the ban", body.Duration), "The duration is invalid.")
+			return
+		}
+	}
+
+	admin := ctx.GetSession().Username
+
+	ban, err := ctx.Providers.Regulator.Ban(body.Type, body.Value, duration, body.Reason, admin)
+	if !regulationHandleError(ctx, err, "ban", body.Type, body.Value) {
+		return
+	}
+
+	ctx.Logger.Infof("The %s %s was banned until %s by %s", ban.Type, ban.Value, ban.ExpiresAt, admin)
+
+	ctx.ReplyOK()
+}
+
+// RegulationBanDelete unbans a user, an IP address or a network.
+func RegulationBanDelete(ctx *middlewares.AutheliaCtx) {
+	banType, _ := ctx.UserValue("type").(string)
+	value, _ := ctx.UserValue("value").(string)
+	admin := ctx.GetSession().Username
+
+	if !regulationHandleError(ctx, ctx.Providers.Regulator.Unban(banType, value, admin), "unban", banType, value) {
+		return
+	}
+
+	ctx.Logger.Infof("The %s %s was unbanned by %s", banType, value, admin)
+
+	ctx.ReplyOK()
+}
+
+// regulationHandleError replies with the error of a ban or an unban, the errors caused by the request are sent to the
+// administrator. It returns true if there is no error.
+func regulationHandleError(ctx *middlewares.AutheliaCtx, err error, action, banType, value string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, regulation.ErrInvalidBan), errors.Is(err, regulation.ErrRegulationDisabled), errors.Is(err, regulation.ErrNetworkIsExempt):
+		ctx.Error(fmt.Errorf("Unable to %s the %s %s: %w", action, banType, value, err), err.Error())
+	default:
+		ctx.Error(fmt.Errorf("Unable to %s the %s %s: %w", action, banType, value, err), operationFailedMessage)
+	}
+
+	return false
+}
+
+// regulationRequireAdministrator ensures the user is a member of the group allowed to manage the bans of the
+// regulation.
+func regulationRequireAdministrator(next middlewares.RequestHandler) middlewares.RequestHandler {
+	return func(ctx *middlewares.AutheliaCtx) {
+		var group string
+
+		if ctx.Configuration.Regulation != nil {
+			group = ctx.Configuration.Regulation.AdminGroup
+		}
+
+		if group == "" || !utils.IsStringInSlice(group, ctx.GetSession().Groups) {
+			ctx.ReplyForbidden()
+			return
+		}
+
+		next(ctx)
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/regulation"
+)
+
+type HandlerRegulationSuite struct {
+	suite.Suite
+
+	mock *mocks.MockAutheliaCtx
+}
+
+func (s *HandlerRegulationSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	userSession := s.mock.Ctx.GetSession()
+	userSession.Username = testUsername
+	userSession.Groups = []string{"admins"}
+	userSession.AuthenticationLevel = authentication.TwoFactor
+	err := s.mock.Ctx.SaveSession(userSession)
+	require.NoError(s.T(), err)
+
+	s.mock.Ctx.Configuration.Regulation = &schema.RegulationConfiguration{
+		MaxRetries: 3,
+		FindTime:   "2m",
+		BanTime:    "5m",
+		IP:         schema.RegulationIPConfiguration{MaxRetries: 10, FindTime: "5m", BanTime: "30m"},
+		AdminGroup: "admins",
+	}
+
+	s.mock.Ctx.Providers.Regulator = regulation.NewRegulator(s.mock.Ctx.Configuration.Regulation, s.mock.StorageProviderMock, &s.mock.Clock)
+}
+
+func (s *HandlerRegulationSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *HandlerRegulationSuite) TestShouldForbidUsersNotInTheAdminGroup() {
+	userSession := s.mock.Ctx.GetSession()
+	userSession.Groups = []string{"dev"}
+	s.Require().NoError(s.mock.Ctx.SaveSession(userSession))
+
+	regulationRequireAdministrator(RegulationBansGet)(s.mock.Ctx)
+
+	s.Assert().Equal(fasthttp.StatusForbidden, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *HandlerRegulationSuite) TestShouldForbidAllUsersWithoutAdminGroup() {
+	s.mock.Ctx.Configuration.Regulation.AdminGroup = ""
+
+	regulationRequireAdministrator(RegulationBansGet)(s.mock.Ctx)
+
+	s.Assert().Equal(fasthttp.StatusForbidden, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *HandlerRegulationSuite) TestShouldListBans() {
+	s.mock.StorageProviderMock.EXPECT().
+		LoadRegulationBans(gomock.Eq(s.mock.Clock.Now())).
+		Return([]models.RegulationBan{{
+			Type:      regulation.BanTypeIP,
+			Value:     "192.168.1.10",
+			Reason:    "banned by john",
+			ExpiresAt: s.mock.Clock.Now().Add(time.Hour),
+		}}, nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		LoadLatestFailedAuthenticationLogs(gomock.Any()).
+		Return(nil, nil)
+
+	regulationRequireAdministrator(RegulationBansGet)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), []regulationBanResponse{{
+		Type:        regulation.BanTypeIP,
+		Value:       "192.168.1.10",
+		Reason:      "banned by john",
+		BannedUntil: s.mock.Clock.Now().Add(time.Hour),
+	}})
+}
+
+func (s *HandlerRegulationSuite) TestShouldBanUser() {
+	gomock.InOrder(
+		s.mock.StorageProviderMock.EXPECT().
+			SaveRegulationBan(gomock.Eq(models.RegulationBan{
+				Type:      regulation.BanTypeUser,
+				Value:     "harry",
+				Reason:    "compromised",
+				CreatedAt: s.mock.Clock.Now(),
+				ExpiresAt: s.mock.Clock.Now().Add(24 * time.Hour),
+			})).
+			Return(nil),
+		s.mock.StorageProviderMock.EXPECT().
+			AppendAuthenticationLog(gomock.Eq(models.AuthenticationAttempt{
+				Username: "harry",
+				Time:     s.mock.Clock.Now(),
+				Admin:    testUsername,
+			})).
+			Return(nil),
+	)
+
+	s.mock.Ctx.Request.SetBodyString(`{"type":"user","value":"harry","duration":"1d","reason":"compromised"}`)
+
+	RegulationBansPost(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+}
+
+func (s *HandlerRegulationSuite) TestShouldFailToBanWithInvalidDuration() {
+	s.mock.Ctx.Request.SetBodyString(`{"type":"user","value":"harry","duration":"a while"}`)
+
+	RegulationBansPost(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "The duration is invalid.")
+}
+
+func (s *HandlerRegulationSuite) TestShouldFailToBanDisabledType() {
+	s.mock.Ctx.Request.SetBodyString(`{"type":"network","value":"10.0.0.0/24"}`)
+
+	RegulationBansPost(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "the regulation of this ban type is disabled")
+}
+
+func (s *HandlerRegulationSuite) TestShouldUnbanIP() {
+	gomock.InOrder(
+		s.mock.StorageProviderMock.EXPECT().
+			RevokeRegulationBans(gomock.Eq(regulation.BanTypeIP), gomock.Eq("192.168.1.10"), gomock.Eq(s.mock.Clock.Now())).
+			Return(nil),
+		s.mock.StorageProviderMock.EXPECT().
+			AppendAuthenticationLog(gomock.Eq(models.AuthenticationAttempt{
+				Successful: true,
+				Time:       s.mock.Clock.Now(),
+				RemoteIP:   net.ParseIP("192.168.1.10"),
+				Admin:      testUsername,
+			})).
+			Return(nil),
+	)
+
+	s.mock.Ctx.SetUserValue("type", "ip")
+	s.mock.Ctx.SetUserValue("value", "192.168.1.10")
+
+	RegulationBanDelete(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+}
+
+func (s *HandlerRegulationSuite) TestShouldFailToUnbanUnknownType() {
+	s.mock.Ctx.SetUserValue("type", "device")
+	s.mock.Ctx.SetUserValue("value", "john")
+
+	RegulationBanDelete(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "invalid ban: unknown ban type device, it must be one of user, ip or network")
+}
+
+func TestRunHandlerRegulationSuite(t *testing.T) {
+	suite.Run(t, new(HandlerRegulationSuite))
+}
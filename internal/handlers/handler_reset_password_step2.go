@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 
+	"github.com/authelia/authelia/internal/authentication"
 	"github.com/authelia/authelia/internal/middlewares"
 	"github.com/authelia/authelia/internal/utils"
 )
@@ -13,7 +15,8 @@ func ResetPasswordPost(ctx *middlewares.AutheliaCtx) {
 
 	// Those checks unsure that the identity verification process has been initiated and completed successfully
 	// otherwise PasswordReset would not be set to true. We can improve the security of this check by making the
-	// request expire at some point because here it only expires when the cookie expires.
+	// request expire at some point because here it only expires when the cookie expires. It's also set when the
+	// password of the user must be changed on sign in.
 	if userSession.PasswordResetUsername == nil {
 		ctx.Error(fmt.Errorf("No identity verification process has been initiated"), unableToResetPasswordMessage)
 		return
@@ -31,7 +34,8 @@ func ResetPasswordPost(ctx *middlewares.AutheliaCtx) {
 
 	if err != nil {
 		switch {
-		case utils.IsStringInSliceContains(err.Error(), ldapPasswordComplexityCodes),
+		case errors.Is(err, authentication.ErrPasswordPolicy),
+			utils.IsStringInSliceContains(err.Error(), ldapPasswordComplexityCodes),
 			utils.IsStringInSliceContains(err.Error(), ldapPasswordComplexityErrors):
 			ctx.Error(fmt.Errorf("%s", err), ldapPasswordComplexityCode)
 		default:
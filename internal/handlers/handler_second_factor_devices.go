@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+// secondFactorDeviceDescription determines the description of a newly registered device. When no description is
+// requested the default is used, suffixed with a number if it is already taken by another device of the user.
+func secondFactorDeviceDescription(requested, defaultDescription string, existing []string) (description string, err error) {
+	description = strings.TrimSpace(requested)
+
+	if len(description) > secondFactorDeviceDescriptionMaxLength {
+		return "", fmt.Errorf("the description '%s' is longer than %d characters", description, secondFactorDeviceDescriptionMaxLength)
+	}
+
+	taken := make(map[string]bool, len(existing))
+	for _, d := range existing {
+		taken[strings.ToLower(d)] = true
+	}
+
+	if description != "" {
+		if taken[strings.ToLower(description)] {
+			return "", fmt.Errorf("a device with the description '%s' is already registered", description)
+		}
+
+		return description, nil
+	}
+
+	description = defaultDescription
+
+	for i := 2; taken[strings.ToLower(description)]; i++ {
+		description = fmt.Sprintf("%s %d", defaultDescription, i)
+	}
+
+	return description, nil
+}
+
+func secondFactorDeviceID(ctx *middlewares.AutheliaCtx) (id int, err error) {
+	value, ok := ctx.UserValue("id").(string)
+	if !ok {
+		return 0, errors.New("no device id was provided")
+	}
+
+	if id, err = strconv.Atoi(value); err != nil {
+		return 0, fmt.Errorf("the device id '%s' is not a valid integer: %w", value, err)
+	}
+
+	return id, nil
+}
+
+// SecondFactorTOTPConfigurationsGet lists the TOTP configurations registered by the current user.
+func SecondFactorTOTPConfigurationsGet(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	configs, err := ctx.Providers.StorageProvider.LoadTOTPConfigurationsByUsername(userSession.Username)
+	if err != nil && err != storage.ErrNoTOTPConfiguration {
+		ctx.Error(fmt.Errorf("Unable to load TOTP configurations for user %s: %w", userSession.Username, err), operationFailedMessage)
+		return
+	}
+
+	response := make([]secondFactorDeviceResponse, len(configs))
+
+	for i, config := range configs {
+		response[i] = secondFactorDeviceResponse{
+			ID:          config.ID,
+			Description: config.Description,
+			CreatedAt:   config.CreatedAt,
+			LastUsedAt:  config.LastUsedAt,
+		}
+	}
+
+	if err = ctx.SetJSONBody(response); err != nil {
+		ctx.Logger.Errorf("Unable to set TOTP configurations response in body: %s", err)
+	}
+}
+
+// SecondFactorTOTPConfigurationDelete removes one of the TOTP configurations registered by the current user.
+func SecondFactorTOTPConfigurationDelete(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	id, err := secondFactorDeviceID(ctx)
+	if err != nil {
+		ctx.Error(err, operationFailedMessage)
+		return
+	}
+
+	if err = ctx.Providers.StorageProvider.DeleteTOTPConfiguration(userSession.Username, id); err != nil {
+		ctx.Error(fmt.Errorf("Unable to delete TOTP configuration %d of user %s: %w", id, userSession.Username, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Debugf("Deleted TOTP configuration %d of user %s", id, userSession.Username)
+
+	ctx.ReplyOK()
+}
+
+// SecondFactorWebauthnDevicesGet lists the Webauthn devices registered by the current user.
+func SecondFactorWebauthnDevicesGet(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	devices, err := ctx.Providers.StorageProvider.LoadWebauthnDevicesByUsername(userSession.Username)
+	if err != nil && err != storage.ErrNoWebauthnDevice {
+		ctx.Error(fmt.Errorf("Unable to load Webauthn devices for user %s: %w", userSession.Username, err), operationFailedMessage)
+		return
+	}
+
+	response := make([]secondFactorDeviceResponse, len(devices))
+
+	for i, device := range devices {
+		response[i] = secondFactorDeviceResponse{
+			ID:          device.ID,
+			Description: device.Description,
+			CreatedAt:   device.CreatedAt,
+			LastUsedAt:  device.LastUsedAt,
+		}
+	}
+
+	if err = ctx.SetJSONBody(response); err != nil {
+		ctx.Logger.Errorf("Unable to set Webauthn devices response in body: %s", err)
+	}
+}
+
+// SecondFactorWebauthnDeviceDelete removes one of the Webauthn devices registered by the current user.
+func SecondFactorWebauthnDeviceDelete(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	id, err := secondFactorDeviceID(ctx)
+	if err != nil {
+		ctx.Error(err, operationFailedMessage)
+		return
+	}
+
+	if err = ctx.Providers.StorageProvider.DeleteWebauthnDevice(userSession.Username, id); err != nil {
+		ctx.Error(fmt.Errorf("Unable to delete Webauthn device %d of user %s: %w", id, userSession.Username, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Debugf("Deleted Webauthn device %d of user %s", id, userSession.Username)
+
+	ctx.ReplyOK()
+}
+
+func totpConfigurationDescriptions(configs []models.TOTPConfiguration) (descriptions []string) {
+	descriptions = make([]string, len(configs))
+
+	for i, config := range configs {
+		descriptions[i] = config.Description
+	}
+
+	return descriptions
+}
+
+func webauthnDeviceDescriptions(devices []models.WebauthnDevice) (descriptions []string) {
+	descriptions = make([]string, len(devices))
+
+	for i, device := range devices {
+		descriptions[i] = device.Description
+	}
+
+	return descriptions
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+type HandlerSecondFactorDevicesSuite struct {
+	suite.Suite
+
+	mock *mocks.MockAutheliaCtx
+}
+
+func (s *HandlerSecondFactorDevicesSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	userSession := s.mock.Ctx.GetSession()
+	userSession.Username = testUsername
+	userSession.AuthenticationLevel = authentication.TwoFactor
+	err := s.mock.Ctx.SaveSession(userSession)
+	require.NoError(s.T(), err)
+}
+
+func (s *HandlerSecondFactorDevicesSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *HandlerSecondFactorDevicesSuite) TestShouldListTOTPConfigurationsWithoutSecrets() {
+	lastUsedAt := time.Unix(1577880002, 0).UTC()
+
+	s.mock.StorageProviderMock.EXPECT().
+		LoadTOTPConfigurationsByUsername(gomock.Eq(testUsername)).
+		Return([]models.TOTPConfiguration{
+			{ID: 1, CreatedAt: time.Unix(1577880001, 0).UTC(), LastUsedAt: &lastUsedAt, Username: testUsername, Description: "Primary", Secret: "secret"},
+			{ID: 2, CreatedAt: time.Unix(1577880003, 0).UTC(), Username: testUsername, Description: "Backup", Secret: "backup"},
+		}, nil)
+
+	SecondFactorTOTPConfigurationsGet(s.mock.Ctx)
+
+	s.Assert().Equal(200, s.mock.Ctx.Response.StatusCode())
+	s.Assert().NotContains(string(s.mock.Ctx.Response.Body()), "secret")
+
+	var response []secondFactorDeviceResponse
+
+	s.mock.GetResponseData(s.T(), &response)
+	s.Require().Len(response, 2)
+	s.Assert().Equal(1, response[0].ID)
+	s.Assert().Equal("Primary", response[0].Description)
+	s.Require().NotNil(response[0].LastUsedAt)
+	s.Assert().Equal(lastUsedAt, *response[0].LastUsedAt)
+	s.Assert().Equal("Backup", response[1].Description)
+	s.Assert().Nil(response[1].LastUsedAt)
+}
+
+func (s *HandlerSecondFactorDevicesSuite) TestShouldListNoTOTPConfigurations() {
+	s.mock.StorageProviderMock.EXPECT().
+		LoadTOTPConfigurationsByUsername(gomock.Eq(testUsername)).
+		Return(nil, storage.ErrNoTOTPConfiguration)
+
+	SecondFactorTOTPConfigurationsGet(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), []secondFactorDeviceResponse{})
+}
+
+func (s *HandlerSecondFactorDevicesSuite) TestShouldListWebauthnDevices() {
+	s.mock.StorageProviderMock.EXPECT().
+		LoadWebauthnDevicesByUsername(gomock.Eq(testUsername)).
+		Return([]models.WebauthnDevice{
+			{ID: 3, CreatedAt: time.Unix(1577880001, 0).UTC(), Username: testUsername, Description: "Primary", KID: []byte("abc"), PublicKey: []byte("def")},
+		}, nil)
+
+	SecondFactorWebauthnDevicesGet(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), []secondFactorDeviceResponse{
+		{ID: 3, Description: "Primary", CreatedAt: time.Unix(1577880001, 0).UTC()},
+	})
+}
+
+func (s *HandlerSecondFactorDevicesSuite) TestShouldDeleteTOTPConfiguration() {
+	s.mock.Ctx.SetUserValue("id", "2")
+
+	s.mock.StorageProviderMock.EXPECT().
+		DeleteTOTPConfiguration(gomock.Eq(testUsername), gomock.Eq(2)).
+		Return(nil)
+
+	SecondFactorTOTPConfigurationDelete(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+}
+
+func (s *HandlerSecondFactorDevicesSuite) TestShouldFailToDeleteTOTPConfigurationOfAnotherUser() {
+	s.mock.Ctx.SetUserValue("id", "2")
+
+	s.mock.StorageProviderMock.EXPECT().
+		DeleteTOTPConfiguration(gomock.Eq(testUsername), gomock.Eq(2)).
+		Return(storage.ErrNoTOTPConfiguration)
+
+	SecondFactorTOTPConfigurationDelete(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+	s.Assert().Equal("Unable to delete TOTP configuration 2 of user john: No TOTP configuration found", s.mock.Hook.LastEntry().Message)
+}
+
+func (s *HandlerSecondFactorDevicesSuite) TestShouldFailToDeleteWebauthnDeviceWithInvalidID() {
+	s.mock.Ctx.SetUserValue("id", "abc")
+
+	SecondFactorWebauthnDeviceDelete(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+}
+
+func (s *HandlerSecondFactorDevicesSuite) TestShouldDeleteWebauthnDevice() {
+	s.mock.Ctx.SetUserValue("id", "3")
+
+	s.mock.StorageProviderMock.EXPECT().
+		DeleteWebauthnDevice(gomock.Eq(testUsername), gomock.Eq(3)).
+		Return(nil)
+
+	SecondFactorWebauthnDeviceDelete(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+}
+
+func TestRunHandlerSecondFactorDevicesSuite(t *testing.T) {
+	suite.Run(t, new(HandlerSecondFactorDevicesSuite))
+}
+
+func TestShouldDetermineSecondFactorDeviceDescription(t *testing.T) {
+	testCases := []struct {
+		name      string
+		requested string
+		existing  []string
+		expected  string
+		err       string
+	}{
+		{"ShouldUseDefault", "", nil, "Primary", ""},
+		{"ShouldSuffixDefaultWhenTaken", "", []string{"Primary", "Primary 2"}, "Primary 3", ""},
+		{"ShouldUseRequested", " Backup ", []string{"Primary"}, "Backup", ""},
+		{"ShouldRejectDuplicate", "backup", []string{"Primary", "Backup"}, "", "a device with the description 'backup' is already registered"},
+		{"ShouldRejectTooLong", "A description which is far too long", nil, "", "the description 'A description which is far too long' is longer than 30 characters"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			description, err := secondFactorDeviceDescription(tc.requested, "Primary", tc.existing)
+
+			if tc.err == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, description)
+			} else {
+				assert.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
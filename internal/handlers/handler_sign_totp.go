@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
 )
 
 // SecondFactorTOTPPost validate the TOTP passcode provided by the user.
@@ -19,23 +20,45 @@ func SecondFactorTOTPPost(totpVerifier TOTPVerifier) middlewares.RequestHandler
 
 		userSession := ctx.GetSession()
 
-		secret, err := ctx.Providers.StorageProvider.LoadTOTPSecret(userSession.Username)
+		configs, err := ctx.Providers.StorageProvider.LoadTOTPConfigurationsByUsername(userSession.Username)
 		if err != nil {
-			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to load TOTP secret: %s", err), mfaValidationFailedMessage)
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to load TOTP configurations: %s", err), mfaValidationFailedMessage)
 			return
 		}
 
-		isValid, err := totpVerifier.Verify(requestBody.Token, secret)
-		if err != nil {
-			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Error occurred during OTP validation for user %s: %s", userSession.Username, err), mfaValidationFailedMessage)
-			return
+		var (
+			isValid            bool
+			configAuthenticate *models.TOTPConfiguration
+		)
+
+		for i, config := range configs {
+			isValid, err = totpVerifier.Verify(requestBody.Token, config.Secret)
+			if err != nil {
+				handleAuthenticationUnauthorized(ctx, fmt.Errorf("Error occurred during OTP validation for user %s: %s", userSession.Username, err), mfaValidationFailedMessage)
+				return
+			}
+
+			if isValid {
+				configAuthenticate = &configs[i]
+				break
+			}
 		}
 
-		if !isValid {
+		if configAuthenticate == nil {
 			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Wrong passcode during TOTP validation for user %s", userSession.Username), mfaValidationFailedMessage)
 			return
 		}
 
+		authenticatedAt := ctx.Clock.Now()
+
+		configAuthenticate.UpdateSignInInfo(authenticatedAt)
+
+		err = ctx.Providers.StorageProvider.UpdateTOTPConfigurationSignIn(configAuthenticate.ID, authenticatedAt)
+		if err != nil {
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to save TOTP configuration sign in information for user %s: %s", userSession.Username, err), mfaValidationFailedMessage)
+			return
+		}
+
 		err = ctx.Providers.SessionProvider.RegenerateSession(ctx.RequestCtx)
 
 		if err != nil {
@@ -43,7 +66,7 @@ func SecondFactorTOTPPost(totpVerifier TOTPVerifier) middlewares.RequestHandler
 			return
 		}
 
-		userSession.SetTwoFactor(ctx.Clock.Now())
+		userSession.SetTwoFactor(authenticatedAt)
 
 		err = ctx.SaveSession(userSession)
 		if err != nil {
@@ -5,13 +5,13 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/duo-labs/webauthn/webauthn"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
-	"github.com/tstranex/u2f"
 
 	"github.com/authelia/authelia/internal/mocks"
-	"github.com/authelia/authelia/internal/session"
+	"github.com/authelia/authelia/internal/models"
 )
 
 type HandlerSignTOTPSuite struct {
@@ -24,8 +24,7 @@ func (s *HandlerSignTOTPSuite) SetupTest() {
 	s.mock = mocks.NewMockAutheliaCtx(s.T())
 	userSession := s.mock.Ctx.GetSession()
 	userSession.Username = testUsername
-	userSession.U2FChallenge = &u2f.Challenge{}
-	userSession.U2FRegistration = &session.U2FRegistration{}
+	userSession.Webauthn = &webauthn.SessionData{}
 	err := s.mock.Ctx.SaveSession(userSession)
 	require.NoError(s.T(), err)
 }
@@ -38,13 +37,17 @@ func (s *HandlerSignTOTPSuite) TestShouldRedirectUserToDefaultURL() {
 	verifier := NewMockTOTPVerifier(s.mock.Ctrl)
 
 	s.mock.StorageProviderMock.EXPECT().
-		LoadTOTPSecret(gomock.Any()).
-		Return("secret", nil)
+		LoadTOTPConfigurationsByUsername(gomock.Any()).
+		Return([]models.TOTPConfiguration{{ID: 1, Username: testUsername, Description: "Primary", Secret: "secret"}}, nil)
 
 	verifier.EXPECT().
 		Verify(gomock.Eq("abc"), gomock.Eq("secret")).
 		Return(true, nil)
 
+	s.mock.StorageProviderMock.EXPECT().
+		UpdateTOTPConfigurationSignIn(gomock.Eq(1), gomock.Any()).
+		Return(nil)
+
 	s.mock.Ctx.Configuration.DefaultRedirectionURL = testRedirectionURL
 
 	bodyBytes, err := json.Marshal(signTOTPRequestBody{
@@ -63,13 +66,17 @@ func (s *HandlerSignTOTPSuite) TestShouldNotReturnRedirectURL() {
 	verifier := NewMockTOTPVerifier(s.mock.Ctrl)
 
 	s.mock.StorageProviderMock.EXPECT().
-		LoadTOTPSecret(gomock.Any()).
-		Return("secret", nil)
+		LoadTOTPConfigurationsByUsername(gomock.Any()).
+		Return([]models.TOTPConfiguration{{ID: 1, Username: testUsername, Description: "Primary", Secret: "secret"}}, nil)
 
 	verifier.EXPECT().
 		Verify(gomock.Eq("abc"), gomock.Eq("secret")).
 		Return(true, nil)
 
+	s.mock.StorageProviderMock.EXPECT().
+		UpdateTOTPConfigurationSignIn(gomock.Eq(1), gomock.Any()).
+		Return(nil)
+
 	bodyBytes, err := json.Marshal(signTOTPRequestBody{
 		Token: "abc",
 	})
@@ -84,13 +91,17 @@ func (s *HandlerSignTOTPSuite) TestShouldRedirectUserToSafeTargetURL() {
 	verifier := NewMockTOTPVerifier(s.mock.Ctrl)
 
 	s.mock.StorageProviderMock.EXPECT().
-		LoadTOTPSecret(gomock.Any()).
-		Return("secret", nil)
+		LoadTOTPConfigurationsByUsername(gomock.Any()).
+		Return([]models.TOTPConfiguration{{ID: 1, Username: testUsername, Description: "Primary", Secret: "secret"}}, nil)
 
 	verifier.EXPECT().
 		Verify(gomock.Eq("abc"), gomock.Eq("secret")).
 		Return(true, nil)
 
+	s.mock.StorageProviderMock.EXPECT().
+		UpdateTOTPConfigurationSignIn(gomock.Eq(1), gomock.Any()).
+		Return(nil)
+
 	bodyBytes, err := json.Marshal(signTOTPRequestBody{
 		Token:     "abc",
 		TargetURL: "https://mydomain.local",
@@ -108,13 +119,17 @@ func (s *HandlerSignTOTPSuite) TestShouldNotRedirectToUnsafeURL() {
 	verifier := NewMockTOTPVerifier(s.mock.Ctrl)
 
 	s.mock.StorageProviderMock.EXPECT().
-		LoadTOTPSecret(gomock.Any()).
-		Return("secret", nil)
+		LoadTOTPConfigurationsByUsername(gomock.Any()).
+		Return([]models.TOTPConfiguration{{ID: 1, Username: testUsername, Description: "Primary", Secret: "secret"}}, nil)
 
 	verifier.EXPECT().
 		Verify(gomock.Eq("abc"), gomock.Eq("secret")).
 		Return(true, nil)
 
+	s.mock.StorageProviderMock.EXPECT().
+		UpdateTOTPConfigurationSignIn(gomock.Eq(1), gomock.Any()).
+		Return(nil)
+
 	bodyBytes, err := json.Marshal(signTOTPRequestBody{
 		Token:     "abc",
 		TargetURL: "http://mydomain.local",
@@ -130,13 +145,17 @@ func (s *HandlerSignTOTPSuite) TestShouldRegenerateSessionForPreventingSessionFi
 	verifier := NewMockTOTPVerifier(s.mock.Ctrl)
 
 	s.mock.StorageProviderMock.EXPECT().
-		LoadTOTPSecret(gomock.Any()).
-		Return("secret", nil)
+		LoadTOTPConfigurationsByUsername(gomock.Any()).
+		Return([]models.TOTPConfiguration{{ID: 1, Username: testUsername, Description: "Primary", Secret: "secret"}}, nil)
 
 	verifier.EXPECT().
 		Verify(gomock.Eq("abc"), gomock.Eq("secret")).
 		Return(true, nil)
 
+	s.mock.StorageProviderMock.EXPECT().
+		UpdateTOTPConfigurationSignIn(gomock.Eq(1), gomock.Any()).
+		Return(nil)
+
 	bodyBytes, err := json.Marshal(signTOTPRequestBody{
 		Token: "abc",
 	})
@@ -154,6 +173,64 @@ func (s *HandlerSignTOTPSuite) TestShouldRegenerateSessionForPreventingSessionFi
 		string(s.mock.Ctx.Request.Header.Cookie("authelia_session")))
 }
 
+func (s *HandlerSignTOTPSuite) TestShouldAcceptPasscodeOfAnyRegisteredConfiguration() {
+	verifier := NewMockTOTPVerifier(s.mock.Ctrl)
+
+	s.mock.StorageProviderMock.EXPECT().
+		LoadTOTPConfigurationsByUsername(gomock.Any()).
+		Return([]models.TOTPConfiguration{
+			{ID: 1, Username: testUsername, Description: "Primary", Secret: "secret"},
+			{ID: 2, Username: testUsername, Description: "Backup", Secret: "backup"},
+		}, nil)
+
+	gomock.InOrder(
+		verifier.EXPECT().
+			Verify(gomock.Eq("abc"), gomock.Eq("secret")).
+			Return(false, nil),
+		verifier.EXPECT().
+			Verify(gomock.Eq("abc"), gomock.Eq("backup")).
+			Return(true, nil),
+	)
+
+	s.mock.StorageProviderMock.EXPECT().
+		UpdateTOTPConfigurationSignIn(gomock.Eq(2), gomock.Any()).
+		Return(nil)
+
+	bodyBytes, err := json.Marshal(signTOTPRequestBody{
+		Token: "abc",
+	})
+	s.Require().NoError(err)
+	s.mock.Ctx.Request.SetBody(bodyBytes)
+
+	SecondFactorTOTPPost(verifier)(s.mock.Ctx)
+	s.mock.Assert200OK(s.T(), nil)
+}
+
+func (s *HandlerSignTOTPSuite) TestShouldFailWhenNoConfigurationMatches() {
+	verifier := NewMockTOTPVerifier(s.mock.Ctrl)
+
+	s.mock.StorageProviderMock.EXPECT().
+		LoadTOTPConfigurationsByUsername(gomock.Any()).
+		Return([]models.TOTPConfiguration{
+			{ID: 1, Username: testUsername, Description: "Primary", Secret: "secret"},
+			{ID: 2, Username: testUsername, Description: "Backup", Secret: "backup"},
+		}, nil)
+
+	verifier.EXPECT().
+		Verify(gomock.Eq("abc"), gomock.Any()).
+		Return(false, nil).
+		Times(2)
+
+	bodyBytes, err := json.Marshal(signTOTPRequestBody{
+		Token: "abc",
+	})
+	s.Require().NoError(err)
+	s.mock.Ctx.Request.SetBody(bodyBytes)
+
+	SecondFactorTOTPPost(verifier)(s.mock.Ctx)
+	s.mock.Assert401KO(s.T(), "Authentication failed, please retry later.")
+}
+
 func TestRunHandlerSignTOTPSuite(t *testing.T) {
 	suite.Run(t, new(HandlerSignTOTPSuite))
 }
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/duo-labs/webauthn/protocol"
+	"github.com/duo-labs/webauthn/webauthn"
+
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+)
+
+// SecondFactorWebauthnAssertionGet handler for initiating a Webauthn signing request.
+func SecondFactorWebauthnAssertionGet(ctx *middlewares.AutheliaCtx) {
+	var (
+		w    *webauthn.WebAuthn
+		user *models.WebauthnUser
+		err  error
+	)
+
+	userSession := ctx.GetSession()
+
+	if w, err = newWebauthn(ctx); err != nil {
+		handleAuthenticationUnauthorized(ctx, err, mfaValidationFailedMessage)
+		return
+	}
+
+	if user, err = getWebAuthnUser(ctx, userSession); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to load Webauthn user %s: %w", userSession.Username, err), mfaValidationFailedMessage)
+		return
+	}
+
+	if len(user.Devices) == 0 {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("No Webauthn device found for user %s", userSession.Username), mfaValidationFailedMessage)
+		return
+	}
+
+	var opts []webauthn.LoginOption
+
+	// Devices registered with the legacy U2F protocol are scoped to the U2F AppID rather than the RP ID.
+	if user.HasFIDOU2F() {
+		opts = append(opts, webauthn.WithAssertionExtensions(map[string]interface{}{
+			"appid": w.Config.RPOrigin,
+		}))
+	}
+
+	var assertion *protocol.CredentialAssertion
+
+	if assertion, userSession.Webauthn, err = w.BeginLogin(user, opts...); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to create Webauthn assertion challenge for user %s: %w", userSession.Username, err), mfaValidationFailedMessage)
+		return
+	}
+
+	if err = ctx.SaveSession(userSession); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to save Webauthn assertion challenge in session for user %s: %w", userSession.Username, err), mfaValidationFailedMessage)
+		return
+	}
+
+	if err = ctx.SetJSONBody(assertion); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to set Webauthn assertion challenge in body: %w", err), mfaValidationFailedMessage)
+		return
+	}
+}
+
+// SecondFactorWebauthnAssertionPost handler for completing a Webauthn signing request.
+func SecondFactorWebauthnAssertionPost(ctx *middlewares.AutheliaCtx) {
+	var (
+		err  error
+		w    *webauthn.WebAuthn
+		user *models.WebauthnUser
+
+		requestBody        signWebauthnRequestBody
+		assertionResponse  *protocol.ParsedCredentialAssertionData
+		credential         *webauthn.Credential
+		userSession        = ctx.GetSession()
+		authenticatedAt    = ctx.Clock.Now()
+		deviceAuthenticate *models.WebauthnDevice
+	)
+
+	if err = json.Unmarshal(ctx.PostBody(), &requestBody); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to parse Webauthn assertion request body: %w", err), mfaValidationFailedMessage)
+		return
+	}
+
+	if userSession.Webauthn == nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Webauthn signing has not been initiated yet (no challenge)"), mfaValidationFailedMessage)
+		return
+	}
+
+	if w, err = newWebauthn(ctx); err != nil {
+		handleAuthenticationUnauthorized(ctx, err, mfaValidationFailedMessage)
+		return
+	}
+
+	if assertionResponse, err = protocol.ParseCredentialRequestResponseBody(bytes.NewReader(ctx.PostBody())); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to parse Webauthn assertion response for user %s: %w", userSession.Username, err), mfaValidationFailedMessage)
+		return
+	}
+
+	if user, err = getWebAuthnUser(ctx, userSession); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to load Webauthn user %s: %w", userSession.Username, err), mfaValidationFailedMessage)
+		return
+	}
+
+	if credential, err = w.ValidateLogin(user, *userSession.Webauthn, assertionResponse); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to validate Webauthn assertion for user %s: %w", userSession.Username, err), mfaValidationFailedMessage)
+		return
+	}
+
+	for i, device := range user.Devices {
+		if bytes.Equal(device.KID, credential.ID) {
+			deviceAuthenticate = &user.Devices[i]
+			break
+		}
+	}
+
+	if deviceAuthenticate == nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to find the Webauthn device used by user %s", userSession.Username), mfaValidationFailedMessage)
+		return
+	}
+
+	deviceAuthenticate.UpdateSignInInfo(authenticatedAt, credential.Authenticator.SignCount, credential.Authenticator.CloneWarning)
+
+	if deviceAuthenticate.CloneWarning {
+		ctx.Logger.Warnf("Webauthn device %d of user %s reported a sign count which indicates it may have been cloned", deviceAuthenticate.ID, userSession.Username)
+	}
+
+	if err = ctx.Providers.StorageProvider.UpdateWebauthnDeviceSignIn(deviceAuthenticate.ID, authenticatedAt, deviceAuthenticate.SignCount, deviceAuthenticate.CloneWarning); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to save Webauthn device sign in information for user %s: %w", userSession.Username, err), mfaValidationFailedMessage)
+		return
+	}
+
+	if deviceAuthenticate.CloneWarning && !ctx.Configuration.Webauthn.AllowClonedAuthenticators {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Webauthn device %d of user %s may have been cloned", deviceAuthenticate.ID, userSession.Username), mfaValidationFailedMessage)
+		return
+	}
+
+	if err = ctx.Providers.SessionProvider.RegenerateSession(ctx.RequestCtx); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to regenerate session for user %s: %s", userSession.Username, err), mfaValidationFailedMessage)
+		return
+	}
+
+	userSession.Webauthn = nil
+	userSession.SetTwoFactor(authenticatedAt)
+
+	if err = ctx.SaveSession(userSession); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to update authentication level with Webauthn: %s", err), mfaValidationFailedMessage)
+		return
+	}
+
+	if userSession.OIDCWorkflowSession != nil {
+		handleOIDCWorkflowResponse(ctx)
+	} else {
+		Handle2FAResponse(ctx, requestBody.TargetURL)
+	}
+}
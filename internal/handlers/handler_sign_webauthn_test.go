@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/duo-labs/webauthn/protocol"
+	"github.com/duo-labs/webauthn/protocol/webauthncbor"
+	"github.com/duo-labs/webauthn/protocol/webauthncose"
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+type HandlerSignWebauthnSuite struct {
+	suite.Suite
+
+	mock *mocks.MockAutheliaCtx
+}
+
+func (s *HandlerSignWebauthnSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+
+	userSession := s.mock.Ctx.GetSession()
+	userSession.Username = testUsername
+	err := s.mock.Ctx.SaveSession(userSession)
+	require.NoError(s.T(), err)
+}
+
+func (s *HandlerSignWebauthnSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *HandlerSignWebauthnSuite) TestShouldRaiseWhenXForwardedProtoIsMissing() {
+	SecondFactorWebauthnAssertionGet(s.mock.Ctx)
+
+	assert.Equal(s.T(), 401, s.mock.Ctx.Response.StatusCode())
+	assert.Equal(s.T(), "Missing header X-Forwarded-Proto", s.mock.Hook.LastEntry().Message)
+}
+
+func (s *HandlerSignWebauthnSuite) TestShouldRaiseWhenXForwardedHostIsMissing() {
+	s.mock.Ctx.Request.Header.Add("X-Forwarded-Proto", "http")
+	SecondFactorWebauthnAssertionGet(s.mock.Ctx)
+
+	assert.Equal(s.T(), 401, s.mock.Ctx.Response.StatusCode())
+	assert.Equal(s.T(), "Missing header X-Forwarded-Host", s.mock.Hook.LastEntry().Message)
+}
+
+func (s *HandlerSignWebauthnSuite) TestShouldRaiseWhenUserHasNoDevices() {
+	s.mock.Ctx.Request.Header.Add("X-Forwarded-Proto", "https")
+	s.mock.Ctx.Request.Header.Add("X-Forwarded-Host", "login.example.com")
+
+	s.mock.StorageProviderMock.EXPECT().
+		LoadWebauthnDevicesByUsername(gomock.Eq("john")).
+		Return(nil, storage.ErrNoWebauthnDevice)
+
+	SecondFactorWebauthnAssertionGet(s.mock.Ctx)
+
+	assert.Equal(s.T(), 401, s.mock.Ctx.Response.StatusCode())
+	assert.Equal(s.T(), "No Webauthn device found for user john", s.mock.Hook.LastEntry().Message)
+}
+
+func (s *HandlerSignWebauthnSuite) TestShouldIncludeAppIDExtensionForU2FDevices() {
+	s.mock.Ctx.Request.Header.Add("X-Forwarded-Proto", "https")
+	s.mock.Ctx.Request.Header.Add("X-Forwarded-Host", "login.example.com")
+
+	s.mock.StorageProviderMock.EXPECT().
+		LoadWebauthnDevicesByUsername(gomock.Eq("john")).
+		Return([]models.WebauthnDevice{
+			{
+				ID:              1,
+				Username:        "john",
+				KID:             []byte("abc"),
+				PublicKey:       []byte("123"),
+				AttestationType: models.WebauthnAttestationTypeFIDOU2F,
+			},
+		}, nil)
+
+	SecondFactorWebauthnAssertionGet(s.mock.Ctx)
+
+	assert.Equal(s.T(), 200, s.mock.Ctx.Response.StatusCode())
+
+	userSession := s.mock.Ctx.GetSession()
+	s.Require().NotNil(userSession.Webauthn)
+	assert.Equal(s.T(), protocol.AuthenticationExtensions{"appid": "https://login.example.com"}, userSession.Webauthn.Extensions)
+	assert.Equal(s.T(), [][]byte{[]byte("abc")}, userSession.Webauthn.AllowedCredentialIDs)
+}
+
+func (s *HandlerSignWebauthnSuite) TestShouldFailAssertionWhenNotInitiated() {
+	s.mock.Ctx.Request.SetBodyString("{}")
+
+	SecondFactorWebauthnAssertionPost(s.mock.Ctx)
+
+	assert.Equal(s.T(), 401, s.mock.Ctx.Response.StatusCode())
+	assert.Equal(s.T(), "Webauthn signing has not been initiated yet (no challenge)", s.mock.Hook.LastEntry().Message)
+}
+
+// signWebauthnAssertion initiates a Webauthn signing request in the session and returns the body of its response
+// signed by the given key with the given sign count.
+func (s *HandlerSignWebauthnSuite) signWebauthnAssertion(key ed25519.PrivateKey, kid []byte, signCount uint32) []byte {
+	challenge := make([]byte, 32)
+	_, err := rand.Read(challenge)
+	s.Require().NoError(err)
+
+	userSession := s.mock.Ctx.GetSession()
+	userSession.Webauthn = &webauthn.SessionData{
+		Challenge:            base64.RawURLEncoding.EncodeToString(challenge),
+		UserID:               []byte(testUsername),
+		AllowedCredentialIDs: [][]byte{kid},
+		UserVerification:     protocol.VerificationPreferred,
+	}
+	s.Require().NoError(s.mock.Ctx.SaveSession(userSession))
+
+	clientData, err := json.Marshal(map[string]string{
+		"type":      "webauthn.get",
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    "https://login.example.com",
+	})
+	s.Require().NoError(err)
+
+	rpIDHash := sha256.Sum256([]byte("login.example.com"))
+	authData := append(rpIDHash[:], byte(protocol.FlagUserPresent), 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(authData[33:], signCount)
+
+	clientDataHash := sha256.Sum256(clientData)
+	signature := ed25519.Sign(key, append(append([]byte{}, authData...), clientDataHash[:]...))
+
+	body, err := json.Marshal(map[string]interface{}{
+		"id":    base64.RawURLEncoding.EncodeToString(kid),
+		"rawId": base64.RawURLEncoding.EncodeToString(kid),
+		"type":  "public-key",
+		"response": map[string]string{
+			"authenticatorData": base64.RawURLEncoding.EncodeToString(authData),
+			"clientDataJSON":    base64.RawURLEncoding.EncodeToString(clientData),
+			"signature":         base64.RawURLEncoding.EncodeToString(signature),
+		},
+	})
+	s.Require().NoError(err)
+
+	return body
+}
+
+func (s *HandlerSignWebauthnSuite) newWebauthnDevice(key ed25519.PrivateKey, signCount uint32, cloneWarning bool) models.WebauthnDevice {
+	publicKey, err := webauthncbor.Marshal(webauthncose.OKPPublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.OctetKey),
+			Algorithm: int64(webauthncose.AlgEdDSA),
+		},
+		XCoord: key.Public().(ed25519.PublicKey),
+	})
+	s.Require().NoError(err)
+
+	return models.WebauthnDevice{
+		ID:              1,
+		Username:        testUsername,
+		KID:             []byte("abc"),
+		PublicKey:       publicKey,
+		AttestationType: "none",
+		SignCount:       signCount,
+		CloneWarning:    cloneWarning,
+	}
+}
+
+func (s *HandlerSignWebauthnSuite) TestShouldValidateAssertion() {
+	s.mock.Ctx.Request.Header.Add("X-Forwarded-Proto", "https")
+	s.mock.Ctx.Request.Header.Add("X-Forwarded-Host", "login.example.com")
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	s.Require().NoError(err)
+
+	device := s.newWebauthnDevice(key, 5, false)
+
+	s.mock.StorageProviderMock.EXPECT().
+		LoadWebauthnDevicesByUsername(gomock.Eq(testUsername)).
+		Return([]models.WebauthnDevice{device}, nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		UpdateWebauthnDeviceSignIn(gomock.Eq(1), gomock.Any(), gomock.Eq(uint32(6)), gomock.Eq(false)).
+		Return(nil)
+
+	s.mock.Ctx.Request.SetBody(s.signWebauthnAssertion(key, device.KID, 6))
+
+	SecondFactorWebauthnAssertionPost(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+	assert.Equal(s.T(), authentication.TwoFactor, s.mock.Ctx.GetSession().AuthenticationLevel)
+}
+
+func (s *HandlerSignWebauthnSuite) TestShouldRejectClonedAuthenticator() {
+	s.mock.Ctx.Request.Header.Add("X-Forwarded-Proto", "https")
+	s.mock.Ctx.Request.Header.Add("X-Forwarded-Host", "login.example.com")
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	s.Require().NoError(err)
+
+	device := s.newWebauthnDevice(key, 5, false)
+
+	s.mock.StorageProviderMock.EXPECT().
+		LoadWebauthnDevicesByUsername(gomock.Eq(testUsername)).
+		Return([]models.WebauthnDevice{device}, nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		UpdateWebauthnDeviceSignIn(gomock.Eq(1), gomock.Any(), gomock.Eq(uint32(5)), gomock.Eq(true)).
+		Return(nil)
+
+	s.mock.Ctx.Request.SetBody(s.signWebauthnAssertion(key, device.KID, 5))
+
+	SecondFactorWebauthnAssertionPost(s.mock.Ctx)
+
+	assert.Equal(s.T(), 401, s.mock.Ctx.Response.StatusCode())
+	assert.Equal(s.T(), "Webauthn device 1 of user john may have been cloned", s.mock.Hook.LastEntry().Message)
+	assert.Equal(s.T(), authentication.NotAuthenticated, s.mock.Ctx.GetSession().AuthenticationLevel)
+}
+
+func (s *HandlerSignWebauthnSuite) TestShouldRejectAuthenticatorPreviouslyFlaggedAsCloned() {
+	s.mock.Ctx.Request.Header.Add("X-Forwarded-Proto", "https")
+	s.mock.Ctx.Request.Header.Add("X-Forwarded-Host", "login.example.com")
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	s.Require().NoError(err)
+
+	device := s.newWebauthnDevice(key, 5, true)
+
+	s.mock.StorageProviderMock.EXPECT().
+		LoadWebauthnDevicesByUsername(gomock.Eq(testUsername)).
+		Return([]models.WebauthnDevice{device}, nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		UpdateWebauthnDeviceSignIn(gomock.Eq(1), gomock.Any(), gomock.Any(), gomock.Eq(true)).
+		Return(nil)
+
+	s.mock.Ctx.Request.SetBody(s.signWebauthnAssertion(key, device.KID, 6))
+
+	SecondFactorWebauthnAssertionPost(s.mock.Ctx)
+
+	assert.Equal(s.T(), 401, s.mock.Ctx.Response.StatusCode())
+	assert.Equal(s.T(), authentication.NotAuthenticated, s.mock.Ctx.GetSession().AuthenticationLevel)
+}
+
+func (s *HandlerSignWebauthnSuite) TestShouldAllowClonedAuthenticatorWhenEnabled() {
+	s.mock.Ctx.Request.Header.Add("X-Forwarded-Proto", "https")
+	s.mock.Ctx.Request.Header.Add("X-Forwarded-Host", "login.example.com")
+	s.mock.Ctx.Configuration.Webauthn.AllowClonedAuthenticators = true
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	s.Require().NoError(err)
+
+	device := s.newWebauthnDevice(key, 5, false)
+
+	s.mock.StorageProviderMock.EXPECT().
+		LoadWebauthnDevicesByUsername(gomock.Eq(testUsername)).
+		Return([]models.WebauthnDevice{device}, nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		UpdateWebauthnDeviceSignIn(gomock.Eq(1), gomock.Any(), gomock.Eq(uint32(5)), gomock.Eq(true)).
+		Return(nil)
+
+	s.mock.Ctx.Request.SetBody(s.signWebauthnAssertion(key, device.KID, 4))
+
+	SecondFactorWebauthnAssertionPost(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+	assert.Equal(s.T(), authentication.TwoFactor, s.mock.Ctx.GetSession().AuthenticationLevel)
+}
+
+func TestShouldRunHandlerSignWebauthnSuite(t *testing.T) {
+	suite.Run(t, new(HandlerSignWebauthnSuite))
+}
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"github.com/authelia/authelia/internal/authentication"
 	"github.com/authelia/authelia/internal/middlewares"
 )
 
-// StateGet is the handler serving the user state.
+// StateGet is the handler serving the user state. The user is reported as authenticated with one factor when the second
+// factor authentication must be completed again so the login portal asks for it.
 func StateGet(ctx *middlewares.AutheliaCtx) {
 	userSession := ctx.GetSession()
 	stateResponse := StateResponse{
@@ -13,6 +15,10 @@ func StateGet(ctx *middlewares.AutheliaCtx) {
 		DefaultRedirectionURL: ctx.Configuration.DefaultRedirectionURL,
 	}
 
+	if userSession.SecondFactorReauthenticationRequired && stateResponse.AuthenticationLevel == authentication.TwoFactor {
+		stateResponse.AuthenticationLevel = authentication.OneFactor
+	}
+
 	err := ctx.SetJSONBody(stateResponse)
 	if err != nil {
 		ctx.Logger.Errorf("Unable to set state response in body: %s", err)
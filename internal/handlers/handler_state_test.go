@@ -86,6 +86,37 @@ func (s *StateGetSuite) TestShouldReturnAuthenticationLevelFromSession() {
 	assert.Equal(s.T(), expectedBody, actualBody)
 }
 
+func (s *StateGetSuite) TestShouldReturnOneFactorWhenSecondFactorReauthenticationIsRequired() {
+	userSession := s.mock.Ctx.GetSession()
+	userSession.Username = "username"
+	userSession.AuthenticationLevel = authentication.TwoFactor
+	userSession.SecondFactorReauthenticationRequired = true
+	err := s.mock.Ctx.SaveSession(userSession)
+	require.NoError(s.T(), err)
+
+	StateGet(s.mock.Ctx)
+
+	type Response struct {
+		Status string
+		Data   StateResponse
+	}
+
+	expectedBody := Response{
+		Status: "OK",
+		Data: StateResponse{
+			Username:              "username",
+			DefaultRedirectionURL: "",
+			AuthenticationLevel:   authentication.OneFactor,
+		},
+	}
+	actualBody := Response{}
+
+	err = json.Unmarshal(s.mock.Ctx.Response.Body(), &actualBody)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), expectedBody, actualBody)
+	assert.Equal(s.T(), authentication.TwoFactor, s.mock.Ctx.GetSession().AuthenticationLevel)
+}
+
 func TestRunStateGetSuite(t *testing.T) {
 	s := new(StateGetSuite)
 	suite.Run(t, s)
@@ -41,9 +41,9 @@ func loadInfo(username string, storageProvider storage.Provider, userInfo *UserI
 	go func() {
 		defer wg.Done()
 
-		_, _, err := storageProvider.LoadU2FDeviceHandle(username)
+		_, err := storageProvider.LoadWebauthnDevicesByUsername(username)
 		if err != nil {
-			if err == storage.ErrNoU2FDeviceHandle {
+			if err == storage.ErrNoWebauthnDevice {
 				return
 			}
 
@@ -53,15 +53,15 @@ func loadInfo(username string, storageProvider storage.Provider, userInfo *UserI
 			return
 		}
 
-		userInfo.HasU2F = true
+		userInfo.HasWebauthn = true
 	}()
 
 	go func() {
 		defer wg.Done()
 
-		_, err := storageProvider.LoadTOTPSecret(username)
+		_, err := storageProvider.LoadTOTPConfigurationsByUsername(username)
 		if err != nil {
-			if err == storage.ErrNoTOTPSecret {
+			if err == storage.ErrNoTOTPConfiguration {
 				return
 			}
 
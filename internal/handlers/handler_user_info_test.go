@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
 	"github.com/authelia/authelia/internal/storage"
 )
 
@@ -39,52 +40,50 @@ func setPreferencesExpectations(preferences UserInfo, provider *storage.MockProv
 		LoadPreferred2FAMethod(gomock.Eq("john")).
 		Return(preferences.Method, nil)
 
-	if preferences.HasU2F {
-		u2fData := []byte("abc")
+	if preferences.HasWebauthn {
 		provider.
 			EXPECT().
-			LoadU2FDeviceHandle(gomock.Eq("john")).
-			Return(u2fData, u2fData, nil)
+			LoadWebauthnDevicesByUsername(gomock.Eq("john")).
+			Return([]models.WebauthnDevice{{Username: "john", KID: []byte("abc"), PublicKey: []byte("abc")}}, nil)
 	} else {
 		provider.
 			EXPECT().
-			LoadU2FDeviceHandle(gomock.Eq("john")).
-			Return(nil, nil, storage.ErrNoU2FDeviceHandle)
+			LoadWebauthnDevicesByUsername(gomock.Eq("john")).
+			Return(nil, storage.ErrNoWebauthnDevice)
 	}
 
 	if preferences.HasTOTP {
-		totpSecret := "secret"
 		provider.
 			EXPECT().
-			LoadTOTPSecret(gomock.Eq("john")).
-			Return(totpSecret, nil)
+			LoadTOTPConfigurationsByUsername(gomock.Eq("john")).
+			Return([]models.TOTPConfiguration{{ID: 1, Username: "john", Description: "Primary", Secret: "secret"}}, nil)
 	} else {
 		provider.
 			EXPECT().
-			LoadTOTPSecret(gomock.Eq("john")).
-			Return("", storage.ErrNoTOTPSecret)
+			LoadTOTPConfigurationsByUsername(gomock.Eq("john")).
+			Return(nil, storage.ErrNoTOTPConfiguration)
 	}
 }
 
-func TestMethodSetToU2F(t *testing.T) {
+func TestMethodSetToWebauthn(t *testing.T) {
 	table := []UserInfo{
 		{
 			Method: "totp",
 		},
 		{
-			Method:  "u2f",
-			HasU2F:  true,
-			HasTOTP: true,
+			Method:      "webauthn",
+			HasWebauthn: true,
+			HasTOTP:     true,
 		},
 		{
-			Method:  "u2f",
-			HasU2F:  true,
-			HasTOTP: false,
+			Method:      "webauthn",
+			HasWebauthn: true,
+			HasTOTP:     false,
 		},
 		{
-			Method:  "mobile_push",
-			HasU2F:  false,
-			HasTOTP: false,
+			Method:      "mobile_push",
+			HasWebauthn: false,
+			HasTOTP:     false,
 		},
 	}
 
@@ -107,8 +106,8 @@ func TestMethodSetToU2F(t *testing.T) {
 			assert.Equal(t, expectedPreferences.Method, actualPreferences.Method)
 		})
 
-		t.Run("registered u2f", func(t *testing.T) {
-			assert.Equal(t, expectedPreferences.HasU2F, actualPreferences.HasU2F)
+		t.Run("registered webauthn", func(t *testing.T) {
+			assert.Equal(t, expectedPreferences.HasWebauthn, actualPreferences.HasWebauthn)
 		})
 
 		t.Run("registered totp", func(t *testing.T) {
@@ -126,13 +125,13 @@ func (s *FetchSuite) TestShouldGetDefaultPreferenceIfNotInDB() {
 
 	s.mock.StorageProviderMock.
 		EXPECT().
-		LoadU2FDeviceHandle(gomock.Eq("john")).
-		Return(nil, nil, storage.ErrNoU2FDeviceHandle)
+		LoadWebauthnDevicesByUsername(gomock.Eq("john")).
+		Return(nil, storage.ErrNoWebauthnDevice)
 
 	s.mock.StorageProviderMock.
 		EXPECT().
-		LoadTOTPSecret(gomock.Eq("john")).
-		Return("", storage.ErrNoTOTPSecret)
+		LoadTOTPConfigurationsByUsername(gomock.Eq("john")).
+		Return(nil, storage.ErrNoTOTPConfiguration)
 
 	UserInfoGet(s.mock.Ctx)
 	s.mock.Assert200OK(s.T(), UserInfo{Method: "totp"})
@@ -145,11 +144,11 @@ func (s *FetchSuite) TestShouldReturnError500WhenStorageFailsToLoad() {
 
 	s.mock.StorageProviderMock.
 		EXPECT().
-		LoadU2FDeviceHandle(gomock.Eq("john"))
+		LoadWebauthnDevicesByUsername(gomock.Eq("john"))
 
 	s.mock.StorageProviderMock.
 		EXPECT().
-		LoadTOTPSecret(gomock.Eq("john"))
+		LoadTOTPConfigurationsByUsername(gomock.Eq("john"))
 
 	UserInfoGet(s.mock.Ctx)
 
@@ -213,14 +212,14 @@ func (s *SaveSuite) TestShouldReturnError500WhenBadMethodProvided() {
 	MethodPreferencePost(s.mock.Ctx)
 
 	s.mock.Assert200KO(s.T(), "Operation failed.")
-	assert.Equal(s.T(), "Unknown method 'abc', it should be one of totp, u2f, mobile_push", s.mock.Hook.LastEntry().Message)
+	assert.Equal(s.T(), "Unknown method 'abc', it should be one of totp, webauthn, mobile_push", s.mock.Hook.LastEntry().Message)
 	assert.Equal(s.T(), logrus.ErrorLevel, s.mock.Hook.LastEntry().Level)
 }
 
 func (s *SaveSuite) TestShouldReturnError500WhenDatabaseFailsToSave() {
-	s.mock.Ctx.Request.SetBody([]byte("{\"method\":\"u2f\"}"))
+	s.mock.Ctx.Request.SetBody([]byte("{\"method\":\"webauthn\"}"))
 	s.mock.StorageProviderMock.EXPECT().
-		SavePreferred2FAMethod(gomock.Eq("john"), gomock.Eq("u2f")).
+		SavePreferred2FAMethod(gomock.Eq("john"), gomock.Eq("webauthn")).
 		Return(fmt.Errorf("Failure"))
 
 	MethodPreferencePost(s.mock.Ctx)
@@ -231,9 +230,9 @@ func (s *SaveSuite) TestShouldReturnError500WhenDatabaseFailsToSave() {
 }
 
 func (s *SaveSuite) TestShouldReturn200WhenMethodIsSuccessfullySaved() {
-	s.mock.Ctx.Request.SetBody([]byte("{\"method\":\"u2f\"}"))
+	s.mock.Ctx.Request.SetBody([]byte("{\"method\":\"webauthn\"}"))
 	s.mock.StorageProviderMock.EXPECT().
-		SavePreferred2FAMethod(gomock.Eq("john"), gomock.Eq("u2f")).
+		SavePreferred2FAMethod(gomock.Eq("john"), gomock.Eq("webauthn")).
 		Return(nil)
 
 	MethodPreferencePost(s.mock.Ctx)
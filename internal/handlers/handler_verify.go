@@ -3,12 +3,14 @@ package handlers
 import (
 	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
-	"net"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/valyala/fasthttp"
 
 	"github.com/authelia/authelia/internal/authentication"
@@ -53,21 +55,37 @@ func parseBasicAuth(header, auth string) (username, password string, err error)
 	return cs[:s], cs[s+1:], nil
 }
 
-// isTargetURLAuthorized check whether the given user is authorized to access the resource.
-func isTargetURLAuthorized(authorizer *authorization.Authorizer, targetURL url.URL,
-	username string, userGroups []string, clientIP net.IP, method []byte, authLevel authentication.Level) authorizationMatching {
-	level := authorizer.GetRequiredLevel(
-		authorization.Subject{
-			Username: username,
-			Groups:   userGroups,
-			IP:       clientIP,
-		},
-		authorization.NewObjectRaw(&targetURL, method))
+// verifyExplain explains which access control rule produced the authorization decision with a response header and a log
+// entry. The rules are evaluated a second time which is acceptable as it's only enabled for debugging.
+func verifyExplain(ctx *middlewares.AutheliaCtx, subject authorization.Subject, object authorization.Object, authorized authorizationMatching) {
+	position, policy := "default", authorization.LevelToPolicy(ctx.Providers.Authorizer.DefaultPolicy())
+
+	if rule := ctx.Providers.Authorizer.GetMatchingRule(subject, object); rule != nil {
+		position, policy = strconv.Itoa(rule.Position), authorization.LevelToPolicy(rule.Policy)
+	}
+
+	ctx.Response.Header.Set(autheliaExplainHeader, fmt.Sprintf("rule=%s; policy=%s; result=%s", position, policy, authorized))
+
+	ctx.Logger.WithFields(logrus.Fields{
+		"acl_rule":   position,
+		"acl_policy": policy,
+		"acl_result": authorized.String(),
+		"subject":    subject.String(),
+		"object":     object.String(),
+		"method":     object.Method,
+	}).Info("Access control decision explained")
+}
+
+// isTargetURLAuthorized check whether the given user is authorized to access the resource. The secondFactorAge is the
+// time elapsed since the second factor authentication of the user.
+func isTargetURLAuthorized(authorizer *authorization.Authorizer, subject authorization.Subject, object authorization.Object,
+	authLevel authentication.Level, secondFactorAge time.Duration) authorizationMatching {
+	level, maxAge := authorizer.GetRequiredLevelAndMaxAge(subject, object)
 
 	switch {
 	case level == authorization.Bypass:
 		return Authorized
-	case level == authorization.Denied && username != "":
+	case level == authorization.Denied && subject.Username != "":
 		// If the user is not anonymous, it means that we went through
 		// all the rules related to that user and knowing who he is we can
 		// deduce the access is forbidden
@@ -75,6 +93,8 @@ func isTargetURLAuthorized(authorizer *authorization.Authorizer, targetURL url.U
 		// could not be granted the rights to access the resource. Consequently
 		// for anonymous users we send Unauthorized instead of Forbidden
 		return Forbidden
+	case level == authorization.TwoFactor && authLevel >= authentication.TwoFactor && maxAge != 0 && secondFactorAge > maxAge:
+		return ReauthenticationRequired
 	case level == authorization.OneFactor && authLevel >= authentication.OneFactor,
 		level == authorization.TwoFactor && authLevel >= authentication.TwoFactor:
 		return Authorized
@@ -92,8 +112,20 @@ func verifyBasicAuth(header string, auth []byte, targetURL url.URL, ctx *middlew
 		return "", "", nil, nil, authentication.NotAuthenticated, fmt.Errorf("Unable to parse content of %s header: %s", header, err)
 	}
 
+	// The credentials are checked on every request, the regulation of the users who aren't banned is cached.
+	if bannedUntil, err := ctx.Providers.Regulator.RegulateCached(username, ctx.RemoteIP()); err != nil {
+		return "", "", nil, nil, authentication.NotAuthenticated, fmt.Errorf("Unable to regulate the credentials of user %s extracted from %s header: %s until %s", username, header, err, bannedUntil)
+	}
+
 	authenticated, err := ctx.Providers.UserProvider.CheckUserPassword(username, password)
 
+	// Only the failed attempts are marked as the credentials are checked on every request.
+	if err != nil || !authenticated {
+		if err := ctx.Providers.Regulator.Mark(username, ctx.RemoteIP(), false); err != nil {
+			ctx.Logger.Errorf("Unable to mark authentication: %s", err)
+		}
+	}
+
 	if err != nil {
 		return "", "", nil, nil, authentication.NotAuthenticated, fmt.Errorf("Unable to check credentials extracted from %s header: %s", header, err)
 	}
@@ -165,6 +197,8 @@ func verifySessionCookie(ctx *middlewares.AutheliaCtx, targetURL *url.URL, userS
 		}
 
 		if inactiveLongEnough {
+			oidcLogout(ctx, *userSession)
+
 			// Destroy the session a new one will be regenerated on next request.
 			err := ctx.Providers.SessionProvider.DestroySession(ctx.RequestCtx)
 			if err != nil {
@@ -177,10 +211,12 @@ func verifySessionCookie(ctx *middlewares.AutheliaCtx, targetURL *url.URL, userS
 
 	err = verifySessionHasUpToDateProfile(ctx, targetURL, userSession, refreshProfile, refreshProfileInterval)
 	if err != nil {
-		if err == authentication.ErrUserNotFound {
+		if errors.Is(err, authentication.ErrUserNotFound) || errors.Is(err, authentication.ErrAccountDisabled) {
+			oidcLogout(ctx, *userSession)
+
 			err = ctx.Providers.SessionProvider.DestroySession(ctx.RequestCtx)
 			if err != nil {
-				ctx.Logger.Error(fmt.Errorf("Unable to destroy user session after provider refresh didn't find the user: %s", err))
+				ctx.Logger.Error(fmt.Errorf("Unable to destroy user session after provider refresh didn't find the user or found it disabled: %s", err))
 			}
 
 			return userSession.Username, userSession.DisplayName, userSession.Groups, userSession.Emails, authentication.NotAuthenticated, err
@@ -236,6 +272,16 @@ func handleUnauthorized(ctx *middlewares.AutheliaCtx, targetURL fmt.Stringer, is
 	}
 }
 
+// requireSecondFactorReauthentication marks the session so the login portal asks the user to complete the second factor
+// authentication again, which refreshes the second factor timestamp. The authentication level of the session is kept
+// so the resources without a maximum age remain accessible in the meantime.
+func requireSecondFactorReauthentication(ctx *middlewares.AutheliaCtx) error {
+	userSession := ctx.GetSession()
+	userSession.SecondFactorReauthenticationRequired = true
+
+	return ctx.SaveSession(userSession)
+}
+
 func updateActivityTimestamp(ctx *middlewares.AutheliaCtx, isBasicAuth bool, username string) error {
 	if isBasicAuth || username == "" {
 		return nil
@@ -405,6 +451,8 @@ func verifyAuth(ctx *middlewares.AutheliaCtx, targetURL *url.URL, refreshProfile
 	if sessionUsername != nil && !strings.EqualFold(string(sessionUsername), username) {
 		ctx.Logger.Warnf("Possible cookie hijack or attempt to bypass security detected destroying the session and sending 401 response")
 
+		oidcLogout(ctx, userSession)
+
 		err = ctx.Providers.SessionProvider.DestroySession(ctx.RequestCtx)
 		if err != nil {
 			ctx.Logger.Error(
@@ -467,8 +515,23 @@ func VerifyGet(cfg schema.AuthenticationBackendConfiguration) middlewares.Reques
 			return
 		}
 
-		authorized := isTargetURLAuthorized(ctx.Providers.Authorizer, *targetURL, username,
-			groups, ctx.RemoteIP(), method, authLevel)
+		subject := authorization.Subject{
+			Username: username,
+			Groups:   groups,
+			Emails:   emails,
+			IP:       ctx.RemoteIP(),
+		}
+
+		object := authorization.NewObjectRaw(targetURL, method, ctx.Clock.Now())
+		object.Header = ctx.RequestHeaders()
+
+		var secondFactorAge time.Duration
+
+		if !isBasicAuth && authLevel >= authentication.TwoFactor {
+			secondFactorAge = ctx.Clock.Now().Sub(time.Unix(ctx.GetSession().SecondFactorAuthnTimestamp, 0))
+		}
+
+		authorized := isTargetURLAuthorized(ctx.Providers.Authorizer, subject, object, authLevel, secondFactorAge)
 
 		switch authorized {
 		case Forbidden:
@@ -476,10 +539,23 @@ func VerifyGet(cfg schema.AuthenticationBackendConfiguration) middlewares.Reques
 			ctx.ReplyForbidden()
 		case NotAuthorized:
 			handleUnauthorized(ctx, targetURL, isBasicAuth, username, method)
+		case ReauthenticationRequired:
+			if err := requireSecondFactorReauthentication(ctx); err != nil {
+				ctx.Error(fmt.Errorf("Unable to require the second factor authentication again: %s", err), operationFailedMessage)
+				return
+			}
+
+			ctx.Logger.Infof("Second factor authentication of user %s is too old to access %s", username, targetURL.String())
+			handleUnauthorized(ctx, targetURL, isBasicAuth, username, method)
 		case Authorized:
 			setForwardedHeaders(&ctx.Response.Header, username, name, groups, emails)
 		}
 
+		// The explanation is added once the response is written as replying with an error resets the headers.
+		if ctx.Providers.Authorizer.IsExplainEnabled(ctx.RemoteIP()) {
+			verifyExplain(ctx, subject, object, authorized)
+		}
+
 		if err := updateActivityTimestamp(ctx, isBasicAuth, username); err != nil {
 			ctx.Error(fmt.Errorf("Unable to update last activity: %s", err), operationFailedMessage)
 		}
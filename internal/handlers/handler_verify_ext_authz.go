@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/middlewares"
+)
+
+// ExtAuthzPath is the prefix of the path of the verify endpoint dedicated to the Envoy external authorization, the path
+// of the original request follows the prefix.
+const ExtAuthzPath = "/api/verify/ext-authz"
+
+// ExtAuthzForwardedHeaders are the headers of the response of the verify endpoint forwarded to the upstream when the
+// request is authorized.
+var ExtAuthzForwardedHeaders = []string{remoteUserHeader, remoteGroupsHeader, remoteNameHeader, remoteEmailHeader}
+
+// extAuthzOriginalRequestHeaders are the headers describing the original request. They are removed from the requests of
+// the external authorization before being set from the attributes of the original request so they can't be forged.
+var extAuthzOriginalRequestHeaders = []string{xOriginalURLHeader, xForwardedMethodHeader, "X-Forwarded-Host", "X-Forwarded-URI"}
+
+// ExtAuthzOriginalRequestURI returns the request URI of the original request of an external authorization request
+// made to the HTTP endpoint, i.e. the raw request URI following ExtAuthzPath. It returns false when the request URI
+// isn't the one of the HTTP endpoint. The raw request URI is used so the dot segments of the original request can't
+// move the request out of the endpoint once normalized.
+func ExtAuthzOriginalRequestURI(requestURI []byte) (originalRequestURI []byte, ok bool) {
+	if !bytes.HasPrefix(requestURI, []byte(ExtAuthzPath)) {
+		return nil, false
+	}
+
+	originalRequestURI = requestURI[len(ExtAuthzPath):]
+
+	switch {
+	case len(originalRequestURI) == 0:
+		return []byte("/"), true
+	case originalRequestURI[0] == '/':
+		return originalRequestURI, true
+	case originalRequestURI[0] == '?':
+		return append([]byte("/"), originalRequestURI...), true
+	default:
+		return nil, false
+	}
+}
+
+// SetExtAuthzOriginalRequest replaces the request URI of an external authorization request with ExtAuthzPath and sets
+// the headers describing the original request from its method, scheme, host and request URI. The original URL is left
+// unset when the scheme is unknown so the request is rejected.
+func SetExtAuthzOriginalRequest(request *fasthttp.Request, method, scheme, host, requestURI string) {
+	for _, header := range extAuthzOriginalRequestHeaders {
+		request.Header.Del(header)
+	}
+
+	request.SetRequestURI(ExtAuthzPath)
+	request.Header.SetMethod(fasthttp.MethodGet)
+	request.Header.Set(xForwardedMethodHeader, method)
+	request.Header.SetHost(host)
+
+	if scheme == "" {
+		return
+	}
+
+	if !strings.HasPrefix(requestURI, "/") {
+		requestURI = "/" + requestURI
+	}
+
+	request.Header.Set(fasthttp.HeaderXForwardedProto, scheme)
+	request.Header.Set(xOriginalURLHeader, scheme+"://"+host+requestURI)
+}
+
+// VerifyExtAuthz returns the handler verifying the requests of the Envoy external authorization once the original
+// request has been described with SetExtAuthzOriginalRequest. The query of the request is dropped so it can't alter the
+// verification and the login portal of the configuration is used as redirection URL.
+func VerifyExtAuthz(cfg schema.AuthenticationBackendConfiguration, autheliaURL string) middlewares.RequestHandler {
+	verify := VerifyGet(cfg)
+
+	return func(ctx *middlewares.AutheliaCtx) {
+		if ctx.XOriginalURL() == nil {
+			ctx.Logger.Error(fmt.Errorf("Unable to compute the original URL of the external authorization request: " +
+				"the scheme of the original request is missing"))
+			ctx.ReplyUnauthorized()
+
+			return
+		}
+
+		ctx.URI().SetQueryString("")
+
+		if autheliaURL != "" {
+			ctx.QueryArgs().Set("rd", autheliaURL)
+		}
+
+		verify(ctx)
+	}
+}
+
+// VerifyExtAuthzHTTP returns the handler verifying the requests of the Envoy external authorization HTTP service. Envoy
+// forwards the original request with its method, host, headers and the request URI appended to ExtAuthzPath. The scheme
+// is given by the X-Forwarded-Proto header.
+func VerifyExtAuthzHTTP(cfg schema.AuthenticationBackendConfiguration, autheliaURL string) middlewares.RequestHandler {
+	verify := VerifyExtAuthz(cfg, autheliaURL)
+
+	return func(ctx *middlewares.AutheliaCtx) {
+		originalRequestURI, ok := ExtAuthzOriginalRequestURI(ctx.Request.Header.RequestURI())
+		if !ok {
+			ctx.Logger.Error(fmt.Errorf("Unable to compute the original URL of the external authorization request: "+
+				"the request URI %s isn't under %s", ctx.Request.Header.RequestURI(), ExtAuthzPath))
+			ctx.ReplyUnauthorized()
+
+			return
+		}
+
+		SetExtAuthzOriginalRequest(&ctx.Request, string(ctx.Method()), string(ctx.XForwardedProto()),
+			string(ctx.Request.Host()), string(originalRequestURI))
+
+		verify(ctx)
+	}
+}
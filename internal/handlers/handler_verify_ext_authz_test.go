@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/mocks"
+)
+
+func TestShouldRaiseWhenExtAuthzRequestHasNoForwardedProto(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Request.SetRequestURI("/api/verify/ext-authz/abc")
+	mock.Ctx.Request.SetHost("one-factor.example.com")
+
+	VerifyExtAuthzHTTP(verifyGetCfg, "")(mock.Ctx)
+
+	assert.Equal(t, 401, mock.Ctx.Response.StatusCode())
+}
+
+func TestShouldVerifyExtAuthzRequestUsingSessionCookie(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Clock.Set(time.Now())
+
+	userSession := mock.Ctx.GetSession()
+	userSession.Username = testUsername
+	userSession.Groups = []string{"dev", "admins"}
+	userSession.Emails = []string{"john.doe@example.com"}
+	userSession.AuthenticationLevel = authentication.OneFactor
+	userSession.RefreshTTL = mock.Clock.Now().Add(5 * time.Minute)
+
+	err := mock.Ctx.SaveSession(userSession)
+	require.NoError(t, err)
+
+	// The headers provided by the client must not alter the original URL.
+	mock.Ctx.Request.Header.Set("X-Original-URL", "https://two-factor.example.com")
+	mock.Ctx.Request.Header.Set("X-Forwarded-Proto", "https")
+	mock.Ctx.Request.Header.SetMethod("POST")
+	mock.Ctx.Request.SetRequestURI("/api/verify/ext-authz/abc?auth=basic")
+	mock.Ctx.Request.SetHost("one-factor.example.com")
+
+	VerifyExtAuthzHTTP(verifyGetCfg, "")(mock.Ctx)
+
+	assert.Equal(t, 200, mock.Ctx.Response.StatusCode())
+	assert.Equal(t, "https://one-factor.example.com/abc?auth=basic", string(mock.Ctx.Request.Header.Peek("X-Original-URL")))
+	assert.Equal(t, "POST", string(mock.Ctx.Request.Header.Peek("X-Forwarded-Method")))
+	assert.Equal(t, testUsername, string(mock.Ctx.Response.Header.Peek("Remote-User")))
+	assert.Equal(t, "dev,admins", string(mock.Ctx.Response.Header.Peek("Remote-Groups")))
+}
+
+func TestShouldRedirectExtAuthzRequestToAutheliaURL(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Clock.Set(time.Now())
+
+	mock.Ctx.Request.Header.Set("X-Forwarded-Proto", "https")
+	mock.Ctx.Request.SetRequestURI("/api/verify/ext-authz/?rd=https://evil.example.com")
+	mock.Ctx.Request.SetHost("two-factor.example.com")
+
+	VerifyExtAuthzHTTP(verifyGetCfg, "https://auth.example.com")(mock.Ctx)
+
+	assert.Equal(t, 302, mock.Ctx.Response.StatusCode())
+	assert.Equal(t, "https://auth.example.com/?rd=https%3A%2F%2Ftwo-factor.example.com%2F%3Frd%3Dhttps%3A%2F%2Fevil.example.com&rm=GET",
+		string(mock.Ctx.Response.Header.Peek("Location")))
+}
+
+func TestShouldKeepDotSegmentsOfExtAuthzOriginalRequestURI(t *testing.T) {
+	testCases := []struct {
+		requestURI string
+		expected   string
+	}{
+		{"/api/verify/ext-authz", "/"},
+		{"/api/verify/ext-authz?x=1", "/?x=1"},
+		{"/api/verify/ext-authz/abc?x=1", "/abc?x=1"},
+		{"/api/verify/ext-authz/../../health", "/../../health"},
+		{"/api/verify/ext-authz/%2e%2e/%2e%2e/x", "/%2e%2e/%2e%2e/x"},
+		{"/api/verify/ext-authz//evil.example.com/x", "//evil.example.com/x"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.requestURI, func(t *testing.T) {
+			originalRequestURI, ok := ExtAuthzOriginalRequestURI([]byte(tc.requestURI))
+
+			assert.True(t, ok)
+			assert.Equal(t, tc.expected, string(originalRequestURI))
+		})
+	}
+
+	for _, requestURI := range []string{"/api/verify/ext-authzabc", "/api/verify", "/api/health"} {
+		_, ok := ExtAuthzOriginalRequestURI([]byte(requestURI))
+		assert.False(t, ok, requestURI)
+	}
+}
+
+func TestShouldVerifyExtAuthzRequestWithDotSegmentsAgainstOriginalURL(t *testing.T) {
+	for _, path := range []string{"/../../health", "/%2e%2e/%2e%2e/x", "//x"} {
+		t.Run(path, func(t *testing.T) {
+			mock := mocks.NewMockAutheliaCtx(t)
+			defer mock.Close()
+
+			mock.Clock.Set(time.Now())
+
+			mock.Ctx.Request.Header.Set("X-Forwarded-Proto", "https")
+			mock.Ctx.Request.Header.Set("X-Forwarded-Method", "GET")
+			mock.Ctx.Request.SetRequestURI("/api/verify/ext-authz" + path)
+			mock.Ctx.Request.SetHost("two-factor.example.com")
+
+			VerifyExtAuthzHTTP(verifyGetCfg, "https://auth.example.com")(mock.Ctx)
+
+			assert.Equal(t, 302, mock.Ctx.Response.StatusCode())
+			assert.Equal(t, "https://two-factor.example.com"+path, string(mock.Ctx.Request.Header.Peek("X-Original-URL")))
+			assert.Equal(t, ExtAuthzPath+"?rd=https%3A%2F%2Fauth.example.com", string(mock.Ctx.Request.RequestURI()))
+		})
+	}
+}
+
+func TestShouldRaiseWhenExtAuthzOriginalURLIsMissing(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	// The original URL must be computed from the attributes of the original request, not given by the client.
+	mock.Ctx.Request.Header.Set("X-Original-URL", "https://bypass.example.com")
+	mock.Ctx.Request.SetRequestURI(ExtAuthzPath)
+	SetExtAuthzOriginalRequest(&mock.Ctx.Request, "GET", "", "bypass.example.com", "/")
+
+	VerifyExtAuthz(verifyGetCfg, "")(mock.Ctx)
+
+	assert.Equal(t, 401, mock.Ctx.Response.StatusCode())
+}
@@ -1,17 +1,27 @@
 package handlers
 
 import (
-	"github.com/ory/fosite/handler/openid"
-	"github.com/ory/fosite/token/jwt"
+	"encoding/json"
 
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
 	"github.com/authelia/authelia/internal/oidc"
 	"github.com/authelia/authelia/internal/session"
+	"github.com/authelia/authelia/internal/storage"
 	"github.com/authelia/authelia/internal/utils"
 )
 
-// isConsentMissing compares the requestedScopes and requestedAudience to the workflows
-// GrantedScopes and GrantedAudience and returns true if they do not match or the workflow is nil.
-func isConsentMissing(workflow *session.OIDCWorkflowSession, requestedScopes, requestedAudience []string) (isMissing bool) {
+// isConsentMissing compares the requestedScopes and requestedAudience to the consents pre-configured by the user and
+// to the workflows GrantedScopes and GrantedAudience, and returns true if none of them grant everything requested.
+func isConsentMissing(workflow *session.OIDCWorkflowSession, consents []models.OAuth2ConsentSession, requestedScopes, requestedAudience []string) (isMissing bool) {
+	for _, consent := range consents {
+		if consent.Grants(requestedScopes, requestedAudience) {
+			return false
+		}
+	}
+
 	if workflow == nil {
 		return true
 	}
@@ -20,13 +30,66 @@ func isConsentMissing(workflow *session.OIDCWorkflowSession, requestedScopes, re
 		len(requestedAudience) > 0 && utils.IsStringSlicesDifferentFold(requestedAudience, workflow.GrantedAudience)
 }
 
+// isOIDCConsentRequired returns true if the user must be prompted for consent according to the consent mode of the
+// client.
+func isOIDCConsentRequired(ctx *middlewares.AutheliaCtx, client *oidc.InternalClient, workflow *session.OIDCWorkflowSession,
+	subject string, requestedScopes, requestedAudience []string) (required bool) {
+	switch client.ConsentMode {
+	case oidc.ClientConsentModeImplicit:
+		return false
+	case oidc.ClientConsentModePreConfigured:
+		consents, err := loadOIDCConsentSessions(ctx, client.ID, subject)
+		if err != nil {
+			ctx.Logger.Errorf("Unable to load the pre-configured consent of user %s for client %s: %v", subject, client.ID, err)
+		}
+
+		return isConsentMissing(workflow, consents, requestedScopes, requestedAudience)
+	default:
+		return isConsentMissing(workflow, nil, requestedScopes, requestedAudience)
+	}
+}
+
+// loadOIDCConsentSessions loads the consents of the subject for the client which have not expired yet.
+func loadOIDCConsentSessions(ctx *middlewares.AutheliaCtx, clientID, subject string) (consents []models.OAuth2ConsentSession, err error) {
+	all, err := ctx.Providers.StorageProvider.LoadOAuth2ConsentSessionsBySubject(subject, ctx.Clock.Now())
+	if err != nil {
+		if err == storage.ErrNoOAuth2ConsentSession {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	for _, consent := range all {
+		if consent.ClientID == clientID {
+			consents = append(consents, consent)
+		}
+	}
+
+	return consents, nil
+}
+
 func newOpenIDSession(subject string) *oidc.OpenIDSession {
-	return &oidc.OpenIDSession{
-		DefaultSession: &openid.DefaultSession{
-			Claims:  new(jwt.IDTokenClaims),
-			Headers: new(jwt.Headers),
-			Subject: subject,
-		},
-		Extra: map[string]interface{}{},
+	session := oidc.NewSession()
+	session.Subject = subject
+
+	return session
+}
+
+// oidcWriteError writes an OAuth 2.0 error response for the endpoints which aren't handled by fosite.
+func oidcWriteError(ctx *middlewares.AutheliaCtx, statusCode int, code, description string) {
+	oidcWriteJSON(ctx, statusCode, oidcErrorResponseBody{Error: code, ErrorDescription: description})
+}
+
+// oidcWriteJSON writes a JSON response which must not be cached.
+func oidcWriteJSON(ctx *middlewares.AutheliaCtx, statusCode int, body interface{}) {
+	ctx.Response.Header.Set("Cache-Control", "no-store")
+	ctx.Response.Header.Set("Pragma", "no-cache")
+	ctx.SetContentType("application/json")
+	ctx.SetStatusCode(statusCode)
+
+	if err := json.NewEncoder(ctx).Encode(body); err != nil {
+		ctx.Logger.Errorf("Error occurred in json Encode: %+v", err)
+		ctx.Response.SetStatusCode(fasthttp.StatusInternalServerError)
 	}
 }
@@ -6,7 +6,7 @@ import (
 	"github.com/authelia/authelia/internal/middlewares"
 )
 
-// RegisterOIDC registers the handlers with the fasthttp *router.Router. TODO: Add paths for UserInfo, Flush, Logout.
+// RegisterOIDC registers the handlers with the fasthttp *router.Router. TODO: Add paths for Flush.
 func RegisterOIDC(router *router.Router, middleware middlewares.RequestHandlerBridge) {
 	// TODO: Add OPTIONS handler.
 	router.GET("/.well-known/openid-configuration", middleware(oidcWellKnown))
@@ -15,6 +15,23 @@ func RegisterOIDC(router *router.Router, middleware middlewares.RequestHandlerBr
 
 	router.POST(oidcConsentPath, middleware(oidcConsentPOST))
 
+	router.GET(oidcConsentSessionsPath, middleware(middlewares.RequireFirstFactor(OIDCConsentSessionsGet)))
+	router.DELETE(oidcConsentSessionsPath+"/{id}", middleware(middlewares.RequireFirstFactor(OIDCConsentSessionDelete)))
+
+	router.GET(oidcDevicePath, middleware(middlewares.RequireFirstFactor(OIDCDeviceGet)))
+	router.POST(oidcDevicePath, middleware(middlewares.RequireFirstFactor(OIDCDevicePost)))
+
+	router.GET(oidcClientsPath, middleware(middlewares.RequireSecondFactor(oidcRequireClientAdministrator(OIDCClientsGet))))
+	router.POST(oidcClientsPath, middleware(middlewares.RequireSecondFactor(oidcRequireClientAdministrator(OIDCClientsPost))))
+	router.PUT(oidcClientsPath+"/{id}", middleware(middlewares.RequireSecondFactor(oidcRequireClientAdministrator(OIDCClientPut))))
+	router.DELETE(oidcClientsPath+"/{id}", middleware(middlewares.RequireSecondFactor(oidcRequireClientAdministrator(OIDCClientDelete))))
+	router.POST(oidcClientsPath+"/{id}/secret", middleware(middlewares.RequireSecondFactor(oidcRequireClientAdministrator(OIDCClientSecretPost))))
+
+	router.POST(oidcRegistrationPath, middleware(OIDCRegistrationPost))
+
+	router.GET(oidcEndSessionPath, middleware(oidcEndSession))
+	router.POST(oidcEndSessionPath, middleware(oidcEndSession))
+
 	router.GET(oidcJWKsPath, middleware(oidcJWKs))
 
 	router.GET(oidcAuthorizePath, middleware(middlewares.NewHTTPToAutheliaHandlerAdaptor(oidcAuthorize)))
@@ -22,6 +39,10 @@ func RegisterOIDC(router *router.Router, middleware middlewares.RequestHandlerBr
 	// TODO: Add OPTIONS handler.
 	router.POST(oidcTokenPath, middleware(middlewares.NewHTTPToAutheliaHandlerAdaptor(oidcToken)))
 
+	router.POST(oidcDeviceAuthorizationPath, middleware(middlewares.NewHTTPToAutheliaHandlerAdaptor(oidcDeviceAuthorization)))
+
+	router.POST(oidcPushedAuthorizationRequestPath, middleware(middlewares.NewHTTPToAutheliaHandlerAdaptor(oidcPushedAuthorization)))
+
 	router.POST(oidcIntrospectPath, middleware(middlewares.NewHTTPToAutheliaHandlerAdaptor(oidcIntrospect)))
 
 	router.GET(oidcUserinfoPath, middleware(middlewares.NewHTTPToAutheliaHandlerAdaptor(oidcUserinfo)))
@@ -1,10 +1,21 @@
 package handlers
 
 import (
+	"errors"
+	"net"
+	"net/url"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/authelia/authelia/internal/authorization"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/oidc"
 	"github.com/authelia/authelia/internal/session"
 )
 
@@ -14,20 +25,139 @@ func TestShouldDetectIfConsentIsMissing(t *testing.T) {
 	requestedScopes := []string{"openid", "profile"}
 	requestedAudience := []string{"https://authelia.com"}
 
-	assert.True(t, isConsentMissing(workflow, requestedScopes, requestedAudience))
+	assert.True(t, isConsentMissing(workflow, nil, requestedScopes, requestedAudience))
 
 	workflow = &session.OIDCWorkflowSession{
 		GrantedScopes:   []string{"openid", "profile"},
 		GrantedAudience: []string{"https://authelia.com"},
 	}
 
-	assert.False(t, isConsentMissing(workflow, requestedScopes, requestedAudience))
+	assert.False(t, isConsentMissing(workflow, nil, requestedScopes, requestedAudience))
 
 	requestedScopes = []string{"openid", "profile", "group"}
 
-	assert.True(t, isConsentMissing(workflow, requestedScopes, requestedAudience))
+	assert.True(t, isConsentMissing(workflow, nil, requestedScopes, requestedAudience))
 
 	requestedScopes = []string{"openid", "profile"}
 	requestedAudience = []string{"https://not.authelia.com"}
-	assert.True(t, isConsentMissing(workflow, requestedScopes, requestedAudience))
+	assert.True(t, isConsentMissing(workflow, nil, requestedScopes, requestedAudience))
+}
+
+func TestShouldDetectIfConsentIsPreConfigured(t *testing.T) {
+	consents := []models.OAuth2ConsentSession{
+		{
+			GrantedScopes:   []string{"openid", "profile", "groups"},
+			GrantedAudience: []string{"https://authelia.com"},
+		},
+	}
+
+	requestedScopes := []string{"openid", "profile"}
+	requestedAudience := []string{"https://AUTHELIA.com"}
+
+	assert.False(t, isConsentMissing(nil, consents, requestedScopes, requestedAudience))
+
+	requestedScopes = []string{"openid", "profile", "email"}
+
+	assert.True(t, isConsentMissing(nil, consents, requestedScopes, requestedAudience))
+
+	workflow := &session.OIDCWorkflowSession{
+		GrantedScopes:   []string{"openid", "profile", "email"},
+		GrantedAudience: []string{"https://authelia.com"},
+	}
+
+	assert.False(t, isConsentMissing(workflow, consents, requestedScopes, requestedAudience))
+}
+
+func TestShouldApplyClaimsPolicyOfClient(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	client := &oidc.InternalClient{
+		ID: "grafana",
+		ClaimsPolicy: oidc.NewClaimsPolicy(schema.OpenIDConnectClaimsPolicyConfiguration{
+			Name: "grafana",
+			CustomClaims: []schema.OpenIDConnectCustomClaimConfiguration{
+				{
+					Name:  "role",
+					Scope: "groups",
+					Roles: []schema.OpenIDConnectRoleMappingConfiguration{
+						{Group: "admins", Role: "Admin"},
+					},
+				},
+				{Name: "employee_number", Scope: "profile", Attribute: "employeeNumber"},
+			},
+			RenameClaims: []schema.OpenIDConnectRenameClaimConfiguration{
+				{Claim: "groups", Name: "teams"},
+			},
+		}),
+	}
+
+	userSession := &session.UserSession{
+		Username:    "john",
+		DisplayName: "John Doe",
+		Groups:      []string{"admins", "dev"},
+	}
+
+	mock.UserProviderMock.EXPECT().
+		GetAttributes(gomock.Eq("john"), gomock.Eq([]string{"employeeNumber"})).
+		Return(map[string][]string{"employeeNumber": {"1234"}}, nil)
+
+	claims, err := oidcExtraClaims(mock.Ctx, client, []string{"openid", "profile", "groups"}, userSession)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"name":            "John Doe",
+		"teams":           []string{"admins", "dev"},
+		"role":            "Admin",
+		"employee_number": "1234",
+	}, claims)
+
+	// The user provider is not queried when the scopes of the attribute claims are not granted.
+	claims, err = oidcExtraClaims(mock.Ctx, client, []string{"openid", "groups"}, userSession)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"teams": []string{"admins", "dev"},
+		"role":  "Admin",
+	}, claims)
+
+	mock.UserProviderMock.EXPECT().
+		GetAttributes(gomock.Eq("john"), gomock.Eq([]string{"employeeNumber"})).
+		Return(nil, errors.New("connection failed"))
+
+	_, err = oidcExtraClaims(mock.Ctx, client, []string{"openid", "profile"}, userSession)
+	assert.EqualError(t, err, "connection failed")
+}
+
+func TestShouldDenyOIDCClientByAccessControl(t *testing.T) {
+	authorizer := authorization.NewAuthorizer(&schema.Configuration{
+		AccessControl: schema.AccessControlConfiguration{
+			DefaultPolicy: "deny",
+			Rules: []schema.ACLRule{{
+				Domains:  []string{"grafana.example.com"},
+				Policy:   "deny",
+				Subjects: [][]string{{"oidc:grafana", "group:contractors"}, {"oidc:grafana", "!email:*@example.com"}},
+			}, {
+				Domains: []string{"grafana.example.com"},
+				Policy:  "deny",
+			}},
+		}})
+
+	redirectURI, err := url.Parse("https://grafana.example.com/login/generic_oauth")
+	require.NoError(t, err)
+
+	ip := net.ParseIP("10.0.0.1")
+
+	john := &session.UserSession{Username: "john", Groups: []string{"dev"}, Emails: []string{"john@example.com"}}
+	fred := &session.UserSession{Username: "fred", Groups: []string{"contractors"}, Emails: []string{"fred@example.com"}}
+	mary := &session.UserSession{Username: "mary", Groups: []string{"dev"}, Emails: []string{"mary@example.org"}}
+
+	assert.False(t, isOIDCClientDeniedByAccessControl(authorizer, "grafana", redirectURI, john, ip, time.Now()))
+	assert.True(t, isOIDCClientDeniedByAccessControl(authorizer, "grafana", redirectURI, fred, ip, time.Now()))
+	assert.True(t, isOIDCClientDeniedByAccessControl(authorizer, "grafana", redirectURI, mary, ip, time.Now()))
+
+	// The rules without client subjects and the default policy don't apply to the clients.
+	assert.False(t, isOIDCClientDeniedByAccessControl(authorizer, "other", redirectURI, fred, ip, time.Now()))
+	assert.False(t, isOIDCClientDeniedByAccessControl(authorizer, "grafana", &url.URL{Scheme: "https", Host: "app.example.com"}, fred, ip, time.Now()))
+	assert.False(t, isOIDCClientDeniedByAccessControl(authorizer, "grafana", nil, fred, ip, time.Now()))
 }
@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"github.com/tstranex/u2f"
+	"time"
 
 	"github.com/authelia/authelia/internal/authentication"
 )
@@ -11,6 +11,22 @@ type MethodList = []string
 
 type authorizationMatching int
 
+// String returns a string representation of the authorizationMatching.
+func (m authorizationMatching) String() string {
+	switch m {
+	case Forbidden:
+		return "forbidden"
+	case NotAuthorized:
+		return "not authorized"
+	case Authorized:
+		return "authorized"
+	case ReauthenticationRequired:
+		return "reauthentication required"
+	default:
+		return "unknown"
+	}
+}
+
 // UserInfo is the model of user info and second factor preferences.
 type UserInfo struct {
 	// The users display name.
@@ -20,7 +36,7 @@ type UserInfo struct {
 	Method string `json:"method" valid:"required"`
 
 	// True if a security key has been registered.
-	HasU2F bool `json:"has_u2f" valid:"required"`
+	HasWebauthn bool `json:"has_webauthn" valid:"required"`
 
 	// True if a TOTP device has been registered.
 	HasTOTP bool `json:"has_totp" valid:"required"`
@@ -32,10 +48,30 @@ type signTOTPRequestBody struct {
 	TargetURL string `json:"targetURL"`
 }
 
-// signU2FRequestBody model of the request body of U2F authentication endpoint.
-type signU2FRequestBody struct {
-	SignResponse u2f.SignResponse `json:"signResponse"`
-	TargetURL    string           `json:"targetURL"`
+// signWebauthnRequestBody model of the request body of Webauthn authentication endpoint. The credential assertion
+// response is parsed separately from the same body.
+type signWebauthnRequestBody struct {
+	TargetURL string `json:"targetURL"`
+}
+
+// registerTOTPRequestBody model of the optional fields of the TOTP registration request body. The identity
+// verification token is parsed separately from the same body.
+type registerTOTPRequestBody struct {
+	Description string `json:"description"`
+}
+
+// registerWebauthnRequestBody model of the optional fields of the Webauthn attestation request body. The credential
+// creation response is parsed separately from the same body.
+type registerWebauthnRequestBody struct {
+	Description string `json:"description"`
+}
+
+// secondFactorDeviceResponse is the model of a registered TOTP configuration or Webauthn device sent to the client.
+type secondFactorDeviceResponse struct {
+	ID          int        `json:"id"`
+	Description string     `json:"description"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
 }
 
 type signDuoRequestBody struct {
@@ -81,3 +117,20 @@ type resetPasswordStep1RequestBody struct {
 type resetPasswordStep2RequestBody struct {
 	Password string `json:"password"`
 }
+
+// regulationBanRequestBody is the model of a ban made by an administrator.
+type regulationBanRequestBody struct {
+	Type     string `json:"type" valid:"required"`
+	Value    string `json:"value" valid:"required"`
+	Duration string `json:"duration"`
+	Reason   string `json:"reason"`
+}
+
+// regulationBanResponse is the model of an active ban sent to an administrator.
+type regulationBanResponse struct {
+	Type        string    `json:"type"`
+	Value       string    `json:"value"`
+	Reason      string    `json:"reason"`
+	BannedUntil time.Time `json:"banned_until"`
+	Failures    int       `json:"failures"`
+}
@@ -1,5 +1,9 @@
 package handlers
 
+import (
+	"time"
+)
+
 // ConsentPostRequestBody schema of the request body of the consent POST endpoint.
 type ConsentPostRequestBody struct {
 	ClientID       string `json:"client_id"`
@@ -10,3 +14,128 @@ type ConsentPostRequestBody struct {
 type ConsentPostResponseBody struct {
 	RedirectURI string `json:"redirect_uri"`
 }
+
+// DevicePostRequestBody schema of the request body of the device POST endpoint.
+type DevicePostRequestBody struct {
+	UserCode       string `json:"user_code"`
+	AcceptOrReject string `json:"accept_or_reject"`
+}
+
+// oidcConsentSessionResponse is the model of a consent pre-configured by the user sent to the client.
+type oidcConsentSessionResponse struct {
+	ID                int       `json:"id"`
+	ClientID          string    `json:"client_id"`
+	ClientDescription string    `json:"client_description"`
+	Scopes            []string  `json:"scopes"`
+	Audience          []string  `json:"audience"`
+	CreatedAt         time.Time `json:"created_at"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// oidcClientRequestBody is the model of a client registered at runtime sent by an administrator.
+type oidcClientRequestBody struct {
+	ID                           string   `json:"id"`
+	Description                  string   `json:"description"`
+	RedirectURIs                 []string `json:"redirect_uris"`
+	Policy                       string   `json:"authorization_policy"`
+	Scopes                       []string `json:"scopes"`
+	Audience                     []string `json:"audience"`
+	GrantTypes                   []string `json:"grant_types"`
+	ResponseTypes                []string `json:"response_types"`
+	ResponseModes                []string `json:"response_modes"`
+	IDTokenSigningAlgorithm      string   `json:"id_token_signing_algorithm"`
+	UserinfoSigningAlgorithm     string   `json:"userinfo_signing_algorithm"`
+	ConsentMode                  string   `json:"consent_mode"`
+	PreConfiguredConsentDuration int64    `json:"pre_configured_consent_duration"`
+	TokenEndpointAuthMethod      string   `json:"token_endpoint_auth_method"`
+	RequirePushedAuthorization   bool     `json:"require_pushed_authorization_requests"`
+	RequirePKCE                  bool     `json:"require_pkce"`
+	RequestObjectSigningAlg      string   `json:"request_object_signing_algorithm"`
+	RequestURIs                  []string `json:"request_uris"`
+	JWKSURI                      string   `json:"jwks_uri"`
+	PostLogoutRedirectURIs       []string `json:"post_logout_redirect_uris"`
+	FrontChannelLogoutURI        string   `json:"frontchannel_logout_uri"`
+	BackChannelLogoutURI         string   `json:"backchannel_logout_uri"`
+}
+
+// oidcClientResponse is the model of a client registered at runtime sent to an administrator. The secret is only
+// included when it has just been generated.
+type oidcClientResponse struct {
+	ID                           string    `json:"id"`
+	Secret                       string    `json:"secret,omitempty"`
+	Description                  string    `json:"description"`
+	RedirectURIs                 []string  `json:"redirect_uris"`
+	Policy                       string    `json:"authorization_policy"`
+	Scopes                       []string  `json:"scopes"`
+	Audience                     []string  `json:"audience"`
+	GrantTypes                   []string  `json:"grant_types"`
+	ResponseTypes                []string  `json:"response_types"`
+	ResponseModes                []string  `json:"response_modes"`
+	IDTokenSigningAlgorithm      string    `json:"id_token_signing_algorithm"`
+	UserinfoSigningAlgorithm     string    `json:"userinfo_signing_algorithm"`
+	ConsentMode                  string    `json:"consent_mode"`
+	PreConfiguredConsentDuration int64     `json:"pre_configured_consent_duration"`
+	TokenEndpointAuthMethod      string    `json:"token_endpoint_auth_method"`
+	RequirePushedAuthorization   bool      `json:"require_pushed_authorization_requests"`
+	RequirePKCE                  bool      `json:"require_pkce"`
+	RequestObjectSigningAlg      string    `json:"request_object_signing_algorithm"`
+	RequestURIs                  []string  `json:"request_uris"`
+	JWKSURI                      string    `json:"jwks_uri"`
+	PostLogoutRedirectURIs       []string  `json:"post_logout_redirect_uris"`
+	FrontChannelLogoutURI        string    `json:"frontchannel_logout_uri"`
+	BackChannelLogoutURI         string    `json:"backchannel_logout_uri"`
+	CreatedAt                    time.Time `json:"created_at"`
+	UpdatedAt                    time.Time `json:"updated_at"`
+}
+
+// oidcRegistrationRequestBody is the client metadata of a dynamic client registration request, see RFC 7591 section
+// 2.
+type oidcRegistrationRequestBody struct {
+	RedirectURIs               []string `json:"redirect_uris"`
+	ClientName                 string   `json:"client_name"`
+	GrantTypes                 []string `json:"grant_types"`
+	ResponseTypes              []string `json:"response_types"`
+	Scope                      string   `json:"scope"`
+	TokenEndpointAuthMethod    string   `json:"token_endpoint_auth_method"`
+	IDTokenSignedResponseAlg   string   `json:"id_token_signed_response_alg"`
+	UserinfoSignedResponseAlg  string   `json:"userinfo_signed_response_alg"`
+	RequestObjectSigningAlg    string   `json:"request_object_signing_alg"`
+	RequestURIs                []string `json:"request_uris"`
+	JWKSURI                    string   `json:"jwks_uri"`
+	RequirePushedAuthorization bool     `json:"require_pushed_authorization_requests"`
+	PostLogoutRedirectURIs     []string `json:"post_logout_redirect_uris"`
+	FrontChannelLogoutURI      string   `json:"frontchannel_logout_uri"`
+	BackChannelLogoutURI       string   `json:"backchannel_logout_uri"`
+}
+
+// oidcRegistrationResponseBody is the client information response of a dynamic client registration request, see
+// RFC 7591 section 3.2.1.
+type oidcRegistrationResponseBody struct {
+	ClientID              string `json:"client_id"`
+	ClientSecret          string `json:"client_secret"`
+	ClientIDIssuedAt      int64  `json:"client_id_issued_at"`
+	ClientSecretExpiresAt int64  `json:"client_secret_expires_at"`
+
+	RedirectURIs               []string `json:"redirect_uris"`
+	ClientName                 string   `json:"client_name"`
+	GrantTypes                 []string `json:"grant_types"`
+	ResponseTypes              []string `json:"response_types"`
+	Scope                      string   `json:"scope"`
+	TokenEndpointAuthMethod    string   `json:"token_endpoint_auth_method"`
+	IDTokenSignedResponseAlg   string   `json:"id_token_signed_response_alg"`
+	UserinfoSignedResponseAlg  string   `json:"userinfo_signed_response_alg"`
+	RequestObjectSigningAlg    string   `json:"request_object_signing_alg"`
+	RequestURIs                []string `json:"request_uris"`
+	JWKSURI                    string   `json:"jwks_uri"`
+	RequirePushedAuthorization bool     `json:"require_pushed_authorization_requests"`
+	PostLogoutRedirectURIs     []string `json:"post_logout_redirect_uris"`
+	FrontChannelLogoutURI      string   `json:"frontchannel_logout_uri"`
+	BackChannelLogoutURI       string   `json:"backchannel_logout_uri"`
+}
+
+// oidcErrorResponseBody is the error response of the OpenID Connect endpoints which aren't handled by fosite such as
+// the dynamic client registration, see RFC 7591 section 3.2.2.
+type oidcErrorResponseBody struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
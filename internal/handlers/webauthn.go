@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/duo-labs/webauthn/protocol"
+	"github.com/duo-labs/webauthn/webauthn"
+
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/session"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+func getWebAuthnUser(ctx *middlewares.AutheliaCtx, userSession session.UserSession) (user *models.WebauthnUser, err error) {
+	user = &models.WebauthnUser{
+		Username:    userSession.Username,
+		DisplayName: userSession.DisplayName,
+	}
+
+	if user.DisplayName == "" {
+		user.DisplayName = user.Username
+	}
+
+	if user.Devices, err = ctx.Providers.StorageProvider.LoadWebauthnDevicesByUsername(userSession.Username); err != nil && err != storage.ErrNoWebauthnDevice {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func newWebauthn(ctx *middlewares.AutheliaCtx) (w *webauthn.WebAuthn, err error) {
+	var (
+		origin string
+		u      *url.URL
+	)
+
+	if origin, err = ctx.ForwardedProtoHost(); err != nil {
+		return nil, err
+	}
+
+	if u, err = url.Parse(origin); err != nil {
+		return nil, fmt.Errorf("unable to parse the origin '%s': %w", origin, err)
+	}
+
+	timeout, err := utils.ParseDurationString(ctx.Configuration.Webauthn.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the webauthn timeout '%s': %w", ctx.Configuration.Webauthn.Timeout, err)
+	}
+
+	config := &webauthn.Config{
+		RPDisplayName: ctx.Configuration.Webauthn.DisplayName,
+		RPID:          u.Hostname(),
+		RPOrigin:      origin,
+		RPIcon:        "",
+
+		AttestationPreference: protocol.ConveyancePreference(ctx.Configuration.Webauthn.ConveyancePreference),
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			AuthenticatorAttachment: protocol.CrossPlatform,
+			UserVerification:        protocol.UserVerificationRequirement(ctx.Configuration.Webauthn.UserVerification),
+			RequireResidentKey:      protocol.ResidentKeyUnrequired(),
+		},
+
+		Timeout: int(timeout.Milliseconds()),
+	}
+
+	ctx.Logger.Tracef("Creating new Webauthn RP instance with ID %s and Origins %s", config.RPID, config.RPOrigin)
+
+	return webauthn.New(config)
+}
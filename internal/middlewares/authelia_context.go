@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"net/http"
 	"net/url"
 	"strings"
 
@@ -206,6 +207,17 @@ func (c *AutheliaCtx) RemoteIP() net.IP {
 	return c.RequestCtx.RemoteIP()
 }
 
+// RequestHeaders returns the headers of the request, the values of the headers which are repeated are kept in order.
+func (c *AutheliaCtx) RequestHeaders() http.Header {
+	header := http.Header{}
+
+	c.Request.Header.VisitAll(func(key, value []byte) {
+		header.Add(string(key), string(value))
+	})
+
+	return header
+}
+
 // GetOriginalURL extract the URL from the request headers (X-Original-URI or X-Forwarded-* headers).
 func (c *AutheliaCtx) GetOriginalURL() (*url.URL, error) {
 	originalURL := c.XOriginalURL()
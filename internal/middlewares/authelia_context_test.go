@@ -70,3 +70,18 @@ func TestShouldGetOriginalURLFromForwardedHeadersWithURI(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, "Unable to parse URL extracted from X-Original-URL header: parse \"htt-ps//home?-.example.com\": invalid URI for request", err.Error())
 }
+
+func TestShouldGetRequestHeaders(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Request.Header.Set("X-Internal", "true")
+	mock.Ctx.Request.Header.Add("X-Roles", "admin")
+	mock.Ctx.Request.Header.Add("X-Roles", "dev")
+
+	header := mock.Ctx.RequestHeaders()
+
+	assert.Equal(t, "true", header.Get("x-internal"))
+	assert.Equal(t, []string{"admin", "dev"}, header.Values("X-Roles"))
+	assert.Empty(t, header.Values("X-Missing"))
+}
@@ -0,0 +1,17 @@
+package middlewares
+
+import (
+	"github.com/authelia/authelia/internal/authentication"
+)
+
+// RequireSecondFactor check if user has completed two factor authentication before executing the next handler.
+func RequireSecondFactor(next RequestHandler) RequestHandler {
+	return func(ctx *AutheliaCtx) {
+		if ctx.GetSession().AuthenticationLevel < authentication.TwoFactor {
+			ctx.ReplyForbidden()
+			return
+		}
+
+		next(ctx)
+	}
+}
@@ -80,7 +80,7 @@ type IdentityVerificationFinishArgs struct {
 }
 
 // IdentityVerificationClaim custom claim for specifying the action claim.
-// The action can be to register a TOTP device, a U2F device or reset one's password.
+// The action can be to register a TOTP device, a Webauthn device or reset one's password.
 type IdentityVerificationClaim struct {
 	jwt.StandardClaims
 
@@ -50,6 +50,21 @@ func (mr *MockUserProviderMockRecorder) CheckUserPassword(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckUserPassword", reflect.TypeOf((*MockUserProvider)(nil).CheckUserPassword), arg0, arg1)
 }
 
+// GetAttributes mocks base method.
+func (m *MockUserProvider) GetAttributes(arg0 string, arg1 []string) (map[string][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttributes", arg0, arg1)
+	ret0, _ := ret[0].(map[string][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttributes indicates an expected call of GetAttributes.
+func (mr *MockUserProviderMockRecorder) GetAttributes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttributes", reflect.TypeOf((*MockUserProvider)(nil).GetAttributes), arg0, arg1)
+}
+
 // GetDetails mocks base method.
 func (m *MockUserProvider) GetDetails(arg0 string) (*authentication.UserDetails, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,216 @@
+package models
+
+import (
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/ory/fosite"
+
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// oauth2SessionFormSensitiveParameters are the request form parameters which are never persisted.
+var oauth2SessionFormSensitiveParameters = []string{
+	"password", "client_secret", "client_assertion", "code", "code_verifier", "refresh_token",
+}
+
+// NewOAuth2SessionFromRequest creates an OAuth2Session from a fosite.Requester. The tokenType is used to determine
+// the expiration of the session.
+func NewOAuth2SessionFromRequest(signature string, r fosite.Requester, tokenType fosite.TokenType) (session OAuth2Session, err error) {
+	session = OAuth2Session{
+		Signature:         signature,
+		RequestID:         r.GetID(),
+		ClientID:          r.GetClient().GetID(),
+		RequestedAt:       r.GetRequestedAt(),
+		RequestedScopes:   r.GetRequestedScopes(),
+		GrantedScopes:     r.GetGrantedScopes(),
+		RequestedAudience: r.GetRequestedAudience(),
+		GrantedAudience:   r.GetGrantedAudience(),
+		Active:            true,
+	}
+
+	form := url.Values{}
+
+	for key, values := range r.GetRequestForm() {
+		form[key] = values
+	}
+
+	for _, key := range oauth2SessionFormSensitiveParameters {
+		form.Del(key)
+	}
+
+	session.Form = form.Encode()
+
+	if s := r.GetSession(); s != nil {
+		session.Subject = s.GetSubject()
+
+		if expiresAt := s.GetExpiresAt(tokenType); !expiresAt.IsZero() {
+			session.ExpiresAt = &expiresAt
+		}
+
+		if session.Session, err = json.Marshal(s); err != nil {
+			return session, err
+		}
+	}
+
+	return session, nil
+}
+
+// OAuth2Session represents a persisted fosite.Requester for one of the OAuth 2.0 session types.
+type OAuth2Session struct {
+	ID                int
+	Signature         string
+	RequestID         string
+	ClientID          string
+	Subject           string
+	RequestedAt       time.Time
+	ExpiresAt         *time.Time
+	RequestedScopes   []string
+	GrantedScopes     []string
+	RequestedAudience []string
+	GrantedAudience   []string
+	Form              string
+	Session           []byte
+	Active            bool
+}
+
+// ToRequest converts the OAuth2Session into a fosite.Request. The session is decoded into the provided fosite.Session.
+func (s OAuth2Session) ToRequest(client fosite.Client, session fosite.Session) (request *fosite.Request, err error) {
+	if session != nil && len(s.Session) != 0 {
+		if err = json.Unmarshal(s.Session, session); err != nil {
+			return nil, err
+		}
+	}
+
+	form, err := url.ParseQuery(s.Form)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fosite.Request{
+		ID:                s.RequestID,
+		RequestedAt:       s.RequestedAt,
+		Client:            client,
+		RequestedScope:    s.RequestedScopes,
+		GrantedScope:      s.GrantedScopes,
+		RequestedAudience: s.RequestedAudience,
+		GrantedAudience:   s.GrantedAudience,
+		Form:              form,
+		Session:           session,
+	}, nil
+}
+
+// OAuth2BlacklistedJTI represents a JWT ID which has already been used and may not be used again until it expires.
+type OAuth2BlacklistedJTI struct {
+	ID        int
+	Signature string
+	ExpiresAt time.Time
+}
+
+// OAuth2ConsentSession represents the consent a subject has granted to a client, which is reused instead of prompting
+// the subject again until it expires.
+type OAuth2ConsentSession struct {
+	ID              int
+	ClientID        string
+	Subject         string
+	CreatedAt       time.Time
+	ExpiresAt       time.Time
+	GrantedScopes   []string
+	GrantedAudience []string
+}
+
+// Grants returns true if every one of the requested scopes and audience has been granted by this consent session.
+func (s OAuth2ConsentSession) Grants(requestedScopes, requestedAudience []string) bool {
+	for _, scope := range requestedScopes {
+		if !utils.IsStringInSlice(scope, s.GrantedScopes) {
+			return false
+		}
+	}
+
+	for _, audience := range requestedAudience {
+		if !utils.IsStringInSliceFold(audience, s.GrantedAudience) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// OAuth2DeviceCodeStatus represents the state of the user interaction of a device authorization request.
+type OAuth2DeviceCodeStatus int
+
+const (
+	// OAuth2DeviceCodeStatusPending is the status of a device code which the user has not yet approved or denied.
+	OAuth2DeviceCodeStatusPending OAuth2DeviceCodeStatus = iota
+
+	// OAuth2DeviceCodeStatusApproved is the status of a device code which the user has approved.
+	OAuth2DeviceCodeStatusApproved
+
+	// OAuth2DeviceCodeStatusDenied is the status of a device code which the user has denied.
+	OAuth2DeviceCodeStatusDenied
+
+	// OAuth2DeviceCodeStatusConsumed is the status of a device code which has been exchanged for tokens.
+	OAuth2DeviceCodeStatusConsumed
+)
+
+// OAuth2DeviceCodeSession represents a persisted device authorization request. The device code and user code are only
+// persisted as signatures.
+type OAuth2DeviceCodeSession struct {
+	ID                int
+	Signature         string
+	UserCodeSignature string
+	ClientID          string
+	Subject           string
+	Status            OAuth2DeviceCodeStatus
+	RequestedAt       time.Time
+	ExpiresAt         time.Time
+	LastPolledAt      *time.Time
+	RequestedScopes   []string
+	GrantedScopes     []string
+	RequestedAudience []string
+	GrantedAudience   []string
+	Session           []byte
+}
+
+// OAuth2PushedAuthorizeRequest represents a persisted pushed authorization request, see RFC 9126. The request URI is
+// only persisted as a signature.
+type OAuth2PushedAuthorizeRequest struct {
+	ID          int
+	Signature   string
+	ClientID    string
+	RequestedAt time.Time
+	ExpiresAt   time.Time
+	Form        string
+}
+
+// OAuth2Client represents a persisted OpenID Connect client which was registered at runtime. The secret is only
+// persisted as a hash.
+type OAuth2Client struct {
+	ID                                 int
+	ClientID                           string
+	Description                        string
+	Secret                             string
+	RedirectURIs                       []string
+	Policy                             string
+	Scopes                             []string
+	Audience                           []string
+	GrantTypes                         []string
+	ResponseTypes                      []string
+	ResponseModes                      []string
+	IDTokenSigningAlgorithm            string
+	UserinfoSigningAlgorithm           string
+	ConsentMode                        string
+	PreConfiguredConsentDuration       time.Duration
+	PostLogoutRedirectURIs             []string
+	FrontChannelLogoutURI              string
+	BackChannelLogoutURI               string
+	RequirePushedAuthorizationRequests bool
+	RequirePKCE                        bool
+	RequestObjectSigningAlgorithm      string
+	RequestURIs                        []string
+	JWKSURI                            string
+	TokenEndpointAuthMethod            string
+	CreatedAt                          time.Time
+	UpdatedAt                          time.Time
+}
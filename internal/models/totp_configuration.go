@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// TOTPConfigurationDefaultDescription is the description given to a TOTP configuration when none is provided.
+const TOTPConfigurationDefaultDescription = "Primary"
+
+// TOTPConfiguration represents a users TOTP configuration.
+type TOTPConfiguration struct {
+	ID          int
+	CreatedAt   time.Time
+	LastUsedAt  *time.Time
+	Username    string
+	Description string
+	Secret      string
+}
+
+// UpdateSignInInfo adjusts the values of the TOTPConfiguration after a sign in.
+func (c *TOTPConfiguration) UpdateSignInInfo(now time.Time) {
+	c.LastUsedAt = &now
+}
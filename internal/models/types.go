@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"net"
+	"time"
+)
 
 // AuthenticationAttempt represent an authentication attempt.
 type AuthenticationAttempt struct {
@@ -10,4 +13,38 @@ type AuthenticationAttempt struct {
 	Successful bool
 	// The time of the attempt.
 	Time time.Time
+	// The IP address the attempt was made from, it's nil for the attempts logged before it was recorded.
+	RemoteIP net.IP
+	// The administrator who banned or unbanned the user, IP address or network, it's empty for the actual attempts.
+	// The unbans are recorded as successful and the bans as failed.
+	Admin string
+}
+
+// RegulationBan represents a ban of a user, an IP address or a network by the regulation.
+type RegulationBan struct {
+	ID int
+	// The type of the banned value, either user, ip or network.
+	Type string
+	// The banned username, IP address or network in the CIDR notation.
+	Value     string
+	Reason    string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	Revoked   bool
+}
+
+// User represents a user of the SQL authentication backend.
+type User struct {
+	ID          int
+	Username    string
+	DisplayName string
+	// The password hash in one of the formats supported by the authentication backends.
+	Password string
+	Disabled bool
+	Emails   []string
+	Groups   []string
+	// The values of the attributes of the user by name.
+	Attributes map[string][]string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
 }
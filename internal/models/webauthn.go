@@ -0,0 +1,136 @@
+package models
+
+import (
+	"time"
+
+	"github.com/duo-labs/webauthn/protocol"
+	"github.com/duo-labs/webauthn/webauthn"
+	"github.com/google/uuid"
+)
+
+// WebauthnAttestationTypeFIDOU2F is the attestation type of devices registered with the legacy U2F protocol.
+const WebauthnAttestationTypeFIDOU2F = "fido-u2f"
+
+// WebauthnDeviceDefaultDescription is the description given to a device when none is provided.
+const WebauthnDeviceDefaultDescription = "Primary"
+
+// WebauthnUser is an object to represent a user for the Webauthn lib.
+type WebauthnUser struct {
+	Username    string
+	DisplayName string
+	Devices     []WebauthnDevice
+}
+
+// HasFIDOU2F returns true if the user has any devices registered with the legacy U2F protocol.
+func (w WebauthnUser) HasFIDOU2F() bool {
+	for _, device := range w.Devices {
+		if device.AttestationType == WebauthnAttestationTypeFIDOU2F {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebAuthnID implements the webauthn.User interface.
+func (w WebauthnUser) WebAuthnID() []byte {
+	return []byte(w.Username)
+}
+
+// WebAuthnName implements the webauthn.User interface.
+func (w WebauthnUser) WebAuthnName() string {
+	return w.Username
+}
+
+// WebAuthnDisplayName implements the webauthn.User interface.
+func (w WebauthnUser) WebAuthnDisplayName() string {
+	return w.DisplayName
+}
+
+// WebAuthnIcon implements the webauthn.User interface.
+func (w WebauthnUser) WebAuthnIcon() string {
+	return ""
+}
+
+// WebAuthnCredentials implements the webauthn.User interface.
+func (w WebauthnUser) WebAuthnCredentials() (credentials []webauthn.Credential) {
+	credentials = make([]webauthn.Credential, len(w.Devices))
+
+	for i, device := range w.Devices {
+		aaguid := device.AAGUID
+
+		credentials[i] = webauthn.Credential{
+			ID:              device.KID,
+			PublicKey:       device.PublicKey,
+			AttestationType: device.AttestationType,
+			Authenticator: webauthn.Authenticator{
+				AAGUID:       aaguid[:],
+				SignCount:    device.SignCount,
+				CloneWarning: device.CloneWarning,
+			},
+		}
+	}
+
+	return credentials
+}
+
+// WebAuthnCredentialDescriptors returns the descriptors of all registered devices, used to prevent a single
+// authenticator being registered twice.
+func (w WebauthnUser) WebAuthnCredentialDescriptors() (descriptors []protocol.CredentialDescriptor) {
+	descriptors = make([]protocol.CredentialDescriptor, len(w.Devices))
+
+	for i, device := range w.Devices {
+		descriptors[i] = protocol.CredentialDescriptor{
+			Type:         protocol.PublicKeyCredentialType,
+			CredentialID: device.KID,
+		}
+	}
+
+	return descriptors
+}
+
+// NewWebauthnDeviceFromCredential creates a WebauthnDevice from a webauthn.Credential.
+func NewWebauthnDeviceFromCredential(now time.Time, username, description string, credential *webauthn.Credential) (device WebauthnDevice) {
+	aaguid, err := uuid.FromBytes(credential.Authenticator.AAGUID)
+	if err != nil {
+		aaguid = uuid.Nil
+	}
+
+	return WebauthnDevice{
+		CreatedAt:       now,
+		Username:        username,
+		Description:     description,
+		KID:             credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          aaguid,
+		SignCount:       credential.Authenticator.SignCount,
+		CloneWarning:    credential.Authenticator.CloneWarning,
+	}
+}
+
+// WebauthnDevice represents a Webauthn Device in the database storage.
+type WebauthnDevice struct {
+	ID              int
+	CreatedAt       time.Time
+	LastUsedAt      *time.Time
+	Username        string
+	Description     string
+	KID             []byte
+	PublicKey       []byte
+	AttestationType string
+	AAGUID          uuid.UUID
+	SignCount       uint32
+	CloneWarning    bool
+}
+
+// UpdateSignInInfo adjusts the values of the WebauthnDevice after a sign in.
+func (d *WebauthnDevice) UpdateSignInInfo(now time.Time, signCount uint32, cloneWarning bool) {
+	d.LastUsedAt = &now
+	d.SignCount = signCount
+
+	// The clone warning is sticky, once a device has been seen replaying a counter it remains flagged.
+	if cloneWarning {
+		d.CloneWarning = true
+	}
+}
@@ -0,0 +1,91 @@
+package oidc
+
+import (
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// NewClaimsPolicy creates a new ClaimsPolicy from its configuration.
+func NewClaimsPolicy(config schema.OpenIDConnectClaimsPolicyConfiguration) (policy *ClaimsPolicy) {
+	policy = &ClaimsPolicy{
+		Name:         config.Name,
+		CustomScopes: make(map[string]string, len(config.CustomScopes)),
+		CustomClaims: config.CustomClaims,
+		RenameClaims: make(map[string]string, len(config.RenameClaims)),
+	}
+
+	for _, scope := range config.CustomScopes {
+		policy.CustomScopes[scope.Name] = scope.Description
+	}
+
+	for _, rename := range config.RenameClaims {
+		policy.RenameClaims[rename.Claim] = rename.Name
+	}
+
+	return policy
+}
+
+// Attributes returns the user attributes the custom claims of the granted scopes are taken from.
+func (p *ClaimsPolicy) Attributes(scopes []string) (attributes []string) {
+	for _, claim := range p.CustomClaims {
+		if claim.Attribute == "" || !utils.IsStringInSlice(claim.Scope, scopes) {
+			continue
+		}
+
+		if !utils.IsStringInSlice(claim.Attribute, attributes) {
+			attributes = append(attributes, claim.Attribute)
+		}
+	}
+
+	return attributes
+}
+
+// Apply renames the standard claims and adds the custom claims of the granted scopes. The attributes are the values of
+// the user attributes returned by Attributes.
+func (p *ClaimsPolicy) Apply(claims map[string]interface{}, scopes, groups []string, attributes map[string][]string) {
+	for claim, name := range p.RenameClaims {
+		if value, ok := claims[claim]; ok {
+			delete(claims, claim)
+			claims[name] = value
+		}
+	}
+
+	for _, claim := range p.CustomClaims {
+		if !utils.IsStringInSlice(claim.Scope, scopes) {
+			continue
+		}
+
+		if value, ok := customClaimValue(claim, groups, attributes); ok {
+			claims[claim.Name] = value
+		}
+	}
+}
+
+// customClaimValue returns the value of a custom claim. Roles are returned in the order of the mappings so the first
+// value is the role of the most specific group when the claim isn't multi valued.
+func customClaimValue(claim schema.OpenIDConnectCustomClaimConfiguration, groups []string, attributes map[string][]string) (value interface{}, ok bool) {
+	var values []string
+
+	if claim.Attribute != "" {
+		values = attributes[claim.Attribute]
+	} else {
+		for _, role := range claim.Roles {
+			if utils.IsStringInSlice(role.Group, groups) && !utils.IsStringInSlice(role.Role, values) {
+				values = append(values, role.Role)
+			}
+		}
+	}
+
+	if len(values) == 0 && claim.DefaultValue != "" {
+		values = []string{claim.DefaultValue}
+	}
+
+	switch {
+	case len(values) == 0:
+		return nil, false
+	case claim.MultiValued:
+		return values, true
+	default:
+		return values[0], true
+	}
+}
@@ -0,0 +1,103 @@
+package oidc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func newTestClaimsPolicy() *ClaimsPolicy {
+	return NewClaimsPolicy(schema.OpenIDConnectClaimsPolicyConfiguration{
+		Name: "grafana",
+		CustomScopes: []schema.OpenIDConnectCustomScopeConfiguration{
+			{Name: "roles", Description: "Access your roles"},
+		},
+		CustomClaims: []schema.OpenIDConnectCustomClaimConfiguration{
+			{
+				Name:  "role",
+				Scope: "roles",
+				Roles: []schema.OpenIDConnectRoleMappingConfiguration{
+					{Group: "admins", Role: "Admin"},
+					{Group: "dev", Role: "Editor"},
+				},
+				DefaultValue: "Viewer",
+			},
+			{
+				Name:  "roles",
+				Scope: "roles",
+				Roles: []schema.OpenIDConnectRoleMappingConfiguration{
+					{Group: "admins", Role: "Admin"},
+					{Group: "dev", Role: "Editor"},
+					{Group: "ops", Role: "Editor"},
+				},
+				MultiValued: true,
+			},
+			{
+				Name:      "employee_number",
+				Scope:     "profile",
+				Attribute: "employeeNumber",
+			},
+			{
+				Name:        "locales",
+				Scope:       "profile",
+				Attribute:   "preferredLanguage",
+				MultiValued: true,
+			},
+		},
+		RenameClaims: []schema.OpenIDConnectRenameClaimConfiguration{
+			{Claim: "name", Name: "display_name"},
+		},
+	})
+}
+
+func TestClaimsPolicy_ShouldReturnAttributesOfGrantedScopes(t *testing.T) {
+	policy := newTestClaimsPolicy()
+
+	assert.Equal(t, map[string]string{"roles": "Access your roles"}, policy.CustomScopes)
+	assert.Equal(t, []string{"employeeNumber", "preferredLanguage"}, policy.Attributes([]string{"openid", "profile"}))
+	assert.Nil(t, policy.Attributes([]string{"openid", "roles"}))
+}
+
+func TestClaimsPolicy_ShouldApplyClaims(t *testing.T) {
+	policy := newTestClaimsPolicy()
+
+	claims := map[string]interface{}{"name": "John Doe", "groups": []string{"dev", "ops"}}
+
+	policy.Apply(claims, []string{"openid", "profile", "groups", "roles"}, []string{"dev", "ops"}, map[string][]string{
+		"employeeNumber":    {"1234", "5678"},
+		"preferredLanguage": {"en", "fr"},
+	})
+
+	assert.Equal(t, map[string]interface{}{
+		"display_name":    "John Doe",
+		"groups":          []string{"dev", "ops"},
+		"role":            "Editor",
+		"roles":           []string{"Editor"},
+		"employee_number": "1234",
+		"locales":         []string{"en", "fr"},
+	}, claims)
+}
+
+func TestClaimsPolicy_ShouldApplyDefaultValueAndOmitClaimsWithoutValue(t *testing.T) {
+	policy := newTestClaimsPolicy()
+
+	claims := map[string]interface{}{}
+
+	policy.Apply(claims, []string{"openid", "profile", "roles"}, []string{"users"}, nil)
+
+	assert.Equal(t, map[string]interface{}{"role": "Viewer"}, claims)
+}
+
+func TestClaimsPolicy_ShouldOnlyApplyClaimsOfGrantedScopes(t *testing.T) {
+	policy := newTestClaimsPolicy()
+
+	claims := map[string]interface{}{}
+
+	policy.Apply(claims, []string{"openid", "profile"}, []string{"admins"}, map[string][]string{
+		"employeeNumber": {"1234"},
+	})
+
+	assert.Equal(t, map[string]interface{}{"employee_number": "1234"}, claims)
+}
@@ -1,11 +1,19 @@
 package oidc
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+
 	"github.com/ory/fosite"
 
 	"github.com/authelia/authelia/internal/authentication"
 	"github.com/authelia/authelia/internal/authorization"
 	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/models"
 	"github.com/authelia/authelia/internal/session"
 )
 
@@ -20,9 +28,27 @@ func NewClient(config schema.OpenIDConnectClientConfiguration) (client *Internal
 		GrantTypes:    config.GrantTypes,
 		ResponseTypes: config.ResponseTypes,
 		Scopes:        config.Scopes,
+		Audience:      config.Audience,
 
+		IDTokenSigningAlgorithm:  config.IDTokenSigningAlgorithm,
 		UserinfoSigningAlgorithm: config.UserinfoSigningAlgorithm,
 
+		ConsentMode:                  NewClientConsentMode(config.ConsentMode),
+		PreConfiguredConsentDuration: config.PreConfiguredConsentDuration,
+
+		TokenEndpointAuthMethod: config.TokenEndpointAuthMethod,
+
+		RequirePushedAuthorizationRequests: config.RequirePushedAuthorizationRequests,
+		RequirePKCE:                        config.RequirePKCE,
+
+		RequestObjectSigningAlgorithm: config.RequestObjectSigningAlgorithm,
+		RequestURIs:                   config.RequestURIs,
+		JWKSURI:                       config.JWKSURI,
+
+		PostLogoutRedirectURIs: config.PostLogoutRedirectURIs,
+		FrontChannelLogoutURI:  config.FrontChannelLogoutURI,
+		BackChannelLogoutURI:   config.BackChannelLogoutURI,
+
 		ResponseModes: []fosite.ResponseModeType{
 			fosite.ResponseModeDefault,
 		},
@@ -35,6 +61,159 @@ func NewClient(config schema.OpenIDConnectClientConfiguration) (client *Internal
 	return client
 }
 
+// NewClientFromModel creates a new InternalClient from a client registered at runtime.
+func NewClientFromModel(model models.OAuth2Client) (client *InternalClient) {
+	return NewClient(NewClientConfiguration(model))
+}
+
+// NewClientConfiguration converts a client registered at runtime into a schema.OpenIDConnectClientConfiguration.
+func NewClientConfiguration(model models.OAuth2Client) schema.OpenIDConnectClientConfiguration {
+	return schema.OpenIDConnectClientConfiguration{
+		ID:            model.ClientID,
+		Description:   model.Description,
+		Secret:        model.Secret,
+		RedirectURIs:  model.RedirectURIs,
+		Policy:        model.Policy,
+		Scopes:        model.Scopes,
+		Audience:      model.Audience,
+		GrantTypes:    model.GrantTypes,
+		ResponseTypes: model.ResponseTypes,
+		ResponseModes: model.ResponseModes,
+
+		IDTokenSigningAlgorithm:  model.IDTokenSigningAlgorithm,
+		UserinfoSigningAlgorithm: model.UserinfoSigningAlgorithm,
+
+		ConsentMode:                  model.ConsentMode,
+		PreConfiguredConsentDuration: model.PreConfiguredConsentDuration,
+
+		TokenEndpointAuthMethod: model.TokenEndpointAuthMethod,
+
+		RequirePushedAuthorizationRequests: model.RequirePushedAuthorizationRequests,
+		RequirePKCE:                        model.RequirePKCE,
+
+		RequestObjectSigningAlgorithm: model.RequestObjectSigningAlgorithm,
+		RequestURIs:                   model.RequestURIs,
+		JWKSURI:                       model.JWKSURI,
+
+		PostLogoutRedirectURIs: model.PostLogoutRedirectURIs,
+		FrontChannelLogoutURI:  model.FrontChannelLogoutURI,
+		BackChannelLogoutURI:   model.BackChannelLogoutURI,
+	}
+}
+
+// NewClientModel converts a validated schema.OpenIDConnectClientConfiguration into a models.OAuth2Client which can be
+// persisted. The secret of the configuration is expected to already be hashed.
+func NewClientModel(config schema.OpenIDConnectClientConfiguration) models.OAuth2Client {
+	return models.OAuth2Client{
+		ClientID:      config.ID,
+		Description:   config.Description,
+		Secret:        config.Secret,
+		RedirectURIs:  config.RedirectURIs,
+		Policy:        config.Policy,
+		Scopes:        config.Scopes,
+		Audience:      config.Audience,
+		GrantTypes:    config.GrantTypes,
+		ResponseTypes: config.ResponseTypes,
+		ResponseModes: config.ResponseModes,
+
+		IDTokenSigningAlgorithm:  config.IDTokenSigningAlgorithm,
+		UserinfoSigningAlgorithm: config.UserinfoSigningAlgorithm,
+
+		ConsentMode:                  config.ConsentMode,
+		PreConfiguredConsentDuration: config.PreConfiguredConsentDuration,
+
+		TokenEndpointAuthMethod: config.TokenEndpointAuthMethod,
+
+		RequirePushedAuthorizationRequests: config.RequirePushedAuthorizationRequests,
+		RequirePKCE:                        config.RequirePKCE,
+
+		RequestObjectSigningAlgorithm: config.RequestObjectSigningAlgorithm,
+		RequestURIs:                   config.RequestURIs,
+		JWKSURI:                       config.JWKSURI,
+
+		PostLogoutRedirectURIs: config.PostLogoutRedirectURIs,
+		FrontChannelLogoutURI:  config.FrontChannelLogoutURI,
+		BackChannelLogoutURI:   config.BackChannelLogoutURI,
+	}
+}
+
+// NewClientAuthenticationStrategy wraps a fosite.ClientAuthenticationStrategy to reject the clients which authenticate
+// at the token endpoint with another method than their TokenEndpointAuthMethod. The clients without one can use any of
+// the methods supported by fosite.
+func NewClientAuthenticationStrategy(strategy fosite.ClientAuthenticationStrategy) fosite.ClientAuthenticationStrategy {
+	return func(ctx context.Context, r *http.Request, form url.Values) (client fosite.Client, err error) {
+		if client, err = strategy(ctx, r, form); err != nil {
+			return nil, err
+		}
+
+		c, ok := client.(*InternalClient)
+		if !ok || c.IsPublic() || c.TokenEndpointAuthMethod == "" {
+			return client, nil
+		}
+
+		if method := getTokenEndpointAuthMethod(r, form); method != c.TokenEndpointAuthMethod {
+			return nil, fosite.ErrInvalidClient.WithHintf("The OAuth 2.0 Client supports client authentication method '%s', but method '%s' was requested.", c.TokenEndpointAuthMethod, method)
+		}
+
+		return client, nil
+	}
+}
+
+// getTokenEndpointAuthMethod returns the method the client authenticated with in the same order of precedence as
+// fosite: the client assertion, the HTTP basic authentication and then the client secret of the form.
+func getTokenEndpointAuthMethod(r *http.Request, form url.Values) string {
+	if form.Get("client_assertion_type") != "" {
+		return TokenEndpointAuthMethodPrivateKeyJWT
+	}
+
+	if _, _, ok := r.BasicAuth(); ok {
+		return TokenEndpointAuthMethodClientSecretBasic
+	}
+
+	if form.Get("client_secret") != "" {
+		return TokenEndpointAuthMethodClientSecretPost
+	}
+
+	return TokenEndpointAuthMethodNone
+}
+
+// NewClientSecret generates a new random client secret along with its hash. Only the hash is ever persisted, the
+// secret is returned to the client once.
+func NewClientSecret(ctx context.Context) (secret, hash string, err error) {
+	data := make([]byte, clientSecretEntropy)
+
+	if _, err = rand.Read(data); err != nil {
+		return "", "", err
+	}
+
+	secret = base64.RawURLEncoding.EncodeToString(data)
+
+	hashed, err := AutheliaHasher{}.Hash(ctx, []byte(secret))
+	if err != nil {
+		return "", "", err
+	}
+
+	return secret, string(hashed), nil
+}
+
+// IsHashedSecret returns true if the secret is a password hash rather than a plain text secret.
+func IsHashedSecret(secret string) bool {
+	return strings.HasPrefix(secret, "$argon2id$") || strings.HasPrefix(secret, "$6$")
+}
+
+// NewClientConsentMode converts the consent mode of the configuration into a ClientConsentMode. Unknown modes are
+// treated as explicit.
+func NewClientConsentMode(mode string) ClientConsentMode {
+	switch mode {
+	case "implicit":
+		return ClientConsentModeImplicit
+	case "pre-configured":
+		return ClientConsentModePreConfigured
+	default:
+		return ClientConsentModeExplicit
+	}
+}
+
 // IsAuthenticationLevelSufficient returns if the provided authentication.Level is sufficient for the client of the AutheliaClient.
 func (c InternalClient) IsAuthenticationLevelSufficient(level authentication.Level) bool {
 	return authorization.IsAuthLevelSufficient(level, c.Policy)
@@ -53,13 +232,34 @@ func (c InternalClient) GetConsentResponseBody(session *session.OIDCWorkflowSess
 	}
 
 	if session != nil {
-		body.Scopes = scopeNamesToScopes(session.RequestedScopes)
+		body.Scopes = scopeNamesToScopes(session.RequestedScopes, c.customScopes())
 		body.Audience = audienceNamesToAudience(session.RequestedAudience)
 	}
 
 	return body
 }
 
+// GetDeviceResponseBody returns the proper device response body for this models.OAuth2DeviceCodeSession.
+func (c InternalClient) GetDeviceResponseBody(session models.OAuth2DeviceCodeSession, level authentication.Level) DeviceGetResponseBody {
+	return DeviceGetResponseBody{
+		ConsentGetResponseBody: ConsentGetResponseBody{
+			ClientID:          c.ID,
+			ClientDescription: c.Description,
+			Scopes:            scopeNamesToScopes(session.RequestedScopes, c.customScopes()),
+			Audience:          audienceNamesToAudience(session.RequestedAudience),
+		},
+		SecondFactorRequired: !c.IsAuthenticationLevelSufficient(level),
+	}
+}
+
+func (c InternalClient) customScopes() map[string]string {
+	if c.ClaimsPolicy == nil {
+		return nil
+	}
+
+	return c.ClaimsPolicy.CustomScopes
+}
+
 // GetHashedSecret returns the Secret.
 func (c InternalClient) GetHashedSecret() []byte {
 	return c.Secret
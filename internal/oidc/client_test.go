@@ -1,7 +1,13 @@
 package oidc
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/ory/fosite"
 	"github.com/stretchr/testify/assert"
@@ -21,6 +27,7 @@ func TestNewClient(t *testing.T) {
 	assert.Equal(t, "", blankClient.Description)
 	require.Len(t, blankClient.ResponseModes, 1)
 	assert.Equal(t, fosite.ResponseModeDefault, blankClient.ResponseModes[0])
+	assert.Equal(t, ClientConsentModeExplicit, blankClient.ConsentMode)
 
 	exampleConfig := schema.OpenIDConnectClientConfiguration{
 		ID:            "myapp",
@@ -32,10 +39,15 @@ func TestNewClient(t *testing.T) {
 		ResponseTypes: schema.DefaultOpenIDConnectClientConfiguration.ResponseTypes,
 		GrantTypes:    schema.DefaultOpenIDConnectClientConfiguration.GrantTypes,
 		ResponseModes: schema.DefaultOpenIDConnectClientConfiguration.ResponseModes,
+
+		ConsentMode:                  "pre-configured",
+		PreConfiguredConsentDuration: time.Hour,
 	}
 
 	exampleClient := NewClient(exampleConfig)
 	assert.Equal(t, "myapp", exampleClient.ID)
+	assert.Equal(t, ClientConsentModePreConfigured, exampleClient.ConsentMode)
+	assert.Equal(t, time.Hour, exampleClient.PreConfiguredConsentDuration)
 	require.Len(t, exampleClient.ResponseModes, 4)
 	assert.Equal(t, fosite.ResponseModeDefault, exampleClient.ResponseModes[0])
 	assert.Equal(t, fosite.ResponseModeFormPost, exampleClient.ResponseModes[1])
@@ -217,3 +229,43 @@ func TestInternalClient_IsPublic(t *testing.T) {
 	c.Public = true
 	assert.True(t, c.IsPublic())
 }
+
+func TestShouldEnforceTokenEndpointAuthMethodOfClient(t *testing.T) {
+	client := &InternalClient{ID: "app", TokenEndpointAuthMethod: TokenEndpointAuthMethodClientSecretPost}
+
+	strategy := NewClientAuthenticationStrategy(func(_ context.Context, _ *http.Request, _ url.Values) (fosite.Client, error) {
+		return client, nil
+	})
+
+	form := url.Values{"client_id": []string{"app"}, "client_secret": []string{"secret"}}
+
+	r := httptest.NewRequest(http.MethodPost, "/api/oidc/token", nil)
+
+	authenticated, err := strategy(context.Background(), r, form)
+	require.NoError(t, err)
+	assert.Equal(t, client, authenticated)
+
+	r.SetBasicAuth("app", "secret")
+
+	authenticated, err = strategy(context.Background(), r, url.Values{})
+	assert.Nil(t, authenticated)
+	require.True(t, errors.Is(err, fosite.ErrInvalidClient))
+	assert.Equal(t, "The OAuth 2.0 Client supports client authentication method 'client_secret_post', but method "+
+		"'client_secret_basic' was requested.", fosite.ErrorToRFC6749Error(err).HintField)
+
+	client.TokenEndpointAuthMethod = ""
+
+	authenticated, err = strategy(context.Background(), r, url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, client, authenticated)
+}
+
+func TestShouldNotEnforceTokenEndpointAuthMethodWhenAuthenticationFails(t *testing.T) {
+	strategy := NewClientAuthenticationStrategy(func(_ context.Context, _ *http.Request, _ url.Values) (fosite.Client, error) {
+		return nil, fosite.ErrInvalidClient
+	})
+
+	authenticated, err := strategy(context.Background(), httptest.NewRequest(http.MethodPost, "/api/oidc/token", nil), url.Values{})
+	assert.Nil(t, authenticated)
+	assert.Equal(t, fosite.ErrInvalidClient, err)
+}
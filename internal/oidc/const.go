@@ -1,5 +1,90 @@
 package oidc
 
+import (
+	"time"
+)
+
+// storagePurgeInterval is the interval between each purge of the expired sessions from the storage provider.
+const storagePurgeInterval = time.Hour
+
+// clientReloadInterval is the interval between each reload of the clients registered at runtime from the storage
+// provider, which propagates the changes made by other instances.
+const clientReloadInterval = time.Minute
+
+// clientSecretEntropy is the number of random bytes of a generated client secret.
+const clientSecretEntropy = 32
+
+const (
+	// BackChannelLogoutEvent is the member of the events claim which identifies a logout token.
+	BackChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+	// logoutTokenType is the typ header of a logout token.
+	logoutTokenType = "logout+jwt"
+
+	// logoutTokenLifespan is the lifespan of a logout token. It's only used once, straight after it's issued.
+	logoutTokenLifespan = time.Minute * 2
+
+	// backChannelLogoutTimeout is the time a client has to respond to a back-channel logout request.
+	backChannelLogoutTimeout = time.Second * 10
+)
+
+// Methods the clients can authenticate with at the token endpoint, see RFC 7591 section 2.
+const (
+	TokenEndpointAuthMethodClientSecretBasic = "client_secret_basic"
+	TokenEndpointAuthMethodClientSecretPost  = "client_secret_post"
+	TokenEndpointAuthMethodPrivateKeyJWT     = "private_key_jwt"
+	TokenEndpointAuthMethodNone              = "none"
+)
+
+// GrantTypeDeviceCode is the grant type of the OAuth 2.0 Device Authorization Grant, see RFC 8628.
+const GrantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+
+const (
+	// deviceCodeEntropy is the number of random bytes of a device code.
+	deviceCodeEntropy = 32
+
+	// deviceUserCodeCharset is the charset of the user codes. It has no vowels to avoid accidental words and no
+	// characters which are easily mistaken for one another, see RFC 8628 section 6.1.
+	deviceUserCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+
+	// deviceUserCodeLength is the number of characters of a user code, excluding the separator.
+	deviceUserCodeLength = 8
+)
+
+const (
+	// PushedAuthorizeRequestURIPrefix is the prefix of the request URIs of the pushed authorization requests, see
+	// RFC 9126 section 2.2.
+	PushedAuthorizeRequestURIPrefix = "urn:ietf:params:oauth:request_uri:"
+
+	// pushedAuthorizeRequestURIEntropy is the number of random bytes of the reference of a pushed authorization
+	// request URI.
+	pushedAuthorizeRequestURIEntropy = 32
+
+	// requestObjectMaxSize is the maximum size of a request object fetched from a request URI.
+	requestObjectMaxSize = 1 << 16
+)
+
+// RequestObjectSigningAlgorithms are the algorithms the request objects can be signed with. The unsigned request
+// objects are not supported.
+var RequestObjectSigningAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384",
+	"ES512"}
+
+// requestObjectRegisteredClaims are the JWT claims of a request object which aren't authorization request parameters.
+var requestObjectRegisteredClaims = []string{"iss", "aud", "exp", "nbf", "iat", "jti"}
+
+// pushedAuthorizeRequestSensitiveParameters are the parameters of a pushed authorization request which are never
+// persisted, they're only used to authenticate the client.
+var pushedAuthorizeRequestSensitiveParameters = []string{"client_secret", "client_assertion", "client_assertion_type"}
+
+// Signing algorithms supported by the KeyManager.
+const (
+	SigningAlgorithmNone                   = "none"
+	SigningAlgorithmRSAWithSHA256          = "RS256"
+	SigningAlgorithmRSAPSSWithSHA256       = "PS256"
+	SigningAlgorithmECDSAWithP256AndSHA256 = "ES256"
+	SigningAlgorithmECDSAWithP384AndSHA384 = "ES384"
+)
+
 var scopeDescriptions = map[string]string{
 	"openid":  "Use OpenID to verify your identity",
 	"email":   "Access your email addresses",
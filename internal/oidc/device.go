@@ -0,0 +1,311 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/handler/openid"
+
+	"github.com/authelia/authelia/internal/models"
+)
+
+// NewDeviceCodeGrantFactory returns a compose.Factory which creates the DeviceCodeGrantHandler. The interval is the
+// minimum amount of time clients must wait between each poll of the token endpoint.
+func NewDeviceCodeGrantFactory(interval time.Duration) compose.Factory {
+	return func(config *compose.Config, storage interface{}, strategy interface{}) interface{} {
+		return &DeviceCodeGrantHandler{
+			HandleHelper: &oauth2.HandleHelper{
+				AccessTokenStrategy:  strategy.(oauth2.AccessTokenStrategy),
+				AccessTokenStorage:   storage.(oauth2.AccessTokenStorage),
+				AccessTokenLifespan:  config.GetAccessTokenLifespan(),
+				RefreshTokenLifespan: config.GetRefreshTokenLifespan(),
+			},
+			IDTokenHandleHelper: &openid.IDTokenHandleHelper{
+				IDTokenStrategy: strategy.(openid.OpenIDConnectTokenStrategy),
+			},
+			RefreshTokenStrategy: strategy.(oauth2.RefreshTokenStrategy),
+			store:                storage.(*OpenIDConnectStore),
+			interval:             interval,
+		}
+	}
+}
+
+// HandleTokenEndpointRequest validates the device code and restores the session the user approved, see RFC 8628
+// section 3.4 and 3.5.
+func (c *DeviceCodeGrantHandler) HandleTokenEndpointRequest(_ context.Context, request fosite.AccessRequester) error {
+	if !c.CanHandleTokenEndpointRequest(request) {
+		return fosite.ErrUnknownRequest
+	}
+
+	client := request.GetClient()
+
+	if !client.GetGrantTypes().Has(GrantTypeDeviceCode) {
+		return fosite.ErrUnauthorizedClient.WithHintf("The OAuth 2.0 Client is not allowed to use authorization grant '%s'.", GrantTypeDeviceCode)
+	}
+
+	deviceCode := request.GetRequestForm().Get("device_code")
+	if deviceCode == "" {
+		return fosite.ErrInvalidRequest.WithHint("The 'device_code' parameter is missing.")
+	}
+
+	session, err := c.store.GetDeviceCodeSession(deviceCode)
+
+	switch {
+	case errors.Is(err, fosite.ErrNotFound):
+		return fosite.ErrInvalidGrant.WithHint("The device code is not valid.")
+	case err != nil:
+		return fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+	case session.ClientID != client.GetID():
+		return fosite.ErrInvalidGrant.WithHint("The OAuth 2.0 Client ID from this request does not match the one from the device authorization request.")
+	}
+
+	now := time.Now()
+
+	if now.After(session.ExpiresAt) {
+		return ErrExpiredToken
+	}
+
+	switch session.Status {
+	case models.OAuth2DeviceCodeStatusPending:
+		return c.handlePending(*session, now)
+	case models.OAuth2DeviceCodeStatusDenied:
+		return fosite.ErrAccessDenied.WithHint("The end user denied the device authorization request.")
+	case models.OAuth2DeviceCodeStatusConsumed:
+		return fosite.ErrInvalidGrant.WithHint("The device code has already been used.")
+	}
+
+	if err = json.Unmarshal(session.Session, request.GetSession()); err != nil {
+		return fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+	}
+
+	request.SetRequestedScopes(session.RequestedScopes)
+	request.SetRequestedAudience(session.RequestedAudience)
+
+	for _, scope := range session.GrantedScopes {
+		request.GrantScope(scope)
+	}
+
+	for _, audience := range session.GrantedAudience {
+		request.GrantAudience(audience)
+	}
+
+	// The device code can only be exchanged once, only one of the concurrent requests can consume it.
+	err = c.store.ConsumeDeviceCodeSession(deviceCode)
+
+	switch {
+	case errors.Is(err, fosite.ErrNotFound):
+		return fosite.ErrInvalidGrant.WithHint("The device code has already been used.")
+	case err != nil:
+		return fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+	}
+
+	request.GetSession().SetExpiresAt(fosite.AccessToken, now.UTC().Add(c.AccessTokenLifespan))
+
+	if c.canIssueRefreshToken(request) {
+		request.GetSession().SetExpiresAt(fosite.RefreshToken, now.UTC().Add(c.RefreshTokenLifespan))
+	}
+
+	return nil
+}
+
+func (c *DeviceCodeGrantHandler) handlePending(session models.OAuth2DeviceCodeSession, now time.Time) error {
+	polledAt := session.LastPolledAt
+	session.LastPolledAt = &now
+
+	if err := c.store.UpdateDeviceCodeSession(session); err != nil {
+		return fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+	}
+
+	if polledAt != nil && now.Before(polledAt.Add(c.interval)) {
+		return ErrSlowDown
+	}
+
+	return ErrAuthorizationPending
+}
+
+// PopulateTokenEndpointResponse issues the access token, and the refresh token and ID token when the respective scopes
+// were granted.
+func (c *DeviceCodeGrantHandler) PopulateTokenEndpointResponse(ctx context.Context, request fosite.AccessRequester, response fosite.AccessResponder) error {
+	if !c.CanHandleTokenEndpointRequest(request) {
+		return fosite.ErrUnknownRequest
+	}
+
+	if err := c.IssueAccessToken(ctx, request, response); err != nil {
+		return fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+	}
+
+	if c.canIssueRefreshToken(request) {
+		token, signature, err := c.RefreshTokenStrategy.GenerateRefreshToken(ctx, request)
+		if err != nil {
+			return fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+		}
+
+		if err = c.store.CreateRefreshTokenSession(ctx, signature, request.Sanitize([]string{})); err != nil {
+			return fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+		}
+
+		response.SetExtra("refresh_token", token)
+	}
+
+	if !request.GetGrantedScopes().Has("openid") {
+		return nil
+	}
+
+	session, ok := request.GetSession().(openid.Session)
+	if !ok {
+		return fosite.ErrServerError.WithDebug("Failed to generate id token because session must be of type fosite/handler/openid.Session.")
+	}
+
+	session.IDTokenClaims().AccessTokenHash = c.GetAccessTokenHash(ctx, request, response)
+
+	return c.IssueExplicitIDToken(ctx, request, response)
+}
+
+// CanSkipClientAuth returns false as confidential clients must authenticate, see RFC 8628 section 3.4.
+func (c *DeviceCodeGrantHandler) CanSkipClientAuth(_ fosite.AccessRequester) bool {
+	return false
+}
+
+// CanHandleTokenEndpointRequest returns true if the grant type is the device code grant type.
+func (c *DeviceCodeGrantHandler) CanHandleTokenEndpointRequest(requester fosite.AccessRequester) bool {
+	return requester.GetGrantTypes().ExactOne(GrantTypeDeviceCode)
+}
+
+func (c *DeviceCodeGrantHandler) canIssueRefreshToken(request fosite.Requester) bool {
+	return request.GetGrantedScopes().HasOneOf("offline", "offline_access") &&
+		request.GetClient().GetGrantTypes().Has("refresh_token")
+}
+
+// NewDeviceAuthorizeResponse authenticates the client and stores a new device authorization request, see RFC 8628
+// section 3.1. The verificationURI is the URI of the page where the user enters the user code.
+func (p OpenIDConnectProvider) NewDeviceAuthorizeResponse(ctx context.Context, r *http.Request, verificationURI string) (response *DeviceAuthorizeResponse, err error) {
+	f, ok := p.Fosite.(*fosite.Fosite)
+	if !ok {
+		return nil, fosite.ErrServerError.WithDebug("The OAuth 2.0 provider is not able to authenticate clients.")
+	}
+
+	if r.Method != http.MethodPost {
+		return nil, fosite.ErrInvalidRequest.WithHintf("HTTP method is '%s', expected 'POST'.", r.Method)
+	}
+
+	if err = r.ParseForm(); err != nil {
+		return nil, fosite.ErrInvalidRequest.WithHint("Unable to parse HTTP body, make sure to send a properly formatted form request body.").WithWrap(err).WithDebug(err.Error())
+	}
+
+	client, err := f.AuthenticateClient(ctx, r, r.PostForm)
+	if err != nil {
+		return nil, err
+	}
+
+	if !client.GetGrantTypes().Has(GrantTypeDeviceCode) {
+		return nil, fosite.ErrUnauthorizedClient.WithHintf("The OAuth 2.0 Client is not allowed to use authorization grant '%s'.", GrantTypeDeviceCode)
+	}
+
+	scopes := fosite.RemoveEmpty(strings.Split(r.PostForm.Get("scope"), " "))
+	for _, scope := range scopes {
+		if !fosite.HierarchicScopeStrategy(client.GetScopes(), scope) {
+			return nil, fosite.ErrInvalidScope.WithHintf("The OAuth 2.0 Client is not allowed to request scope '%s'.", scope)
+		}
+	}
+
+	audience := fosite.GetAudiences(r.PostForm)
+	if err = fosite.DefaultAudienceMatchingStrategy(client.GetAudience(), audience); err != nil {
+		return nil, err
+	}
+
+	deviceCode, err := newDeviceCode()
+	if err != nil {
+		return nil, fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+	}
+
+	userCode, err := newUserCode()
+	if err != nil {
+		return nil, fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+	}
+
+	now := time.Now()
+
+	err = p.Store.CreateDeviceCodeSession(deviceCode, userCode, models.OAuth2DeviceCodeSession{
+		ClientID:          client.GetID(),
+		Status:            models.OAuth2DeviceCodeStatusPending,
+		RequestedAt:       now,
+		ExpiresAt:         now.Add(p.deviceCodeLifespan),
+		RequestedScopes:   scopes,
+		RequestedAudience: audience,
+	})
+	if err != nil {
+		return nil, fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+	}
+
+	return &DeviceAuthorizeResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                userCode,
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: fmt.Sprintf("%s?user_code=%s", verificationURI, url.QueryEscape(userCode)),
+		ExpiresIn:               int64(p.deviceCodeLifespan / time.Second),
+		Interval:                int64(p.deviceCodePollingInterval / time.Second),
+	}, nil
+}
+
+// WriteDeviceAuthorizeError writes an error of the device authorization endpoint, the errors have the same format as
+// the errors of the token endpoint, see RFC 8628 section 3.2.
+func (p OpenIDConnectProvider) WriteDeviceAuthorizeError(rw http.ResponseWriter, err error) {
+	p.Fosite.WriteAccessError(rw, nil, err)
+}
+
+// NormalizeUserCode removes the separators and whitespace from a user code entered by the user and converts it to
+// upper case, see RFC 8628 section 6.1.
+func NormalizeUserCode(userCode string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' || r == '\t' {
+			return -1
+		}
+
+		return r
+	}, strings.ToUpper(strings.TrimSpace(userCode)))
+}
+
+func newDeviceCode() (deviceCode string, err error) {
+	data := make([]byte, deviceCodeEntropy)
+
+	if _, err = rand.Read(data); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func newUserCode() (userCode string, err error) {
+	var (
+		builder strings.Builder
+		n       *big.Int
+	)
+
+	max := big.NewInt(int64(len(deviceUserCodeCharset)))
+
+	for i := 0; i < deviceUserCodeLength; i++ {
+		if i == deviceUserCodeLength/2 {
+			builder.WriteRune('-')
+		}
+
+		if n, err = rand.Int(rand.Reader, max); err != nil {
+			return "", err
+		}
+
+		builder.WriteByte(deviceUserCodeCharset[n.Int64()])
+	}
+
+	return builder.String(), nil
+}
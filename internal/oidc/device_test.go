@@ -0,0 +1,321 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/ory/fosite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+func newTestDeviceProvider(t *testing.T) (provider OpenIDConnectProvider, mock *storage.MockProvider, ctrl *gomock.Controller) {
+	ctrl = gomock.NewController(t)
+	mock = storage.NewMockProvider(ctrl)
+
+	provider, err := NewOpenIDConnectProvider(&schema.OpenIDConnectConfiguration{
+		IssuerPrivateKey:          exampleIssuerPrivateKey,
+		HMACSecret:                "asbdhaaskmdlkamdklasmdlkams",
+		AccessTokenLifespan:       time.Hour,
+		IDTokenLifespan:           time.Hour,
+		RefreshTokenLifespan:      time.Hour,
+		DeviceCodeLifespan:        time.Minute * 10,
+		DeviceCodePollingInterval: time.Second * 5,
+		Clients: []schema.OpenIDConnectClientConfiguration{
+			{
+				ID:                      "tv",
+				Secret:                  "tv-secret",
+				Policy:                  "two_factor",
+				Scopes:                  []string{"openid", "profile", "offline_access"},
+				GrantTypes:              []string{GrantTypeDeviceCode, "refresh_token"},
+				IDTokenSigningAlgorithm: SigningAlgorithmRSAWithSHA256,
+			},
+			{
+				ID:     "web",
+				Secret: "web-secret",
+				Policy: "two_factor",
+			},
+		},
+	}, mock, nil)
+	require.NoError(t, err)
+
+	return provider, mock, ctrl
+}
+
+func newTestDeviceTokenRequest(deviceCode string) *http.Request {
+	form := url.Values{
+		"grant_type":    []string{GrantTypeDeviceCode},
+		"device_code":   []string{deviceCode},
+		"client_id":     []string{"tv"},
+		"client_secret": []string{"tv-secret"},
+	}
+
+	r, _ := http.NewRequest(http.MethodPost, "https://auth.example.com/api/oidc/token", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return r
+}
+
+func TestNormalizeUserCode(t *testing.T) {
+	assert.Equal(t, "BCDFGHJK", NormalizeUserCode("BCDF-GHJK"))
+	assert.Equal(t, "BCDFGHJK", NormalizeUserCode(" bcdf ghjk "))
+	assert.Equal(t, "BCDFGHJK", NormalizeUserCode("bcdfghjk"))
+}
+
+func TestShouldGenerateUserCodesAndDeviceCodes(t *testing.T) {
+	userCode, err := newUserCode()
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile("^["+deviceUserCodeCharset+"]{4}-["+deviceUserCodeCharset+"]{4}$"), userCode)
+
+	deviceCode, err := newDeviceCode()
+	require.NoError(t, err)
+	assert.Len(t, deviceCode, 43)
+
+	otherDeviceCode, err := newDeviceCode()
+	require.NoError(t, err)
+	assert.NotEqual(t, deviceCode, otherDeviceCode)
+}
+
+func TestOpenIDConnectProvider_NewDeviceAuthorizeResponse(t *testing.T) {
+	provider, mock, ctrl := newTestDeviceProvider(t)
+	defer ctrl.Finish()
+
+	var saved models.OAuth2DeviceCodeSession
+
+	mock.EXPECT().
+		SaveOAuth2DeviceCodeSession(gomock.Any()).
+		DoAndReturn(func(session models.OAuth2DeviceCodeSession) error {
+			saved = session
+			return nil
+		})
+
+	form := url.Values{
+		"client_id":     []string{"tv"},
+		"client_secret": []string{"tv-secret"},
+		"scope":         []string{"openid profile"},
+	}
+
+	r, err := http.NewRequest(http.MethodPost, "https://auth.example.com/api/oidc/device_authorization", strings.NewReader(form.Encode()))
+	require.NoError(t, err)
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := provider.NewDeviceAuthorizeResponse(context.Background(), r, "https://auth.example.com/device")
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://auth.example.com/device", response.VerificationURI)
+	assert.Equal(t, "https://auth.example.com/device?user_code="+response.UserCode, response.VerificationURIComplete)
+	assert.Equal(t, int64(600), response.ExpiresIn)
+	assert.Equal(t, int64(5), response.Interval)
+
+	assert.Equal(t, utils.HashSHA256FromString(response.DeviceCode), saved.Signature)
+	assert.Equal(t, utils.HashSHA256FromString(NormalizeUserCode(response.UserCode)), saved.UserCodeSignature)
+	assert.Equal(t, "tv", saved.ClientID)
+	assert.Equal(t, models.OAuth2DeviceCodeStatusPending, saved.Status)
+	assert.Equal(t, []string{"openid", "profile"}, saved.RequestedScopes)
+	assert.Equal(t, time.Minute*10, saved.ExpiresAt.Sub(saved.RequestedAt))
+}
+
+func TestOpenIDConnectProvider_NewDeviceAuthorizeResponse_ShouldRejectClients(t *testing.T) {
+	provider, _, ctrl := newTestDeviceProvider(t)
+	defer ctrl.Finish()
+
+	for _, tc := range []struct {
+		name     string
+		form     url.Values
+		expected string
+	}{
+		{"InvalidSecret", url.Values{"client_id": []string{"tv"}, "client_secret": []string{"bad"}}, "invalid_client"},
+		{"GrantTypeNotAllowed", url.Values{"client_id": []string{"web"}, "client_secret": []string{"web-secret"}}, "unauthorized_client"},
+		{"ScopeNotAllowed", url.Values{"client_id": []string{"tv"}, "client_secret": []string{"tv-secret"}, "scope": []string{"groups"}}, "invalid_scope"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodPost, "https://auth.example.com/api/oidc/device_authorization", strings.NewReader(tc.form.Encode()))
+			require.NoError(t, err)
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			response, err := provider.NewDeviceAuthorizeResponse(context.Background(), r, "https://auth.example.com/device")
+			assert.Nil(t, response)
+			require.Error(t, err)
+			assert.Equal(t, tc.expected, fosite.ErrorToRFC6749Error(err).ErrorField)
+		})
+	}
+}
+
+func TestDeviceCodeGrantHandler_ShouldReturnPendingErrors(t *testing.T) {
+	provider, mock, ctrl := newTestDeviceProvider(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	polledAt := now.Add(time.Second * -1)
+
+	for _, tc := range []struct {
+		name     string
+		session  models.OAuth2DeviceCodeSession
+		expected string
+	}{
+		{"Pending", models.OAuth2DeviceCodeSession{ClientID: "tv", ExpiresAt: now.Add(time.Minute)}, "authorization_pending"},
+		{"SlowDown", models.OAuth2DeviceCodeSession{ClientID: "tv", ExpiresAt: now.Add(time.Minute), LastPolledAt: &polledAt}, "slow_down"},
+		{"Expired", models.OAuth2DeviceCodeSession{ClientID: "tv", ExpiresAt: now.Add(-time.Minute)}, "expired_token"},
+		{"Denied", models.OAuth2DeviceCodeSession{ClientID: "tv", ExpiresAt: now.Add(time.Minute), Status: models.OAuth2DeviceCodeStatusDenied}, "access_denied"},
+		{"Consumed", models.OAuth2DeviceCodeSession{ClientID: "tv", ExpiresAt: now.Add(time.Minute), Status: models.OAuth2DeviceCodeStatusConsumed}, "invalid_grant"},
+		{"OtherClient", models.OAuth2DeviceCodeSession{ClientID: "web", ExpiresAt: now.Add(time.Minute)}, "invalid_grant"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			session := tc.session
+
+			mock.EXPECT().
+				LoadOAuth2DeviceCodeSession(utils.HashSHA256FromString("code")).
+				Return(&session, nil)
+
+			if tc.session.Status == models.OAuth2DeviceCodeStatusPending && tc.session.ClientID == "tv" && tc.expected != "expired_token" {
+				mock.EXPECT().
+					UpdateOAuth2DeviceCodeSession(gomock.Any()).
+					DoAndReturn(func(updated models.OAuth2DeviceCodeSession) error {
+						require.NotNil(t, updated.LastPolledAt)
+						assert.Equal(t, models.OAuth2DeviceCodeStatusPending, updated.Status)
+						return nil
+					})
+			}
+
+			_, err := provider.Fosite.NewAccessRequest(context.Background(), newTestDeviceTokenRequest("code"), NewSession())
+			require.Error(t, err)
+			assert.Equal(t, tc.expected, fosite.ErrorToRFC6749Error(err).ErrorField)
+		})
+	}
+
+	mock.EXPECT().
+		LoadOAuth2DeviceCodeSession(utils.HashSHA256FromString("missing")).
+		Return(nil, storage.ErrNoOAuth2DeviceCodeSession)
+
+	_, err := provider.Fosite.NewAccessRequest(context.Background(), newTestDeviceTokenRequest("missing"), NewSession())
+	require.Error(t, err)
+	assert.Equal(t, "invalid_grant", fosite.ErrorToRFC6749Error(err).ErrorField)
+}
+
+func TestDeviceCodeGrantHandler_ShouldIssueTokensWhenApproved(t *testing.T) {
+	provider, mock, ctrl := newTestDeviceProvider(t)
+	defer ctrl.Finish()
+
+	session := NewSession()
+	session.Subject = "john"
+	session.Claims.Subject = "john"
+	session.Claims.Issuer = "https://auth.example.com"
+	session.Claims.Extra = map[string]interface{}{"name": "John Doe"}
+	session.ClientID = "tv"
+
+	data, err := json.Marshal(session)
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	gomock.InOrder(
+		mock.EXPECT().
+			LoadOAuth2DeviceCodeSession(utils.HashSHA256FromString("code")).
+			Return(&models.OAuth2DeviceCodeSession{
+				ID:                1,
+				ClientID:          "tv",
+				Subject:           "john",
+				Status:            models.OAuth2DeviceCodeStatusApproved,
+				RequestedAt:       now,
+				ExpiresAt:         now.Add(time.Minute),
+				RequestedScopes:   []string{"openid", "profile", "offline_access"},
+				GrantedScopes:     []string{"openid", "profile", "offline_access"},
+				RequestedAudience: []string{},
+				GrantedAudience:   []string{"tv"},
+				Session:           data,
+			}, nil),
+		mock.EXPECT().
+			ConsumeOAuth2DeviceCodeSession(utils.HashSHA256FromString("code")).
+			Return(nil),
+		mock.EXPECT().
+			SaveOAuth2Session(storage.OAuth2SessionTypeAccessToken, gomock.Any()).
+			DoAndReturn(func(_ storage.OAuth2SessionType, saved models.OAuth2Session) error {
+				assert.Equal(t, "john", saved.Subject)
+				return nil
+			}),
+		mock.EXPECT().
+			SaveOAuth2Session(storage.OAuth2SessionTypeRefreshToken, gomock.Any()).
+			Return(nil),
+	)
+
+	request, err := provider.Fosite.NewAccessRequest(context.Background(), newTestDeviceTokenRequest("code"), NewSession())
+	require.NoError(t, err)
+
+	assert.Equal(t, fosite.Arguments{"openid", "profile", "offline_access"}, request.GetGrantedScopes())
+	assert.Equal(t, fosite.Arguments{"tv"}, request.GetGrantedAudience())
+
+	response, err := provider.Fosite.NewAccessResponse(context.Background(), request)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, response.GetAccessToken())
+	assert.Equal(t, "bearer", response.GetTokenType())
+	assert.NotEmpty(t, response.GetExtra("refresh_token"))
+
+	idToken, ok := response.GetExtra("id_token").(string)
+	require.True(t, ok)
+
+	decoded, err := provider.KeyManager.Strategy().Decode(context.Background(), idToken)
+	require.NoError(t, err)
+	assert.Equal(t, "john", decoded.Claims["sub"])
+	assert.Equal(t, "John Doe", decoded.Claims["name"])
+	assert.NotEmpty(t, decoded.Claims["at_hash"])
+}
+
+func TestDeviceCodeGrantHandler_ShouldIssueTokensOnlyOnceWhenPolledConcurrently(t *testing.T) {
+	provider, mock, ctrl := newTestDeviceProvider(t)
+	defer ctrl.Finish()
+
+	session := NewSession()
+	session.Subject = "john"
+	session.ClientID = "tv"
+
+	data, err := json.Marshal(session)
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	// Both polls read the device code while it's approved, only the first one is able to consume it.
+	mock.EXPECT().
+		LoadOAuth2DeviceCodeSession(utils.HashSHA256FromString("code")).
+		Return(&models.OAuth2DeviceCodeSession{
+			ID:              1,
+			ClientID:        "tv",
+			Subject:         "john",
+			Status:          models.OAuth2DeviceCodeStatusApproved,
+			RequestedAt:     now,
+			ExpiresAt:       now.Add(time.Minute),
+			RequestedScopes: []string{"openid"},
+			GrantedScopes:   []string{"openid"},
+			Session:         data,
+		}, nil).
+		Times(2)
+
+	gomock.InOrder(
+		mock.EXPECT().
+			ConsumeOAuth2DeviceCodeSession(utils.HashSHA256FromString("code")).
+			Return(nil),
+		mock.EXPECT().
+			ConsumeOAuth2DeviceCodeSession(utils.HashSHA256FromString("code")).
+			Return(storage.ErrNoOAuth2DeviceCodeSession),
+	)
+
+	_, err = provider.Fosite.NewAccessRequest(context.Background(), newTestDeviceTokenRequest("code"), NewSession())
+	require.NoError(t, err)
+
+	_, err = provider.Fosite.NewAccessRequest(context.Background(), newTestDeviceTokenRequest("code"), NewSession())
+	require.Error(t, err)
+	assert.Equal(t, "invalid_grant", fosite.ErrorToRFC6749Error(err).ErrorField)
+	assert.Equal(t, "The device code has already been used.", fosite.ErrorToRFC6749Error(err).HintField)
+}
@@ -1,5 +1,39 @@
 package oidc
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ory/fosite"
+)
 
 var errPasswordsDoNotMatch = errors.New("the passwords don't match")
+
+var (
+	// ErrClientAlreadyExists is returned when registering a client with the ID of an existing client.
+	ErrClientAlreadyExists = errors.New("a client with this ID already exists")
+
+	// ErrClientConfigured is returned when changing a client which is defined in the configuration.
+	ErrClientConfigured = errors.New("the client is defined in the configuration and can't be changed at runtime")
+)
+
+// Errors of the token endpoint specific to the device authorization grant, see RFC 8628 section 3.5.
+var (
+	ErrAuthorizationPending = &fosite.RFC6749Error{
+		ErrorField:       "authorization_pending",
+		DescriptionField: "The authorization request is still pending as the end user hasn't yet completed the user-interaction steps.",
+		CodeField:        http.StatusBadRequest,
+	}
+
+	ErrSlowDown = &fosite.RFC6749Error{
+		ErrorField:       "slow_down",
+		DescriptionField: "The authorization request is still pending and polling should continue, but the interval must be increased.",
+		CodeField:        http.StatusBadRequest,
+	}
+
+	ErrExpiredToken = &fosite.RFC6749Error{
+		ErrorField:       "expired_token",
+		DescriptionField: "The device code has expired, and the device authorization session has concluded.",
+		CodeField:        http.StatusBadRequest,
+	}
+)
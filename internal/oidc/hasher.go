@@ -3,18 +3,44 @@ package oidc
 import (
 	"context"
 	"crypto/subtle"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
 )
 
-// Compare compares the hash with the data and returns an error if they don't match.
+// Compare compares the hash with the data and returns an error if they don't match. The hash is either a password
+// hash understood by authentication.ParseHash or, for clients with a plain text secret in the configuration, the
+// secret itself.
 func (h AutheliaHasher) Compare(_ context.Context, hash, data []byte) (err error) {
-	if subtle.ConstantTimeCompare(hash, data) == 0 {
+	if !IsHashedSecret(string(hash)) {
+		if subtle.ConstantTimeCompare(hash, data) == 0 {
+			return errPasswordsDoNotMatch
+		}
+
+		return nil
+	}
+
+	ok, err := authentication.CheckPassword(string(data), string(hash))
+	if err != nil {
+		return err
+	}
+
+	if !ok {
 		return errPasswordsDoNotMatch
 	}
 
 	return nil
 }
 
-// Hash creates a new hash from data.
+// Hash creates a new argon2id hash from data.
 func (h AutheliaHasher) Hash(_ context.Context, data []byte) (hash []byte, err error) {
-	return data, nil
+	config := schema.DefaultPasswordConfiguration
+
+	hashed, err := authentication.HashPassword(string(data), "", authentication.HashingAlgorithmArgon2id,
+		config.Iterations, config.Memory*1024, config.Parallelism, config.KeyLength, config.SaltLength)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(hashed), nil
 }
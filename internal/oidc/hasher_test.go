@@ -2,9 +2,13 @@ package oidc
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/authentication"
 )
 
 func TestShouldNotRaiseErrorOnEqualPasswordsPlainText(t *testing.T) {
@@ -43,5 +47,23 @@ func TestShouldHashPassword(t *testing.T) {
 	hash, err := hasher.Hash(ctx, data)
 
 	assert.NoError(t, err)
-	assert.Equal(t, data, hash)
+	assert.True(t, strings.HasPrefix(string(hash), "$argon2id$"))
+
+	assert.NoError(t, hasher.Compare(ctx, hash, data))
+	assert.Equal(t, errPasswordsDoNotMatch, hasher.Compare(ctx, hash, []byte("abcd")))
+}
+
+func TestShouldCompareHashedPasswords(t *testing.T) {
+	hasher := AutheliaHasher{}
+
+	ctx := context.Background()
+
+	sha512, err := authentication.HashPassword("password", "", authentication.HashingAlgorithmSHA512, 5000, 0, 0, 0, 16)
+	require.NoError(t, err)
+
+	hash := []byte(sha512)
+
+	assert.NoError(t, hasher.Compare(ctx, hash, []byte("password")))
+	assert.Equal(t, errPasswordsDoNotMatch, hasher.Compare(ctx, hash, []byte("wrong")))
+	assert.Equal(t, errPasswordsDoNotMatch, hasher.Compare(ctx, []byte("password"), []byte("$6$password")))
 }
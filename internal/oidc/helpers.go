@@ -1,9 +1,27 @@
 package oidc
 
-func scopeNamesToScopes(scopeSlice []string) (scopes []Scope) {
+import (
+	"github.com/ory/fosite/handler/openid"
+	"github.com/ory/fosite/token/jwt"
+)
+
+// NewSession creates a new empty OpenIDSession.
+func NewSession() (session *OpenIDSession) {
+	return &OpenIDSession{
+		DefaultSession: &openid.DefaultSession{
+			Claims:  new(jwt.IDTokenClaims),
+			Headers: new(jwt.Headers),
+		},
+		Extra: map[string]interface{}{},
+	}
+}
+
+func scopeNamesToScopes(scopeSlice []string, customScopes map[string]string) (scopes []Scope) {
 	for _, name := range scopeSlice {
 		if val, ok := scopeDescriptions[name]; ok {
 			scopes = append(scopes, Scope{name, val})
+		} else if val, ok = customScopes[name]; ok && val != "" {
+			scopes = append(scopes, Scope{name, val})
 		} else {
 			scopes = append(scopes, Scope{name, name})
 		}
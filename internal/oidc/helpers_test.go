@@ -9,33 +9,37 @@ import (
 func TestScopeNamesToScopes(t *testing.T) {
 	scopeNames := []string{"openid"}
 
-	scopes := scopeNamesToScopes(scopeNames)
+	scopes := scopeNamesToScopes(scopeNames, nil)
 	assert.Equal(t, "openid", scopes[0].Name)
 	assert.Equal(t, "Use OpenID to verify your identity", scopes[0].Description)
 
 	scopeNames = []string{"groups"}
 
-	scopes = scopeNamesToScopes(scopeNames)
+	scopes = scopeNamesToScopes(scopeNames, nil)
 	assert.Equal(t, "groups", scopes[0].Name)
 	assert.Equal(t, "Access your group membership", scopes[0].Description)
 
 	scopeNames = []string{"profile"}
 
-	scopes = scopeNamesToScopes(scopeNames)
+	scopes = scopeNamesToScopes(scopeNames, nil)
 	assert.Equal(t, "profile", scopes[0].Name)
 	assert.Equal(t, "Access your display name", scopes[0].Description)
 
 	scopeNames = []string{"email"}
 
-	scopes = scopeNamesToScopes(scopeNames)
+	scopes = scopeNamesToScopes(scopeNames, nil)
 	assert.Equal(t, "email", scopes[0].Name)
 	assert.Equal(t, "Access your email addresses", scopes[0].Description)
 
 	scopeNames = []string{"another"}
 
-	scopes = scopeNamesToScopes(scopeNames)
+	scopes = scopeNamesToScopes(scopeNames, nil)
 	assert.Equal(t, "another", scopes[0].Name)
 	assert.Equal(t, "another", scopes[0].Description)
+
+	scopes = scopeNamesToScopes(scopeNames, map[string]string{"another": "Access your roles"})
+	assert.Equal(t, "another", scopes[0].Name)
+	assert.Equal(t, "Access your roles", scopes[0].Description)
 }
 
 func TestAudienceNamesToScopes(t *testing.T) {
@@ -3,10 +3,16 @@ package oidc
 import (
 	"context"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/ory/fosite/token/jwt"
 	"gopkg.in/square/go-jose.v2"
@@ -15,13 +21,31 @@ import (
 	"github.com/authelia/authelia/internal/utils"
 )
 
-// NewKeyManagerWithConfiguration when provided a schema.OpenIDConnectConfiguration creates a new KeyManager and adds an
-// initial key to the manager.
+// NewKeyManagerWithConfiguration when provided a schema.OpenIDConnectConfiguration creates a new KeyManager and adds
+// the issuer private keys to the manager. The issuer_private_key is always active, the keys from issuer_private_keys
+// become active at their activate_at time.
 func NewKeyManagerWithConfiguration(configuration *schema.OpenIDConnectConfiguration) (manager *KeyManager, err error) {
 	manager = NewKeyManager()
 
-	_, _, err = manager.AddActivePrivateKeyData(configuration.IssuerPrivateKey)
-	if err != nil {
+	// Tokens signed by a superseded key may be presented until they expire, so the key stays published until then.
+	manager.retention = configuration.IDTokenLifespan
+	if configuration.AccessTokenLifespan > manager.retention {
+		manager.retention = configuration.AccessTokenLifespan
+	}
+
+	if configuration.IssuerPrivateKey != "" {
+		if _, err = manager.AddPrivateKeyData("", "", configuration.IssuerPrivateKey, time.Time{}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, key := range configuration.IssuerPrivateKeys {
+		if _, err = manager.AddPrivateKeyData(key.KeyID, key.Algorithm, key.Key, key.ActivateAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err = manager.GetActiveKey(SigningAlgorithmRSAWithSHA256); err != nil {
 		return nil, err
 	}
 
@@ -30,168 +54,341 @@ func NewKeyManagerWithConfiguration(configuration *schema.OpenIDConnectConfigura
 
 // NewKeyManager creates a new empty KeyManager.
 func NewKeyManager() (manager *KeyManager) {
-	manager = new(KeyManager)
-	manager.keys = map[string]*rsa.PrivateKey{}
-	manager.keySet = new(jose.JSONWebKeySet)
+	manager = &KeyManager{
+		clock: utils.RealClock{},
+	}
+
+	manager.strategy = &JWTStrategy{manager: manager}
 
 	return manager
 }
 
-// Strategy returns the RS256JWTStrategy.
-func (m KeyManager) Strategy() (strategy *RS256JWTStrategy) {
+// Strategy returns the JWTStrategy.
+func (m *KeyManager) Strategy() (strategy *JWTStrategy) {
 	return m.strategy
 }
 
-// GetKeySet returns the joseJSONWebKeySet containing the rsa.PublicKey types.
-func (m KeyManager) GetKeySet() (keySet *jose.JSONWebKeySet) {
-	return m.keySet
-}
+// GetKeySet returns the jose.JSONWebKeySet containing the public keys of every published key, including keys which
+// are not yet active and keys which have been superseded but not yet retired.
+func (m *KeyManager) GetKeySet() (keySet *jose.JSONWebKeySet) {
+	keySet = &jose.JSONWebKeySet{}
 
-// GetActiveWebKey obtains the currently active jose.JSONWebKey.
-func (m KeyManager) GetActiveWebKey() (webKey *jose.JSONWebKey, err error) {
-	webKeys := m.keySet.Key(m.activeKeyID)
-	if len(webKeys) == 1 {
-		return &webKeys[0], nil
+	for _, key := range m.publishedKeys() {
+		keySet.Keys = append(keySet.Keys, key.WebKey())
 	}
 
-	if len(webKeys) == 0 {
-		return nil, errors.New("could not find a key with the active key id")
+	return keySet
+}
+
+// Algorithms returns the signing algorithms which currently have an active key.
+func (m *KeyManager) Algorithms() (algorithms []string) {
+	now := m.clock.Now()
+
+	for _, key := range m.keys {
+		if !key.ActivateAt.After(now) && !utils.IsStringInSlice(key.Algorithm, algorithms) {
+			algorithms = append(algorithms, key.Algorithm)
+		}
 	}
 
-	return &webKeys[0], errors.New("multiple keys with the same key id")
+	return algorithms
 }
 
-// GetActiveKeyID returns the key id of the currently active key.
-func (m KeyManager) GetActiveKeyID() (keyID string) {
-	return m.activeKeyID
+// GetActiveKey returns the currently active key for the provided signing algorithm. This is the key with the most
+// recent activation time which is not in the future.
+func (m *KeyManager) GetActiveKey(algorithm string) (key *Key, err error) {
+	now := m.clock.Now()
+
+	for i := len(m.keys) - 1; i >= 0; i-- {
+		if m.keys[i].Algorithm == algorithm && !m.keys[i].ActivateAt.After(now) {
+			return m.keys[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to retrieve an active key for the %s algorithm", algorithm)
 }
 
-// GetActiveKey returns the rsa.PublicKey of the currently active key.
-func (m KeyManager) GetActiveKey() (key *rsa.PublicKey, err error) {
-	if key, ok := m.keys[m.activeKeyID]; ok {
-		return &key.PublicKey, nil
+// GetActiveKeyID returns the key id of the currently active key for the provided signing algorithm.
+func (m *KeyManager) GetActiveKeyID(algorithm string) (keyID string) {
+	key, err := m.GetActiveKey(algorithm)
+	if err != nil {
+		return ""
 	}
 
-	return nil, errors.New("failed to retrieve active public key")
+	return key.ID
 }
 
-// GetActivePrivateKey returns the rsa.PrivateKey of the currently active key.
-func (m KeyManager) GetActivePrivateKey() (key *rsa.PrivateKey, err error) {
-	if key, ok := m.keys[m.activeKeyID]; ok {
-		return key, nil
+// GetKey returns the published key with the provided key id.
+func (m *KeyManager) GetKey(keyID string) (key *Key, err error) {
+	for _, key = range m.publishedKeys() {
+		if key.ID == keyID {
+			return key, nil
+		}
 	}
 
-	return nil, errors.New("failed to retrieve active private key")
+	return nil, fmt.Errorf("failed to retrieve a published key with the key id %s", keyID)
 }
 
-// AddActivePrivateKeyData adds a rsa.PublicKey given the key in the PEM string format, then sets it to the active key.
-func (m *KeyManager) AddActivePrivateKeyData(data string) (key *rsa.PrivateKey, webKey *jose.JSONWebKey, err error) {
-	key, err = utils.ParseRsaPrivateKeyFromPemStr(data)
+// AddPrivateKeyData parses the key in the PEM string format and adds it to the manager. The key id is generated
+// from the thumbprint of the key when empty, and the algorithm is inferred from the type of the key when empty.
+func (m *KeyManager) AddPrivateKeyData(keyID, algorithm, data string, activateAt time.Time) (key *Key, err error) {
+	privateKey, err := parsePrivateKeyFromPEM(data)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	webKey, err = m.AddActivePrivateKey(key)
+	return m.AddPrivateKey(keyID, algorithm, privateKey, activateAt)
+}
 
-	return key, webKey, err
+// AddPrivateKey adds a crypto.Signer to the manager which becomes active at the provided time.
+func (m *KeyManager) AddPrivateKey(keyID, algorithm string, privateKey crypto.Signer, activateAt time.Time) (key *Key, err error) {
+	if algorithm == "" {
+		if algorithm, err = inferSigningAlgorithm(privateKey); err != nil {
+			return nil, err
+		}
+	} else if err = validateSigningAlgorithm(algorithm, privateKey); err != nil {
+		return nil, err
+	}
+
+	key = &Key{
+		ID:         keyID,
+		Algorithm:  algorithm,
+		PrivateKey: privateKey,
+		ActivateAt: activateAt,
+	}
+
+	if key.ID == "" {
+		webKey := key.WebKey()
+
+		thumbprint, err := webKey.Thumbprint(crypto.SHA1)
+		if err != nil {
+			return nil, err
+		}
+
+		// Shorten the thumbprint to a length of exactly 6.
+		key.ID = strings.ToLower(fmt.Sprintf("%x", thumbprint))[0:6]
+	}
+
+	for _, k := range m.keys {
+		if k.ID == key.ID {
+			return nil, fmt.Errorf("key id %s already exists", key.ID)
+		}
+	}
+
+	m.keys = append(m.keys, key)
+
+	sort.SliceStable(m.keys, func(i, j int) bool {
+		return m.keys[i].ActivateAt.Before(m.keys[j].ActivateAt)
+	})
+
+	return key, nil
 }
 
-// AddActivePrivateKey adds a rsa.PublicKey, then sets it to the active key.
-func (m *KeyManager) AddActivePrivateKey(key *rsa.PrivateKey) (webKey *jose.JSONWebKey, err error) {
-	wk := jose.JSONWebKey{
-		Key:       &key.PublicKey,
-		Algorithm: "RS256",
-		Use:       "sig",
+// publishedKeys returns the keys which have not been retired. A key is retired once the retention period has elapsed
+// since a newer key of the same algorithm became active.
+func (m *KeyManager) publishedKeys() (keys []*Key) {
+	now := m.clock.Now()
+
+	for i, key := range m.keys {
+		if !m.isRetired(i, now) {
+			keys = append(keys, key)
+		}
 	}
 
-	keyID, err := wk.Thumbprint(crypto.SHA1)
-	if err != nil {
-		return nil, err
+	return keys
+}
+
+func (m *KeyManager) isRetired(i int, now time.Time) bool {
+	for _, next := range m.keys[i+1:] {
+		if next.Algorithm == m.keys[i].Algorithm {
+			return !now.Before(next.ActivateAt.Add(m.retention))
+		}
 	}
 
-	strKeyID := strings.ToLower(fmt.Sprintf("%x", keyID))
-	if len(strKeyID) >= 7 {
-		// Shorten the key if it's greater than 7 to a length of exactly 7.
-		strKeyID = strKeyID[0:6]
+	return false
+}
+
+// WebKey returns the jose.JSONWebKey containing the public key of this key.
+func (k Key) WebKey() (webKey jose.JSONWebKey) {
+	return jose.JSONWebKey{
+		Key:       k.PrivateKey.Public(),
+		KeyID:     k.ID,
+		Algorithm: k.Algorithm,
+		Use:       "sig",
 	}
+}
 
-	if _, ok := m.keys[strKeyID]; ok {
-		return nil, fmt.Errorf("key id %s already exists", strKeyID)
+// Generate signs the claims with the active key of the algorithm of the key referenced by the kid header, or the
+// active RS256 key if the kid header is absent or unknown. The kid header is updated to the key used.
+func (s *JWTStrategy) Generate(ctx context.Context, claims jwt.MapClaims, header jwt.Mapper) (rawToken, signature string, err error) {
+	if header == nil || claims == nil {
+		return "", "", errors.New("either claims or header is nil")
 	}
 
-	// TODO: Add Mutex here when implementing key rotation.
-	wk.KeyID = strKeyID
-	m.keySet.Keys = append(m.keySet.Keys, wk)
-	m.keys[strKeyID] = key
-	m.activeKeyID = strKeyID
+	headers := header.ToMap()
+	algorithm := SigningAlgorithmRSAWithSHA256
 
-	m.strategy, err = NewRS256JWTStrategy(wk.KeyID, key)
+	if keyID, ok := headers["kid"].(string); ok {
+		for _, k := range s.manager.keys {
+			if k.ID == keyID {
+				algorithm = k.Algorithm
+				break
+			}
+		}
+	}
+
+	key, err := s.manager.GetActiveKey(algorithm)
 	if err != nil {
-		return &wk, err
+		return "", "", err
 	}
 
-	return &wk, nil
-}
+	headers["kid"] = key.ID
 
-// NewRS256JWTStrategy returns a new RS256JWTStrategy.
-func NewRS256JWTStrategy(id string, key *rsa.PrivateKey) (strategy *RS256JWTStrategy, err error) {
-	strategy = new(RS256JWTStrategy)
-	strategy.JWTStrategy = new(jwt.RS256JWTStrategy)
+	token := jwt.NewWithClaims(jose.SignatureAlgorithm(key.Algorithm), claims)
+	token.Header = headers
 
-	strategy.SetKey(id, key)
+	if rawToken, err = token.SignedString(key.PrivateKey); err != nil {
+		return "", "", err
+	}
 
-	return strategy, nil
+	if signature, err = s.GetSignature(ctx, rawToken); err != nil {
+		return "", "", err
+	}
+
+	return rawToken, signature, nil
 }
 
-// RS256JWTStrategy is a decorator struct for the fosite RS256JWTStrategy.
-type RS256JWTStrategy struct {
-	JWTStrategy *jwt.RS256JWTStrategy
+// Validate validates a token and returns its signature or an error if the token is not valid.
+func (s *JWTStrategy) Validate(ctx context.Context, token string) (signature string, err error) {
+	if _, err = s.Decode(ctx, token); err != nil {
+		return "", err
+	}
 
-	keyID string
+	return s.GetSignature(ctx, token)
 }
 
-// KeyID returns the key id.
-func (s RS256JWTStrategy) KeyID() (id string) {
-	return s.keyID
+// Decode decodes a token and validates it with the published key referenced by the kid header, or the active RS256
+// key if the kid header is absent.
+func (s *JWTStrategy) Decode(_ context.Context, token string) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(token, jwt.MapClaims{}, s.verificationKey)
 }
 
-// SetKey sets the provided key id and key as the active key (this is what triggers fosite to use it).
-func (s *RS256JWTStrategy) SetKey(id string, key *rsa.PrivateKey) {
-	s.keyID = id
-	s.JWTStrategy.PrivateKey = key
+// GetSignature returns the signature of a token.
+func (s *JWTStrategy) GetSignature(_ context.Context, token string) (signature string, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("header, body and signature must all be set")
+	}
+
+	return parts[2], nil
 }
 
-// Hash is a decorator func for the underlying fosite RS256JWTStrategy.
-func (s *RS256JWTStrategy) Hash(ctx context.Context, in []byte) ([]byte, error) {
-	return s.JWTStrategy.Hash(ctx, in)
+// Hash returns the SHA-256 hash of the input.
+func (s *JWTStrategy) Hash(_ context.Context, in []byte) (sum []byte, err error) {
+	hash := crypto.SHA256.New()
+
+	if _, err = hash.Write(in); err != nil {
+		return nil, err
+	}
+
+	return hash.Sum(nil), nil
 }
 
-// GetSigningMethodLength is a decorator func for the underlying fosite RS256JWTStrategy.
-func (s *RS256JWTStrategy) GetSigningMethodLength() int {
-	return s.JWTStrategy.GetSigningMethodLength()
+// GetSigningMethodLength returns the length of the hash used by Hash.
+func (s *JWTStrategy) GetSigningMethodLength() int {
+	return crypto.SHA256.Size()
 }
 
-// GetSignature is a decorator func for the underlying fosite RS256JWTStrategy.
-func (s *RS256JWTStrategy) GetSignature(ctx context.Context, token string) (string, error) {
-	return s.JWTStrategy.GetSignature(ctx, token)
+// GetPublicKeyID returns the key id of the active RS256 key.
+func (s *JWTStrategy) GetPublicKeyID(_ context.Context) (keyID string, err error) {
+	key, err := s.manager.GetActiveKey(SigningAlgorithmRSAWithSHA256)
+	if err != nil {
+		return "", err
+	}
+
+	return key.ID, nil
 }
 
-// Generate is a decorator func for the underlying fosite RS256JWTStrategy.
-func (s *RS256JWTStrategy) Generate(ctx context.Context, claims jwt.MapClaims, header jwt.Mapper) (string, string, error) {
-	return s.JWTStrategy.Generate(ctx, claims, header)
+func (s *JWTStrategy) verificationKey(token *jwt.Token) (verificationKey interface{}, err error) {
+	var key *Key
+
+	if keyID, ok := token.Header["kid"].(string); ok && keyID != "" {
+		key, err = s.manager.GetKey(keyID)
+	} else {
+		key, err = s.manager.GetActiveKey(SigningAlgorithmRSAWithSHA256)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if string(token.Method) != key.Algorithm {
+		return nil, fmt.Errorf("token algorithm %s does not match the %s algorithm of key id %s", token.Method, key.Algorithm, key.ID)
+	}
+
+	return key.PrivateKey.Public(), nil
 }
 
-// Validate is a decorator func for the underlying fosite RS256JWTStrategy.
-func (s *RS256JWTStrategy) Validate(ctx context.Context, token string) (string, error) {
-	return s.JWTStrategy.Validate(ctx, token)
+func parsePrivateKeyFromPEM(data string) (key crypto.Signer, err error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("failed to parse PEM block containing the key")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		switch parsed := parsed.(type) {
+		case *rsa.PrivateKey:
+			return parsed, nil
+		case *ecdsa.PrivateKey:
+			return parsed, nil
+		}
+
+		return nil, fmt.Errorf("unsupported private key type %T", parsed)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %s", block.Type)
+	}
 }
 
-// Decode is a decorator func for the underlying fosite RS256JWTStrategy.
-func (s *RS256JWTStrategy) Decode(ctx context.Context, token string) (*jwt.Token, error) {
-	return s.JWTStrategy.Decode(ctx, token)
+func inferSigningAlgorithm(key crypto.Signer) (algorithm string, err error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return SigningAlgorithmRSAWithSHA256, nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return SigningAlgorithmECDSAWithP256AndSHA256, nil
+		case elliptic.P384():
+			return SigningAlgorithmECDSAWithP384AndSHA384, nil
+		}
+
+		return "", fmt.Errorf("unsupported elliptic curve %s", k.Curve.Params().Name)
+	}
+
+	return "", fmt.Errorf("unsupported private key type %T", key)
 }
 
-// GetPublicKeyID is a decorator func for the underlying fosite RS256JWTStrategy.
-func (s *RS256JWTStrategy) GetPublicKeyID(_ context.Context) (string, error) {
-	return s.keyID, nil
+func validateSigningAlgorithm(algorithm string, key crypto.Signer) (err error) {
+	switch algorithm {
+	case SigningAlgorithmRSAWithSHA256, SigningAlgorithmRSAPSSWithSHA256:
+		if _, ok := key.(*rsa.PrivateKey); ok {
+			return nil
+		}
+	case SigningAlgorithmECDSAWithP256AndSHA256, SigningAlgorithmECDSAWithP384AndSHA384:
+		if inferred, _ := inferSigningAlgorithm(key); inferred == algorithm {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unsupported signing algorithm %s", algorithm)
+	}
+
+	return fmt.Errorf("the %s signing algorithm can't be used with a private key of type %T", algorithm, key)
 }
@@ -1,53 +1,248 @@
 package oidc
 
 import (
+	"context"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/ory/fosite/token/jwt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
 )
 
-func TestKeyManager_AddActiveKeyData(t *testing.T) {
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func exampleECDSAPrivateKey(t *testing.T, curve elliptic.Curve) string {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	require.NoError(t, err)
+
+	data, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: data}))
+}
+
+func TestKeyManager_AddPrivateKeyData(t *testing.T) {
 	manager := NewKeyManager()
-	assert.Nil(t, manager.strategy)
-	assert.Nil(t, manager.Strategy())
+	assert.NotNil(t, manager.Strategy())
 
-	key, wk, err := manager.AddActivePrivateKeyData(exampleIssuerPrivateKey)
+	key, err := manager.AddPrivateKeyData("", "", exampleIssuerPrivateKey, time.Time{})
 	require.NoError(t, err)
 	require.NotNil(t, key)
-	require.NotNil(t, wk)
-	require.NotNil(t, manager.strategy)
-	require.NotNil(t, manager.Strategy())
 
-	thumbprint, err := wk.Thumbprint(crypto.SHA1)
+	webKey := key.WebKey()
+	thumbprint, err := webKey.Thumbprint(crypto.SHA1)
 	assert.NoError(t, err)
 
 	kid := strings.ToLower(fmt.Sprintf("%x", thumbprint)[0:6])
-	assert.Equal(t, manager.activeKeyID, kid)
-	assert.Equal(t, kid, wk.KeyID)
+	assert.Equal(t, kid, key.ID)
+	assert.Equal(t, SigningAlgorithmRSAWithSHA256, key.Algorithm)
 	assert.Len(t, manager.keys, 1)
-	assert.Len(t, manager.keySet.Keys, 1)
-	assert.Contains(t, manager.keys, kid)
-
-	keys := manager.keySet.Key(kid)
-	assert.Equal(t, keys[0].KeyID, kid)
 
-	privKey, err := manager.GetActivePrivateKey()
+	active, err := manager.GetActiveKey(SigningAlgorithmRSAWithSHA256)
 	assert.NoError(t, err)
-	assert.NotNil(t, privKey)
+	assert.Equal(t, key, active)
+	assert.Equal(t, kid, manager.GetActiveKeyID(SigningAlgorithmRSAWithSHA256))
 
-	pubKey, err := manager.GetActiveKey()
-	assert.NoError(t, err)
-	assert.NotNil(t, pubKey)
+	keySet := manager.GetKeySet()
+	require.Len(t, keySet.Keys, 1)
+	assert.Equal(t, kid, keySet.Keys[0].KeyID)
+	assert.Equal(t, SigningAlgorithmRSAWithSHA256, keySet.Keys[0].Algorithm)
 
-	webKey, err := manager.GetActiveWebKey()
-	assert.NoError(t, err)
-	assert.NotNil(t, webKey)
+	_, err = manager.AddPrivateKeyData("", "", exampleIssuerPrivateKey, time.Time{})
+	assert.EqualError(t, err, fmt.Sprintf("key id %s already exists", kid))
+}
+
+func TestKeyManager_ShouldInferAndValidateAlgorithms(t *testing.T) {
+	manager := NewKeyManager()
+
+	key, err := manager.AddPrivateKeyData("es256", "", exampleECDSAPrivateKey(t, elliptic.P256()), time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, SigningAlgorithmECDSAWithP256AndSHA256, key.Algorithm)
+
+	key, err = manager.AddPrivateKeyData("es384", "", exampleECDSAPrivateKey(t, elliptic.P384()), time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, SigningAlgorithmECDSAWithP384AndSHA384, key.Algorithm)
+
+	key, err = manager.AddPrivateKeyData("ps256", SigningAlgorithmRSAPSSWithSHA256, exampleIssuerPrivateKey, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, SigningAlgorithmRSAPSSWithSHA256, key.Algorithm)
+
+	_, err = manager.AddPrivateKeyData("bad", SigningAlgorithmECDSAWithP384AndSHA384, exampleECDSAPrivateKey(t, elliptic.P256()), time.Time{})
+	assert.EqualError(t, err, "the ES384 signing algorithm can't be used with a private key of type *ecdsa.PrivateKey")
+
+	_, err = manager.AddPrivateKeyData("bad", SigningAlgorithmRSAWithSHA256, exampleECDSAPrivateKey(t, elliptic.P256()), time.Time{})
+	assert.EqualError(t, err, "the RS256 signing algorithm can't be used with a private key of type *ecdsa.PrivateKey")
+
+	_, err = manager.AddPrivateKeyData("bad", "", exampleECDSAPrivateKey(t, elliptic.P521()), time.Time{})
+	assert.EqualError(t, err, "unsupported elliptic curve P-521")
+
+	_, err = manager.AddPrivateKeyData("bad", "", "not a key", time.Time{})
+	assert.EqualError(t, err, "failed to parse PEM block containing the key")
+
+	assert.Equal(t, []string{SigningAlgorithmECDSAWithP256AndSHA256, SigningAlgorithmECDSAWithP384AndSHA384, SigningAlgorithmRSAPSSWithSHA256}, manager.Algorithms())
+}
+
+func TestKeyManager_ShouldRotateKeys(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	clock := &testClock{now: now}
+
+	manager, err := NewKeyManagerWithConfiguration(&schema.OpenIDConnectConfiguration{
+		IDTokenLifespan:     time.Hour,
+		AccessTokenLifespan: time.Hour * 2,
+		IssuerPrivateKey:    exampleIssuerPrivateKey,
+		IssuerPrivateKeys: []schema.OpenIDConnectIssuerPrivateKeyConfiguration{
+			{
+				KeyID:      "next",
+				Algorithm:  SigningAlgorithmRSAWithSHA256,
+				Key:        exampleECDSAPrivateKey(t, elliptic.P256()),
+				ActivateAt: now.Add(time.Hour),
+			},
+		},
+	})
+	assert.Nil(t, manager)
+	assert.EqualError(t, err, "the RS256 signing algorithm can't be used with a private key of type *ecdsa.PrivateKey")
+
+	manager, err = NewKeyManagerWithConfiguration(&schema.OpenIDConnectConfiguration{
+		IDTokenLifespan:     time.Hour,
+		AccessTokenLifespan: time.Hour * 2,
+		IssuerPrivateKey:    exampleIssuerPrivateKey,
+		IssuerPrivateKeys: []schema.OpenIDConnectIssuerPrivateKeyConfiguration{
+			{
+				KeyID:      "next",
+				Key:        exampleECDSAPrivateKey(t, elliptic.P256()),
+				ActivateAt: now.Add(time.Hour),
+			},
+			{
+				KeyID:      "rotated",
+				Algorithm:  SigningAlgorithmRSAWithSHA256,
+				Key:        exampleIssuerPrivateKey,
+				ActivateAt: now.Add(time.Hour),
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour*2, manager.retention)
+
+	manager.clock = clock
+
+	legacyKeyID := manager.GetActiveKeyID(SigningAlgorithmRSAWithSHA256)
+	assert.NotEqual(t, "rotated", legacyKeyID)
+	assert.Equal(t, "", manager.GetActiveKeyID(SigningAlgorithmECDSAWithP256AndSHA256))
+	assert.Equal(t, []string{SigningAlgorithmRSAWithSHA256}, manager.Algorithms())
+
+	// Upcoming keys are published before they become active.
+	assert.Len(t, manager.GetKeySet().Keys, 3)
+
+	token, _, err := manager.Strategy().Generate(context.Background(), jwt.MapClaims{"sub": "john"}, &jwt.Headers{})
+	require.NoError(t, err)
+
+	clock.now = now.Add(time.Hour)
+
+	assert.Equal(t, "rotated", manager.GetActiveKeyID(SigningAlgorithmRSAWithSHA256))
+	assert.Equal(t, "next", manager.GetActiveKeyID(SigningAlgorithmECDSAWithP256AndSHA256))
+	assert.Equal(t, []string{SigningAlgorithmRSAWithSHA256, SigningAlgorithmECDSAWithP256AndSHA256}, manager.Algorithms())
+
+	// Superseded keys are still published and accepted until the retention period elapses.
+	assert.Len(t, manager.GetKeySet().Keys, 3)
+
+	decoded, err := manager.Strategy().Decode(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, legacyKeyID, decoded.Header["kid"])
+
+	clock.now = now.Add(time.Hour * 3)
 
 	keySet := manager.GetKeySet()
-	assert.NotNil(t, keySet)
-	assert.Equal(t, kid, manager.GetActiveKeyID())
+	require.Len(t, keySet.Keys, 2)
+	assert.Equal(t, "next", keySet.Keys[0].KeyID)
+	assert.Equal(t, "rotated", keySet.Keys[1].KeyID)
+
+	_, err = manager.Strategy().Decode(context.Background(), token)
+	assert.EqualError(t, err, fmt.Sprintf("failed to retrieve a published key with the key id %s", legacyKeyID))
+}
+
+func TestJWTStrategy_ShouldSignWithTheAlgorithmOfTheKeyID(t *testing.T) {
+	manager, err := NewKeyManagerWithConfiguration(&schema.OpenIDConnectConfiguration{
+		IssuerPrivateKey: exampleIssuerPrivateKey,
+		IssuerPrivateKeys: []schema.OpenIDConnectIssuerPrivateKeyConfiguration{
+			{
+				KeyID: "ec",
+				Key:   exampleECDSAPrivateKey(t, elliptic.P384()),
+			},
+			{
+				KeyID:     "ps",
+				Algorithm: SigningAlgorithmRSAPSSWithSHA256,
+				Key:       exampleIssuerPrivateKey,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	strategy := manager.Strategy()
+
+	for _, tc := range []struct {
+		keyID, expectedKeyID, expectedAlgorithm string
+	}{
+		{"", manager.GetActiveKeyID(SigningAlgorithmRSAWithSHA256), SigningAlgorithmRSAWithSHA256},
+		{"ec", "ec", SigningAlgorithmECDSAWithP384AndSHA384},
+		{"ps", "ps", SigningAlgorithmRSAPSSWithSHA256},
+	} {
+		t.Run(tc.expectedAlgorithm, func(t *testing.T) {
+			headers := &jwt.Headers{Extra: map[string]interface{}{}}
+			if tc.keyID != "" {
+				headers.Add("kid", tc.keyID)
+			}
+
+			token, signature, err := strategy.Generate(context.Background(), jwt.MapClaims{"sub": "john"}, headers)
+			require.NoError(t, err)
+			assert.True(t, strings.HasSuffix(token, signature))
+
+			decoded, err := strategy.Decode(context.Background(), token)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedKeyID, decoded.Header["kid"])
+			assert.Equal(t, tc.expectedAlgorithm, string(decoded.Method))
+
+			validated, err := strategy.Validate(context.Background(), token)
+			assert.NoError(t, err)
+			assert.Equal(t, signature, validated)
+		})
+	}
+
+	keyID, err := strategy.GetPublicKeyID(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, manager.GetActiveKeyID(SigningAlgorithmRSAWithSHA256), keyID)
+}
+
+func TestKeyManager_ShouldRequireActiveRS256Key(t *testing.T) {
+	manager, err := NewKeyManagerWithConfiguration(&schema.OpenIDConnectConfiguration{
+		IssuerPrivateKeys: []schema.OpenIDConnectIssuerPrivateKeyConfiguration{
+			{
+				KeyID: "ec",
+				Key:   exampleECDSAPrivateKey(t, elliptic.P256()),
+			},
+		},
+	})
+
+	assert.Nil(t, manager)
+	assert.EqualError(t, err, "failed to retrieve an active key for the RS256 algorithm")
 }
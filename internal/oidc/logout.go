@@ -0,0 +1,154 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ory/fosite/token/jwt"
+
+	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/session"
+)
+
+// DecodeIDTokenHint decodes an ID token issued by this provider which is used as the id_token_hint of a logout
+// request. The signature must be valid but the token is allowed to have expired.
+func (p OpenIDConnectProvider) DecodeIDTokenHint(ctx context.Context, token string) (claims jwt.MapClaims, err error) {
+	decoded, err := p.KeyManager.Strategy().Decode(ctx, token)
+	if err != nil {
+		var validationErr *jwt.ValidationError
+
+		if !errors.As(err, &validationErr) || validationErr.Errors != jwt.ValidationErrorExpired {
+			return nil, err
+		}
+	}
+
+	return decoded.Claims, nil
+}
+
+// NewLogoutToken creates the logout token sent to the back-channel logout URI of a client, see
+// https://openid.net/specs/openid-connect-backchannel-1_0.html#LogoutToken.
+func (p OpenIDConnectProvider) NewLogoutToken(ctx context.Context, client *InternalClient, subject string,
+	logoutSession *session.OIDCLogoutSession, now time.Time) (token string, err error) {
+	claims := jwt.MapClaims{
+		"iss": logoutSession.Issuer,
+		"sub": subject,
+		"aud": []string{client.ID},
+		"iat": now.Unix(),
+		"exp": now.Add(logoutTokenLifespan).Unix(),
+		"jti": uuid.New().String(),
+		"sid": logoutSession.ID,
+		"events": map[string]interface{}{
+			BackChannelLogoutEvent: map[string]interface{}{},
+		},
+	}
+
+	headers := logoutTokenHeaders{
+		"kid": p.KeyManager.GetActiveKeyID(client.IDTokenSigningAlgorithm),
+		"typ": logoutTokenType,
+	}
+
+	token, _, err = p.KeyManager.Strategy().Generate(ctx, claims, headers)
+
+	return token, err
+}
+
+// BackChannelLogout sends a logout token to the back-channel logout URI of every client the user signed in to during
+// the session. It returns once every client responded or the requests timed out, errors are only logged as the
+// session is already gone.
+func (p OpenIDConnectProvider) BackChannelLogout(ctx context.Context, subject string, logoutSession *session.OIDCLogoutSession, now time.Time) {
+	if logoutSession == nil {
+		return
+	}
+
+	logger := logging.Logger()
+
+	var wg sync.WaitGroup
+
+	for _, id := range logoutSession.ClientIDs {
+		client, err := p.Store.GetInternalClient(id)
+		if err != nil {
+			logger.Warnf("Unable to notify OpenID Connect client %s of the logout of user %s: %v", id, subject, err)
+			continue
+		}
+
+		if client.BackChannelLogoutURI == "" {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(client *InternalClient) {
+			defer wg.Done()
+
+			if err := p.sendLogoutToken(ctx, client, subject, logoutSession, now); err != nil {
+				logger.Errorf("Unable to notify OpenID Connect client %s of the logout of user %s: %v", client.ID, subject, err)
+				return
+			}
+
+			logger.Debugf("Notified OpenID Connect client %s of the logout of user %s", client.ID, subject)
+		}(client)
+	}
+
+	wg.Wait()
+}
+
+func (p OpenIDConnectProvider) sendLogoutToken(ctx context.Context, client *InternalClient, subject string,
+	logoutSession *session.OIDCLogoutSession, now time.Time) (err error) {
+	token, err := p.NewLogoutToken(ctx, client, subject, logoutSession, now)
+	if err != nil {
+		return err
+	}
+
+	body := url.Values{"logout_token": []string{token}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, client.BackChannelLogoutURI, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("the back-channel logout URI responded with status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// logoutTokenHeaders are the headers of a logout token. Unlike jwt.Headers the typ header is kept.
+type logoutTokenHeaders map[string]interface{}
+
+// ToMap returns a copy of the headers.
+func (h logoutTokenHeaders) ToMap() map[string]interface{} {
+	headers := make(map[string]interface{}, len(h))
+
+	for key, value := range h {
+		headers[key] = value
+	}
+
+	return headers
+}
+
+// Add sets a header.
+func (h logoutTokenHeaders) Add(key string, value interface{}) {
+	h[key] = value
+}
+
+// Get returns a header.
+func (h logoutTokenHeaders) Get(key string) interface{} {
+	return h[key]
+}
@@ -0,0 +1,165 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite/token/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/session"
+)
+
+func newTestLogoutProvider(t *testing.T, backChannelLogoutURI string) OpenIDConnectProvider {
+	provider, err := NewOpenIDConnectProvider(&schema.OpenIDConnectConfiguration{
+		IssuerPrivateKey: exampleIssuerPrivateKey,
+		HMACSecret:       "asbdhaaskmdlkamdklasmdlkams",
+		Clients: []schema.OpenIDConnectClientConfiguration{
+			{
+				ID:                      "app",
+				Secret:                  "app-secret",
+				Policy:                  "two_factor",
+				IDTokenSigningAlgorithm: SigningAlgorithmRSAWithSHA256,
+				BackChannelLogoutURI:    backChannelLogoutURI,
+			},
+			{
+				ID:     "other",
+				Secret: "other-secret",
+				Policy: "two_factor",
+			},
+		},
+	}, nil, nil)
+	require.NoError(t, err)
+
+	return provider
+}
+
+func TestOpenIDConnectProvider_NewLogoutToken(t *testing.T) {
+	provider := newTestLogoutProvider(t, "https://app.example.com/backchannel")
+	now := time.Now().Truncate(time.Second)
+
+	client, err := provider.Store.GetInternalClient("app")
+	require.NoError(t, err)
+
+	token, err := provider.NewLogoutToken(context.Background(), client, "john", &session.OIDCLogoutSession{
+		ID:        "sid-1",
+		Issuer:    "https://auth.example.com",
+		ClientIDs: []string{"app"},
+	}, now)
+	require.NoError(t, err)
+
+	decoded, err := provider.KeyManager.Strategy().Decode(context.Background(), token)
+	require.NoError(t, err)
+
+	assert.Equal(t, logoutTokenType, decoded.Header["typ"])
+	assert.Equal(t, provider.KeyManager.GetActiveKeyID(SigningAlgorithmRSAWithSHA256), decoded.Header["kid"])
+
+	assert.Equal(t, "https://auth.example.com", decoded.Claims["iss"])
+	assert.Equal(t, "john", decoded.Claims["sub"])
+	assert.Equal(t, []interface{}{"app"}, decoded.Claims["aud"])
+	assert.Equal(t, "sid-1", decoded.Claims["sid"])
+	assert.EqualValues(t, now.Unix(), decoded.Claims["iat"])
+	assert.EqualValues(t, now.Add(logoutTokenLifespan).Unix(), decoded.Claims["exp"])
+	assert.NotEmpty(t, decoded.Claims["jti"])
+	assert.Nil(t, decoded.Claims["nonce"])
+	assert.Equal(t, map[string]interface{}{BackChannelLogoutEvent: map[string]interface{}{}}, decoded.Claims["events"])
+}
+
+func TestOpenIDConnectProvider_BackChannelLogout(t *testing.T) {
+	var (
+		mutex  sync.Mutex
+		tokens []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+
+		mutex.Lock()
+		tokens = append(tokens, r.PostFormValue("logout_token"))
+		mutex.Unlock()
+
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	provider := newTestLogoutProvider(t, server.URL)
+
+	provider.BackChannelLogout(context.Background(), "john", &session.OIDCLogoutSession{
+		ID:        "sid-1",
+		Issuer:    "https://auth.example.com",
+		ClientIDs: []string{"app", "other", "missing"},
+	}, time.Now())
+
+	require.Len(t, tokens, 1)
+
+	decoded, err := provider.KeyManager.Strategy().Decode(context.Background(), tokens[0])
+	require.NoError(t, err)
+	assert.Equal(t, "sid-1", decoded.Claims["sid"])
+
+	// Nothing is sent when the user didn't sign in to any client.
+	provider.BackChannelLogout(context.Background(), "john", nil, time.Now())
+
+	assert.Len(t, tokens, 1)
+}
+
+func TestOpenIDConnectProvider_ShouldReportBackChannelLogoutFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	provider := newTestLogoutProvider(t, server.URL)
+
+	client, err := provider.Store.GetInternalClient("app")
+	require.NoError(t, err)
+
+	err = provider.sendLogoutToken(context.Background(), client, "john", &session.OIDCLogoutSession{
+		ID:     "sid-1",
+		Issuer: "https://auth.example.com",
+	}, time.Now())
+
+	assert.EqualError(t, err, "the back-channel logout URI responded with status code 400")
+}
+
+func TestOpenIDConnectProvider_DecodeIDTokenHint(t *testing.T) {
+	provider := newTestLogoutProvider(t, "")
+	strategy := provider.KeyManager.Strategy()
+
+	expired, _, err := strategy.Generate(context.Background(), jwt.MapClaims{
+		"sub": "john",
+		"aud": []string{"app"},
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	}, &jwt.Headers{})
+	require.NoError(t, err)
+
+	claims, err := provider.DecodeIDTokenHint(context.Background(), expired)
+	require.NoError(t, err)
+	assert.Equal(t, "john", claims["sub"])
+
+	other := newTestLogoutProvider(t, "")
+	other.KeyManager = NewKeyManager()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, err = other.KeyManager.AddPrivateKey("", SigningAlgorithmRSAWithSHA256, rsaKey, time.Time{})
+	require.NoError(t, err)
+
+	forged, _, err := other.KeyManager.Strategy().Generate(context.Background(), jwt.MapClaims{"sub": "john"}, &jwt.Headers{})
+	require.NoError(t, err)
+
+	_, err = provider.DecodeIDTokenHint(context.Background(), forged)
+	assert.Error(t, err)
+
+	_, err = provider.DecodeIDTokenHint(context.Background(), "not a token")
+	assert.Error(t, err)
+}
@@ -0,0 +1,192 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ory/fosite"
+
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/session"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// NewPushedAuthorizeResponse authenticates the client, validates the authorization request and stores it for use with
+// the authorization endpoint, see RFC 9126 section 2.1. The issuer is the audience of the request objects.
+func (p OpenIDConnectProvider) NewPushedAuthorizeResponse(ctx context.Context, r *http.Request, issuer string) (response *PushedAuthorizeResponse, err error) {
+	f, ok := p.Fosite.(*fosite.Fosite)
+	if !ok {
+		return nil, fosite.ErrServerError.WithDebug("The OAuth 2.0 provider is not able to authenticate clients.")
+	}
+
+	if r.Method != http.MethodPost {
+		return nil, fosite.ErrInvalidRequest.WithHintf("HTTP method is '%s', expected 'POST'.", r.Method)
+	}
+
+	if err = r.ParseForm(); err != nil {
+		return nil, fosite.ErrInvalidRequest.WithHint("Unable to parse HTTP body, make sure to send a properly formatted form request body.").WithWrap(err).WithDebug(err.Error())
+	}
+
+	c, err := f.AuthenticateClient(ctx, r, r.PostForm)
+	if err != nil {
+		return nil, err
+	}
+
+	client, ok := c.(*InternalClient)
+	if !ok {
+		return nil, fosite.ErrServerError.WithDebug("The OAuth 2.0 Client is not an internal client.")
+	}
+
+	form := url.Values{}
+
+	for key, values := range r.PostForm {
+		if !utils.IsStringInSlice(key, pushedAuthorizeRequestSensitiveParameters) {
+			form[key] = values
+		}
+	}
+
+	switch {
+	case form.Get("request_uri") != "":
+		return nil, fosite.ErrInvalidRequest.WithHint("The 'request_uri' parameter can not be used with pushed authorization requests.")
+	case form.Get("client_id") != "" && form.Get("client_id") != client.ID:
+		return nil, fosite.ErrInvalidRequest.WithHint("The 'client_id' parameter does not match the authenticated OAuth 2.0 Client.")
+	}
+
+	form.Set("client_id", client.ID)
+
+	if form, err = p.resolveRequestObject(ctx, client, form, issuer); err != nil {
+		return nil, err
+	}
+
+	if err = p.validatePushedAuthorizeRequest(ctx, f, form); err != nil {
+		return nil, err
+	}
+
+	reference, err := newPushedAuthorizeRequestReference()
+	if err != nil {
+		return nil, fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+	}
+
+	requestURI := PushedAuthorizeRequestURIPrefix + reference
+	now := time.Now()
+
+	err = p.Store.CreatePushedAuthorizeRequest(requestURI, models.OAuth2PushedAuthorizeRequest{
+		ClientID:    client.ID,
+		RequestedAt: now,
+		ExpiresAt:   now.Add(p.pushedAuthorizeRequestLifespan),
+		Form:        form.Encode(),
+	})
+	if err != nil {
+		return nil, fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+	}
+
+	return &PushedAuthorizeResponse{
+		RequestURI: requestURI,
+		ExpiresIn:  int64(p.pushedAuthorizeRequestLifespan / time.Second),
+	}, nil
+}
+
+// WritePushedAuthorizeError writes an error of the pushed authorization request endpoint, the errors have the same
+// format as the errors of the token endpoint, see RFC 9126 section 2.3.
+func (p OpenIDConnectProvider) WritePushedAuthorizeError(rw http.ResponseWriter, err error) {
+	p.Fosite.WriteAccessError(rw, nil, err)
+}
+
+// ResolveAuthorizeRequest replaces the parameters of the authorization request with the parameters of the pushed
+// authorization request referenced by the request_uri parameter, or with the parameters of the request object, so the
+// request can be handled by fosite, see RFC 9126 section 4 and RFC 9101 section 6. The pushed authorization requests
+// can be used only once, the requestURI is returned when the request is a pushed authorization request so its
+// parameters can be kept in the workflow session, the parameters of the workflow are used when it references the same
+// pushed authorization request.
+func (p OpenIDConnectProvider) ResolveAuthorizeRequest(ctx context.Context, r *http.Request, issuer string, workflow *session.OIDCWorkflowSession) (requestURI string, err error) {
+	if err = r.ParseForm(); err != nil {
+		return "", fosite.ErrInvalidRequest.WithHint("Unable to parse the authorization request parameters.").WithWrap(err).WithDebug(err.Error())
+	}
+
+	form := r.Form
+
+	// The unknown clients are reported by fosite.
+	client, err := p.Store.GetInternalClient(form.Get("client_id"))
+	if err != nil {
+		return "", nil
+	}
+
+	if strings.HasPrefix(form.Get("request_uri"), PushedAuthorizeRequestURIPrefix) {
+		requestURI = form.Get("request_uri")
+
+		if form, err = p.loadPushedAuthorizeRequest(client, requestURI, workflow); err != nil {
+			return "", err
+		}
+	} else {
+		if client.RequirePushedAuthorizationRequests {
+			return "", fosite.ErrInvalidRequest.WithHint("The OAuth 2.0 Client must use pushed authorization requests.")
+		}
+
+		if form, err = p.resolveRequestObject(ctx, client, form, issuer); err != nil {
+			return "", err
+		}
+	}
+
+	r.URL.RawQuery = form.Encode()
+	r.Form = form
+
+	return requestURI, nil
+}
+
+// loadPushedAuthorizeRequest consumes the pushed authorization request and returns its parameters. The parameters kept
+// in the workflow session are returned when the workflow was started by the same pushed authorization request.
+func (p OpenIDConnectProvider) loadPushedAuthorizeRequest(client *InternalClient, requestURI string, workflow *session.OIDCWorkflowSession) (form url.Values, err error) {
+	if workflow != nil && workflow.ClientID == client.ID && workflow.PushedAuthorizeRequestURI == requestURI {
+		if form, err = url.ParseQuery(workflow.PushedAuthorizeRequestForm); err != nil {
+			return nil, fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+		}
+
+		return form, nil
+	}
+
+	request, err := p.Store.ConsumePushedAuthorizeRequest(requestURI)
+
+	switch {
+	case errors.Is(err, fosite.ErrNotFound):
+		return nil, fosite.ErrInvalidRequestURI.WithHint("The 'request_uri' parameter is not valid or has expired.")
+	case err != nil:
+		return nil, fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+	case request.ClientID != client.ID, time.Now().After(request.ExpiresAt):
+		return nil, fosite.ErrInvalidRequestURI.WithHint("The 'request_uri' parameter is not valid or has expired.")
+	}
+
+	if form, err = url.ParseQuery(request.Form); err != nil {
+		return nil, fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+	}
+
+	return form, nil
+}
+
+// validatePushedAuthorizeRequest validates the parameters of the pushed authorization request the same way they're
+// validated by the authorization endpoint, see RFC 9126 section 2.1.
+func (p OpenIDConnectProvider) validatePushedAuthorizeRequest(ctx context.Context, f *fosite.Fosite, form url.Values) (err error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/?"+form.Encode(), nil)
+	if err != nil {
+		return fosite.ErrServerError.WithWrap(err).WithDebug(err.Error())
+	}
+
+	_, err = f.NewAuthorizeRequest(ctx, r)
+
+	return err
+}
+
+func newPushedAuthorizeRequestReference() (reference string, err error) {
+	data := make([]byte, pushedAuthorizeRequestURIEntropy)
+
+	if _, err = rand.Read(data); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
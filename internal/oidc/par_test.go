@@ -0,0 +1,271 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/ory/fosite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/session"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+func newTestPushedAuthorizeProvider(t *testing.T, jwksURI string) (provider OpenIDConnectProvider, mock *storage.MockProvider, ctrl *gomock.Controller) {
+	ctrl = gomock.NewController(t)
+	mock = storage.NewMockProvider(ctrl)
+
+	provider, err := NewOpenIDConnectProvider(&schema.OpenIDConnectConfiguration{
+		IssuerPrivateKey:                   exampleIssuerPrivateKey,
+		HMACSecret:                         "asbdhaaskmdlkamdklasmdlkams",
+		AccessTokenLifespan:                time.Hour,
+		IDTokenLifespan:                    time.Hour,
+		RefreshTokenLifespan:               time.Hour,
+		PushedAuthorizationRequestLifespan: time.Minute * 5,
+		Clients: []schema.OpenIDConnectClientConfiguration{
+			{
+				ID:                                 "app",
+				Secret:                             "app-secret",
+				Policy:                             "two_factor",
+				RedirectURIs:                       []string{"https://app.example.com/callback"},
+				Scopes:                             []string{"openid", "profile"},
+				GrantTypes:                         []string{"authorization_code"},
+				ResponseTypes:                      []string{"code"},
+				ResponseModes:                      []string{"query"},
+				IDTokenSigningAlgorithm:            SigningAlgorithmRSAWithSHA256,
+				RequirePushedAuthorizationRequests: true,
+				RequirePKCE:                        true,
+				RequestObjectSigningAlgorithm:      SigningAlgorithmECDSAWithP256AndSHA256,
+				RequestURIs:                        []string{jwksURI + "/request.jwt"},
+				JWKSURI:                            jwksURI + "/jwks.json",
+			},
+			{
+				ID:                            "web",
+				Secret:                        "web-secret",
+				Policy:                        "two_factor",
+				RedirectURIs:                  []string{"https://web.example.com/callback"},
+				Scopes:                        []string{"openid"},
+				GrantTypes:                    []string{"authorization_code"},
+				ResponseTypes:                 []string{"code"},
+				ResponseModes:                 []string{"query"},
+				IDTokenSigningAlgorithm:       SigningAlgorithmRSAWithSHA256,
+				RequestObjectSigningAlgorithm: SigningAlgorithmRSAWithSHA256,
+			},
+		},
+	}, mock, nil)
+	require.NoError(t, err)
+
+	return provider, mock, ctrl
+}
+
+func newTestPushedAuthorizeRequest(form url.Values) *http.Request {
+	r, _ := http.NewRequest(http.MethodPost, "https://auth.example.com/api/oidc/pushed_authorization_request", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return r
+}
+
+func TestOpenIDConnectProvider_NewPushedAuthorizeResponse(t *testing.T) {
+	provider, mock, ctrl := newTestPushedAuthorizeProvider(t, "https://app.example.com")
+	defer ctrl.Finish()
+
+	var saved models.OAuth2PushedAuthorizeRequest
+
+	mock.EXPECT().
+		SaveOAuth2PushedAuthorizeRequest(gomock.Any()).
+		DoAndReturn(func(request models.OAuth2PushedAuthorizeRequest) error {
+			saved = request
+			return nil
+		})
+
+	r := newTestPushedAuthorizeRequest(url.Values{
+		"client_id":             []string{"app"},
+		"client_secret":         []string{"app-secret"},
+		"response_type":         []string{"code"},
+		"redirect_uri":          []string{"https://app.example.com/callback"},
+		"scope":                 []string{"openid profile"},
+		"state":                 []string{"abcdefghijklmnop"},
+		"code_challenge":        []string{"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"},
+		"code_challenge_method": []string{"S256"},
+	})
+
+	response, err := provider.NewPushedAuthorizeResponse(context.Background(), r, "https://auth.example.com")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(response.RequestURI, PushedAuthorizeRequestURIPrefix))
+	assert.Len(t, strings.TrimPrefix(response.RequestURI, PushedAuthorizeRequestURIPrefix), 43)
+	assert.Equal(t, int64(300), response.ExpiresIn)
+
+	assert.Equal(t, utils.HashSHA256FromString(response.RequestURI), saved.Signature)
+	assert.Equal(t, "app", saved.ClientID)
+	assert.Equal(t, time.Minute*5, saved.ExpiresAt.Sub(saved.RequestedAt))
+
+	form, err := url.ParseQuery(saved.Form)
+	require.NoError(t, err)
+
+	assert.Equal(t, "app", form.Get("client_id"))
+	assert.Equal(t, "openid profile", form.Get("scope"))
+	assert.Equal(t, "S256", form.Get("code_challenge_method"))
+	assert.NotContains(t, form, "client_secret")
+}
+
+func TestOpenIDConnectProvider_NewPushedAuthorizeResponse_ShouldRejectRequests(t *testing.T) {
+	provider, _, ctrl := newTestPushedAuthorizeProvider(t, "https://app.example.com")
+	defer ctrl.Finish()
+
+	base := url.Values{
+		"client_id":     []string{"app"},
+		"client_secret": []string{"app-secret"},
+		"response_type": []string{"code"},
+		"redirect_uri":  []string{"https://app.example.com/callback"},
+		"scope":         []string{"openid"},
+		"state":         []string{"abcdefghijklmnop"},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		key      string
+		value    string
+		expected string
+	}{
+		{"InvalidSecret", "client_secret", "bad", "invalid_client"},
+		{"RequestURI", "request_uri", PushedAuthorizeRequestURIPrefix + "abc", "invalid_request"},
+		{"InvalidRedirectURI", "redirect_uri", "https://evil.example.com/callback", "invalid_request"},
+		{"InvalidScope", "scope", "openid groups", "invalid_scope"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			form := url.Values{}
+
+			for key, values := range base {
+				form[key] = values
+			}
+
+			form.Set(tc.key, tc.value)
+
+			response, err := provider.NewPushedAuthorizeResponse(context.Background(), newTestPushedAuthorizeRequest(form), "https://auth.example.com")
+			assert.Nil(t, response)
+			require.Error(t, err)
+			assert.Equal(t, tc.expected, fosite.ErrorToRFC6749Error(err).ErrorField)
+		})
+	}
+}
+
+func TestOpenIDConnectProvider_ResolveAuthorizeRequest(t *testing.T) {
+	provider, mock, ctrl := newTestPushedAuthorizeProvider(t, "https://app.example.com")
+	defer ctrl.Finish()
+
+	requestURI := PushedAuthorizeRequestURIPrefix + "abc"
+	now := time.Now()
+
+	mock.EXPECT().
+		ConsumeOAuth2PushedAuthorizeRequest(utils.HashSHA256FromString(requestURI)).
+		Return(&models.OAuth2PushedAuthorizeRequest{
+			ClientID:    "app",
+			RequestedAt: now,
+			ExpiresAt:   now.Add(time.Minute),
+			Form:        "client_id=app&response_type=code&scope=openid&state=abcdefghijklmnop",
+		}, nil)
+
+	r, err := http.NewRequest(http.MethodGet, "https://auth.example.com/api/oidc/authorize?client_id=app&scope=profile&request_uri="+url.QueryEscape(requestURI), nil)
+	require.NoError(t, err)
+
+	resolved, err := provider.ResolveAuthorizeRequest(context.Background(), r, "https://auth.example.com", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, requestURI, resolved)
+	assert.Equal(t, "openid", r.Form.Get("scope"))
+	assert.Equal(t, "code", r.URL.Query().Get("response_type"))
+	assert.Equal(t, "", r.Form.Get("request_uri"))
+
+	// The pushed authorization request has been consumed, the authorization request made once the user has consented
+	// uses the parameters kept in the workflow session.
+	workflow := &session.OIDCWorkflowSession{
+		ClientID:                   "app",
+		PushedAuthorizeRequestURI:  requestURI,
+		PushedAuthorizeRequestForm: r.URL.RawQuery,
+	}
+
+	r, err = http.NewRequest(http.MethodGet, "https://auth.example.com/api/oidc/authorize?client_id=app&request_uri="+url.QueryEscape(requestURI), nil)
+	require.NoError(t, err)
+
+	resolved, err = provider.ResolveAuthorizeRequest(context.Background(), r, "https://auth.example.com", workflow)
+	require.NoError(t, err)
+
+	assert.Equal(t, requestURI, resolved)
+	assert.Equal(t, "openid", r.Form.Get("scope"))
+	assert.Equal(t, "abcdefghijklmnop", r.Form.Get("state"))
+
+	mock.EXPECT().
+		ConsumeOAuth2PushedAuthorizeRequest(utils.HashSHA256FromString(requestURI)).
+		Return(nil, storage.ErrNoOAuth2PushedAuthorizeRequest)
+
+	r, err = http.NewRequest(http.MethodGet, "https://auth.example.com/api/oidc/authorize?client_id=app&request_uri="+url.QueryEscape(requestURI), nil)
+	require.NoError(t, err)
+
+	_, err = provider.ResolveAuthorizeRequest(context.Background(), r, "https://auth.example.com", nil)
+	require.Error(t, err)
+	assert.Equal(t, "invalid_request_uri", fosite.ErrorToRFC6749Error(err).ErrorField)
+}
+
+func TestOpenIDConnectProvider_ResolveAuthorizeRequest_ShouldRejectRequests(t *testing.T) {
+	provider, mock, ctrl := newTestPushedAuthorizeProvider(t, "https://app.example.com")
+	defer ctrl.Finish()
+
+	now := time.Now()
+
+	mock.EXPECT().
+		ConsumeOAuth2PushedAuthorizeRequest(utils.HashSHA256FromString(PushedAuthorizeRequestURIPrefix+"expired")).
+		Return(&models.OAuth2PushedAuthorizeRequest{ClientID: "app", ExpiresAt: now.Add(-time.Minute)}, nil)
+
+	mock.EXPECT().
+		ConsumeOAuth2PushedAuthorizeRequest(utils.HashSHA256FromString(PushedAuthorizeRequestURIPrefix+"other")).
+		Return(&models.OAuth2PushedAuthorizeRequest{ClientID: "web", ExpiresAt: now.Add(time.Minute)}, nil)
+
+	mock.EXPECT().
+		ConsumeOAuth2PushedAuthorizeRequest(utils.HashSHA256FromString(PushedAuthorizeRequestURIPrefix+"missing")).
+		Return(nil, storage.ErrNoOAuth2PushedAuthorizeRequest)
+
+	for _, tc := range []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"PushedAuthorizationRequired", "client_id=app&response_type=code", "invalid_request"},
+		{"Expired", "client_id=app&request_uri=" + url.QueryEscape(PushedAuthorizeRequestURIPrefix+"expired"), "invalid_request_uri"},
+		{"OtherClient", "client_id=app&request_uri=" + url.QueryEscape(PushedAuthorizeRequestURIPrefix+"other"), "invalid_request_uri"},
+		{"Missing", "client_id=app&request_uri=" + url.QueryEscape(PushedAuthorizeRequestURIPrefix+"missing"), "invalid_request_uri"},
+		{"RequestObjectWithoutJWKS", "client_id=web&request=abc", "request_not_supported"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodGet, "https://auth.example.com/api/oidc/authorize?"+tc.query, nil)
+			require.NoError(t, err)
+
+			requestURI, err := provider.ResolveAuthorizeRequest(context.Background(), r, "https://auth.example.com", nil)
+			assert.Equal(t, "", requestURI)
+			require.Error(t, err)
+			assert.Equal(t, tc.expected, fosite.ErrorToRFC6749Error(err).ErrorField)
+		})
+	}
+}
+
+func TestOpenIDConnectProvider_ResolveAuthorizeRequest_ShouldIgnoreUnknownClients(t *testing.T) {
+	provider, _, ctrl := newTestPushedAuthorizeProvider(t, "https://app.example.com")
+	defer ctrl.Finish()
+
+	r, err := http.NewRequest(http.MethodGet, "https://auth.example.com/api/oidc/authorize?client_id=unknown&request=abc", nil)
+	require.NoError(t, err)
+
+	requestURI, err := provider.ResolveAuthorizeRequest(context.Background(), r, "https://auth.example.com", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", requestURI)
+	assert.Equal(t, "abc", r.URL.Query().Get("request"))
+}
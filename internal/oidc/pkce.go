@@ -0,0 +1,39 @@
+package oidc
+
+import (
+	"context"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/oauth2"
+	"github.com/ory/fosite/handler/pkce"
+)
+
+// NewPKCEHandlerFactory returns a compose.Factory which creates the PKCEHandler. Only the S256 challenge method is
+// supported.
+func NewPKCEHandlerFactory() compose.Factory {
+	return func(config *compose.Config, storage interface{}, strategy interface{}) interface{} {
+		return &PKCEHandler{
+			Handler: &pkce.Handler{
+				AuthorizeCodeStrategy:      strategy.(oauth2.AuthorizeCodeStrategy),
+				Storage:                    storage.(pkce.PKCERequestStorage),
+				Force:                      config.EnforcePKCE,
+				ForceForPublicClients:      config.EnforcePKCEForPublicClients,
+				EnablePlainChallengeMethod: false,
+			},
+		}
+	}
+}
+
+// HandleAuthorizeEndpointRequest rejects the authorization code requests without a code challenge of the clients
+// requiring PKCE, the other requests are handled by the fosite pkce.Handler.
+func (c *PKCEHandler) HandleAuthorizeEndpointRequest(ctx context.Context, ar fosite.AuthorizeRequester, resp fosite.AuthorizeResponder) error {
+	if client, ok := ar.GetClient().(*InternalClient); ok && client.RequirePKCE &&
+		ar.GetResponseTypes().Has("code") && ar.GetRequestForm().Get("code_challenge") == "" {
+		return fosite.ErrInvalidRequest.
+			WithHint("This client must include a code_challenge when performing the authorize code flow, but it is missing.").
+			WithDebug("The client is configured in a way that enforces PKCE.")
+	}
+
+	return c.Handler.HandleAuthorizeEndpointRequest(ctx, ar, resp)
+}
@@ -0,0 +1,47 @@
+package oidc
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/pkce"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPKCEHandler_ShouldRejectRequestsWithoutCodeChallenge(t *testing.T) {
+	handler := &PKCEHandler{Handler: &pkce.Handler{}}
+
+	for _, tc := range []struct {
+		name   string
+		client *InternalClient
+		form   url.Values
+	}{
+		{"RequirePKCE", &InternalClient{ID: "app", RequirePKCE: true}, url.Values{}},
+		{"PlainChallengeMethod", &InternalClient{ID: "app"}, url.Values{"code_challenge": []string{"abc"}, "code_challenge_method": []string{"plain"}}},
+		{"UnknownChallengeMethod", &InternalClient{ID: "app"}, url.Values{"code_challenge": []string{"abc"}, "code_challenge_method": []string{"S512"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ar := fosite.NewAuthorizeRequest()
+			ar.Client = tc.client
+			ar.ResponseTypes = fosite.Arguments{"code"}
+			ar.Form = tc.form
+
+			err := handler.HandleAuthorizeEndpointRequest(context.Background(), ar, fosite.NewAuthorizeResponse())
+			require.Error(t, err)
+			assert.Equal(t, "invalid_request", fosite.ErrorToRFC6749Error(err).ErrorField)
+		})
+	}
+}
+
+func TestPKCEHandler_ShouldIgnoreRequestsWithoutCode(t *testing.T) {
+	handler := &PKCEHandler{Handler: &pkce.Handler{}}
+
+	ar := fosite.NewAuthorizeRequest()
+	ar.Client = &InternalClient{ID: "app", RequirePKCE: true}
+	ar.ResponseTypes = fosite.Arguments{"id_token"}
+
+	assert.NoError(t, handler.HandleAuthorizeEndpointRequest(context.Background(), ar, fosite.NewAuthorizeResponse()))
+}
@@ -1,17 +1,23 @@
 package oidc
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"net/http"
 
+	"github.com/ory/fosite"
 	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/openid"
 	"github.com/ory/herodot"
 
 	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/storage"
 	"github.com/authelia/authelia/internal/utils"
 )
 
 // NewOpenIDConnectProvider new-ups a OpenIDConnectProvider.
-func NewOpenIDConnectProvider(configuration *schema.OpenIDConnectConfiguration) (provider OpenIDConnectProvider, err error) {
+func NewOpenIDConnectProvider(configuration *schema.OpenIDConnectConfiguration, storageProvider storage.Provider, certPool *x509.CertPool) (provider OpenIDConnectProvider, err error) {
 	provider = OpenIDConnectProvider{
 		Fosite: nil,
 	}
@@ -20,7 +26,7 @@ func NewOpenIDConnectProvider(configuration *schema.OpenIDConnectConfiguration)
 		return provider, nil
 	}
 
-	provider.Store, err = NewOpenIDConnectStore(configuration)
+	provider.Store, err = NewOpenIDConnectStore(configuration, storageProvider)
 	if err != nil {
 		return provider, err
 	}
@@ -40,10 +46,14 @@ func NewOpenIDConnectProvider(configuration *schema.OpenIDConnectConfiguration)
 	}
 
 	provider.KeyManager = keyManager
-
-	key, err := provider.KeyManager.GetActivePrivateKey()
-	if err != nil {
-		return provider, err
+	provider.deviceCodeLifespan = configuration.DeviceCodeLifespan
+	provider.deviceCodePollingInterval = configuration.DeviceCodePollingInterval
+	provider.pushedAuthorizeRequestLifespan = configuration.PushedAuthorizationRequestLifespan
+
+	for _, client := range configuration.Clients {
+		if err = provider.ValidateClientSigningAlgorithms(client); err != nil {
+			return provider, err
+		}
 	}
 
 	strategy := &compose.CommonStrategy{
@@ -52,10 +62,12 @@ func NewOpenIDConnectProvider(configuration *schema.OpenIDConnectConfiguration)
 			[]byte(utils.HashSHA256FromString(configuration.HMACSecret)),
 			nil,
 		),
-		OpenIDConnectTokenStrategy: compose.NewOpenIDConnectStrategy(
-			composeConfiguration,
-			key,
-		),
+		OpenIDConnectTokenStrategy: &openid.DefaultStrategy{
+			JWTStrategy:         provider.KeyManager.Strategy(),
+			Expiry:              composeConfiguration.GetIDTokenLifespan(),
+			Issuer:              composeConfiguration.IDTokenIssuer,
+			MinParameterEntropy: composeConfiguration.GetMinParameterEntropy(),
+		},
 		JWTStrategy: provider.KeyManager.Strategy(),
 	}
 
@@ -76,6 +88,7 @@ func NewOpenIDConnectProvider(configuration *schema.OpenIDConnectConfiguration)
 		compose.OAuth2RefreshTokenGrantFactory,
 		compose.OAuth2ResourceOwnerPasswordCredentialsFactory,
 		// compose.RFC7523AssertionGrantFactory,
+		NewDeviceCodeGrantFactory(configuration.DeviceCodePollingInterval),
 
 		compose.OpenIDConnectExplicitFactory,
 		compose.OpenIDConnectImplicitFactory,
@@ -85,19 +98,53 @@ func NewOpenIDConnectProvider(configuration *schema.OpenIDConnectConfiguration)
 		compose.OAuth2TokenIntrospectionFactory,
 		compose.OAuth2TokenRevocationFactory,
 
-		// compose.OAuth2PKCEFactory,
+		NewPKCEHandlerFactory(),
 	)
 
+	if f, ok := provider.Fosite.(*fosite.Fosite); ok {
+		f.ClientAuthenticationStrategy = NewClientAuthenticationStrategy(f.DefaultClientAuthenticationStrategy)
+	}
+
+	provider.httpClient = &http.Client{
+		Timeout: backChannelLogoutTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: utils.NewTLSConfig(&schema.TLSConfig{}, tls.VersionTLS12, certPool),
+		},
+	}
+
+	provider.jwks = NewJWKSFetcher(provider.httpClient)
+
 	provider.herodot = herodot.NewJSONWriter(nil)
 
 	return provider, nil
 }
 
+// ValidateClientSigningAlgorithms returns an error if there is no active issuer private key for one of the signing
+// algorithms used by the client.
+func (p OpenIDConnectProvider) ValidateClientSigningAlgorithms(client schema.OpenIDConnectClientConfiguration) (err error) {
+	for _, algorithm := range []string{client.IDTokenSigningAlgorithm, client.UserinfoSigningAlgorithm} {
+		if algorithm == "" || algorithm == SigningAlgorithmNone {
+			continue
+		}
+
+		if _, err = p.KeyManager.GetActiveKey(algorithm); err != nil {
+			return fmt.Errorf("OIDC client with ID '%s' requires an issuer private key: %w", client.ID, err)
+		}
+	}
+
+	return nil
+}
+
 // Write writes data with herodot.JSONWriter.
 func (p OpenIDConnectProvider) Write(w http.ResponseWriter, r *http.Request, e interface{}, opts ...herodot.EncoderOptions) {
 	p.herodot.Write(w, r, e, opts...)
 }
 
+// WriteCode writes data with a status code with herodot.JSONWriter.
+func (p OpenIDConnectProvider) WriteCode(w http.ResponseWriter, r *http.Request, code int, e interface{}, opts ...herodot.EncoderOptions) {
+	p.herodot.WriteCode(w, r, code, e, opts...)
+}
+
 // WriteError writes an error with herodot.JSONWriter.
 func (p OpenIDConnectProvider) WriteError(w http.ResponseWriter, r *http.Request, err error, opts ...herodot.Option) {
 	p.herodot.WriteError(w, r, err, opts...)
@@ -0,0 +1,228 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ory/fosite"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// NewJWKSFetcher returns a new JWKSFetcher using the provided http.Client.
+func NewJWKSFetcher(client *http.Client) *JWKSFetcher {
+	return &JWKSFetcher{
+		client: client,
+		keys:   map[string]*jose.JSONWebKeySet{},
+	}
+}
+
+// Resolve returns the JSON Web Key Set at the location. The cached set is returned unless forceRefresh is true or the
+// set has never been fetched.
+func (f *JWKSFetcher) Resolve(location string, forceRefresh bool) (keys *jose.JSONWebKeySet, err error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if keys, ok := f.keys[location]; ok && !forceRefresh {
+		return keys, nil
+	}
+
+	response, err := f.client.Get(location)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch the JSON Web Key Set from '%s': %w", location, err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to fetch the JSON Web Key Set from '%s': expected status code %d but got %d", location, http.StatusOK, response.StatusCode)
+	}
+
+	keys = &jose.JSONWebKeySet{}
+
+	if err = json.NewDecoder(response.Body).Decode(keys); err != nil {
+		return nil, fmt.Errorf("unable to decode the JSON Web Key Set from '%s': %w", location, err)
+	}
+
+	f.keys[location] = keys
+
+	return keys, nil
+}
+
+// resolveRequestObject returns the authorization request parameters of the request object passed by value with the
+// request parameter or by reference with the request_uri parameter, see RFC 9101 section 4 and 5. Only the parameters
+// of the request object are used, the other parameters of the form are ignored apart from the client_id parameter which
+// must match the client, see RFC 9101 section 6.3.
+func (p OpenIDConnectProvider) resolveRequestObject(ctx context.Context, client *InternalClient, form url.Values, issuer string) (resolved url.Values, err error) {
+	request, requestURI := form.Get("request"), form.Get("request_uri")
+
+	switch {
+	case request == "" && requestURI == "":
+		return form, nil
+	case request != "" && requestURI != "":
+		return nil, fosite.ErrInvalidRequest.WithHint("The 'request' and 'request_uri' parameters can not be used together.")
+	case client.JWKSURI == "":
+		return nil, fosite.ErrRequestNotSupported.WithHint("The OAuth 2.0 Client does not have a JSON Web Key Set URI to verify request objects.")
+	}
+
+	if requestURI != "" {
+		if request, err = p.fetchRequestObject(ctx, client, requestURI); err != nil {
+			return nil, err
+		}
+	}
+
+	claims, err := p.verifyRequestObject(client, request, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	if clientID := form.Get("client_id"); clientID != "" && clientID != client.ID {
+		return nil, fosite.ErrInvalidRequest.WithHint("The 'client_id' parameter does not match the OAuth 2.0 Client of the request object.")
+	}
+
+	resolved = url.Values{}
+
+	for key, value := range claims {
+		if utils.IsStringInSlice(key, requestObjectRegisteredClaims) {
+			continue
+		}
+
+		if resolved[key], err = requestObjectParameter(value); err != nil {
+			return nil, fosite.ErrInvalidRequestObject.WithHintf("The request object claim '%s' could not be decoded.", key).WithWrap(err).WithDebug(err.Error())
+		}
+	}
+
+	resolved.Set("client_id", client.ID)
+
+	return resolved, nil
+}
+
+func (p OpenIDConnectProvider) fetchRequestObject(ctx context.Context, client *InternalClient, requestURI string) (request string, err error) {
+	if !utils.IsStringInSlice(requestURI, client.RequestURIs) {
+		return "", fosite.ErrInvalidRequestURI.WithHint("The 'request_uri' parameter is not registered for the OAuth 2.0 Client.")
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURI, nil)
+	if err != nil {
+		return "", fosite.ErrInvalidRequestURI.WithWrap(err).WithDebug(err.Error())
+	}
+
+	r.Header.Set("Accept", "application/oauth-authz-req+jwt")
+
+	response, err := p.httpClient.Do(r)
+	if err != nil {
+		return "", fosite.ErrInvalidRequestURI.WithHint("Unable to fetch the request object from the 'request_uri' parameter.").WithWrap(err).WithDebug(err.Error())
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fosite.ErrInvalidRequestURI.WithHintf("Unable to fetch the request object from the 'request_uri' parameter, expected status code %d but got %d.", http.StatusOK, response.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, requestObjectMaxSize))
+	if err != nil {
+		return "", fosite.ErrInvalidRequestURI.WithHint("Unable to read the request object from the 'request_uri' parameter.").WithWrap(err).WithDebug(err.Error())
+	}
+
+	return string(body), nil
+}
+
+// verifyRequestObject verifies the signature and the registered claims of the request object and returns its claims.
+// The request objects must be signed with the algorithm registered for the client, unsigned request objects are
+// always rejected.
+func (p OpenIDConnectProvider) verifyRequestObject(client *InternalClient, request, issuer string) (claims map[string]interface{}, err error) {
+	token, err := jwt.ParseSigned(request)
+	if err != nil {
+		return nil, fosite.ErrInvalidRequestObject.WithHint("Unable to parse the request object.").WithWrap(err).WithDebug(err.Error())
+	}
+
+	if len(token.Headers) != 1 || token.Headers[0].Algorithm != client.RequestObjectSigningAlgorithm {
+		return nil, fosite.ErrInvalidRequestObject.WithHintf("The request object must be signed with the '%s' algorithm.", client.RequestObjectSigningAlgorithm)
+	}
+
+	registered := jwt.Claims{}
+
+	if err = p.verifyRequestObjectSignature(client, token, &registered, &claims); err != nil {
+		return nil, fosite.ErrInvalidRequestObject.WithHint("Unable to verify the signature of the request object.").WithWrap(err).WithDebug(err.Error())
+	}
+
+	if err = registered.ValidateWithLeeway(jwt.Expected{Time: time.Now()}, jwt.DefaultLeeway); err != nil {
+		return nil, fosite.ErrInvalidRequestObject.WithHint("The request object is expired or not yet valid.").WithWrap(err).WithDebug(err.Error())
+	}
+
+	switch {
+	case registered.Issuer != "" && registered.Issuer != client.ID:
+		return nil, fosite.ErrInvalidRequestObject.WithHint("The 'iss' claim of the request object must be the OAuth 2.0 Client ID.")
+	case len(registered.Audience) != 0 && !registered.Audience.Contains(issuer):
+		return nil, fosite.ErrInvalidRequestObject.WithHint("The 'aud' claim of the request object must contain the issuer.")
+	}
+
+	if clientID, ok := claims["client_id"]; ok && clientID != client.ID {
+		return nil, fosite.ErrInvalidRequestObject.WithHint("The 'client_id' claim of the request object must be the OAuth 2.0 Client ID.")
+	}
+
+	return claims, nil
+}
+
+// verifyRequestObjectSignature verifies the signature of the request object with the public keys of the client. The
+// keys are fetched again when none of the cached keys can verify the signature, as the client may have rotated them.
+func (p OpenIDConnectProvider) verifyRequestObjectSignature(client *InternalClient, token *jwt.JSONWebToken, dest ...interface{}) (err error) {
+	for _, forceRefresh := range []bool{false, true} {
+		var keys *jose.JSONWebKeySet
+
+		if keys, err = p.jwks.Resolve(client.JWKSURI, forceRefresh); err != nil {
+			return err
+		}
+
+		if err = verifyWithKeySet(token, keys, dest...); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+func verifyWithKeySet(token *jwt.JSONWebToken, keys *jose.JSONWebKeySet, dest ...interface{}) (err error) {
+	candidates := keys.Keys
+
+	if kid := token.Headers[0].KeyID; kid != "" {
+		candidates = keys.Key(kid)
+	}
+
+	err = fmt.Errorf("no public key to verify the signature")
+
+	for _, key := range candidates {
+		if !key.IsPublic() || key.Use == "enc" {
+			continue
+		}
+
+		if err = token.Claims(key, dest...); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// requestObjectParameter converts a claim of a request object to a parameter of the authorization request. Strings
+// are used as is and the other values are JSON encoded, like the claims parameter of OpenID Connect.
+func requestObjectParameter(value interface{}) (values []string, err error) {
+	if s, ok := value.(string); ok {
+		return []string{s}, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{string(encoded)}, nil
+}
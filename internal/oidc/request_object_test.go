@@ -0,0 +1,226 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+type testRequestObjectServer struct {
+	*httptest.Server
+
+	key     *ecdsa.PrivateKey
+	request string
+	fetches int
+}
+
+func newTestRequestObjectServer(t *testing.T) *testRequestObjectServer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	server := &testRequestObjectServer{key: key}
+
+	server.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/jwks.json":
+			server.fetches++
+
+			_ = json.NewEncoder(rw).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+				{Key: &server.key.PublicKey, KeyID: "app", Algorithm: "ES256", Use: "sig"},
+			}})
+		case "/request.jwt":
+			_, _ = rw.Write([]byte(server.request))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return server
+}
+
+func (s *testRequestObjectServer) sign(t *testing.T, algorithm jose.SignatureAlgorithm, key interface{}, claims map[string]interface{}) string {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: algorithm, Key: key}, (&jose.SignerOptions{}).WithHeader("kid", "app"))
+	require.NoError(t, err)
+
+	token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	require.NoError(t, err)
+
+	return token
+}
+
+func newTestRequestObjectClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss":           "app",
+		"aud":           "https://auth.example.com",
+		"exp":           time.Now().Add(time.Minute).Unix(),
+		"client_id":     "app",
+		"response_type": "code",
+		"scope":         "openid profile",
+		"claims":        map[string]interface{}{"id_token": map[string]interface{}{"name": nil}},
+		"max_age":       60,
+	}
+}
+
+func TestOpenIDConnectProvider_ResolveRequestObject(t *testing.T) {
+	server := newTestRequestObjectServer(t)
+	defer server.Close()
+
+	provider, _, ctrl := newTestPushedAuthorizeProvider(t, server.URL)
+	defer ctrl.Finish()
+
+	client, err := provider.Store.GetInternalClient("app")
+	require.NoError(t, err)
+
+	request := server.sign(t, jose.ES256, server.key, newTestRequestObjectClaims())
+
+	form, err := provider.resolveRequestObject(context.Background(), client, url.Values{
+		"client_id": []string{"app"},
+		"request":   []string{request},
+	}, "https://auth.example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, "app", form.Get("client_id"))
+	assert.Equal(t, "openid profile", form.Get("scope"))
+	assert.Equal(t, "code", form.Get("response_type"))
+	assert.Equal(t, "60", form.Get("max_age"))
+	assert.Equal(t, `{"id_token":{"name":null}}`, form.Get("claims"))
+	assert.NotContains(t, form, "request")
+	assert.NotContains(t, form, "iss")
+	assert.NotContains(t, form, "exp")
+
+	server.request = request
+
+	form, err = provider.resolveRequestObject(context.Background(), client, url.Values{
+		"client_id":   []string{"app"},
+		"request_uri": []string{server.URL + "/request.jwt"},
+	}, "https://auth.example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, "openid profile", form.Get("scope"))
+	assert.NotContains(t, form, "request_uri")
+
+	assert.Equal(t, 1, server.fetches)
+}
+
+func TestOpenIDConnectProvider_ResolveRequestObject_ShouldIgnoreParametersOutsideOfRequestObject(t *testing.T) {
+	server := newTestRequestObjectServer(t)
+	defer server.Close()
+
+	provider, _, ctrl := newTestPushedAuthorizeProvider(t, server.URL)
+	defer ctrl.Finish()
+
+	client, err := provider.Store.GetInternalClient("app")
+	require.NoError(t, err)
+
+	claims := newTestRequestObjectClaims()
+	delete(claims, "client_id")
+
+	form, err := provider.resolveRequestObject(context.Background(), client, url.Values{
+		"client_id":    []string{"app"},
+		"redirect_uri": []string{"https://evil.example.com/callback"},
+		"scope":        []string{"openid profile email groups"},
+		"state":        []string{"abcdefghijklmnop"},
+		"request":      []string{server.sign(t, jose.ES256, server.key, claims)},
+	}, "https://auth.example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, "app", form.Get("client_id"))
+	assert.Equal(t, "openid profile", form.Get("scope"))
+	assert.NotContains(t, form, "redirect_uri")
+	assert.NotContains(t, form, "state")
+
+	form, err = provider.resolveRequestObject(context.Background(), client, url.Values{
+		"client_id": []string{"web"},
+		"request":   []string{server.sign(t, jose.ES256, server.key, newTestRequestObjectClaims())},
+	}, "https://auth.example.com")
+	assert.Nil(t, form)
+	require.Error(t, err)
+	assert.Equal(t, "invalid_request", fosite.ErrorToRFC6749Error(err).ErrorField)
+}
+
+func TestOpenIDConnectProvider_ResolveRequestObject_ShouldRejectRequestObjects(t *testing.T) {
+	server := newTestRequestObjectServer(t)
+	defer server.Close()
+
+	provider, _, ctrl := newTestPushedAuthorizeProvider(t, server.URL)
+	defer ctrl.Finish()
+
+	client, err := provider.Store.GetInternalClient("app")
+	require.NoError(t, err)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	withClaim := func(key string, value interface{}) map[string]interface{} {
+		claims := newTestRequestObjectClaims()
+
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+
+		return claims
+	}
+
+	valid := server.sign(t, jose.ES256, server.key, newTestRequestObjectClaims())
+
+	for _, tc := range []struct {
+		name     string
+		form     url.Values
+		expected string
+	}{
+		{"BothParameters", url.Values{"request": []string{valid}, "request_uri": []string{server.URL + "/request.jwt"}}, "invalid_request"},
+		{"Malformed", url.Values{"request": []string{"abc"}}, "invalid_request_object"},
+		{"Unsigned", url.Values{"request": []string{"eyJhbGciOiJub25lIn0.eyJjbGllbnRfaWQiOiJhcHAifQ."}}, "invalid_request_object"},
+		{"WrongAlgorithm", url.Values{"request": []string{server.sign(t, jose.HS256, []byte("0123456789abcdef0123456789abcdef"), newTestRequestObjectClaims())}}, "invalid_request_object"},
+		{"WrongKey", url.Values{"request": []string{server.sign(t, jose.ES256, otherKey, newTestRequestObjectClaims())}}, "invalid_request_object"},
+		{"Expired", url.Values{"request": []string{server.sign(t, jose.ES256, server.key, withClaim("exp", time.Now().Add(-time.Hour).Unix()))}}, "invalid_request_object"},
+		{"WrongIssuer", url.Values{"request": []string{server.sign(t, jose.ES256, server.key, withClaim("iss", "web"))}}, "invalid_request_object"},
+		{"WrongAudience", url.Values{"request": []string{server.sign(t, jose.ES256, server.key, withClaim("aud", "https://evil.example.com"))}}, "invalid_request_object"},
+		{"WrongClientID", url.Values{"request": []string{server.sign(t, jose.ES256, server.key, withClaim("client_id", "web"))}}, "invalid_request_object"},
+		{"UnregisteredRequestURI", url.Values{"request_uri": []string{server.URL + "/other.jwt"}}, "invalid_request_uri"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			form, err := provider.resolveRequestObject(context.Background(), client, tc.form, "https://auth.example.com")
+			assert.Nil(t, form)
+			require.Error(t, err)
+			assert.Equal(t, tc.expected, fosite.ErrorToRFC6749Error(err).ErrorField)
+		})
+	}
+}
+
+func TestJWKSFetcher_ShouldRefreshKeysWhenRequested(t *testing.T) {
+	server := newTestRequestObjectServer(t)
+	defer server.Close()
+
+	fetcher := NewJWKSFetcher(server.Client())
+
+	keys, err := fetcher.Resolve(server.URL+"/jwks.json", false)
+	require.NoError(t, err)
+	require.Len(t, keys.Key("app"), 1)
+
+	_, err = fetcher.Resolve(server.URL+"/jwks.json", false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, server.fetches)
+
+	_, err = fetcher.Resolve(server.URL+"/jwks.json", true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, server.fetches)
+
+	_, err = fetcher.Resolve(server.URL+"/missing.json", false)
+	assert.EqualError(t, err, "unable to fetch the JSON Web Key Set from '"+server.URL+"/missing.json': expected status code 200 but got 404")
+}
@@ -2,46 +2,52 @@ package oidc
 
 import (
 	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/ory/fosite"
-	"github.com/ory/fosite/storage"
 	"gopkg.in/square/go-jose.v2"
 
 	"github.com/authelia/authelia/internal/authorization"
 	"github.com/authelia/authelia/internal/configuration/schema"
 	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/utils"
 )
 
-// NewOpenIDConnectStore returns a new OpenIDConnectStore using the provided schema.OpenIDConnectConfiguration.
-func NewOpenIDConnectStore(configuration *schema.OpenIDConnectConfiguration) (store *OpenIDConnectStore, err error) {
+// NewOpenIDConnectStore returns a new OpenIDConnectStore using the provided schema.OpenIDConnectConfiguration and
+// storage.Provider. The clients registered at runtime are only available once LoadClients has been called.
+func NewOpenIDConnectStore(configuration *schema.OpenIDConnectConfiguration, provider storage.Provider) (store *OpenIDConnectStore, err error) {
 	store = &OpenIDConnectStore{
-		memory: &storage.MemoryStore{
-			IDSessions:             map[string]fosite.Requester{},
-			Users:                  map[string]storage.MemoryUserRelation{},
-			AuthorizeCodes:         map[string]storage.StoreAuthorizeCode{},
-			AccessTokens:           map[string]fosite.Requester{},
-			RefreshTokens:          map[string]storage.StoreRefreshToken{},
-			PKCES:                  map[string]fosite.Requester{},
-			AccessTokenRequestIDs:  map[string]string{},
-			RefreshTokenRequestIDs: map[string]string{},
-		},
+		provider: provider,
 	}
 
+	store.configuredClients = make(map[string]*InternalClient)
 	store.clients = make(map[string]*InternalClient)
 
+	claimsPolicies := make(map[string]*ClaimsPolicy, len(configuration.ClaimsPolicies))
+
+	for _, policy := range configuration.ClaimsPolicies {
+		claimsPolicies[policy.Name] = NewClaimsPolicy(policy)
+	}
+
 	for _, client := range configuration.Clients {
 		policy := authorization.PolicyToLevel(client.Policy)
 		logging.Logger().Debugf("registering client %s with policy %s (%v)", client.ID, client.Policy, policy)
 
-		store.clients[client.ID] = NewClient(client)
+		store.configuredClients[client.ID] = NewClient(client)
+		store.configuredClients[client.ID].ClaimsPolicy = claimsPolicies[client.ClaimsPolicy]
+		store.clients[client.ID] = store.configuredClients[client.ID]
 	}
 
 	return store, nil
 }
 
 // GetClientPolicy retrieves the policy from the client with the matching provided id.
-func (s OpenIDConnectStore) GetClientPolicy(id string) (level authorization.Level) {
+func (s *OpenIDConnectStore) GetClientPolicy(id string) (level authorization.Level) {
 	client, err := s.GetInternalClient(id)
 	if err != nil {
 		return authorization.TwoFactor
@@ -51,7 +57,10 @@ func (s OpenIDConnectStore) GetClientPolicy(id string) (level authorization.Leve
 }
 
 // GetInternalClient returns a fosite.Client asserted as an InternalClient matching the provided id.
-func (s OpenIDConnectStore) GetInternalClient(id string) (client *InternalClient, err error) {
+func (s *OpenIDConnectStore) GetInternalClient(id string) (client *InternalClient, err error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
 	client, ok := s.clients[id]
 	if !ok {
 		return nil, fosite.ErrNotFound
@@ -61,138 +70,467 @@ func (s OpenIDConnectStore) GetInternalClient(id string) (client *InternalClient
 }
 
 // IsValidClientID returns true if the provided id exists in the OpenIDConnectProvider.Clients map.
-func (s OpenIDConnectStore) IsValidClientID(id string) (valid bool) {
+func (s *OpenIDConnectStore) IsValidClientID(id string) (valid bool) {
 	_, err := s.GetInternalClient(id)
 
 	return err == nil
 }
 
-// CreateOpenIDConnectSession decorates fosite's storage.MemoryStore CreateOpenIDConnectSession method.
+// IsConfiguredClient returns true if the client with the provided id is defined in the configuration, such clients
+// can't be changed at runtime.
+func (s *OpenIDConnectStore) IsConfiguredClient(id string) (configured bool) {
+	_, configured = s.configuredClients[id]
+
+	return configured
+}
+
+// LoadClients replaces the clients registered at runtime with the ones persisted by the storage provider. Clients
+// defined in the configuration always take precedence.
+func (s *OpenIDConnectStore) LoadClients() (err error) {
+	registered, err := s.provider.LoadOAuth2Clients()
+	if err != nil {
+		return fmt.Errorf("unable to load registered clients: %w", err)
+	}
+
+	clients := make(map[string]*InternalClient, len(s.configuredClients)+len(registered))
+
+	for id, client := range s.configuredClients {
+		clients[id] = client
+	}
+
+	for _, model := range registered {
+		if s.IsConfiguredClient(model.ClientID) {
+			logging.Logger().Warnf("Ignoring registered client %s as a client with the same ID is defined in the configuration", model.ClientID)
+			continue
+		}
+
+		clients[model.ClientID] = NewClientFromModel(model)
+	}
+
+	s.mutex.Lock()
+	s.clients = clients
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// StartClientReloadRoutine periodically reloads the clients registered at runtime from the storage provider so the
+// changes made by other instances are picked up. It never returns so it's expected to be run in a goroutine.
+func (s *OpenIDConnectStore) StartClientReloadRoutine() {
+	ticker := time.NewTicker(clientReloadInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.LoadClients(); err != nil {
+			logging.Logger().Errorf("Unable to reload OpenID Connect clients: %v", err)
+		}
+	}
+}
+
+// GetRegisteredClients returns every client registered at runtime.
+func (s *OpenIDConnectStore) GetRegisteredClients() (clients []models.OAuth2Client, err error) {
+	return s.provider.LoadOAuth2Clients()
+}
+
+// GetRegisteredClient returns the client registered at runtime with the matching provided id.
+func (s *OpenIDConnectStore) GetRegisteredClient(id string) (client *models.OAuth2Client, err error) {
+	if s.IsConfiguredClient(id) {
+		return nil, ErrClientConfigured
+	}
+
+	client, err = s.provider.LoadOAuth2Client(id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoOAuth2Client) {
+			return nil, fosite.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// CreateClient persists a new client and makes it available immediately.
+func (s *OpenIDConnectStore) CreateClient(client models.OAuth2Client) (err error) {
+	if s.IsValidClientID(client.ClientID) {
+		return ErrClientAlreadyExists
+	}
+
+	if err = s.provider.SaveOAuth2Client(client); err != nil {
+		return err
+	}
+
+	s.setClient(client.ClientID, NewClientFromModel(client))
+
+	return nil
+}
+
+// UpdateClient updates the metadata of a client registered at runtime. The secret is left untouched.
+func (s *OpenIDConnectStore) UpdateClient(client models.OAuth2Client) (err error) {
+	if s.IsConfiguredClient(client.ClientID) {
+		return ErrClientConfigured
+	}
+
+	if err = s.provider.UpdateOAuth2Client(client); err != nil {
+		return s.handleClientError(err)
+	}
+
+	return s.reloadClient(client.ClientID)
+}
+
+// RotateClientSecret replaces the secret of a client registered at runtime with the provided hash.
+func (s *OpenIDConnectStore) RotateClientSecret(id, hash string, now time.Time) (err error) {
+	if s.IsConfiguredClient(id) {
+		return ErrClientConfigured
+	}
+
+	if err = s.provider.UpdateOAuth2ClientSecret(id, hash, now); err != nil {
+		return s.handleClientError(err)
+	}
+
+	return s.reloadClient(id)
+}
+
+// DeleteClient removes a client registered at runtime.
+func (s *OpenIDConnectStore) DeleteClient(id string) (err error) {
+	if s.IsConfiguredClient(id) {
+		return ErrClientConfigured
+	}
+
+	if err = s.provider.DeleteOAuth2Client(id); err != nil {
+		return s.handleClientError(err)
+	}
+
+	s.mutex.Lock()
+	delete(s.clients, id)
+	s.mutex.Unlock()
+
+	return nil
+}
+
+func (s *OpenIDConnectStore) reloadClient(id string) (err error) {
+	client, err := s.GetRegisteredClient(id)
+	if err != nil {
+		return err
+	}
+
+	s.setClient(id, NewClientFromModel(*client))
+
+	return nil
+}
+
+func (s *OpenIDConnectStore) setClient(id string, client *InternalClient) {
+	s.mutex.Lock()
+	s.clients[id] = client
+	s.mutex.Unlock()
+}
+
+func (s *OpenIDConnectStore) handleClientError(err error) error {
+	if errors.Is(err, storage.ErrNoOAuth2Client) {
+		return fosite.ErrNotFound
+	}
+
+	return err
+}
+
+// PurgeExpired removes every expired session and blacklisted JTI from the storage provider.
+func (s *OpenIDConnectStore) PurgeExpired() (err error) {
+	return s.provider.PurgeExpiredOAuth2(time.Now())
+}
+
+// StartPurgeRoutine periodically removes every expired session and blacklisted JTI from the storage provider. It
+// never returns so it's expected to be run in a goroutine.
+func (s *OpenIDConnectStore) StartPurgeRoutine() {
+	ticker := time.NewTicker(storagePurgeInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.PurgeExpired(); err != nil {
+			logging.Logger().Errorf("Unable to purge expired OpenID Connect sessions: %v", err)
+		}
+	}
+}
+
+// CreateOpenIDConnectSession stores the OpenID Connect session for the given authorize code.
 func (s *OpenIDConnectStore) CreateOpenIDConnectSession(ctx context.Context, authorizeCode string, requester fosite.Requester) error {
-	return s.memory.CreateOpenIDConnectSession(ctx, authorizeCode, requester)
+	return s.saveSession(ctx, storage.OAuth2SessionTypeOpenIDConnect, authorizeCode, requester, fosite.AuthorizeCode)
 }
 
-// GetOpenIDConnectSession decorates fosite's storage.MemoryStore GetOpenIDConnectSession method.
+// GetOpenIDConnectSession loads the OpenID Connect session for the given authorize code.
 func (s *OpenIDConnectStore) GetOpenIDConnectSession(ctx context.Context, authorizeCode string, requester fosite.Requester) (fosite.Requester, error) {
-	return s.memory.GetOpenIDConnectSession(ctx, authorizeCode, requester)
+	r, _, err := s.loadSession(ctx, storage.OAuth2SessionTypeOpenIDConnect, authorizeCode, requester.GetSession())
+
+	return r, err
 }
 
-// DeleteOpenIDConnectSession decorates fosite's storage.MemoryStore DeleteOpenIDConnectSession method.
-func (s *OpenIDConnectStore) DeleteOpenIDConnectSession(ctx context.Context, authorizeCode string) error {
-	return s.memory.DeleteOpenIDConnectSession(ctx, authorizeCode)
+// DeleteOpenIDConnectSession removes the OpenID Connect session for the given authorize code.
+func (s *OpenIDConnectStore) DeleteOpenIDConnectSession(_ context.Context, authorizeCode string) error {
+	return s.provider.RevokeOAuth2Session(storage.OAuth2SessionTypeOpenIDConnect, authorizeCode)
 }
 
-// GetClient decorates fosite's storage.MemoryStore GetClient method.
+// GetClient returns the client with the matching provided id.
 func (s *OpenIDConnectStore) GetClient(_ context.Context, id string) (fosite.Client, error) {
 	return s.GetInternalClient(id)
 }
 
-// ClientAssertionJWTValid decorates fosite's storage.MemoryStore ClientAssertionJWTValid method.
-func (s *OpenIDConnectStore) ClientAssertionJWTValid(ctx context.Context, jti string) error {
-	return s.memory.ClientAssertionJWTValid(ctx, jti)
+// ClientAssertionJWTValid returns an error if the JTI is known or the DB check failed and nil if the JTI is not known.
+func (s *OpenIDConnectStore) ClientAssertionJWTValid(_ context.Context, jti string) error {
+	blacklisted, err := s.provider.LoadOAuth2BlacklistedJTI(jtiSignature(jti))
+
+	switch {
+	case errors.Is(err, storage.ErrNoOAuth2BlacklistedJTI):
+		return nil
+	case err != nil:
+		return err
+	case blacklisted.ExpiresAt.After(time.Now()):
+		return fosite.ErrJTIKnown
+	default:
+		return nil
+	}
 }
 
-// SetClientAssertionJWT decorates fosite's storage.MemoryStore SetClientAssertionJWT method.
+// SetClientAssertionJWT marks a JTI as known for the given expiry time.
 func (s *OpenIDConnectStore) SetClientAssertionJWT(ctx context.Context, jti string, exp time.Time) error {
-	return s.memory.SetClientAssertionJWT(ctx, jti, exp)
+	if err := s.ClientAssertionJWTValid(ctx, jti); err != nil {
+		return err
+	}
+
+	return s.provider.SaveOAuth2BlacklistedJTI(models.OAuth2BlacklistedJTI{
+		Signature: jtiSignature(jti),
+		ExpiresAt: exp,
+	})
 }
 
-// CreateAuthorizeCodeSession decorates fosite's storage.MemoryStore CreateAuthorizeCodeSession method.
+// CreateAuthorizeCodeSession stores the authorization request for the given authorize code.
 func (s *OpenIDConnectStore) CreateAuthorizeCodeSession(ctx context.Context, code string, req fosite.Requester) error {
-	return s.memory.CreateAuthorizeCodeSession(ctx, code, req)
+	return s.saveSession(ctx, storage.OAuth2SessionTypeAuthorizeCode, code, req, fosite.AuthorizeCode)
 }
 
-// GetAuthorizeCodeSession decorates fosite's storage.MemoryStore GetAuthorizeCodeSession method.
+// GetAuthorizeCodeSession loads the authorization request for the given authorize code. If the authorize code has
+// been invalidated the request is returned along with fosite.ErrInvalidatedAuthorizeCode.
 func (s *OpenIDConnectStore) GetAuthorizeCodeSession(ctx context.Context, code string, session fosite.Session) (fosite.Requester, error) {
-	return s.memory.GetAuthorizeCodeSession(ctx, code, session)
+	r, active, err := s.loadSession(ctx, storage.OAuth2SessionTypeAuthorizeCode, code, session)
+	if err != nil {
+		return nil, err
+	}
+
+	if !active {
+		return r, fosite.ErrInvalidatedAuthorizeCode
+	}
+
+	return r, nil
 }
 
-// InvalidateAuthorizeCodeSession decorates fosite's storage.MemoryStore InvalidateAuthorizeCodeSession method.
-func (s *OpenIDConnectStore) InvalidateAuthorizeCodeSession(ctx context.Context, code string) error {
-	return s.memory.InvalidateAuthorizeCodeSession(ctx, code)
+// InvalidateAuthorizeCodeSession marks the authorize code as used.
+func (s *OpenIDConnectStore) InvalidateAuthorizeCodeSession(_ context.Context, code string) error {
+	return s.provider.DeactivateOAuth2Session(storage.OAuth2SessionTypeAuthorizeCode, code)
 }
 
-// CreatePKCERequestSession decorates fosite's storage.MemoryStore CreatePKCERequestSession method.
+// CreatePKCERequestSession stores the PKCE request for the given authorize code.
 func (s *OpenIDConnectStore) CreatePKCERequestSession(ctx context.Context, code string, req fosite.Requester) error {
-	return s.memory.CreatePKCERequestSession(ctx, code, req)
+	return s.saveSession(ctx, storage.OAuth2SessionTypePKCEChallenge, code, req, fosite.AuthorizeCode)
 }
 
-// GetPKCERequestSession decorates fosite's storage.MemoryStore GetPKCERequestSession method.
+// GetPKCERequestSession loads the PKCE request for the given authorize code.
 func (s *OpenIDConnectStore) GetPKCERequestSession(ctx context.Context, code string, session fosite.Session) (fosite.Requester, error) {
-	return s.memory.GetPKCERequestSession(ctx, code, session)
+	r, _, err := s.loadSession(ctx, storage.OAuth2SessionTypePKCEChallenge, code, session)
+
+	return r, err
 }
 
-// DeletePKCERequestSession decorates fosite's storage.MemoryStore DeletePKCERequestSession method.
-func (s *OpenIDConnectStore) DeletePKCERequestSession(ctx context.Context, code string) error {
-	return s.memory.DeletePKCERequestSession(ctx, code)
+// DeletePKCERequestSession removes the PKCE request for the given authorize code.
+func (s *OpenIDConnectStore) DeletePKCERequestSession(_ context.Context, code string) error {
+	return s.provider.RevokeOAuth2Session(storage.OAuth2SessionTypePKCEChallenge, code)
 }
 
-// CreateAccessTokenSession decorates fosite's storage.MemoryStore CreateAccessTokenSession method.
+// CreateAccessTokenSession stores the request for the given access token signature.
 func (s *OpenIDConnectStore) CreateAccessTokenSession(ctx context.Context, signature string, req fosite.Requester) error {
-	return s.memory.CreateAccessTokenSession(ctx, signature, req)
+	return s.saveSession(ctx, storage.OAuth2SessionTypeAccessToken, signature, req, fosite.AccessToken)
 }
 
-// GetAccessTokenSession decorates fosite's storage.MemoryStore GetAccessTokenSession method.
+// GetAccessTokenSession loads the request for the given access token signature.
 func (s *OpenIDConnectStore) GetAccessTokenSession(ctx context.Context, signature string, session fosite.Session) (fosite.Requester, error) {
-	return s.memory.GetAccessTokenSession(ctx, signature, session)
+	r, _, err := s.loadSession(ctx, storage.OAuth2SessionTypeAccessToken, signature, session)
+
+	return r, err
 }
 
-// DeleteAccessTokenSession decorates fosite's storage.MemoryStore DeleteAccessTokenSession method.
-func (s *OpenIDConnectStore) DeleteAccessTokenSession(ctx context.Context, signature string) error {
-	return s.memory.DeleteAccessTokenSession(ctx, signature)
+// DeleteAccessTokenSession removes the request for the given access token signature.
+func (s *OpenIDConnectStore) DeleteAccessTokenSession(_ context.Context, signature string) error {
+	return s.provider.RevokeOAuth2Session(storage.OAuth2SessionTypeAccessToken, signature)
 }
 
-// CreateRefreshTokenSession decorates fosite's storage.MemoryStore CreateRefreshTokenSession method.
+// CreateRefreshTokenSession stores the request for the given refresh token signature.
 func (s *OpenIDConnectStore) CreateRefreshTokenSession(ctx context.Context, signature string, req fosite.Requester) error {
-	return s.memory.CreateRefreshTokenSession(ctx, signature, req)
+	return s.saveSession(ctx, storage.OAuth2SessionTypeRefreshToken, signature, req, fosite.RefreshToken)
 }
 
-// GetRefreshTokenSession decorates fosite's storage.MemoryStore GetRefreshTokenSession method.
+// GetRefreshTokenSession loads the request for the given refresh token signature. If the refresh token has been
+// revoked the request is returned along with fosite.ErrInactiveToken.
 func (s *OpenIDConnectStore) GetRefreshTokenSession(ctx context.Context, signature string, session fosite.Session) (fosite.Requester, error) {
-	return s.memory.GetRefreshTokenSession(ctx, signature, session)
+	r, active, err := s.loadSession(ctx, storage.OAuth2SessionTypeRefreshToken, signature, session)
+	if err != nil {
+		return nil, err
+	}
+
+	if !active {
+		return r, fosite.ErrInactiveToken
+	}
+
+	return r, nil
 }
 
-// DeleteRefreshTokenSession decorates fosite's storage.MemoryStore DeleteRefreshTokenSession method.
-func (s *OpenIDConnectStore) DeleteRefreshTokenSession(ctx context.Context, signature string) error {
-	return s.memory.DeleteRefreshTokenSession(ctx, signature)
+// DeleteRefreshTokenSession removes the request for the given refresh token signature.
+func (s *OpenIDConnectStore) DeleteRefreshTokenSession(_ context.Context, signature string) error {
+	return s.provider.RevokeOAuth2Session(storage.OAuth2SessionTypeRefreshToken, signature)
 }
 
-// Authenticate decorates fosite's storage.MemoryStore Authenticate method.
-func (s *OpenIDConnectStore) Authenticate(ctx context.Context, name string, secret string) error {
-	return s.memory.Authenticate(ctx, name, secret)
+// Authenticate is used by the resource owner password credentials grant which is not supported.
+func (s *OpenIDConnectStore) Authenticate(_ context.Context, _ string, _ string) error {
+	return fosite.ErrNotFound
 }
 
-// RevokeRefreshToken decorates fosite's storage.MemoryStore RevokeRefreshToken method.
-func (s *OpenIDConnectStore) RevokeRefreshToken(ctx context.Context, requestID string) error {
-	return s.memory.RevokeRefreshToken(ctx, requestID)
+// RevokeRefreshToken marks every refresh token of the given request id as inactive.
+func (s *OpenIDConnectStore) RevokeRefreshToken(_ context.Context, requestID string) error {
+	return s.provider.DeactivateOAuth2SessionByRequestID(storage.OAuth2SessionTypeRefreshToken, requestID)
 }
 
-// RevokeAccessToken decorates fosite's storage.MemoryStore RevokeAccessToken method.
-func (s *OpenIDConnectStore) RevokeAccessToken(ctx context.Context, requestID string) error {
-	return s.memory.RevokeAccessToken(ctx, requestID)
+// RevokeAccessToken removes every access token of the given request id.
+func (s *OpenIDConnectStore) RevokeAccessToken(_ context.Context, requestID string) error {
+	return s.provider.RevokeOAuth2SessionByRequestID(storage.OAuth2SessionTypeAccessToken, requestID)
 }
 
-// GetPublicKey decorates fosite's storage.MemoryStore GetPublicKey method.
-func (s *OpenIDConnectStore) GetPublicKey(ctx context.Context, issuer string, subject string, keyID string) (*jose.JSONWebKey, error) {
-	return s.memory.GetPublicKey(ctx, issuer, subject, keyID)
+// GetPublicKey is used by the JWT assertion grant which is not supported.
+func (s *OpenIDConnectStore) GetPublicKey(_ context.Context, _ string, _ string, _ string) (*jose.JSONWebKey, error) {
+	return nil, fosite.ErrNotFound
 }
 
-// GetPublicKeys decorates fosite's storage.MemoryStore GetPublicKeys method.
-func (s *OpenIDConnectStore) GetPublicKeys(ctx context.Context, issuer string, subject string) (*jose.JSONWebKeySet, error) {
-	return s.memory.GetPublicKeys(ctx, issuer, subject)
+// GetPublicKeys is used by the JWT assertion grant which is not supported.
+func (s *OpenIDConnectStore) GetPublicKeys(_ context.Context, _ string, _ string) (*jose.JSONWebKeySet, error) {
+	return nil, fosite.ErrNotFound
 }
 
-// GetPublicKeyScopes decorates fosite's storage.MemoryStore GetPublicKeyScopes method.
-func (s *OpenIDConnectStore) GetPublicKeyScopes(ctx context.Context, issuer string, subject string, keyID string) ([]string, error) {
-	return s.memory.GetPublicKeyScopes(ctx, issuer, subject, keyID)
+// GetPublicKeyScopes is used by the JWT assertion grant which is not supported.
+func (s *OpenIDConnectStore) GetPublicKeyScopes(_ context.Context, _ string, _ string, _ string) ([]string, error) {
+	return nil, fosite.ErrNotFound
 }
 
-// IsJWTUsed decorates fosite's storage.MemoryStore IsJWTUsed method.
+// IsJWTUsed returns true if the JTI is known.
 func (s *OpenIDConnectStore) IsJWTUsed(ctx context.Context, jti string) (bool, error) {
-	return s.memory.IsJWTUsed(ctx, jti)
+	if err := s.ClientAssertionJWTValid(ctx, jti); err != nil {
+		return true, nil
+	}
+
+	return false, nil
 }
 
-// MarkJWTUsedForTime decorates fosite's storage.MemoryStore MarkJWTUsedForTime method.
+// MarkJWTUsedForTime marks a JTI as known for the given expiry time.
 func (s *OpenIDConnectStore) MarkJWTUsedForTime(ctx context.Context, jti string, exp time.Time) error {
-	return s.memory.MarkJWTUsedForTime(ctx, jti, exp)
+	return s.SetClientAssertionJWT(ctx, jti, exp)
+}
+
+// CreateDeviceCodeSession stores the device authorization request for the given device code and user code.
+func (s *OpenIDConnectStore) CreateDeviceCodeSession(deviceCode, userCode string, session models.OAuth2DeviceCodeSession) error {
+	session.Signature = utils.HashSHA256FromString(deviceCode)
+	session.UserCodeSignature = utils.HashSHA256FromString(NormalizeUserCode(userCode))
+
+	return s.provider.SaveOAuth2DeviceCodeSession(session)
+}
+
+// GetDeviceCodeSession loads the device authorization request for the given device code.
+func (s *OpenIDConnectStore) GetDeviceCodeSession(deviceCode string) (*models.OAuth2DeviceCodeSession, error) {
+	return s.loadDeviceCodeSession(s.provider.LoadOAuth2DeviceCodeSession(utils.HashSHA256FromString(deviceCode)))
+}
+
+// GetDeviceCodeSessionByUserCode loads the device authorization request for the given user code.
+func (s *OpenIDConnectStore) GetDeviceCodeSessionByUserCode(userCode string) (*models.OAuth2DeviceCodeSession, error) {
+	return s.loadDeviceCodeSession(s.provider.LoadOAuth2DeviceCodeSessionByUserCode(utils.HashSHA256FromString(NormalizeUserCode(userCode))))
+}
+
+// UpdateDeviceCodeSession saves the user interaction and polling state of the device authorization request.
+func (s *OpenIDConnectStore) UpdateDeviceCodeSession(session models.OAuth2DeviceCodeSession) error {
+	return s.provider.UpdateOAuth2DeviceCodeSession(session)
+}
+
+// ConsumeDeviceCodeSession marks the approved device authorization request for the given device code as consumed. It
+// returns fosite.ErrNotFound when the request isn't approved anymore, usually because it has already been consumed.
+func (s *OpenIDConnectStore) ConsumeDeviceCodeSession(deviceCode string) error {
+	err := s.provider.ConsumeOAuth2DeviceCodeSession(utils.HashSHA256FromString(deviceCode))
+	if errors.Is(err, storage.ErrNoOAuth2DeviceCodeSession) {
+		return fosite.ErrNotFound
+	}
+
+	return err
+}
+
+// CreatePushedAuthorizeRequest stores the pushed authorization request for the given request URI.
+func (s *OpenIDConnectStore) CreatePushedAuthorizeRequest(requestURI string, request models.OAuth2PushedAuthorizeRequest) error {
+	request.Signature = utils.HashSHA256FromString(requestURI)
+
+	return s.provider.SaveOAuth2PushedAuthorizeRequest(request)
+}
+
+// ConsumePushedAuthorizeRequest loads and deletes the pushed authorization request for the given request URI.
+func (s *OpenIDConnectStore) ConsumePushedAuthorizeRequest(requestURI string) (*models.OAuth2PushedAuthorizeRequest, error) {
+	request, err := s.provider.ConsumeOAuth2PushedAuthorizeRequest(utils.HashSHA256FromString(requestURI))
+	if err != nil {
+		if errors.Is(err, storage.ErrNoOAuth2PushedAuthorizeRequest) {
+			return nil, fosite.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return request, nil
+}
+
+func (s *OpenIDConnectStore) loadDeviceCodeSession(session *models.OAuth2DeviceCodeSession, err error) (*models.OAuth2DeviceCodeSession, error) {
+	if err != nil {
+		if errors.Is(err, storage.ErrNoOAuth2DeviceCodeSession) {
+			return nil, fosite.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (s *OpenIDConnectStore) saveSession(_ context.Context, sessionType storage.OAuth2SessionType, signature string, r fosite.Requester, tokenType fosite.TokenType) (err error) {
+	session, err := models.NewOAuth2SessionFromRequest(signature, r, tokenType)
+	if err != nil {
+		return fmt.Errorf("unable to create %s session: %w", sessionType, err)
+	}
+
+	return s.provider.SaveOAuth2Session(sessionType, session)
+}
+
+func (s *OpenIDConnectStore) loadSession(ctx context.Context, sessionType storage.OAuth2SessionType, signature string, session fosite.Session) (r fosite.Requester, active bool, err error) {
+	model, err := s.provider.LoadOAuth2Session(sessionType, signature)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoOAuth2Session) {
+			return nil, false, fosite.ErrNotFound
+		}
+
+		return nil, false, err
+	}
+
+	client, err := s.GetClient(ctx, model.ClientID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if session == nil {
+		session = NewSession()
+	}
+
+	if r, err = model.ToRequest(client, session); err != nil {
+		return nil, false, fmt.Errorf("unable to decode %s session: %w", sessionType, err)
+	}
+
+	return r, model.Active, nil
+}
+
+func jtiSignature(jti string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(jti)))
 }
@@ -2,13 +2,19 @@ package oidc
 
 import (
 	"context"
+	"net/url"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
+	"github.com/ory/fosite"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/authelia/authelia/internal/authorization"
 	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
 )
 
 func TestOpenIDConnectStore_GetClientPolicy(t *testing.T) {
@@ -30,7 +36,7 @@ func TestOpenIDConnectStore_GetClientPolicy(t *testing.T) {
 				Secret:      "mysecret",
 			},
 		},
-	})
+	}, nil)
 
 	require.NoError(t, err)
 
@@ -56,7 +62,7 @@ func TestOpenIDConnectStore_GetInternalClient(t *testing.T) {
 				Secret:      "mysecret",
 			},
 		},
-	})
+	}, nil)
 
 	require.NoError(t, err)
 
@@ -81,7 +87,7 @@ func TestOpenIDConnectStore_GetInternalClient_ValidClient(t *testing.T) {
 	s, err := NewOpenIDConnectStore(&schema.OpenIDConnectConfiguration{
 		IssuerPrivateKey: exampleIssuerPrivateKey,
 		Clients:          []schema.OpenIDConnectClientConfiguration{c1},
-	})
+	}, nil)
 
 	require.NoError(t, err)
 
@@ -109,7 +115,7 @@ func TestOpenIDConnectStore_GetInternalClient_InvalidClient(t *testing.T) {
 	s, err := NewOpenIDConnectStore(&schema.OpenIDConnectConfiguration{
 		IssuerPrivateKey: exampleIssuerPrivateKey,
 		Clients:          []schema.OpenIDConnectClientConfiguration{c1},
-	})
+	}, nil)
 
 	require.NoError(t, err)
 
@@ -130,7 +136,7 @@ func TestOpenIDConnectStore_IsValidClientID(t *testing.T) {
 				Secret:      "mysecret",
 			},
 		},
-	})
+	}, nil)
 
 	require.NoError(t, err)
 
@@ -140,3 +146,245 @@ func TestOpenIDConnectStore_IsValidClientID(t *testing.T) {
 	assert.True(t, validClient)
 	assert.False(t, invalidClient)
 }
+
+func newTestOpenIDConnectStore(t *testing.T) (s *OpenIDConnectStore, provider *storage.MockProvider, ctrl *gomock.Controller) {
+	ctrl = gomock.NewController(t)
+	provider = storage.NewMockProvider(ctrl)
+
+	s, err := NewOpenIDConnectStore(&schema.OpenIDConnectConfiguration{
+		IssuerPrivateKey: exampleIssuerPrivateKey,
+		Clients: []schema.OpenIDConnectClientConfiguration{
+			{
+				ID:          "myclient",
+				Description: "myclient desc",
+				Policy:      "one_factor",
+				Scopes:      []string{"openid", "profile"},
+				Secret:      "mysecret",
+			},
+		},
+	}, provider)
+
+	require.NoError(t, err)
+
+	return s, provider, ctrl
+}
+
+func newTestRequest(t *testing.T, s *OpenIDConnectStore) (request *fosite.Request) {
+	client, err := s.GetClient(context.Background(), "myclient")
+	require.NoError(t, err)
+
+	session := NewSession()
+	session.Subject = "john"
+	session.SetExpiresAt(fosite.AuthorizeCode, time.Unix(1577880060, 0))
+
+	return &fosite.Request{
+		ID:             "req",
+		RequestedAt:    time.Unix(1577880001, 0),
+		Client:         client,
+		RequestedScope: fosite.Arguments{"openid", "profile"},
+		GrantedScope:   fosite.Arguments{"openid"},
+		Form:           url.Values{"state": []string{"abc"}, "client_secret": []string{"mysecret"}},
+		Session:        session,
+	}
+}
+
+func TestOpenIDConnectStore_ShouldPersistAuthorizeCodeSession(t *testing.T) {
+	s, provider, ctrl := newTestOpenIDConnectStore(t)
+	defer ctrl.Finish()
+
+	var saved models.OAuth2Session
+
+	provider.EXPECT().
+		SaveOAuth2Session(storage.OAuth2SessionTypeAuthorizeCode, gomock.Any()).
+		DoAndReturn(func(_ storage.OAuth2SessionType, session models.OAuth2Session) error {
+			saved = session
+			return nil
+		})
+
+	require.NoError(t, s.CreateAuthorizeCodeSession(context.Background(), "code", newTestRequest(t, s)))
+
+	assert.Equal(t, "code", saved.Signature)
+	assert.Equal(t, "req", saved.RequestID)
+	assert.Equal(t, "myclient", saved.ClientID)
+	assert.Equal(t, "john", saved.Subject)
+	assert.Equal(t, "state=abc", saved.Form)
+	require.NotNil(t, saved.ExpiresAt)
+	assert.Equal(t, time.Unix(1577880060, 0), *saved.ExpiresAt)
+
+	provider.EXPECT().
+		LoadOAuth2Session(storage.OAuth2SessionTypeAuthorizeCode, "code").
+		Return(&saved, nil)
+
+	r, err := s.GetAuthorizeCodeSession(context.Background(), "code", NewSession())
+	require.NoError(t, err)
+	assert.Equal(t, "req", r.GetID())
+	assert.Equal(t, "myclient", r.GetClient().GetID())
+	assert.Equal(t, fosite.Arguments{"openid"}, r.GetGrantedScopes())
+	assert.Equal(t, "john", r.GetSession().GetSubject())
+	assert.Equal(t, "abc", r.GetRequestForm().Get("state"))
+
+	saved.Active = false
+
+	provider.EXPECT().
+		LoadOAuth2Session(storage.OAuth2SessionTypeAuthorizeCode, "code").
+		Return(&saved, nil)
+
+	r, err = s.GetAuthorizeCodeSession(context.Background(), "code", NewSession())
+	assert.Equal(t, fosite.ErrInvalidatedAuthorizeCode, err)
+	require.NotNil(t, r)
+	assert.Equal(t, "req", r.GetID())
+}
+
+func TestOpenIDConnectStore_ShouldReturnNotFound(t *testing.T) {
+	s, provider, ctrl := newTestOpenIDConnectStore(t)
+	defer ctrl.Finish()
+
+	provider.EXPECT().
+		LoadOAuth2Session(storage.OAuth2SessionTypeAccessToken, "sig").
+		Return(nil, storage.ErrNoOAuth2Session)
+
+	r, err := s.GetAccessTokenSession(context.Background(), "sig", nil)
+	assert.Equal(t, fosite.ErrNotFound, err)
+	assert.Nil(t, r)
+}
+
+func TestOpenIDConnectStore_ShouldReturnInactiveRefreshToken(t *testing.T) {
+	s, provider, ctrl := newTestOpenIDConnectStore(t)
+	defer ctrl.Finish()
+
+	session, err := models.NewOAuth2SessionFromRequest("sig", newTestRequest(t, s), fosite.RefreshToken)
+	require.NoError(t, err)
+
+	session.Active = false
+
+	provider.EXPECT().
+		LoadOAuth2Session(storage.OAuth2SessionTypeRefreshToken, "sig").
+		Return(&session, nil)
+
+	r, err := s.GetRefreshTokenSession(context.Background(), "sig", nil)
+	assert.Equal(t, fosite.ErrInactiveToken, err)
+	require.NotNil(t, r)
+	assert.Equal(t, "john", r.GetSession().GetSubject())
+
+	provider.EXPECT().
+		DeactivateOAuth2SessionByRequestID(storage.OAuth2SessionTypeRefreshToken, "req").
+		Return(nil)
+
+	assert.NoError(t, s.RevokeRefreshToken(context.Background(), "req"))
+}
+
+func TestOpenIDConnectStore_ShouldBlacklistJTI(t *testing.T) {
+	s, provider, ctrl := newTestOpenIDConnectStore(t)
+	defer ctrl.Finish()
+
+	signature := jtiSignature("jti")
+	exp := time.Now().Add(time.Hour)
+
+	gomock.InOrder(
+		provider.EXPECT().
+			LoadOAuth2BlacklistedJTI(signature).
+			Return(nil, storage.ErrNoOAuth2BlacklistedJTI),
+		provider.EXPECT().
+			SaveOAuth2BlacklistedJTI(models.OAuth2BlacklistedJTI{Signature: signature, ExpiresAt: exp}).
+			Return(nil),
+		provider.EXPECT().
+			LoadOAuth2BlacklistedJTI(signature).
+			Return(&models.OAuth2BlacklistedJTI{Signature: signature, ExpiresAt: exp}, nil),
+		provider.EXPECT().
+			LoadOAuth2BlacklistedJTI(signature).
+			Return(&models.OAuth2BlacklistedJTI{Signature: signature, ExpiresAt: time.Now().Add(-time.Hour)}, nil),
+	)
+
+	assert.NoError(t, s.SetClientAssertionJWT(context.Background(), "jti", exp))
+	assert.Equal(t, fosite.ErrJTIKnown, s.ClientAssertionJWTValid(context.Background(), "jti"))
+	assert.NoError(t, s.ClientAssertionJWTValid(context.Background(), "jti"))
+}
+
+func TestOpenIDConnectStore_ShouldManageRegisteredClients(t *testing.T) {
+	s, provider, ctrl := newTestOpenIDConnectStore(t)
+	defer ctrl.Finish()
+
+	registered := models.OAuth2Client{
+		ClientID:     "grafana",
+		Description:  "Grafana",
+		Secret:       "$argon2id$v=19$m=65536,t=1,p=8$c2FsdA$hash",
+		RedirectURIs: []string{"https://grafana.example.com/login/generic_oauth"},
+		Policy:       "one_factor",
+		Scopes:       []string{"openid"},
+	}
+
+	provider.EXPECT().
+		LoadOAuth2Clients().
+		Return([]models.OAuth2Client{registered, {ClientID: "myclient", Policy: "two_factor"}}, nil)
+
+	require.NoError(t, s.LoadClients())
+
+	client, err := s.GetInternalClient("grafana")
+	require.NoError(t, err)
+	assert.Equal(t, authorization.OneFactor, client.Policy)
+	assert.Equal(t, []byte(registered.Secret), client.GetHashedSecret())
+
+	// Clients defined in the configuration take precedence over registered clients with the same ID.
+	assert.Equal(t, authorization.OneFactor, s.GetClientPolicy("myclient"))
+	assert.True(t, s.IsConfiguredClient("myclient"))
+	assert.False(t, s.IsConfiguredClient("grafana"))
+
+	assert.Equal(t, ErrClientAlreadyExists, s.CreateClient(models.OAuth2Client{ClientID: "grafana"}))
+	assert.Equal(t, ErrClientConfigured, s.UpdateClient(models.OAuth2Client{ClientID: "myclient"}))
+	assert.Equal(t, ErrClientConfigured, s.RotateClientSecret("myclient", "hash", time.Now()))
+	assert.Equal(t, ErrClientConfigured, s.DeleteClient("myclient"))
+
+	provider.EXPECT().
+		SaveOAuth2Client(models.OAuth2Client{ClientID: "gitlab", Policy: "two_factor"}).
+		Return(nil)
+
+	require.NoError(t, s.CreateClient(models.OAuth2Client{ClientID: "gitlab", Policy: "two_factor"}))
+	assert.True(t, s.IsValidClientID("gitlab"))
+
+	updated := registered
+	updated.Policy = "two_factor"
+
+	gomock.InOrder(
+		provider.EXPECT().
+			UpdateOAuth2Client(updated).
+			Return(nil),
+		provider.EXPECT().
+			LoadOAuth2Client("grafana").
+			Return(&updated, nil),
+	)
+
+	require.NoError(t, s.UpdateClient(updated))
+	assert.Equal(t, authorization.TwoFactor, s.GetClientPolicy("grafana"))
+
+	now := time.Unix(1577880001, 0)
+	rotated := updated
+	rotated.Secret = "$argon2id$rotated"
+
+	gomock.InOrder(
+		provider.EXPECT().
+			UpdateOAuth2ClientSecret("grafana", "$argon2id$rotated", now).
+			Return(nil),
+		provider.EXPECT().
+			LoadOAuth2Client("grafana").
+			Return(&rotated, nil),
+	)
+
+	require.NoError(t, s.RotateClientSecret("grafana", "$argon2id$rotated", now))
+
+	client, err = s.GetInternalClient("grafana")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("$argon2id$rotated"), client.GetHashedSecret())
+
+	provider.EXPECT().
+		DeleteOAuth2Client("grafana").
+		Return(nil)
+
+	require.NoError(t, s.DeleteClient("grafana"))
+	assert.False(t, s.IsValidClientID("grafana"))
+
+	provider.EXPECT().
+		DeleteOAuth2Client("grafana").
+		Return(storage.ErrNoOAuth2Client)
+
+	assert.Equal(t, fosite.ErrNotFound, s.DeleteClient("grafana"))
+}
@@ -1,15 +1,22 @@
 package oidc
 
 import (
-	"crypto/rsa"
+	"crypto"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/ory/fosite"
+	"github.com/ory/fosite/handler/oauth2"
 	"github.com/ory/fosite/handler/openid"
-	"github.com/ory/fosite/storage"
+	"github.com/ory/fosite/handler/pkce"
 	"github.com/ory/herodot"
 	"gopkg.in/square/go-jose.v2"
 
 	"github.com/authelia/authelia/internal/authorization"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/utils"
 )
 
 // OpenIDConnectProvider for OpenID Connect.
@@ -18,17 +25,26 @@ type OpenIDConnectProvider struct {
 	Store      *OpenIDConnectStore
 	KeyManager *KeyManager
 
-	herodot *herodot.JSONWriter
+	deviceCodeLifespan        time.Duration
+	deviceCodePollingInterval time.Duration
+
+	pushedAuthorizeRequestLifespan time.Duration
+
+	httpClient *http.Client
+	jwks       *JWKSFetcher
+	herodot    *herodot.JSONWriter
 }
 
-// OpenIDConnectStore is Authelia's internal representation of the fosite.Storage interface.
-//
-//	Currently it is mostly just implementing a decorator pattern other then GetInternalClient.
-//	The long term plan is to have these methods interact with the Authelia storage and
-//	session providers where applicable.
+// OpenIDConnectStore is Authelia's internal representation of the fosite.Storage interface. It maps the fosite
+// storage methods to the Authelia storage provider so sessions survive restarts and are shared between instances.
+// The clients are the ones defined in the configuration along with the ones registered at runtime.
 type OpenIDConnectStore struct {
+	configuredClients map[string]*InternalClient
+
 	clients map[string]*InternalClient
-	memory  *storage.MemoryStore
+	mutex   sync.RWMutex
+
+	provider storage.Provider
 }
 
 // InternalClient represents the client internally.
@@ -45,21 +61,123 @@ type InternalClient struct {
 
 	ResponseModes []fosite.ResponseModeType `json:"response_modes"`
 
+	IDTokenSigningAlgorithm  string `json:"id_token_signed_response_alg,omitempty"`
 	UserinfoSigningAlgorithm string `json:"userinfo_signed_response_alg,omitempty"`
 
 	Policy authorization.Level `json:"-"`
+
+	ConsentMode                  ClientConsentMode `json:"-"`
+	PreConfiguredConsentDuration time.Duration     `json:"-"`
+
+	ClaimsPolicy *ClaimsPolicy `json:"-"`
+
+	TokenEndpointAuthMethod string `json:"token_endpoint_auth_method,omitempty"`
+
+	RequirePushedAuthorizationRequests bool `json:"require_pushed_authorization_requests,omitempty"`
+	RequirePKCE                        bool `json:"require_pkce,omitempty"`
+
+	RequestObjectSigningAlgorithm string   `json:"request_object_signing_alg,omitempty"`
+	RequestURIs                   []string `json:"request_uris,omitempty"`
+	JWKSURI                       string   `json:"jwks_uri,omitempty"`
+
+	PostLogoutRedirectURIs []string `json:"post_logout_redirect_uris,omitempty"`
+	FrontChannelLogoutURI  string   `json:"frontchannel_logout_uri,omitempty"`
+	BackChannelLogoutURI   string   `json:"backchannel_logout_uri,omitempty"`
 }
 
-// KeyManager keeps track of all of the active/inactive rsa keys and provides them to services requiring them.
-// It additionally allows us to add keys for the purpose of key rotation in the future.
+// ClaimsPolicy describes the claims issued to the clients using it in addition to the standard claims.
+type ClaimsPolicy struct {
+	Name string
+
+	// CustomScopes are the descriptions of the scopes only used by the custom claims keyed by the scope.
+	CustomScopes map[string]string
+	CustomClaims []schema.OpenIDConnectCustomClaimConfiguration
+
+	// RenameClaims are the names the standard claims are issued with keyed by the standard claim.
+	RenameClaims map[string]string
+}
+
+// ClientConsentMode represents how the consent of a user is obtained for a client.
+type ClientConsentMode int
+
+const (
+	// ClientConsentModeExplicit requires the user to consent every time the client requests authorization.
+	ClientConsentModeExplicit ClientConsentMode = iota
+
+	// ClientConsentModeImplicit never prompts the user for consent, it's granted automatically.
+	ClientConsentModeImplicit
+
+	// ClientConsentModePreConfigured remembers the consent of the user for a configured duration.
+	ClientConsentModePreConfigured
+)
+
+// KeyManager keeps track of all of the issuer private keys and provides them to services requiring them. Each key is
+// published before it becomes active, is active until a newer key of the same algorithm is activated, and is retired
+// once the tokens it signed have expired.
 type KeyManager struct {
-	activeKeyID string
-	keys        map[string]*rsa.PrivateKey
-	keySet      *jose.JSONWebKeySet
-	strategy    *RS256JWTStrategy
+	keys      []*Key
+	clock     utils.Clock
+	retention time.Duration
+	strategy  *JWTStrategy
 }
 
-// AutheliaHasher implements the fosite.Hasher interface without an actual hashing algo.
+// Key is an issuer private key alongside the information required to sign with it and to schedule its rotation.
+type Key struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+	ActivateAt time.Time
+}
+
+// JWTStrategy implements the fosite jwt.JWTStrategy interface. Tokens are signed with the active key of the algorithm
+// of the key referenced by the kid header, and verified with any published key.
+type JWTStrategy struct {
+	manager *KeyManager
+}
+
+// DeviceCodeGrantHandler implements the token endpoint of the OAuth 2.0 Device Authorization Grant, see RFC 8628
+// section 3.4. The device code is exchanged for an access token and, depending on the granted scopes, an ID token and a
+// refresh token once the user has approved the request.
+type DeviceCodeGrantHandler struct {
+	*oauth2.HandleHelper
+	*openid.IDTokenHandleHelper
+
+	RefreshTokenStrategy oauth2.RefreshTokenStrategy
+
+	store    *OpenIDConnectStore
+	interval time.Duration
+}
+
+// DeviceAuthorizeResponse is the response of the device authorization endpoint, see RFC 8628 section 3.2.
+type DeviceAuthorizeResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// PushedAuthorizeResponse is the response of the pushed authorization request endpoint, see RFC 9126 section 2.2.
+type PushedAuthorizeResponse struct {
+	RequestURI string `json:"request_uri"`
+	ExpiresIn  int64  `json:"expires_in"`
+}
+
+// PKCEHandler wraps the fosite pkce.Handler so PKCE can be required by individual clients.
+type PKCEHandler struct {
+	*pkce.Handler
+}
+
+// JWKSFetcher implements the fosite.JWKSFetcherStrategy interface. The JSON Web Key Sets of the clients are fetched
+// with the HTTP client of the provider and cached until a key which isn't part of the cached set is used.
+type JWKSFetcher struct {
+	client *http.Client
+	keys   map[string]*jose.JSONWebKeySet
+	mutex  sync.Mutex
+}
+
+// AutheliaHasher implements the fosite.Hasher interface with the password hashing algorithms of Authelia.
 type AutheliaHasher struct{}
 
 // ConsentGetResponseBody schema of the response body of the consent GET endpoint.
@@ -70,6 +188,14 @@ type ConsentGetResponseBody struct {
 	Audience          []Audience `json:"audience"`
 }
 
+// DeviceGetResponseBody schema of the response body of the device GET endpoint.
+type DeviceGetResponseBody struct {
+	ConsentGetResponseBody
+
+	UserCode             string `json:"user_code"`
+	SecondFactorRequired bool   `json:"second_factor_required"`
+}
+
 // Scope represents the scope information.
 type Scope struct {
 	Name        string `json:"name"`
@@ -89,10 +215,13 @@ type WellKnownConfiguration struct {
 	Issuer  string `json:"issuer"`
 	JWKSURI string `json:"jwks_uri"`
 
-	AuthorizationEndpoint string `json:"authorization_endpoint"`
-	TokenEndpoint         string `json:"token_endpoint"`
-	RevocationEndpoint    string `json:"revocation_endpoint"`
-	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	AuthorizationEndpoint       string `json:"authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	RevocationEndpoint          string `json:"revocation_endpoint"`
+	UserinfoEndpoint            string `json:"userinfo_endpoint"`
+	EndSessionEndpoint          string `json:"end_session_endpoint"`
+	RegistrationEndpoint        string `json:"registration_endpoint,omitempty"`
 
 	Algorithms         []string `json:"id_token_signing_alg_values_supported"`
 	UserinfoAlgorithms []string `json:"userinfo_signing_alg_values_supported"`
@@ -102,8 +231,16 @@ type WellKnownConfiguration struct {
 	ResponseModesSupported []string `json:"response_modes_supported"`
 	ScopesSupported        []string `json:"scopes_supported"`
 	ClaimsSupported        []string `json:"claims_supported"`
+	GrantTypesSupported    []string `json:"grant_types_supported"`
+
+	PushedAuthorizationRequestEndpoint string `json:"pushed_authorization_request_endpoint"`
+
+	RequestObjectSigningAlgorithms []string `json:"request_object_signing_alg_values_supported"`
+	CodeChallengeMethodsSupported  []string `json:"code_challenge_methods_supported"`
 
+	RequestParameterSupported          bool `json:"request_parameter_supported"`
 	RequestURIParameterSupported       bool `json:"request_uri_parameter_supported"`
+	RequirePushedAuthorizationRequests bool `json:"require_pushed_authorization_requests"`
 	BackChannelLogoutSupported         bool `json:"backchannel_logout_supported"`
 	FrontChannelLogoutSupported        bool `json:"frontchannel_logout_supported"`
 	BackChannelLogoutSessionSupported  bool `json:"backchannel_logout_session_supported"`
@@ -0,0 +1,267 @@
+package regulation
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/authelia/authelia/internal/models"
+)
+
+// Bans returns the active bans of the users, IP addresses and networks along with their number of failed
+// authentication attempts. The users banned by their failed attempts are listed along with the bans made by
+// administrators.
+func (r *Regulator) Bans() (bans []Ban, err error) {
+	now := r.clock.Now()
+
+	stored, err := r.storageProvider.LoadRegulationBans(now)
+	if err != nil {
+		return nil, err
+	}
+
+	window := r.banTime
+	for _, t := range []threshold{r.ip, r.network} {
+		if t.banTime > window {
+			window = t.banTime
+		}
+	}
+
+	attempts, err := r.storageProvider.LoadLatestFailedAuthenticationLogs(now.Add(-window))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ban := range stored {
+		bans = append(bans, Ban{
+			Type:        ban.Type,
+			Value:       ban.Value,
+			Reason:      ban.Reason,
+			BannedUntil: ban.ExpiresAt,
+			Failures:    r.failures(attempts, ban.Type, ban.Value, now),
+		})
+	}
+
+	if !r.enabled {
+		return bans, nil
+	}
+
+	userBans, err := r.userBans(attempts, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(bans, userBans...), nil
+}
+
+// userBans returns the users banned by their failed authentication attempts. The users with enough failed attempts
+// are the candidates, their authentication log is then loaded to check whether a successful attempt lifted the ban.
+func (r *Regulator) userBans(attempts []models.AuthenticationAttempt, now time.Time) (bans []Ban, err error) {
+	counts := map[string]int{}
+
+	for _, attempt := range attempts {
+		if attempt.Admin == "" && attempt.Username != "" && now.Sub(attempt.Time) < r.banTime {
+			counts[attempt.Username]++
+		}
+	}
+
+	usernames := make([]string, 0, len(counts))
+
+	for username, count := range counts {
+		if count >= r.maxRetries {
+			usernames = append(usernames, username)
+		}
+	}
+
+	sort.Strings(usernames)
+
+	for _, username := range usernames {
+		userAttempts, err := r.storageProvider.LoadLatestAuthenticationLogs(username, now.Add(-r.banTime))
+		if err != nil {
+			return nil, err
+		}
+
+		if bannedUntil, failures, banned := r.userBannedUntil(userAttempts); banned {
+			bans = append(bans, Ban{
+				Type:        BanTypeUser,
+				Value:       username,
+				Reason:      fmt.Sprintf("%d failed authentication attempts within %s", failures, r.findTime),
+				BannedUntil: bannedUntil,
+				Failures:    failures,
+			})
+		}
+	}
+
+	return bans, nil
+}
+
+// failures counts the failed authentication attempts of a banned value within the ban time of its type.
+func (r *Regulator) failures(attempts []models.AuthenticationAttempt, banType, value string, now time.Time) (failures int) {
+	var (
+		banTime time.Duration
+		matches func(attempt models.AuthenticationAttempt) bool
+	)
+
+	switch banType {
+	case BanTypeUser:
+		banTime = r.banTime
+		matches = func(attempt models.AuthenticationAttempt) bool { return attempt.Username == value }
+	case BanTypeIP:
+		ip := net.ParseIP(value)
+		banTime = r.ip.banTime
+		matches = func(attempt models.AuthenticationAttempt) bool { return attempt.RemoteIP.Equal(ip) }
+	case BanTypeNetwork:
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return 0
+		}
+
+		banTime = r.network.banTime
+		matches = func(attempt models.AuthenticationAttempt) bool { return network.Contains(attempt.RemoteIP) }
+	default:
+		return 0
+	}
+
+	for _, attempt := range attempts {
+		if attempt.Admin == "" && !attempt.Successful && now.Sub(attempt.Time) < banTime && matches(attempt) {
+			failures++
+		}
+	}
+
+	return failures
+}
+
+// Ban bans a user, an IP address or a network for the given duration, the ban time of the type of the value is used
+// when the duration is 0. The ban is recorded in the authentication log along with the administrator who made it.
+func (r *Regulator) Ban(banType, value string, duration time.Duration, reason, admin string) (ban models.RegulationBan, err error) {
+	value, ip, banTime, err := r.parseBanValue(banType, value)
+	if err != nil {
+		return ban, err
+	}
+
+	if duration == 0 {
+		duration = banTime
+	}
+
+	if reason == "" {
+		reason = fmt.Sprintf("banned by %s", admin)
+	}
+
+	now := r.clock.Now()
+
+	ban = models.RegulationBan{
+		Type:      banType,
+		Value:     value,
+		Reason:    reason,
+		CreatedAt: now,
+		ExpiresAt: now.Add(duration),
+	}
+
+	if err = r.storageProvider.SaveRegulationBan(ban); err != nil {
+		return ban, err
+	}
+
+	r.forgetCleared()
+
+	return ban, r.storageProvider.AppendAuthenticationLog(newAdminAttempt(banType, value, ip, false, admin, now))
+}
+
+// Unban lifts the bans of a user, an IP address or a network. The unban is recorded in the authentication log along
+// with the administrator who made it, the failed attempts made before the unban no longer count towards a ban.
+func (r *Regulator) Unban(banType, value, admin string) (err error) {
+	value, ip, _, err := r.parseBanValue(banType, value)
+	if err != nil {
+		return err
+	}
+
+	now := r.clock.Now()
+
+	if err = r.storageProvider.RevokeRegulationBans(banType, value, now); err != nil {
+		return err
+	}
+
+	return r.storageProvider.AppendAuthenticationLog(newAdminAttempt(banType, value, ip, true, admin, now))
+}
+
+// parseBanValue normalizes the value of a ban of the given type and returns the IP address recorded in the
+// authentication log along with the ban time of the type.
+func (r *Regulator) parseBanValue(banType, value string) (normalized string, ip net.IP, banTime time.Duration, err error) {
+	value = strings.TrimSpace(value)
+
+	switch banType {
+	case BanTypeUser:
+		if !r.enabled {
+			return "", nil, 0, ErrRegulationDisabled
+		}
+
+		if value == "" {
+			return "", nil, 0, fmt.Errorf("%w: the username must not be empty", ErrInvalidBan)
+		}
+
+		return value, nil, r.banTime, nil
+	case BanTypeIP:
+		if r.ip.maxRetries == 0 {
+			return "", nil, 0, ErrRegulationDisabled
+		}
+
+		if ip = net.ParseIP(value); ip == nil {
+			return "", nil, 0, fmt.Errorf("%w: the value %s is not a valid IP address", ErrInvalidBan, value)
+		}
+
+		if !r.isIPRegulated(ip) {
+			return "", nil, 0, ErrNetworkIsExempt
+		}
+
+		return ip.String(), ip, r.ip.banTime, nil
+	case BanTypeNetwork:
+		if r.network.maxRetries == 0 {
+			return "", nil, 0, ErrRegulationDisabled
+		}
+
+		network := r.parseNetwork(value)
+		if network == nil {
+			return "", nil, 0, fmt.Errorf("%w: the value %s is not a valid network", ErrInvalidBan, value)
+		}
+
+		if !r.isIPRegulated(network.IP) {
+			return "", nil, 0, ErrNetworkIsExempt
+		}
+
+		return network.String(), network.IP, r.network.banTime, nil
+	default:
+		return "", nil, 0, fmt.Errorf("%w: unknown ban type %s, it must be one of %s, %s or %s", ErrInvalidBan, banType, BanTypeUser, BanTypeIP, BanTypeNetwork)
+	}
+}
+
+// parseNetwork parses a network in the CIDR notation, an IP address is turned into the subnet used by the network
+// regulation.
+func (r *Regulator) parseNetwork(value string) *net.IPNet {
+	if ip := net.ParseIP(value); ip != nil {
+		return r.subnet(ip)
+	}
+
+	_, network, err := net.ParseCIDR(value)
+	if err != nil {
+		return nil
+	}
+
+	return network
+}
+
+// newAdminAttempt creates the authentication log entry of a ban or an unban made by an administrator. The entries of
+// the IP addresses and networks are recorded with the IP address of the value and without a username.
+func newAdminAttempt(banType, value string, ip net.IP, successful bool, admin string, now time.Time) models.AuthenticationAttempt {
+	attempt := models.AuthenticationAttempt{
+		Successful: successful,
+		Time:       now,
+		RemoteIP:   ip,
+		Admin:      admin,
+	}
+
+	if banType == BanTypeUser {
+		attempt.Username = value
+	}
+
+	return attempt
+}
@@ -1,6 +1,39 @@
 package regulation
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // ErrUserIsBanned user is banned error message.
 var ErrUserIsBanned = fmt.Errorf("user is banned")
+
+// ErrIPIsBanned IP address is banned error message, it's returned when either the IP address or its subnet is banned.
+var ErrIPIsBanned = fmt.Errorf("ip is banned")
+
+// ErrInvalidBan is returned when an administrator bans or unbans an unknown type of value or an invalid value.
+var ErrInvalidBan = fmt.Errorf("invalid ban")
+
+// ErrRegulationDisabled is returned when an administrator bans or unbans a value whose regulation is disabled.
+var ErrRegulationDisabled = fmt.Errorf("the regulation of this ban type is disabled")
+
+// ErrNetworkIsExempt is returned when an administrator bans an IP address or a network of an exempt network.
+var ErrNetworkIsExempt = fmt.Errorf("the network is exempt from regulation")
+
+const (
+	// regulationCacheDuration is how long RegulateCached remembers that a user and an IP address aren't banned.
+	regulationCacheDuration = 10 * time.Second
+
+	// regulationCacheSize is the number of users and IP addresses remembered by RegulateCached before they're all
+	// forgotten.
+	regulationCacheSize = 1024
+)
+
+const (
+	// BanTypeUser is the type of the bans of a user.
+	BanTypeUser = "user"
+	// BanTypeIP is the type of the bans of a single IP address.
+	BanTypeIP = "ip"
+	// BanTypeNetwork is the type of the bans of the IP addresses of a subnet.
+	BanTypeNetwork = "network"
+)
@@ -2,6 +2,7 @@ package regulation
 
 import (
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/authelia/authelia/internal/configuration/schema"
@@ -35,25 +36,101 @@ func NewRegulator(configuration *schema.RegulationConfiguration, provider storag
 		regulator.maxRetries = configuration.MaxRetries
 		regulator.findTime = findTime
 		regulator.banTime = banTime
+
+		regulator.ip = newThreshold(configuration.IP.MaxRetries, configuration.IP.FindTime, configuration.IP.BanTime)
+		regulator.network = newThreshold(configuration.Network.MaxRetries, configuration.Network.FindTime, configuration.Network.BanTime)
+
+		regulator.ipv4Mask = newMask(configuration.Network.IPv4PrefixLength, schema.DefaultRegulationConfiguration.Network.IPv4PrefixLength, 32)
+		regulator.ipv6Mask = newMask(configuration.Network.IPv6PrefixLength, schema.DefaultRegulationConfiguration.Network.IPv6PrefixLength, 128)
+
+		regulator.exemptNetworks = newNetworks(configuration.ExemptNetworks)
 	}
 
 	return regulator
 }
 
+func newThreshold(maxRetries int, rawFindTime, rawBanTime string) threshold {
+	if maxRetries <= 0 {
+		return threshold{}
+	}
+
+	findTime, err := utils.ParseDurationString(rawFindTime)
+	if err != nil {
+		panic(err)
+	}
+
+	banTime, err := utils.ParseDurationString(rawBanTime)
+	if err != nil {
+		panic(err)
+	}
+
+	return threshold{maxRetries: maxRetries, findTime: findTime, banTime: banTime}
+}
+
+func newMask(prefixLength, defaultPrefixLength, bits int) net.IPMask {
+	if prefixLength <= 0 || prefixLength > bits {
+		prefixLength = defaultPrefixLength
+	}
+
+	return net.CIDRMask(prefixLength, bits)
+}
+
+func newNetworks(rawNetworks []string) (networks []*net.IPNet) {
+	for _, rawNetwork := range rawNetworks {
+		if ip := net.ParseIP(rawNetwork); ip != nil {
+			if ip.To4() != nil {
+				networks = append(networks, &net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)})
+			} else {
+				networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)})
+			}
+
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(rawNetwork)
+		if err != nil {
+			panic(err)
+		}
+
+		networks = append(networks, network)
+	}
+
+	return networks
+}
+
 // Mark mark an authentication attempt.
 // We split Mark and Regulate in order to avoid timing attacks.
-func (r *Regulator) Mark(username string, successful bool) error {
-	return r.storageProvider.AppendAuthenticationLog(models.AuthenticationAttempt{
+func (r *Regulator) Mark(username string, ip net.IP, successful bool) error {
+	now := r.clock.Now()
+
+	err := r.storageProvider.AppendAuthenticationLog(models.AuthenticationAttempt{
 		Username:   username,
 		Successful: successful,
-		Time:       r.clock.Now(),
+		Time:       now,
+		RemoteIP:   ip,
 	})
+
+	if !successful {
+		r.forgetCleared()
+	}
+
+	if err != nil || successful || !r.isIPRegulated(ip) {
+		return err
+	}
+
+	return r.banIP(ip, now)
 }
 
-// Regulate regulate the authentication attempts for a given user.
-// This method returns ErrUserIsBanned if the user is banned along with the time until when
-// the user is banned.
-func (r *Regulator) Regulate(username string) (time.Time, error) {
+// Regulate regulate the authentication attempts for a given user and IP address, the IP address is optional.
+// This method returns ErrIPIsBanned if the IP address or its subnet is banned or ErrUserIsBanned if the user is banned
+// along with the time until when the user is banned.
+func (r *Regulator) Regulate(username string, ip net.IP) (time.Time, error) {
+	if r.isIPRegulated(ip) {
+		if bannedUntil, err := r.regulateIP(ip); err != nil {
+			return bannedUntil, err
+		}
+	}
+
 	// If there is regulation configuration, no regulation applies.
 	if !r.enabled {
 		return time.Time{}, nil
@@ -61,6 +138,10 @@ func (r *Regulator) Regulate(username string) (time.Time, error) {
 
 	now := r.clock.Now()
 
+	if bannedUntil, err := r.checkBan(BanTypeUser, username, now, ErrUserIsBanned); err != nil {
+		return bannedUntil, err
+	}
+
 	// TODO(c.michaud): make sure FindTime < BanTime.
 	attempts, err := r.storageProvider.LoadLatestAuthenticationLogs(username, now.Add(-r.banTime))
 
@@ -68,14 +149,72 @@ func (r *Regulator) Regulate(username string) (time.Time, error) {
 		return time.Time{}, nil
 	}
 
+	if bannedUntil, _, banned := r.userBannedUntil(attempts); banned {
+		return bannedUntil, ErrUserIsBanned
+	}
+
+	return time.Time{}, nil
+}
+
+// RegulateCached regulates the authentication attempts like Regulate but it remembers for a short time the users and
+// IP addresses which aren't banned. It's meant for the credentials which are checked on every request like the ones of
+// the Basic authentication, everything remembered is forgotten as soon as an authentication attempt fails.
+func (r *Regulator) RegulateCached(username string, ip net.IP) (time.Time, error) {
+	key := username + "@" + ip.String()
+	now := r.clock.Now()
+
+	r.clearedMutex.Lock()
+	clearedAt, cleared := r.cleared[key]
+	generation := r.clearedGeneration
+	r.clearedMutex.Unlock()
+
+	if cleared && now.Sub(clearedAt) < regulationCacheDuration {
+		return time.Time{}, nil
+	}
+
+	if bannedUntil, err := r.Regulate(username, ip); err != nil {
+		return bannedUntil, err
+	}
+
+	r.clearedMutex.Lock()
+	defer r.clearedMutex.Unlock()
+
+	// An authentication attempt which failed in the meantime may have banned the user or the IP address.
+	if generation != r.clearedGeneration {
+		return time.Time{}, nil
+	}
+
+	if r.cleared == nil || len(r.cleared) >= regulationCacheSize {
+		r.cleared = make(map[string]time.Time)
+	}
+
+	r.cleared[key] = now
+
+	return time.Time{}, nil
+}
+
+// forgetCleared forgets the users and IP addresses remembered by RegulateCached.
+func (r *Regulator) forgetCleared() {
+	r.clearedMutex.Lock()
+	defer r.clearedMutex.Unlock()
+
+	r.cleared = nil
+	r.clearedGeneration++
+}
+
+// userBannedUntil computes whether the latest authentication attempts of a user, sorted from the latest, ban them and
+// until when along with the number of failed attempts which were taken into account.
+func (r *Regulator) userBannedUntil(attempts []models.AuthenticationAttempt) (bannedUntil time.Time, failures int, banned bool) {
 	latestFailedAttempts := make([]models.AuthenticationAttempt, 0, r.maxRetries)
 
 	for _, attempt := range attempts {
 		if attempt.Successful || len(latestFailedAttempts) >= r.maxRetries {
 			// We stop appending failed attempts once we find the first successful attempts or we reach
-			// the configured number of retries, meaning the user is already banned.
+			// the configured number of retries, meaning the user is already banned. The unbans made by
+			// administrators are recorded as successful attempts.
 			break
-		} else {
+		} else if attempt.Admin == "" {
+			// The bans made by administrators are held by the storage provider and aren't failed attempts.
 			latestFailedAttempts = append(latestFailedAttempts, attempt)
 		}
 	}
@@ -83,7 +222,7 @@ func (r *Regulator) Regulate(username string) (time.Time, error) {
 	// If the number of failed attempts within the ban time is less than the max number of retries
 	// then the user is not banned.
 	if len(latestFailedAttempts) < r.maxRetries {
-		return time.Time{}, nil
+		return time.Time{}, len(latestFailedAttempts), false
 	}
 
 	// Now we compute the time between the latest attempt and the MaxRetry-th one. If it's
@@ -92,9 +231,137 @@ func (r *Regulator) Regulate(username string) (time.Time, error) {
 		latestFailedAttempts[r.maxRetries-1].Time)
 
 	if durationBetweenLatestAttempts < r.findTime {
-		bannedUntil := latestFailedAttempts[0].Time.Add(r.banTime)
-		return bannedUntil, ErrUserIsBanned
+		return latestFailedAttempts[0].Time.Add(r.banTime), len(latestFailedAttempts), true
+	}
+
+	return time.Time{}, len(latestFailedAttempts), false
+}
+
+// isIPRegulated returns true if the IP address or subnet regulation is enabled and the IP address isn't exempted.
+func (r *Regulator) isIPRegulated(ip net.IP) bool {
+	if ip == nil || (r.ip.maxRetries == 0 && r.network.maxRetries == 0) {
+		return false
+	}
+
+	for _, network := range r.exemptNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// host returns the network holding only the IP address.
+func host(ip net.IP) *net.IPNet {
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+// subnet returns the subnet of the IP address used by the subnet regulation.
+func (r *Regulator) subnet(ip net.IP) *net.IPNet {
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4.Mask(r.ipv4Mask), Mask: r.ipv4Mask}
+	}
+
+	return &net.IPNet{IP: ip.Mask(r.ipv6Mask), Mask: r.ipv6Mask}
+}
+
+// regulateIP checks the bans of the IP address and its subnet held by the storage provider.
+func (r *Regulator) regulateIP(ip net.IP) (time.Time, error) {
+	now := r.clock.Now()
+
+	if r.ip.maxRetries != 0 {
+		if bannedUntil, err := r.checkBan(BanTypeIP, ip.String(), now, ErrIPIsBanned); err != nil {
+			return bannedUntil, err
+		}
+	}
+
+	if r.network.maxRetries != 0 {
+		if bannedUntil, err := r.checkBan(BanTypeNetwork, r.subnet(ip).String(), now, ErrIPIsBanned); err != nil {
+			return bannedUntil, err
+		}
 	}
 
 	return time.Time{}, nil
 }
+
+// checkBan returns the given error along with the time until when the value is banned if the storage provider holds a
+// ban of the value.
+func (r *Regulator) checkBan(banType, value string, now time.Time, errBanned error) (time.Time, error) {
+	ban, err := r.storageProvider.LoadRegulationBan(banType, value, now)
+
+	switch err {
+	case nil:
+		return ban.ExpiresAt, errBanned
+	case storage.ErrNoRegulationBan:
+		return time.Time{}, nil
+	default:
+		return time.Time{}, err
+	}
+}
+
+// banIP bans the IP address or its subnet when their failed authentication attempts exceed their threshold.
+func (r *Regulator) banIP(ip net.IP, now time.Time) error {
+	if r.ip.maxRetries != 0 {
+		failures, err := r.networkFailures(host(ip), r.ip, now)
+		if err != nil {
+			return err
+		}
+
+		if failures >= r.ip.maxRetries {
+			if err = r.ban(BanTypeIP, ip.String(), r.ip, failures, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	if r.network.maxRetries != 0 {
+		subnet := r.subnet(ip)
+
+		failures, err := r.networkFailures(subnet, r.network, now)
+		if err != nil {
+			return err
+		}
+
+		if failures >= r.network.maxRetries {
+			return r.ban(BanTypeNetwork, subnet.String(), r.network, failures, now)
+		}
+	}
+
+	return nil
+}
+
+// networkFailures counts the failed authentication attempts made from the IP addresses of a network within the find time of
+// the threshold, up to its max retries.
+func (r *Regulator) networkFailures(network *net.IPNet, threshold threshold, now time.Time) (failures int, err error) {
+	attempts, err := r.storageProvider.LoadLatestFailedAuthenticationLogsByNetwork(network, now.Add(-threshold.findTime), threshold.maxRetries)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, attempt := range attempts {
+		// The attempts are sorted from the latest and the only successful ones are the unbans made by administrators,
+		// the attempts made before an IP address of the network was unbanned are forgiven.
+		if attempt.Successful {
+			break
+		}
+
+		failures++
+	}
+
+	return failures, nil
+}
+
+func (r *Regulator) ban(banType, value string, threshold threshold, attempts int, now time.Time) error {
+	return r.storageProvider.SaveRegulationBan(models.RegulationBan{
+		Type:      banType,
+		Value:     value,
+		Reason:    fmt.Sprintf("%d failed authentication attempts within %s", attempts, threshold.findTime),
+		CreatedAt: now,
+		ExpiresAt: now.Add(threshold.banTime),
+	})
+}
@@ -1,6 +1,7 @@
 package regulation_test
 
 import (
+	"net"
 	"testing"
 	"time"
 
@@ -49,13 +50,17 @@ func (s *RegulatorSuite) TestShouldNotThrowWhenUserIsLegitimate() {
 		},
 	}
 
+	s.storageMock.EXPECT().
+		LoadRegulationBan(gomock.Eq(regulation.BanTypeUser), gomock.Eq("john"), gomock.Any()).
+		Return(nil, storage.ErrNoRegulationBan)
+
 	s.storageMock.EXPECT().
 		LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
 		Return(attemptsInDB, nil)
 
 	regulator := regulation.NewRegulator(&s.configuration, s.storageMock, &s.clock)
 
-	_, err := regulator.Regulate("john")
+	_, err := regulator.Regulate("john", nil)
 	assert.NoError(s.T(), err)
 }
 
@@ -80,13 +85,17 @@ func (s *RegulatorSuite) TestShouldNotThrowWhenFailedAuthenticationNotInFindTime
 		},
 	}
 
+	s.storageMock.EXPECT().
+		LoadRegulationBan(gomock.Eq(regulation.BanTypeUser), gomock.Eq("john"), gomock.Any()).
+		Return(nil, storage.ErrNoRegulationBan)
+
 	s.storageMock.EXPECT().
 		LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
 		Return(attemptsInDB, nil)
 
 	regulator := regulation.NewRegulator(&s.configuration, s.storageMock, &s.clock)
 
-	_, err := regulator.Regulate("john")
+	_, err := regulator.Regulate("john", nil)
 	assert.NoError(s.T(), err)
 }
 
@@ -116,13 +125,17 @@ func (s *RegulatorSuite) TestShouldBanUserIfLatestAttemptsAreWithinFinTime() {
 		},
 	}
 
+	s.storageMock.EXPECT().
+		LoadRegulationBan(gomock.Eq(regulation.BanTypeUser), gomock.Eq("john"), gomock.Any()).
+		Return(nil, storage.ErrNoRegulationBan)
+
 	s.storageMock.EXPECT().
 		LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
 		Return(attemptsInDB, nil)
 
 	regulator := regulation.NewRegulator(&s.configuration, s.storageMock, &s.clock)
 
-	_, err := regulator.Regulate("john")
+	_, err := regulator.Regulate("john", nil)
 	assert.Equal(s.T(), regulation.ErrUserIsBanned, err)
 }
 
@@ -149,13 +162,17 @@ func (s *RegulatorSuite) TestShouldCheckUserIsStillBanned() {
 		},
 	}
 
+	s.storageMock.EXPECT().
+		LoadRegulationBan(gomock.Eq(regulation.BanTypeUser), gomock.Eq("john"), gomock.Any()).
+		Return(nil, storage.ErrNoRegulationBan)
+
 	s.storageMock.EXPECT().
 		LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
 		Return(attemptsInDB, nil)
 
 	regulator := regulation.NewRegulator(&s.configuration, s.storageMock, &s.clock)
 
-	_, err := regulator.Regulate("john")
+	_, err := regulator.Regulate("john", nil)
 	assert.Equal(s.T(), regulation.ErrUserIsBanned, err)
 }
 
@@ -173,13 +190,17 @@ func (s *RegulatorSuite) TestShouldCheckUserIsNotYetBanned() {
 		},
 	}
 
+	s.storageMock.EXPECT().
+		LoadRegulationBan(gomock.Eq(regulation.BanTypeUser), gomock.Eq("john"), gomock.Any()).
+		Return(nil, storage.ErrNoRegulationBan)
+
 	s.storageMock.EXPECT().
 		LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
 		Return(attemptsInDB, nil)
 
 	regulator := regulation.NewRegulator(&s.configuration, s.storageMock, &s.clock)
 
-	_, err := regulator.Regulate("john")
+	_, err := regulator.Regulate("john", nil)
 	assert.NoError(s.T(), err)
 }
 
@@ -205,13 +226,17 @@ func (s *RegulatorSuite) TestShouldCheckUserWasAboutToBeBanned() {
 		},
 	}
 
+	s.storageMock.EXPECT().
+		LoadRegulationBan(gomock.Eq(regulation.BanTypeUser), gomock.Eq("john"), gomock.Any()).
+		Return(nil, storage.ErrNoRegulationBan)
+
 	s.storageMock.EXPECT().
 		LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
 		Return(attemptsInDB, nil)
 
 	regulator := regulation.NewRegulator(&s.configuration, s.storageMock, &s.clock)
 
-	_, err := regulator.Regulate("john")
+	_, err := regulator.Regulate("john", nil)
 	assert.NoError(s.T(), err)
 }
 
@@ -241,13 +266,17 @@ func (s *RegulatorSuite) TestShouldCheckRegulationHasBeenResetOnSuccessfulAttemp
 		},
 	}
 
+	s.storageMock.EXPECT().
+		LoadRegulationBan(gomock.Eq(regulation.BanTypeUser), gomock.Eq("john"), gomock.Any()).
+		Return(nil, storage.ErrNoRegulationBan)
+
 	s.storageMock.EXPECT().
 		LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
 		Return(attemptsInDB, nil)
 
 	regulator := regulation.NewRegulator(&s.configuration, s.storageMock, &s.clock)
 
-	_, err := regulator.Regulate("john")
+	_, err := regulator.Regulate("john", nil)
 	assert.NoError(s.T(), err)
 }
 
@@ -276,6 +305,10 @@ func (s *RegulatorSuite) TestShouldHaveRegulatorDisabled() {
 		},
 	}
 
+	s.storageMock.EXPECT().
+		LoadRegulationBan(gomock.Eq(regulation.BanTypeUser), gomock.Eq("john"), gomock.Any()).
+		Return(nil, storage.ErrNoRegulationBan)
+
 	s.storageMock.EXPECT().
 		LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
 		Return(attemptsInDB, nil)
@@ -288,7 +321,7 @@ func (s *RegulatorSuite) TestShouldHaveRegulatorDisabled() {
 	}
 
 	regulator := regulation.NewRegulator(&configuration, s.storageMock, &s.clock)
-	_, err := regulator.Regulate("john")
+	_, err := regulator.Regulate("john", nil)
 	assert.NoError(s.T(), err)
 
 	// Check Enabled Functionality
@@ -299,6 +332,469 @@ func (s *RegulatorSuite) TestShouldHaveRegulatorDisabled() {
 	}
 
 	regulator = regulation.NewRegulator(&configuration, s.storageMock, &s.clock)
-	_, err = regulator.Regulate("john")
+	_, err = regulator.Regulate("john", nil)
+	assert.Equal(s.T(), regulation.ErrUserIsBanned, err)
+}
+
+func (s *RegulatorSuite) TestShouldBanIPAfterFailedAttemptsOfManyUsers() {
+	ip := net.ParseIP("192.168.1.10")
+
+	configuration := schema.RegulationConfiguration{
+		IP: schema.RegulationIPConfiguration{MaxRetries: 3, FindTime: "5m", BanTime: "30m"},
+	}
+
+	attemptsInDB := []models.AuthenticationAttempt{
+		{Username: "bob", RemoteIP: ip, Time: s.clock.Now()},
+		{Username: "john", RemoteIP: ip, Time: s.clock.Now().Add(-1 * time.Minute)},
+		{Username: "james", RemoteIP: ip, Time: s.clock.Now().Add(-2 * time.Minute)},
+	}
+
+	gomock.InOrder(
+		s.storageMock.EXPECT().
+			AppendAuthenticationLog(gomock.Eq(models.AuthenticationAttempt{Username: "bob", RemoteIP: ip, Time: s.clock.Now()})).
+			Return(nil),
+		s.storageMock.EXPECT().
+			LoadLatestFailedAuthenticationLogsByNetwork(
+				gomock.Eq(&net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)}),
+				gomock.Eq(s.clock.Now().Add(-5*time.Minute)),
+				gomock.Eq(3)).
+			Return(attemptsInDB, nil),
+		s.storageMock.EXPECT().
+			SaveRegulationBan(gomock.Eq(models.RegulationBan{
+				Type:      regulation.BanTypeIP,
+				Value:     "192.168.1.10",
+				Reason:    "3 failed authentication attempts within 5m0s",
+				CreatedAt: s.clock.Now(),
+				ExpiresAt: s.clock.Now().Add(30 * time.Minute),
+			})).
+			Return(nil),
+	)
+
+	regulator := regulation.NewRegulator(&configuration, s.storageMock, &s.clock)
+
+	err := regulator.Mark("bob", ip, false)
+	assert.NoError(s.T(), err)
+}
+
+func (s *RegulatorSuite) TestShouldBanNetworkAfterFailedAttemptsOfManyIPs() {
+	configuration := schema.RegulationConfiguration{
+		Network: schema.RegulationNetworkConfiguration{MaxRetries: 3, FindTime: "5m", BanTime: "1h", IPv4PrefixLength: 24},
+	}
+
+	attemptsInDB := []models.AuthenticationAttempt{
+		{Username: "bob", RemoteIP: net.ParseIP("10.0.0.1"), Time: s.clock.Now()},
+		{Username: "bob", RemoteIP: net.ParseIP("10.0.0.2"), Time: s.clock.Now().Add(-1 * time.Minute)},
+		{Username: "bob", RemoteIP: net.ParseIP("10.0.0.3"), Time: s.clock.Now().Add(-2 * time.Minute)},
+	}
+
+	s.storageMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
+	s.storageMock.EXPECT().
+		LoadLatestFailedAuthenticationLogsByNetwork(
+			gomock.Eq(&net.IPNet{IP: net.ParseIP("10.0.0.0").To4(), Mask: net.CIDRMask(24, 32)}),
+			gomock.Eq(s.clock.Now().Add(-5*time.Minute)),
+			gomock.Eq(3)).
+		Return(attemptsInDB, nil)
+
+	s.storageMock.EXPECT().
+		SaveRegulationBan(gomock.Eq(models.RegulationBan{
+			Type:      regulation.BanTypeNetwork,
+			Value:     "10.0.0.0/24",
+			Reason:    "3 failed authentication attempts within 5m0s",
+			CreatedAt: s.clock.Now(),
+			ExpiresAt: s.clock.Now().Add(time.Hour),
+		})).
+		Return(nil)
+
+	regulator := regulation.NewRegulator(&configuration, s.storageMock, &s.clock)
+
+	err := regulator.Mark("bob", net.ParseIP("10.0.0.1"), false)
+	assert.NoError(s.T(), err)
+}
+
+func (s *RegulatorSuite) TestShouldNotBanIPBelowMaxRetries() {
+	ip := net.ParseIP("192.168.1.10")
+
+	configuration := schema.RegulationConfiguration{
+		IP: schema.RegulationIPConfiguration{MaxRetries: 3, FindTime: "5m", BanTime: "30m"},
+	}
+
+	attemptsInDB := []models.AuthenticationAttempt{
+		{Username: "bob", RemoteIP: ip, Time: s.clock.Now()},
+		{Username: "john", RemoteIP: ip, Time: s.clock.Now().Add(-1 * time.Minute)},
+	}
+
+	s.storageMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
+	s.storageMock.EXPECT().
+		LoadLatestFailedAuthenticationLogsByNetwork(gomock.Any(), gomock.Any(), gomock.Eq(3)).
+		Return(attemptsInDB, nil)
+
+	regulator := regulation.NewRegulator(&configuration, s.storageMock, &s.clock)
+
+	err := regulator.Mark("bob", ip, false)
+	assert.NoError(s.T(), err)
+}
+
+func (s *RegulatorSuite) TestShouldLoadFailedAttemptsOfIPAndNetworkWithTheirThreshold() {
+	ip := net.ParseIP("2001:db8::1")
+
+	configuration := schema.RegulationConfiguration{
+		IP:      schema.RegulationIPConfiguration{MaxRetries: 3, FindTime: "5m", BanTime: "30m"},
+		Network: schema.RegulationNetworkConfiguration{MaxRetries: 10, FindTime: "10m", BanTime: "1h", IPv6PrefixLength: 64},
+	}
+
+	s.storageMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
+	s.storageMock.EXPECT().
+		LoadLatestFailedAuthenticationLogsByNetwork(
+			gomock.Eq(&net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}),
+			gomock.Eq(s.clock.Now().Add(-5*time.Minute)),
+			gomock.Eq(3)).
+		Return([]models.AuthenticationAttempt{{Username: "bob", RemoteIP: ip, Time: s.clock.Now()}}, nil)
+
+	s.storageMock.EXPECT().
+		LoadLatestFailedAuthenticationLogsByNetwork(
+			gomock.Eq(&net.IPNet{IP: net.ParseIP("2001:db8::"), Mask: net.CIDRMask(64, 128)}),
+			gomock.Eq(s.clock.Now().Add(-10*time.Minute)),
+			gomock.Eq(10)).
+		Return([]models.AuthenticationAttempt{
+			{Username: "bob", RemoteIP: ip, Time: s.clock.Now()},
+			{RemoteIP: net.ParseIP("2001:db8::"), Successful: true, Admin: "harry", Time: s.clock.Now().Add(-1 * time.Minute)},
+		}, nil)
+
+	regulator := regulation.NewRegulator(&configuration, s.storageMock, &s.clock)
+
+	err := regulator.Mark("bob", ip, false)
+	assert.NoError(s.T(), err)
+}
+
+func (s *RegulatorSuite) TestShouldRegulateBannedIPAndNetwork() {
+	configuration := schema.RegulationConfiguration{
+		IP:      schema.RegulationIPConfiguration{MaxRetries: 3, FindTime: "5m", BanTime: "30m"},
+		Network: schema.RegulationNetworkConfiguration{MaxRetries: 10, FindTime: "5m", BanTime: "1h"},
+	}
+
+	bannedUntil := s.clock.Now().Add(10 * time.Minute)
+
+	s.storageMock.EXPECT().
+		LoadRegulationBan(gomock.Eq(regulation.BanTypeIP), gomock.Eq("192.168.1.10"), gomock.Eq(s.clock.Now())).
+		Return(&models.RegulationBan{ExpiresAt: bannedUntil}, nil)
+
+	regulator := regulation.NewRegulator(&configuration, s.storageMock, &s.clock)
+
+	until, err := regulator.Regulate("john", net.ParseIP("192.168.1.10"))
+	assert.Equal(s.T(), regulation.ErrIPIsBanned, err)
+	assert.Equal(s.T(), bannedUntil, until)
+
+	s.storageMock.EXPECT().
+		LoadRegulationBan(gomock.Eq(regulation.BanTypeIP), gomock.Eq("2001:db8::1"), gomock.Any()).
+		Return(nil, storage.ErrNoRegulationBan)
+
+	s.storageMock.EXPECT().
+		LoadRegulationBan(gomock.Eq(regulation.BanTypeNetwork), gomock.Eq("2001:db8::/64"), gomock.Any()).
+		Return(&models.RegulationBan{ExpiresAt: bannedUntil}, nil)
+
+	_, err = regulator.Regulate("john", net.ParseIP("2001:db8::1"))
+	assert.Equal(s.T(), regulation.ErrIPIsBanned, err)
+}
+
+func (s *RegulatorSuite) TestShouldNotRegulateExemptNetworks() {
+	ip := net.ParseIP("192.168.1.10")
+
+	configuration := schema.RegulationConfiguration{
+		IP:             schema.RegulationIPConfiguration{MaxRetries: 1, FindTime: "5m", BanTime: "30m"},
+		ExemptNetworks: []string{"10.0.0.1", "192.168.1.0/24"},
+	}
+
+	s.storageMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
+	regulator := regulation.NewRegulator(&configuration, s.storageMock, &s.clock)
+
+	err := regulator.Mark("bob", ip, false)
+	assert.NoError(s.T(), err)
+
+	_, err = regulator.Regulate("bob", ip)
+	assert.NoError(s.T(), err)
+}
+
+func (s *RegulatorSuite) TestShouldRegulateUserBannedByAdministrator() {
+	bannedUntil := s.clock.Now().Add(time.Hour)
+
+	s.storageMock.EXPECT().
+		LoadRegulationBan(gomock.Eq(regulation.BanTypeUser), gomock.Eq("john"), gomock.Eq(s.clock.Now())).
+		Return(&models.RegulationBan{ExpiresAt: bannedUntil}, nil)
+
+	regulator := regulation.NewRegulator(&s.configuration, s.storageMock, &s.clock)
+
+	until, err := regulator.Regulate("john", nil)
+	assert.Equal(s.T(), regulation.ErrUserIsBanned, err)
+	assert.Equal(s.T(), bannedUntil, until)
+}
+
+func (s *RegulatorSuite) TestShouldNotBanUserUnbannedByAdministrator() {
+	attemptsInDB := []models.AuthenticationAttempt{
+		{Username: "john", Time: s.clock.Now().Add(-1 * time.Second)},
+		{Username: "john", Successful: true, Admin: "harry", Time: s.clock.Now().Add(-2 * time.Second)},
+		{Username: "john", Time: s.clock.Now().Add(-3 * time.Second)},
+		{Username: "john", Time: s.clock.Now().Add(-4 * time.Second)},
+		{Username: "john", Time: s.clock.Now().Add(-5 * time.Second)},
+	}
+
+	s.storageMock.EXPECT().
+		LoadRegulationBan(gomock.Eq(regulation.BanTypeUser), gomock.Eq("john"), gomock.Any()).
+		Return(nil, storage.ErrNoRegulationBan)
+
+	s.storageMock.EXPECT().
+		LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
+		Return(attemptsInDB, nil)
+
+	regulator := regulation.NewRegulator(&s.configuration, s.storageMock, &s.clock)
+
+	_, err := regulator.Regulate("john", nil)
+	assert.NoError(s.T(), err)
+}
+
+func (s *RegulatorSuite) TestShouldNotBanIPForAttemptsBeforeUnban() {
+	ip := net.ParseIP("192.168.1.10")
+
+	configuration := schema.RegulationConfiguration{
+		IP: schema.RegulationIPConfiguration{MaxRetries: 3, FindTime: "5m", BanTime: "30m"},
+	}
+
+	attemptsInDB := []models.AuthenticationAttempt{
+		{Username: "bob", RemoteIP: ip, Time: s.clock.Now()},
+		{RemoteIP: ip, Successful: true, Admin: "harry", Time: s.clock.Now().Add(-10 * time.Second)},
+		{Username: "john", RemoteIP: ip, Time: s.clock.Now().Add(-1 * time.Minute)},
+	}
+
+	s.storageMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
+	s.storageMock.EXPECT().
+		LoadLatestFailedAuthenticationLogsByNetwork(gomock.Any(), gomock.Any(), gomock.Eq(3)).
+		Return(attemptsInDB, nil)
+
+	regulator := regulation.NewRegulator(&configuration, s.storageMock, &s.clock)
+
+	err := regulator.Mark("bob", ip, false)
+	assert.NoError(s.T(), err)
+}
+
+func (s *RegulatorSuite) TestShouldBanAndUnbanByAdministrator() {
+	configuration := schema.RegulationConfiguration{
+		MaxRetries: 3,
+		FindTime:   "2m",
+		BanTime:    "5m",
+		Network:    schema.RegulationNetworkConfiguration{MaxRetries: 10, FindTime: "5m", BanTime: "1h", IPv4PrefixLength: 24},
+	}
+
+	gomock.InOrder(
+		s.storageMock.EXPECT().
+			SaveRegulationBan(gomock.Eq(models.RegulationBan{
+				Type:      regulation.BanTypeNetwork,
+				Value:     "10.0.0.0/24",
+				Reason:    "banned by harry",
+				CreatedAt: s.clock.Now(),
+				ExpiresAt: s.clock.Now().Add(time.Hour),
+			})).
+			Return(nil),
+		s.storageMock.EXPECT().
+			AppendAuthenticationLog(gomock.Eq(models.AuthenticationAttempt{
+				RemoteIP: net.ParseIP("10.0.0.0").To4(),
+				Time:     s.clock.Now(),
+				Admin:    "harry",
+			})).
+			Return(nil),
+		s.storageMock.EXPECT().
+			SaveRegulationBan(gomock.Eq(models.RegulationBan{
+				Type:      regulation.BanTypeUser,
+				Value:     "john",
+				Reason:    "compromised",
+				CreatedAt: s.clock.Now(),
+				ExpiresAt: s.clock.Now().Add(24 * time.Hour),
+			})).
+			Return(nil),
+		s.storageMock.EXPECT().
+			AppendAuthenticationLog(gomock.Eq(models.AuthenticationAttempt{
+				Username: "john",
+				Time:     s.clock.Now(),
+				Admin:    "harry",
+			})).
+			Return(nil),
+		s.storageMock.EXPECT().
+			RevokeRegulationBans(gomock.Eq(regulation.BanTypeUser), gomock.Eq("john"), gomock.Eq(s.clock.Now())).
+			Return(nil),
+		s.storageMock.EXPECT().
+			AppendAuthenticationLog(gomock.Eq(models.AuthenticationAttempt{
+				Username:   "john",
+				Successful: true,
+				Time:       s.clock.Now(),
+				Admin:      "harry",
+			})).
+			Return(nil),
+	)
+
+	regulator := regulation.NewRegulator(&configuration, s.storageMock, &s.clock)
+
+	ban, err := regulator.Ban(regulation.BanTypeNetwork, "10.0.0.27", 0, "", "harry")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "10.0.0.0/24", ban.Value)
+
+	_, err = regulator.Ban(regulation.BanTypeUser, "john", 24*time.Hour, "compromised", "harry")
+	assert.NoError(s.T(), err)
+
+	err = regulator.Unban(regulation.BanTypeUser, "john", "harry")
+	assert.NoError(s.T(), err)
+
+	_, err = regulator.Ban(regulation.BanTypeIP, "10.0.0.1", 0, "", "harry")
+	assert.Equal(s.T(), regulation.ErrRegulationDisabled, err)
+
+	_, err = regulator.Ban(regulation.BanTypeNetwork, "not an ip", 0, "", "harry")
+	assert.EqualError(s.T(), err, "invalid ban: the value not an ip is not a valid network")
+
+	err = regulator.Unban("device", "john", "harry")
+	assert.EqualError(s.T(), err, "invalid ban: unknown ban type device, it must be one of user, ip or network")
+}
+
+func (s *RegulatorSuite) TestShouldListBans() {
+	configuration := schema.RegulationConfiguration{
+		MaxRetries: 2,
+		FindTime:   "2m",
+		BanTime:    "5m",
+		IP:         schema.RegulationIPConfiguration{MaxRetries: 3, FindTime: "5m", BanTime: "30m"},
+	}
+
+	ip := net.ParseIP("192.168.1.10")
+
+	failedAttempts := []models.AuthenticationAttempt{
+		{Username: "john", RemoteIP: ip, Time: s.clock.Now().Add(-1 * time.Minute)},
+		{Username: "bob", RemoteIP: ip, Time: s.clock.Now().Add(-2 * time.Minute)},
+		{Username: "john", RemoteIP: ip, Time: s.clock.Now().Add(-150 * time.Second)},
+		{Username: "bob", RemoteIP: ip, Time: s.clock.Now().Add(-4 * time.Minute)},
+	}
+
+	s.storageMock.EXPECT().
+		LoadRegulationBans(gomock.Eq(s.clock.Now())).
+		Return([]models.RegulationBan{{
+			Type:      regulation.BanTypeIP,
+			Value:     "192.168.1.10",
+			Reason:    "4 failed authentication attempts within 5m0s",
+			ExpiresAt: s.clock.Now().Add(20 * time.Minute),
+		}}, nil)
+
+	s.storageMock.EXPECT().
+		LoadLatestFailedAuthenticationLogs(gomock.Eq(s.clock.Now().Add(-30*time.Minute))).
+		Return(failedAttempts, nil)
+
+	// Bob's failed attempts are too far apart to ban him.
+	s.storageMock.EXPECT().
+		LoadLatestAuthenticationLogs(gomock.Eq("bob"), gomock.Any()).
+		Return([]models.AuthenticationAttempt{failedAttempts[1], failedAttempts[3]}, nil)
+
+	s.storageMock.EXPECT().
+		LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
+		Return([]models.AuthenticationAttempt{failedAttempts[0]}, nil)
+
+	regulator := regulation.NewRegulator(&configuration, s.storageMock, &s.clock)
+
+	bans, err := regulator.Bans()
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []regulation.Ban{{
+		Type:        regulation.BanTypeIP,
+		Value:       "192.168.1.10",
+		Reason:      "4 failed authentication attempts within 5m0s",
+		BannedUntil: s.clock.Now().Add(20 * time.Minute),
+		Failures:    4,
+	}}, bans)
+
+	s.storageMock.EXPECT().
+		LoadRegulationBans(gomock.Any()).
+		Return(nil, nil)
+
+	s.storageMock.EXPECT().
+		LoadLatestFailedAuthenticationLogs(gomock.Any()).
+		Return(failedAttempts[:3], nil)
+
+	s.storageMock.EXPECT().
+		LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
+		Return([]models.AuthenticationAttempt{failedAttempts[0], failedAttempts[2]}, nil)
+
+	bans, err = regulator.Bans()
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []regulation.Ban{{
+		Type:        regulation.BanTypeUser,
+		Value:       "john",
+		Reason:      "2 failed authentication attempts within 2m0s",
+		BannedUntil: s.clock.Now().Add(4 * time.Minute),
+		Failures:    2,
+	}}, bans)
+}
+
+func (s *RegulatorSuite) TestShouldRememberUserIsNotBannedForAShortTime() {
+	s.storageMock.EXPECT().
+		LoadRegulationBan(gomock.Eq(regulation.BanTypeUser), gomock.Eq("john"), gomock.Any()).
+		Return(nil, storage.ErrNoRegulationBan).
+		Times(2)
+
+	s.storageMock.EXPECT().
+		LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
+		Return(nil, nil).
+		Times(2)
+
+	regulator := regulation.NewRegulator(&s.configuration, s.storageMock, &s.clock)
+
+	_, err := regulator.RegulateCached("john", nil)
+	assert.NoError(s.T(), err)
+
+	// The user isn't regulated again until the cache expires.
+	_, err = regulator.RegulateCached("john", nil)
+	assert.NoError(s.T(), err)
+
+	s.clock.Set(s.clock.Now().Add(time.Minute))
+
+	_, err = regulator.RegulateCached("john", nil)
+	assert.NoError(s.T(), err)
+}
+
+func (s *RegulatorSuite) TestShouldForgetUserIsNotBannedAfterFailedAttempt() {
+	s.storageMock.EXPECT().
+		LoadRegulationBan(gomock.Eq(regulation.BanTypeUser), gomock.Eq("john"), gomock.Any()).
+		Return(nil, storage.ErrNoRegulationBan).
+		Times(2)
+
+	gomock.InOrder(
+		s.storageMock.EXPECT().
+			LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
+			Return(nil, nil),
+		s.storageMock.EXPECT().
+			AppendAuthenticationLog(gomock.Any()).
+			Return(nil),
+		s.storageMock.EXPECT().
+			LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
+			Return([]models.AuthenticationAttempt{
+				{Username: "john", Successful: false, Time: s.clock.Now()},
+				{Username: "john", Successful: false, Time: s.clock.Now()},
+				{Username: "john", Successful: false, Time: s.clock.Now()},
+			}, nil),
+	)
+
+	regulator := regulation.NewRegulator(&s.configuration, s.storageMock, &s.clock)
+
+	_, err := regulator.RegulateCached("john", nil)
+	assert.NoError(s.T(), err)
+
+	assert.NoError(s.T(), regulator.Mark("john", nil, false))
+
+	_, err = regulator.RegulateCached("john", nil)
 	assert.Equal(s.T(), regulation.ErrUserIsBanned, err)
 }
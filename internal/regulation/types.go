@@ -1,6 +1,8 @@
 package regulation
 
 import (
+	"net"
+	"sync"
 	"time"
 
 	"github.com/authelia/authelia/internal/storage"
@@ -18,7 +20,46 @@ type Regulator struct {
 	// If a user has been banned, this duration is the timelapse during which the user is banned.
 	banTime time.Duration
 
+	// The regulation of the failed authentication attempts made from a single IP address.
+	ip threshold
+	// The regulation of the failed authentication attempts made from the IP addresses of a subnet.
+	network threshold
+
+	ipv4Mask net.IPMask
+	ipv6Mask net.IPMask
+
+	// The networks which are never regulated by IP address or subnet.
+	exemptNetworks []*net.IPNet
+
 	storageProvider storage.Provider
 
 	clock utils.Clock
+
+	// The users and IP addresses recently found not banned by RegulateCached along with when they were. They're all
+	// forgotten whenever an authentication attempt fails or a ban is made.
+	cleared           map[string]time.Time
+	clearedGeneration uint64
+	clearedMutex      sync.Mutex
+}
+
+// threshold is the number of failed authentication attempts within the find time which bans a value for the ban time,
+// it's disabled when maxRetries is 0.
+type threshold struct {
+	maxRetries int
+	findTime   time.Duration
+	banTime    time.Duration
+}
+
+// Ban is an active ban of a user, an IP address or a network. The bans of the users are either computed from their
+// failed authentication attempts or made by an administrator, the bans of the IP addresses and networks are always held
+// by the storage provider.
+type Ban struct {
+	// The type of the banned value, either user, ip or network.
+	Type string
+	// The banned username, IP address or network in the CIDR notation.
+	Value       string
+	Reason      string
+	BannedUntil time.Time
+	// The number of failed authentication attempts of the banned value within the ban time of its type.
+	Failures int
 }
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/valyala/fasthttp"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/authelia/authelia/internal/handlers"
+	"github.com/authelia/authelia/internal/logging"
+)
+
+// extAuthzServer implements the Envoy external authorization gRPC service. The checks are translated into requests
+// handled directly by the external authorization verify handler, the original request is only described by headers so
+// its path can't select another handler.
+type extAuthzServer struct {
+	handler fasthttp.RequestHandler
+}
+
+// newExtAuthzServer creates the Envoy external authorization gRPC server dispatching the checks to the given handler.
+func newExtAuthzServer(handler fasthttp.RequestHandler) *grpc.Server {
+	server := grpc.NewServer()
+
+	authv3.RegisterAuthorizationServer(server, &extAuthzServer{handler: handler})
+
+	return server
+}
+
+// newExtAuthzHTTPHandler returns the handler dispatching the requests of the external authorization HTTP service to the
+// given handler and the other requests to next. The requests are dispatched before being routed since the router
+// normalizes the path, which would let the dot segments of the original request reach other handlers.
+func newExtAuthzHTTPHandler(next, extAuthz fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if _, ok := handlers.ExtAuthzOriginalRequestURI(ctx.Request.Header.RequestURI()); ok {
+			extAuthz(ctx)
+
+			return
+		}
+
+		next(ctx)
+	}
+}
+
+// Check verifies whether the request described by the attributes is authorized.
+func (s *extAuthzServer) Check(_ context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	ctx := &fasthttp.RequestCtx{}
+	request := newExtAuthzRequest(req.GetAttributes())
+
+	ctx.Init(request, newExtAuthzRemoteAddr(req.GetAttributes().GetSource()), nil)
+
+	s.handler(ctx)
+
+	return newExtAuthzCheckResponse(&ctx.Response), nil
+}
+
+// newExtAuthzRequest creates the request to the verify handler from the attributes of the original request.
+func newExtAuthzRequest(attributes *authv3.AttributeContext) *fasthttp.Request {
+	httpRequest := attributes.GetRequest().GetHttp()
+	request := &fasthttp.Request{}
+
+	for key, value := range httpRequest.GetHeaders() {
+		switch {
+		case strings.HasPrefix(key, ":"), strings.EqualFold(key, fasthttp.HeaderContentLength):
+			continue
+		default:
+			request.Header.Set(key, value)
+		}
+	}
+
+	request.Header.Del(fasthttp.HeaderXForwardedProto)
+
+	handlers.SetExtAuthzOriginalRequest(request, httpRequest.GetMethod(), httpRequest.GetScheme(), httpRequest.GetHost(),
+		httpRequest.GetPath())
+
+	return request
+}
+
+// newExtAuthzRemoteAddr returns the address of the peer which made the original request.
+func newExtAuthzRemoteAddr(source *authv3.AttributeContext_Peer) net.Addr {
+	address := source.GetAddress().GetSocketAddress()
+
+	return &net.TCPAddr{
+		IP:   net.ParseIP(address.GetAddress()),
+		Port: int(address.GetPortValue()),
+	}
+}
+
+// newExtAuthzCheckResponse converts the response of the verify endpoint into a check response. The forwarded headers
+// of authorized requests are added to the request sent upstream, or removed when they are not set so they can't be
+// forged by the client. The responses of the other requests are sent back to the client by Envoy.
+func newExtAuthzCheckResponse(response *fasthttp.Response) *authv3.CheckResponse {
+	if response.StatusCode() == fasthttp.StatusOK {
+		okResponse := &authv3.OkHttpResponse{}
+
+		for _, key := range handlers.ExtAuthzForwardedHeaders {
+			if value := response.Header.Peek(key); value != nil {
+				okResponse.Headers = append(okResponse.Headers, newExtAuthzHeader(key, string(value)))
+			} else {
+				okResponse.HeadersToRemove = append(okResponse.HeadersToRemove, key)
+			}
+		}
+
+		return &authv3.CheckResponse{
+			Status:       &status.Status{Code: int32(codes.OK)},
+			HttpResponse: &authv3.CheckResponse_OkResponse{OkResponse: okResponse},
+		}
+	}
+
+	deniedResponse := &authv3.DeniedHttpResponse{
+		Status: &typev3.HttpStatus{Code: typev3.StatusCode(response.StatusCode())},
+		Body:   string(response.Body()),
+	}
+
+	response.Header.VisitAll(func(key, value []byte) {
+		if !strings.EqualFold(string(key), fasthttp.HeaderContentLength) {
+			deniedResponse.Headers = append(deniedResponse.Headers, newExtAuthzHeader(string(key), string(value)))
+		}
+	})
+
+	var code codes.Code
+
+	switch response.StatusCode() {
+	case fasthttp.StatusUnauthorized, fasthttp.StatusFound:
+		code = codes.Unauthenticated
+	case fasthttp.StatusForbidden:
+		code = codes.PermissionDenied
+	default:
+		code = codes.Unknown
+	}
+
+	return &authv3.CheckResponse{
+		Status:       &status.Status{Code: int32(code)},
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{DeniedResponse: deniedResponse},
+	}
+}
+
+func newExtAuthzHeader(key, value string) *corev3.HeaderValueOption {
+	return &corev3.HeaderValueOption{
+		Header: &corev3.HeaderValue{Key: key, Value: value},
+	}
+}
+
+// startExtAuthzServer starts the Envoy external authorization gRPC server on the given address.
+func startExtAuthzServer(handler fasthttp.RequestHandler, address string) {
+	logger := logging.Logger()
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		logger.Fatalf("Error initializing the external authorization listener: %s", err)
+	}
+
+	logger.Infof("Envoy external authorization server is listening for gRPC connections on %s", address)
+
+	if err := newExtAuthzServer(handler).Serve(listener); err != nil {
+		logger.Fatalf("Error while serving the external authorization server: %s", err)
+	}
+}
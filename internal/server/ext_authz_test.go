@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc/codes"
+)
+
+func TestShouldCreateVerifyRequestFromCheckAttributes(t *testing.T) {
+	request := newExtAuthzRequest(&authv3.AttributeContext{
+		Request: &authv3.AttributeContext_Request{
+			Http: &authv3.AttributeContext_HttpRequest{
+				Method: "POST",
+				Host:   "one-factor.example.com",
+				Path:   "/abc?x=1",
+				Scheme: "https",
+				Headers: map[string]string{
+					":authority":     "one-factor.example.com",
+					"content-length": "10",
+					"cookie":         "authelia_session=abc",
+					"x-original-url": "https://bypass.example.com/",
+				},
+			},
+		},
+	})
+
+	assert.Equal(t, "GET", string(request.Header.Method()))
+	assert.Equal(t, "POST", string(request.Header.Peek("X-Forwarded-Method")))
+	assert.Equal(t, "/api/verify/ext-authz", string(request.RequestURI()))
+	assert.Equal(t, "https://one-factor.example.com/abc?x=1", string(request.Header.Peek("X-Original-URL")))
+	assert.Equal(t, "one-factor.example.com", string(request.Host()))
+	assert.Equal(t, "https", string(request.Header.Peek("X-Forwarded-Proto")))
+	assert.Equal(t, "abc", string(request.Header.Cookie("authelia_session")))
+	assert.Nil(t, request.Header.Peek(":authority"))
+	assert.Equal(t, 0, request.Header.ContentLength())
+}
+
+func TestShouldNotLeaveVerifyHandlerWithDotSegmentsOfCheckPath(t *testing.T) {
+	var requestURIs, originalURLs []string
+
+	server := &extAuthzServer{handler: func(ctx *fasthttp.RequestCtx) {
+		requestURIs = append(requestURIs, string(ctx.RequestURI()))
+		originalURLs = append(originalURLs, string(ctx.Request.Header.Peek("X-Original-URL")))
+		ctx.SetStatusCode(fasthttp.StatusUnauthorized)
+	}}
+
+	paths := []string{"/../../health", "/%2e%2e/%2e%2e/x", "//evil.example.com/x"}
+
+	for _, path := range paths {
+		response, err := server.Check(context.Background(), &authv3.CheckRequest{
+			Attributes: &authv3.AttributeContext{
+				Request: &authv3.AttributeContext_Request{
+					Http: &authv3.AttributeContext_HttpRequest{Method: "GET", Host: "secure.example.com", Path: path, Scheme: "https"},
+				},
+			},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(codes.Unauthenticated), response.GetStatus().GetCode())
+	}
+
+	assert.Equal(t, []string{"/api/verify/ext-authz", "/api/verify/ext-authz", "/api/verify/ext-authz"}, requestURIs)
+	assert.Equal(t, []string{
+		"https://secure.example.com/../../health",
+		"https://secure.example.com/%2e%2e/%2e%2e/x",
+		"https://secure.example.com//evil.example.com/x",
+	}, originalURLs)
+}
+
+func TestShouldDispatchExtAuthzHTTPRequestsBeforeRouting(t *testing.T) {
+	var dispatched []string
+
+	next := func(ctx *fasthttp.RequestCtx) {
+		dispatched = append(dispatched, "router")
+	}
+
+	extAuthz := func(ctx *fasthttp.RequestCtx) {
+		dispatched = append(dispatched, "ext-authz")
+	}
+
+	handler := newExtAuthzHTTPHandler(next, extAuthz)
+
+	for _, requestURI := range []string{
+		"/api/verify/ext-authz/../../health",
+		"/api/verify/ext-authz/%2e%2e/%2e%2e/x",
+		"/api/verify/ext-authz//evil.example.com/x",
+		"/api/verify/ext-authz",
+		"/api/health",
+		"/api/verify/ext-authzx",
+	} {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(requestURI)
+
+		handler(ctx)
+	}
+
+	assert.Equal(t, []string{"ext-authz", "ext-authz", "ext-authz", "ext-authz", "router", "router"}, dispatched)
+}
+
+func TestShouldConvertAuthorizedResponseToOkCheckResponse(t *testing.T) {
+	response := &fasthttp.Response{}
+	response.Header.Set("Remote-User", "john")
+	response.Header.Set("Remote-Groups", "admins,dev")
+
+	checkResponse := newExtAuthzCheckResponse(response)
+
+	assert.Equal(t, int32(codes.OK), checkResponse.GetStatus().GetCode())
+
+	okResponse := checkResponse.GetOkResponse()
+	require.NotNil(t, okResponse)
+
+	assert.Equal(t, []*corev3.HeaderValueOption{
+		{Header: &corev3.HeaderValue{Key: "Remote-User", Value: "john"}},
+		{Header: &corev3.HeaderValue{Key: "Remote-Groups", Value: "admins,dev"}},
+	}, okResponse.GetHeaders())
+	assert.Equal(t, []string{"Remote-Name", "Remote-Email"}, okResponse.GetHeadersToRemove())
+}
+
+func TestShouldConvertRedirectResponseToDeniedCheckResponse(t *testing.T) {
+	response := &fasthttp.Response{}
+	response.SetStatusCode(fasthttp.StatusFound)
+	response.Header.Set("Location", "https://auth.example.com/?rd=https%3A%2F%2Fexample.com")
+	response.SetBodyString("Found. Redirecting to https://auth.example.com/?rd=https%3A%2F%2Fexample.com")
+
+	checkResponse := newExtAuthzCheckResponse(response)
+
+	assert.Equal(t, int32(codes.Unauthenticated), checkResponse.GetStatus().GetCode())
+
+	deniedResponse := checkResponse.GetDeniedResponse()
+	require.NotNil(t, deniedResponse)
+
+	assert.Equal(t, int32(fasthttp.StatusFound), int32(deniedResponse.GetStatus().GetCode()))
+	assert.Equal(t, "Found. Redirecting to https://auth.example.com/?rd=https%3A%2F%2Fexample.com", deniedResponse.GetBody())
+
+	headers := map[string]string{}
+	for _, header := range deniedResponse.GetHeaders() {
+		headers[header.GetHeader().GetKey()] = header.GetHeader().GetValue()
+	}
+
+	assert.Equal(t, "https://auth.example.com/?rd=https%3A%2F%2Fexample.com", headers["Location"])
+	assert.NotContains(t, headers, "Content-Length")
+}
+
+func TestShouldConvertForbiddenResponseToDeniedCheckResponse(t *testing.T) {
+	response := &fasthttp.Response{}
+	response.SetStatusCode(fasthttp.StatusForbidden)
+
+	checkResponse := newExtAuthzCheckResponse(response)
+
+	assert.Equal(t, int32(codes.PermissionDenied), checkResponse.GetStatus().GetCode())
+	assert.Equal(t, int32(fasthttp.StatusForbidden), int32(checkResponse.GetDeniedResponse().GetStatus().GetCode()))
+}
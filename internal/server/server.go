@@ -72,10 +72,14 @@ func registerRoutes(configuration schema.Configuration, providers middlewares.Pr
 			handlers.ResetPasswordIdentityStart))
 		r.POST("/api/reset-password/identity/finish", autheliaMiddleware(
 			handlers.ResetPasswordIdentityFinish))
-		r.POST("/api/reset-password", autheliaMiddleware(
-			handlers.ResetPasswordPost))
 	}
 
+	// The password is also changed with this endpoint when the user provider requires it to be changed on sign in, so
+	// it's registered even if forgot password is disabled. It only accepts the sessions in which the first factor or the
+	// identity verification of the reset password process set the user whose password is changed.
+	r.POST("/api/reset-password", autheliaMiddleware(
+		handlers.ResetPasswordPost))
+
 	// Information about the user.
 	r.GET("/api/user/info", autheliaMiddleware(
 		middlewares.RequireFirstFactor(handlers.UserInfoGet)))
@@ -93,20 +97,31 @@ func registerRoutes(configuration schema.Configuration, providers middlewares.Pr
 			Skew:   uint(*configuration.TOTP.Skew),
 		}))))
 
-	// U2F related endpoints.
-	r.POST("/api/secondfactor/u2f/identity/start", autheliaMiddleware(
-		middlewares.RequireFirstFactor(handlers.SecondFactorU2FIdentityStart)))
-	r.POST("/api/secondfactor/u2f/identity/finish", autheliaMiddleware(
-		middlewares.RequireFirstFactor(handlers.SecondFactorU2FIdentityFinish)))
-
-	r.POST("/api/secondfactor/u2f/register", autheliaMiddleware(
-		middlewares.RequireFirstFactor(handlers.SecondFactorU2FRegister)))
-
-	r.POST("/api/secondfactor/u2f/sign_request", autheliaMiddleware(
-		middlewares.RequireFirstFactor(handlers.SecondFactorU2FSignGet)))
-
-	r.POST("/api/secondfactor/u2f/sign", autheliaMiddleware(
-		middlewares.RequireFirstFactor(handlers.SecondFactorU2FSignPost(&handlers.U2FVerifierImpl{}))))
+	r.GET("/api/secondfactor/totp/configurations", autheliaMiddleware(
+		middlewares.RequireFirstFactor(handlers.SecondFactorTOTPConfigurationsGet)))
+	r.DELETE("/api/secondfactor/totp/configurations/{id}", autheliaMiddleware(
+		middlewares.RequireSecondFactor(handlers.SecondFactorTOTPConfigurationDelete)))
+
+	if !configuration.Webauthn.Disable {
+		// Webauthn related endpoints.
+		r.POST("/api/secondfactor/webauthn/identity/start", autheliaMiddleware(
+			middlewares.RequireFirstFactor(handlers.SecondFactorWebauthnIdentityStart)))
+		r.POST("/api/secondfactor/webauthn/identity/finish", autheliaMiddleware(
+			middlewares.RequireFirstFactor(handlers.SecondFactorWebauthnIdentityFinish)))
+
+		r.POST("/api/secondfactor/webauthn/attestation", autheliaMiddleware(
+			middlewares.RequireFirstFactor(handlers.SecondFactorWebauthnAttestationPost)))
+
+		r.GET("/api/secondfactor/webauthn/assertion", autheliaMiddleware(
+			middlewares.RequireFirstFactor(handlers.SecondFactorWebauthnAssertionGet)))
+		r.POST("/api/secondfactor/webauthn/assertion", autheliaMiddleware(
+			middlewares.RequireFirstFactor(handlers.SecondFactorWebauthnAssertionPost)))
+
+		r.GET("/api/secondfactor/webauthn/devices", autheliaMiddleware(
+			middlewares.RequireFirstFactor(handlers.SecondFactorWebauthnDevicesGet)))
+		r.DELETE("/api/secondfactor/webauthn/devices/{id}", autheliaMiddleware(
+			middlewares.RequireSecondFactor(handlers.SecondFactorWebauthnDeviceDelete)))
+	}
 
 	// Configure DUO api endpoint only if configuration exists.
 	if configuration.DuoAPI != nil {
@@ -127,6 +142,8 @@ func registerRoutes(configuration schema.Configuration, providers middlewares.Pr
 			middlewares.RequireFirstFactor(handlers.SecondFactorDuoPost(duoAPI))))
 	}
 
+	handlers.RegisterRegulation(r, autheliaMiddleware)
+
 	if configuration.Server.EnablePprof {
 		r.GET("/debug/pprof/{name?}", pprofhandler.PprofHandler)
 	}
@@ -137,7 +154,10 @@ func registerRoutes(configuration schema.Configuration, providers middlewares.Pr
 
 	r.NotFound = serveIndexHandler
 
-	handler := middlewares.LogRequestMiddleware(r.Handler)
+	extAuthzHandler := autheliaMiddleware(
+		handlers.VerifyExtAuthzHTTP(configuration.AuthenticationBackend, configuration.Server.ExtAuthz.AutheliaURL))
+
+	handler := middlewares.LogRequestMiddleware(newExtAuthzHTTPHandler(r.Handler, extAuthzHandler))
 	if configuration.Server.Path != "" {
 		handler = middlewares.StripPathMiddleware(handler)
 	}
@@ -187,6 +207,14 @@ func StartServer(configuration schema.Configuration, providers middlewares.Provi
 		}
 	}
 
+	if configuration.Server.ExtAuthz.GRPCPort != 0 {
+		extAuthzHandler := middlewares.AutheliaMiddleware(configuration, providers)(
+			handlers.VerifyExtAuthz(configuration.AuthenticationBackend, configuration.Server.ExtAuthz.AutheliaURL))
+
+		go startExtAuthzServer(middlewares.LogRequestMiddleware(extAuthzHandler),
+			net.JoinHostPort(configuration.Host, strconv.Itoa(configuration.Server.ExtAuthz.GRPCPort)))
+	}
+
 	if configuration.TLSCert != "" && configuration.TLSKey != "" {
 		logger.Infof("Authelia is listening for TLS connections on %s%s", addrPattern, configuration.Server.Path)
 		logger.Fatal(server.ServeTLS(listener, configuration.TLSCert, configuration.TLSKey))
@@ -3,9 +3,9 @@ package session
 import (
 	"time"
 
+	"github.com/duo-labs/webauthn/webauthn"
 	"github.com/fasthttp/session/v2"
 	"github.com/fasthttp/session/v2/providers/redis"
-	"github.com/tstranex/u2f"
 
 	"github.com/authelia/authelia/internal/authentication"
 	"github.com/authelia/authelia/internal/authorization"
@@ -19,12 +19,6 @@ type ProviderConfig struct {
 	providerName        string
 }
 
-// U2FRegistration is a serializable version of a U2F registration.
-type U2FRegistration struct {
-	KeyHandle []byte
-	PublicKey []byte
-}
-
 // UserSession is the structure representing the session of a user.
 type UserSession struct {
 	Username    string
@@ -40,16 +34,21 @@ type UserSession struct {
 	FirstFactorAuthnTimestamp  int64
 	SecondFactorAuthnTimestamp int64
 
-	// The challenge generated in first step of U2F registration (after identity verification) or authentication.
-	// This is used reused in the second phase to check that the challenge has been completed.
-	U2FChallenge *u2f.Challenge
-	// The registration representing a U2F device in DB set after identity verification.
-	// This is used in second phase of a U2F authentication.
-	U2FRegistration *U2FRegistration
+	// This boolean is set to true when the second factor authentication is older than the maximum age of a rule and
+	// reset when the user completes the second factor authentication again.
+	SecondFactorReauthenticationRequired bool
+
+	// The Webauthn session data generated when beginning a Webauthn registration (after identity verification) or
+	// authentication. This is used in the second phase to check that the challenge has been completed.
+	Webauthn *webauthn.SessionData
 
 	// Represent an OIDC workflow session initiated by the client if not null.
 	OIDCWorkflowSession *OIDCWorkflowSession
 
+	// Represent the OIDC clients which were issued an ID token during this session if not null. They are notified
+	// when the session is destroyed.
+	OIDCLogoutSession *OIDCLogoutSession
+
 	// This boolean is set to true after identity verification and checked
 	// while doing the query actually updating the password.
 	PasswordResetUsername *string
@@ -74,4 +73,17 @@ type OIDCWorkflowSession struct {
 	AuthURI                    string
 	RequiredAuthorizationLevel authorization.Level
 	CreatedTimestamp           int64
+
+	// The pushed authorization request consumed by the workflow, the authorization endpoint is requested again with
+	// its request URI once the user has authenticated and consented.
+	PushedAuthorizeRequestURI  string
+	PushedAuthorizeRequestForm string
+}
+
+// OIDCLogoutSession represent the OIDC clients a user signed in to during a session.
+type OIDCLogoutSession struct {
+	// ID is the sid claim of the ID tokens which identifies this session to the clients.
+	ID        string
+	Issuer    string
+	ClientIDs []string
 }
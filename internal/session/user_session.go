@@ -36,6 +36,7 @@ func (s *UserSession) SetTwoFactor(now time.Time) {
 	s.SecondFactorAuthnTimestamp = now.Unix()
 	s.LastActivity = now.Unix()
 	s.AuthenticationLevel = authentication.TwoFactor
+	s.SecondFactorReauthenticationRequired = false
 }
 
 // AuthenticatedTime returns the unix timestamp this session authenticated successfully at the given level.
@@ -4,7 +4,7 @@ import (
 	"fmt"
 )
 
-const storageSchemaCurrentVersion = SchemaVersion(1)
+const storageSchemaCurrentVersion = SchemaVersion(13)
 const storageSchemaUpgradeMessage = "Storage schema upgraded to v"
 const storageSchemaUpgradeErrorText = "storage schema upgrade failed at v"
 
@@ -12,9 +12,47 @@ const storageSchemaUpgradeErrorText = "storage schema upgrade failed at v"
 const userPreferencesTableName = "user_preferences"
 const identityVerificationTokensTableName = "identity_verification_tokens"
 const totpSecretsTableName = "totp_secrets"
+const totpConfigurationsTableName = "totp_configurations"
 const u2fDeviceHandlesTableName = "u2f_devices"
+const webauthnDevicesTableName = "webauthn_devices"
 const authenticationLogsTableName = "authentication_logs"
+const regulationBansTableName = "regulation_bans"
 const configTableName = "config"
+const usersTableName = "users"
+const userEmailsTableName = "user_emails"
+const userGroupsTableName = "user_groups"
+const userAttributesTableName = "user_attributes"
+
+const oauth2AuthorizeCodeSessionsTableName = "oauth2_authorization_code_sessions"
+const oauth2AccessTokenSessionsTableName = "oauth2_access_token_sessions"
+const oauth2RefreshTokenSessionsTableName = "oauth2_refresh_token_sessions"
+const oauth2PKCERequestSessionsTableName = "oauth2_pkce_request_sessions"
+const oauth2OpenIDConnectSessionsTableName = "oauth2_openid_connect_sessions"
+const oauth2BlacklistedJTIsTableName = "oauth2_blacklisted_jtis"
+const oauth2ConsentSessionsTableName = "oauth2_consent_sessions"
+const oauth2DeviceCodeSessionsTableName = "oauth2_device_code_sessions"
+const oauth2PushedAuthorizeRequestsTableName = "oauth2_pushed_authorization_requests"
+const oauth2ClientsTableName = "oauth2_clients"
+
+// oauth2SessionTypes is every OAuth2SessionType, each of which is stored in its own table.
+var oauth2SessionTypes = []OAuth2SessionType{
+	OAuth2SessionTypeAuthorizeCode,
+	OAuth2SessionTypeAccessToken,
+	OAuth2SessionTypeRefreshToken,
+	OAuth2SessionTypePKCEChallenge,
+	OAuth2SessionTypeOpenIDConnect,
+}
+
+const sqlCreateOAuth2SessionTableColumns = "signature VARCHAR(255) NOT NULL, request_id VARCHAR(40) NOT NULL, client_id VARCHAR(255) NOT NULL, subject VARCHAR(255) NOT NULL, requested_at INTEGER NOT NULL, expires_at INTEGER NULL, requested_scopes TEXT NOT NULL, granted_scopes TEXT NOT NULL, requested_audience TEXT NOT NULL, granted_audience TEXT NOT NULL, form_data TEXT NOT NULL, session_data TEXT NOT NULL, active BOOLEAN NOT NULL DEFAULT TRUE, UNIQUE (signature)"
+const sqlCreateOAuth2DeviceCodeSessionTableColumns = "signature VARCHAR(64) NOT NULL, user_code_signature VARCHAR(64) NOT NULL, client_id VARCHAR(255) NOT NULL, subject VARCHAR(255) NOT NULL, status INTEGER NOT NULL, requested_at INTEGER NOT NULL, expires_at INTEGER NOT NULL, last_polled_at INTEGER NULL, requested_scopes TEXT NOT NULL, granted_scopes TEXT NOT NULL, requested_audience TEXT NOT NULL, granted_audience TEXT NOT NULL, session_data TEXT NOT NULL, UNIQUE (signature), UNIQUE (user_code_signature)"
+const sqlCreateOAuth2ClientTableColumns = "client_id VARCHAR(255) NOT NULL, description VARCHAR(255) NOT NULL, secret TEXT NOT NULL, redirect_uris TEXT NOT NULL, authorization_policy VARCHAR(32) NOT NULL, scopes TEXT NOT NULL, audience TEXT NOT NULL, grant_types TEXT NOT NULL, response_types TEXT NOT NULL, response_modes TEXT NOT NULL, id_token_signing_algorithm VARCHAR(16) NOT NULL, userinfo_signing_algorithm VARCHAR(16) NOT NULL, consent_mode VARCHAR(32) NOT NULL, pre_configured_consent_duration INTEGER NOT NULL, created_at INTEGER NOT NULL, updated_at INTEGER NOT NULL, UNIQUE (client_id)"
+const sqlCreateOAuth2PushedAuthorizeRequestTableColumns = "signature VARCHAR(64) NOT NULL, client_id VARCHAR(255) NOT NULL, requested_at INTEGER NOT NULL, expires_at INTEGER NOT NULL, form_data TEXT NOT NULL, UNIQUE (signature)"
+const sqlCreateRegulationBanTableColumns = "ban_type VARCHAR(16) NOT NULL, value VARCHAR(255) NOT NULL, reason TEXT NOT NULL, created_at INTEGER NOT NULL, expires_at INTEGER NOT NULL, revoked BOOLEAN NOT NULL DEFAULT FALSE"
+const sqlCreateUserTableColumns = "username VARCHAR(100) NOT NULL, display_name VARCHAR(255) NOT NULL, password TEXT NOT NULL, disabled BOOLEAN NOT NULL DEFAULT FALSE, created_at INTEGER NOT NULL, updated_at INTEGER NOT NULL, UNIQUE (username)"
+const sqlCreateUserEmailTableColumns = "username VARCHAR(100) NOT NULL, email VARCHAR(255) NOT NULL, UNIQUE (username, email)"
+const sqlCreateUserGroupTableColumns = "username VARCHAR(100) NOT NULL, group_name VARCHAR(255) NOT NULL, UNIQUE (username, group_name)"
+const sqlCreateUserAttributeTableColumns = "username VARCHAR(100) NOT NULL, name VARCHAR(100) NOT NULL, value TEXT NOT NULL"
+const sqlCreateOAuth2SessionTable = "CREATE TABLE %s (id INTEGER PRIMARY KEY AUTOINCREMENT, " + sqlCreateOAuth2SessionTableColumns + ")"
 
 // sqlUpgradeCreateTableStatements is a map of the schema version number, plus a map of the table name and the statement used to create it.
 // The statement is fmt.Sprintf'd with the table name as the first argument.
@@ -27,6 +65,41 @@ var sqlUpgradeCreateTableStatements = map[SchemaVersion]map[string]string{
 		authenticationLogsTableName:         "CREATE TABLE %s (username VARCHAR(100), successful BOOL, time INTEGER)",
 		configTableName:                     "CREATE TABLE %s (category VARCHAR(32) NOT NULL, key_name VARCHAR(32) NOT NULL, value TEXT, PRIMARY KEY (category, key_name))",
 	},
+	SchemaVersion(2): {
+		webauthnDevicesTableName: "CREATE TABLE %s (id INTEGER PRIMARY KEY AUTOINCREMENT, created_at INTEGER NOT NULL, last_used_at INTEGER NULL, username VARCHAR(100) NOT NULL, description VARCHAR(30) NOT NULL, kid VARCHAR(512) NOT NULL, public_key TEXT NOT NULL, attestation_type VARCHAR(32), aaguid CHAR(36) NOT NULL, sign_count INTEGER DEFAULT 0, clone_warning BOOLEAN NOT NULL DEFAULT FALSE, UNIQUE (username, description), UNIQUE (kid))",
+	},
+	SchemaVersion(3): {
+		totpConfigurationsTableName: "CREATE TABLE %s (id INTEGER PRIMARY KEY AUTOINCREMENT, created_at INTEGER NOT NULL, last_used_at INTEGER NULL, username VARCHAR(100) NOT NULL, description VARCHAR(30) NOT NULL, secret VARCHAR(64) NOT NULL, UNIQUE (username, description))",
+	},
+	SchemaVersion(4): {
+		oauth2AuthorizeCodeSessionsTableName: sqlCreateOAuth2SessionTable,
+		oauth2AccessTokenSessionsTableName:   sqlCreateOAuth2SessionTable,
+		oauth2RefreshTokenSessionsTableName:  sqlCreateOAuth2SessionTable,
+		oauth2PKCERequestSessionsTableName:   sqlCreateOAuth2SessionTable,
+		oauth2OpenIDConnectSessionsTableName: sqlCreateOAuth2SessionTable,
+		oauth2BlacklistedJTIsTableName:       "CREATE TABLE %s (id INTEGER PRIMARY KEY AUTOINCREMENT, signature VARCHAR(64) NOT NULL, expires_at INTEGER NOT NULL, UNIQUE (signature))",
+	},
+	SchemaVersion(5): {
+		oauth2ConsentSessionsTableName: "CREATE TABLE %s (id INTEGER PRIMARY KEY AUTOINCREMENT, client_id VARCHAR(255) NOT NULL, subject VARCHAR(255) NOT NULL, created_at INTEGER NOT NULL, expires_at INTEGER NOT NULL, granted_scopes TEXT NOT NULL, granted_audience TEXT NOT NULL)",
+	},
+	SchemaVersion(6): {
+		oauth2DeviceCodeSessionsTableName: "CREATE TABLE %s (id INTEGER PRIMARY KEY AUTOINCREMENT, " + sqlCreateOAuth2DeviceCodeSessionTableColumns + ")",
+	},
+	SchemaVersion(7): {
+		oauth2ClientsTableName: "CREATE TABLE %s (id INTEGER PRIMARY KEY AUTOINCREMENT, " + sqlCreateOAuth2ClientTableColumns + ")",
+	},
+	SchemaVersion(9): {
+		oauth2PushedAuthorizeRequestsTableName: "CREATE TABLE %s (id INTEGER PRIMARY KEY AUTOINCREMENT, " + sqlCreateOAuth2PushedAuthorizeRequestTableColumns + ")",
+	},
+	SchemaVersion(10): {
+		regulationBansTableName: "CREATE TABLE %s (id INTEGER PRIMARY KEY AUTOINCREMENT, " + sqlCreateRegulationBanTableColumns + ")",
+	},
+	SchemaVersion(12): {
+		usersTableName:          "CREATE TABLE %s (id INTEGER PRIMARY KEY AUTOINCREMENT, " + sqlCreateUserTableColumns + ")",
+		userEmailsTableName:     "CREATE TABLE %s (id INTEGER PRIMARY KEY AUTOINCREMENT, " + sqlCreateUserEmailTableColumns + ")",
+		userGroupsTableName:     "CREATE TABLE %s (id INTEGER PRIMARY KEY AUTOINCREMENT, " + sqlCreateUserGroupTableColumns + ")",
+		userAttributesTableName: "CREATE TABLE %s (id INTEGER PRIMARY KEY AUTOINCREMENT, " + sqlCreateUserAttributeTableColumns + ")",
+	},
 }
 
 // sqlUpgradesCreateTableIndexesStatements is a map of t he schema version number, plus a slice of statements to create all of the indexes.
@@ -34,6 +107,24 @@ var sqlUpgradesCreateTableIndexesStatements = map[SchemaVersion][]string{
 	SchemaVersion(1): {
 		fmt.Sprintf("CREATE INDEX IF NOT EXISTS usr_time_idx ON %s (username, time)", authenticationLogsTableName),
 	},
+	SchemaVersion(4): {
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS oauth2_authorization_code_sessions_request_id_idx ON %s (request_id)", oauth2AuthorizeCodeSessionsTableName),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS oauth2_access_token_sessions_request_id_idx ON %s (request_id)", oauth2AccessTokenSessionsTableName),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS oauth2_refresh_token_sessions_request_id_idx ON %s (request_id)", oauth2RefreshTokenSessionsTableName),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS oauth2_pkce_request_sessions_request_id_idx ON %s (request_id)", oauth2PKCERequestSessionsTableName),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS oauth2_openid_connect_sessions_request_id_idx ON %s (request_id)", oauth2OpenIDConnectSessionsTableName),
+	},
+	SchemaVersion(5): {
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS oauth2_consent_sessions_subject_idx ON %s (subject, client_id)", oauth2ConsentSessionsTableName),
+	},
+	SchemaVersion(10): {
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS remote_ip_time_idx ON %s (remote_ip, time)", authenticationLogsTableName),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS regulation_bans_value_idx ON %s (ban_type, value)", regulationBansTableName),
+	},
+	SchemaVersion(12): {
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS user_attributes_username_idx ON %s (username, name)", userAttributesTableName),
+	},
 }
 
 const unitTestUser = "john"
+const unitTestMethod = "totp"
@@ -3,9 +3,33 @@ package storage
 import "errors"
 
 var (
-	// ErrNoU2FDeviceHandle error thrown when no U2F device handle has been found in DB.
-	ErrNoU2FDeviceHandle = errors.New("No U2F device handle found")
+	// ErrNoWebauthnDevice error thrown when no Webauthn device has been found in DB.
+	ErrNoWebauthnDevice = errors.New("No Webauthn device found")
 
-	// ErrNoTOTPSecret error thrown when no TOTP secret has been found in DB.
-	ErrNoTOTPSecret = errors.New("No TOTP secret registered")
+	// ErrNoTOTPConfiguration error thrown when no TOTP configuration has been found in DB.
+	ErrNoTOTPConfiguration = errors.New("No TOTP configuration found")
+
+	// ErrNoOAuth2Session error thrown when no OAuth 2.0 session has been found in DB.
+	ErrNoOAuth2Session = errors.New("No OAuth 2.0 session found")
+
+	// ErrNoOAuth2BlacklistedJTI error thrown when no blacklisted JWT ID has been found in DB.
+	ErrNoOAuth2BlacklistedJTI = errors.New("No OAuth 2.0 blacklisted JTI found")
+
+	// ErrNoOAuth2ConsentSession error thrown when no OAuth 2.0 consent session has been found in DB.
+	ErrNoOAuth2ConsentSession = errors.New("No OAuth 2.0 consent session found")
+
+	// ErrNoOAuth2DeviceCodeSession error thrown when no OAuth 2.0 device code session has been found in DB.
+	ErrNoOAuth2DeviceCodeSession = errors.New("No OAuth 2.0 device code session found")
+
+	// ErrNoOAuth2PushedAuthorizeRequest error thrown when no OAuth 2.0 pushed authorization request has been found in DB.
+	ErrNoOAuth2PushedAuthorizeRequest = errors.New("No OAuth 2.0 pushed authorization request found")
+
+	// ErrNoOAuth2Client error thrown when no OpenID Connect client has been found in DB.
+	ErrNoOAuth2Client = errors.New("No OpenID Connect client found")
+
+	// ErrNoRegulationBan error thrown when no active regulation ban has been found in DB.
+	ErrNoRegulationBan = errors.New("No regulation ban found")
+
+	// ErrNoUser error thrown when no user has been found in DB.
+	ErrNoUser = errors.New("No user found")
 )
@@ -29,15 +29,80 @@ func NewMySQLProvider(configuration schema.MySQLStorageConfiguration) *MySQLProv
 			sqlInsertIdentityVerificationToken:        fmt.Sprintf("INSERT INTO %s (token) VALUES (?)", identityVerificationTokensTableName),
 			sqlDeleteIdentityVerificationToken:        fmt.Sprintf("DELETE FROM %s WHERE token=?", identityVerificationTokensTableName),
 
-			sqlGetTOTPSecretByUsername: fmt.Sprintf("SELECT secret FROM %s WHERE username=?", totpSecretsTableName),
-			sqlUpsertTOTPSecret:        fmt.Sprintf("REPLACE INTO %s (username, secret) VALUES (?, ?)", totpSecretsTableName),
-			sqlDeleteTOTPSecret:        fmt.Sprintf("DELETE FROM %s WHERE username=?", totpSecretsTableName),
-
-			sqlGetU2FDeviceHandleByUsername: fmt.Sprintf("SELECT keyHandle, publicKey FROM %s WHERE username=?", u2fDeviceHandlesTableName),
-			sqlUpsertU2FDeviceHandle:        fmt.Sprintf("REPLACE INTO %s (username, keyHandle, publicKey) VALUES (?, ?, ?)", u2fDeviceHandlesTableName),
-
-			sqlInsertAuthenticationLog:     fmt.Sprintf("INSERT INTO %s (username, successful, time) VALUES (?, ?, ?)", authenticationLogsTableName),
-			sqlGetLatestAuthenticationLogs: fmt.Sprintf("SELECT successful, time FROM %s WHERE time>? AND username=? ORDER BY time DESC", authenticationLogsTableName),
+			sqlInsertTOTPConfiguration:         fmt.Sprintf("INSERT INTO %s (created_at, last_used_at, username, description, secret) VALUES (?, ?, ?, ?, ?)", totpConfigurationsTableName),
+			sqlUpdateTOTPConfigurationSignIn:   fmt.Sprintf("UPDATE %s SET last_used_at=? WHERE id=?", totpConfigurationsTableName),
+			sqlGetTOTPConfigurationsByUsername: fmt.Sprintf("SELECT id, created_at, last_used_at, username, description, secret FROM %s WHERE username=?", totpConfigurationsTableName),
+			sqlDeleteTOTPConfiguration:         fmt.Sprintf("DELETE FROM %s WHERE id=? AND username=?", totpConfigurationsTableName),
+			sqlUpgradeGetTOTPSecrets:           fmt.Sprintf("SELECT username, secret FROM %s", totpSecretsTableName),
+
+			sqlUpsertWebauthnDevice:         fmt.Sprintf("REPLACE INTO %s (created_at, last_used_at, username, description, kid, public_key, attestation_type, aaguid, sign_count, clone_warning) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", webauthnDevicesTableName),
+			sqlUpdateWebauthnDeviceSignIn:   fmt.Sprintf("UPDATE %s SET last_used_at=?, sign_count=?, clone_warning=? WHERE id=?", webauthnDevicesTableName),
+			sqlGetWebauthnDevicesByUsername: fmt.Sprintf("SELECT id, created_at, last_used_at, username, description, kid, public_key, attestation_type, aaguid, sign_count, clone_warning FROM %s WHERE username=?", webauthnDevicesTableName),
+			sqlDeleteWebauthnDevice:         fmt.Sprintf("DELETE FROM %s WHERE id=? AND username=?", webauthnDevicesTableName),
+
+			sqlUpgradeGetU2FDeviceHandles:      fmt.Sprintf("SELECT username, keyHandle, publicKey FROM %s", u2fDeviceHandlesTableName),
+			sqlUpgradeRenameSecondFactorMethod: fmt.Sprintf("UPDATE %s SET second_factor_method=? WHERE second_factor_method=?", userPreferencesTableName),
+
+			sqlInsertOAuth2Session:                "INSERT INTO %s (signature, request_id, client_id, subject, requested_at, expires_at, requested_scopes, granted_scopes, requested_audience, granted_audience, form_data, session_data, active) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			sqlSelectOAuth2Session:                "SELECT id, signature, request_id, client_id, subject, requested_at, expires_at, requested_scopes, granted_scopes, requested_audience, granted_audience, form_data, session_data, active FROM %s WHERE signature=?",
+			sqlDeactivateOAuth2Session:            "UPDATE %s SET active=? WHERE signature=?",
+			sqlDeactivateOAuth2SessionByRequestID: "UPDATE %s SET active=? WHERE request_id=?",
+			sqlDeleteOAuth2Session:                "DELETE FROM %s WHERE signature=?",
+			sqlDeleteOAuth2SessionByRequestID:     "DELETE FROM %s WHERE request_id=?",
+			sqlDeleteExpiredOAuth2Sessions:        "DELETE FROM %s WHERE expires_at<?",
+			sqlUpsertOAuth2BlacklistedJTI:         fmt.Sprintf("REPLACE INTO %s (signature, expires_at) VALUES (?, ?)", oauth2BlacklistedJTIsTableName),
+			sqlSelectOAuth2BlacklistedJTI:         fmt.Sprintf("SELECT id, signature, expires_at FROM %s WHERE signature=?", oauth2BlacklistedJTIsTableName),
+			sqlDeleteExpiredOAuth2BlacklistedJTIs: fmt.Sprintf("DELETE FROM %s WHERE expires_at<?", oauth2BlacklistedJTIsTableName),
+
+			sqlInsertOAuth2ConsentSession:           fmt.Sprintf("INSERT INTO %s (client_id, subject, created_at, expires_at, granted_scopes, granted_audience) VALUES (?, ?, ?, ?, ?, ?)", oauth2ConsentSessionsTableName),
+			sqlSelectOAuth2ConsentSessionsBySubject: fmt.Sprintf("SELECT id, client_id, subject, created_at, expires_at, granted_scopes, granted_audience FROM %s WHERE subject=? AND expires_at>? ORDER BY created_at", oauth2ConsentSessionsTableName),
+			sqlDeleteOAuth2ConsentSession:           fmt.Sprintf("DELETE FROM %s WHERE id=? AND subject=?", oauth2ConsentSessionsTableName),
+			sqlDeleteExpiredOAuth2ConsentSessions:   fmt.Sprintf("DELETE FROM %s WHERE expires_at<?", oauth2ConsentSessionsTableName),
+
+			sqlInsertOAuth2DeviceCodeSession:           fmt.Sprintf("INSERT INTO %s (signature, user_code_signature, client_id, subject, status, requested_at, expires_at, last_polled_at, requested_scopes, granted_scopes, requested_audience, granted_audience, session_data) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", oauth2DeviceCodeSessionsTableName),
+			sqlSelectOAuth2DeviceCodeSession:           fmt.Sprintf("SELECT id, signature, user_code_signature, client_id, subject, status, requested_at, expires_at, last_polled_at, requested_scopes, granted_scopes, requested_audience, granted_audience, session_data FROM %s WHERE signature=?", oauth2DeviceCodeSessionsTableName),
+			sqlSelectOAuth2DeviceCodeSessionByUserCode: fmt.Sprintf("SELECT id, signature, user_code_signature, client_id, subject, status, requested_at, expires_at, last_polled_at, requested_scopes, granted_scopes, requested_audience, granted_audience, session_data FROM %s WHERE user_code_signature=?", oauth2DeviceCodeSessionsTableName),
+			sqlUpdateOAuth2DeviceCodeSession:           fmt.Sprintf("UPDATE %s SET subject=?, status=?, last_polled_at=?, granted_scopes=?, granted_audience=?, session_data=? WHERE id=?", oauth2DeviceCodeSessionsTableName),
+			sqlConsumeOAuth2DeviceCodeSession:          fmt.Sprintf("UPDATE %s SET status=? WHERE signature=? AND status=?", oauth2DeviceCodeSessionsTableName),
+			sqlDeleteExpiredOAuth2DeviceCodeSessions:   fmt.Sprintf("DELETE FROM %s WHERE expires_at<?", oauth2DeviceCodeSessionsTableName),
+
+			sqlInsertOAuth2PushedAuthorizeRequest:         fmt.Sprintf("INSERT INTO %s (signature, client_id, requested_at, expires_at, form_data) VALUES (?, ?, ?, ?, ?)", oauth2PushedAuthorizeRequestsTableName),
+			sqlSelectOAuth2PushedAuthorizeRequest:         fmt.Sprintf("SELECT id, signature, client_id, requested_at, expires_at, form_data FROM %s WHERE signature=?", oauth2PushedAuthorizeRequestsTableName),
+			sqlDeleteOAuth2PushedAuthorizeRequest:         fmt.Sprintf("DELETE FROM %s WHERE signature=?", oauth2PushedAuthorizeRequestsTableName),
+			sqlDeleteExpiredOAuth2PushedAuthorizeRequests: fmt.Sprintf("DELETE FROM %s WHERE expires_at<?", oauth2PushedAuthorizeRequestsTableName),
+
+			sqlInsertOAuth2Client:       fmt.Sprintf("INSERT INTO %s (client_id, description, secret, redirect_uris, authorization_policy, scopes, audience, grant_types, response_types, response_modes, id_token_signing_algorithm, userinfo_signing_algorithm, consent_mode, pre_configured_consent_duration, post_logout_redirect_uris, frontchannel_logout_uri, backchannel_logout_uri, require_pushed_authorization_requests, require_pkce, request_object_signing_algorithm, request_uris, jwks_uri, token_endpoint_auth_method, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", oauth2ClientsTableName),
+			sqlUpdateOAuth2Client:       fmt.Sprintf("UPDATE %s SET description=?, redirect_uris=?, authorization_policy=?, scopes=?, audience=?, grant_types=?, response_types=?, response_modes=?, id_token_signing_algorithm=?, userinfo_signing_algorithm=?, consent_mode=?, pre_configured_consent_duration=?, post_logout_redirect_uris=?, frontchannel_logout_uri=?, backchannel_logout_uri=?, require_pushed_authorization_requests=?, require_pkce=?, request_object_signing_algorithm=?, request_uris=?, jwks_uri=?, token_endpoint_auth_method=?, updated_at=? WHERE client_id=?", oauth2ClientsTableName),
+			sqlUpdateOAuth2ClientSecret: fmt.Sprintf("UPDATE %s SET secret=?, updated_at=? WHERE client_id=?", oauth2ClientsTableName),
+			sqlSelectOAuth2Client:       fmt.Sprintf("SELECT id, client_id, description, secret, redirect_uris, authorization_policy, scopes, audience, grant_types, response_types, response_modes, id_token_signing_algorithm, userinfo_signing_algorithm, consent_mode, pre_configured_consent_duration, post_logout_redirect_uris, frontchannel_logout_uri, backchannel_logout_uri, require_pushed_authorization_requests, require_pkce, request_object_signing_algorithm, request_uris, jwks_uri, token_endpoint_auth_method, created_at, updated_at FROM %s WHERE client_id=?", oauth2ClientsTableName),
+			sqlSelectOAuth2Clients:      fmt.Sprintf("SELECT id, client_id, description, secret, redirect_uris, authorization_policy, scopes, audience, grant_types, response_types, response_modes, id_token_signing_algorithm, userinfo_signing_algorithm, consent_mode, pre_configured_consent_duration, post_logout_redirect_uris, frontchannel_logout_uri, backchannel_logout_uri, require_pushed_authorization_requests, require_pkce, request_object_signing_algorithm, request_uris, jwks_uri, token_endpoint_auth_method, created_at, updated_at FROM %s ORDER BY client_id", oauth2ClientsTableName),
+			sqlDeleteOAuth2Client:       fmt.Sprintf("DELETE FROM %s WHERE client_id=?", oauth2ClientsTableName),
+
+			sqlUpgradeAddOAuth2ClientLogoutColumns: []string{
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN post_logout_redirect_uris TEXT NOT NULL, ADD COLUMN frontchannel_logout_uri TEXT NOT NULL, ADD COLUMN backchannel_logout_uri TEXT NOT NULL", oauth2ClientsTableName),
+			},
+			sqlUpgradeAddOAuth2ClientAuthorizeRequestColumns: []string{
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN require_pushed_authorization_requests BOOLEAN NOT NULL DEFAULT FALSE, ADD COLUMN require_pkce BOOLEAN NOT NULL DEFAULT FALSE, ADD COLUMN request_object_signing_algorithm VARCHAR(16) NOT NULL DEFAULT '', ADD COLUMN request_uris TEXT NOT NULL, ADD COLUMN jwks_uri TEXT NOT NULL", oauth2ClientsTableName),
+			},
+			sqlUpgradeAddOAuth2ClientTokenEndpointAuthMethod: fmt.Sprintf("ALTER TABLE %s ADD COLUMN token_endpoint_auth_method VARCHAR(32) NOT NULL DEFAULT ''", oauth2ClientsTableName),
+
+			sqlInsertAuthenticationLog:                    fmt.Sprintf("INSERT INTO %s (username, successful, time, remote_ip, admin) VALUES (?, ?, ?, ?, ?)", authenticationLogsTableName),
+			sqlGetLatestAuthenticationLogs:                fmt.Sprintf("SELECT successful, time, admin FROM %s WHERE time>? AND username=? ORDER BY time DESC", authenticationLogsTableName),
+			sqlGetLatestFailedAuthenticationLogs:          fmt.Sprintf("SELECT username, successful, remote_ip, time, admin FROM %s WHERE time>? AND (successful=? OR admin IS NOT NULL) AND remote_ip IS NOT NULL ORDER BY time DESC", authenticationLogsTableName),
+			sqlGetLatestFailedAuthenticationLogsByNetwork: fmt.Sprintf("SELECT username, successful, remote_ip, time, admin FROM %s WHERE remote_ip>=? AND remote_ip<=? AND time>? AND ((successful=? AND admin IS NULL) OR (successful=? AND admin IS NOT NULL)) ORDER BY time DESC LIMIT ?", authenticationLogsTableName),
+			sqlUpgradeAddAuthenticationLogRemoteIP:        fmt.Sprintf("ALTER TABLE %s ADD COLUMN remote_ip VARCHAR(32) NULL, ADD INDEX remote_ip_time_idx (remote_ip, time)", authenticationLogsTableName),
+			sqlUpgradeAddAuthenticationLogAdmin:           fmt.Sprintf("ALTER TABLE %s ADD COLUMN admin VARCHAR(100) NULL", authenticationLogsTableName),
+
+			sqlInsertRegulationBan:  fmt.Sprintf("INSERT INTO %s (ban_type, value, reason, created_at, expires_at, revoked) VALUES (?, ?, ?, ?, ?, ?)", regulationBansTableName),
+			sqlSelectRegulationBan:  fmt.Sprintf("SELECT id, ban_type, value, reason, created_at, expires_at, revoked FROM %s WHERE ban_type=? AND value=? AND expires_at>? AND revoked=? ORDER BY expires_at DESC LIMIT 1", regulationBansTableName),
+			sqlSelectRegulationBans: fmt.Sprintf("SELECT id, ban_type, value, reason, created_at, expires_at, revoked FROM %s WHERE expires_at>? AND revoked=? ORDER BY expires_at DESC", regulationBansTableName),
+			sqlRevokeRegulationBans: fmt.Sprintf("UPDATE %s SET revoked=? WHERE ban_type=? AND value=? AND expires_at>? AND revoked=?", regulationBansTableName),
+
+			sqlSelectUser:           fmt.Sprintf("SELECT id, username, display_name, password, disabled, created_at, updated_at FROM %s WHERE username=?", usersTableName),
+			sqlSelectUserEmails:     fmt.Sprintf("SELECT email FROM %s WHERE username=? ORDER BY id", userEmailsTableName),
+			sqlSelectUserGroups:     fmt.Sprintf("SELECT group_name FROM %s WHERE username=? ORDER BY id", userGroupsTableName),
+			sqlSelectUserAttributes: fmt.Sprintf("SELECT name, value FROM %s WHERE username=? ORDER BY id", userAttributesTableName),
+			sqlUpdateUserPassword:   fmt.Sprintf("UPDATE %s SET password=?, updated_at=? WHERE username=?", usersTableName),
 
 			sqlGetExistingTables: "SELECT table_name FROM information_schema.tables WHERE table_type='BASE TABLE' AND table_schema=database()",
 
@@ -47,6 +112,23 @@ func NewMySQLProvider(configuration schema.MySQLStorageConfiguration) *MySQLProv
 	}
 
 	provider.sqlUpgradesCreateTableStatements[SchemaVersion(1)][authenticationLogsTableName] = "CREATE TABLE %s (username VARCHAR(100), successful BOOL, time INTEGER, INDEX usr_time_idx (username, time))"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(2)][webauthnDevicesTableName] = "CREATE TABLE %s (id INTEGER AUTO_INCREMENT PRIMARY KEY, created_at INTEGER NOT NULL, last_used_at INTEGER NULL, username VARCHAR(100) NOT NULL, description VARCHAR(30) NOT NULL, kid VARCHAR(512) NOT NULL, public_key TEXT NOT NULL, attestation_type VARCHAR(32), aaguid CHAR(36) NOT NULL, sign_count INTEGER DEFAULT 0, clone_warning BOOLEAN NOT NULL DEFAULT FALSE, UNIQUE (username, description), UNIQUE (kid))"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(3)][totpConfigurationsTableName] = "CREATE TABLE %s (id INTEGER AUTO_INCREMENT PRIMARY KEY, created_at INTEGER NOT NULL, last_used_at INTEGER NULL, username VARCHAR(100) NOT NULL, description VARCHAR(30) NOT NULL, secret VARCHAR(64) NOT NULL, UNIQUE (username, description))"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(4)][oauth2BlacklistedJTIsTableName] = "CREATE TABLE %s (id INTEGER AUTO_INCREMENT PRIMARY KEY, signature VARCHAR(64) NOT NULL, expires_at INTEGER NOT NULL, UNIQUE (signature))"
+
+	for _, sessionType := range oauth2SessionTypes {
+		provider.sqlUpgradesCreateTableStatements[SchemaVersion(4)][sessionType.Table()] = "CREATE TABLE %s (id INTEGER AUTO_INCREMENT PRIMARY KEY, " + sqlCreateOAuth2SessionTableColumns + ", INDEX request_id_idx (request_id))"
+	}
+
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(5)][oauth2ConsentSessionsTableName] = "CREATE TABLE %s (id INTEGER AUTO_INCREMENT PRIMARY KEY, client_id VARCHAR(255) NOT NULL, subject VARCHAR(255) NOT NULL, created_at INTEGER NOT NULL, expires_at INTEGER NOT NULL, granted_scopes TEXT NOT NULL, granted_audience TEXT NOT NULL, INDEX subject_idx (subject, client_id))"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(6)][oauth2DeviceCodeSessionsTableName] = "CREATE TABLE %s (id INTEGER AUTO_INCREMENT PRIMARY KEY, " + sqlCreateOAuth2DeviceCodeSessionTableColumns + ")"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(7)][oauth2ClientsTableName] = "CREATE TABLE %s (id INTEGER AUTO_INCREMENT PRIMARY KEY, " + sqlCreateOAuth2ClientTableColumns + ")"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(9)][oauth2PushedAuthorizeRequestsTableName] = "CREATE TABLE %s (id INTEGER AUTO_INCREMENT PRIMARY KEY, " + sqlCreateOAuth2PushedAuthorizeRequestTableColumns + ")"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(10)][regulationBansTableName] = "CREATE TABLE %s (id INTEGER AUTO_INCREMENT PRIMARY KEY, " + sqlCreateRegulationBanTableColumns + ", INDEX value_idx (ban_type, value))"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(12)][usersTableName] = "CREATE TABLE %s (id INTEGER AUTO_INCREMENT PRIMARY KEY, " + sqlCreateUserTableColumns + ")"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(12)][userEmailsTableName] = "CREATE TABLE %s (id INTEGER AUTO_INCREMENT PRIMARY KEY, " + sqlCreateUserEmailTableColumns + ")"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(12)][userGroupsTableName] = "CREATE TABLE %s (id INTEGER AUTO_INCREMENT PRIMARY KEY, " + sqlCreateUserGroupTableColumns + ")"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(12)][userAttributesTableName] = "CREATE TABLE %s (id INTEGER AUTO_INCREMENT PRIMARY KEY, " + sqlCreateUserAttributeTableColumns + ", INDEX username_idx (username, name))"
 
 	connectionString := configuration.Username
 
@@ -31,15 +31,86 @@ func NewPostgreSQLProvider(configuration schema.PostgreSQLStorageConfiguration)
 			sqlInsertIdentityVerificationToken:        fmt.Sprintf("INSERT INTO %s (token) VALUES ($1)", identityVerificationTokensTableName),
 			sqlDeleteIdentityVerificationToken:        fmt.Sprintf("DELETE FROM %s WHERE token=$1", identityVerificationTokensTableName),
 
-			sqlGetTOTPSecretByUsername: fmt.Sprintf("SELECT secret FROM %s WHERE username=$1", totpSecretsTableName),
-			sqlUpsertTOTPSecret:        fmt.Sprintf("INSERT INTO %s (username, secret) VALUES ($1, $2) ON CONFLICT (username) DO UPDATE SET secret=$2", totpSecretsTableName),
-			sqlDeleteTOTPSecret:        fmt.Sprintf("DELETE FROM %s WHERE username=$1", totpSecretsTableName),
-
-			sqlGetU2FDeviceHandleByUsername: fmt.Sprintf("SELECT keyHandle, publicKey FROM %s WHERE username=$1", u2fDeviceHandlesTableName),
-			sqlUpsertU2FDeviceHandle:        fmt.Sprintf("INSERT INTO %s (username, keyHandle, publicKey) VALUES ($1, $2, $3) ON CONFLICT (username) DO UPDATE SET keyHandle=$2, publicKey=$3", u2fDeviceHandlesTableName),
-
-			sqlInsertAuthenticationLog:     fmt.Sprintf("INSERT INTO %s (username, successful, time) VALUES ($1, $2, $3)", authenticationLogsTableName),
-			sqlGetLatestAuthenticationLogs: fmt.Sprintf("SELECT successful, time FROM %s WHERE time>$1 AND username=$2 ORDER BY time DESC", authenticationLogsTableName),
+			sqlInsertTOTPConfiguration:         fmt.Sprintf("INSERT INTO %s (created_at, last_used_at, username, description, secret) VALUES ($1, $2, $3, $4, $5)", totpConfigurationsTableName),
+			sqlUpdateTOTPConfigurationSignIn:   fmt.Sprintf("UPDATE %s SET last_used_at=$1 WHERE id=$2", totpConfigurationsTableName),
+			sqlGetTOTPConfigurationsByUsername: fmt.Sprintf("SELECT id, created_at, last_used_at, username, description, secret FROM %s WHERE username=$1", totpConfigurationsTableName),
+			sqlDeleteTOTPConfiguration:         fmt.Sprintf("DELETE FROM %s WHERE id=$1 AND username=$2", totpConfigurationsTableName),
+			sqlUpgradeGetTOTPSecrets:           fmt.Sprintf("SELECT username, secret FROM %s", totpSecretsTableName),
+
+			sqlUpsertWebauthnDevice:         fmt.Sprintf("INSERT INTO %s (created_at, last_used_at, username, description, kid, public_key, attestation_type, aaguid, sign_count, clone_warning) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT (username, description) DO UPDATE SET created_at=$1, last_used_at=$2, kid=$5, public_key=$6, attestation_type=$7, aaguid=$8, sign_count=$9, clone_warning=$10", webauthnDevicesTableName),
+			sqlUpdateWebauthnDeviceSignIn:   fmt.Sprintf("UPDATE %s SET last_used_at=$1, sign_count=$2, clone_warning=$3 WHERE id=$4", webauthnDevicesTableName),
+			sqlGetWebauthnDevicesByUsername: fmt.Sprintf("SELECT id, created_at, last_used_at, username, description, kid, public_key, attestation_type, aaguid, sign_count, clone_warning FROM %s WHERE username=$1", webauthnDevicesTableName),
+			sqlDeleteWebauthnDevice:         fmt.Sprintf("DELETE FROM %s WHERE id=$1 AND username=$2", webauthnDevicesTableName),
+
+			sqlUpgradeGetU2FDeviceHandles:      fmt.Sprintf("SELECT username, keyHandle, publicKey FROM %s", u2fDeviceHandlesTableName),
+			sqlUpgradeRenameSecondFactorMethod: fmt.Sprintf("UPDATE %s SET second_factor_method=$1 WHERE second_factor_method=$2", userPreferencesTableName),
+
+			sqlInsertOAuth2Session:                "INSERT INTO %s (signature, request_id, client_id, subject, requested_at, expires_at, requested_scopes, granted_scopes, requested_audience, granted_audience, form_data, session_data, active) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)",
+			sqlSelectOAuth2Session:                "SELECT id, signature, request_id, client_id, subject, requested_at, expires_at, requested_scopes, granted_scopes, requested_audience, granted_audience, form_data, session_data, active FROM %s WHERE signature=$1",
+			sqlDeactivateOAuth2Session:            "UPDATE %s SET active=$1 WHERE signature=$2",
+			sqlDeactivateOAuth2SessionByRequestID: "UPDATE %s SET active=$1 WHERE request_id=$2",
+			sqlDeleteOAuth2Session:                "DELETE FROM %s WHERE signature=$1",
+			sqlDeleteOAuth2SessionByRequestID:     "DELETE FROM %s WHERE request_id=$1",
+			sqlDeleteExpiredOAuth2Sessions:        "DELETE FROM %s WHERE expires_at<$1",
+			sqlUpsertOAuth2BlacklistedJTI:         fmt.Sprintf("INSERT INTO %s (signature, expires_at) VALUES ($1, $2) ON CONFLICT (signature) DO UPDATE SET expires_at=$2", oauth2BlacklistedJTIsTableName),
+			sqlSelectOAuth2BlacklistedJTI:         fmt.Sprintf("SELECT id, signature, expires_at FROM %s WHERE signature=$1", oauth2BlacklistedJTIsTableName),
+			sqlDeleteExpiredOAuth2BlacklistedJTIs: fmt.Sprintf("DELETE FROM %s WHERE expires_at<$1", oauth2BlacklistedJTIsTableName),
+
+			sqlInsertOAuth2ConsentSession:           fmt.Sprintf("INSERT INTO %s (client_id, subject, created_at, expires_at, granted_scopes, granted_audience) VALUES ($1, $2, $3, $4, $5, $6)", oauth2ConsentSessionsTableName),
+			sqlSelectOAuth2ConsentSessionsBySubject: fmt.Sprintf("SELECT id, client_id, subject, created_at, expires_at, granted_scopes, granted_audience FROM %s WHERE subject=$1 AND expires_at>$2 ORDER BY created_at", oauth2ConsentSessionsTableName),
+			sqlDeleteOAuth2ConsentSession:           fmt.Sprintf("DELETE FROM %s WHERE id=$1 AND subject=$2", oauth2ConsentSessionsTableName),
+			sqlDeleteExpiredOAuth2ConsentSessions:   fmt.Sprintf("DELETE FROM %s WHERE expires_at<$1", oauth2ConsentSessionsTableName),
+
+			sqlInsertOAuth2DeviceCodeSession:           fmt.Sprintf("INSERT INTO %s (signature, user_code_signature, client_id, subject, status, requested_at, expires_at, last_polled_at, requested_scopes, granted_scopes, requested_audience, granted_audience, session_data) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)", oauth2DeviceCodeSessionsTableName),
+			sqlSelectOAuth2DeviceCodeSession:           fmt.Sprintf("SELECT id, signature, user_code_signature, client_id, subject, status, requested_at, expires_at, last_polled_at, requested_scopes, granted_scopes, requested_audience, granted_audience, session_data FROM %s WHERE signature=$1", oauth2DeviceCodeSessionsTableName),
+			sqlSelectOAuth2DeviceCodeSessionByUserCode: fmt.Sprintf("SELECT id, signature, user_code_signature, client_id, subject, status, requested_at, expires_at, last_polled_at, requested_scopes, granted_scopes, requested_audience, granted_audience, session_data FROM %s WHERE user_code_signature=$1", oauth2DeviceCodeSessionsTableName),
+			sqlUpdateOAuth2DeviceCodeSession:           fmt.Sprintf("UPDATE %s SET subject=$1, status=$2, last_polled_at=$3, granted_scopes=$4, granted_audience=$5, session_data=$6 WHERE id=$7", oauth2DeviceCodeSessionsTableName),
+			sqlConsumeOAuth2DeviceCodeSession:          fmt.Sprintf("UPDATE %s SET status=$1 WHERE signature=$2 AND status=$3", oauth2DeviceCodeSessionsTableName),
+			sqlDeleteExpiredOAuth2DeviceCodeSessions:   fmt.Sprintf("DELETE FROM %s WHERE expires_at<$1", oauth2DeviceCodeSessionsTableName),
+
+			sqlInsertOAuth2PushedAuthorizeRequest:         fmt.Sprintf("INSERT INTO %s (signature, client_id, requested_at, expires_at, form_data) VALUES ($1, $2, $3, $4, $5)", oauth2PushedAuthorizeRequestsTableName),
+			sqlSelectOAuth2PushedAuthorizeRequest:         fmt.Sprintf("SELECT id, signature, client_id, requested_at, expires_at, form_data FROM %s WHERE signature=$1", oauth2PushedAuthorizeRequestsTableName),
+			sqlDeleteOAuth2PushedAuthorizeRequest:         fmt.Sprintf("DELETE FROM %s WHERE signature=$1", oauth2PushedAuthorizeRequestsTableName),
+			sqlDeleteExpiredOAuth2PushedAuthorizeRequests: fmt.Sprintf("DELETE FROM %s WHERE expires_at<$1", oauth2PushedAuthorizeRequestsTableName),
+
+			sqlInsertOAuth2Client:       fmt.Sprintf("INSERT INTO %s (client_id, description, secret, redirect_uris, authorization_policy, scopes, audience, grant_types, response_types, response_modes, id_token_signing_algorithm, userinfo_signing_algorithm, consent_mode, pre_configured_consent_duration, post_logout_redirect_uris, frontchannel_logout_uri, backchannel_logout_uri, require_pushed_authorization_requests, require_pkce, request_object_signing_algorithm, request_uris, jwks_uri, token_endpoint_auth_method, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25)", oauth2ClientsTableName),
+			sqlUpdateOAuth2Client:       fmt.Sprintf("UPDATE %s SET description=$1, redirect_uris=$2, authorization_policy=$3, scopes=$4, audience=$5, grant_types=$6, response_types=$7, response_modes=$8, id_token_signing_algorithm=$9, userinfo_signing_algorithm=$10, consent_mode=$11, pre_configured_consent_duration=$12, post_logout_redirect_uris=$13, frontchannel_logout_uri=$14, backchannel_logout_uri=$15, require_pushed_authorization_requests=$16, require_pkce=$17, request_object_signing_algorithm=$18, request_uris=$19, jwks_uri=$20, token_endpoint_auth_method=$21, updated_at=$22 WHERE client_id=$23", oauth2ClientsTableName),
+			sqlUpdateOAuth2ClientSecret: fmt.Sprintf("UPDATE %s SET secret=$1, updated_at=$2 WHERE client_id=$3", oauth2ClientsTableName),
+			sqlSelectOAuth2Client:       fmt.Sprintf("SELECT id, client_id, description, secret, redirect_uris, authorization_policy, scopes, audience, grant_types, response_types, response_modes, id_token_signing_algorithm, userinfo_signing_algorithm, consent_mode, pre_configured_consent_duration, post_logout_redirect_uris, frontchannel_logout_uri, backchannel_logout_uri, require_pushed_authorization_requests, require_pkce, request_object_signing_algorithm, request_uris, jwks_uri, token_endpoint_auth_method, created_at, updated_at FROM %s WHERE client_id=$1", oauth2ClientsTableName),
+			sqlSelectOAuth2Clients:      fmt.Sprintf("SELECT id, client_id, description, secret, redirect_uris, authorization_policy, scopes, audience, grant_types, response_types, response_modes, id_token_signing_algorithm, userinfo_signing_algorithm, consent_mode, pre_configured_consent_duration, post_logout_redirect_uris, frontchannel_logout_uri, backchannel_logout_uri, require_pushed_authorization_requests, require_pkce, request_object_signing_algorithm, request_uris, jwks_uri, token_endpoint_auth_method, created_at, updated_at FROM %s ORDER BY client_id", oauth2ClientsTableName),
+			sqlDeleteOAuth2Client:       fmt.Sprintf("DELETE FROM %s WHERE client_id=$1", oauth2ClientsTableName),
+
+			sqlUpgradeAddOAuth2ClientLogoutColumns: []string{
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN post_logout_redirect_uris TEXT NOT NULL DEFAULT ''", oauth2ClientsTableName),
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN frontchannel_logout_uri TEXT NOT NULL DEFAULT ''", oauth2ClientsTableName),
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN backchannel_logout_uri TEXT NOT NULL DEFAULT ''", oauth2ClientsTableName),
+			},
+			sqlUpgradeAddOAuth2ClientAuthorizeRequestColumns: []string{
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN require_pushed_authorization_requests BOOLEAN NOT NULL DEFAULT FALSE", oauth2ClientsTableName),
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN require_pkce BOOLEAN NOT NULL DEFAULT FALSE", oauth2ClientsTableName),
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN request_object_signing_algorithm VARCHAR(16) NOT NULL DEFAULT ''", oauth2ClientsTableName),
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN request_uris TEXT NOT NULL DEFAULT ''", oauth2ClientsTableName),
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN jwks_uri TEXT NOT NULL DEFAULT ''", oauth2ClientsTableName),
+			},
+			sqlUpgradeAddOAuth2ClientTokenEndpointAuthMethod: fmt.Sprintf("ALTER TABLE %s ADD COLUMN token_endpoint_auth_method VARCHAR(32) NOT NULL DEFAULT ''", oauth2ClientsTableName),
+
+			sqlInsertAuthenticationLog:                    fmt.Sprintf("INSERT INTO %s (username, successful, time, remote_ip, admin) VALUES ($1, $2, $3, $4, $5)", authenticationLogsTableName),
+			sqlGetLatestAuthenticationLogs:                fmt.Sprintf("SELECT successful, time, admin FROM %s WHERE time>$1 AND username=$2 ORDER BY time DESC", authenticationLogsTableName),
+			sqlGetLatestFailedAuthenticationLogs:          fmt.Sprintf("SELECT username, successful, remote_ip, time, admin FROM %s WHERE time>$1 AND (successful=$2 OR admin IS NOT NULL) AND remote_ip IS NOT NULL ORDER BY time DESC", authenticationLogsTableName),
+			sqlGetLatestFailedAuthenticationLogsByNetwork: fmt.Sprintf("SELECT username, successful, remote_ip, time, admin FROM %s WHERE remote_ip>=$1 AND remote_ip<=$2 AND time>$3 AND ((successful=$4 AND admin IS NULL) OR (successful=$5 AND admin IS NOT NULL)) ORDER BY time DESC LIMIT $6", authenticationLogsTableName),
+			sqlUpgradeAddAuthenticationLogRemoteIP:        fmt.Sprintf("ALTER TABLE %s ADD COLUMN remote_ip VARCHAR(32) NULL", authenticationLogsTableName),
+			sqlUpgradeAddAuthenticationLogAdmin:           fmt.Sprintf("ALTER TABLE %s ADD COLUMN admin VARCHAR(100) NULL", authenticationLogsTableName),
+
+			sqlInsertRegulationBan:  fmt.Sprintf("INSERT INTO %s (ban_type, value, reason, created_at, expires_at, revoked) VALUES ($1, $2, $3, $4, $5, $6)", regulationBansTableName),
+			sqlSelectRegulationBan:  fmt.Sprintf("SELECT id, ban_type, value, reason, created_at, expires_at, revoked FROM %s WHERE ban_type=$1 AND value=$2 AND expires_at>$3 AND revoked=$4 ORDER BY expires_at DESC LIMIT 1", regulationBansTableName),
+			sqlSelectRegulationBans: fmt.Sprintf("SELECT id, ban_type, value, reason, created_at, expires_at, revoked FROM %s WHERE expires_at>$1 AND revoked=$2 ORDER BY expires_at DESC", regulationBansTableName),
+			sqlRevokeRegulationBans: fmt.Sprintf("UPDATE %s SET revoked=$1 WHERE ban_type=$2 AND value=$3 AND expires_at>$4 AND revoked=$5", regulationBansTableName),
+
+			sqlSelectUser:           fmt.Sprintf("SELECT id, username, display_name, password, disabled, created_at, updated_at FROM %s WHERE username=$1", usersTableName),
+			sqlSelectUserEmails:     fmt.Sprintf("SELECT email FROM %s WHERE username=$1 ORDER BY id", userEmailsTableName),
+			sqlSelectUserGroups:     fmt.Sprintf("SELECT group_name FROM %s WHERE username=$1 ORDER BY id", userGroupsTableName),
+			sqlSelectUserAttributes: fmt.Sprintf("SELECT name, value FROM %s WHERE username=$1 ORDER BY id", userAttributesTableName),
+			sqlUpdateUserPassword:   fmt.Sprintf("UPDATE %s SET password=$1, updated_at=$2 WHERE username=$3", usersTableName),
 
 			sqlGetExistingTables: "SELECT table_name FROM information_schema.tables WHERE table_type='BASE TABLE' AND table_schema='public'",
 
@@ -48,6 +119,24 @@ func NewPostgreSQLProvider(configuration schema.PostgreSQLStorageConfiguration)
 		},
 	}
 
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(2)][webauthnDevicesTableName] = "CREATE TABLE %s (id SERIAL PRIMARY KEY, created_at INTEGER NOT NULL, last_used_at INTEGER NULL, username VARCHAR(100) NOT NULL, description VARCHAR(30) NOT NULL, kid VARCHAR(512) NOT NULL, public_key TEXT NOT NULL, attestation_type VARCHAR(32), aaguid CHAR(36) NOT NULL, sign_count INTEGER DEFAULT 0, clone_warning BOOLEAN NOT NULL DEFAULT FALSE, UNIQUE (username, description), UNIQUE (kid))"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(3)][totpConfigurationsTableName] = "CREATE TABLE %s (id SERIAL PRIMARY KEY, created_at INTEGER NOT NULL, last_used_at INTEGER NULL, username VARCHAR(100) NOT NULL, description VARCHAR(30) NOT NULL, secret VARCHAR(64) NOT NULL, UNIQUE (username, description))"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(4)][oauth2BlacklistedJTIsTableName] = "CREATE TABLE %s (id SERIAL PRIMARY KEY, signature VARCHAR(64) NOT NULL, expires_at INTEGER NOT NULL, UNIQUE (signature))"
+
+	for _, sessionType := range oauth2SessionTypes {
+		provider.sqlUpgradesCreateTableStatements[SchemaVersion(4)][sessionType.Table()] = "CREATE TABLE %s (id SERIAL PRIMARY KEY, " + sqlCreateOAuth2SessionTableColumns + ")"
+	}
+
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(5)][oauth2ConsentSessionsTableName] = "CREATE TABLE %s (id SERIAL PRIMARY KEY, client_id VARCHAR(255) NOT NULL, subject VARCHAR(255) NOT NULL, created_at INTEGER NOT NULL, expires_at INTEGER NOT NULL, granted_scopes TEXT NOT NULL, granted_audience TEXT NOT NULL)"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(6)][oauth2DeviceCodeSessionsTableName] = "CREATE TABLE %s (id SERIAL PRIMARY KEY, " + sqlCreateOAuth2DeviceCodeSessionTableColumns + ")"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(7)][oauth2ClientsTableName] = "CREATE TABLE %s (id SERIAL PRIMARY KEY, " + sqlCreateOAuth2ClientTableColumns + ")"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(9)][oauth2PushedAuthorizeRequestsTableName] = "CREATE TABLE %s (id SERIAL PRIMARY KEY, " + sqlCreateOAuth2PushedAuthorizeRequestTableColumns + ")"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(10)][regulationBansTableName] = "CREATE TABLE %s (id SERIAL PRIMARY KEY, " + sqlCreateRegulationBanTableColumns + ")"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(12)][usersTableName] = "CREATE TABLE %s (id SERIAL PRIMARY KEY, " + sqlCreateUserTableColumns + ")"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(12)][userEmailsTableName] = "CREATE TABLE %s (id SERIAL PRIMARY KEY, " + sqlCreateUserEmailTableColumns + ")"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(12)][userGroupsTableName] = "CREATE TABLE %s (id SERIAL PRIMARY KEY, " + sqlCreateUserGroupTableColumns + ")"
+	provider.sqlUpgradesCreateTableStatements[SchemaVersion(12)][userAttributesTableName] = "CREATE TABLE %s (id SERIAL PRIMARY KEY, " + sqlCreateUserAttributeTableColumns + ")"
+
 	args := make([]string, 0)
 	if configuration.Username != "" {
 		args = append(args, fmt.Sprintf("user='%s'", configuration.Username))
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"net"
 	"time"
 
 	"github.com/authelia/authelia/internal/models"
@@ -16,13 +17,52 @@ type Provider interface {
 	SaveIdentityVerificationToken(token string) error
 	RemoveIdentityVerificationToken(token string) error
 
-	SaveTOTPSecret(username string, secret string) error
-	LoadTOTPSecret(username string) (string, error)
-	DeleteTOTPSecret(username string) error
+	SaveTOTPConfiguration(config models.TOTPConfiguration) error
+	UpdateTOTPConfigurationSignIn(id int, lastUsedAt time.Time) error
+	LoadTOTPConfigurationsByUsername(username string) (configs []models.TOTPConfiguration, err error)
+	DeleteTOTPConfiguration(username string, id int) error
 
-	SaveU2FDeviceHandle(username string, keyHandle []byte, publicKey []byte) error
-	LoadU2FDeviceHandle(username string) (keyHandle []byte, publicKey []byte, err error)
+	SaveWebauthnDevice(device models.WebauthnDevice) error
+	UpdateWebauthnDeviceSignIn(id int, lastUsedAt time.Time, signCount uint32, cloneWarning bool) error
+	LoadWebauthnDevicesByUsername(username string) (devices []models.WebauthnDevice, err error)
+	DeleteWebauthnDevice(username string, id int) error
+
+	SaveOAuth2Session(sessionType OAuth2SessionType, session models.OAuth2Session) error
+	LoadOAuth2Session(sessionType OAuth2SessionType, signature string) (session *models.OAuth2Session, err error)
+	DeactivateOAuth2Session(sessionType OAuth2SessionType, signature string) error
+	DeactivateOAuth2SessionByRequestID(sessionType OAuth2SessionType, requestID string) error
+	RevokeOAuth2Session(sessionType OAuth2SessionType, signature string) error
+	RevokeOAuth2SessionByRequestID(sessionType OAuth2SessionType, requestID string) error
+	SaveOAuth2BlacklistedJTI(jti models.OAuth2BlacklistedJTI) error
+	LoadOAuth2BlacklistedJTI(signature string) (jti *models.OAuth2BlacklistedJTI, err error)
+	SaveOAuth2ConsentSession(consent models.OAuth2ConsentSession) error
+	LoadOAuth2ConsentSessionsBySubject(subject string, after time.Time) (consents []models.OAuth2ConsentSession, err error)
+	DeleteOAuth2ConsentSession(subject string, id int) error
+	SaveOAuth2DeviceCodeSession(session models.OAuth2DeviceCodeSession) error
+	LoadOAuth2DeviceCodeSession(signature string) (session *models.OAuth2DeviceCodeSession, err error)
+	LoadOAuth2DeviceCodeSessionByUserCode(userCodeSignature string) (session *models.OAuth2DeviceCodeSession, err error)
+	UpdateOAuth2DeviceCodeSession(session models.OAuth2DeviceCodeSession) error
+	ConsumeOAuth2DeviceCodeSession(signature string) error
+	SaveOAuth2PushedAuthorizeRequest(request models.OAuth2PushedAuthorizeRequest) error
+	ConsumeOAuth2PushedAuthorizeRequest(signature string) (request *models.OAuth2PushedAuthorizeRequest, err error)
+	PurgeExpiredOAuth2(before time.Time) error
+	SaveOAuth2Client(client models.OAuth2Client) error
+	UpdateOAuth2Client(client models.OAuth2Client) error
+	UpdateOAuth2ClientSecret(clientID, secret string, updatedAt time.Time) error
+	LoadOAuth2Client(clientID string) (client *models.OAuth2Client, err error)
+	LoadOAuth2Clients() (clients []models.OAuth2Client, err error)
+	DeleteOAuth2Client(clientID string) error
 
 	AppendAuthenticationLog(attempt models.AuthenticationAttempt) error
 	LoadLatestAuthenticationLogs(username string, fromDate time.Time) ([]models.AuthenticationAttempt, error)
+	LoadLatestFailedAuthenticationLogs(fromDate time.Time) ([]models.AuthenticationAttempt, error)
+	LoadLatestFailedAuthenticationLogsByNetwork(network *net.IPNet, fromDate time.Time, limit int) ([]models.AuthenticationAttempt, error)
+
+	SaveRegulationBan(ban models.RegulationBan) error
+	LoadRegulationBan(banType, value string, now time.Time) (ban *models.RegulationBan, err error)
+	LoadRegulationBans(now time.Time) (bans []models.RegulationBan, err error)
+	RevokeRegulationBans(banType, value string, now time.Time) (err error)
+
+	LoadUser(username string) (user *models.User, err error)
+	UpdateUserPassword(username, password string, updatedAt time.Time) (err error)
 }
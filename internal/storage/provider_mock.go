@@ -5,6 +5,7 @@
 package storage
 
 import (
+	net "net"
 	reflect "reflect"
 	time "time"
 
@@ -13,59 +14,157 @@ import (
 	models "github.com/authelia/authelia/internal/models"
 )
 
-// MockProvider is a mock of Provider interface
+// MockProvider is a mock of Provider interface.
 type MockProvider struct {
 	ctrl     *gomock.Controller
 	recorder *MockProviderMockRecorder
 }
 
-// MockProviderMockRecorder is the mock recorder for MockProvider
+// MockProviderMockRecorder is the mock recorder for MockProvider.
 type MockProviderMockRecorder struct {
 	mock *MockProvider
 }
 
-// NewMockProvider creates a new mock instance
+// NewMockProvider creates a new mock instance.
 func NewMockProvider(ctrl *gomock.Controller) *MockProvider {
 	mock := &MockProvider{ctrl: ctrl}
 	mock.recorder = &MockProviderMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockProvider) EXPECT() *MockProviderMockRecorder {
 	return m.recorder
 }
 
-// LoadPreferred2FAMethod mocks base method
-func (m *MockProvider) LoadPreferred2FAMethod(username string) (string, error) {
+// AppendAuthenticationLog mocks base method.
+func (m *MockProvider) AppendAuthenticationLog(attempt models.AuthenticationAttempt) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LoadPreferred2FAMethod", username)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "AppendAuthenticationLog", attempt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendAuthenticationLog indicates an expected call of AppendAuthenticationLog.
+func (mr *MockProviderMockRecorder) AppendAuthenticationLog(attempt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendAuthenticationLog", reflect.TypeOf((*MockProvider)(nil).AppendAuthenticationLog), attempt)
+}
+
+// ConsumeOAuth2DeviceCodeSession mocks base method.
+func (m *MockProvider) ConsumeOAuth2DeviceCodeSession(signature string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeOAuth2DeviceCodeSession", signature)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeOAuth2DeviceCodeSession indicates an expected call of ConsumeOAuth2DeviceCodeSession.
+func (mr *MockProviderMockRecorder) ConsumeOAuth2DeviceCodeSession(signature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeOAuth2DeviceCodeSession", reflect.TypeOf((*MockProvider)(nil).ConsumeOAuth2DeviceCodeSession), signature)
+}
+
+// ConsumeOAuth2PushedAuthorizeRequest mocks base method.
+func (m *MockProvider) ConsumeOAuth2PushedAuthorizeRequest(signature string) (*models.OAuth2PushedAuthorizeRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeOAuth2PushedAuthorizeRequest", signature)
+	ret0, _ := ret[0].(*models.OAuth2PushedAuthorizeRequest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// LoadPreferred2FAMethod indicates an expected call of LoadPreferred2FAMethod
-func (mr *MockProviderMockRecorder) LoadPreferred2FAMethod(username interface{}) *gomock.Call {
+// ConsumeOAuth2PushedAuthorizeRequest indicates an expected call of ConsumeOAuth2PushedAuthorizeRequest.
+func (mr *MockProviderMockRecorder) ConsumeOAuth2PushedAuthorizeRequest(signature interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadPreferred2FAMethod", reflect.TypeOf((*MockProvider)(nil).LoadPreferred2FAMethod), username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeOAuth2PushedAuthorizeRequest", reflect.TypeOf((*MockProvider)(nil).ConsumeOAuth2PushedAuthorizeRequest), signature)
 }
 
-// SavePreferred2FAMethod mocks base method
-func (m *MockProvider) SavePreferred2FAMethod(username, method string) error {
+// DeactivateOAuth2Session mocks base method.
+func (m *MockProvider) DeactivateOAuth2Session(sessionType OAuth2SessionType, signature string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SavePreferred2FAMethod", username, method)
+	ret := m.ctrl.Call(m, "DeactivateOAuth2Session", sessionType, signature)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SavePreferred2FAMethod indicates an expected call of SavePreferred2FAMethod
-func (mr *MockProviderMockRecorder) SavePreferred2FAMethod(username, method interface{}) *gomock.Call {
+// DeactivateOAuth2Session indicates an expected call of DeactivateOAuth2Session.
+func (mr *MockProviderMockRecorder) DeactivateOAuth2Session(sessionType, signature interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePreferred2FAMethod", reflect.TypeOf((*MockProvider)(nil).SavePreferred2FAMethod), username, method)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateOAuth2Session", reflect.TypeOf((*MockProvider)(nil).DeactivateOAuth2Session), sessionType, signature)
+}
+
+// DeactivateOAuth2SessionByRequestID mocks base method.
+func (m *MockProvider) DeactivateOAuth2SessionByRequestID(sessionType OAuth2SessionType, requestID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateOAuth2SessionByRequestID", sessionType, requestID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeactivateOAuth2SessionByRequestID indicates an expected call of DeactivateOAuth2SessionByRequestID.
+func (mr *MockProviderMockRecorder) DeactivateOAuth2SessionByRequestID(sessionType, requestID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateOAuth2SessionByRequestID", reflect.TypeOf((*MockProvider)(nil).DeactivateOAuth2SessionByRequestID), sessionType, requestID)
 }
 
-// FindIdentityVerificationToken mocks base method
+// DeleteOAuth2Client mocks base method.
+func (m *MockProvider) DeleteOAuth2Client(clientID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOAuth2Client", clientID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOAuth2Client indicates an expected call of DeleteOAuth2Client.
+func (mr *MockProviderMockRecorder) DeleteOAuth2Client(clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuth2Client", reflect.TypeOf((*MockProvider)(nil).DeleteOAuth2Client), clientID)
+}
+
+// DeleteOAuth2ConsentSession mocks base method.
+func (m *MockProvider) DeleteOAuth2ConsentSession(subject string, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOAuth2ConsentSession", subject, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOAuth2ConsentSession indicates an expected call of DeleteOAuth2ConsentSession.
+func (mr *MockProviderMockRecorder) DeleteOAuth2ConsentSession(subject, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuth2ConsentSession", reflect.TypeOf((*MockProvider)(nil).DeleteOAuth2ConsentSession), subject, id)
+}
+
+// DeleteTOTPConfiguration mocks base method.
+func (m *MockProvider) DeleteTOTPConfiguration(username string, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTOTPConfiguration", username, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTOTPConfiguration indicates an expected call of DeleteTOTPConfiguration.
+func (mr *MockProviderMockRecorder) DeleteTOTPConfiguration(username, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTOTPConfiguration", reflect.TypeOf((*MockProvider)(nil).DeleteTOTPConfiguration), username, id)
+}
+
+// DeleteWebauthnDevice mocks base method.
+func (m *MockProvider) DeleteWebauthnDevice(username string, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebauthnDevice", username, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebauthnDevice indicates an expected call of DeleteWebauthnDevice.
+func (mr *MockProviderMockRecorder) DeleteWebauthnDevice(username, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebauthnDevice", reflect.TypeOf((*MockProvider)(nil).DeleteWebauthnDevice), username, id)
+}
+
+// FindIdentityVerificationToken mocks base method.
 func (m *MockProvider) FindIdentityVerificationToken(token string) (bool, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "FindIdentityVerificationToken", token)
@@ -74,27 +173,267 @@ func (m *MockProvider) FindIdentityVerificationToken(token string) (bool, error)
 	return ret0, ret1
 }
 
-// FindIdentityVerificationToken indicates an expected call of FindIdentityVerificationToken
+// FindIdentityVerificationToken indicates an expected call of FindIdentityVerificationToken.
 func (mr *MockProviderMockRecorder) FindIdentityVerificationToken(token interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindIdentityVerificationToken", reflect.TypeOf((*MockProvider)(nil).FindIdentityVerificationToken), token)
 }
 
-// SaveIdentityVerificationToken mocks base method
-func (m *MockProvider) SaveIdentityVerificationToken(token string) error {
+// LoadLatestAuthenticationLogs mocks base method.
+func (m *MockProvider) LoadLatestAuthenticationLogs(username string, fromDate time.Time) ([]models.AuthenticationAttempt, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveIdentityVerificationToken", token)
+	ret := m.ctrl.Call(m, "LoadLatestAuthenticationLogs", username, fromDate)
+	ret0, _ := ret[0].([]models.AuthenticationAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadLatestAuthenticationLogs indicates an expected call of LoadLatestAuthenticationLogs.
+func (mr *MockProviderMockRecorder) LoadLatestAuthenticationLogs(username, fromDate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadLatestAuthenticationLogs", reflect.TypeOf((*MockProvider)(nil).LoadLatestAuthenticationLogs), username, fromDate)
+}
+
+// LoadLatestFailedAuthenticationLogs mocks base method.
+func (m *MockProvider) LoadLatestFailedAuthenticationLogs(fromDate time.Time) ([]models.AuthenticationAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadLatestFailedAuthenticationLogs", fromDate)
+	ret0, _ := ret[0].([]models.AuthenticationAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadLatestFailedAuthenticationLogs indicates an expected call of LoadLatestFailedAuthenticationLogs.
+func (mr *MockProviderMockRecorder) LoadLatestFailedAuthenticationLogs(fromDate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadLatestFailedAuthenticationLogs", reflect.TypeOf((*MockProvider)(nil).LoadLatestFailedAuthenticationLogs), fromDate)
+}
+
+// LoadLatestFailedAuthenticationLogsByNetwork mocks base method.
+func (m *MockProvider) LoadLatestFailedAuthenticationLogsByNetwork(network *net.IPNet, fromDate time.Time, limit int) ([]models.AuthenticationAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadLatestFailedAuthenticationLogsByNetwork", network, fromDate, limit)
+	ret0, _ := ret[0].([]models.AuthenticationAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadLatestFailedAuthenticationLogsByNetwork indicates an expected call of LoadLatestFailedAuthenticationLogsByNetwork.
+func (mr *MockProviderMockRecorder) LoadLatestFailedAuthenticationLogsByNetwork(network, fromDate, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadLatestFailedAuthenticationLogsByNetwork", reflect.TypeOf((*MockProvider)(nil).LoadLatestFailedAuthenticationLogsByNetwork), network, fromDate, limit)
+}
+
+// LoadOAuth2BlacklistedJTI mocks base method.
+func (m *MockProvider) LoadOAuth2BlacklistedJTI(signature string) (*models.OAuth2BlacklistedJTI, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadOAuth2BlacklistedJTI", signature)
+	ret0, _ := ret[0].(*models.OAuth2BlacklistedJTI)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadOAuth2BlacklistedJTI indicates an expected call of LoadOAuth2BlacklistedJTI.
+func (mr *MockProviderMockRecorder) LoadOAuth2BlacklistedJTI(signature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadOAuth2BlacklistedJTI", reflect.TypeOf((*MockProvider)(nil).LoadOAuth2BlacklistedJTI), signature)
+}
+
+// LoadOAuth2Client mocks base method.
+func (m *MockProvider) LoadOAuth2Client(clientID string) (*models.OAuth2Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadOAuth2Client", clientID)
+	ret0, _ := ret[0].(*models.OAuth2Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadOAuth2Client indicates an expected call of LoadOAuth2Client.
+func (mr *MockProviderMockRecorder) LoadOAuth2Client(clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadOAuth2Client", reflect.TypeOf((*MockProvider)(nil).LoadOAuth2Client), clientID)
+}
+
+// LoadOAuth2Clients mocks base method.
+func (m *MockProvider) LoadOAuth2Clients() ([]models.OAuth2Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadOAuth2Clients")
+	ret0, _ := ret[0].([]models.OAuth2Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadOAuth2Clients indicates an expected call of LoadOAuth2Clients.
+func (mr *MockProviderMockRecorder) LoadOAuth2Clients() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadOAuth2Clients", reflect.TypeOf((*MockProvider)(nil).LoadOAuth2Clients))
+}
+
+// LoadOAuth2ConsentSessionsBySubject mocks base method.
+func (m *MockProvider) LoadOAuth2ConsentSessionsBySubject(subject string, after time.Time) ([]models.OAuth2ConsentSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadOAuth2ConsentSessionsBySubject", subject, after)
+	ret0, _ := ret[0].([]models.OAuth2ConsentSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadOAuth2ConsentSessionsBySubject indicates an expected call of LoadOAuth2ConsentSessionsBySubject.
+func (mr *MockProviderMockRecorder) LoadOAuth2ConsentSessionsBySubject(subject, after interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadOAuth2ConsentSessionsBySubject", reflect.TypeOf((*MockProvider)(nil).LoadOAuth2ConsentSessionsBySubject), subject, after)
+}
+
+// LoadOAuth2DeviceCodeSession mocks base method.
+func (m *MockProvider) LoadOAuth2DeviceCodeSession(signature string) (*models.OAuth2DeviceCodeSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadOAuth2DeviceCodeSession", signature)
+	ret0, _ := ret[0].(*models.OAuth2DeviceCodeSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadOAuth2DeviceCodeSession indicates an expected call of LoadOAuth2DeviceCodeSession.
+func (mr *MockProviderMockRecorder) LoadOAuth2DeviceCodeSession(signature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadOAuth2DeviceCodeSession", reflect.TypeOf((*MockProvider)(nil).LoadOAuth2DeviceCodeSession), signature)
+}
+
+// LoadOAuth2DeviceCodeSessionByUserCode mocks base method.
+func (m *MockProvider) LoadOAuth2DeviceCodeSessionByUserCode(userCodeSignature string) (*models.OAuth2DeviceCodeSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadOAuth2DeviceCodeSessionByUserCode", userCodeSignature)
+	ret0, _ := ret[0].(*models.OAuth2DeviceCodeSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadOAuth2DeviceCodeSessionByUserCode indicates an expected call of LoadOAuth2DeviceCodeSessionByUserCode.
+func (mr *MockProviderMockRecorder) LoadOAuth2DeviceCodeSessionByUserCode(userCodeSignature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadOAuth2DeviceCodeSessionByUserCode", reflect.TypeOf((*MockProvider)(nil).LoadOAuth2DeviceCodeSessionByUserCode), userCodeSignature)
+}
+
+// LoadOAuth2Session mocks base method.
+func (m *MockProvider) LoadOAuth2Session(sessionType OAuth2SessionType, signature string) (*models.OAuth2Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadOAuth2Session", sessionType, signature)
+	ret0, _ := ret[0].(*models.OAuth2Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadOAuth2Session indicates an expected call of LoadOAuth2Session.
+func (mr *MockProviderMockRecorder) LoadOAuth2Session(sessionType, signature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadOAuth2Session", reflect.TypeOf((*MockProvider)(nil).LoadOAuth2Session), sessionType, signature)
+}
+
+// LoadPreferred2FAMethod mocks base method.
+func (m *MockProvider) LoadPreferred2FAMethod(username string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadPreferred2FAMethod", username)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadPreferred2FAMethod indicates an expected call of LoadPreferred2FAMethod.
+func (mr *MockProviderMockRecorder) LoadPreferred2FAMethod(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadPreferred2FAMethod", reflect.TypeOf((*MockProvider)(nil).LoadPreferred2FAMethod), username)
+}
+
+// LoadRegulationBan mocks base method.
+func (m *MockProvider) LoadRegulationBan(banType, value string, now time.Time) (*models.RegulationBan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadRegulationBan", banType, value, now)
+	ret0, _ := ret[0].(*models.RegulationBan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadRegulationBan indicates an expected call of LoadRegulationBan.
+func (mr *MockProviderMockRecorder) LoadRegulationBan(banType, value, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadRegulationBan", reflect.TypeOf((*MockProvider)(nil).LoadRegulationBan), banType, value, now)
+}
+
+// LoadRegulationBans mocks base method.
+func (m *MockProvider) LoadRegulationBans(now time.Time) ([]models.RegulationBan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadRegulationBans", now)
+	ret0, _ := ret[0].([]models.RegulationBan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadRegulationBans indicates an expected call of LoadRegulationBans.
+func (mr *MockProviderMockRecorder) LoadRegulationBans(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadRegulationBans", reflect.TypeOf((*MockProvider)(nil).LoadRegulationBans), now)
+}
+
+// LoadTOTPConfigurationsByUsername mocks base method.
+func (m *MockProvider) LoadTOTPConfigurationsByUsername(username string) ([]models.TOTPConfiguration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadTOTPConfigurationsByUsername", username)
+	ret0, _ := ret[0].([]models.TOTPConfiguration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadTOTPConfigurationsByUsername indicates an expected call of LoadTOTPConfigurationsByUsername.
+func (mr *MockProviderMockRecorder) LoadTOTPConfigurationsByUsername(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadTOTPConfigurationsByUsername", reflect.TypeOf((*MockProvider)(nil).LoadTOTPConfigurationsByUsername), username)
+}
+
+// LoadUser mocks base method.
+func (m *MockProvider) LoadUser(username string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadUser", username)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadUser indicates an expected call of LoadUser.
+func (mr *MockProviderMockRecorder) LoadUser(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadUser", reflect.TypeOf((*MockProvider)(nil).LoadUser), username)
+}
+
+// LoadWebauthnDevicesByUsername mocks base method.
+func (m *MockProvider) LoadWebauthnDevicesByUsername(username string) ([]models.WebauthnDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadWebauthnDevicesByUsername", username)
+	ret0, _ := ret[0].([]models.WebauthnDevice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadWebauthnDevicesByUsername indicates an expected call of LoadWebauthnDevicesByUsername.
+func (mr *MockProviderMockRecorder) LoadWebauthnDevicesByUsername(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadWebauthnDevicesByUsername", reflect.TypeOf((*MockProvider)(nil).LoadWebauthnDevicesByUsername), username)
+}
+
+// PurgeExpiredOAuth2 mocks base method.
+func (m *MockProvider) PurgeExpiredOAuth2(before time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpiredOAuth2", before)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SaveIdentityVerificationToken indicates an expected call of SaveIdentityVerificationToken
-func (mr *MockProviderMockRecorder) SaveIdentityVerificationToken(token interface{}) *gomock.Call {
+// PurgeExpiredOAuth2 indicates an expected call of PurgeExpiredOAuth2.
+func (mr *MockProviderMockRecorder) PurgeExpiredOAuth2(before interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveIdentityVerificationToken", reflect.TypeOf((*MockProvider)(nil).SaveIdentityVerificationToken), token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpiredOAuth2", reflect.TypeOf((*MockProvider)(nil).PurgeExpiredOAuth2), before)
 }
 
-// RemoveIdentityVerificationToken mocks base method
+// RemoveIdentityVerificationToken mocks base method.
 func (m *MockProvider) RemoveIdentityVerificationToken(token string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "RemoveIdentityVerificationToken", token)
@@ -102,110 +441,288 @@ func (m *MockProvider) RemoveIdentityVerificationToken(token string) error {
 	return ret0
 }
 
-// RemoveIdentityVerificationToken indicates an expected call of RemoveIdentityVerificationToken
+// RemoveIdentityVerificationToken indicates an expected call of RemoveIdentityVerificationToken.
 func (mr *MockProviderMockRecorder) RemoveIdentityVerificationToken(token interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveIdentityVerificationToken", reflect.TypeOf((*MockProvider)(nil).RemoveIdentityVerificationToken), token)
 }
 
-// SaveTOTPSecret mocks base method
-func (m *MockProvider) SaveTOTPSecret(username, secret string) error {
+// RevokeOAuth2Session mocks base method.
+func (m *MockProvider) RevokeOAuth2Session(sessionType OAuth2SessionType, signature string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveTOTPSecret", username, secret)
+	ret := m.ctrl.Call(m, "RevokeOAuth2Session", sessionType, signature)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SaveTOTPSecret indicates an expected call of SaveTOTPSecret
-func (mr *MockProviderMockRecorder) SaveTOTPSecret(username, secret interface{}) *gomock.Call {
+// RevokeOAuth2Session indicates an expected call of RevokeOAuth2Session.
+func (mr *MockProviderMockRecorder) RevokeOAuth2Session(sessionType, signature interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTOTPSecret", reflect.TypeOf((*MockProvider)(nil).SaveTOTPSecret), username, secret)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOAuth2Session", reflect.TypeOf((*MockProvider)(nil).RevokeOAuth2Session), sessionType, signature)
 }
 
-// LoadTOTPSecret mocks base method
-func (m *MockProvider) LoadTOTPSecret(username string) (string, error) {
+// RevokeOAuth2SessionByRequestID mocks base method.
+func (m *MockProvider) RevokeOAuth2SessionByRequestID(sessionType OAuth2SessionType, requestID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LoadTOTPSecret", username)
-	ret0, _ := ret[0].(string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "RevokeOAuth2SessionByRequestID", sessionType, requestID)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// LoadTOTPSecret indicates an expected call of LoadTOTPSecret
-func (mr *MockProviderMockRecorder) LoadTOTPSecret(username interface{}) *gomock.Call {
+// RevokeOAuth2SessionByRequestID indicates an expected call of RevokeOAuth2SessionByRequestID.
+func (mr *MockProviderMockRecorder) RevokeOAuth2SessionByRequestID(sessionType, requestID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadTOTPSecret", reflect.TypeOf((*MockProvider)(nil).LoadTOTPSecret), username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOAuth2SessionByRequestID", reflect.TypeOf((*MockProvider)(nil).RevokeOAuth2SessionByRequestID), sessionType, requestID)
 }
 
-// DeleteTOTPSecret mocks base method
-func (m *MockProvider) DeleteTOTPSecret(username string) error {
+// RevokeRegulationBans mocks base method.
+func (m *MockProvider) RevokeRegulationBans(banType, value string, now time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteTOTPSecret", username)
+	ret := m.ctrl.Call(m, "RevokeRegulationBans", banType, value, now)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteTOTPSecret indicates an expected call of DeleteTOTPSecret
-func (mr *MockProviderMockRecorder) DeleteTOTPSecret(username interface{}) *gomock.Call {
+// RevokeRegulationBans indicates an expected call of RevokeRegulationBans.
+func (mr *MockProviderMockRecorder) RevokeRegulationBans(banType, value, now interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTOTPSecret", reflect.TypeOf((*MockProvider)(nil).DeleteTOTPSecret), username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRegulationBans", reflect.TypeOf((*MockProvider)(nil).RevokeRegulationBans), banType, value, now)
 }
 
-// SaveU2FDeviceHandle mocks base method
-func (m *MockProvider) SaveU2FDeviceHandle(username string, keyHandle, publicKey []byte) error {
+// SaveIdentityVerificationToken mocks base method.
+func (m *MockProvider) SaveIdentityVerificationToken(token string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveU2FDeviceHandle", username, keyHandle, publicKey)
+	ret := m.ctrl.Call(m, "SaveIdentityVerificationToken", token)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SaveU2FDeviceHandle indicates an expected call of SaveU2FDeviceHandle
-func (mr *MockProviderMockRecorder) SaveU2FDeviceHandle(username, keyHandle, publicKey interface{}) *gomock.Call {
+// SaveIdentityVerificationToken indicates an expected call of SaveIdentityVerificationToken.
+func (mr *MockProviderMockRecorder) SaveIdentityVerificationToken(token interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveU2FDeviceHandle", reflect.TypeOf((*MockProvider)(nil).SaveU2FDeviceHandle), username, keyHandle, publicKey)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveIdentityVerificationToken", reflect.TypeOf((*MockProvider)(nil).SaveIdentityVerificationToken), token)
 }
 
-// LoadU2FDeviceHandle mocks base method
-func (m *MockProvider) LoadU2FDeviceHandle(username string) ([]byte, []byte, error) {
+// SaveOAuth2BlacklistedJTI mocks base method.
+func (m *MockProvider) SaveOAuth2BlacklistedJTI(jti models.OAuth2BlacklistedJTI) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LoadU2FDeviceHandle", username)
-	ret0, _ := ret[0].([]byte)
-	ret1, _ := ret[1].([]byte)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret := m.ctrl.Call(m, "SaveOAuth2BlacklistedJTI", jti)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// LoadU2FDeviceHandle indicates an expected call of LoadU2FDeviceHandle
-func (mr *MockProviderMockRecorder) LoadU2FDeviceHandle(username interface{}) *gomock.Call {
+// SaveOAuth2BlacklistedJTI indicates an expected call of SaveOAuth2BlacklistedJTI.
+func (mr *MockProviderMockRecorder) SaveOAuth2BlacklistedJTI(jti interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadU2FDeviceHandle", reflect.TypeOf((*MockProvider)(nil).LoadU2FDeviceHandle), username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOAuth2BlacklistedJTI", reflect.TypeOf((*MockProvider)(nil).SaveOAuth2BlacklistedJTI), jti)
 }
 
-// AppendAuthenticationLog mocks base method
-func (m *MockProvider) AppendAuthenticationLog(attempt models.AuthenticationAttempt) error {
+// SaveOAuth2Client mocks base method.
+func (m *MockProvider) SaveOAuth2Client(client models.OAuth2Client) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AppendAuthenticationLog", attempt)
+	ret := m.ctrl.Call(m, "SaveOAuth2Client", client)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// AppendAuthenticationLog indicates an expected call of AppendAuthenticationLog
-func (mr *MockProviderMockRecorder) AppendAuthenticationLog(attempt interface{}) *gomock.Call {
+// SaveOAuth2Client indicates an expected call of SaveOAuth2Client.
+func (mr *MockProviderMockRecorder) SaveOAuth2Client(client interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendAuthenticationLog", reflect.TypeOf((*MockProvider)(nil).AppendAuthenticationLog), attempt)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOAuth2Client", reflect.TypeOf((*MockProvider)(nil).SaveOAuth2Client), client)
 }
 
-// LoadLatestAuthenticationLogs mocks base method
-func (m *MockProvider) LoadLatestAuthenticationLogs(username string, fromDate time.Time) ([]models.AuthenticationAttempt, error) {
+// SaveOAuth2ConsentSession mocks base method.
+func (m *MockProvider) SaveOAuth2ConsentSession(consent models.OAuth2ConsentSession) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LoadLatestAuthenticationLogs", username, fromDate)
-	ret0, _ := ret[0].([]models.AuthenticationAttempt)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "SaveOAuth2ConsentSession", consent)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// LoadLatestAuthenticationLogs indicates an expected call of LoadLatestAuthenticationLogs
-func (mr *MockProviderMockRecorder) LoadLatestAuthenticationLogs(username, fromDate interface{}) *gomock.Call {
+// SaveOAuth2ConsentSession indicates an expected call of SaveOAuth2ConsentSession.
+func (mr *MockProviderMockRecorder) SaveOAuth2ConsentSession(consent interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadLatestAuthenticationLogs", reflect.TypeOf((*MockProvider)(nil).LoadLatestAuthenticationLogs), username, fromDate)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOAuth2ConsentSession", reflect.TypeOf((*MockProvider)(nil).SaveOAuth2ConsentSession), consent)
+}
+
+// SaveOAuth2DeviceCodeSession mocks base method.
+func (m *MockProvider) SaveOAuth2DeviceCodeSession(session models.OAuth2DeviceCodeSession) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOAuth2DeviceCodeSession", session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOAuth2DeviceCodeSession indicates an expected call of SaveOAuth2DeviceCodeSession.
+func (mr *MockProviderMockRecorder) SaveOAuth2DeviceCodeSession(session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOAuth2DeviceCodeSession", reflect.TypeOf((*MockProvider)(nil).SaveOAuth2DeviceCodeSession), session)
+}
+
+// SaveOAuth2PushedAuthorizeRequest mocks base method.
+func (m *MockProvider) SaveOAuth2PushedAuthorizeRequest(request models.OAuth2PushedAuthorizeRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOAuth2PushedAuthorizeRequest", request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOAuth2PushedAuthorizeRequest indicates an expected call of SaveOAuth2PushedAuthorizeRequest.
+func (mr *MockProviderMockRecorder) SaveOAuth2PushedAuthorizeRequest(request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOAuth2PushedAuthorizeRequest", reflect.TypeOf((*MockProvider)(nil).SaveOAuth2PushedAuthorizeRequest), request)
+}
+
+// SaveOAuth2Session mocks base method.
+func (m *MockProvider) SaveOAuth2Session(sessionType OAuth2SessionType, session models.OAuth2Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOAuth2Session", sessionType, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOAuth2Session indicates an expected call of SaveOAuth2Session.
+func (mr *MockProviderMockRecorder) SaveOAuth2Session(sessionType, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOAuth2Session", reflect.TypeOf((*MockProvider)(nil).SaveOAuth2Session), sessionType, session)
+}
+
+// SavePreferred2FAMethod mocks base method.
+func (m *MockProvider) SavePreferred2FAMethod(username, method string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePreferred2FAMethod", username, method)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePreferred2FAMethod indicates an expected call of SavePreferred2FAMethod.
+func (mr *MockProviderMockRecorder) SavePreferred2FAMethod(username, method interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePreferred2FAMethod", reflect.TypeOf((*MockProvider)(nil).SavePreferred2FAMethod), username, method)
+}
+
+// SaveRegulationBan mocks base method.
+func (m *MockProvider) SaveRegulationBan(ban models.RegulationBan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRegulationBan", ban)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRegulationBan indicates an expected call of SaveRegulationBan.
+func (mr *MockProviderMockRecorder) SaveRegulationBan(ban interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRegulationBan", reflect.TypeOf((*MockProvider)(nil).SaveRegulationBan), ban)
+}
+
+// SaveTOTPConfiguration mocks base method.
+func (m *MockProvider) SaveTOTPConfiguration(config models.TOTPConfiguration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTOTPConfiguration", config)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTOTPConfiguration indicates an expected call of SaveTOTPConfiguration.
+func (mr *MockProviderMockRecorder) SaveTOTPConfiguration(config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTOTPConfiguration", reflect.TypeOf((*MockProvider)(nil).SaveTOTPConfiguration), config)
+}
+
+// SaveWebauthnDevice mocks base method.
+func (m *MockProvider) SaveWebauthnDevice(device models.WebauthnDevice) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebauthnDevice", device)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebauthnDevice indicates an expected call of SaveWebauthnDevice.
+func (mr *MockProviderMockRecorder) SaveWebauthnDevice(device interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebauthnDevice", reflect.TypeOf((*MockProvider)(nil).SaveWebauthnDevice), device)
+}
+
+// UpdateOAuth2Client mocks base method.
+func (m *MockProvider) UpdateOAuth2Client(client models.OAuth2Client) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOAuth2Client", client)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOAuth2Client indicates an expected call of UpdateOAuth2Client.
+func (mr *MockProviderMockRecorder) UpdateOAuth2Client(client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOAuth2Client", reflect.TypeOf((*MockProvider)(nil).UpdateOAuth2Client), client)
+}
+
+// UpdateOAuth2ClientSecret mocks base method.
+func (m *MockProvider) UpdateOAuth2ClientSecret(clientID, secret string, updatedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOAuth2ClientSecret", clientID, secret, updatedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOAuth2ClientSecret indicates an expected call of UpdateOAuth2ClientSecret.
+func (mr *MockProviderMockRecorder) UpdateOAuth2ClientSecret(clientID, secret, updatedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOAuth2ClientSecret", reflect.TypeOf((*MockProvider)(nil).UpdateOAuth2ClientSecret), clientID, secret, updatedAt)
+}
+
+// UpdateOAuth2DeviceCodeSession mocks base method.
+func (m *MockProvider) UpdateOAuth2DeviceCodeSession(session models.OAuth2DeviceCodeSession) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOAuth2DeviceCodeSession", session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOAuth2DeviceCodeSession indicates an expected call of UpdateOAuth2DeviceCodeSession.
+func (mr *MockProviderMockRecorder) UpdateOAuth2DeviceCodeSession(session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOAuth2DeviceCodeSession", reflect.TypeOf((*MockProvider)(nil).UpdateOAuth2DeviceCodeSession), session)
+}
+
+// UpdateTOTPConfigurationSignIn mocks base method.
+func (m *MockProvider) UpdateTOTPConfigurationSignIn(id int, lastUsedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTOTPConfigurationSignIn", id, lastUsedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTOTPConfigurationSignIn indicates an expected call of UpdateTOTPConfigurationSignIn.
+func (mr *MockProviderMockRecorder) UpdateTOTPConfigurationSignIn(id, lastUsedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTOTPConfigurationSignIn", reflect.TypeOf((*MockProvider)(nil).UpdateTOTPConfigurationSignIn), id, lastUsedAt)
+}
+
+// UpdateUserPassword mocks base method.
+func (m *MockProvider) UpdateUserPassword(username, password string, updatedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPassword", username, password, updatedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserPassword indicates an expected call of UpdateUserPassword.
+func (mr *MockProviderMockRecorder) UpdateUserPassword(username, password, updatedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockProvider)(nil).UpdateUserPassword), username, password, updatedAt)
+}
+
+// UpdateWebauthnDeviceSignIn mocks base method.
+func (m *MockProvider) UpdateWebauthnDeviceSignIn(id int, lastUsedAt time.Time, signCount uint32, cloneWarning bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebauthnDeviceSignIn", id, lastUsedAt, signCount, cloneWarning)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebauthnDeviceSignIn indicates an expected call of UpdateWebauthnDeviceSignIn.
+func (mr *MockProviderMockRecorder) UpdateWebauthnDeviceSignIn(id, lastUsedAt, signCount, cloneWarning interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebauthnDeviceSignIn", reflect.TypeOf((*MockProvider)(nil).UpdateWebauthnDeviceSignIn), id, lastUsedAt, signCount, cloneWarning)
 }
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"encoding/base64"
 	"fmt"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -29,15 +31,77 @@ type SQLProvider struct {
 	sqlInsertIdentityVerificationToken        string
 	sqlDeleteIdentityVerificationToken        string
 
-	sqlGetTOTPSecretByUsername string
-	sqlUpsertTOTPSecret        string
-	sqlDeleteTOTPSecret        string
-
-	sqlGetU2FDeviceHandleByUsername string
-	sqlUpsertU2FDeviceHandle        string
-
-	sqlInsertAuthenticationLog     string
-	sqlGetLatestAuthenticationLogs string
+	sqlInsertTOTPConfiguration         string
+	sqlUpdateTOTPConfigurationSignIn   string
+	sqlGetTOTPConfigurationsByUsername string
+	sqlDeleteTOTPConfiguration         string
+	sqlUpgradeGetTOTPSecrets           string
+
+	sqlUpsertWebauthnDevice            string
+	sqlUpdateWebauthnDeviceSignIn      string
+	sqlGetWebauthnDevicesByUsername    string
+	sqlDeleteWebauthnDevice            string
+	sqlUpgradeGetU2FDeviceHandles      string
+	sqlUpgradeRenameSecondFactorMethod string
+
+	// The OAuth 2.0 session statements are fmt.Sprintf'd with the table name of the OAuth2SessionType.
+	sqlInsertOAuth2Session                string
+	sqlSelectOAuth2Session                string
+	sqlDeactivateOAuth2Session            string
+	sqlDeactivateOAuth2SessionByRequestID string
+	sqlDeleteOAuth2Session                string
+	sqlDeleteOAuth2SessionByRequestID     string
+	sqlDeleteExpiredOAuth2Sessions        string
+
+	sqlUpsertOAuth2BlacklistedJTI         string
+	sqlSelectOAuth2BlacklistedJTI         string
+	sqlDeleteExpiredOAuth2BlacklistedJTIs string
+
+	sqlInsertOAuth2ConsentSession           string
+	sqlSelectOAuth2ConsentSessionsBySubject string
+	sqlDeleteOAuth2ConsentSession           string
+	sqlDeleteExpiredOAuth2ConsentSessions   string
+
+	sqlInsertOAuth2DeviceCodeSession           string
+	sqlSelectOAuth2DeviceCodeSession           string
+	sqlSelectOAuth2DeviceCodeSessionByUserCode string
+	sqlUpdateOAuth2DeviceCodeSession           string
+	sqlConsumeOAuth2DeviceCodeSession          string
+	sqlDeleteExpiredOAuth2DeviceCodeSessions   string
+
+	sqlInsertOAuth2PushedAuthorizeRequest         string
+	sqlSelectOAuth2PushedAuthorizeRequest         string
+	sqlDeleteOAuth2PushedAuthorizeRequest         string
+	sqlDeleteExpiredOAuth2PushedAuthorizeRequests string
+
+	sqlInsertOAuth2Client       string
+	sqlUpdateOAuth2Client       string
+	sqlUpdateOAuth2ClientSecret string
+	sqlSelectOAuth2Client       string
+	sqlSelectOAuth2Clients      string
+	sqlDeleteOAuth2Client       string
+
+	sqlUpgradeAddOAuth2ClientLogoutColumns           []string
+	sqlUpgradeAddOAuth2ClientAuthorizeRequestColumns []string
+	sqlUpgradeAddOAuth2ClientTokenEndpointAuthMethod string
+
+	sqlInsertAuthenticationLog                    string
+	sqlGetLatestAuthenticationLogs                string
+	sqlGetLatestFailedAuthenticationLogs          string
+	sqlGetLatestFailedAuthenticationLogsByNetwork string
+	sqlUpgradeAddAuthenticationLogRemoteIP        string
+	sqlUpgradeAddAuthenticationLogAdmin           string
+
+	sqlInsertRegulationBan  string
+	sqlSelectRegulationBan  string
+	sqlSelectRegulationBans string
+	sqlRevokeRegulationBans string
+
+	sqlSelectUser           string
+	sqlSelectUserEmails     string
+	sqlSelectUserGroups     string
+	sqlSelectUserAttributes string
+	sqlUpdateUserPassword   string
 
 	sqlGetExistingTables string
 
@@ -111,6 +175,90 @@ func (p *SQLProvider) upgrade() error {
 				return p.handleUpgradeFailure(tx, 1, err)
 			}
 
+			fallthrough
+		case 1:
+			err := p.upgradeSchemaToVersion002(tx, tables)
+			if err != nil {
+				return p.handleUpgradeFailure(tx, 2, err)
+			}
+
+			fallthrough
+		case 2:
+			err := p.upgradeSchemaToVersion003(tx, tables)
+			if err != nil {
+				return p.handleUpgradeFailure(tx, 3, err)
+			}
+
+			fallthrough
+		case 3:
+			err := p.upgradeSchemaToVersion004(tx, tables)
+			if err != nil {
+				return p.handleUpgradeFailure(tx, 4, err)
+			}
+
+			fallthrough
+		case 4:
+			err := p.upgradeSchemaToVersion005(tx, tables)
+			if err != nil {
+				return p.handleUpgradeFailure(tx, 5, err)
+			}
+
+			fallthrough
+		case 5:
+			err := p.upgradeSchemaToVersion006(tx, tables)
+			if err != nil {
+				return p.handleUpgradeFailure(tx, 6, err)
+			}
+
+			fallthrough
+		case 6:
+			err := p.upgradeSchemaToVersion007(tx, tables)
+			if err != nil {
+				return p.handleUpgradeFailure(tx, 7, err)
+			}
+
+			fallthrough
+		case 7:
+			err := p.upgradeSchemaToVersion008(tx)
+			if err != nil {
+				return p.handleUpgradeFailure(tx, 8, err)
+			}
+
+			fallthrough
+		case 8:
+			err := p.upgradeSchemaToVersion009(tx, tables)
+			if err != nil {
+				return p.handleUpgradeFailure(tx, 9, err)
+			}
+
+			fallthrough
+		case 9:
+			err := p.upgradeSchemaToVersion010(tx, tables)
+			if err != nil {
+				return p.handleUpgradeFailure(tx, 10, err)
+			}
+
+			fallthrough
+		case 10:
+			err := p.upgradeSchemaToVersion011(tx)
+			if err != nil {
+				return p.handleUpgradeFailure(tx, 11, err)
+			}
+
+			fallthrough
+		case 11:
+			err := p.upgradeSchemaToVersion012(tx, tables)
+			if err != nil {
+				return p.handleUpgradeFailure(tx, 12, err)
+			}
+
+			fallthrough
+		case 12:
+			err := p.upgradeSchemaToVersion013(tx)
+			if err != nil {
+				return p.handleUpgradeFailure(tx, 13, err)
+			}
+
 			fallthrough
 		default:
 			err := tx.Commit()
@@ -187,71 +335,562 @@ func (p *SQLProvider) RemoveIdentityVerificationToken(token string) error {
 	return err
 }
 
-// SaveTOTPSecret save a TOTP secret of a given user in the database.
-func (p *SQLProvider) SaveTOTPSecret(username string, secret string) error {
-	_, err := p.db.Exec(p.sqlUpsertTOTPSecret, username, secret)
+// SaveTOTPConfiguration saves a new TOTP configuration of a given user in the database.
+func (p *SQLProvider) SaveTOTPConfiguration(config models.TOTPConfiguration) error {
+	_, err := p.db.Exec(p.sqlInsertTOTPConfiguration,
+		config.CreatedAt.Unix(),
+		nullUnixTime(config.LastUsedAt),
+		config.Username,
+		config.Description,
+		config.Secret)
+
+	return err
+}
+
+// UpdateTOTPConfigurationSignIn updates the sign in information of a TOTP configuration.
+func (p *SQLProvider) UpdateTOTPConfigurationSignIn(id int, lastUsedAt time.Time) error {
+	_, err := p.db.Exec(p.sqlUpdateTOTPConfigurationSignIn, lastUsedAt.Unix(), id)
+	return err
+}
+
+// LoadTOTPConfigurationsByUsername loads all of the TOTP configurations registered for a given username.
+func (p *SQLProvider) LoadTOTPConfigurationsByUsername(username string) ([]models.TOTPConfiguration, error) {
+	rows, err := p.db.Query(p.sqlGetTOTPConfigurationsByUsername, username)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	configs := make([]models.TOTPConfiguration, 0, 1)
+
+	for rows.Next() {
+		config, err := scanTOTPConfiguration(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		configs = append(configs, config)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(configs) == 0 {
+		return nil, ErrNoTOTPConfiguration
+	}
+
+	return configs, nil
+}
+
+// DeleteTOTPConfiguration deletes a TOTP configuration of a given user from the database.
+func (p *SQLProvider) DeleteTOTPConfiguration(username string, id int) error {
+	return p.deleteSecondFactorDevice(p.sqlDeleteTOTPConfiguration, username, id, ErrNoTOTPConfiguration)
+}
+
+// SaveWebauthnDevice saves a registered Webauthn device.
+func (p *SQLProvider) SaveWebauthnDevice(device models.WebauthnDevice) error {
+	_, err := p.db.Exec(p.sqlUpsertWebauthnDevice,
+		device.CreatedAt.Unix(),
+		nullUnixTime(device.LastUsedAt),
+		device.Username,
+		device.Description,
+		base64.StdEncoding.EncodeToString(device.KID),
+		base64.StdEncoding.EncodeToString(device.PublicKey),
+		device.AttestationType,
+		device.AAGUID.String(),
+		device.SignCount,
+		device.CloneWarning)
+
+	return err
+}
+
+// UpdateWebauthnDeviceSignIn updates the sign in information of a Webauthn device.
+func (p *SQLProvider) UpdateWebauthnDeviceSignIn(id int, lastUsedAt time.Time, signCount uint32, cloneWarning bool) error {
+	_, err := p.db.Exec(p.sqlUpdateWebauthnDeviceSignIn, lastUsedAt.Unix(), signCount, cloneWarning, id)
 	return err
 }
 
-// LoadTOTPSecret load a TOTP secret given a username from the database.
-func (p *SQLProvider) LoadTOTPSecret(username string) (string, error) {
-	var secret string
-	if err := p.db.QueryRow(p.sqlGetTOTPSecretByUsername, username).Scan(&secret); err != nil {
+// LoadWebauthnDevicesByUsername loads all of the Webauthn devices registered for a given username.
+func (p *SQLProvider) LoadWebauthnDevicesByUsername(username string) ([]models.WebauthnDevice, error) {
+	rows, err := p.db.Query(p.sqlGetWebauthnDevicesByUsername, username)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	devices := make([]models.WebauthnDevice, 0, 1)
+
+	for rows.Next() {
+		device, err := scanWebauthnDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		devices = append(devices, device)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(devices) == 0 {
+		return nil, ErrNoWebauthnDevice
+	}
+
+	return devices, nil
+}
+
+// DeleteWebauthnDevice deletes a Webauthn device of a given user from the database.
+func (p *SQLProvider) DeleteWebauthnDevice(username string, id int) error {
+	return p.deleteSecondFactorDevice(p.sqlDeleteWebauthnDevice, username, id, ErrNoWebauthnDevice)
+}
+
+func (p *SQLProvider) deleteSecondFactorDevice(query, username string, id int, errNotFound error) error {
+	result, err := p.db.Exec(query, id, username)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return errNotFound
+	}
+
+	return nil
+}
+
+// SaveOAuth2Session saves an OAuth 2.0 session of the given type in the database.
+func (p *SQLProvider) SaveOAuth2Session(sessionType OAuth2SessionType, session models.OAuth2Session) error {
+	_, err := p.db.Exec(fmt.Sprintf(p.sqlInsertOAuth2Session, sessionType.Table()),
+		session.Signature,
+		session.RequestID,
+		session.ClientID,
+		session.Subject,
+		session.RequestedAt.Unix(),
+		nullUnixTime(session.ExpiresAt),
+		strings.Join(session.RequestedScopes, " "),
+		strings.Join(session.GrantedScopes, " "),
+		strings.Join(session.RequestedAudience, " "),
+		strings.Join(session.GrantedAudience, " "),
+		session.Form,
+		string(session.Session),
+		session.Active)
+
+	return err
+}
+
+// LoadOAuth2Session loads an OAuth 2.0 session of the given type from the database.
+func (p *SQLProvider) LoadOAuth2Session(sessionType OAuth2SessionType, signature string) (*models.OAuth2Session, error) {
+	session, err := scanOAuth2Session(p.db.QueryRow(fmt.Sprintf(p.sqlSelectOAuth2Session, sessionType.Table()), signature))
+	if err != nil {
 		if err == sql.ErrNoRows {
-			return "", ErrNoTOTPSecret
+			return nil, ErrNoOAuth2Session
 		}
 
-		return "", err
+		return nil, err
 	}
 
-	return secret, nil
+	return &session, nil
+}
+
+// DeactivateOAuth2Session marks an OAuth 2.0 session of the given type as inactive in the database.
+func (p *SQLProvider) DeactivateOAuth2Session(sessionType OAuth2SessionType, signature string) error {
+	_, err := p.db.Exec(fmt.Sprintf(p.sqlDeactivateOAuth2Session, sessionType.Table()), false, signature)
+	return err
+}
+
+// DeactivateOAuth2SessionByRequestID marks all OAuth 2.0 sessions of the given type with a request id as inactive in
+// the database.
+func (p *SQLProvider) DeactivateOAuth2SessionByRequestID(sessionType OAuth2SessionType, requestID string) error {
+	_, err := p.db.Exec(fmt.Sprintf(p.sqlDeactivateOAuth2SessionByRequestID, sessionType.Table()), false, requestID)
+	return err
 }
 
-// DeleteTOTPSecret delete a TOTP secret from the database given a username.
-func (p *SQLProvider) DeleteTOTPSecret(username string) error {
-	_, err := p.db.Exec(p.sqlDeleteTOTPSecret, username)
+// RevokeOAuth2Session deletes an OAuth 2.0 session of the given type from the database.
+func (p *SQLProvider) RevokeOAuth2Session(sessionType OAuth2SessionType, signature string) error {
+	_, err := p.db.Exec(fmt.Sprintf(p.sqlDeleteOAuth2Session, sessionType.Table()), signature)
 	return err
 }
 
-// SaveU2FDeviceHandle save a registered U2F device registration blob.
-func (p *SQLProvider) SaveU2FDeviceHandle(username string, keyHandle []byte, publicKey []byte) error {
-	_, err := p.db.Exec(p.sqlUpsertU2FDeviceHandle,
-		username,
-		base64.StdEncoding.EncodeToString(keyHandle),
-		base64.StdEncoding.EncodeToString(publicKey))
+// RevokeOAuth2SessionByRequestID deletes all OAuth 2.0 sessions of the given type with a request id from the database.
+func (p *SQLProvider) RevokeOAuth2SessionByRequestID(sessionType OAuth2SessionType, requestID string) error {
+	_, err := p.db.Exec(fmt.Sprintf(p.sqlDeleteOAuth2SessionByRequestID, sessionType.Table()), requestID)
+	return err
+}
 
+// SaveOAuth2BlacklistedJTI saves a blacklisted JWT ID in the database.
+func (p *SQLProvider) SaveOAuth2BlacklistedJTI(jti models.OAuth2BlacklistedJTI) error {
+	_, err := p.db.Exec(p.sqlUpsertOAuth2BlacklistedJTI, jti.Signature, jti.ExpiresAt.Unix())
 	return err
 }
 
-// LoadU2FDeviceHandle load a U2F device registration blob for a given username.
-func (p *SQLProvider) LoadU2FDeviceHandle(username string) ([]byte, []byte, error) {
-	var keyHandleBase64, publicKeyBase64 string
-	if err := p.db.QueryRow(p.sqlGetU2FDeviceHandleByUsername, username).Scan(&keyHandleBase64, &publicKeyBase64); err != nil {
+// LoadOAuth2BlacklistedJTI loads a blacklisted JWT ID from the database.
+func (p *SQLProvider) LoadOAuth2BlacklistedJTI(signature string) (*models.OAuth2BlacklistedJTI, error) {
+	var (
+		jti       models.OAuth2BlacklistedJTI
+		expiresAt int64
+	)
+
+	err := p.db.QueryRow(p.sqlSelectOAuth2BlacklistedJTI, signature).Scan(&jti.ID, &jti.Signature, &expiresAt)
+	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil, ErrNoU2FDeviceHandle
+			return nil, ErrNoOAuth2BlacklistedJTI
 		}
 
-		return nil, nil, err
+		return nil, err
 	}
 
-	keyHandle, err := base64.StdEncoding.DecodeString(keyHandleBase64)
+	jti.ExpiresAt = time.Unix(expiresAt, 0)
+
+	return &jti, nil
+}
+
+// SaveOAuth2ConsentSession saves the consent a subject has granted to a client in the database.
+func (p *SQLProvider) SaveOAuth2ConsentSession(consent models.OAuth2ConsentSession) error {
+	_, err := p.db.Exec(p.sqlInsertOAuth2ConsentSession,
+		consent.ClientID,
+		consent.Subject,
+		consent.CreatedAt.Unix(),
+		consent.ExpiresAt.Unix(),
+		strings.Join(consent.GrantedScopes, " "),
+		strings.Join(consent.GrantedAudience, " "))
+
+	return err
+}
 
+// LoadOAuth2ConsentSessionsBySubject loads all of the consent sessions of a given subject which expire after the
+// given time.
+func (p *SQLProvider) LoadOAuth2ConsentSessionsBySubject(subject string, after time.Time) ([]models.OAuth2ConsentSession, error) {
+	rows, err := p.db.Query(p.sqlSelectOAuth2ConsentSessionsBySubject, subject, after.Unix())
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	consents := make([]models.OAuth2ConsentSession, 0, 1)
+
+	for rows.Next() {
+		consent, err := scanOAuth2ConsentSession(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		consents = append(consents, consent)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(consents) == 0 {
+		return nil, ErrNoOAuth2ConsentSession
 	}
 
-	publicKey, err := base64.StdEncoding.DecodeString(publicKeyBase64)
+	return consents, nil
+}
+
+// DeleteOAuth2ConsentSession deletes a consent session of a given subject from the database.
+func (p *SQLProvider) DeleteOAuth2ConsentSession(subject string, id int) error {
+	result, err := p.db.Exec(p.sqlDeleteOAuth2ConsentSession, id, subject)
+	if err != nil {
+		return err
+	}
 
+	affected, err := result.RowsAffected()
 	if err != nil {
-		return nil, nil, err
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNoOAuth2ConsentSession
 	}
 
-	return keyHandle, publicKey, nil
+	return nil
+}
+
+// SaveOAuth2DeviceCodeSession saves a new device authorization request in the database.
+func (p *SQLProvider) SaveOAuth2DeviceCodeSession(session models.OAuth2DeviceCodeSession) error {
+	_, err := p.db.Exec(p.sqlInsertOAuth2DeviceCodeSession,
+		session.Signature,
+		session.UserCodeSignature,
+		session.ClientID,
+		session.Subject,
+		session.Status,
+		session.RequestedAt.Unix(),
+		session.ExpiresAt.Unix(),
+		nullUnixTime(session.LastPolledAt),
+		strings.Join(session.RequestedScopes, " "),
+		strings.Join(session.GrantedScopes, " "),
+		strings.Join(session.RequestedAudience, " "),
+		strings.Join(session.GrantedAudience, " "),
+		string(session.Session))
+
+	return err
+}
+
+// LoadOAuth2DeviceCodeSession loads a device authorization request by the signature of its device code from the
+// database.
+func (p *SQLProvider) LoadOAuth2DeviceCodeSession(signature string) (*models.OAuth2DeviceCodeSession, error) {
+	return p.loadOAuth2DeviceCodeSession(p.sqlSelectOAuth2DeviceCodeSession, signature)
+}
+
+// LoadOAuth2DeviceCodeSessionByUserCode loads a device authorization request by the signature of its user code from
+// the database.
+func (p *SQLProvider) LoadOAuth2DeviceCodeSessionByUserCode(userCodeSignature string) (*models.OAuth2DeviceCodeSession, error) {
+	return p.loadOAuth2DeviceCodeSession(p.sqlSelectOAuth2DeviceCodeSessionByUserCode, userCodeSignature)
+}
+
+func (p *SQLProvider) loadOAuth2DeviceCodeSession(query, signature string) (*models.OAuth2DeviceCodeSession, error) {
+	session, err := scanOAuth2DeviceCodeSession(p.db.QueryRow(query, signature))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNoOAuth2DeviceCodeSession
+		}
+
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// UpdateOAuth2DeviceCodeSession updates the subject, status, last poll time, grants and session of a device
+// authorization request in the database.
+func (p *SQLProvider) UpdateOAuth2DeviceCodeSession(session models.OAuth2DeviceCodeSession) error {
+	_, err := p.db.Exec(p.sqlUpdateOAuth2DeviceCodeSession,
+		session.Subject,
+		session.Status,
+		nullUnixTime(session.LastPolledAt),
+		strings.Join(session.GrantedScopes, " "),
+		strings.Join(session.GrantedAudience, " "),
+		string(session.Session),
+		session.ID)
+
+	return err
+}
+
+// ConsumeOAuth2DeviceCodeSession marks an approved device authorization request as consumed in the database. The
+// status is only changed for the caller which finds the request approved so the device code can be used only once.
+func (p *SQLProvider) ConsumeOAuth2DeviceCodeSession(signature string) error {
+	result, err := p.db.Exec(p.sqlConsumeOAuth2DeviceCodeSession,
+		models.OAuth2DeviceCodeStatusConsumed,
+		signature,
+		models.OAuth2DeviceCodeStatusApproved)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	// The device code has been consumed by another caller in the meantime.
+	if affected == 0 {
+		return ErrNoOAuth2DeviceCodeSession
+	}
+
+	return nil
+}
+
+// SaveOAuth2PushedAuthorizeRequest saves a new pushed authorization request in the database.
+func (p *SQLProvider) SaveOAuth2PushedAuthorizeRequest(request models.OAuth2PushedAuthorizeRequest) error {
+	_, err := p.db.Exec(p.sqlInsertOAuth2PushedAuthorizeRequest,
+		request.Signature,
+		request.ClientID,
+		request.RequestedAt.Unix(),
+		request.ExpiresAt.Unix(),
+		request.Form)
+
+	return err
+}
+
+// ConsumeOAuth2PushedAuthorizeRequest loads and deletes a pushed authorization request by the signature of its request
+// URI from the database. The request is only returned to the caller which deleted it so it can be used only once.
+func (p *SQLProvider) ConsumeOAuth2PushedAuthorizeRequest(signature string) (*models.OAuth2PushedAuthorizeRequest, error) {
+	var (
+		request                models.OAuth2PushedAuthorizeRequest
+		requestedAt, expiresAt int64
+	)
+
+	err := p.db.QueryRow(p.sqlSelectOAuth2PushedAuthorizeRequest, signature).Scan(&request.ID, &request.Signature,
+		&request.ClientID, &requestedAt, &expiresAt, &request.Form)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNoOAuth2PushedAuthorizeRequest
+		}
+
+		return nil, err
+	}
+
+	result, err := p.db.Exec(p.sqlDeleteOAuth2PushedAuthorizeRequest, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	// The request has been consumed by another caller in the meantime.
+	if affected == 0 {
+		return nil, ErrNoOAuth2PushedAuthorizeRequest
+	}
+
+	request.RequestedAt = time.Unix(requestedAt, 0)
+	request.ExpiresAt = time.Unix(expiresAt, 0)
+
+	return &request, nil
+}
+
+// SaveOAuth2Client saves a new OpenID Connect client in the database.
+func (p *SQLProvider) SaveOAuth2Client(client models.OAuth2Client) error {
+	_, err := p.db.Exec(p.sqlInsertOAuth2Client,
+		client.ClientID,
+		client.Description,
+		client.Secret,
+		strings.Join(client.RedirectURIs, " "),
+		client.Policy,
+		strings.Join(client.Scopes, " "),
+		strings.Join(client.Audience, " "),
+		strings.Join(client.GrantTypes, " "),
+		strings.Join(client.ResponseTypes, " "),
+		strings.Join(client.ResponseModes, " "),
+		client.IDTokenSigningAlgorithm,
+		client.UserinfoSigningAlgorithm,
+		client.ConsentMode,
+		int64(client.PreConfiguredConsentDuration/time.Second),
+		strings.Join(client.PostLogoutRedirectURIs, " "),
+		client.FrontChannelLogoutURI,
+		client.BackChannelLogoutURI,
+		client.RequirePushedAuthorizationRequests,
+		client.RequirePKCE,
+		client.RequestObjectSigningAlgorithm,
+		strings.Join(client.RequestURIs, " "),
+		client.JWKSURI,
+		client.TokenEndpointAuthMethod,
+		client.CreatedAt.Unix(),
+		client.UpdatedAt.Unix())
+
+	return err
+}
+
+// UpdateOAuth2Client updates the metadata of an OpenID Connect client in the database. The secret is left untouched.
+func (p *SQLProvider) UpdateOAuth2Client(client models.OAuth2Client) error {
+	result, err := p.db.Exec(p.sqlUpdateOAuth2Client,
+		client.Description,
+		strings.Join(client.RedirectURIs, " "),
+		client.Policy,
+		strings.Join(client.Scopes, " "),
+		strings.Join(client.Audience, " "),
+		strings.Join(client.GrantTypes, " "),
+		strings.Join(client.ResponseTypes, " "),
+		strings.Join(client.ResponseModes, " "),
+		client.IDTokenSigningAlgorithm,
+		client.UserinfoSigningAlgorithm,
+		client.ConsentMode,
+		int64(client.PreConfiguredConsentDuration/time.Second),
+		strings.Join(client.PostLogoutRedirectURIs, " "),
+		client.FrontChannelLogoutURI,
+		client.BackChannelLogoutURI,
+		client.RequirePushedAuthorizationRequests,
+		client.RequirePKCE,
+		client.RequestObjectSigningAlgorithm,
+		strings.Join(client.RequestURIs, " "),
+		client.JWKSURI,
+		client.TokenEndpointAuthMethod,
+		client.UpdatedAt.Unix(),
+		client.ClientID)
+
+	return checkOAuth2ClientAffected(result, err)
+}
+
+// UpdateOAuth2ClientSecret replaces the hashed secret of an OpenID Connect client in the database.
+func (p *SQLProvider) UpdateOAuth2ClientSecret(clientID, secret string, updatedAt time.Time) error {
+	return checkOAuth2ClientAffected(p.db.Exec(p.sqlUpdateOAuth2ClientSecret, secret, updatedAt.Unix(), clientID))
+}
+
+// LoadOAuth2Client loads an OpenID Connect client by its client id from the database.
+func (p *SQLProvider) LoadOAuth2Client(clientID string) (*models.OAuth2Client, error) {
+	client, err := scanOAuth2Client(p.db.QueryRow(p.sqlSelectOAuth2Client, clientID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNoOAuth2Client
+		}
+
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+// LoadOAuth2Clients loads every OpenID Connect client from the database.
+func (p *SQLProvider) LoadOAuth2Clients() ([]models.OAuth2Client, error) {
+	rows, err := p.db.Query(p.sqlSelectOAuth2Clients)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	clients := make([]models.OAuth2Client, 0)
+
+	for rows.Next() {
+		client, err := scanOAuth2Client(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		clients = append(clients, client)
+	}
+
+	return clients, rows.Err()
+}
+
+// DeleteOAuth2Client deletes an OpenID Connect client by its client id from the database.
+func (p *SQLProvider) DeleteOAuth2Client(clientID string) error {
+	return checkOAuth2ClientAffected(p.db.Exec(p.sqlDeleteOAuth2Client, clientID))
+}
+
+// PurgeExpiredOAuth2 deletes every OAuth 2.0 session, blacklisted JWT ID, consent session, device code session and
+// pushed authorization request which expired before the given time from the database.
+func (p *SQLProvider) PurgeExpiredOAuth2(before time.Time) error {
+	for _, sessionType := range oauth2SessionTypes {
+		if _, err := p.db.Exec(fmt.Sprintf(p.sqlDeleteExpiredOAuth2Sessions, sessionType.Table()), before.Unix()); err != nil {
+			return fmt.Errorf("unable to purge expired %s sessions: %w", sessionType, err)
+		}
+	}
+
+	if _, err := p.db.Exec(p.sqlDeleteExpiredOAuth2BlacklistedJTIs, before.Unix()); err != nil {
+		return fmt.Errorf("unable to purge expired blacklisted JTIs: %w", err)
+	}
+
+	if _, err := p.db.Exec(p.sqlDeleteExpiredOAuth2ConsentSessions, before.Unix()); err != nil {
+		return fmt.Errorf("unable to purge expired consent sessions: %w", err)
+	}
+
+	if _, err := p.db.Exec(p.sqlDeleteExpiredOAuth2DeviceCodeSessions, before.Unix()); err != nil {
+		return fmt.Errorf("unable to purge expired device code sessions: %w", err)
+	}
+
+	if _, err := p.db.Exec(p.sqlDeleteExpiredOAuth2PushedAuthorizeRequests, before.Unix()); err != nil {
+		return fmt.Errorf("unable to purge expired pushed authorization requests: %w", err)
+	}
+
+	return nil
 }
 
 // AppendAuthenticationLog append a mark to the authentication log.
 func (p *SQLProvider) AppendAuthenticationLog(attempt models.AuthenticationAttempt) error {
-	_, err := p.db.Exec(p.sqlInsertAuthenticationLog, attempt.Username, attempt.Successful, attempt.Time.Unix())
+	_, err := p.db.Exec(p.sqlInsertAuthenticationLog, attempt.Username, attempt.Successful, attempt.Time.Unix(), nullIP(attempt.RemoteIP), nullString(attempt.Admin))
 	return err
 }
 
@@ -268,11 +907,14 @@ func (p *SQLProvider) LoadLatestAuthenticationLogs(username string, fromDate tim
 	attempts := make([]models.AuthenticationAttempt, 0, 10)
 
 	for rows.Next() {
+		var admin sql.NullString
+
 		attempt := models.AuthenticationAttempt{
 			Username: username,
 		}
-		err = rows.Scan(&attempt.Successful, &t)
+		err = rows.Scan(&attempt.Successful, &t, &admin)
 		attempt.Time = time.Unix(t, 0)
+		attempt.Admin = admin.String
 
 		if err != nil {
 			return nil, err
@@ -283,3 +925,162 @@ func (p *SQLProvider) LoadLatestAuthenticationLogs(username string, fromDate tim
 
 	return attempts, nil
 }
+
+// LoadLatestFailedAuthenticationLogs retrieve the latest failed marks of every user from the authentication log along
+// with the marks recorded by administrators, only the marks with a remote IP are retrieved.
+func (p *SQLProvider) LoadLatestFailedAuthenticationLogs(fromDate time.Time) ([]models.AuthenticationAttempt, error) {
+	rows, err := p.db.Query(p.sqlGetLatestFailedAuthenticationLogs, fromDate.Unix(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAuthenticationAttemptsWithRemoteIP(rows)
+}
+
+// LoadLatestFailedAuthenticationLogsByNetwork retrieve at most limit of the latest failed marks made from the IP
+// addresses of a network along with the unbans of these IP addresses recorded by administrators.
+func (p *SQLProvider) LoadLatestFailedAuthenticationLogsByNetwork(network *net.IPNet, fromDate time.Time, limit int) ([]models.AuthenticationAttempt, error) {
+	first, last := ipRange(network)
+
+	rows, err := p.db.Query(p.sqlGetLatestFailedAuthenticationLogsByNetwork, first, last, fromDate.Unix(), false, true, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAuthenticationAttemptsWithRemoteIP(rows)
+}
+
+// SaveRegulationBan save a ban of the regulation.
+func (p *SQLProvider) SaveRegulationBan(ban models.RegulationBan) error {
+	_, err := p.db.Exec(p.sqlInsertRegulationBan, ban.Type, ban.Value, ban.Reason, ban.CreatedAt.Unix(), ban.ExpiresAt.Unix(), ban.Revoked)
+
+	return err
+}
+
+// LoadRegulationBan loads the active ban of the regulation with the given type and value which expires last.
+func (p *SQLProvider) LoadRegulationBan(banType, value string, now time.Time) (*models.RegulationBan, error) {
+	ban, err := scanRegulationBan(p.db.QueryRow(p.sqlSelectRegulationBan, banType, value, now.Unix(), false))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNoRegulationBan
+		}
+
+		return nil, err
+	}
+
+	return &ban, nil
+}
+
+// LoadRegulationBans loads the active bans of the regulation, the bans which expire last come first.
+func (p *SQLProvider) LoadRegulationBans(now time.Time) ([]models.RegulationBan, error) {
+	rows, err := p.db.Query(p.sqlSelectRegulationBans, now.Unix(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	bans := make([]models.RegulationBan, 0, 10)
+
+	for rows.Next() {
+		ban, err := scanRegulationBan(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		bans = append(bans, ban)
+	}
+
+	return bans, rows.Err()
+}
+
+// RevokeRegulationBans revokes the active bans of the regulation with the given type and value.
+func (p *SQLProvider) RevokeRegulationBans(banType, value string, now time.Time) error {
+	_, err := p.db.Exec(p.sqlRevokeRegulationBans, true, banType, value, now.Unix(), false)
+
+	return err
+}
+
+// LoadUser loads a user of the SQL authentication backend with their emails, groups and attributes.
+func (p *SQLProvider) LoadUser(username string) (*models.User, error) {
+	user, err := scanUser(p.db.QueryRow(p.sqlSelectUser, username))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNoUser
+		}
+
+		return nil, err
+	}
+
+	if user.Emails, err = p.loadUserValues(p.sqlSelectUserEmails, username); err != nil {
+		return nil, err
+	}
+
+	if user.Groups, err = p.loadUserValues(p.sqlSelectUserGroups, username); err != nil {
+		return nil, err
+	}
+
+	rows, err := p.db.Query(p.sqlSelectUserAttributes, username)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	user.Attributes = map[string][]string{}
+
+	var name, value string
+
+	for rows.Next() {
+		if err = rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+
+		user.Attributes[name] = append(user.Attributes[name], value)
+	}
+
+	return &user, rows.Err()
+}
+
+// UpdateUserPassword replaces the password hash of a user of the SQL authentication backend.
+func (p *SQLProvider) UpdateUserPassword(username, password string, updatedAt time.Time) error {
+	result, err := p.db.Exec(p.sqlUpdateUserPassword, password, updatedAt.Unix(), username)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNoUser
+	}
+
+	return nil
+}
+
+func (p *SQLProvider) loadUserValues(query, username string) ([]string, error) {
+	rows, err := p.db.Query(query, username)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var (
+		values []string
+		value  string
+	)
+
+	for rows.Next() {
+		if err = rows.Scan(&value); err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+	}
+
+	return values, rows.Err()
+}
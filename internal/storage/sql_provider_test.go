@@ -4,19 +4,20 @@ import (
 	"database/sql/driver"
 	"encoding/base64"
 	"fmt"
+	"net"
 	"sort"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
-	"github.com/authelia/authelia/internal/authentication"
 	"github.com/authelia/authelia/internal/models"
 )
 
-const currentSchemaMockSchemaVersion = "1"
+const currentSchemaMockSchemaVersion = "13"
 
 func TestSQLInitializeDatabase(t *testing.T) {
 	provider, mock := NewSQLMockProvider()
@@ -50,6 +51,19 @@ func TestSQLInitializeDatabase(t *testing.T) {
 		WithArgs("schema", "version", "1").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
+	expectSchemaUpgradeToVersion002(mock, sqlmock.NewRows([]string{"username", "keyHandle", "publicKey"}), 0)
+	expectSchemaUpgradeToVersion003(mock, sqlmock.NewRows([]string{"username", "secret"}), 0)
+	expectSchemaUpgradeToVersion004(mock)
+	expectSchemaUpgradeToVersion005(mock)
+	expectSchemaUpgradeToVersion006(mock)
+	expectSchemaUpgradeToVersion007(mock)
+	expectSchemaUpgradeToVersion008(mock)
+	expectSchemaUpgradeToVersion009(mock)
+	expectSchemaUpgradeToVersion010(mock)
+	expectSchemaUpgradeToVersion011(mock)
+	expectSchemaUpgradeToVersion012(mock)
+	expectSchemaUpgradeToVersion013(mock)
+
 	mock.ExpectCommit()
 
 	err := provider.initialize(provider.db)
@@ -83,6 +97,19 @@ func TestSQLUpgradeDatabase(t *testing.T) {
 		WithArgs("schema", "version", "1").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
+	expectSchemaUpgradeToVersion002(mock, sqlmock.NewRows([]string{"username", "keyHandle", "publicKey"}), 0)
+	expectSchemaUpgradeToVersion003(mock, sqlmock.NewRows([]string{"username", "secret"}), 0)
+	expectSchemaUpgradeToVersion004(mock)
+	expectSchemaUpgradeToVersion005(mock)
+	expectSchemaUpgradeToVersion006(mock)
+	expectSchemaUpgradeToVersion007(mock)
+	expectSchemaUpgradeToVersion008(mock)
+	expectSchemaUpgradeToVersion009(mock)
+	expectSchemaUpgradeToVersion010(mock)
+	expectSchemaUpgradeToVersion011(mock)
+	expectSchemaUpgradeToVersion012(mock)
+	expectSchemaUpgradeToVersion013(mock)
+
 	mock.ExpectCommit()
 
 	err := provider.initialize(provider.db)
@@ -97,8 +124,8 @@ func TestSQLProviderMethodsAuthenticationLogs(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"name"}).
 			AddRow(userPreferencesTableName).
 			AddRow(identityVerificationTokensTableName).
-			AddRow(totpSecretsTableName).
-			AddRow(u2fDeviceHandlesTableName).
+			AddRow(totpConfigurationsTableName).
+			AddRow(webauthnDevicesTableName).
 			AddRow(authenticationLogsTableName).
 			AddRow(configTableName))
 
@@ -107,41 +134,42 @@ func TestSQLProviderMethodsAuthenticationLogs(t *testing.T) {
 		fmt.Sprintf("SELECT value FROM %s WHERE category=\\? AND key_name=\\?", configTableName)).
 		WithArgs(args...).
 		WillReturnRows(sqlmock.NewRows([]string{"value"}).
-			AddRow("1"))
+			AddRow(currentSchemaMockSchemaVersion))
 
 	err := provider.initialize(provider.db)
 	assert.NoError(t, err)
 
 	attempts := []models.AuthenticationAttempt{
 		{Username: unitTestUser, Successful: true, Time: time.Unix(1577880001, 0)},
-		{Username: unitTestUser, Successful: true, Time: time.Unix(1577880002, 0)},
-		{Username: unitTestUser, Successful: false, Time: time.Unix(1577880003, 0)},
+		{Username: unitTestUser, Successful: true, Time: time.Unix(1577880002, 0), RemoteIP: net.ParseIP("192.168.1.10")},
+		{Username: unitTestUser, Successful: false, Time: time.Unix(1577880003, 0), RemoteIP: net.ParseIP("192.168.1.10")},
+		{Username: unitTestUser, Successful: true, Time: time.Unix(1577880004, 0), Admin: "harry"},
 	}
 
-	rows := sqlmock.NewRows([]string{"successful", "time"})
+	rows := sqlmock.NewRows([]string{"successful", "time", "admin"})
 
 	for id, attempt := range attempts {
-		args = []driver.Value{attempt.Username, attempt.Successful, attempt.Time.Unix()}
+		args = []driver.Value{attempt.Username, attempt.Successful, attempt.Time.Unix(), nullIP(attempt.RemoteIP), nullString(attempt.Admin)}
 		mock.ExpectExec(
-			fmt.Sprintf("INSERT INTO %s \\(username, successful, time\\) VALUES \\(\\?, \\?, \\?\\)", authenticationLogsTableName)).
+			fmt.Sprintf("INSERT INTO %s \\(username, successful, time, remote_ip, admin\\) VALUES \\(\\?, \\?, \\?, \\?, \\?\\)", authenticationLogsTableName)).
 			WithArgs(args...).
 			WillReturnResult(sqlmock.NewResult(int64(id), 1))
 
 		err := provider.AppendAuthenticationLog(attempt)
 		assert.NoError(t, err)
-		rows.AddRow(attempt.Successful, attempt.Time.Unix())
+		rows.AddRow(attempt.Successful, attempt.Time.Unix(), nullString(attempt.Admin))
 	}
 
 	args = []driver.Value{1577880000, unitTestUser}
 	mock.ExpectQuery(
-		fmt.Sprintf("SELECT successful, time FROM %s WHERE time>\\? AND username=\\? ORDER BY time DESC", authenticationLogsTableName)).
+		fmt.Sprintf("SELECT successful, time, admin FROM %s WHERE time>\\? AND username=\\? ORDER BY time DESC", authenticationLogsTableName)).
 		WithArgs(args...).
 		WillReturnRows(rows)
 
 	after := time.Unix(1577880000, 0)
 	results, err := provider.LoadLatestAuthenticationLogs(unitTestUser, after)
 	assert.NoError(t, err)
-	require.Len(t, results, 3)
+	require.Len(t, results, 4)
 	assert.Equal(t, unitTestUser, results[0].Username)
 	assert.Equal(t, true, results[0].Successful)
 	assert.Equal(t, time.Unix(1577880001, 0), results[0].Time)
@@ -151,18 +179,239 @@ func TestSQLProviderMethodsAuthenticationLogs(t *testing.T) {
 	assert.Equal(t, unitTestUser, results[2].Username)
 	assert.Equal(t, false, results[2].Successful)
 	assert.Equal(t, time.Unix(1577880003, 0), results[2].Time)
+	assert.Equal(t, "", results[2].Admin)
+	assert.Equal(t, true, results[3].Successful)
+	assert.Equal(t, "harry", results[3].Admin)
 
 	// Test Blank Rows.
 	mock.ExpectQuery(
-		fmt.Sprintf("SELECT successful, time FROM %s WHERE time>\\? AND username=\\? ORDER BY time DESC", authenticationLogsTableName)).
+		fmt.Sprintf("SELECT successful, time, admin FROM %s WHERE time>\\? AND username=\\? ORDER BY time DESC", authenticationLogsTableName)).
 		WithArgs(args...).
-		WillReturnRows(sqlmock.NewRows([]string{"successful", "time"}))
+		WillReturnRows(sqlmock.NewRows([]string{"successful", "time", "admin"}))
 
 	results, err = provider.LoadLatestAuthenticationLogs(unitTestUser, after)
 	assert.NoError(t, err)
 	assert.Len(t, results, 0)
 }
 
+func TestSQLProviderMethodsRegulation(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	mock.ExpectQuery(
+		"SELECT name FROM sqlite_master WHERE type='table'").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).
+			AddRow(userPreferencesTableName).
+			AddRow(identityVerificationTokensTableName).
+			AddRow(totpConfigurationsTableName).
+			AddRow(webauthnDevicesTableName).
+			AddRow(authenticationLogsTableName).
+			AddRow(regulationBansTableName).
+			AddRow(configTableName))
+
+	args := []driver.Value{"schema", "version"}
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT value FROM %s WHERE category=\\? AND key_name=\\?", configTableName)).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"value"}).
+			AddRow(currentSchemaMockSchemaVersion))
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	after := time.Unix(1577880000, 0)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT username, successful, remote_ip, time, admin FROM %s WHERE time>\\? AND \\(successful=\\? OR admin IS NOT NULL\\) AND remote_ip IS NOT NULL ORDER BY time DESC", authenticationLogsTableName)).
+		WithArgs(after.Unix(), false).
+		WillReturnRows(sqlmock.NewRows([]string{"username", "successful", "remote_ip", "time", "admin"}).
+			AddRow("", true, "00000000000000000000ffffc0a8010a", 1577880003, "harry").
+			AddRow("john", false, "00000000000000000000ffffc0a8010a", 1577880002, nil).
+			AddRow("bob", false, "20010db8000000000000000000000001", 1577880001, nil))
+
+	attempts, err := provider.LoadLatestFailedAuthenticationLogs(after)
+	assert.NoError(t, err)
+	require.Len(t, attempts, 3)
+	assert.True(t, attempts[0].Successful)
+	assert.Equal(t, "harry", attempts[0].Admin)
+	assert.Equal(t, "john", attempts[1].Username)
+	assert.Equal(t, net.ParseIP("192.168.1.10"), attempts[1].RemoteIP)
+	assert.Equal(t, time.Unix(1577880002, 0), attempts[1].Time)
+	assert.False(t, attempts[1].Successful)
+	assert.Equal(t, "", attempts[1].Admin)
+	assert.Equal(t, "bob", attempts[2].Username)
+	assert.Equal(t, net.ParseIP("2001:db8::1"), attempts[2].RemoteIP)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT username, successful, remote_ip, time, admin FROM %s WHERE remote_ip>=\\? AND remote_ip<=\\? AND time>\\? AND \\(\\(successful=\\? AND admin IS NULL\\) OR \\(successful=\\? AND admin IS NOT NULL\\)\\) ORDER BY time DESC LIMIT \\?", authenticationLogsTableName)).
+		WithArgs("00000000000000000000ffffc0a80100", "00000000000000000000ffffc0a801ff", after.Unix(), false, true, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"username", "successful", "remote_ip", "time", "admin"}).
+			AddRow("john", false, "00000000000000000000ffffc0a8010a", 1577880002, nil))
+
+	_, network, err := net.ParseCIDR("192.168.1.0/24")
+	require.NoError(t, err)
+
+	attempts, err = provider.LoadLatestFailedAuthenticationLogsByNetwork(network, after, 3)
+	assert.NoError(t, err)
+	require.Len(t, attempts, 1)
+	assert.True(t, net.ParseIP("192.168.1.10").Equal(attempts[0].RemoteIP))
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT username, successful, remote_ip, time, admin FROM %s WHERE remote_ip>=\\? AND remote_ip<=\\? .*", authenticationLogsTableName)).
+		WithArgs("20010db8000000000000000000000001", "20010db8000000000000000000000001", after.Unix(), false, true, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"username", "successful", "remote_ip", "time", "admin"}))
+
+	attempts, err = provider.LoadLatestFailedAuthenticationLogsByNetwork(&net.IPNet{IP: net.ParseIP("2001:db8::1"), Mask: net.CIDRMask(128, 128)}, after, 5)
+	assert.NoError(t, err)
+	assert.Len(t, attempts, 0)
+
+	ban := models.RegulationBan{
+		Type:      "ip",
+		Value:     "192.168.1.10",
+		Reason:    "3 failed authentication attempts within 5m0s",
+		CreatedAt: time.Unix(1577880000, 0),
+		ExpiresAt: time.Unix(1577881800, 0),
+	}
+
+	mock.ExpectExec(
+		fmt.Sprintf("INSERT INTO %s \\(ban_type, value, reason, created_at, expires_at, revoked\\) VALUES \\(\\?, \\?, \\?, \\?, \\?, \\?\\)", regulationBansTableName)).
+		WithArgs(ban.Type, ban.Value, ban.Reason, ban.CreatedAt.Unix(), ban.ExpiresAt.Unix(), false).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = provider.SaveRegulationBan(ban)
+	assert.NoError(t, err)
+
+	selectBan := fmt.Sprintf("SELECT id, ban_type, value, reason, created_at, expires_at, revoked FROM %s WHERE ban_type=\\? AND value=\\? AND expires_at>\\? AND revoked=\\? ORDER BY expires_at DESC LIMIT 1", regulationBansTableName)
+
+	mock.ExpectQuery(selectBan).
+		WithArgs("ip", "192.168.1.10", int64(1577880100), false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ban_type", "value", "reason", "created_at", "expires_at", "revoked"}).
+			AddRow(1, ban.Type, ban.Value, ban.Reason, ban.CreatedAt.Unix(), ban.ExpiresAt.Unix(), false))
+
+	loaded, err := provider.LoadRegulationBan("ip", "192.168.1.10", time.Unix(1577880100, 0))
+	assert.NoError(t, err)
+	require.NotNil(t, loaded)
+
+	ban.ID = 1
+	assert.Equal(t, ban, *loaded)
+
+	mock.ExpectQuery(selectBan).
+		WithArgs("ip", "192.168.1.11", int64(1577880100), false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ban_type", "value", "reason", "created_at", "expires_at", "revoked"}))
+
+	loaded, err = provider.LoadRegulationBan("ip", "192.168.1.11", time.Unix(1577880100, 0))
+	assert.Equal(t, ErrNoRegulationBan, err)
+	assert.Nil(t, loaded)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, ban_type, value, reason, created_at, expires_at, revoked FROM %s WHERE expires_at>\\? AND revoked=\\? ORDER BY expires_at DESC", regulationBansTableName)).
+		WithArgs(int64(1577880100), false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ban_type", "value", "reason", "created_at", "expires_at", "revoked"}).
+			AddRow(1, ban.Type, ban.Value, ban.Reason, ban.CreatedAt.Unix(), ban.ExpiresAt.Unix(), false))
+
+	bans, err := provider.LoadRegulationBans(time.Unix(1577880100, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, []models.RegulationBan{ban}, bans)
+
+	mock.ExpectExec(
+		fmt.Sprintf("UPDATE %s SET revoked=\\? WHERE ban_type=\\? AND value=\\? AND expires_at>\\? AND revoked=\\?", regulationBansTableName)).
+		WithArgs(true, "ip", "192.168.1.10", int64(1577880100), false).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.RevokeRegulationBans("ip", "192.168.1.10", time.Unix(1577880100, 0))
+	assert.NoError(t, err)
+}
+
+func TestSQLProviderMethodsUser(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	mock.ExpectQuery(
+		"SELECT name FROM sqlite_master WHERE type='table'").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).
+			AddRow(userPreferencesTableName).
+			AddRow(identityVerificationTokensTableName).
+			AddRow(totpConfigurationsTableName).
+			AddRow(webauthnDevicesTableName).
+			AddRow(authenticationLogsTableName).
+			AddRow(usersTableName).
+			AddRow(configTableName))
+
+	args := []driver.Value{"schema", "version"}
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT value FROM %s WHERE category=\\? AND key_name=\\?", configTableName)).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"value"}).
+			AddRow(currentSchemaMockSchemaVersion))
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	selectUser := fmt.Sprintf("SELECT id, username, display_name, password, disabled, created_at, updated_at FROM %s WHERE username=\\?", usersTableName)
+
+	mock.ExpectQuery(selectUser).
+		WithArgs(unitTestUser).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "display_name", "password", "disabled", "created_at", "updated_at"}).
+			AddRow(1, unitTestUser, "John Doe", "$6$rounds=50000$aFr56HjK3DrB8t3S$zhPQiS85cgBlNhUKKE6n/AHMlpqrvYSnSL3fEVkK0yHFQ.oFFAd8D4OhPAy18K5U61Z2eBhxQXExGU/eknXlY1", false, 1577880000, 1577880001))
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT email FROM %s WHERE username=\\? ORDER BY id", userEmailsTableName)).
+		WithArgs(unitTestUser).
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).
+			AddRow("john.doe@authelia.com").
+			AddRow("jdoe@authelia.com"))
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT group_name FROM %s WHERE username=\\? ORDER BY id", userGroupsTableName)).
+		WithArgs(unitTestUser).
+		WillReturnRows(sqlmock.NewRows([]string{"group_name"}).
+			AddRow("admins").
+			AddRow("dev"))
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT name, value FROM %s WHERE username=\\? ORDER BY id", userAttributesTableName)).
+		WithArgs(unitTestUser).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "value"}).
+			AddRow("phone_number", "+1 555 0100").
+			AddRow("locale", "en-US").
+			AddRow("phone_number", "+1 555 0101"))
+
+	user, err := provider.LoadUser(unitTestUser)
+	assert.NoError(t, err)
+	require.NotNil(t, user)
+	assert.Equal(t, 1, user.ID)
+	assert.Equal(t, unitTestUser, user.Username)
+	assert.Equal(t, "John Doe", user.DisplayName)
+	assert.False(t, user.Disabled)
+	assert.Equal(t, []string{"john.doe@authelia.com", "jdoe@authelia.com"}, user.Emails)
+	assert.Equal(t, []string{"admins", "dev"}, user.Groups)
+	assert.Equal(t, map[string][]string{"phone_number": {"+1 555 0100", "+1 555 0101"}, "locale": {"en-US"}}, user.Attributes)
+	assert.Equal(t, time.Unix(1577880000, 0), user.CreatedAt)
+	assert.Equal(t, time.Unix(1577880001, 0), user.UpdatedAt)
+
+	mock.ExpectQuery(selectUser).
+		WithArgs("harry").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "display_name", "password", "disabled", "created_at", "updated_at"}))
+
+	user, err = provider.LoadUser("harry")
+	assert.Equal(t, ErrNoUser, err)
+	assert.Nil(t, user)
+
+	updatePassword := fmt.Sprintf("UPDATE %s SET password=\\?, updated_at=\\? WHERE username=\\?", usersTableName)
+
+	mock.ExpectExec(updatePassword).
+		WithArgs("hash", int64(1577880100), unitTestUser).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.UpdateUserPassword(unitTestUser, "hash", time.Unix(1577880100, 0))
+	assert.NoError(t, err)
+
+	mock.ExpectExec(updatePassword).
+		WithArgs("hash", int64(1577880100), "harry").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = provider.UpdateUserPassword("harry", "hash", time.Unix(1577880100, 0))
+	assert.Equal(t, ErrNoUser, err)
+}
+
 func TestSQLProviderMethodsPreferred(t *testing.T) {
 	provider, mock := NewSQLMockProvider()
 
@@ -171,8 +420,8 @@ func TestSQLProviderMethodsPreferred(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"name"}).
 			AddRow(userPreferencesTableName).
 			AddRow(identityVerificationTokensTableName).
-			AddRow(totpSecretsTableName).
-			AddRow(u2fDeviceHandlesTableName).
+			AddRow(totpConfigurationsTableName).
+			AddRow(webauthnDevicesTableName).
 			AddRow(authenticationLogsTableName).
 			AddRow(configTableName))
 
@@ -188,20 +437,20 @@ func TestSQLProviderMethodsPreferred(t *testing.T) {
 
 	mock.ExpectExec(
 		fmt.Sprintf("REPLACE INTO %s \\(username, second_factor_method\\) VALUES \\(\\?, \\?\\)", userPreferencesTableName)).
-		WithArgs(unitTestUser, authentication.TOTP).
+		WithArgs(unitTestUser, unitTestMethod).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = provider.SavePreferred2FAMethod(unitTestUser, authentication.TOTP)
+	err = provider.SavePreferred2FAMethod(unitTestUser, unitTestMethod)
 	assert.NoError(t, err)
 
 	mock.ExpectQuery(
 		fmt.Sprintf("SELECT second_factor_method FROM %s WHERE username=\\?", userPreferencesTableName)).
 		WithArgs(unitTestUser).
-		WillReturnRows(sqlmock.NewRows([]string{"second_factor_method"}).AddRow(authentication.TOTP))
+		WillReturnRows(sqlmock.NewRows([]string{"second_factor_method"}).AddRow(unitTestMethod))
 
 	method, err := provider.LoadPreferred2FAMethod(unitTestUser)
 	assert.NoError(t, err)
-	assert.Equal(t, authentication.TOTP, method)
+	assert.Equal(t, unitTestMethod, method)
 
 	// Test Blank Rows.
 	mock.ExpectQuery(
@@ -222,8 +471,8 @@ func TestSQLProviderMethodsTOTP(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"name"}).
 			AddRow(userPreferencesTableName).
 			AddRow(identityVerificationTokensTableName).
-			AddRow(totpSecretsTableName).
-			AddRow(u2fDeviceHandlesTableName).
+			AddRow(totpConfigurationsTableName).
+			AddRow(webauthnDevicesTableName).
 			AddRow(authenticationLogsTableName).
 			AddRow(configTableName))
 
@@ -237,46 +486,116 @@ func TestSQLProviderMethodsTOTP(t *testing.T) {
 	err := provider.initialize(provider.db)
 	assert.NoError(t, err)
 
-	pretendSecret := "abc123"
-	args = []driver.Value{unitTestUser, pretendSecret}
+	config := models.TOTPConfiguration{
+		CreatedAt:   time.Unix(1577880001, 0),
+		Username:    unitTestUser,
+		Description: models.TOTPConfigurationDefaultDescription,
+		Secret:      "abc123",
+	}
+
 	mock.ExpectExec(
-		fmt.Sprintf("REPLACE INTO %s \\(username, secret\\) VALUES \\(\\?, \\?\\)", totpSecretsTableName)).
-		WithArgs(args...).
+		fmt.Sprintf("INSERT INTO %s \\(created_at, last_used_at, username, description, secret\\) VALUES \\(\\?, \\?, \\?, \\?, \\?\\)", totpConfigurationsTableName)).
+		WithArgs(int64(1577880001), nil, unitTestUser, models.TOTPConfigurationDefaultDescription, "abc123").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = provider.SaveTOTPConfiguration(config)
+	assert.NoError(t, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("UPDATE %s SET last_used_at=\\? WHERE id=\\?", totpConfigurationsTableName)).
+		WithArgs(int64(1577880002), 1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = provider.SaveTOTPSecret(unitTestUser, pretendSecret)
+	err = provider.UpdateTOTPConfigurationSignIn(1, time.Unix(1577880002, 0))
 	assert.NoError(t, err)
 
-	args = []driver.Value{unitTestUser}
 	mock.ExpectQuery(
-		fmt.Sprintf("SELECT secret FROM %s WHERE username=\\?", totpSecretsTableName)).
-		WithArgs(args...).
-		WillReturnRows(sqlmock.NewRows([]string{"secret"}).AddRow(pretendSecret))
+		fmt.Sprintf("SELECT id, created_at, last_used_at, username, description, secret FROM %s WHERE username=\\?", totpConfigurationsTableName)).
+		WithArgs(unitTestUser).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "last_used_at", "username", "description", "secret"}).
+			AddRow(1, 1577880001, 1577880002, unitTestUser, models.TOTPConfigurationDefaultDescription, "abc123").
+			AddRow(2, 1577880003, nil, unitTestUser, "Backup", "def456"))
 
-	secret, err := provider.LoadTOTPSecret(unitTestUser)
+	configs, err := provider.LoadTOTPConfigurationsByUsername(unitTestUser)
 	assert.NoError(t, err)
-	assert.Equal(t, pretendSecret, secret)
+	require.Len(t, configs, 2)
+	assert.Equal(t, 1, configs[0].ID)
+	assert.Equal(t, "abc123", configs[0].Secret)
+	require.NotNil(t, configs[0].LastUsedAt)
+	assert.Equal(t, time.Unix(1577880002, 0), *configs[0].LastUsedAt)
+	assert.Equal(t, "Backup", configs[1].Description)
+	assert.Nil(t, configs[1].LastUsedAt)
 
 	mock.ExpectExec(
-		fmt.Sprintf("DELETE FROM %s WHERE username=\\?", totpSecretsTableName)).
-		WithArgs(unitTestUser).
+		fmt.Sprintf("DELETE FROM %s WHERE id=\\? AND username=\\?", totpConfigurationsTableName)).
+		WithArgs(1, unitTestUser).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = provider.DeleteTOTPSecret(unitTestUser)
+	err = provider.DeleteTOTPConfiguration(unitTestUser, 1)
 	assert.NoError(t, err)
 
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE id=\\? AND username=\\?", totpConfigurationsTableName)).
+		WithArgs(1, unitTestUser).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = provider.DeleteTOTPConfiguration(unitTestUser, 1)
+	assert.EqualError(t, err, "No TOTP configuration found")
+
 	mock.ExpectQuery(
-		fmt.Sprintf("SELECT secret FROM %s WHERE username=\\?", totpSecretsTableName)).
-		WithArgs(args...).
-		WillReturnRows(sqlmock.NewRows([]string{"secret"}))
+		fmt.Sprintf("SELECT id, created_at, last_used_at, username, description, secret FROM %s WHERE username=\\?", totpConfigurationsTableName)).
+		WithArgs(unitTestUser).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "last_used_at", "username", "description", "secret"}))
+
+	// Test Blank Rows.
+	configs, err = provider.LoadTOTPConfigurationsByUsername(unitTestUser)
+	assert.EqualError(t, err, "No TOTP configuration found")
+	assert.Len(t, configs, 0)
+}
 
-	// Test Blank Rows
-	secret, err = provider.LoadTOTPSecret(unitTestUser)
-	assert.EqualError(t, err, "No TOTP secret registered")
-	assert.Equal(t, "", secret)
+func TestSQLUpgradeDatabaseMigratesTOTPSecrets(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	mock.ExpectQuery(
+		"SELECT name FROM sqlite_master WHERE type='table'").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).
+			AddRow(userPreferencesTableName).
+			AddRow(identityVerificationTokensTableName).
+			AddRow(totpSecretsTableName).
+			AddRow(webauthnDevicesTableName).
+			AddRow(authenticationLogsTableName).
+			AddRow(configTableName))
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT value FROM %s WHERE category=\\? AND key_name=\\?", configTableName)).
+		WithArgs("schema", "version").
+		WillReturnRows(sqlmock.NewRows([]string{"value"}).
+			AddRow("2"))
+
+	mock.ExpectBegin()
+
+	expectSchemaUpgradeToVersion003(mock, sqlmock.NewRows([]string{"username", "secret"}).
+		AddRow(unitTestUser, "abc123").
+		AddRow("harry", "def456"), 2)
+	expectSchemaUpgradeToVersion004(mock)
+	expectSchemaUpgradeToVersion005(mock)
+	expectSchemaUpgradeToVersion006(mock)
+	expectSchemaUpgradeToVersion007(mock)
+	expectSchemaUpgradeToVersion008(mock)
+	expectSchemaUpgradeToVersion009(mock)
+	expectSchemaUpgradeToVersion010(mock)
+	expectSchemaUpgradeToVersion011(mock)
+	expectSchemaUpgradeToVersion012(mock)
+	expectSchemaUpgradeToVersion013(mock)
+
+	mock.ExpectCommit()
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestSQLProviderMethodsU2F(t *testing.T) {
+func TestSQLUpgradeDatabaseMigratesU2FDevices(t *testing.T) {
 	provider, mock := NewSQLMockProvider()
 
 	mock.ExpectQuery(
@@ -289,6 +608,51 @@ func TestSQLProviderMethodsU2F(t *testing.T) {
 			AddRow(authenticationLogsTableName).
 			AddRow(configTableName))
 
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT value FROM %s WHERE category=\\? AND key_name=\\?", configTableName)).
+		WithArgs("schema", "version").
+		WillReturnRows(sqlmock.NewRows([]string{"value"}).
+			AddRow("1"))
+
+	mock.ExpectBegin()
+
+	pretendKeyHandleB64 := base64.StdEncoding.EncodeToString([]byte("abc"))
+	pretendPublicKeyB64 := base64.StdEncoding.EncodeToString([]byte("123"))
+
+	expectSchemaUpgradeToVersion002(mock, sqlmock.NewRows([]string{"username", "keyHandle", "publicKey"}).
+		AddRow(unitTestUser, pretendKeyHandleB64, pretendPublicKeyB64), 1)
+	expectSchemaUpgradeToVersion003(mock, sqlmock.NewRows([]string{"username", "secret"}), 0)
+	expectSchemaUpgradeToVersion004(mock)
+	expectSchemaUpgradeToVersion005(mock)
+	expectSchemaUpgradeToVersion006(mock)
+	expectSchemaUpgradeToVersion007(mock)
+	expectSchemaUpgradeToVersion008(mock)
+	expectSchemaUpgradeToVersion009(mock)
+	expectSchemaUpgradeToVersion010(mock)
+	expectSchemaUpgradeToVersion011(mock)
+	expectSchemaUpgradeToVersion012(mock)
+	expectSchemaUpgradeToVersion013(mock)
+
+	mock.ExpectCommit()
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLProviderMethodsWebauthn(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	mock.ExpectQuery(
+		"SELECT name FROM sqlite_master WHERE type='table'").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).
+			AddRow(userPreferencesTableName).
+			AddRow(identityVerificationTokensTableName).
+			AddRow(totpConfigurationsTableName).
+			AddRow(webauthnDevicesTableName).
+			AddRow(authenticationLogsTableName).
+			AddRow(configTableName))
+
 	args := []driver.Value{"schema", "version"}
 	mock.ExpectQuery(
 		fmt.Sprintf("SELECT value FROM %s WHERE category=\\? AND key_name=\\?", configTableName)).
@@ -299,55 +663,87 @@ func TestSQLProviderMethodsU2F(t *testing.T) {
 	err := provider.initialize(provider.db)
 	assert.NoError(t, err)
 
-	pretendKeyHandle := []byte("abc")
-	pretendPublicKey := []byte("123")
-	pretendKeyHandleB64 := base64.StdEncoding.EncodeToString(pretendKeyHandle)
-	pretendPublicKeyB64 := base64.StdEncoding.EncodeToString(pretendPublicKey)
+	device := models.WebauthnDevice{
+		CreatedAt:       time.Unix(1577880001, 0),
+		Username:        unitTestUser,
+		Description:     models.WebauthnDeviceDefaultDescription,
+		KID:             []byte("abc"),
+		PublicKey:       []byte("123"),
+		AttestationType: "packed",
+		AAGUID:          uuid.MustParse("cb69481e-8ff7-4039-93ec-0a2729a154a8"),
+		SignCount:       10,
+	}
+
+	kidB64 := base64.StdEncoding.EncodeToString(device.KID)
+	publicKeyB64 := base64.StdEncoding.EncodeToString(device.PublicKey)
 
-	args = []driver.Value{unitTestUser, pretendKeyHandleB64, pretendPublicKeyB64}
 	mock.ExpectExec(
-		fmt.Sprintf("REPLACE INTO %s \\(username, keyHandle, publicKey\\) VALUES \\(\\?, \\?, \\?\\)", u2fDeviceHandlesTableName)).
-		WithArgs(args...).
+		fmt.Sprintf("REPLACE INTO %s \\(created_at, last_used_at, username, description, kid, public_key, attestation_type, aaguid, sign_count, clone_warning\\) VALUES \\(\\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?\\)", webauthnDevicesTableName)).
+		WithArgs(int64(1577880001), nil, unitTestUser, models.WebauthnDeviceDefaultDescription, kidB64, publicKeyB64, "packed", "cb69481e-8ff7-4039-93ec-0a2729a154a8", 10, false).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = provider.SaveWebauthnDevice(device)
+	assert.NoError(t, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("UPDATE %s SET last_used_at=\\?, sign_count=\\?, clone_warning=\\? WHERE id=\\?", webauthnDevicesTableName)).
+		WithArgs(int64(1577880002), 11, false, 1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = provider.SaveU2FDeviceHandle(unitTestUser, pretendKeyHandle, pretendPublicKey)
+	err = provider.UpdateWebauthnDeviceSignIn(1, time.Unix(1577880002, 0), 11, false)
 	assert.NoError(t, err)
 
-	args = []driver.Value{unitTestUser}
 	mock.ExpectQuery(
-		fmt.Sprintf("SELECT keyHandle, publicKey FROM %s WHERE username=\\?", u2fDeviceHandlesTableName)).
-		WithArgs(args...).
-		WillReturnRows(sqlmock.NewRows([]string{"keyHandle", "publicKey"}).
-			AddRow(pretendKeyHandleB64, pretendPublicKeyB64))
+		fmt.Sprintf("SELECT id, created_at, last_used_at, username, description, kid, public_key, attestation_type, aaguid, sign_count, clone_warning FROM %s WHERE username=\\?", webauthnDevicesTableName)).
+		WithArgs(unitTestUser).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "last_used_at", "username", "description", "kid", "public_key", "attestation_type", "aaguid", "sign_count", "clone_warning"}).
+			AddRow(1, 1577880001, 1577880002, unitTestUser, models.WebauthnDeviceDefaultDescription, kidB64, publicKeyB64, "packed", "cb69481e-8ff7-4039-93ec-0a2729a154a8", 11, false))
 
-	keyHandle, publicKey, err := provider.LoadU2FDeviceHandle(unitTestUser)
+	devices, err := provider.LoadWebauthnDevicesByUsername(unitTestUser)
 	assert.NoError(t, err)
-	assert.Equal(t, pretendKeyHandle, keyHandle)
-	assert.Equal(t, pretendPublicKey, publicKey)
+	require.Len(t, devices, 1)
+	assert.Equal(t, 1, devices[0].ID)
+	assert.Equal(t, time.Unix(1577880001, 0), devices[0].CreatedAt)
+	require.NotNil(t, devices[0].LastUsedAt)
+	assert.Equal(t, time.Unix(1577880002, 0), *devices[0].LastUsedAt)
+	assert.Equal(t, device.KID, devices[0].KID)
+	assert.Equal(t, device.PublicKey, devices[0].PublicKey)
+	assert.Equal(t, device.AAGUID, devices[0].AAGUID)
+	assert.Equal(t, uint32(11), devices[0].SignCount)
 
 	// Test Blank Rows.
 	mock.ExpectQuery(
-		fmt.Sprintf("SELECT keyHandle, publicKey FROM %s WHERE username=\\?", u2fDeviceHandlesTableName)).
-		WithArgs(args...).
-		WillReturnRows(sqlmock.NewRows([]string{"keyHandle", "publicKey"}))
+		fmt.Sprintf("SELECT id, created_at, last_used_at, username, description, kid, public_key, attestation_type, aaguid, sign_count, clone_warning FROM %s WHERE username=\\?", webauthnDevicesTableName)).
+		WithArgs(unitTestUser).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "last_used_at", "username", "description", "kid", "public_key", "attestation_type", "aaguid", "sign_count", "clone_warning"}))
+
+	devices, err = provider.LoadWebauthnDevicesByUsername(unitTestUser)
+	assert.EqualError(t, err, "No Webauthn device found")
+	assert.Len(t, devices, 0)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE id=\\? AND username=\\?", webauthnDevicesTableName)).
+		WithArgs(1, unitTestUser).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.DeleteWebauthnDevice(unitTestUser, 1)
+	assert.NoError(t, err)
 
-	keyHandle, publicKey, err = provider.LoadU2FDeviceHandle(unitTestUser)
-	assert.EqualError(t, err, "No U2F device handle found")
-	assert.Equal(t, []byte(nil), keyHandle)
-	assert.Equal(t, []byte(nil), publicKey)
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE id=\\? AND username=\\?", webauthnDevicesTableName)).
+		WithArgs(2, unitTestUser).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = provider.DeleteWebauthnDevice(unitTestUser, 2)
+	assert.EqualError(t, err, "No Webauthn device found")
 }
 
-func TestSQLProviderMethodsIdentityVerificationTokens(t *testing.T) {
+func TestSQLProviderMethodsOAuth2(t *testing.T) {
 	provider, mock := NewSQLMockProvider()
 
 	mock.ExpectQuery(
 		"SELECT name FROM sqlite_master WHERE type='table'").
 		WillReturnRows(sqlmock.NewRows([]string{"name"}).
-			AddRow(userPreferencesTableName).
-			AddRow(identityVerificationTokensTableName).
-			AddRow(totpSecretsTableName).
-			AddRow(u2fDeviceHandlesTableName).
-			AddRow(authenticationLogsTableName).
 			AddRow(configTableName))
 
 	args := []driver.Value{"schema", "version"}
@@ -360,41 +756,834 @@ func TestSQLProviderMethodsIdentityVerificationTokens(t *testing.T) {
 	err := provider.initialize(provider.db)
 	assert.NoError(t, err)
 
-	fakeIdentityVerificationToken := "abc"
+	expiresAt := time.Unix(1577880060, 0)
+
+	session := models.OAuth2Session{
+		Signature:       "sig",
+		RequestID:       "req",
+		ClientID:        "client",
+		Subject:         unitTestUser,
+		RequestedAt:     time.Unix(1577880001, 0),
+		ExpiresAt:       &expiresAt,
+		RequestedScopes: []string{"openid", "profile"},
+		GrantedScopes:   []string{"openid"},
+		Form:            "state=abc",
+		Session:         []byte(`{"subject":"john"}`),
+		Active:          true,
+	}
 
 	mock.ExpectExec(
-		fmt.Sprintf("INSERT INTO %s \\(token\\) VALUES \\(\\?\\)", identityVerificationTokensTableName)).
-		WithArgs(fakeIdentityVerificationToken).
+		fmt.Sprintf("INSERT INTO %s \\(signature, request_id, client_id, subject, requested_at, expires_at, requested_scopes, granted_scopes, requested_audience, granted_audience, form_data, session_data, active\\) VALUES .*", oauth2AccessTokenSessionsTableName)).
+		WithArgs("sig", "req", "client", unitTestUser, int64(1577880001), int64(1577880060), "openid profile", "openid", "", "", "state=abc", `{"subject":"john"}`, true).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = provider.SaveIdentityVerificationToken(fakeIdentityVerificationToken)
+	err = provider.SaveOAuth2Session(OAuth2SessionTypeAccessToken, session)
 	assert.NoError(t, err)
 
 	mock.ExpectQuery(
-		fmt.Sprintf("SELECT EXISTS \\(SELECT \\* FROM %s WHERE token=\\?\\)", identityVerificationTokensTableName)).
-		WithArgs(fakeIdentityVerificationToken).
-		WillReturnRows(sqlmock.NewRows([]string{"EXISTS"}).
-			AddRow(true))
+		fmt.Sprintf("SELECT id, signature, request_id, client_id, subject, requested_at, expires_at, requested_scopes, granted_scopes, requested_audience, granted_audience, form_data, session_data, active FROM %s WHERE signature=\\?", oauth2AccessTokenSessionsTableName)).
+		WithArgs("sig").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "signature", "request_id", "client_id", "subject", "requested_at", "expires_at", "requested_scopes", "granted_scopes", "requested_audience", "granted_audience", "form_data", "session_data", "active"}).
+			AddRow(1, "sig", "req", "client", unitTestUser, 1577880001, 1577880060, "openid profile", "openid", "", "", "state=abc", `{"subject":"john"}`, true))
+
+	loaded, err := provider.LoadOAuth2Session(OAuth2SessionTypeAccessToken, "sig")
+	require.NoError(t, err)
+	assert.Equal(t, 1, loaded.ID)
+	assert.Equal(t, session.RequestedScopes, loaded.RequestedScopes)
+	assert.Equal(t, session.GrantedScopes, loaded.GrantedScopes)
+	assert.Len(t, loaded.GrantedAudience, 0)
+	assert.Equal(t, session.Session, loaded.Session)
+	require.NotNil(t, loaded.ExpiresAt)
+	assert.Equal(t, expiresAt, *loaded.ExpiresAt)
+	assert.True(t, loaded.Active)
 
-	valid, err := provider.FindIdentityVerificationToken(fakeIdentityVerificationToken)
-	assert.NoError(t, err)
-	assert.True(t, valid)
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT .* FROM %s WHERE signature=\\?", oauth2RefreshTokenSessionsTableName)).
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	loaded, err = provider.LoadOAuth2Session(OAuth2SessionTypeRefreshToken, "missing")
+	assert.EqualError(t, err, "No OAuth 2.0 session found")
+	assert.Nil(t, loaded)
 
 	mock.ExpectExec(
-		fmt.Sprintf("DELETE FROM %s WHERE token=\\?", identityVerificationTokensTableName)).
-		WithArgs(fakeIdentityVerificationToken).
+		fmt.Sprintf("UPDATE %s SET active=\\? WHERE signature=\\?", oauth2AuthorizeCodeSessionsTableName)).
+		WithArgs(false, "sig").
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = provider.RemoveIdentityVerificationToken(fakeIdentityVerificationToken)
-	assert.NoError(t, err)
+	assert.NoError(t, provider.DeactivateOAuth2Session(OAuth2SessionTypeAuthorizeCode, "sig"))
+
+	mock.ExpectExec(
+		fmt.Sprintf("UPDATE %s SET active=\\? WHERE request_id=\\?", oauth2RefreshTokenSessionsTableName)).
+		WithArgs(false, "req").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, provider.DeactivateOAuth2SessionByRequestID(OAuth2SessionTypeRefreshToken, "req"))
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE signature=\\?", oauth2PKCERequestSessionsTableName)).
+		WithArgs("sig").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, provider.RevokeOAuth2Session(OAuth2SessionTypePKCEChallenge, "sig"))
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE request_id=\\?", oauth2AccessTokenSessionsTableName)).
+		WithArgs("req").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, provider.RevokeOAuth2SessionByRequestID(OAuth2SessionTypeAccessToken, "req"))
+
+	mock.ExpectExec(
+		fmt.Sprintf("REPLACE INTO %s \\(signature, expires_at\\) VALUES \\(\\?, \\?\\)", oauth2BlacklistedJTIsTableName)).
+		WithArgs("jti", int64(1577880060)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, provider.SaveOAuth2BlacklistedJTI(models.OAuth2BlacklistedJTI{Signature: "jti", ExpiresAt: expiresAt}))
 
 	mock.ExpectQuery(
-		fmt.Sprintf("SELECT EXISTS \\(SELECT \\* FROM %s WHERE token=\\?\\)", identityVerificationTokensTableName)).
-		WithArgs(fakeIdentityVerificationToken).
-		WillReturnRows(sqlmock.NewRows([]string{"EXISTS"}).
-			AddRow(false))
+		fmt.Sprintf("SELECT id, signature, expires_at FROM %s WHERE signature=\\?", oauth2BlacklistedJTIsTableName)).
+		WithArgs("jti").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "signature", "expires_at"}).
+			AddRow(1, "jti", 1577880060))
 
-	valid, err = provider.FindIdentityVerificationToken(fakeIdentityVerificationToken)
-	assert.NoError(t, err)
-	assert.False(t, valid)
+	jti, err := provider.LoadOAuth2BlacklistedJTI("jti")
+	require.NoError(t, err)
+	assert.Equal(t, expiresAt, jti.ExpiresAt)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, signature, expires_at FROM %s WHERE signature=\\?", oauth2BlacklistedJTIsTableName)).
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "signature", "expires_at"}))
+
+	jti, err = provider.LoadOAuth2BlacklistedJTI("missing")
+	assert.EqualError(t, err, "No OAuth 2.0 blacklisted JTI found")
+	assert.Nil(t, jti)
+
+	for _, sessionType := range oauth2SessionTypes {
+		mock.ExpectExec(
+			fmt.Sprintf("DELETE FROM %s WHERE expires_at<\\?", sessionType.Table())).
+			WithArgs(int64(1577880100)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE expires_at<\\?", oauth2BlacklistedJTIsTableName)).
+		WithArgs(int64(1577880100)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE expires_at<\\?", oauth2ConsentSessionsTableName)).
+		WithArgs(int64(1577880100)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE expires_at<\\?", oauth2DeviceCodeSessionsTableName)).
+		WithArgs(int64(1577880100)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE expires_at<\\?", oauth2PushedAuthorizeRequestsTableName)).
+		WithArgs(int64(1577880100)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, provider.PurgeExpiredOAuth2(time.Unix(1577880100, 0)))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLProviderMethodsOAuth2ConsentSessions(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	mock.ExpectQuery(
+		"SELECT name FROM sqlite_master WHERE type='table'").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).
+			AddRow(configTableName))
+
+	args := []driver.Value{"schema", "version"}
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT value FROM %s WHERE category=\\? AND key_name=\\?", configTableName)).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"value"}).
+			AddRow(currentSchemaMockSchemaVersion))
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	consent := models.OAuth2ConsentSession{
+		ClientID:        "client",
+		Subject:         unitTestUser,
+		CreatedAt:       time.Unix(1577880001, 0),
+		ExpiresAt:       time.Unix(1578484801, 0),
+		GrantedScopes:   []string{"openid", "profile"},
+		GrantedAudience: []string{"client"},
+	}
+
+	mock.ExpectExec(
+		fmt.Sprintf("INSERT INTO %s \\(client_id, subject, created_at, expires_at, granted_scopes, granted_audience\\) VALUES \\(\\?, \\?, \\?, \\?, \\?, \\?\\)", oauth2ConsentSessionsTableName)).
+		WithArgs("client", unitTestUser, int64(1577880001), int64(1578484801), "openid profile", "client").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, provider.SaveOAuth2ConsentSession(consent))
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, client_id, subject, created_at, expires_at, granted_scopes, granted_audience FROM %s WHERE subject=\\? AND expires_at>\\? ORDER BY created_at", oauth2ConsentSessionsTableName)).
+		WithArgs(unitTestUser, int64(1577880100)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "client_id", "subject", "created_at", "expires_at", "granted_scopes", "granted_audience"}).
+			AddRow(1, "client", unitTestUser, 1577880001, 1578484801, "openid profile", "client"))
+
+	consents, err := provider.LoadOAuth2ConsentSessionsBySubject(unitTestUser, time.Unix(1577880100, 0))
+	require.NoError(t, err)
+	require.Len(t, consents, 1)
+	assert.Equal(t, 1, consents[0].ID)
+	assert.Equal(t, consent.ExpiresAt, consents[0].ExpiresAt)
+	assert.Equal(t, consent.GrantedScopes, consents[0].GrantedScopes)
+	assert.Equal(t, consent.GrantedAudience, consents[0].GrantedAudience)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT .* FROM %s WHERE subject=\\? AND expires_at>\\? ORDER BY created_at", oauth2ConsentSessionsTableName)).
+		WithArgs("harry", int64(1577880100)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "client_id", "subject", "created_at", "expires_at", "granted_scopes", "granted_audience"}))
+
+	consents, err = provider.LoadOAuth2ConsentSessionsBySubject("harry", time.Unix(1577880100, 0))
+	assert.EqualError(t, err, "No OAuth 2.0 consent session found")
+	assert.Nil(t, consents)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE id=\\? AND subject=\\?", oauth2ConsentSessionsTableName)).
+		WithArgs(1, unitTestUser).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, provider.DeleteOAuth2ConsentSession(unitTestUser, 1))
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE id=\\? AND subject=\\?", oauth2ConsentSessionsTableName)).
+		WithArgs(1, "harry").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.EqualError(t, provider.DeleteOAuth2ConsentSession("harry", 1), "No OAuth 2.0 consent session found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLProviderMethodsOAuth2DeviceCodeSessions(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	mock.ExpectQuery(
+		"SELECT name FROM sqlite_master WHERE type='table'").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).
+			AddRow(configTableName))
+
+	args := []driver.Value{"schema", "version"}
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT value FROM %s WHERE category=\\? AND key_name=\\?", configTableName)).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"value"}).
+			AddRow(currentSchemaMockSchemaVersion))
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	columns := []string{"id", "signature", "user_code_signature", "client_id", "subject", "status", "requested_at",
+		"expires_at", "last_polled_at", "requested_scopes", "granted_scopes", "requested_audience", "granted_audience",
+		"session_data"}
+
+	session := models.OAuth2DeviceCodeSession{
+		Signature:         "abc",
+		UserCodeSignature: "def",
+		ClientID:          "tv",
+		Status:            models.OAuth2DeviceCodeStatusPending,
+		RequestedAt:       time.Unix(1577880001, 0),
+		ExpiresAt:         time.Unix(1577880601, 0),
+		RequestedScopes:   []string{"openid", "profile"},
+		RequestedAudience: []string{"tv"},
+	}
+
+	mock.ExpectExec(
+		fmt.Sprintf("INSERT INTO %s \\(signature, user_code_signature, client_id, subject, status, requested_at, expires_at, last_polled_at, requested_scopes, granted_scopes, requested_audience, granted_audience, session_data\\) VALUES \\(\\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?\\)", oauth2DeviceCodeSessionsTableName)).
+		WithArgs("abc", "def", "tv", "", models.OAuth2DeviceCodeStatusPending, int64(1577880001), int64(1577880601), nil, "openid profile", "", "tv", "", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, provider.SaveOAuth2DeviceCodeSession(session))
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, .* FROM %s WHERE user_code_signature=\\?", oauth2DeviceCodeSessionsTableName)).
+		WithArgs("def").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, "abc", "def", "tv", "", 0, 1577880001, 1577880601, nil, "openid profile", "", "tv", "", ""))
+
+	loaded, err := provider.LoadOAuth2DeviceCodeSessionByUserCode("def")
+	require.NoError(t, err)
+	assert.Equal(t, 1, loaded.ID)
+	assert.Equal(t, models.OAuth2DeviceCodeStatusPending, loaded.Status)
+	assert.Nil(t, loaded.LastPolledAt)
+	assert.Equal(t, session.RequestedScopes, loaded.RequestedScopes)
+	assert.Len(t, loaded.GrantedScopes, 0)
+
+	polledAt := time.Unix(1577880100, 0)
+	loaded.Subject = unitTestUser
+	loaded.Status = models.OAuth2DeviceCodeStatusApproved
+	loaded.LastPolledAt = &polledAt
+	loaded.GrantedScopes = []string{"openid"}
+	loaded.GrantedAudience = []string{"tv"}
+	loaded.Session = []byte("{}")
+
+	mock.ExpectExec(
+		fmt.Sprintf("UPDATE %s SET subject=\\?, status=\\?, last_polled_at=\\?, granted_scopes=\\?, granted_audience=\\?, session_data=\\? WHERE id=\\?", oauth2DeviceCodeSessionsTableName)).
+		WithArgs(unitTestUser, models.OAuth2DeviceCodeStatusApproved, int64(1577880100), "openid", "tv", "{}", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, provider.UpdateOAuth2DeviceCodeSession(*loaded))
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, .* FROM %s WHERE signature=\\?", oauth2DeviceCodeSessionsTableName)).
+		WithArgs("abc").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, "abc", "def", "tv", unitTestUser, 1, 1577880001, 1577880601, 1577880100, "openid profile", "openid", "tv", "tv", "{}"))
+
+	loaded, err = provider.LoadOAuth2DeviceCodeSession("abc")
+	require.NoError(t, err)
+	assert.Equal(t, unitTestUser, loaded.Subject)
+	assert.Equal(t, models.OAuth2DeviceCodeStatusApproved, loaded.Status)
+	require.NotNil(t, loaded.LastPolledAt)
+	assert.Equal(t, polledAt, *loaded.LastPolledAt)
+	assert.Equal(t, []byte("{}"), loaded.Session)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, .* FROM %s WHERE signature=\\?", oauth2DeviceCodeSessionsTableName)).
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	loaded, err = provider.LoadOAuth2DeviceCodeSession("missing")
+	assert.EqualError(t, err, "No OAuth 2.0 device code session found")
+	assert.Nil(t, loaded)
+
+	consumeSession := fmt.Sprintf("UPDATE %s SET status=\\? WHERE signature=\\? AND status=\\?", oauth2DeviceCodeSessionsTableName)
+
+	mock.ExpectExec(consumeSession).
+		WithArgs(models.OAuth2DeviceCodeStatusConsumed, "abc", models.OAuth2DeviceCodeStatusApproved).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, provider.ConsumeOAuth2DeviceCodeSession("abc"))
+
+	// The device code has already been consumed.
+	mock.ExpectExec(consumeSession).
+		WithArgs(models.OAuth2DeviceCodeStatusConsumed, "abc", models.OAuth2DeviceCodeStatusApproved).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.Equal(t, ErrNoOAuth2DeviceCodeSession, provider.ConsumeOAuth2DeviceCodeSession("abc"))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLProviderMethodsOAuth2PushedAuthorizeRequests(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	mock.ExpectQuery(
+		"SELECT name FROM sqlite_master WHERE type='table'").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).
+			AddRow(configTableName))
+
+	args := []driver.Value{"schema", "version"}
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT value FROM %s WHERE category=\\? AND key_name=\\?", configTableName)).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"value"}).
+			AddRow(currentSchemaMockSchemaVersion))
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	columns := []string{"id", "signature", "client_id", "requested_at", "expires_at", "form_data"}
+
+	request := models.OAuth2PushedAuthorizeRequest{
+		Signature:   "abc",
+		ClientID:    "grafana",
+		RequestedAt: time.Unix(1577880001, 0),
+		ExpiresAt:   time.Unix(1577880301, 0),
+		Form:        "client_id=grafana&response_type=code",
+	}
+
+	mock.ExpectExec(
+		fmt.Sprintf("INSERT INTO %s \\(signature, client_id, requested_at, expires_at, form_data\\) VALUES \\(\\?, \\?, \\?, \\?, \\?\\)", oauth2PushedAuthorizeRequestsTableName)).
+		WithArgs("abc", "grafana", int64(1577880001), int64(1577880301), "client_id=grafana&response_type=code").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, provider.SaveOAuth2PushedAuthorizeRequest(request))
+
+	selectRequest := fmt.Sprintf("SELECT id, signature, client_id, requested_at, expires_at, form_data FROM %s WHERE signature=\\?", oauth2PushedAuthorizeRequestsTableName)
+	deleteRequest := fmt.Sprintf("DELETE FROM %s WHERE signature=\\?", oauth2PushedAuthorizeRequestsTableName)
+
+	mock.ExpectQuery(selectRequest).
+		WithArgs("abc").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, "abc", "grafana", 1577880001, 1577880301, "client_id=grafana&response_type=code"))
+
+	mock.ExpectExec(deleteRequest).
+		WithArgs("abc").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	loaded, err := provider.ConsumeOAuth2PushedAuthorizeRequest("abc")
+	require.NoError(t, err)
+
+	request.ID = 1
+	assert.Equal(t, request, *loaded)
+
+	mock.ExpectQuery(selectRequest).
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	loaded, err = provider.ConsumeOAuth2PushedAuthorizeRequest("missing")
+	assert.EqualError(t, err, "No OAuth 2.0 pushed authorization request found")
+	assert.Nil(t, loaded)
+
+	// The request was consumed by another caller between the select and the delete.
+	mock.ExpectQuery(selectRequest).
+		WithArgs("abc").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, "abc", "grafana", 1577880001, 1577880301, "client_id=grafana&response_type=code"))
+
+	mock.ExpectExec(deleteRequest).
+		WithArgs("abc").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	loaded, err = provider.ConsumeOAuth2PushedAuthorizeRequest("abc")
+	assert.EqualError(t, err, "No OAuth 2.0 pushed authorization request found")
+	assert.Nil(t, loaded)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLProviderMethodsOAuth2Clients(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	mock.ExpectQuery(
+		"SELECT name FROM sqlite_master WHERE type='table'").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).
+			AddRow(configTableName))
+
+	args := []driver.Value{"schema", "version"}
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT value FROM %s WHERE category=\\? AND key_name=\\?", configTableName)).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"value"}).
+			AddRow(currentSchemaMockSchemaVersion))
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	columns := []string{"id", "client_id", "description", "secret", "redirect_uris", "authorization_policy", "scopes",
+		"audience", "grant_types", "response_types", "response_modes", "id_token_signing_algorithm",
+		"userinfo_signing_algorithm", "consent_mode", "pre_configured_consent_duration", "post_logout_redirect_uris",
+		"frontchannel_logout_uri", "backchannel_logout_uri", "require_pushed_authorization_requests", "require_pkce",
+		"request_object_signing_algorithm", "request_uris", "jwks_uri", "token_endpoint_auth_method", "created_at",
+		"updated_at"}
+
+	client := models.OAuth2Client{
+		ClientID:                      "grafana",
+		Description:                   "Grafana",
+		Secret:                        "$argon2id$hash",
+		RedirectURIs:                  []string{"https://grafana.example.com/login/generic_oauth"},
+		Policy:                        "two_factor",
+		Scopes:                        []string{"openid", "groups"},
+		GrantTypes:                    []string{"authorization_code", "refresh_token"},
+		ResponseTypes:                 []string{"code"},
+		ResponseModes:                 []string{"form_post", "query"},
+		IDTokenSigningAlgorithm:       "RS256",
+		UserinfoSigningAlgorithm:      "none",
+		ConsentMode:                   "pre-configured",
+		PreConfiguredConsentDuration:  time.Hour,
+		PostLogoutRedirectURIs:        []string{"https://grafana.example.com/logout"},
+		BackChannelLogoutURI:          "https://grafana.example.com/backchannel",
+		RequirePKCE:                   true,
+		RequestObjectSigningAlgorithm: "RS256",
+		RequestURIs:                   []string{"https://grafana.example.com/request.jwt"},
+		JWKSURI:                       "https://grafana.example.com/jwks.json",
+		TokenEndpointAuthMethod:       "client_secret_post",
+		CreatedAt:                     time.Unix(1577880001, 0),
+		UpdatedAt:                     time.Unix(1577880001, 0),
+	}
+
+	mock.ExpectExec(
+		fmt.Sprintf("INSERT INTO %s \\(client_id, description, secret, redirect_uris, authorization_policy, scopes, audience, grant_types, response_types, response_modes, id_token_signing_algorithm, userinfo_signing_algorithm, consent_mode, pre_configured_consent_duration, post_logout_redirect_uris, frontchannel_logout_uri, backchannel_logout_uri, require_pushed_authorization_requests, require_pkce, request_object_signing_algorithm, request_uris, jwks_uri, token_endpoint_auth_method, created_at, updated_at\\) VALUES \\(\\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?\\)", oauth2ClientsTableName)).
+		WithArgs("grafana", "Grafana", "$argon2id$hash", "https://grafana.example.com/login/generic_oauth", "two_factor",
+			"openid groups", "", "authorization_code refresh_token", "code", "form_post query", "RS256", "none",
+			"pre-configured", int64(3600), "https://grafana.example.com/logout", "",
+			"https://grafana.example.com/backchannel", false, true, "RS256", "https://grafana.example.com/request.jwt",
+			"https://grafana.example.com/jwks.json", "client_secret_post", int64(1577880001), int64(1577880001)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, provider.SaveOAuth2Client(client))
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, .* FROM %s ORDER BY client_id", oauth2ClientsTableName)).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, "grafana", "Grafana", "$argon2id$hash", "https://grafana.example.com/login/generic_oauth",
+				"two_factor", "openid groups", "", "authorization_code refresh_token", "code", "form_post query",
+				"RS256", "none", "pre-configured", 3600, "https://grafana.example.com/logout", "",
+				"https://grafana.example.com/backchannel", false, true, "RS256", "https://grafana.example.com/request.jwt",
+				"https://grafana.example.com/jwks.json", "client_secret_post", 1577880001, 1577880001))
+
+	clients, err := provider.LoadOAuth2Clients()
+	require.NoError(t, err)
+	require.Len(t, clients, 1)
+	assert.Equal(t, 1, clients[0].ID)
+	assert.Equal(t, client.RedirectURIs, clients[0].RedirectURIs)
+	assert.Equal(t, client.Scopes, clients[0].Scopes)
+	assert.Len(t, clients[0].Audience, 0)
+	assert.Equal(t, time.Hour, clients[0].PreConfiguredConsentDuration)
+	assert.Equal(t, client.PostLogoutRedirectURIs, clients[0].PostLogoutRedirectURIs)
+	assert.Equal(t, client.BackChannelLogoutURI, clients[0].BackChannelLogoutURI)
+	assert.False(t, clients[0].RequirePushedAuthorizationRequests)
+	assert.True(t, clients[0].RequirePKCE)
+	assert.Equal(t, client.RequestURIs, clients[0].RequestURIs)
+	assert.Equal(t, client.JWKSURI, clients[0].JWKSURI)
+	assert.Equal(t, "client_secret_post", clients[0].TokenEndpointAuthMethod)
+	assert.Equal(t, client.CreatedAt, clients[0].CreatedAt)
+
+	client.Description = "Grafana Dashboards"
+	client.UpdatedAt = time.Unix(1577880100, 0)
+
+	mock.ExpectExec(
+		fmt.Sprintf("UPDATE %s SET description=\\?, redirect_uris=\\?, .* WHERE client_id=\\?", oauth2ClientsTableName)).
+		WithArgs("Grafana Dashboards", "https://grafana.example.com/login/generic_oauth", "two_factor",
+			"openid groups", "", "authorization_code refresh_token", "code", "form_post query", "RS256", "none",
+			"pre-configured", int64(3600), "https://grafana.example.com/logout", "",
+			"https://grafana.example.com/backchannel", false, true, "RS256", "https://grafana.example.com/request.jwt",
+			"https://grafana.example.com/jwks.json", "client_secret_post", int64(1577880100), "grafana").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, provider.UpdateOAuth2Client(client))
+
+	mock.ExpectExec(
+		fmt.Sprintf("UPDATE %s SET secret=\\?, updated_at=\\? WHERE client_id=\\?", oauth2ClientsTableName)).
+		WithArgs("$argon2id$rotated", int64(1577880200), "grafana").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, provider.UpdateOAuth2ClientSecret("grafana", "$argon2id$rotated", time.Unix(1577880200, 0)))
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, .* FROM %s WHERE client_id=\\?", oauth2ClientsTableName)).
+		WithArgs("grafana").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, "grafana", "Grafana Dashboards", "$argon2id$rotated", "https://grafana.example.com/login/generic_oauth",
+				"two_factor", "openid groups", "", "authorization_code refresh_token", "code", "form_post query",
+				"RS256", "none", "pre-configured", 3600, "", "", "", false, false, "RS256", "", "", "", 1577880001, 1577880200))
+
+	loaded, err := provider.LoadOAuth2Client("grafana")
+	require.NoError(t, err)
+	assert.Equal(t, "$argon2id$rotated", loaded.Secret)
+	assert.Equal(t, time.Unix(1577880200, 0), loaded.UpdatedAt)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, .* FROM %s WHERE client_id=\\?", oauth2ClientsTableName)).
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	loaded, err = provider.LoadOAuth2Client("missing")
+	assert.EqualError(t, err, "No OpenID Connect client found")
+	assert.Nil(t, loaded)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE client_id=\\?", oauth2ClientsTableName)).
+		WithArgs("grafana").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, provider.DeleteOAuth2Client("grafana"))
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE client_id=\\?", oauth2ClientsTableName)).
+		WithArgs("grafana").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.EqualError(t, provider.DeleteOAuth2Client("grafana"), "No OpenID Connect client found")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLProviderMethodsIdentityVerificationTokens(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	mock.ExpectQuery(
+		"SELECT name FROM sqlite_master WHERE type='table'").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).
+			AddRow(userPreferencesTableName).
+			AddRow(identityVerificationTokensTableName).
+			AddRow(totpConfigurationsTableName).
+			AddRow(webauthnDevicesTableName).
+			AddRow(authenticationLogsTableName).
+			AddRow(configTableName))
+
+	args := []driver.Value{"schema", "version"}
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT value FROM %s WHERE category=\\? AND key_name=\\?", configTableName)).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"value"}).
+			AddRow(currentSchemaMockSchemaVersion))
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	fakeIdentityVerificationToken := "abc"
+
+	mock.ExpectExec(
+		fmt.Sprintf("INSERT INTO %s \\(token\\) VALUES \\(\\?\\)", identityVerificationTokensTableName)).
+		WithArgs(fakeIdentityVerificationToken).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = provider.SaveIdentityVerificationToken(fakeIdentityVerificationToken)
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT EXISTS \\(SELECT \\* FROM %s WHERE token=\\?\\)", identityVerificationTokensTableName)).
+		WithArgs(fakeIdentityVerificationToken).
+		WillReturnRows(sqlmock.NewRows([]string{"EXISTS"}).
+			AddRow(true))
+
+	valid, err := provider.FindIdentityVerificationToken(fakeIdentityVerificationToken)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE token=\\?", identityVerificationTokensTableName)).
+		WithArgs(fakeIdentityVerificationToken).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.RemoveIdentityVerificationToken(fakeIdentityVerificationToken)
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT EXISTS \\(SELECT \\* FROM %s WHERE token=\\?\\)", identityVerificationTokensTableName)).
+		WithArgs(fakeIdentityVerificationToken).
+		WillReturnRows(sqlmock.NewRows([]string{"EXISTS"}).
+			AddRow(false))
+
+	valid, err = provider.FindIdentityVerificationToken(fakeIdentityVerificationToken)
+	assert.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func expectSchemaUpgradeToVersion002(mock sqlmock.Sqlmock, u2fRows *sqlmock.Rows, migrations int) {
+	mock.ExpectExec(
+		fmt.Sprintf("CREATE TABLE %s .*", webauthnDevicesTableName)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT username, keyHandle, publicKey FROM %s", u2fDeviceHandlesTableName)).
+		WillReturnRows(u2fRows)
+
+	for i := 0; i < migrations; i++ {
+		mock.ExpectExec(
+			fmt.Sprintf("REPLACE INTO %s .*", webauthnDevicesTableName)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+
+	mock.ExpectExec(
+		fmt.Sprintf("UPDATE %s SET second_factor_method=\\? WHERE second_factor_method=\\?", userPreferencesTableName)).
+		WithArgs("webauthn", "u2f").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(
+		fmt.Sprintf("DROP TABLE %s", u2fDeviceHandlesTableName)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(
+		fmt.Sprintf("REPLACE INTO %s \\(category, key_name, value\\) VALUES \\(\\?, \\?, \\?\\)", configTableName)).
+		WithArgs("schema", "version", "2").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func expectSchemaUpgradeToVersion003(mock sqlmock.Sqlmock, totpRows *sqlmock.Rows, migrations int) {
+	mock.ExpectExec(
+		fmt.Sprintf("CREATE TABLE %s .*", totpConfigurationsTableName)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT username, secret FROM %s", totpSecretsTableName)).
+		WillReturnRows(totpRows)
+
+	for i := 0; i < migrations; i++ {
+		mock.ExpectExec(
+			fmt.Sprintf("INSERT INTO %s .*", totpConfigurationsTableName)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+
+	mock.ExpectExec(
+		fmt.Sprintf("DROP TABLE %s", totpSecretsTableName)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(
+		fmt.Sprintf("REPLACE INTO %s \\(category, key_name, value\\) VALUES \\(\\?, \\?, \\?\\)", configTableName)).
+		WithArgs("schema", "version", "3").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func expectSchemaUpgradeToVersion004(mock sqlmock.Sqlmock) {
+	for _, table := range []string{
+		oauth2AccessTokenSessionsTableName,
+		oauth2AuthorizeCodeSessionsTableName,
+		oauth2BlacklistedJTIsTableName,
+		oauth2OpenIDConnectSessionsTableName,
+		oauth2PKCERequestSessionsTableName,
+		oauth2RefreshTokenSessionsTableName,
+	} {
+		mock.ExpectExec(
+			fmt.Sprintf("CREATE TABLE %s .*", table)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	for _, table := range []string{
+		oauth2AuthorizeCodeSessionsTableName,
+		oauth2AccessTokenSessionsTableName,
+		oauth2RefreshTokenSessionsTableName,
+		oauth2PKCERequestSessionsTableName,
+		oauth2OpenIDConnectSessionsTableName,
+	} {
+		mock.ExpectExec(
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_request_id_idx ON %s .*", table, table)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	mock.ExpectExec(
+		fmt.Sprintf("REPLACE INTO %s \\(category, key_name, value\\) VALUES \\(\\?, \\?, \\?\\)", configTableName)).
+		WithArgs("schema", "version", "4").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func expectSchemaUpgradeToVersion005(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(
+		fmt.Sprintf("CREATE TABLE %s .*", oauth2ConsentSessionsTableName)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS oauth2_consent_sessions_subject_idx ON %s .*", oauth2ConsentSessionsTableName)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(
+		fmt.Sprintf("REPLACE INTO %s \\(category, key_name, value\\) VALUES \\(\\?, \\?, \\?\\)", configTableName)).
+		WithArgs("schema", "version", "5").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func expectSchemaUpgradeToVersion006(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(
+		fmt.Sprintf("CREATE TABLE %s .*", oauth2DeviceCodeSessionsTableName)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(
+		fmt.Sprintf("REPLACE INTO %s \\(category, key_name, value\\) VALUES \\(\\?, \\?, \\?\\)", configTableName)).
+		WithArgs("schema", "version", "6").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func expectSchemaUpgradeToVersion007(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(
+		fmt.Sprintf("CREATE TABLE %s .*", oauth2ClientsTableName)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(
+		fmt.Sprintf("REPLACE INTO %s \\(category, key_name, value\\) VALUES \\(\\?, \\?, \\?\\)", configTableName)).
+		WithArgs("schema", "version", "7").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func expectSchemaUpgradeToVersion008(mock sqlmock.Sqlmock) {
+	for _, column := range []string{"post_logout_redirect_uris", "frontchannel_logout_uri", "backchannel_logout_uri"} {
+		mock.ExpectExec(
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s TEXT NOT NULL DEFAULT ''", oauth2ClientsTableName, column)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	mock.ExpectExec(
+		fmt.Sprintf("REPLACE INTO %s \\(category, key_name, value\\) VALUES \\(\\?, \\?, \\?\\)", configTableName)).
+		WithArgs("schema", "version", "8").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func expectSchemaUpgradeToVersion009(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(
+		fmt.Sprintf("CREATE TABLE %s .*", oauth2PushedAuthorizeRequestsTableName)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	for _, column := range []string{
+		"require_pushed_authorization_requests BOOLEAN NOT NULL DEFAULT FALSE",
+		"require_pkce BOOLEAN NOT NULL DEFAULT FALSE",
+		"request_object_signing_algorithm VARCHAR\\(16\\) NOT NULL DEFAULT ''",
+		"request_uris TEXT NOT NULL DEFAULT ''",
+		"jwks_uri TEXT NOT NULL DEFAULT ''",
+	} {
+		mock.ExpectExec(
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", oauth2ClientsTableName, column)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	mock.ExpectExec(
+		fmt.Sprintf("REPLACE INTO %s \\(category, key_name, value\\) VALUES \\(\\?, \\?, \\?\\)", configTableName)).
+		WithArgs("schema", "version", "9").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func expectSchemaUpgradeToVersion010(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(
+		fmt.Sprintf("CREATE TABLE %s .*", regulationBansTableName)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN remote_ip VARCHAR\\(32\\) NULL", authenticationLogsTableName)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS remote_ip_time_idx ON %s .*", authenticationLogsTableName)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS regulation_bans_value_idx ON %s .*", regulationBansTableName)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(
+		fmt.Sprintf("REPLACE INTO %s \\(category, key_name, value\\) VALUES \\(\\?, \\?, \\?\\)", configTableName)).
+		WithArgs("schema", "version", "10").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func expectSchemaUpgradeToVersion011(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN admin VARCHAR\\(100\\) NULL", authenticationLogsTableName)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(
+		fmt.Sprintf("REPLACE INTO %s \\(category, key_name, value\\) VALUES \\(\\?, \\?, \\?\\)", configTableName)).
+		WithArgs("schema", "version", "11").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func expectSchemaUpgradeToVersion012(mock sqlmock.Sqlmock) {
+	for _, table := range []string{
+		userAttributesTableName,
+		userEmailsTableName,
+		userGroupsTableName,
+		usersTableName,
+	} {
+		mock.ExpectExec(
+			fmt.Sprintf("CREATE TABLE %s .*", table)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	mock.ExpectExec(
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS user_attributes_username_idx ON %s .*", userAttributesTableName)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(
+		fmt.Sprintf("REPLACE INTO %s \\(category, key_name, value\\) VALUES \\(\\?, \\?, \\?\\)", configTableName)).
+		WithArgs("schema", "version", "12").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func expectSchemaUpgradeToVersion013(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN token_endpoint_auth_method VARCHAR\\(32\\) NOT NULL DEFAULT ''", oauth2ClientsTableName)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(
+		fmt.Sprintf("REPLACE INTO %s \\(category, key_name, value\\) VALUES \\(\\?, \\?, \\?\\)", configTableName)).
+		WithArgs("schema", "version", "13").
+		WillReturnResult(sqlmock.NewResult(1, 1))
 }
@@ -15,4 +15,57 @@ func (s SchemaVersion) ToString() string {
 
 type transaction interface {
 	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// OAuth2SessionType represents the potential OAuth 2.0 session types.
+type OAuth2SessionType int
+
+// Representation of specific OAuth 2.0 session types.
+const (
+	OAuth2SessionTypeAuthorizeCode OAuth2SessionType = iota
+	OAuth2SessionTypeAccessToken
+	OAuth2SessionTypeRefreshToken
+	OAuth2SessionTypePKCEChallenge
+	OAuth2SessionTypeOpenIDConnect
+)
+
+// String returns a human readable representation of the OAuth2SessionType.
+func (s OAuth2SessionType) String() string {
+	switch s {
+	case OAuth2SessionTypeAuthorizeCode:
+		return "authorization code"
+	case OAuth2SessionTypeAccessToken:
+		return "access token"
+	case OAuth2SessionTypeRefreshToken:
+		return "refresh token"
+	case OAuth2SessionTypePKCEChallenge:
+		return "pkce challenge"
+	case OAuth2SessionTypeOpenIDConnect:
+		return "openid connect"
+	default:
+		return "invalid"
+	}
+}
+
+// Table returns the name of the table which stores the OAuth2SessionType.
+func (s OAuth2SessionType) Table() string {
+	switch s {
+	case OAuth2SessionTypeAuthorizeCode:
+		return oauth2AuthorizeCodeSessionsTableName
+	case OAuth2SessionTypeAccessToken:
+		return oauth2AccessTokenSessionsTableName
+	case OAuth2SessionTypeRefreshToken:
+		return oauth2RefreshTokenSessionsTableName
+	case OAuth2SessionTypePKCEChallenge:
+		return oauth2PKCERequestSessionsTableName
+	case OAuth2SessionTypeOpenIDConnect:
+		return oauth2OpenIDConnectSessionsTableName
+	default:
+		return ""
+	}
 }
@@ -3,7 +3,11 @@ package storage
 import (
 	"fmt"
 	"sort"
+	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/authelia/authelia/internal/models"
 	"github.com/authelia/authelia/internal/utils"
 )
 
@@ -74,3 +78,363 @@ func (p *SQLProvider) upgradeSchemaToVersion001(tx transaction, tables []string)
 
 	return nil
 }
+
+// upgradeSchemaToVersion002 upgrades the schema to version 2. This adds the Webauthn devices table and migrates the
+// existing U2F device handles to it, as well as the preferred second factor method of users who had chosen U2F.
+func (p *SQLProvider) upgradeSchemaToVersion002(tx transaction, tables []string) error {
+	version := SchemaVersion(2)
+
+	err := p.upgradeCreateTableStatements(tx, p.sqlUpgradesCreateTableStatements[version], tables)
+	if err != nil {
+		return err
+	}
+
+	err = p.upgradeMigrateU2FDeviceHandles(tx)
+	if err != nil {
+		return fmt.Errorf("Unable to migrate U2F devices: %v", err)
+	}
+
+	_, err = tx.Exec(p.sqlUpgradeRenameSecondFactorMethod, "webauthn", "u2f")
+	if err != nil {
+		return fmt.Errorf("Unable to migrate U2F preferences: %v", err)
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("DROP TABLE %s", u2fDeviceHandlesTableName))
+	if err != nil {
+		return fmt.Errorf("Unable to drop table %s: %v", u2fDeviceHandlesTableName, err)
+	}
+
+	err = p.upgradeFinalize(tx, version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// upgradeMigrateU2FDeviceHandles copies every U2F device handle into the Webauthn devices table. The raw U2F public
+// key is kept as is with the fido-u2f attestation type so it can be verified using the appid extension.
+func (p *SQLProvider) upgradeMigrateU2FDeviceHandles(tx transaction) error {
+	rows, err := tx.Query(p.sqlUpgradeGetU2FDeviceHandles)
+	if err != nil {
+		return err
+	}
+
+	var (
+		username, keyHandle, publicKey string
+		devices                        [][]string
+	)
+
+	for rows.Next() {
+		if err = rows.Scan(&username, &keyHandle, &publicKey); err != nil {
+			_ = rows.Close()
+
+			return err
+		}
+
+		devices = append(devices, []string{username, keyHandle, publicKey})
+	}
+
+	if err = rows.Close(); err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+
+	for _, device := range devices {
+		_, err = tx.Exec(p.sqlUpsertWebauthnDevice, now, nil, device[0], models.WebauthnDeviceDefaultDescription,
+			device[1], device[2], models.WebauthnAttestationTypeFIDOU2F, uuid.Nil.String(), 0, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// upgradeSchemaToVersion003 upgrades the schema to version 3. This adds the TOTP configurations table which allows
+// multiple named TOTP configurations per user and migrates the existing TOTP secrets to it.
+func (p *SQLProvider) upgradeSchemaToVersion003(tx transaction, tables []string) error {
+	version := SchemaVersion(3)
+
+	err := p.upgradeCreateTableStatements(tx, p.sqlUpgradesCreateTableStatements[version], tables)
+	if err != nil {
+		return err
+	}
+
+	err = p.upgradeMigrateTOTPSecrets(tx)
+	if err != nil {
+		return fmt.Errorf("Unable to migrate TOTP secrets: %v", err)
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("DROP TABLE %s", totpSecretsTableName))
+	if err != nil {
+		return fmt.Errorf("Unable to drop table %s: %v", totpSecretsTableName, err)
+	}
+
+	err = p.upgradeFinalize(tx, version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// upgradeMigrateTOTPSecrets copies every TOTP secret into the TOTP configurations table with the default description.
+func (p *SQLProvider) upgradeMigrateTOTPSecrets(tx transaction) error {
+	rows, err := tx.Query(p.sqlUpgradeGetTOTPSecrets)
+	if err != nil {
+		return err
+	}
+
+	var (
+		username, secret string
+		secrets          [][]string
+	)
+
+	for rows.Next() {
+		if err = rows.Scan(&username, &secret); err != nil {
+			_ = rows.Close()
+
+			return err
+		}
+
+		secrets = append(secrets, []string{username, secret})
+	}
+
+	if err = rows.Close(); err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+
+	for _, s := range secrets {
+		_, err = tx.Exec(p.sqlInsertTOTPConfiguration, now, nil, s[0], models.TOTPConfigurationDefaultDescription, s[1])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// upgradeSchemaToVersion004 upgrades the schema to version 4. This adds the tables used to persist the OpenID Connect
+// provider sessions.
+func (p *SQLProvider) upgradeSchemaToVersion004(tx transaction, tables []string) error {
+	version := SchemaVersion(4)
+
+	err := p.upgradeCreateTableStatements(tx, p.sqlUpgradesCreateTableStatements[version], tables)
+	if err != nil {
+		return err
+	}
+
+	// Skip mysql create index statements, the indexes are created with the tables instead.
+	if p.name != "mysql" {
+		err = p.upgradeRunMultipleStatements(tx, p.sqlUpgradesCreateTableIndexesStatements[version])
+		if err != nil {
+			return fmt.Errorf("Unable to create index: %v", err)
+		}
+	}
+
+	err = p.upgradeFinalize(tx, version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// upgradeSchemaToVersion005 upgrades the schema to version 5. This adds the table used to persist the consent users
+// have pre-configured for OpenID Connect clients.
+func (p *SQLProvider) upgradeSchemaToVersion005(tx transaction, tables []string) error {
+	version := SchemaVersion(5)
+
+	err := p.upgradeCreateTableStatements(tx, p.sqlUpgradesCreateTableStatements[version], tables)
+	if err != nil {
+		return err
+	}
+
+	// Skip mysql create index statements, the indexes are created with the tables instead.
+	if p.name != "mysql" {
+		err = p.upgradeRunMultipleStatements(tx, p.sqlUpgradesCreateTableIndexesStatements[version])
+		if err != nil {
+			return fmt.Errorf("Unable to create index: %v", err)
+		}
+	}
+
+	err = p.upgradeFinalize(tx, version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// upgradeSchemaToVersion006 upgrades the schema to version 6. This adds the table used to persist the OAuth 2.0 device
+// authorization requests.
+func (p *SQLProvider) upgradeSchemaToVersion006(tx transaction, tables []string) error {
+	version := SchemaVersion(6)
+
+	err := p.upgradeCreateTableStatements(tx, p.sqlUpgradesCreateTableStatements[version], tables)
+	if err != nil {
+		return err
+	}
+
+	err = p.upgradeFinalize(tx, version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// upgradeSchemaToVersion007 upgrades the schema to version 7. This adds the table used to persist the OpenID Connect
+// clients registered at runtime.
+func (p *SQLProvider) upgradeSchemaToVersion007(tx transaction, tables []string) error {
+	version := SchemaVersion(7)
+
+	err := p.upgradeCreateTableStatements(tx, p.sqlUpgradesCreateTableStatements[version], tables)
+	if err != nil {
+		return err
+	}
+
+	err = p.upgradeFinalize(tx, version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// upgradeSchemaToVersion008 upgrades the schema to version 8. This adds the logout columns of the OpenID Connect clients.
+func (p *SQLProvider) upgradeSchemaToVersion008(tx transaction) error {
+	version := SchemaVersion(8)
+
+	err := p.upgradeRunMultipleStatements(tx, p.sqlUpgradeAddOAuth2ClientLogoutColumns)
+	if err != nil {
+		return fmt.Errorf("Unable to add the logout columns to table %s: %v", oauth2ClientsTableName, err)
+	}
+
+	err = p.upgradeFinalize(tx, version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// upgradeSchemaToVersion009 upgrades the schema to version 9. This adds the table used to persist the pushed
+// authorization requests and the columns of the OpenID Connect clients related to the authorization requests.
+func (p *SQLProvider) upgradeSchemaToVersion009(tx transaction, tables []string) error {
+	version := SchemaVersion(9)
+
+	err := p.upgradeCreateTableStatements(tx, p.sqlUpgradesCreateTableStatements[version], tables)
+	if err != nil {
+		return err
+	}
+
+	err = p.upgradeRunMultipleStatements(tx, p.sqlUpgradeAddOAuth2ClientAuthorizeRequestColumns)
+	if err != nil {
+		return fmt.Errorf("Unable to add the authorization request columns to table %s: %v", oauth2ClientsTableName, err)
+	}
+
+	err = p.upgradeFinalize(tx, version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// upgradeSchemaToVersion010 upgrades the schema to version 10. This adds the table used to persist the regulation bans
+// and the remote IP column of the authentication logs.
+func (p *SQLProvider) upgradeSchemaToVersion010(tx transaction, tables []string) error {
+	version := SchemaVersion(10)
+
+	err := p.upgradeCreateTableStatements(tx, p.sqlUpgradesCreateTableStatements[version], tables)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(p.sqlUpgradeAddAuthenticationLogRemoteIP)
+	if err != nil {
+		return fmt.Errorf("Unable to add the remote_ip column to table %s: %v", authenticationLogsTableName, err)
+	}
+
+	// Skip mysql create index statements, the indexes are created with the tables and columns instead.
+	if p.name != "mysql" {
+		err = p.upgradeRunMultipleStatements(tx, p.sqlUpgradesCreateTableIndexesStatements[version])
+		if err != nil {
+			return fmt.Errorf("Unable to create index: %v", err)
+		}
+	}
+
+	err = p.upgradeFinalize(tx, version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// upgradeSchemaToVersion011 upgrades the schema to version 11. This adds the column of the authentication logs holding
+// the administrator who recorded a ban or an unban.
+func (p *SQLProvider) upgradeSchemaToVersion011(tx transaction) error {
+	version := SchemaVersion(11)
+
+	_, err := tx.Exec(p.sqlUpgradeAddAuthenticationLogAdmin)
+	if err != nil {
+		return fmt.Errorf("Unable to add the admin column to table %s: %v", authenticationLogsTableName, err)
+	}
+
+	err = p.upgradeFinalize(tx, version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// upgradeSchemaToVersion012 upgrades the schema to version 12. This adds the tables used to persist the users of the
+// SQL authentication backend, their emails, groups and attributes.
+func (p *SQLProvider) upgradeSchemaToVersion012(tx transaction, tables []string) error {
+	version := SchemaVersion(12)
+
+	err := p.upgradeCreateTableStatements(tx, p.sqlUpgradesCreateTableStatements[version], tables)
+	if err != nil {
+		return err
+	}
+
+	// Skip mysql create index statements, the indexes are created with the tables instead.
+	if p.name != "mysql" {
+		err = p.upgradeRunMultipleStatements(tx, p.sqlUpgradesCreateTableIndexesStatements[version])
+		if err != nil {
+			return fmt.Errorf("Unable to create index: %v", err)
+		}
+	}
+
+	err = p.upgradeFinalize(tx, version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// upgradeSchemaToVersion013 upgrades the schema to version 13. This adds the column of the OpenID Connect clients holding
+// the authentication method the clients must use at the token endpoint.
+func (p *SQLProvider) upgradeSchemaToVersion013(tx transaction) error {
+	version := SchemaVersion(13)
+
+	_, err := tx.Exec(p.sqlUpgradeAddOAuth2ClientTokenEndpointAuthMethod)
+	if err != nil {
+		return fmt.Errorf("Unable to add the token_endpoint_auth_method column to table %s: %v", oauth2ClientsTableName, err)
+	}
+
+	err = p.upgradeFinalize(tx, version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,306 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/authelia/authelia/internal/models"
+)
+
+func scanWebauthnDevice(row scanner) (device models.WebauthnDevice, err error) {
+	var (
+		createdAt              int64
+		lastUsedAt             sql.NullInt64
+		kid, publicKey, aaguid string
+	)
+
+	err = row.Scan(&device.ID, &createdAt, &lastUsedAt, &device.Username, &device.Description,
+		&kid, &publicKey, &device.AttestationType, &aaguid, &device.SignCount, &device.CloneWarning)
+	if err != nil {
+		return device, err
+	}
+
+	device.CreatedAt = time.Unix(createdAt, 0)
+
+	if lastUsedAt.Valid {
+		t := time.Unix(lastUsedAt.Int64, 0)
+		device.LastUsedAt = &t
+	}
+
+	if device.KID, err = base64.StdEncoding.DecodeString(kid); err != nil {
+		return device, err
+	}
+
+	if device.PublicKey, err = base64.StdEncoding.DecodeString(publicKey); err != nil {
+		return device, err
+	}
+
+	if device.AAGUID, err = uuid.Parse(aaguid); err != nil {
+		return device, err
+	}
+
+	return device, nil
+}
+
+func scanTOTPConfiguration(row scanner) (config models.TOTPConfiguration, err error) {
+	var (
+		createdAt  int64
+		lastUsedAt sql.NullInt64
+	)
+
+	err = row.Scan(&config.ID, &createdAt, &lastUsedAt, &config.Username, &config.Description, &config.Secret)
+	if err != nil {
+		return config, err
+	}
+
+	config.CreatedAt = time.Unix(createdAt, 0)
+
+	if lastUsedAt.Valid {
+		t := time.Unix(lastUsedAt.Int64, 0)
+		config.LastUsedAt = &t
+	}
+
+	return config, nil
+}
+
+func scanOAuth2Session(row scanner) (session models.OAuth2Session, err error) {
+	var (
+		requestedAt                                                        int64
+		expiresAt                                                          sql.NullInt64
+		requestedScopes, grantedScopes, requestedAudience, grantedAudience string
+		sessionData                                                        string
+	)
+
+	err = row.Scan(&session.ID, &session.Signature, &session.RequestID, &session.ClientID, &session.Subject,
+		&requestedAt, &expiresAt, &requestedScopes, &grantedScopes, &requestedAudience, &grantedAudience,
+		&session.Form, &sessionData, &session.Active)
+	if err != nil {
+		return session, err
+	}
+
+	session.RequestedAt = time.Unix(requestedAt, 0)
+
+	if expiresAt.Valid {
+		t := time.Unix(expiresAt.Int64, 0)
+		session.ExpiresAt = &t
+	}
+
+	session.RequestedScopes = strings.Fields(requestedScopes)
+	session.GrantedScopes = strings.Fields(grantedScopes)
+	session.RequestedAudience = strings.Fields(requestedAudience)
+	session.GrantedAudience = strings.Fields(grantedAudience)
+
+	session.Session = []byte(sessionData)
+
+	return session, nil
+}
+
+func scanOAuth2ConsentSession(row scanner) (consent models.OAuth2ConsentSession, err error) {
+	var (
+		createdAt, expiresAt           int64
+		grantedScopes, grantedAudience string
+	)
+
+	err = row.Scan(&consent.ID, &consent.ClientID, &consent.Subject, &createdAt, &expiresAt, &grantedScopes, &grantedAudience)
+	if err != nil {
+		return consent, err
+	}
+
+	consent.CreatedAt = time.Unix(createdAt, 0)
+	consent.ExpiresAt = time.Unix(expiresAt, 0)
+	consent.GrantedScopes = strings.Fields(grantedScopes)
+	consent.GrantedAudience = strings.Fields(grantedAudience)
+
+	return consent, nil
+}
+
+func scanOAuth2DeviceCodeSession(row scanner) (session models.OAuth2DeviceCodeSession, err error) {
+	var (
+		requestedAt, expiresAt                                             int64
+		lastPolledAt                                                       sql.NullInt64
+		requestedScopes, grantedScopes, requestedAudience, grantedAudience string
+		sessionData                                                        string
+	)
+
+	err = row.Scan(&session.ID, &session.Signature, &session.UserCodeSignature, &session.ClientID, &session.Subject,
+		&session.Status, &requestedAt, &expiresAt, &lastPolledAt, &requestedScopes, &grantedScopes, &requestedAudience,
+		&grantedAudience, &sessionData)
+	if err != nil {
+		return session, err
+	}
+
+	session.RequestedAt = time.Unix(requestedAt, 0)
+	session.ExpiresAt = time.Unix(expiresAt, 0)
+
+	if lastPolledAt.Valid {
+		t := time.Unix(lastPolledAt.Int64, 0)
+		session.LastPolledAt = &t
+	}
+
+	session.RequestedScopes = strings.Fields(requestedScopes)
+	session.GrantedScopes = strings.Fields(grantedScopes)
+	session.RequestedAudience = strings.Fields(requestedAudience)
+	session.GrantedAudience = strings.Fields(grantedAudience)
+
+	session.Session = []byte(sessionData)
+
+	return session, nil
+}
+
+func nullUnixTime(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+
+	return sql.NullInt64{Int64: t.Unix(), Valid: true}
+}
+
+func scanOAuth2Client(row scanner) (client models.OAuth2Client, err error) {
+	var (
+		redirectURIs, scopes, audience, grantTypes, responseTypes, responseModes, postLogoutRedirectURIs, requestURIs string
+		preConfiguredConsentDuration, createdAt, updatedAt                                                            int64
+	)
+
+	err = row.Scan(&client.ID, &client.ClientID, &client.Description, &client.Secret, &redirectURIs, &client.Policy,
+		&scopes, &audience, &grantTypes, &responseTypes, &responseModes, &client.IDTokenSigningAlgorithm,
+		&client.UserinfoSigningAlgorithm, &client.ConsentMode, &preConfiguredConsentDuration, &postLogoutRedirectURIs,
+		&client.FrontChannelLogoutURI, &client.BackChannelLogoutURI, &client.RequirePushedAuthorizationRequests,
+		&client.RequirePKCE, &client.RequestObjectSigningAlgorithm, &requestURIs, &client.JWKSURI,
+		&client.TokenEndpointAuthMethod, &createdAt, &updatedAt)
+	if err != nil {
+		return client, err
+	}
+
+	client.RedirectURIs = strings.Fields(redirectURIs)
+	client.Scopes = strings.Fields(scopes)
+	client.Audience = strings.Fields(audience)
+	client.GrantTypes = strings.Fields(grantTypes)
+	client.ResponseTypes = strings.Fields(responseTypes)
+	client.ResponseModes = strings.Fields(responseModes)
+	client.PreConfiguredConsentDuration = time.Duration(preConfiguredConsentDuration) * time.Second
+	client.PostLogoutRedirectURIs = strings.Fields(postLogoutRedirectURIs)
+	client.RequestURIs = strings.Fields(requestURIs)
+	client.CreatedAt = time.Unix(createdAt, 0)
+	client.UpdatedAt = time.Unix(updatedAt, 0)
+
+	return client, nil
+}
+
+func checkOAuth2ClientAffected(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNoOAuth2Client
+	}
+
+	return nil
+}
+
+// nullIP returns the value of the remote_ip column of an IP address, i.e. the hexadecimal encoding of its 16-byte form.
+// The encoding has a fixed width so the IP addresses of a network are a range of the values of the column.
+func nullIP(ip net.IP) sql.NullString {
+	if ip == nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: hex.EncodeToString(ip.To16()), Valid: true}
+}
+
+// parseIP parses the value of the remote_ip column.
+func parseIP(value string) net.IP {
+	ip, err := hex.DecodeString(value)
+	if err != nil || len(ip) != net.IPv6len {
+		return nil
+	}
+
+	return ip
+}
+
+// ipRange returns the values of the remote_ip column of the first and the last IP addresses of a network.
+func ipRange(network *net.IPNet) (first, last string) {
+	firstIP := network.IP.Mask(network.Mask)
+	lastIP := make(net.IP, len(firstIP))
+
+	for i := range firstIP {
+		lastIP[i] = firstIP[i] | ^network.Mask[i]
+	}
+
+	return nullIP(firstIP).String, nullIP(lastIP).String
+}
+
+func scanAuthenticationAttemptsWithRemoteIP(rows *sql.Rows) (attempts []models.AuthenticationAttempt, err error) {
+	defer rows.Close()
+
+	attempts = make([]models.AuthenticationAttempt, 0, 10)
+
+	for rows.Next() {
+		var (
+			t        int64
+			remoteIP string
+			admin    sql.NullString
+		)
+
+		attempt := models.AuthenticationAttempt{}
+
+		if err = rows.Scan(&attempt.Username, &attempt.Successful, &remoteIP, &t, &admin); err != nil {
+			return nil, err
+		}
+
+		attempt.Time = time.Unix(t, 0)
+		attempt.RemoteIP = parseIP(remoteIP)
+		attempt.Admin = admin.String
+
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts, rows.Err()
+}
+
+func nullString(value string) sql.NullString {
+	if value == "" {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: value, Valid: true}
+}
+
+func scanRegulationBan(row scanner) (ban models.RegulationBan, err error) {
+	var createdAt, expiresAt int64
+
+	err = row.Scan(&ban.ID, &ban.Type, &ban.Value, &ban.Reason, &createdAt, &expiresAt, &ban.Revoked)
+	if err != nil {
+		return ban, err
+	}
+
+	ban.CreatedAt = time.Unix(createdAt, 0)
+	ban.ExpiresAt = time.Unix(expiresAt, 0)
+
+	return ban, nil
+}
+
+func scanUser(row scanner) (user models.User, err error) {
+	var createdAt, updatedAt int64
+
+	err = row.Scan(&user.ID, &user.Username, &user.DisplayName, &user.Password, &user.Disabled, &createdAt, &updatedAt)
+	if err != nil {
+		return user, err
+	}
+
+	user.CreatedAt = time.Unix(createdAt, 0)
+	user.UpdatedAt = time.Unix(updatedAt, 0)
+
+	return user, nil
+}
@@ -73,9 +73,17 @@ func (s *StandaloneWebDriverSuite) TestShouldCheckUserIsAskedToRegisterDevice()
 	username := "john"
 	password := "password"
 
-	// Clean up any TOTP secret already in DB.
+	// Clean up any TOTP configuration already in DB.
 	provider := storage.NewSQLiteProvider("/tmp/db.sqlite3")
-	require.NoError(s.T(), provider.DeleteTOTPSecret(username))
+
+	configs, err := provider.LoadTOTPConfigurationsByUsername(username)
+	if err != storage.ErrNoTOTPConfiguration {
+		require.NoError(s.T(), err)
+	}
+
+	for _, config := range configs {
+		require.NoError(s.T(), provider.DeleteTOTPConfiguration(username, config.ID))
+	}
 
 	// Login one factor.
 	s.doLoginOneFactor(ctx, s.T(), username, password, false, "")
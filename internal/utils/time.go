@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -49,3 +50,33 @@ func ParseDurationString(input string) (time.Duration, error) {
 
 	return duration, nil
 }
+
+// ParseTimeOfDay parses a time of day in the 24-hour HH:MM notation and returns the duration since midnight, the
+// special value 24:00 represents the end of the day.
+func ParseTimeOfDay(input string) (time.Duration, error) {
+	if input == "24:00" {
+		return Day, nil
+	}
+
+	t, err := time.Parse("15:04", input)
+	if err != nil {
+		return 0, fmt.Errorf("could not convert the input string of %s into a time of day, it must be in the HH:MM format", input)
+	}
+
+	return time.Duration(t.Hour())*Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// ParseWeekday parses the english name of a day of the week or its three letter abbreviation case-insensitively.
+func ParseWeekday(input string) (time.Weekday, error) {
+	input = strings.ToLower(input)
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+
+		if input == name || input == name[:3] {
+			return day, nil
+		}
+	}
+
+	return 0, fmt.Errorf("could not convert the input string of %s into a day of the week", input)
+}
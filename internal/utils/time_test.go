@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -76,3 +77,37 @@ func TestShouldTimeIntervalsMakeSense(t *testing.T) {
 	assert.Equal(t, Year, Day*365)
 	assert.Equal(t, Month, Year/12)
 }
+
+func TestShouldParseTimeOfDay(t *testing.T) {
+	duration, err := ParseTimeOfDay("00:00")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), duration)
+
+	duration, err = ParseTimeOfDay("09:30")
+	assert.NoError(t, err)
+	assert.Equal(t, 9*Hour+30*time.Minute, duration)
+
+	duration, err = ParseTimeOfDay("24:00")
+	assert.NoError(t, err)
+	assert.Equal(t, Day, duration)
+}
+
+func TestShouldNotParseInvalidTimeOfDay(t *testing.T) {
+	for _, input := range []string{"", "9", "25:00", "12:60", "9am"} {
+		_, err := ParseTimeOfDay(input)
+		assert.EqualError(t, err, fmt.Sprintf("could not convert the input string of %s into a time of day, it must be in the HH:MM format", input))
+	}
+}
+
+func TestShouldParseWeekday(t *testing.T) {
+	day, err := ParseWeekday("monday")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Monday, day)
+
+	day, err = ParseWeekday("Sat")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Saturday, day)
+
+	_, err = ParseWeekday("weekend")
+	assert.EqualError(t, err, "could not convert the input string of weekend into a day of the week")
+}
@@ -8,11 +8,13 @@ import NotificationBar from "@components/NotificationBar";
 import {
     FirstFactorRoute,
     ResetPasswordStep2Route,
+    PasswordChangeRoute,
     ResetPasswordStep1Route,
     RegisterSecurityKeyRoute,
     RegisterOneTimePasswordRoute,
     LogoutRoute,
     ConsentRoute,
+    DeviceRoute,
 } from "@constants/Routes";
 import NotificationsContext from "@hooks/NotificationsContext";
 import { Notification } from "@models/Notifications";
@@ -22,6 +24,7 @@ import { getRememberMe, getResetPassword, getTheme } from "@utils/Configuration"
 import RegisterOneTimePassword from "@views/DeviceRegistration/RegisterOneTimePassword";
 import RegisterSecurityKey from "@views/DeviceRegistration/RegisterSecurityKey";
 import ConsentView from "@views/LoginPortal/ConsentView/ConsentView";
+import DeviceView from "@views/LoginPortal/DeviceView/DeviceView";
 import LoginPortal from "@views/LoginPortal/LoginPortal";
 import SignOut from "@views/LoginPortal/SignOut/SignOut";
 import ResetPasswordStep1 from "@views/ResetPassword/ResetPasswordStep1";
@@ -71,6 +74,9 @@ const App: React.FC = () => {
                         <Route path={ResetPasswordStep2Route} exact>
                             <ResetPasswordStep2 />
                         </Route>
+                        <Route path={PasswordChangeRoute} exact>
+                            <ResetPasswordStep2 passwordChange />
+                        </Route>
                         <Route path={RegisterSecurityKeyRoute} exact>
                             <RegisterSecurityKey />
                         </Route>
@@ -83,6 +89,9 @@ const App: React.FC = () => {
                         <Route path={ConsentRoute} exact>
                             <ConsentView />
                         </Route>
+                        <Route path={DeviceRoute} exact>
+                            <DeviceView />
+                        </Route>
                         <Route path={FirstFactorRoute}>
                             <LoginPortal rememberMe={getRememberMe()} resetPassword={getResetPassword()} />
                         </Route>
@@ -1,14 +1,17 @@
 export const FirstFactorRoute: string = "/";
 export const AuthenticatedRoute: string = "/authenticated";
 export const ConsentRoute: string = "/consent";
+// Note: If you change this const you must also do so in the backend at internal/handlers/const.go.
+export const DeviceRoute: string = "/device";
 
 export const SecondFactorRoute: string = "/2fa";
-export const SecondFactorU2FRoute: string = "/2fa/security-key";
+export const SecondFactorWebauthnRoute: string = "/2fa/security-key";
 export const SecondFactorTOTPRoute: string = "/2fa/one-time-password";
 export const SecondFactorPushRoute: string = "/2fa/push-notification";
 
 export const ResetPasswordStep1Route: string = "/reset-password/step1";
 export const ResetPasswordStep2Route: string = "/reset-password/step2";
+export const PasswordChangeRoute: string = "/password/change";
 export const RegisterSecurityKeyRoute: string = "/security-key/register";
 export const RegisterOneTimePasswordRoute: string = "/one-time-password/register";
 export const LogoutRoute: string = "/logout";
@@ -1,5 +1,5 @@
 export enum SecondFactorMethod {
     TOTP = 1,
-    U2F = 2,
+    Webauthn = 2,
     MobilePush = 3,
 }
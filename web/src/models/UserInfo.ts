@@ -3,6 +3,6 @@ import { SecondFactorMethod } from "@models/Methods";
 export interface UserInfo {
     display_name: string;
     method: SecondFactorMethod;
-    has_u2f: boolean;
+    has_webauthn: boolean;
     has_totp: boolean;
 }
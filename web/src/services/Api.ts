@@ -6,17 +6,20 @@ const basePath = getBasePath();
 
 // Note: If you change this const you must also do so in the backend at internal/handlers/cost.go.
 export const ConsentPath = basePath + "/api/oidc/consent";
+export const ConsentSessionsPath = basePath + "/api/oidc/consents";
+export const DevicePath = basePath + "/api/oidc/device";
 
 export const FirstFactorPath = basePath + "/api/firstfactor";
 export const InitiateTOTPRegistrationPath = basePath + "/api/secondfactor/totp/identity/start";
 export const CompleteTOTPRegistrationPath = basePath + "/api/secondfactor/totp/identity/finish";
+export const TOTPConfigurationsPath = basePath + "/api/secondfactor/totp/configurations";
 
-export const InitiateU2FRegistrationPath = basePath + "/api/secondfactor/u2f/identity/start";
-export const CompleteU2FRegistrationStep1Path = basePath + "/api/secondfactor/u2f/identity/finish";
-export const CompleteU2FRegistrationStep2Path = basePath + "/api/secondfactor/u2f/register";
+export const WebauthnIdentityStartPath = basePath + "/api/secondfactor/webauthn/identity/start";
+export const WebauthnIdentityFinishPath = basePath + "/api/secondfactor/webauthn/identity/finish";
+export const WebauthnAttestationPath = basePath + "/api/secondfactor/webauthn/attestation";
+export const WebauthnDevicesPath = basePath + "/api/secondfactor/webauthn/devices";
 
-export const InitiateU2FSignInPath = basePath + "/api/secondfactor/u2f/sign_request";
-export const CompleteU2FSignInPath = basePath + "/api/secondfactor/u2f/sign";
+export const WebauthnAssertionPath = basePath + "/api/secondfactor/webauthn/assertion";
 
 export const CompletePushNotificationSignInPath = basePath + "/api/secondfactor/duo";
 export const CompleteTOTPSignInPath = basePath + "/api/secondfactor/totp";
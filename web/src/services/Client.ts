@@ -32,3 +32,11 @@ export async function Get<T = undefined>(path: string): Promise<T> {
     }
     return d;
 }
+
+export async function Delete(path: string) {
+    const res = await axios.delete<ServiceResponse<undefined>>(path);
+
+    if (res.status !== 200 || hasServiceError(res).errored) {
+        throw new Error(`Failed DELETE to ${path}. Code: ${res.status}. Message: ${hasServiceError(res).message}`);
+    }
+}
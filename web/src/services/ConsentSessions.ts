@@ -0,0 +1,20 @@
+import { ConsentSessionsPath } from "@services/Api";
+import { Delete, Get } from "@services/Client";
+
+export interface ConsentSession {
+    id: number;
+    client_id: string;
+    client_description: string;
+    scopes: string[];
+    audience: string[];
+    created_at: string;
+    expires_at: string;
+}
+
+export async function getConsentSessions() {
+    return Get<ConsentSession[]>(ConsentSessionsPath);
+}
+
+export async function revokeConsentSession(id: number) {
+    return Delete(`${ConsentSessionsPath}/${id}`);
+}
@@ -0,0 +1,40 @@
+import { DevicePath } from "@services/Api";
+import { Get, PostWithOptionalResponse } from "@services/Client";
+
+interface DevicePostRequestBody {
+    user_code: string;
+    accept_or_reject: "accept" | "reject";
+}
+
+export interface DeviceGetResponseBody {
+    client_id: string;
+    client_description: string;
+    scopes: Scope[];
+    audience: Audience[];
+    user_code: string;
+    second_factor_required: boolean;
+}
+
+interface Scope {
+    name: string;
+    description: string;
+}
+
+interface Audience {
+    name: string;
+    description: string;
+}
+
+export function getDeviceAuthorizationRequest(userCode: string) {
+    return Get<DeviceGetResponseBody>(`${DevicePath}?user_code=${encodeURIComponent(userCode)}`);
+}
+
+export function acceptDeviceAuthorizationRequest(userCode: string) {
+    const body: DevicePostRequestBody = { user_code: userCode, accept_or_reject: "accept" };
+    return PostWithOptionalResponse(DevicePath, body);
+}
+
+export function rejectDeviceAuthorizationRequest(userCode: string) {
+    const body: DevicePostRequestBody = { user_code: userCode, accept_or_reject: "reject" };
+    return PostWithOptionalResponse(DevicePath, body);
+}
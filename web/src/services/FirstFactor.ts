@@ -2,6 +2,12 @@ import { FirstFactorPath } from "@services/Api";
 import { PostWithOptionalResponse } from "@services/Client";
 import { SignInResponse } from "@services/SignIn";
 
+// Note: If you change these messages you must also do so in the backend at internal/handlers/const.go.
+export const AccountLockedMessage = "Your account is locked.";
+export const AccountDisabledMessage = "Your account is disabled.";
+export const PasswordExpiredMessage = "Your password has expired and must be changed.";
+export const PasswordMustChangeMessage = "Your password must be changed.";
+
 interface PostFirstFactorBody {
     username: string;
     password: string;
@@ -34,3 +40,8 @@ export async function postFirstFactor(
     const res = await PostWithOptionalResponse<SignInResponse>(FirstFactorPath, data);
     return res ? res : ({} as SignInResponse);
 }
+
+// getFirstFactorErrorMessage returns the message of the reason the first factor failed replied by the API.
+export function getFirstFactorErrorMessage(err: any): string | undefined {
+    return err?.response?.data?.message;
+}
@@ -0,0 +1,25 @@
+import { TOTPConfigurationsPath, WebauthnDevicesPath } from "@services/Api";
+import { Delete, Get } from "@services/Client";
+
+export interface SecondFactorDevice {
+    id: number;
+    description: string;
+    created_at: string;
+    last_used_at?: string;
+}
+
+export async function getTOTPConfigurations() {
+    return Get<SecondFactorDevice[]>(TOTPConfigurationsPath);
+}
+
+export async function deleteTOTPConfiguration(id: number) {
+    return Delete(`${TOTPConfigurationsPath}/${id}`);
+}
+
+export async function getWebauthnDevices() {
+    return Get<SecondFactorDevice[]>(WebauthnDevicesPath);
+}
+
+export async function deleteWebauthnDevice(id: number) {
+    return Delete(`${WebauthnDevicesPath}/${id}`);
+}
@@ -3,12 +3,12 @@ import { UserInfo } from "@models/UserInfo";
 import { UserInfoPath, UserInfo2FAMethodPath } from "@services/Api";
 import { Get, PostWithOptionalResponse } from "@services/Client";
 
-export type Method2FA = "u2f" | "totp" | "mobile_push";
+export type Method2FA = "webauthn" | "totp" | "mobile_push";
 
 export interface UserInfoPayload {
     display_name: string;
     method: Method2FA;
-    has_u2f: boolean;
+    has_webauthn: boolean;
     has_totp: boolean;
 }
 
@@ -18,8 +18,8 @@ export interface MethodPreferencePayload {
 
 export function toEnum(method: Method2FA): SecondFactorMethod {
     switch (method) {
-        case "u2f":
-            return SecondFactorMethod.U2F;
+        case "webauthn":
+            return SecondFactorMethod.Webauthn;
         case "totp":
             return SecondFactorMethod.TOTP;
         case "mobile_push":
@@ -29,8 +29,8 @@ export function toEnum(method: Method2FA): SecondFactorMethod {
 
 export function toString(method: SecondFactorMethod): Method2FA {
     switch (method) {
-        case SecondFactorMethod.U2F:
-            return "u2f";
+        case SecondFactorMethod.Webauthn:
+            return "webauthn";
         case SecondFactorMethod.TOTP:
             return "totp";
         case SecondFactorMethod.MobilePush:
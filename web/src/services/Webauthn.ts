@@ -0,0 +1,185 @@
+import { WebauthnAssertionPath, WebauthnAttestationPath, WebauthnIdentityFinishPath } from "@services/Api";
+import { Get, Post, PostWithOptionalResponse } from "@services/Client";
+import { SignInResponse } from "@services/SignIn";
+
+interface PublicKeyCredentialCreationOptionsJSON
+    extends Omit<PublicKeyCredentialCreationOptions, "challenge" | "user" | "excludeCredentials"> {
+    challenge: string;
+    user: Omit<PublicKeyCredentialUserEntity, "id"> & { id: string };
+    excludeCredentials?: PublicKeyCredentialDescriptorJSON[];
+}
+
+interface PublicKeyCredentialRequestOptionsJSON
+    extends Omit<PublicKeyCredentialRequestOptions, "challenge" | "allowCredentials"> {
+    challenge: string;
+    allowCredentials?: PublicKeyCredentialDescriptorJSON[];
+}
+
+interface PublicKeyCredentialDescriptorJSON extends Omit<PublicKeyCredentialDescriptor, "id"> {
+    id: string;
+}
+
+interface CredentialCreation {
+    publicKey: PublicKeyCredentialCreationOptionsJSON;
+}
+
+interface CredentialRequest {
+    publicKey: PublicKeyCredentialRequestOptionsJSON;
+}
+
+interface PublicKeyCredentialJSON {
+    id: string;
+    rawId: string;
+    type: string;
+    clientExtensionResults: AuthenticationExtensionsClientOutputs;
+}
+
+interface AttestationPublicKeyCredentialJSON extends PublicKeyCredentialJSON {
+    response: {
+        attestationObject: string;
+        clientDataJSON: string;
+    };
+}
+
+interface AttestationRequestJSON extends AttestationPublicKeyCredentialJSON {
+    description?: string;
+}
+
+interface AssertionPublicKeyCredentialJSON extends PublicKeyCredentialJSON {
+    response: {
+        authenticatorData: string;
+        clientDataJSON: string;
+        signature: string;
+        userHandle: string | null;
+    };
+    targetURL?: string;
+}
+
+export function isWebauthnSupported() {
+    return window?.PublicKeyCredential !== undefined && typeof window.PublicKeyCredential === "function";
+}
+
+function arrayBufferDecode(value: string): ArrayBuffer {
+    const base64 = value.replace(/-/g, "+").replace(/_/g, "/");
+    const padded = base64.padEnd(base64.length + ((4 - (base64.length % 4)) % 4), "=");
+    const binary = window.atob(padded);
+    const buffer = new Uint8Array(binary.length);
+
+    for (let i = 0; i < binary.length; i++) {
+        buffer[i] = binary.charCodeAt(i);
+    }
+
+    return buffer.buffer;
+}
+
+function arrayBufferEncode(value: ArrayBuffer): string {
+    const bytes = new Uint8Array(value);
+    let binary = "";
+
+    for (let i = 0; i < bytes.byteLength; i++) {
+        binary += String.fromCharCode(bytes[i]);
+    }
+
+    return window.btoa(binary).replace(/\+/g, "-").replace(/\//g, "_").replace(/=/g, "");
+}
+
+function decodeCredentialDescriptor(descriptor: PublicKeyCredentialDescriptorJSON): PublicKeyCredentialDescriptor {
+    return { ...descriptor, id: arrayBufferDecode(descriptor.id) };
+}
+
+function decodePublicKeyCredentialCreationOptions(
+    options: PublicKeyCredentialCreationOptionsJSON,
+): PublicKeyCredentialCreationOptions {
+    return {
+        ...options,
+        challenge: arrayBufferDecode(options.challenge),
+        user: { ...options.user, id: arrayBufferDecode(options.user.id) },
+        excludeCredentials: options.excludeCredentials?.map(decodeCredentialDescriptor),
+    };
+}
+
+function decodePublicKeyCredentialRequestOptions(
+    options: PublicKeyCredentialRequestOptionsJSON,
+): PublicKeyCredentialRequestOptions {
+    return {
+        ...options,
+        challenge: arrayBufferDecode(options.challenge),
+        allowCredentials: options.allowCredentials?.map(decodeCredentialDescriptor),
+    };
+}
+
+function encodeAttestationPublicKeyCredential(credential: PublicKeyCredential): AttestationPublicKeyCredentialJSON {
+    const response = credential.response as AuthenticatorAttestationResponse;
+
+    return {
+        id: credential.id,
+        rawId: arrayBufferEncode(credential.rawId),
+        type: credential.type,
+        clientExtensionResults: credential.getClientExtensionResults(),
+        response: {
+            attestationObject: arrayBufferEncode(response.attestationObject),
+            clientDataJSON: arrayBufferEncode(response.clientDataJSON),
+        },
+    };
+}
+
+function encodeAssertionPublicKeyCredential(
+    credential: PublicKeyCredential,
+    targetURL: string | undefined,
+): AssertionPublicKeyCredentialJSON {
+    const response = credential.response as AuthenticatorAssertionResponse;
+
+    return {
+        id: credential.id,
+        rawId: arrayBufferEncode(credential.rawId),
+        type: credential.type,
+        clientExtensionResults: credential.getClientExtensionResults(),
+        response: {
+            authenticatorData: arrayBufferEncode(response.authenticatorData),
+            clientDataJSON: arrayBufferEncode(response.clientDataJSON),
+            signature: arrayBufferEncode(response.signature),
+            userHandle: response.userHandle ? arrayBufferEncode(response.userHandle) : null,
+        },
+        targetURL: targetURL,
+    };
+}
+
+export async function performAttestation(processToken: string, description?: string) {
+    const creation = await Post<CredentialCreation>(WebauthnIdentityFinishPath, { token: processToken });
+
+    const credential = (await navigator.credentials.create({
+        publicKey: decodePublicKeyCredentialCreationOptions(creation.publicKey),
+    })) as PublicKeyCredential | null;
+
+    if (credential === null) {
+        throw new Error("The browser did not return a credential");
+    }
+
+    const request: AttestationRequestJSON = {
+        ...encodeAttestationPublicKeyCredential(credential),
+        description: description,
+    };
+
+    return PostWithOptionalResponse(WebauthnAttestationPath, request);
+}
+
+export async function getAssertion() {
+    const request = await Get<CredentialRequest>(WebauthnAssertionPath);
+
+    const credential = (await navigator.credentials.get({
+        publicKey: decodePublicKeyCredentialRequestOptions(request.publicKey),
+    })) as PublicKeyCredential | null;
+
+    if (credential === null) {
+        throw new Error("The browser did not return a credential");
+    }
+
+    return credential;
+}
+
+export function postAssertion(credential: PublicKeyCredential, targetURL: string | undefined) {
+    return PostWithOptionalResponse<SignInResponse>(
+        WebauthnAssertionPath,
+        encodeAssertionPublicKeyCredential(credential, targetURL),
+    );
+}
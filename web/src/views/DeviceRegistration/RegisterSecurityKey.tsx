@@ -2,13 +2,12 @@ import React, { useState, useEffect, useCallback } from "react";
 
 import { makeStyles, Typography, Button } from "@material-ui/core";
 import { useHistory, useLocation } from "react-router";
-import u2fApi from "u2f-api";
 
 import FingerTouchIcon from "@components/FingerTouchIcon";
 import { useNotifications } from "@hooks/NotificationsContext";
 import LoginLayout from "@layouts/LoginLayout";
 import { FirstFactorPath } from "@services/Api";
-import { completeU2FRegistrationProcessStep1, completeU2FRegistrationProcessStep2 } from "@services/RegisterDevice";
+import { performAttestation } from "@services/Webauthn";
 import { extractIdentityToken } from "@utils/IdentityToken";
 
 const RegisterSecurityKey = function () {
@@ -30,18 +29,7 @@ const RegisterSecurityKey = function () {
         }
         try {
             setRegistrationInProgress(true);
-            const res = await completeU2FRegistrationProcessStep1(processToken);
-            const registerRequests: u2fApi.RegisterRequest[] = [];
-            for (var i in res.registerRequests) {
-                const r = res.registerRequests[i];
-                registerRequests.push({
-                    appId: res.appId,
-                    challenge: r.challenge,
-                    version: r.version,
-                });
-            }
-            const registerResponse = await u2fApi.register(registerRequests, [], 60);
-            await completeU2FRegistrationProcessStep2(registerResponse);
+            await performAttestation(processToken);
             setRegistrationInProgress(false);
             history.push(FirstFactorPath);
         } catch (err) {
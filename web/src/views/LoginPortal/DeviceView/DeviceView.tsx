@@ -0,0 +1,260 @@
+import React, { useCallback, useEffect, useState, Fragment, ReactNode } from "react";
+
+import { Button, Grid, List, ListItem, ListItemIcon, ListItemText, Tooltip, makeStyles } from "@material-ui/core";
+import { AccountBox, CheckBox, Contacts, Drafts, Group } from "@material-ui/icons";
+import queryString from "query-string";
+import { useHistory, useLocation } from "react-router-dom";
+
+import FixedTextField from "@components/FixedTextField";
+import { DeviceRoute, FirstFactorRoute } from "@constants/Routes";
+import { useNotifications } from "@hooks/NotificationsContext";
+import { useAutheliaState } from "@hooks/State";
+import LoginLayout from "@layouts/LoginLayout";
+import {
+    acceptDeviceAuthorizationRequest,
+    DeviceGetResponseBody,
+    getDeviceAuthorizationRequest,
+    rejectDeviceAuthorizationRequest,
+} from "@services/Device";
+import { AuthenticationLevel } from "@services/State";
+import { getBasePath } from "@utils/BasePath";
+import LoadingPage from "@views/LoadingPage/LoadingPage";
+
+export interface Props {}
+
+function showListItemAvatar(id: string) {
+    switch (id) {
+        case "openid":
+            return <AccountBox />;
+        case "profile":
+            return <Contacts />;
+        case "groups":
+            return <Group />;
+        case "email":
+            return <Drafts />;
+        default:
+            return <CheckBox />;
+    }
+}
+
+function authenticationURL(userCode: string) {
+    const query = queryString.stringify({ user_code: userCode });
+    const deviceURL = `${window.location.origin}${getBasePath()}${DeviceRoute}?${query}`;
+    return `${FirstFactorRoute}?rd=${encodeURIComponent(deviceURL)}`;
+}
+
+const DeviceView = function (props: Props) {
+    const classes = useStyles();
+    const history = useHistory();
+    const location = useLocation();
+    const { createErrorNotification, createSuccessNotification } = useNotifications();
+    const [state, fetchState, , fetchStateError] = useAutheliaState();
+
+    const queryParams = queryString.parse(location.search);
+    const [userCode, setUserCode] = useState(
+        queryParams && "user_code" in queryParams ? (queryParams["user_code"] as string) : "",
+    );
+    const [userCodeError, setUserCodeError] = useState(false);
+    const [resp, setResp] = useState<DeviceGetResponseBody | undefined>(undefined);
+    const [answered, setAnswered] = useState(false);
+
+    useEffect(() => {
+        fetchState();
+    }, [fetchState]);
+
+    useEffect(() => {
+        if (fetchStateError) {
+            createErrorNotification("There was an issue fetching the current user state");
+        }
+    }, [fetchStateError, createErrorNotification]);
+
+    // The user must be authenticated before answering a device authorization request.
+    useEffect(() => {
+        if (state && state.authentication_level === AuthenticationLevel.Unauthenticated) {
+            history.push(authenticationURL(userCode));
+        }
+    }, [state, history, userCode]);
+
+    const handleContinue = useCallback(async () => {
+        if (!userCode.length) {
+            setUserCodeError(true);
+            return;
+        }
+
+        try {
+            const res = await getDeviceAuthorizationRequest(userCode);
+            if (res.second_factor_required) {
+                history.push(authenticationURL(userCode));
+                return;
+            }
+            setResp(res);
+        } catch (err) {
+            console.error(err);
+            setUserCodeError(true);
+            createErrorNotification("The code is invalid or has expired.");
+        }
+    }, [userCode, history, createErrorNotification]);
+
+    const handleAnswer = async (accept: boolean) => {
+        // This case should not happen in theory because the buttons are not displayed when response is undefined.
+        if (!resp) {
+            return;
+        }
+
+        try {
+            if (accept) {
+                await acceptDeviceAuthorizationRequest(resp.user_code);
+                createSuccessNotification("The device has been authorized, you can now return to it.");
+            } else {
+                await rejectDeviceAuthorizationRequest(resp.user_code);
+                createSuccessNotification("The device has been denied access.");
+            }
+            setAnswered(true);
+        } catch (err) {
+            console.error(err);
+            createErrorNotification("There was an issue answering the device authorization request.");
+        }
+    };
+
+    const ready = state !== undefined && state.authentication_level > AuthenticationLevel.Unauthenticated;
+
+    if (answered) {
+        return (
+            <LoginLayout id="device-stage" title="Device Connected" showBrand>
+                <div id="device-answered">You can now close this page and return to your device.</div>
+            </LoginLayout>
+        );
+    }
+
+    return (
+        <ComponentOrLoading ready={ready}>
+            {resp === undefined ? (
+                <LoginLayout id="device-stage" title="Connect a Device" showBrand>
+                    <Grid container spacing={2}>
+                        <Grid item xs={12}>
+                            <div style={{ textAlign: "left" }}>Enter the code displayed on your device.</div>
+                        </Grid>
+                        <Grid item xs={12}>
+                            <FixedTextField
+                                id="user-code-textfield"
+                                label="Code"
+                                variant="outlined"
+                                required
+                                value={userCode}
+                                error={userCodeError}
+                                fullWidth
+                                onChange={(v) => setUserCode(v.target.value)}
+                                onFocus={() => setUserCodeError(false)}
+                                onKeyPress={(ev) => {
+                                    if (ev.key === "Enter") {
+                                        handleContinue();
+                                        ev.preventDefault();
+                                    }
+                                }}
+                            />
+                        </Grid>
+                        <Grid item xs={12}>
+                            <Button
+                                id="continue-button"
+                                className={classes.button}
+                                onClick={handleContinue}
+                                color="primary"
+                                variant="contained"
+                            >
+                                Continue
+                            </Button>
+                        </Grid>
+                    </Grid>
+                </LoginLayout>
+            ) : (
+                <LoginLayout id="device-consent-stage" title="Permissions Request" showBrand>
+                    <Grid container>
+                        <Grid item xs={12}>
+                            <div style={{ textAlign: "left" }}>
+                                The device using the application
+                                <b>{` ${resp.client_description} (${resp.client_id}) `}</b>
+                                is requesting the following permissions
+                            </div>
+                        </Grid>
+                        <Grid item xs={12}>
+                            <div className={classes.scopesListContainer}>
+                                <List className={classes.scopesList}>
+                                    {resp.scopes.map((s) => (
+                                        <Tooltip title={"Scope " + s.name} key={s.name}>
+                                            <ListItem id={"scope-" + s.name} dense>
+                                                <ListItemIcon>{showListItemAvatar(s.name)}</ListItemIcon>
+                                                <ListItemText primary={s.description} />
+                                            </ListItem>
+                                        </Tooltip>
+                                    ))}
+                                </List>
+                            </div>
+                        </Grid>
+                        <Grid item xs={12}>
+                            <Grid container spacing={1}>
+                                <Grid item xs={6}>
+                                    <Button
+                                        id="accept-button"
+                                        className={classes.button}
+                                        onClick={() => handleAnswer(true)}
+                                        color="primary"
+                                        variant="contained"
+                                    >
+                                        Accept
+                                    </Button>
+                                </Grid>
+                                <Grid item xs={6}>
+                                    <Button
+                                        id="deny-button"
+                                        className={classes.button}
+                                        onClick={() => handleAnswer(false)}
+                                        color="secondary"
+                                        variant="contained"
+                                    >
+                                        Deny
+                                    </Button>
+                                </Grid>
+                            </Grid>
+                        </Grid>
+                    </Grid>
+                </LoginLayout>
+            )}
+        </ComponentOrLoading>
+    );
+};
+
+const useStyles = makeStyles((theme) => ({
+    scopesListContainer: {
+        textAlign: "center",
+    },
+    scopesList: {
+        display: "inline-block",
+        backgroundColor: theme.palette.background.paper,
+        marginTop: theme.spacing(2),
+        marginBottom: theme.spacing(2),
+    },
+    button: {
+        marginLeft: theme.spacing(),
+        marginRight: theme.spacing(),
+        width: "100%",
+    },
+}));
+
+export default DeviceView;
+
+interface ComponentOrLoadingProps {
+    ready: boolean;
+
+    children: ReactNode;
+}
+
+function ComponentOrLoading(props: ComponentOrLoadingProps) {
+    return (
+        <Fragment>
+            <div className={props.ready ? "hidden" : ""}>
+                <LoadingPage />
+            </div>
+            {props.ready ? props.children : null}
+        </Fragment>
+    );
+}
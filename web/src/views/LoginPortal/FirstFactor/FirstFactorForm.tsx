@@ -5,12 +5,19 @@ import classnames from "classnames";
 import { useHistory } from "react-router";
 
 import FixedTextField from "@components/FixedTextField";
-import { ResetPasswordStep1Route } from "@constants/Routes";
+import { PasswordChangeRoute, ResetPasswordStep1Route } from "@constants/Routes";
 import { useNotifications } from "@hooks/NotificationsContext";
 import { useRedirectionURL } from "@hooks/RedirectionURL";
 import { useRequestMethod } from "@hooks/RequestMethod";
 import LoginLayout from "@layouts/LoginLayout";
-import { postFirstFactor } from "@services/FirstFactor";
+import {
+    AccountDisabledMessage,
+    AccountLockedMessage,
+    getFirstFactorErrorMessage,
+    PasswordExpiredMessage,
+    PasswordMustChangeMessage,
+    postFirstFactor,
+} from "@services/FirstFactor";
 
 export interface Props {
     disabled: boolean;
@@ -66,7 +73,18 @@ const FirstFactorForm = function (props: Props) {
             props.onAuthenticationSuccess(res ? res.redirect : undefined);
         } catch (err) {
             console.error(err);
-            createErrorNotification("Incorrect username or password.");
+            const message = getFirstFactorErrorMessage(err);
+            if (message === PasswordExpiredMessage || message === PasswordMustChangeMessage) {
+                createErrorNotification(message);
+                props.onAuthenticationFailure();
+                history.push(PasswordChangeRoute);
+                return;
+            }
+            if (message === AccountLockedMessage || message === AccountDisabledMessage) {
+                createErrorNotification(message);
+            } else {
+                createErrorNotification("Incorrect username or password.");
+            }
             props.onAuthenticationFailure();
             setPassword("");
             passwordRef.current.focus();
@@ -7,7 +7,7 @@ import {
     SecondFactorRoute,
     SecondFactorTOTPRoute,
     SecondFactorPushRoute,
-    SecondFactorU2FRoute,
+    SecondFactorWebauthnRoute,
     AuthenticatedRoute,
 } from "@constants/Routes";
 import { useConfiguration } from "@hooks/Configuration";
@@ -99,8 +99,8 @@ const LoginPortal = function (props: Props) {
                 if (!configuration.second_factor_enabled) {
                     redirect(AuthenticatedRoute);
                 } else {
-                    if (userInfo.method === SecondFactorMethod.U2F) {
-                        redirect(`${SecondFactorU2FRoute}${redirectionSuffix}`);
+                    if (userInfo.method === SecondFactorMethod.Webauthn) {
+                        redirect(`${SecondFactorWebauthnRoute}${redirectionSuffix}`);
                     } else if (userInfo.method === SecondFactorMethod.MobilePush) {
                         redirect(`${SecondFactorPushRoute}${redirectionSuffix}`);
                     } else {
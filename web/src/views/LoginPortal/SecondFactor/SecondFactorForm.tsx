@@ -2,13 +2,12 @@ import React, { useState, useEffect } from "react";
 
 import { Grid, makeStyles, Button } from "@material-ui/core";
 import { useHistory, Switch, Route, Redirect } from "react-router";
-import u2fApi from "u2f-api";
 
 import {
     LogoutRoute as SignOutRoute,
     SecondFactorTOTPRoute,
     SecondFactorPushRoute,
-    SecondFactorU2FRoute,
+    SecondFactorWebauthnRoute,
     SecondFactorRoute,
 } from "@constants/Routes";
 import { useNotifications } from "@hooks/NotificationsContext";
@@ -16,9 +15,10 @@ import LoginLayout from "@layouts/LoginLayout";
 import { Configuration } from "@models/Configuration";
 import { SecondFactorMethod } from "@models/Methods";
 import { UserInfo } from "@models/UserInfo";
-import { initiateTOTPRegistrationProcess, initiateU2FRegistrationProcess } from "@services/RegisterDevice";
+import { initiateTOTPRegistrationProcess, initiateWebauthnRegistrationProcess } from "@services/RegisterDevice";
 import { AuthenticationLevel } from "@services/State";
 import { setPreferred2FAMethod } from "@services/UserPreferences";
+import { isWebauthnSupported } from "@services/Webauthn";
 import MethodSelectionDialog from "@views/LoginPortal/SecondFactor/MethodSelectionDialog";
 import OneTimePasswordMethod from "@views/LoginPortal/SecondFactor/OneTimePasswordMethod";
 import PushNotificationMethod from "@views/LoginPortal/SecondFactor/PushNotificationMethod";
@@ -42,15 +42,16 @@ const SecondFactorForm = function (props: Props) {
     const [methodSelectionOpen, setMethodSelectionOpen] = useState(false);
     const { createInfoNotification, createErrorNotification } = useNotifications();
     const [registrationInProgress, setRegistrationInProgress] = useState(false);
-    const [u2fSupported, setU2fSupported] = useState(false);
+    const [webauthnSupported, setWebauthnSupported] = useState(false);
 
-    // Check that U2F is supported.
+    // Check that Webauthn is supported.
     useEffect(() => {
-        u2fApi.ensureSupport().then(
-            () => setU2fSupported(true),
-            () => console.error("U2F not supported"),
-        );
-    }, [setU2fSupported]);
+        if (isWebauthnSupported()) {
+            setWebauthnSupported(true);
+        } else {
+            console.error("Webauthn not supported");
+        }
+    }, [setWebauthnSupported]);
 
     const initiateRegistration = (initiateRegistrationFunc: () => Promise<void>) => {
         return async () => {
@@ -93,7 +94,7 @@ const SecondFactorForm = function (props: Props) {
             <MethodSelectionDialog
                 open={methodSelectionOpen}
                 methods={props.configuration.available_methods}
-                u2fSupported={u2fSupported}
+                webauthnSupported={webauthnSupported}
                 onClose={() => setMethodSelectionOpen(false)}
                 onClick={handleMethodSelected}
             />
@@ -121,13 +122,13 @@ const SecondFactorForm = function (props: Props) {
                                 onSignInSuccess={props.onAuthenticationSuccess}
                             />
                         </Route>
-                        <Route path={SecondFactorU2FRoute} exact>
+                        <Route path={SecondFactorWebauthnRoute} exact>
                             <SecurityKeyMethod
                                 id="security-key-method"
                                 authenticationLevel={props.authenticationLevel}
-                                // Whether the user has a U2F device registered already
-                                registered={props.userInfo.has_u2f}
-                                onRegisterClick={initiateRegistration(initiateU2FRegistrationProcess)}
+                                // Whether the user has a Webauthn device registered already
+                                registered={props.userInfo.has_webauthn}
+                                onRegisterClick={initiateRegistration(initiateWebauthnRegistrationProcess)}
                                 onSignInError={(err) => createErrorNotification(err.message)}
                                 onSignInSuccess={props.onAuthenticationSuccess}
                             />
@@ -2,7 +2,6 @@ import React, { useCallback, useEffect, useState, Fragment } from "react";
 
 import { makeStyles, Button, useTheme } from "@material-ui/core";
 import { CSSProperties } from "@material-ui/styles";
-import u2fApi from "u2f-api";
 
 import FailureIcon from "@components/FailureIcon";
 import FingerTouchIcon from "@components/FingerTouchIcon";
@@ -10,8 +9,8 @@ import LinearProgressBar from "@components/LinearProgressBar";
 import { useIsMountedRef } from "@hooks/Mounted";
 import { useRedirectionURL } from "@hooks/RedirectionURL";
 import { useTimer } from "@hooks/Timer";
-import { initiateU2FSignin, completeU2FSignin } from "@services/SecurityKey";
 import { AuthenticationLevel } from "@services/State";
+import { getAssertion, postAssertion } from "@services/Webauthn";
 import IconWithContext from "@views/LoginPortal/SecondFactor/IconWithContext";
 import MethodContainer, { State as MethodContainerState } from "@views/LoginPortal/SecondFactor/MethodContainer";
 
@@ -54,24 +53,13 @@ const SecurityKeyMethod = function (props: Props) {
         try {
             triggerTimer();
             setState(State.WaitTouch);
-            const signRequest = await initiateU2FSignin();
-            const signRequests: u2fApi.SignRequest[] = [];
-            for (var i in signRequest.registeredKeys) {
-                const r = signRequest.registeredKeys[i];
-                signRequests.push({
-                    appId: signRequest.appId,
-                    challenge: signRequest.challenge,
-                    keyHandle: r.keyHandle,
-                    version: r.version,
-                });
-            }
-            const signResponse = await u2fApi.sign(signRequests, signInTimeout);
+            const credential = await getAssertion();
             // If the request was initiated and the user changed 2FA method in the meantime,
             // the process is interrupted to avoid updating state of unmounted component.
             if (!mounted.current) return;
 
             setState(State.SigninInProgress);
-            const res = await completeU2FSignin(signResponse, redirectionURL);
+            const res = await postAssertion(credential, redirectionURL);
             onSignInSuccessCallback(res ? res.redirect : undefined);
         } catch (err) {
             // If the request was initiated and the user changed 2FA method in the meantime,
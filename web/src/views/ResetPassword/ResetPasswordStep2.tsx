@@ -11,7 +11,12 @@ import LoginLayout from "@layouts/LoginLayout";
 import { completeResetPasswordProcess, resetPassword } from "@services/ResetPassword";
 import { extractIdentityToken } from "@utils/IdentityToken";
 
-const ResetPasswordStep2 = function () {
+export interface Props {
+    // The password must be changed on sign in, there is no identity verification process to complete.
+    passwordChange?: boolean;
+}
+
+const ResetPasswordStep2 = function (props: Props) {
     const style = useStyles();
     const location = useLocation();
     const [formDisabled, setFormDisabled] = useState(true);
@@ -26,6 +31,11 @@ const ResetPasswordStep2 = function () {
     const processToken = extractIdentityToken(location.search);
 
     const completeProcess = useCallback(async () => {
+        if (props.passwordChange) {
+            setFormDisabled(false);
+            return;
+        }
+
         if (!processToken) {
             setFormDisabled(true);
             createErrorNotification("No verification token provided");
@@ -43,7 +53,7 @@ const ResetPasswordStep2 = function () {
             );
             setFormDisabled(true);
         }
-    }, [processToken, createErrorNotification]);
+    }, [processToken, createErrorNotification, props.passwordChange]);
 
     useEffect(() => {
         completeProcess();
@@ -68,7 +78,7 @@ const ResetPasswordStep2 = function () {
 
         try {
             await resetPassword(password1);
-            createSuccessNotification("Password has been reset.");
+            createSuccessNotification(props.passwordChange ? "Password has been changed." : "Password has been reset.");
             setTimeout(() => history.push(FirstFactorRoute), 1500);
             setFormDisabled(true);
         } catch (err) {
@@ -86,7 +96,10 @@ const ResetPasswordStep2 = function () {
     const handleCancelClick = () => history.push(FirstFactorRoute);
 
     return (
-        <LoginLayout title="Enter new password" id="reset-password-step2-stage">
+        <LoginLayout
+            title={props.passwordChange ? "Change your password" : "Enter new password"}
+            id="reset-password-step2-stage"
+        >
             <Grid container className={style.root} spacing={2}>
                 <Grid item xs={12}>
                     <FixedTextField